@@ -0,0 +1,362 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/fernet/fernet-go"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/app"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/database"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/model"
+)
+
+// migrate applies, rolls back or lists schema migrations.
+func (e *env) migrate(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("%w: migrate expects up, down or status", errUsage)
+	}
+
+	switch args[0] {
+	case "up":
+		if err := database.EnsureDir(e.dbPath); err != nil {
+			return fmt.Errorf("ensure database directory: %w", err)
+		}
+		db, err := database.OpenWithConfig(e.dbConfig())
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+		return database.Migrate(db)
+	case "down":
+		db, err := e.openDB()
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+		return database.MigrateDown(db)
+	case "status":
+		db, err := e.openDB()
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+		states, err := database.MigrationStatus(db)
+		if err != nil {
+			return err
+		}
+		tw := tabwriter.NewWriter(e.stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "VERSION\tAPPLIED AT\tMIGRATION")
+		for _, s := range states {
+			appliedAt := "pending"
+			if s.Applied {
+				appliedAt = s.AppliedAt.UTC().Format("2006-01-02 15:04:05")
+			}
+			fmt.Fprintf(tw, "%d\t%s\t%s\n", s.Version, appliedAt, s.Name)
+		}
+		return tw.Flush()
+	default:
+		return fmt.Errorf("%w: unknown migrate command %q", errUsage, args[0])
+	}
+}
+
+// backup writes a point-in-time copy of the database.
+func (e *env) backup(args []string) error {
+	fs := flag.NewFlagSet("backup", flag.ContinueOnError)
+	if err := parseFlags(fs, args, 1); err != nil {
+		return err
+	}
+
+	db, err := e.openDB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	if err := database.Backup(db, fs.Arg(0)); err != nil {
+		return err
+	}
+	fmt.Fprintf(e.stdout, "backed up %s to %s\n", e.dbPath, fs.Arg(0))
+	return nil
+}
+
+// restore replaces the database with a backup.
+func (e *env) restore(args []string) error {
+	fs := flag.NewFlagSet("restore", flag.ContinueOnError)
+	if err := parseFlags(fs, args, 1); err != nil {
+		return err
+	}
+
+	if err := database.Restore(fs.Arg(0), e.dbPath); err != nil {
+		return err
+	}
+	fmt.Fprintf(e.stdout, "restored %s from %s\n", e.dbPath, fs.Arg(0))
+	return nil
+}
+
+// rebuild regenerates the materialized history and prints the resulting coverage.
+func (e *env) rebuild(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("rebuild", flag.ContinueOnError)
+	portfolioID := fs.String("portfolio", "", "portfolio ID (default: all portfolios)")
+	from := fs.String("from", "", "first date to regenerate, YYYY-MM-DD (default: full history)")
+	if err := parseFlags(fs, args, 0); err != nil {
+		return err
+	}
+
+	var startDate time.Time
+	if *from != "" {
+		var err error
+		startDate, err = time.Parse("2006-01-02", *from)
+		if err != nil {
+			return fmt.Errorf("%w: invalid -from date %q", errUsage, *from)
+		}
+	}
+
+	return e.withServices(func(s *app.Services) error {
+		coverage, err := s.Materialized.RebuildMaterialized(ctx, *portfolioID, startDate)
+		if err != nil {
+			return err
+		}
+		tw := tabwriter.NewWriter(e.stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "PORTFOLIO\tFIRST\tLAST\tROWS\tSTALE")
+		for _, c := range coverage {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%s\n", c.PortfolioName, formatDate(c.FirstDate), formatDate(c.LastDate), c.Rows, c.StaleReason)
+		}
+		return tw.Flush()
+	})
+}
+
+// importFile imports fund prices or transactions from CSV, or an IBKR Flex statement from XML,
+// and regenerates the affected materialized history.
+func (e *env) importFile(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%w: import expects prices, transactions or flex", errUsage)
+	}
+
+	fs := flag.NewFlagSet("import "+args[0], flag.ContinueOnError)
+	var id *string
+	switch args[0] {
+	case "prices":
+		id = fs.String("fund", "", "fund ID")
+	case "transactions":
+		id = fs.String("portfolio-fund", "", "portfolio fund ID")
+	case "flex":
+	default:
+		return fmt.Errorf("%w: unknown import type %q", errUsage, args[0])
+	}
+	if err := parseFlags(fs, args[1:], 1); err != nil {
+		return err
+	}
+	if id != nil && *id == "" {
+		return fmt.Errorf("%w: import %s requires an ID flag", errUsage, args[0])
+	}
+
+	content, err := os.ReadFile(fs.Arg(0))
+	if err != nil {
+		return fmt.Errorf("read import file: %w", err)
+	}
+
+	return e.withServices(func(s *app.Services) error {
+		switch args[0] {
+		case "prices":
+			count, err := s.Developer.ImportFundPrices(ctx, *id, content)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(e.stdout, "imported %d fund prices\n", count)
+		case "transactions":
+			count, err := s.Developer.ImportTransactions(ctx, *id, content)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(e.stdout, "imported %d transactions\n", count)
+		case "flex":
+			imported, skipped, err := s.Ibkr.ImportFlexFile(ctx, content)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(e.stdout, "imported %d IBKR transactions into the inbox, skipped %d already present\n", imported, skipped)
+		}
+		s.Materialized.ProcessRegenQueue(ctx)
+		return nil
+	})
+}
+
+// updatePrices fetches the missing price history of one fund and regenerates the affected
+// materialized history.
+func (e *env) updatePrices(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("update-prices", flag.ContinueOnError)
+	fundID := fs.String("fund", "", "fund ID")
+	if err := parseFlags(fs, args, 0); err != nil {
+		return err
+	}
+	if *fundID == "" {
+		return fmt.Errorf("%w: update-prices requires -fund", errUsage)
+	}
+
+	return e.withServices(func(s *app.Services) error {
+		count, err := s.Fund.UpdateHistoricalFundPrice(ctx, *fundID)
+		if err != nil {
+			return err
+		}
+		s.Materialized.ProcessRegenQueue(ctx)
+		fmt.Fprintf(e.stdout, "added %d fund prices\n", count)
+		return nil
+	})
+}
+
+// rotateKey re-encrypts the stored IBKR token with the newest encryption key.
+//
+// With keys configured through IBKR_ENCRYPTION_KEYS the newest configured key is used and
+// -new-key is rejected; drop the older keys from the variable afterwards. Otherwise a new
+// key is generated (or taken from -new-key) and prepended to the key file, the token is
+// re-encrypted, and the file is rewritten to hold only the new key.
+func (e *env) rotateKey(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("rotate-key", flag.ContinueOnError)
+	newKeyStr := fs.String("new-key", "", "base64-encoded fernet key to rotate to (default: generate one)")
+	if err := parseFlags(fs, args, 0); err != nil {
+		return err
+	}
+
+	if len(e.cfg.EncryptionKeys) > 0 {
+		if *newKeyStr != "" {
+			return fmt.Errorf("%w: -new-key cannot be used while IBKR_ENCRYPTION_KEYS is set; add the key there instead", errUsage)
+		}
+		return e.withServices(func(s *app.Services) error {
+			rotation, err := s.Ibkr.RotateEncryptionKey(ctx)
+			if err != nil {
+				return err
+			}
+			printRotation(e, rotation)
+			if len(e.cfg.EncryptionKeys) > 1 {
+				fmt.Fprintln(e.stdout, "older keys can now be removed from IBKR_ENCRYPTION_KEYS")
+			}
+			return nil
+		})
+	}
+
+	var newKey fernet.Key
+	if *newKeyStr != "" {
+		decoded, err := fernet.DecodeKey(*newKeyStr)
+		if err != nil {
+			return fmt.Errorf("%w: invalid -new-key: %v", errUsage, err)
+		}
+		newKey = *decoded
+	} else if err := newKey.Generate(); err != nil {
+		return fmt.Errorf("generate encryption key: %w", err)
+	}
+	encoded := newKey.Encode()
+
+	if _, err := os.Stat(e.dbPath); err != nil {
+		return fmt.Errorf("open database: %w", err)
+	}
+	keyPath := app.EncryptionKeyPath(e.dbPath)
+	oldKeys, err := app.ReadEncryptionKeys(keyPath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	// Keep the old keys until the token is re-encrypted, so an interrupted rotation never
+	// leaves a token that no stored key can decrypt.
+	if err := app.WriteEncryptionKeys(keyPath, append([]string{encoded}, oldKeys...)...); err != nil {
+		return err
+	}
+
+	return e.withServices(func(s *app.Services) error {
+		rotation, err := s.Ibkr.RotateEncryptionKey(ctx)
+		if err != nil {
+			return err
+		}
+		if err := app.WriteEncryptionKeys(keyPath, encoded); err != nil {
+			return err
+		}
+		printRotation(e, rotation)
+		fmt.Fprintf(e.stdout, "wrote new encryption key to %s\n", keyPath)
+		return nil
+	})
+}
+
+// printRotation prints the outcome of a key rotation.
+func printRotation(e *env, r model.IbkrKeyRotation) {
+	if !r.Reencrypted {
+		fmt.Fprintf(e.stdout, "no IBKR flex token stored; new tokens use key %s\n", r.KeyID)
+		return
+	}
+	fmt.Fprintf(e.stdout, "re-encrypted the IBKR flex token from key %s to key %s\n", r.PreviousKeyID, r.KeyID)
+}
+
+// check runs SQLite's integrity and foreign key checks and, with -materialized, compares the
+// materialized history against a fresh calculation.
+func (e *env) check(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("check", flag.ContinueOnError)
+	materialized := fs.Bool("materialized", false, "also verify the materialized history")
+	portfolioID := fs.String("portfolio", "", "portfolio ID for -materialized (default: all portfolios)")
+	if err := parseFlags(fs, args, 0); err != nil {
+		return err
+	}
+
+	db, err := e.openDB()
+	if err != nil {
+		return err
+	}
+	report, err := database.CheckIntegrity(db)
+	db.Close()
+	if err != nil {
+		return err
+	}
+	for _, p := range report.Problems {
+		fmt.Fprintf(e.stdout, "integrity: %s\n", p)
+	}
+	for _, v := range report.ForeignKeys {
+		fmt.Fprintf(e.stdout, "foreign key: %s row %d references missing %s\n", v.Table, v.RowID, v.Parent)
+	}
+	failed := !report.OK()
+
+	if *materialized {
+		err := e.withServices(func(s *app.Services) error {
+			verifications, err := s.Materialized.VerifyMaterialized(ctx, *portfolioID, time.Unix(0, 0).UTC(), time.Now().UTC())
+			if err != nil {
+				return err
+			}
+			for _, v := range verifications {
+				fmt.Fprintf(e.stdout, "materialized: %s checked %d rows, %d drifted\n", v.PortfolioName, v.RowsChecked, v.DriftCount)
+				for _, d := range v.Drift {
+					printDrift(e, d)
+				}
+				if v.DriftCount > 0 {
+					failed = true
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if failed {
+		return errCheckFailed
+	}
+	fmt.Fprintln(e.stdout, "ok")
+	return nil
+}
+
+// printDrift prints one materialized drift entry.
+func printDrift(e *env, d model.MaterializedDrift) {
+	if d.Kind != model.DriftMismatch {
+		fmt.Fprintf(e.stdout, "  %s %s %s\n", d.Date.Format("2006-01-02"), d.FundName, d.Kind)
+		return
+	}
+	fmt.Fprintf(e.stdout, "  %s %s %s: materialized %g, expected %g\n", d.Date.Format("2006-01-02"), d.FundName, d.Field, d.Materialized, d.Expected)
+}
+
+// formatDate formats an optional date, or "-" when it is unset.
+func formatDate(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return t.Format("2006-01-02")
+}
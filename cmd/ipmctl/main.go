@@ -0,0 +1,176 @@
+// Command ipmctl runs maintenance tasks directly against the portfolio database: schema
+// migrations, backup and restore, materialized history rebuilds, file imports, price
+// updates, encryption key rotation and integrity checks.
+//
+// Usage:
+//
+//	ipmctl [-db path] <command> [arguments]
+//
+// The database path defaults to the server's configuration (DB_DIR or DB_PATH).
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/app"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/config"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/database"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/logging"
+)
+
+const usage = `Usage: ipmctl [-db path] <command> [arguments]
+
+Commands:
+  migrate up|down|status                    Apply, roll back one, or list migrations
+  backup <file>                             Write a consistent copy of the database to file
+  restore <file>                            Replace the database with a backup (server must be stopped)
+  rebuild [-portfolio id] [-from date]      Regenerate materialized history
+  import prices -fund <id> <file.csv>       Import fund prices (date,price)
+  import transactions -portfolio-fund <id> <file.csv>
+                                            Import transactions (date,type,shares,cost_per_share)
+  import flex <file.xml>                    Import an IBKR Flex statement into the inbox
+  update-prices -fund <id>                  Fetch missing prices for one fund from Yahoo Finance
+  rotate-key [-new-key key]                 Re-encrypt the IBKR token with a new encryption key
+  check [-materialized] [-portfolio id]     Check database integrity and, optionally, materialized drift
+`
+
+// errUsage marks errors caused by invalid command-line arguments.
+var errUsage = errors.New("invalid usage")
+
+// errCheckFailed is returned by check when it found problems, so the exit code reflects them.
+var errCheckFailed = errors.New("check failed")
+
+// env is the state shared by every command.
+type env struct {
+	cfg    *config.Config
+	dbPath string
+	stdout io.Writer
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	code := run(ctx, os.Args[1:], os.Stdout, os.Stderr)
+	stop()
+	os.Exit(code)
+}
+
+// run executes one command and returns the process exit code:
+// 0 on success, 1 on failure and 2 on invalid usage.
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(stderr, "load configuration: %v\n", err)
+		return 1
+	}
+
+	fs := flag.NewFlagSet("ipmctl", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() { fmt.Fprint(stderr, usage) }
+	dbPath := fs.String("db", cfg.Database.Path, "path to the SQLite database")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return 2
+	}
+
+	e := &env{cfg: cfg, dbPath: *dbPath, stdout: stdout}
+	if err := e.dispatch(ctx, fs.Arg(0), fs.Args()[1:]); err != nil {
+		if errors.Is(err, errUsage) {
+			fmt.Fprintf(stderr, "ipmctl: %v\n\n%s", err, usage)
+			return 2
+		}
+		fmt.Fprintf(stderr, "ipmctl: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// dispatch runs the named command with its remaining arguments.
+func (e *env) dispatch(ctx context.Context, command string, args []string) error {
+	switch command {
+	case "migrate":
+		return e.migrate(args)
+	case "backup":
+		return e.backup(args)
+	case "restore":
+		return e.restore(args)
+	case "rebuild":
+		return e.rebuild(ctx, args)
+	case "import":
+		return e.importFile(ctx, args)
+	case "update-prices":
+		return e.updatePrices(ctx, args)
+	case "rotate-key":
+		return e.rotateKey(ctx, args)
+	case "check":
+		return e.check(ctx, args)
+	default:
+		return fmt.Errorf("%w: unknown command %q", errUsage, command)
+	}
+}
+
+// openDB opens the database without touching its schema. The file must already exist, so a
+// mistyped path fails instead of creating an empty database.
+func (e *env) openDB() (*sql.DB, error) {
+	if _, err := os.Stat(e.dbPath); err != nil {
+		return nil, fmt.Errorf("open database: %w", err)
+	}
+	return database.OpenWithConfig(e.dbConfig())
+}
+
+// dbConfig returns the configured database settings for the database at e.dbPath.
+func (e *env) dbConfig() config.DatabaseConfig {
+	cfg := e.cfg.Database
+	cfg.Path = e.dbPath
+	return cfg
+}
+
+// withServices opens the database, refuses to continue if migrations are pending, and runs
+// fn with the same services the server wires. Log entries go to the database log, as they
+// do for the server.
+func (e *env) withServices(fn func(*app.Services) error) error {
+	db, err := e.openDB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	pending, err := database.HasPendingMigrations(db)
+	if err != nil {
+		return fmt.Errorf("check migrations: %w", err)
+	}
+	if pending {
+		return fmt.Errorf("database has pending migrations; run \"ipmctl migrate up\" first")
+	}
+
+	logHandler := logging.Init(db, e.cfg.Log)
+	defer logHandler.Close() // Flush remaining log entries before db.Close() (LIFO).
+
+	fernetKeys, err := app.LoadEncryptionKeys(e.cfg.EncryptionKeys, e.dbPath)
+	if err != nil {
+		return fmt.Errorf("resolve encryption key: %w", err)
+	}
+	return fn(app.NewServices(db, fernetKeys, e.cfg))
+}
+
+// parseFlags parses a command's flags and checks it received exactly nArgs positional arguments.
+func parseFlags(fs *flag.FlagSet, args []string, nArgs int) error {
+	fs.SetOutput(io.Discard)
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("%w: %s: %v", errUsage, fs.Name(), err)
+	}
+	if fs.NArg() != nArgs {
+		return fmt.Errorf("%w: %s expects %d argument(s), got %d", errUsage, fs.Name(), nArgs, fs.NArg())
+	}
+	return nil
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// runCmd runs ipmctl against dbPath and returns the exit code and output.
+func runCmd(t *testing.T, dbPath string, args ...string) (int, string, string) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	code := run(context.Background(), append([]string{"-db", dbPath}, args...), &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestRun_Usage(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"no command", nil},
+		{"unknown command", []string{"frobnicate"}},
+		{"migrate without direction", []string{"migrate"}},
+		{"unknown migrate direction", []string{"migrate", "sideways"}},
+		{"backup without file", []string{"backup"}},
+		{"import without type", []string{"import"}},
+		{"import prices without fund", []string{"import", "prices", "prices.csv"}},
+		{"update-prices without fund", []string{"update-prices"}},
+		{"rebuild with invalid date", []string{"rebuild", "-from", "yesterday"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, _, stderr := runCmd(t, dbPath, tt.args...)
+			if code != 2 {
+				t.Errorf("expected exit code 2, got %d (stderr: %s)", code, stderr)
+			}
+		})
+	}
+
+	if _, err := os.Stat(dbPath); !os.IsNotExist(err) {
+		t.Error("expected no database to be created by invalid commands")
+	}
+}
+
+func TestRun_MissingDatabase(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "missing.db")
+
+	code, _, stderr := runCmd(t, dbPath, "check")
+	if code != 1 {
+		t.Errorf("expected exit code 1, got %d", code)
+	}
+	if !strings.Contains(stderr, "open database") {
+		t.Errorf("expected an open database error, got %q", stderr)
+	}
+	if _, err := os.Stat(dbPath); !os.IsNotExist(err) {
+		t.Error("expected check not to create the database")
+	}
+}
+
+func TestRun_MaintenanceCycle(t *testing.T) {
+	t.Setenv("IBKR_ENCRYPTION_KEY", "")
+	t.Setenv("IBKR_ENCRYPTION_KEYS", "")
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "db", "portfolio_manager.db")
+	backupPath := filepath.Join(dir, "backup.db")
+
+	if code, _, stderr := runCmd(t, dbPath, "migrate", "up"); code != 0 {
+		t.Fatalf("migrate up failed: %s", stderr)
+	}
+
+	code, stdout, stderr := runCmd(t, dbPath, "migrate", "status")
+	if code != 0 {
+		t.Fatalf("migrate status failed: %s", stderr)
+	}
+	if strings.Contains(stdout, "pending") {
+		t.Errorf("expected all migrations applied, got:\n%s", stdout)
+	}
+
+	if code, stdout, stderr = runCmd(t, dbPath, "check", "-materialized"); code != 0 {
+		t.Fatalf("check failed: %s%s", stdout, stderr)
+	}
+	if !strings.Contains(stdout, "ok") {
+		t.Errorf("expected ok, got %q", stdout)
+	}
+
+	if code, _, stderr = runCmd(t, dbPath, "rebuild"); code != 0 {
+		t.Fatalf("rebuild failed: %s", stderr)
+	}
+
+	if code, _, stderr = runCmd(t, dbPath, "backup", backupPath); code != 0 {
+		t.Fatalf("backup failed: %s", stderr)
+	}
+
+	if code, _, stderr = runCmd(t, dbPath, "migrate", "down"); code != 0 {
+		t.Fatalf("migrate down failed: %s", stderr)
+	}
+	if code, _, stderr = runCmd(t, dbPath, "rebuild"); code != 1 || !strings.Contains(stderr, "pending migrations") {
+		t.Errorf("expected rebuild to refuse pending migrations, got %d: %s", code, stderr)
+	}
+
+	if code, _, stderr = runCmd(t, dbPath, "restore", backupPath); code != 0 {
+		t.Fatalf("restore failed: %s", stderr)
+	}
+	code, stdout, stderr = runCmd(t, dbPath, "migrate", "status")
+	if code != 0 {
+		t.Fatalf("migrate status after restore failed: %s", stderr)
+	}
+	if strings.Contains(stdout, "pending") {
+		t.Errorf("expected the restored database to be fully migrated, got:\n%s", stdout)
+	}
+}
+
+func TestRun_RotateKey(t *testing.T) {
+	t.Setenv("IBKR_ENCRYPTION_KEY", "")
+	t.Setenv("IBKR_ENCRYPTION_KEYS", "")
+	dbPath := filepath.Join(t.TempDir(), "portfolio_manager.db")
+	keyPath := filepath.Join(filepath.Dir(dbPath), ".ibkr_encryption_key")
+
+	if code, _, stderr := runCmd(t, dbPath, "migrate", "up"); code != 0 {
+		t.Fatalf("migrate up failed: %s", stderr)
+	}
+
+	code, stdout, stderr := runCmd(t, dbPath, "rotate-key")
+	if code != 0 {
+		t.Fatalf("rotate-key failed: %s", stderr)
+	}
+	first, err := os.ReadFile(keyPath)
+	if err != nil {
+		t.Fatalf("read key file: %v", err)
+	}
+	if !strings.Contains(stdout, keyPath) {
+		t.Errorf("expected the key path in the output, got %q", stdout)
+	}
+
+	if code, _, stderr = runCmd(t, dbPath, "rotate-key"); code != 0 {
+		t.Fatalf("second rotate-key failed: %s", stderr)
+	}
+	second, err := os.ReadFile(keyPath)
+	if err != nil {
+		t.Fatalf("read key file: %v", err)
+	}
+	if bytes.Equal(first, second) {
+		t.Error("expected rotate-key to write a new key")
+	}
+	if lines := strings.Fields(string(second)); len(lines) != 1 {
+		t.Errorf("expected the key file to hold only the new key, got %d keys", len(lines))
+	}
+
+	if code, _, _ = runCmd(t, dbPath, "rotate-key", "-new-key", "not-a-key"); code != 2 {
+		t.Errorf("expected exit code 2 for an invalid key, got %d", code)
+	}
+}
+
+func TestRun_RotateKey_ConfiguredKeys(t *testing.T) {
+	t.Setenv("IBKR_ENCRYPTION_KEY", "")
+	t.Setenv("IBKR_ENCRYPTION_KEYS", "cw_0x689RpI-jtRR7oE8h_eQsKImvJapLeSbXpwF4e4=")
+	dbPath := filepath.Join(t.TempDir(), "portfolio_manager.db")
+
+	if code, _, stderr := runCmd(t, dbPath, "migrate", "up"); code != 0 {
+		t.Fatalf("migrate up failed: %s", stderr)
+	}
+
+	if code, _, _ := runCmd(t, dbPath, "rotate-key", "-new-key", "cw_0x689RpI-jtRR7oE8h_eQsKImvJapLeSbXpwF4e4="); code != 2 {
+		t.Errorf("expected exit code 2 for -new-key with configured keys, got %d", code)
+	}
+
+	code, stdout, stderr := runCmd(t, dbPath, "rotate-key")
+	if code != 0 {
+		t.Fatalf("rotate-key failed: %s", stderr)
+	}
+	if !strings.Contains(stdout, "no IBKR flex token stored") {
+		t.Errorf("unexpected output %q", stdout)
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(dbPath), ".ibkr_encryption_key")); !os.IsNotExist(err) {
+		t.Error("expected no key file with configured keys")
+	}
+}
@@ -2,28 +2,22 @@ package main
 
 import (
 	"context"
-	"database/sql"
-	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
-	"path/filepath"
-	"strings"
 	"syscall"
 	"time"
 
-	"github.com/fernet/fernet-go"
 	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/api"
 	custommiddleware "github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/api/middleware"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/app"
 	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/config"
 	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/database"
-	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/ibkr"
 	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/logging"
-	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/repository"
-	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/service"
-	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/yahoo"
-	"github.com/robfig/cron/v3"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/metrics"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/model"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/tracing"
 )
 
 var syslog = logging.NewLogger("system")
@@ -37,14 +31,14 @@ func main() {
 	if err := database.EnsureDir(cfg.Database.Path); err != nil {
 		log.Fatalf("Failed to ensure database directory: %v", err)
 	}
-	db, err := database.Open(cfg.Database.Path)
+	db, err := database.OpenWithConfig(cfg.Database)
 	if err != nil {
 		log.Fatalf("Failed to open database: %v", err)
 	}
 	defer db.Close()
 
 	// Initialize structured logging (reads config from DB if available).
-	logHandler := logging.Init(db)
+	logHandler := logging.Init(db, cfg.Log)
 	defer logHandler.Close() // Flush remaining log entries before db.Close() (LIFO).
 
 	if err := database.Migrate(db); err != nil {
@@ -52,35 +46,68 @@ func main() {
 	}
 
 	syslog.Info("connected to database", "path", cfg.Database.Path)
+	if cfg.File != "" {
+		syslog.Info("loaded config file", "path", cfg.File)
+	}
+
+	shutdownTracing, err := tracing.Init(context.Background(), cfg.Tracing)
+	if err != nil {
+		log.Fatalf("Failed to initialize tracing: %v", err)
+	}
+	if cfg.Tracing.Exporter != tracing.ExporterNone {
+		syslog.Info("tracing enabled", "exporter", cfg.Tracing.Exporter, "sample_ratio", cfg.Tracing.SampleRatio)
+	}
 
 	// Resolve encryption key (env → file → auto-generate)
-	dataDir := filepath.Dir(cfg.Database.Path)
-	encKeyStr, err := resolveEncryptionKey(cfg.EncryptionKey, dataDir)
+	fernetKeys, err := app.LoadEncryptionKeys(cfg.EncryptionKeys, cfg.Database.Path)
 	if err != nil {
 		log.Fatalf("Failed to resolve encryption key: %v", err)
 	}
 
-	var fernetKey *fernet.Key
-	if encKeyStr != "" {
-		fernetKey, err = fernet.DecodeKey(encKeyStr)
-		if err != nil {
-			log.Fatalf("Invalid IBKR_ENCRYPTION_KEY: %v", err)
-		}
+	services := app.NewServices(db, fernetKeys, cfg)
+	materializedService := services.Materialized
+	jobService := services.Job
+	services.Developer.SetLogHandler(logHandler)
+
+	// Wire long-lived state into the Prometheus registry.
+	metrics.RegisterDB(db)
+	metrics.RegisterLogQueue(logHandler)
+	metrics.RegisterRegenQueue(materializedService.RegenQueueLength)
+
+	// Jobs run in-process, so anything still pending or running was cut off by the last shutdown.
+	if _, err := jobService.FailInterruptedJobs(context.Background()); err != nil {
+		syslog.Error("failed to mark interrupted jobs", "error", err)
 	}
 
-	systemService, portfolioService, fundService, materializedService, dividendService, transactionService, ibkrService, developerService := createRepoAndServices(db, fernetKey)
-	developerService.SetLogHandler(logHandler)
+	// Catch up on investment plan runs that fell due while the server was down.
+	if _, err := jobService.Submit(context.Background(), model.JobTypeInvestmentPlans, nil); err != nil {
+		syslog.Error("failed to submit investment plan catch-up", "error", err)
+	}
 
 	// Create router
 	router := api.NewRouter(
-		systemService,
-		portfolioService,
-		fundService,
-		materializedService,
-		dividendService,
-		transactionService,
-		ibkrService,
-		developerService,
+		services.System,
+		services.Portfolio,
+		services.Fund,
+		services.Materialized,
+		services.Dividend,
+		services.Transaction,
+		services.Ibkr,
+		services.Inbox,
+		services.Audit,
+		services.Trash,
+		services.Developer,
+		services.Auth,
+		services.Job,
+		services.Scheduler,
+		services.Webhook,
+		services.Digest,
+		services.Alert,
+		services.Allocation,
+		services.InvestmentPlan,
+		services.DividendIncome,
+		services.WithholdingTax,
+		services.TaxReport,
 		cfg,
 	)
 
@@ -91,9 +118,9 @@ func main() {
 	server := &http.Server{
 		Addr:         cfg.Server.Addr,
 		Handler:      router,
-		ReadTimeout:  15 * time.Second,
-		WriteTimeout: 15 * time.Second,
-		IdleTimeout:  60 * time.Second,
+		ReadTimeout:  time.Duration(cfg.Server.ReadTimeout),
+		WriteTimeout: time.Duration(cfg.Server.WriteTimeout),
+		IdleTimeout:  time.Duration(cfg.Server.IdleTimeout),
 	}
 
 	// Start server in a goroutine
@@ -104,7 +131,25 @@ func main() {
 		}
 	}()
 
-	c := scheduleTasks(fundService, ibkrService)
+	if err := services.Scheduler.Start(); err != nil {
+		log.Fatalf("Failed to start scheduled tasks: %v", err)
+	}
+
+	// Drain the persistent materialized regeneration queue, including anything left over
+	// from before a restart.
+	workerCtx, stopWorker := context.WithCancel(context.Background())
+	workerDone := make(chan struct{})
+	go func() {
+		materializedService.RunRegenWorker(workerCtx)
+		close(workerDone)
+	}()
+
+	// Send queued webhook deliveries, including retries left over from before a restart.
+	webhookDone := make(chan struct{})
+	go func() {
+		services.Webhook.RunDeliveryWorker(workerCtx)
+		close(webhookDone)
+	}()
 
 	// Wait for interrupt signal for graceful shutdown
 	quit := make(chan os.Signal, 1)
@@ -112,12 +157,13 @@ func main() {
 	<-quit
 	syslog.Info("shutting down")
 
-	// Stop accepting new HTTP requests + stop scheduling new cron jobs
-	cronCtx := c.Stop()
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	// Stop accepting new HTTP requests + stop scheduling new cron jobs and regenerations
+	cronCtx := services.Scheduler.Stop()
+	stopWorker()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.Server.ShutdownTimeout))
 	defer cancel()
 
-	// Drain both in parallel under the same 30s window
+	// Drain both in parallel under the same shutdown window
 	var shutdownErr bool
 	if err := server.Shutdown(ctx); err != nil {
 		syslog.Error("server shutdown error", "error", err)
@@ -132,193 +178,32 @@ func main() {
 		shutdownErr = true
 	}
 
-	if shutdownErr {
-		log.Fatalf("Shutdown completed with errors")
-	}
-	syslog.Info("server exited")
-}
-
-func scheduleTasks(fundService *service.FundService, ibkrService *service.IbkrService) *cron.Cron {
-	c := cron.New(
-		cron.WithLocation(time.UTC),
-		cron.WithChain(
-			cron.SkipIfStillRunning(cron.DefaultLogger),
-			cron.Recover(cron.DefaultLogger),
-		),
-	)
-	// Schedule the price update task to run at 00:55 UTC every weekday
-	_, err := c.AddFunc("55 00 * * 1-5", func() {
-		syslog.Info("starting scheduled fund price update")
-		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Minute)
-		defer cancel()
-		if _, err := fundService.UpdateAllFundHistory(ctx); err != nil {
-			syslog.Error("scheduled fund price update failed", "error", err)
-		}
-	})
-	if err != nil {
-		log.Fatalf("Failed to register fund price update task: %v", err)
-	}
-	// Schedule the IBKR Import task to run between 05:30 and 07:30 UTC Tue-Sat
-	// Fetches previous business day's close-of-business report
-	_, err = c.AddFunc("30 5-7 * * 2-6", func() {
-		syslog.Info("starting scheduled IBKR import")
-		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Minute)
-		defer cancel()
-		if _, _, err := ibkrService.ImportFlexReport(ctx); err != nil {
-			syslog.Error("scheduled IBKR import failed", "error", err)
-		}
-	})
-	if err != nil {
-		log.Fatalf("Failed to register IBKR import task: %v", err)
+	select {
+	case <-workerDone:
+	case <-ctx.Done():
+		syslog.Warn("regeneration worker did not stop in time")
+		shutdownErr = true
 	}
-	c.Start()
-	return c
-}
 
-// resolveEncryptionKey returns the encryption key string from env, file, or auto-generation.
-// Priority: env var > file > generate-and-write.
-func resolveEncryptionKey(cfgKey, dataDir string) (string, error) {
-	// 1. Env var already loaded into cfgKey
-	if cfgKey != "" {
-		return cfgKey, nil
+	select {
+	case <-webhookDone:
+	case <-ctx.Done():
+		syslog.Warn("webhook delivery worker did not stop in time")
+		shutdownErr = true
 	}
 
-	// 2. Try file
-	keyPath := filepath.Join(dataDir, ".ibkr_encryption_key")
-	data, err := os.ReadFile(keyPath)
-	if err == nil {
-		key := strings.TrimSpace(string(data))
-		if key != "" {
-			return key, nil
-		}
+	if err := jobService.Wait(ctx); err != nil {
+		syslog.Warn("background jobs did not finish in time")
+		shutdownErr = true
 	}
 
-	// 3. Generate new key and write to file
-	var k fernet.Key
-	if err := k.Generate(); err != nil {
-		return "", fmt.Errorf("generate encryption key: %w", err)
+	if err := shutdownTracing(ctx); err != nil {
+		syslog.Error("tracing shutdown error", "error", err)
+		shutdownErr = true
 	}
-	encoded := k.Encode()
 
-	if err := os.WriteFile(keyPath, []byte(encoded+"\n"), 0600); err != nil {
-		return "", fmt.Errorf("write encryption key to %s: %w", keyPath, err)
+	if shutdownErr {
+		log.Fatalf("Shutdown completed with errors")
 	}
-	syslog.Info("generated new IBKR encryption key", "path", keyPath)
-
-	return encoded, nil
-}
-
-//nolint:funlen // Wiring function that creates all repos and services; splitting would obscure the dependency graph.
-func createRepoAndServices(db *sql.DB, fernetKey *fernet.Key) (
-	*service.SystemService,
-	*service.PortfolioService,
-	*service.FundService,
-	*service.MaterializedService,
-	*service.DividendService,
-	*service.TransactionService,
-	*service.IbkrService,
-	*service.DeveloperService,
-) {
-	// Create repositories
-	portfolioRepo := repository.NewPortfolioRepository(db)
-	transactionRepo := repository.NewTransactionRepository(db)
-	fundRepo := repository.NewFundRepository(db)
-	pfRepo := repository.NewPortfolioFundRepository(db)
-	dividendRepo := repository.NewDividendRepository(db)
-	realizedGainLossRepo := repository.NewRealizedGainLossRepository(db)
-	materializedRepo := repository.NewMaterializedRepository(db)
-	ibkrRepo := repository.NewIbkrRepository(db)
-	developerRepo := repository.NewDeveloperRepository(db)
-
-	// Create services
-	systemService := service.NewSystemService(db)
-
-	// Create web clients
-	yahooClient := yahoo.NewFinanceClient()
-	ibkrClient := ibkr.NewFinanceClient()
-
-	developerService := service.NewDeveloperService(
-		db,
-		developerRepo,
-		fundRepo,
-		transactionRepo,
-		pfRepo,
-	)
-
-	realizedGainLossService := service.NewRealizedGainLossService(
-		realizedGainLossRepo,
-	)
-	transactionService := service.NewTransactionService(
-		db,
-		transactionRepo,
-		pfRepo,
-		realizedGainLossRepo,
-		ibkrRepo,
-	)
-	dividendService := service.NewDividendService(
-		db,
-		dividendRepo,
-		pfRepo,
-		transactionRepo,
-	)
-	portfolioService := service.NewPortfolioService(
-		db,
-		portfolioRepo,
-		pfRepo,
-	)
-	dataloaderService := service.NewDataLoaderService(
-		service.DataLoaderWithPortfolioFundRepository(pfRepo),
-		service.DataLoaderWithFundRepository(fundRepo),
-		service.DataLoaderWithTransactionService(transactionService),
-		service.DataLoaderWithDividendService(dividendService),
-		service.DataLoaderWithRealizedGainLossService(realizedGainLossService),
-	)
-	fundService := service.NewFundService(
-		db,
-		service.FundWithFundRepo(fundRepo),
-		service.FundWithPortfolioFundRepo(pfRepo),
-		service.FundWithTransactionService(transactionService),
-		service.FundWithDividendService(dividendService),
-		service.FundWithRealizedGainLossService(realizedGainLossService),
-		service.FundWithDataLoaderService(dataloaderService),
-		service.FundWithPortfolioRepo(portfolioRepo),
-		service.FundWithYahooClient(yahooClient),
-	)
-	ibkrService := service.NewIbkrService(
-		db,
-		service.IbkrWithIbkrRepo(ibkrRepo),
-		service.IbkrWithPortfolioRepo(portfolioRepo),
-		service.IbkrWithFundRepo(fundRepo),
-		service.IbkrWithDeveloperRepo(developerRepo),
-		service.IbkrWithClient(ibkrClient),
-		service.IbkrWithPortfolioFundRepo(pfRepo),
-		service.IbkrWithTransactionRepo(transactionRepo),
-		service.IbkrWithDividendRepo(dividendRepo),
-		service.IbkrWithEncryptionKey(fernetKey),
-	)
-	materializedService := service.NewMaterializedService(db,
-		service.MaterializedWithMaterializedRepository(materializedRepo),
-		service.MaterializedWithPortfolioRepository(portfolioRepo),
-		service.MaterializedWithFundRepository(fundRepo),
-		service.MaterializedWithFundService(fundService),
-		service.MaterializedWithDividendService(dividendService),
-		service.MaterializedWithRealizedGainLossService(realizedGainLossService),
-		service.MaterializedWithDataLoaderService(dataloaderService),
-		service.MaterializedWithPortfolioService(portfolioService),
-		service.MaterializedWithPortfolioFundRepository(pfRepo),
-	)
-	fundService.SetMaterializedInvalidator(materializedService)
-	transactionService.SetMaterializedInvalidator(materializedService)
-	dividendService.SetMaterializedInvalidator(materializedService)
-	ibkrService.SetMaterializedInvalidator(materializedService)
-	developerService.SetMaterializedInvalidator(materializedService)
-
-	return systemService,
-		portfolioService,
-		fundService,
-		materializedService,
-		dividendService,
-		transactionService,
-		ibkrService,
-		developerService
+	syslog.Info("server exited")
 }
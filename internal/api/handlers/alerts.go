@@ -0,0 +1,242 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/api/request"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/api/response"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/apperrors"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/service"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/validation"
+)
+
+// AlertHandler handles HTTP requests for alert rules and the alerts they trigger.
+type AlertHandler struct {
+	alertService *service.AlertService
+}
+
+// NewAlertHandler creates a new AlertHandler with the provided service dependency.
+func NewAlertHandler(alertService *service.AlertService) *AlertHandler {
+	return &AlertHandler{
+		alertService: alertService,
+	}
+}
+
+// GetAlertRules handles GET requests to list all alert rules.
+//
+// Endpoint: GET /api/alerts/rules
+// Response: 200 OK with array of AlertRule
+// Error: 500 Internal Server Error if retrieval fails
+func (h *AlertHandler) GetAlertRules(w http.ResponseWriter, r *http.Request) {
+	rules, err := h.alertService.GetAlertRules()
+	if err != nil {
+		sysLog.ErrorContext(r.Context(), "failed to get alert rules", "error", err)
+		response.RespondInternalError(w, r, apperrors.ErrFailedToRetrieveAlertRules.Error())
+		return
+	}
+
+	response.RespondJSON(w, http.StatusOK, rules)
+}
+
+// GetAlertRule handles GET requests to retrieve a single alert rule.
+//
+// Endpoint: GET /api/alerts/rules/{uuid}
+// Response: 200 OK with AlertRule
+// Error: 404 Not Found if the rule doesn't exist
+// Error: 500 Internal Server Error if retrieval fails
+func (h *AlertHandler) GetAlertRule(w http.ResponseWriter, r *http.Request) {
+	ruleID := chi.URLParam(r, "uuid")
+
+	rule, err := h.alertService.GetAlertRule(ruleID)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrAlertRuleNotFound) {
+			response.RespondError(w, http.StatusNotFound, apperrors.ErrAlertRuleNotFound.Error(), "")
+			return
+		}
+		sysLog.ErrorContext(r.Context(), "failed to get alert rule", "error", err, "rule_id", ruleID)
+		response.RespondInternalError(w, r, apperrors.ErrFailedToRetrieveAlertRules.Error())
+		return
+	}
+
+	response.RespondJSON(w, http.StatusOK, rule)
+}
+
+// CreateAlertRule handles POST requests to create an alert rule.
+//
+// Endpoint: POST /api/alerts/rules
+// Request body: CreateAlertRuleRequest
+// Response: 201 Created with the AlertRule
+// Error: 400 Bad Request if the body is invalid
+// Error: 404 Not Found if the portfolio or fund doesn't exist
+// Error: 500 Internal Server Error if creation fails
+func (h *AlertHandler) CreateAlertRule(w http.ResponseWriter, r *http.Request) {
+	req, err := parseJSON[request.CreateAlertRuleRequest](r)
+	if err != nil {
+		response.RespondError(w, http.StatusBadRequest, "invalid request body", err.Error())
+		return
+	}
+
+	if err := validation.ValidateCreateAlertRule(req); err != nil {
+		response.RespondError(w, http.StatusBadRequest, "validation failed", err.Error())
+		return
+	}
+
+	rule, err := h.alertService.CreateAlertRule(r.Context(), req)
+	if err != nil {
+		if respondAlertTargetNotFound(w, err) {
+			return
+		}
+		sysLog.ErrorContext(r.Context(), "failed to create alert rule", "error", err)
+		response.RespondInternalError(w, r, apperrors.ErrFailedToCreateAlertRule.Error())
+		return
+	}
+
+	response.RespondJSON(w, http.StatusCreated, rule)
+}
+
+// UpdateAlertRule handles PUT requests to replace an alert rule. An open alert of the rule
+// is resolved.
+//
+// Endpoint: PUT /api/alerts/rules/{uuid}
+// Request body: UpdateAlertRuleRequest
+// Response: 200 OK with the updated AlertRule
+// Error: 400 Bad Request if the body is invalid
+// Error: 404 Not Found if the rule, portfolio or fund doesn't exist
+// Error: 500 Internal Server Error if the update fails
+func (h *AlertHandler) UpdateAlertRule(w http.ResponseWriter, r *http.Request) {
+	ruleID := chi.URLParam(r, "uuid")
+
+	req, err := parseJSON[request.UpdateAlertRuleRequest](r)
+	if err != nil {
+		response.RespondError(w, http.StatusBadRequest, "invalid request body", err.Error())
+		return
+	}
+
+	if err := validation.ValidateUpdateAlertRule(req); err != nil {
+		response.RespondError(w, http.StatusBadRequest, "validation failed", err.Error())
+		return
+	}
+
+	rule, err := h.alertService.UpdateAlertRule(r.Context(), ruleID, req)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrAlertRuleNotFound) {
+			response.RespondError(w, http.StatusNotFound, apperrors.ErrAlertRuleNotFound.Error(), "")
+			return
+		}
+		if respondAlertTargetNotFound(w, err) {
+			return
+		}
+		sysLog.ErrorContext(r.Context(), "failed to update alert rule", "error", err, "rule_id", ruleID)
+		response.RespondInternalError(w, r, apperrors.ErrFailedToUpdateAlertRule.Error())
+		return
+	}
+
+	response.RespondJSON(w, http.StatusOK, rule)
+}
+
+// DeleteAlertRule handles DELETE requests to remove an alert rule and its alerts.
+//
+// Endpoint: DELETE /api/alerts/rules/{uuid}
+// Response: 204 No Content on success
+// Error: 404 Not Found if the rule doesn't exist
+// Error: 500 Internal Server Error if the deletion fails
+func (h *AlertHandler) DeleteAlertRule(w http.ResponseWriter, r *http.Request) {
+	ruleID := chi.URLParam(r, "uuid")
+
+	if err := h.alertService.DeleteAlertRule(r.Context(), ruleID); err != nil {
+		if errors.Is(err, apperrors.ErrAlertRuleNotFound) {
+			response.RespondError(w, http.StatusNotFound, apperrors.ErrAlertRuleNotFound.Error(), "")
+			return
+		}
+		sysLog.ErrorContext(r.Context(), "failed to delete alert rule", "error", err, "rule_id", ruleID)
+		response.RespondInternalError(w, r, apperrors.ErrFailedToDeleteAlertRule.Error())
+		return
+	}
+
+	response.RespondJSON(w, http.StatusNoContent, nil)
+}
+
+// respondAlertTargetNotFound responds 404 when err reports a missing portfolio or fund and
+// reports whether it did.
+func respondAlertTargetNotFound(w http.ResponseWriter, err error) bool {
+	for _, target := range []error{apperrors.ErrPortfolioNotFound, apperrors.ErrFundNotFound} {
+		if errors.Is(err, target) {
+			response.RespondError(w, http.StatusNotFound, target.Error(), "")
+			return true
+		}
+	}
+	return false
+}
+
+// GetAlerts handles GET requests to list the most recently triggered alerts.
+//
+// Endpoint: GET /api/alerts
+// Query parameters:
+//   - status: Comma-separated statuses (active, acknowledged, resolved); default all
+//   - limit: Number of alerts to return, 1-250 (default 50)
+//
+// Response: 200 OK with array of Alert, newest first
+// Error: 400 Bad Request if a parameter is invalid
+// Error: 500 Internal Server Error if retrieval fails
+func (h *AlertHandler) GetAlerts(w http.ResponseWriter, r *http.Request) {
+	statuses, limit, err := request.ParseAlertFilters(r.URL.Query().Get("status"), r.URL.Query().Get("limit"))
+	if err != nil {
+		response.RespondError(w, http.StatusBadRequest, "invalid query parameters", err.Error())
+		return
+	}
+
+	alerts, err := h.alertService.GetAlerts(statuses, limit)
+	if err != nil {
+		sysLog.ErrorContext(r.Context(), "failed to get alerts", "error", err)
+		response.RespondInternalError(w, r, apperrors.ErrFailedToRetrieveAlerts.Error())
+		return
+	}
+
+	response.RespondJSON(w, http.StatusOK, alerts)
+}
+
+// AcknowledgeAlert handles POST requests to acknowledge an active alert.
+//
+// Endpoint: POST /api/alerts/{uuid}/acknowledge
+// Response: 200 OK with the acknowledged Alert
+// Error: 404 Not Found if the alert doesn't exist
+// Error: 409 Conflict if the alert is already acknowledged or resolved
+// Error: 500 Internal Server Error if the update fails
+func (h *AlertHandler) AcknowledgeAlert(w http.ResponseWriter, r *http.Request) {
+	alertID := chi.URLParam(r, "uuid")
+
+	alert, err := h.alertService.AcknowledgeAlert(r.Context(), alertID)
+	if err != nil {
+		switch {
+		case errors.Is(err, apperrors.ErrAlertNotFound):
+			response.RespondError(w, http.StatusNotFound, apperrors.ErrAlertNotFound.Error(), "")
+		case errors.Is(err, apperrors.ErrAlertNotActive):
+			response.RespondError(w, http.StatusConflict, apperrors.ErrAlertNotActive.Error(), "")
+		default:
+			sysLog.ErrorContext(r.Context(), "failed to acknowledge alert", "error", err, "alert_id", alertID)
+			response.RespondInternalError(w, r, apperrors.ErrFailedToAcknowledgeAlert.Error())
+		}
+		return
+	}
+
+	response.RespondJSON(w, http.StatusOK, alert)
+}
+
+// EvaluateAlerts handles POST requests to evaluate every enabled alert rule right away,
+// instead of waiting for the next price update or regeneration.
+//
+// Endpoint: POST /api/alerts/evaluate
+// Response: 200 OK with AlertEvaluation
+// Error: 500 Internal Server Error if the evaluation fails
+func (h *AlertHandler) EvaluateAlerts(w http.ResponseWriter, r *http.Request) {
+	result, err := h.alertService.EvaluateAlerts(r.Context())
+	if err != nil {
+		sysLog.ErrorContext(r.Context(), "failed to evaluate alerts", "error", err)
+		response.RespondInternalError(w, r, apperrors.ErrFailedToEvaluateAlerts.Error())
+		return
+	}
+
+	response.RespondJSON(w, http.StatusOK, result)
+}
@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/api/request"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/model"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/service"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/testutil"
+)
+
+func setupAlertHandler(t *testing.T) (*AlertHandler, *service.AlertService, *sql.DB) {
+	t.Helper()
+	db := testutil.SetupTestDB(t)
+	svc := testutil.NewTestAlertService(t, db)
+	return NewAlertHandler(svc), svc, db
+}
+
+func TestAlertHandler_CreateAlertRule(t *testing.T) {
+	newRequest := func(body string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/api/alerts/rules", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		return req
+	}
+
+	t.Run("creates the rule", func(t *testing.T) {
+		handler, _, db := setupAlertHandler(t)
+		fund := testutil.NewFund().Build(t, db)
+		w := httptest.NewRecorder()
+
+		handler.CreateAlertRule(w, newRequest(`{"name":"Cheap","type":"fund_price","fundId":"`+fund.ID+`","direction":"below","threshold":50}`))
+
+		if w.Code != http.StatusCreated {
+			t.Fatalf("Expected 201, got %d: %s", w.Code, w.Body.String())
+		}
+		var response model.AlertRule
+		//nolint:errcheck // Test assertion - decode failure would cause test to fail anyway
+		json.NewDecoder(w.Body).Decode(&response)
+		if response.ID == "" || !response.Enabled || response.Threshold != 50 {
+			t.Errorf("Expected an enabled rule, got %+v", response)
+		}
+	})
+
+	t.Run("missing direction returns 400", func(t *testing.T) {
+		handler, _, db := setupAlertHandler(t)
+		fund := testutil.NewFund().Build(t, db)
+		w := httptest.NewRecorder()
+
+		handler.CreateAlertRule(w, newRequest(`{"name":"Cheap","type":"fund_price","fundId":"`+fund.ID+`","threshold":50}`))
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected 400, got %d", w.Code)
+		}
+	})
+
+	t.Run("unknown portfolio returns 404", func(t *testing.T) {
+		handler, _, _ := setupAlertHandler(t)
+		w := httptest.NewRecorder()
+
+		handler.CreateAlertRule(w, newRequest(`{"name":"Floor","type":"portfolio_value","portfolioId":"`+testutil.MakeID()+`","threshold":1000}`))
+
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Expected 404, got %d", w.Code)
+		}
+	})
+}
+
+func TestAlertHandler_AcknowledgeAlert(t *testing.T) {
+	handler, svc, db := setupAlertHandler(t)
+	fund := testutil.NewFund().Build(t, db)
+	testutil.NewFundPrice(fund.ID).WithDate(time.Now().UTC().Truncate(24*time.Hour)).WithPrice(10).Build(t, db)
+	threshold := 20.0
+	if _, err := svc.CreateAlertRule(context.Background(), request.CreateAlertRuleRequest{
+		Name: "Below 20", Type: "fund_price", FundID: fund.ID, Direction: "below", Threshold: &threshold,
+	}); err != nil {
+		t.Fatalf("CreateAlertRule: %v", err)
+	}
+	if _, err := svc.EvaluateFundAlerts(context.Background()); err != nil {
+		t.Fatalf("EvaluateFundAlerts: %v", err)
+	}
+	alerts, err := svc.GetAlerts(nil, 50)
+	if err != nil || len(alerts) != 1 {
+		t.Fatalf("expected one alert, got %+v (%v)", alerts, err)
+	}
+	acknowledge := func() *httptest.ResponseRecorder {
+		req := testutil.NewRequestWithURLParams(http.MethodPost, "/api/alerts/"+alerts[0].ID+"/acknowledge", map[string]string{"uuid": alerts[0].ID})
+		w := httptest.NewRecorder()
+		handler.AcknowledgeAlert(w, req)
+		return w
+	}
+
+	if w := acknowledge(); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"status":"acknowledged"`) {
+		t.Errorf("Expected 200 with the acknowledged alert, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := acknowledge(); w.Code != http.StatusConflict {
+		t.Errorf("Expected 409 for an acknowledged alert, got %d", w.Code)
+	}
+}
+
+func TestAlertHandler_GetAlerts(t *testing.T) {
+	t.Run("invalid status returns 400", func(t *testing.T) {
+		handler, _, _ := setupAlertHandler(t)
+		w := httptest.NewRecorder()
+
+		handler.GetAlerts(w, httptest.NewRequest(http.MethodGet, "/api/alerts?status=open", nil))
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected 400, got %d", w.Code)
+		}
+	})
+
+	t.Run("empty list", func(t *testing.T) {
+		handler, _, _ := setupAlertHandler(t)
+		w := httptest.NewRecorder()
+
+		handler.GetAlerts(w, httptest.NewRequest(http.MethodGet, "/api/alerts?status=active,acknowledged", nil))
+
+		if w.Code != http.StatusOK || strings.TrimSpace(w.Body.String()) != "[]" {
+			t.Errorf("Expected 200 with an empty list, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+}
@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/api/request"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/api/response"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/apperrors"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/service"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/validation"
+)
+
+// AllocationHandler handles HTTP requests for portfolio allocation targets and rebalancing.
+type AllocationHandler struct {
+	allocationService *service.AllocationService
+}
+
+// NewAllocationHandler creates a new AllocationHandler with the provided service dependency.
+func NewAllocationHandler(allocationService *service.AllocationService) *AllocationHandler {
+	return &AllocationHandler{
+		allocationService: allocationService,
+	}
+}
+
+// GetAllocationTargets handles GET requests to retrieve the allocation targets of a portfolio.
+//
+// Endpoint: GET /api/portfolio/{uuid}/allocation
+// Response: 200 OK with array of AllocationTarget
+// Error: 404 Not Found if the portfolio doesn't exist
+// Error: 500 Internal Server Error if retrieval fails
+func (h *AllocationHandler) GetAllocationTargets(w http.ResponseWriter, r *http.Request) {
+	portfolioID := chi.URLParam(r, "uuid")
+
+	targets, err := h.allocationService.GetAllocationTargets(portfolioID)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrPortfolioNotFound) {
+			response.RespondError(w, http.StatusNotFound, apperrors.ErrPortfolioNotFound.Error(), "")
+			return
+		}
+		pfLog.ErrorContext(r.Context(), "failed to get allocation targets", "error", err, "portfolio_id", portfolioID)
+		response.RespondInternalError(w, r, apperrors.ErrFailedToRetrieveAllocationTargets.Error())
+		return
+	}
+
+	response.RespondJSON(w, http.StatusOK, targets)
+}
+
+// SetAllocationTargets handles PUT requests to replace the allocation targets of a portfolio.
+// Targets are set either all per fund or all per asset class and add up to 100 percent;
+// an empty list removes them.
+//
+// Endpoint: PUT /api/portfolio/{uuid}/allocation
+// Request body: SetAllocationTargetsRequest
+// Response: 200 OK with array of AllocationTarget
+// Error: 400 Bad Request if the body is invalid or a fund is not part of the portfolio
+// Error: 404 Not Found if the portfolio doesn't exist
+// Error: 500 Internal Server Error if the update fails
+func (h *AllocationHandler) SetAllocationTargets(w http.ResponseWriter, r *http.Request) {
+	portfolioID := chi.URLParam(r, "uuid")
+
+	req, err := parseJSON[request.SetAllocationTargetsRequest](r)
+	if err != nil {
+		response.RespondError(w, http.StatusBadRequest, "invalid request body", err.Error())
+		return
+	}
+
+	if err := validation.ValidateSetAllocationTargets(req); err != nil {
+		response.RespondError(w, http.StatusBadRequest, "validation failed", err.Error())
+		return
+	}
+
+	targets, err := h.allocationService.SetAllocationTargets(r.Context(), portfolioID, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, apperrors.ErrPortfolioNotFound):
+			response.RespondError(w, http.StatusNotFound, apperrors.ErrPortfolioNotFound.Error(), "")
+		case errors.Is(err, apperrors.ErrFundNotInPortfolio):
+			response.RespondError(w, http.StatusBadRequest, "validation failed", err.Error())
+		default:
+			pfLog.ErrorContext(r.Context(), "failed to set allocation targets", "error", err, "portfolio_id", portfolioID)
+			response.RespondInternalError(w, r, apperrors.ErrFailedToUpdateAllocationTargets.Error())
+		}
+		return
+	}
+
+	response.RespondJSON(w, http.StatusOK, targets)
+}
+
+// GetRebalancePlan handles GET requests to compare a portfolio with its allocation targets
+// and suggest the trades that rebalance it at the latest fund prices.
+//
+// Endpoint: GET /api/portfolio/{uuid}/rebalance
+// Query parameters:
+//   - mode: full (buy and sell, default) or new_money (only invest the contribution)
+//   - contribution: Amount of new money to invest; required for new_money (default 0)
+//
+// Response: 200 OK with RebalancePlan
+// Error: 400 Bad Request if a parameter is invalid
+// Error: 404 Not Found if the portfolio doesn't exist
+// Error: 409 Conflict if the portfolio has no allocation targets
+// Error: 500 Internal Server Error if the calculation fails
+func (h *AllocationHandler) GetRebalancePlan(w http.ResponseWriter, r *http.Request) {
+	portfolioID := chi.URLParam(r, "uuid")
+
+	mode, contribution, err := request.ParseRebalanceParams(r.URL.Query().Get("mode"), r.URL.Query().Get("contribution"))
+	if err != nil {
+		response.RespondError(w, http.StatusBadRequest, "invalid query parameters", err.Error())
+		return
+	}
+
+	plan, err := h.allocationService.GetRebalancePlan(r.Context(), portfolioID, mode, contribution)
+	if err != nil {
+		switch {
+		case errors.Is(err, apperrors.ErrPortfolioNotFound):
+			response.RespondError(w, http.StatusNotFound, apperrors.ErrPortfolioNotFound.Error(), "")
+		case errors.Is(err, apperrors.ErrNoAllocationTargets):
+			response.RespondError(w, http.StatusConflict, apperrors.ErrNoAllocationTargets.Error(), "")
+		default:
+			pfLog.ErrorContext(r.Context(), "failed to calculate rebalance plan", "error", err, "portfolio_id", portfolioID)
+			response.RespondInternalError(w, r, apperrors.ErrFailedToCalculateRebalance.Error())
+		}
+		return
+	}
+
+	response.RespondJSON(w, http.StatusOK, plan)
+}
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/model"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/testutil"
+)
+
+func TestAllocationHandler(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	handler := NewAllocationHandler(testutil.NewTestAllocationService(t, db))
+	portfolio := testutil.NewPortfolio().Build(t, db)
+	fund := testutil.NewFund().Build(t, db)
+	pf := testutil.NewPortfolioFund(portfolio.ID, fund.ID).Build(t, db)
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	testutil.NewTransaction(pf.ID).WithDate(today).WithShares(10).WithCostPerShare(10).Build(t, db)
+	testutil.NewFundPrice(fund.ID).WithDate(today).WithPrice(10).Build(t, db)
+
+	rebalance := func(portfolioID, query string) *httptest.ResponseRecorder {
+		req := testutil.NewRequestWithURLParams(http.MethodGet, "/api/portfolio/"+portfolioID+"/rebalance"+query, map[string]string{"uuid": portfolioID})
+		w := httptest.NewRecorder()
+		handler.GetRebalancePlan(w, req)
+		return w
+	}
+	setTargets := func(portfolioID, body string) *httptest.ResponseRecorder {
+		req := testutil.NewRequestWithURLParamsAndBody(http.MethodPut, "/api/portfolio/"+portfolioID+"/allocation", map[string]string{"uuid": portfolioID}, body)
+		w := httptest.NewRecorder()
+		handler.SetAllocationTargets(w, req)
+		return w
+	}
+
+	t.Run("rebalance without targets returns 409", func(t *testing.T) {
+		if w := rebalance(portfolio.ID, ""); w.Code != http.StatusConflict {
+			t.Errorf("Expected 409, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("targets that do not add up return 400", func(t *testing.T) {
+		if w := setTargets(portfolio.ID, `{"targets":[{"fundId":"`+fund.ID+`","targetPercent":90}]}`); w.Code != http.StatusBadRequest {
+			t.Errorf("Expected 400, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("sets targets and returns a plan", func(t *testing.T) {
+		if w := setTargets(portfolio.ID, `{"targets":[{"fundId":"`+fund.ID+`","targetPercent":100,"tolerancePercent":5}]}`); w.Code != http.StatusOK {
+			t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+
+		w := rebalance(portfolio.ID, "?mode=new_money&contribution=50")
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+		var plan model.RebalancePlan
+		//nolint:errcheck // Test assertion - decode failure would cause test to fail anyway
+		json.NewDecoder(w.Body).Decode(&plan)
+		if plan.CurrentValue != 100 || len(plan.Trades) != 1 || plan.Trades[0].Shares != 5 {
+			t.Errorf("Expected a buy of 5 shares, got %+v", plan)
+		}
+	})
+
+	t.Run("new money without contribution returns 400", func(t *testing.T) {
+		if w := rebalance(portfolio.ID, "?mode=new_money"); w.Code != http.StatusBadRequest {
+			t.Errorf("Expected 400, got %d", w.Code)
+		}
+	})
+
+	t.Run("unknown portfolio returns 404", func(t *testing.T) {
+		id := testutil.MakeID()
+		if w := rebalance(id, ""); w.Code != http.StatusNotFound {
+			t.Errorf("Expected 404, got %d", w.Code)
+		}
+	})
+}
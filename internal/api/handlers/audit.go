@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/api/request"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/api/response"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/apperrors"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/logging"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/service"
+)
+
+var auditLog = logging.NewLogger("security")
+
+// AuditHandler handles HTTP requests for the audit trail.
+type AuditHandler struct {
+	auditService *service.AuditService
+}
+
+// NewAuditHandler creates a new AuditHandler with the provided service dependency.
+func NewAuditHandler(auditService *service.AuditService) *AuditHandler {
+	return &AuditHandler{
+		auditService: auditService,
+	}
+}
+
+// GetAuditEvents handles GET requests to retrieve audit events with filtering and pagination.
+// Uses the same cursor-based pagination as the log viewer.
+//
+// Query Parameters:
+//   - entityType: Comma-separated entity types (portfolio, fund, transaction, etc.)
+//   - entityId: Exact ID of the audited record
+//   - action: Comma-separated actions (create, update, delete, allocate, etc.)
+//   - requestId: Request ID, to list everything changed by one request
+//   - userId: User ID, to list everything changed by one user
+//   - startDate: Filter events from this date (YYYY-MM-DD or RFC3339)
+//   - endDate: Filter events until this date (YYYY-MM-DD or RFC3339)
+//   - sortDir: Sort direction (asc or desc, default: desc)
+//   - cursor: Pagination cursor from previous response
+//   - perPage: Number of results per page (1-250, default: 50)
+//
+// Endpoint: GET /api/audit
+// Response: 200 OK with AuditEventResponse
+// Error: 400 Bad Request if filter parameters are invalid
+// Error: 500 Internal Server Error if retrieval fails
+func (h *AuditHandler) GetAuditEvents(w http.ResponseWriter, r *http.Request) {
+	auditLog.DebugContext(r.Context(), "get audit events request",
+		"filter_entity_type", r.URL.Query().Get("entityType"),
+		"filter_entity_id", r.URL.Query().Get("entityId"),
+		"filter_action", r.URL.Query().Get("action"),
+	)
+
+	filters, err := request.ParseAuditFilters(
+		r.URL.Query().Get("entityType"),
+		r.URL.Query().Get("entityId"),
+		r.URL.Query().Get("action"),
+		r.URL.Query().Get("requestId"),
+		r.URL.Query().Get("userId"),
+		r.URL.Query().Get("startDate"),
+		r.URL.Query().Get("endDate"),
+		r.URL.Query().Get("sortDir"),
+		r.URL.Query().Get("cursor"),
+		r.URL.Query().Get("perPage"),
+	)
+	if err != nil {
+		response.RespondError(w, http.StatusBadRequest, "Invalid filter parameters", err.Error())
+		return
+	}
+
+	events, err := h.auditService.GetAuditEvents(filters)
+	if err != nil {
+		auditLog.ErrorContext(r.Context(), "failed to get audit events", "error", err)
+		response.RespondInternalError(w, r, apperrors.ErrFailedToRetrieveAuditEvents.Error())
+		return
+	}
+
+	response.RespondJSON(w, http.StatusOK, events)
+}
@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/api/request"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/model"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/testutil"
+)
+
+func setupAuditHandler(t *testing.T) (*AuditHandler, *sql.DB) {
+	t.Helper()
+	db := testutil.SetupTestDB(t)
+	return NewAuditHandler(testutil.NewTestAuditService(t, db)), db
+}
+
+func TestAuditHandler_GetAuditEvents(t *testing.T) {
+	t.Run("returns events filtered by entity type", func(t *testing.T) {
+		handler, db := setupAuditHandler(t)
+		pfSvc := testutil.NewTestPortfolioService(t, db)
+
+		if _, err := pfSvc.CreatePortfolio(context.Background(), request.CreatePortfolioRequest{Name: "Audited"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		req := testutil.NewRequestWithQueryParams(http.MethodGet, "/api/audit", map[string]string{
+			"entityType": "portfolio",
+			"action":     "create",
+		})
+		w := httptest.NewRecorder()
+
+		handler.GetAuditEvents(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+
+		var response model.AuditEventResponse
+		//nolint:errcheck // Test assertion - decode failure would cause test to fail anyway
+		json.NewDecoder(w.Body).Decode(&response)
+
+		if response.Count != 1 || len(response.Events) != 1 {
+			t.Fatalf("Expected 1 event, got %d", response.Count)
+		}
+		if response.Events[0].EntityType != "portfolio" || response.Events[0].Action != "create" {
+			t.Errorf("Unexpected event: %+v", response.Events[0])
+		}
+	})
+
+	t.Run("returns empty list when nothing matches", func(t *testing.T) {
+		handler, _ := setupAuditHandler(t)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/audit", nil)
+		w := httptest.NewRecorder()
+
+		handler.GetAuditEvents(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+
+		var response model.AuditEventResponse
+		//nolint:errcheck // Test assertion - decode failure would cause test to fail anyway
+		json.NewDecoder(w.Body).Decode(&response)
+
+		if response.Events == nil || response.Count != 0 || response.HasMore {
+			t.Errorf("Expected empty, non-null result, got %+v", response)
+		}
+	})
+
+	t.Run("invalid filter returns 400", func(t *testing.T) {
+		handler, _ := setupAuditHandler(t)
+
+		req := testutil.NewRequestWithQueryParams(http.MethodGet, "/api/audit", map[string]string{"action": "explode"})
+		w := httptest.NewRecorder()
+
+		handler.GetAuditEvents(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected 400, got %d", w.Code)
+		}
+	})
+
+	t.Run("database error returns 500", func(t *testing.T) {
+		handler, db := setupAuditHandler(t)
+		db.Close()
+
+		req := httptest.NewRequest(http.MethodGet, "/api/audit", nil)
+		w := httptest.NewRecorder()
+
+		handler.GetAuditEvents(w, req)
+
+		if w.Code != http.StatusInternalServerError {
+			t.Errorf("Expected 500, got %d", w.Code)
+		}
+	})
+}
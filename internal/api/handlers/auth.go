@@ -0,0 +1,492 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	custommiddleware "github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/api/middleware"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/api/request"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/api/response"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/apperrors"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/auth"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/logging"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/model"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/service"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/validation"
+)
+
+var authLog = logging.NewLogger("security")
+
+// AuthHandler handles HTTP requests for login sessions, API tokens, user accounts and portfolio sharing.
+type AuthHandler struct {
+	authService      *service.AuthService
+	portfolioService *service.PortfolioService
+}
+
+// NewAuthHandler creates a new AuthHandler with the provided service dependencies.
+func NewAuthHandler(authService *service.AuthService, portfolioService *service.PortfolioService) *AuthHandler {
+	return &AuthHandler{
+		authService:      authService,
+		portfolioService: portfolioService,
+	}
+}
+
+// Status handles GET requests to report whether the API requires authentication.
+// Clients use it to decide between showing the setup form and the login form.
+//
+// Endpoint: GET /api/auth/status
+// Response: 200 OK with AuthStatus
+// Error: 500 Internal Server Error if the check fails
+func (h *AuthHandler) Status(w http.ResponseWriter, r *http.Request) {
+	enabled, err := h.authService.AuthEnabled()
+	if err != nil {
+		authLog.ErrorContext(r.Context(), "failed to get auth status", "error", err)
+		response.RespondInternalError(w, r, apperrors.ErrFailedToAuthenticate.Error())
+		return
+	}
+
+	response.RespondJSON(w, http.StatusOK, model.AuthStatus{AuthEnabled: enabled})
+}
+
+// Setup handles POST requests to create the first account. The account is an
+// administrator, takes ownership of all existing portfolios and is logged in.
+// After setup every other endpoint requires a bearer token.
+//
+// Endpoint: POST /api/auth/setup
+// Request Body: SetupRequest (username, password)
+// Response: 201 Created with LoginResponse
+// Error: 400 Bad Request if validation fails or request body is invalid
+// Error: 409 Conflict if an account already exists
+// Error: 500 Internal Server Error if setup fails
+func (h *AuthHandler) Setup(w http.ResponseWriter, r *http.Request) {
+	req, err := parseJSON[request.SetupRequest](r)
+	if err != nil {
+		response.RespondError(w, http.StatusBadRequest, "invalid request body", err.Error())
+		return
+	}
+
+	if err := validation.ValidateSetup(req); err != nil {
+		response.RespondError(w, http.StatusBadRequest, "validation failed", err.Error())
+		return
+	}
+
+	resp, err := h.authService.Setup(r.Context(), req)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrSetupAlreadyCompleted) {
+			response.RespondError(w, http.StatusConflict, apperrors.ErrSetupAlreadyCompleted.Error(), "")
+			return
+		}
+		authLog.ErrorContext(r.Context(), "failed to complete setup", "error", err)
+		response.RespondInternalError(w, r, apperrors.ErrFailedToCreateUser.Error())
+		return
+	}
+
+	response.RespondJSON(w, http.StatusCreated, resp)
+}
+
+// Login handles POST requests to log in with a username and password.
+//
+// Endpoint: POST /api/auth/login
+// Request Body: LoginRequest (username, password)
+// Response: 200 OK with LoginResponse; send the token as "Authorization: Bearer <token>"
+// Error: 400 Bad Request if request body is invalid
+// Error: 401 Unauthorized if the username or password is wrong
+// Error: 500 Internal Server Error if login fails
+func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
+	req, err := parseJSON[request.LoginRequest](r)
+	if err != nil {
+		response.RespondError(w, http.StatusBadRequest, "invalid request body", err.Error())
+		return
+	}
+
+	if err := validation.ValidateLogin(req); err != nil {
+		response.RespondError(w, http.StatusBadRequest, "validation failed", err.Error())
+		return
+	}
+
+	resp, err := h.authService.Login(r.Context(), req)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrInvalidCredentials) {
+			response.RespondError(w, http.StatusUnauthorized, apperrors.ErrInvalidCredentials.Error(), "")
+			return
+		}
+		authLog.ErrorContext(r.Context(), "failed to log in", "error", err)
+		response.RespondInternalError(w, r, apperrors.ErrFailedToAuthenticate.Error())
+		return
+	}
+
+	response.RespondJSON(w, http.StatusOK, resp)
+}
+
+// Logout handles POST requests to end the caller's session.
+//
+// Endpoint: POST /api/auth/logout
+// Response: 204 No Content
+// Error: 401 Unauthorized if the request is not authenticated
+// Error: 403 Forbidden if the request uses an API token
+// Error: 500 Internal Server Error if logout fails
+func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	if auth.PrincipalFromContext(r.Context()) == nil {
+		response.RespondError(w, http.StatusUnauthorized, apperrors.ErrAuthenticationRequired.Error(), "")
+		return
+	}
+
+	if err := h.authService.Logout(r.Context(), custommiddleware.BearerToken(r)); err != nil {
+		authLog.ErrorContext(r.Context(), "failed to log out", "error", err)
+		response.RespondInternalError(w, r, "failed to log out")
+		return
+	}
+
+	response.RespondJSON(w, http.StatusNoContent, nil)
+}
+
+// Me handles GET requests to retrieve the authenticated caller's account.
+//
+// Endpoint: GET /api/auth/me
+// Response: 200 OK with User
+// Error: 401 Unauthorized if the request is not authenticated
+func (h *AuthHandler) Me(w http.ResponseWriter, r *http.Request) {
+	p := auth.PrincipalFromContext(r.Context())
+	if p == nil {
+		response.RespondError(w, http.StatusUnauthorized, apperrors.ErrAuthenticationRequired.Error(), "")
+		return
+	}
+
+	response.RespondJSON(w, http.StatusOK, p.User)
+}
+
+// ChangePassword handles PUT requests to change the caller's password.
+// All other sessions of the caller are ended; the current one stays valid.
+//
+// Endpoint: PUT /api/auth/password
+// Request Body: ChangePasswordRequest (currentPassword, newPassword)
+// Response: 204 No Content
+// Error: 400 Bad Request if validation fails or request body is invalid
+// Error: 401 Unauthorized if the request is not authenticated or currentPassword is wrong
+// Error: 403 Forbidden if the request uses an API token
+// Error: 500 Internal Server Error if the change fails
+func (h *AuthHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	p := auth.PrincipalFromContext(r.Context())
+	if p == nil {
+		response.RespondError(w, http.StatusUnauthorized, apperrors.ErrAuthenticationRequired.Error(), "")
+		return
+	}
+
+	req, err := parseJSON[request.ChangePasswordRequest](r)
+	if err != nil {
+		response.RespondError(w, http.StatusBadRequest, "invalid request body", err.Error())
+		return
+	}
+
+	if err := validation.ValidateChangePassword(req); err != nil {
+		response.RespondError(w, http.StatusBadRequest, "validation failed", err.Error())
+		return
+	}
+
+	if err := h.authService.ChangePassword(r.Context(), p.User.ID, custommiddleware.BearerToken(r), req); err != nil {
+		if errors.Is(err, apperrors.ErrInvalidCredentials) {
+			response.RespondError(w, http.StatusUnauthorized, apperrors.ErrInvalidCredentials.Error(), "")
+			return
+		}
+		authLog.ErrorContext(r.Context(), "failed to change password", "error", err)
+		response.RespondInternalError(w, r, apperrors.ErrFailedToChangePassword.Error())
+		return
+	}
+
+	response.RespondJSON(w, http.StatusNoContent, nil)
+}
+
+// GetAPITokens handles GET requests to list the caller's personal API tokens,
+// including revoked and expired ones. The tokens themselves are never returned.
+//
+// Endpoint: GET /api/auth/tokens
+// Response: 200 OK with array of APIToken
+// Error: 401 Unauthorized if the request is not authenticated
+// Error: 403 Forbidden if the request uses an API token
+// Error: 500 Internal Server Error if retrieval fails
+func (h *AuthHandler) GetAPITokens(w http.ResponseWriter, r *http.Request) {
+	p := auth.PrincipalFromContext(r.Context())
+	if p == nil {
+		response.RespondError(w, http.StatusUnauthorized, apperrors.ErrAuthenticationRequired.Error(), "")
+		return
+	}
+
+	tokens, err := h.authService.GetAPITokens(p.User.ID)
+	if err != nil {
+		authLog.ErrorContext(r.Context(), "failed to get API tokens", "error", err)
+		response.RespondInternalError(w, r, apperrors.ErrFailedToRetrieveTokens.Error())
+		return
+	}
+
+	response.RespondJSON(w, http.StatusOK, tokens)
+}
+
+// CreateAPIToken handles POST requests to create a personal API token for the caller.
+//
+// Endpoint: POST /api/auth/tokens
+// Request Body: CreateAPITokenRequest (name, scopes, expiresInDays)
+// Response: 201 Created with CreateAPITokenResponse; the token is only shown here
+// Error: 400 Bad Request if validation fails or request body is invalid
+// Error: 401 Unauthorized if the request is not authenticated
+// Error: 403 Forbidden if the request uses an API token or a non-administrator requests an admin scope
+// Error: 500 Internal Server Error if creation fails
+func (h *AuthHandler) CreateAPIToken(w http.ResponseWriter, r *http.Request) {
+	p := auth.PrincipalFromContext(r.Context())
+	if p == nil {
+		response.RespondError(w, http.StatusUnauthorized, apperrors.ErrAuthenticationRequired.Error(), "")
+		return
+	}
+
+	req, err := parseJSON[request.CreateAPITokenRequest](r)
+	if err != nil {
+		response.RespondError(w, http.StatusBadRequest, "invalid request body", err.Error())
+		return
+	}
+
+	if err := validation.ValidateCreateAPIToken(req); err != nil {
+		response.RespondError(w, http.StatusBadRequest, "validation failed", err.Error())
+		return
+	}
+
+	resp, err := h.authService.CreateAPIToken(r.Context(), p.User, req)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrScopeNotAllowed) {
+			response.RespondError(w, http.StatusForbidden, apperrors.ErrScopeNotAllowed.Error(), err.Error())
+			return
+		}
+		authLog.ErrorContext(r.Context(), "failed to create API token", "error", err)
+		response.RespondInternalError(w, r, apperrors.ErrFailedToCreateToken.Error())
+		return
+	}
+
+	response.RespondJSON(w, http.StatusCreated, resp)
+}
+
+// RevokeAPIToken handles DELETE requests to revoke a personal API token. Users can revoke
+// their own tokens; administrators can revoke any token.
+//
+// Endpoint: DELETE /api/auth/tokens/{uuid}
+// Response: 204 No Content
+// Error: 401 Unauthorized if the request is not authenticated
+// Error: 403 Forbidden if the request uses an API token
+// Error: 404 Not Found if the token doesn't exist or belongs to another user
+// Error: 409 Conflict if the token was already revoked
+// Error: 500 Internal Server Error if revoking fails
+func (h *AuthHandler) RevokeAPIToken(w http.ResponseWriter, r *http.Request) {
+	p := auth.PrincipalFromContext(r.Context())
+	if p == nil {
+		response.RespondError(w, http.StatusUnauthorized, apperrors.ErrAuthenticationRequired.Error(), "")
+		return
+	}
+
+	tokenID := chi.URLParam(r, "uuid")
+
+	if err := h.authService.RevokeAPIToken(r.Context(), p.User, tokenID); err != nil {
+		switch {
+		case errors.Is(err, apperrors.ErrAPITokenNotFound):
+			response.RespondError(w, http.StatusNotFound, apperrors.ErrAPITokenNotFound.Error(), "")
+		case errors.Is(err, apperrors.ErrAPITokenRevoked):
+			response.RespondError(w, http.StatusConflict, apperrors.ErrAPITokenRevoked.Error(), "")
+		default:
+			authLog.ErrorContext(r.Context(), "failed to revoke API token", "error", err, "token_id", tokenID)
+			response.RespondInternalError(w, r, apperrors.ErrFailedToRevokeToken.Error())
+		}
+		return
+	}
+
+	response.RespondJSON(w, http.StatusNoContent, nil)
+}
+
+// GetUsers handles GET requests to list all accounts. Administrators only.
+//
+// Endpoint: GET /api/user
+// Response: 200 OK with array of User
+// Error: 500 Internal Server Error if retrieval fails
+func (h *AuthHandler) GetUsers(w http.ResponseWriter, r *http.Request) {
+	users, err := h.authService.GetUsers()
+	if err != nil {
+		authLog.ErrorContext(r.Context(), "failed to get users", "error", err)
+		response.RespondInternalError(w, r, apperrors.ErrFailedToRetrieveUsers.Error())
+		return
+	}
+
+	response.RespondJSON(w, http.StatusOK, users)
+}
+
+// CreateUser handles POST requests to create an account. Administrators only.
+//
+// Endpoint: POST /api/user
+// Request Body: CreateUserRequest (username, password, isAdmin)
+// Response: 201 Created with User
+// Error: 400 Bad Request if validation fails or request body is invalid
+// Error: 409 Conflict if the username is taken
+// Error: 500 Internal Server Error if creation fails
+func (h *AuthHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
+	req, err := parseJSON[request.CreateUserRequest](r)
+	if err != nil {
+		response.RespondError(w, http.StatusBadRequest, "invalid request body", err.Error())
+		return
+	}
+
+	if err := validation.ValidateCreateUser(req); err != nil {
+		response.RespondError(w, http.StatusBadRequest, "validation failed", err.Error())
+		return
+	}
+
+	user, err := h.authService.CreateUser(r.Context(), req)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrUsernameTaken) {
+			response.RespondError(w, http.StatusConflict, apperrors.ErrUsernameTaken.Error(), "")
+			return
+		}
+		authLog.ErrorContext(r.Context(), "failed to create user", "error", err)
+		response.RespondInternalError(w, r, apperrors.ErrFailedToCreateUser.Error())
+		return
+	}
+
+	response.RespondJSON(w, http.StatusCreated, user)
+}
+
+// DeleteUser handles DELETE requests to delete an account. Administrators only.
+// Portfolios owned by the deleted user are transferred to the calling administrator.
+//
+// Endpoint: DELETE /api/user/{uuid}
+// Response: 204 No Content
+// Error: 400 Bad Request if the caller tries to delete their own account
+// Error: 404 Not Found if the user doesn't exist
+// Error: 500 Internal Server Error if deletion fails
+func (h *AuthHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
+	userID := chi.URLParam(r, "uuid")
+
+	var actingUserID string
+	if p := auth.PrincipalFromContext(r.Context()); p != nil {
+		actingUserID = p.User.ID
+	}
+
+	if err := h.authService.DeleteUser(r.Context(), actingUserID, userID); err != nil {
+		switch {
+		case errors.Is(err, apperrors.ErrCannotDeleteSelf):
+			response.RespondError(w, http.StatusBadRequest, apperrors.ErrCannotDeleteSelf.Error(), "")
+		case errors.Is(err, apperrors.ErrUserNotFound):
+			response.RespondError(w, http.StatusNotFound, apperrors.ErrUserNotFound.Error(), "")
+		default:
+			authLog.ErrorContext(r.Context(), "failed to delete user", "error", err, "user_id", userID)
+			response.RespondInternalError(w, r, apperrors.ErrFailedToDeleteUser.Error())
+		}
+		return
+	}
+
+	response.RespondJSON(w, http.StatusNoContent, nil)
+}
+
+// GetPortfolioShares handles GET requests to list who a portfolio is shared with.
+// Portfolio owners and administrators only.
+//
+// Endpoint: GET /api/portfolio/{uuid}/shares
+// Response: 200 OK with array of PortfolioShare
+// Error: 404 Not Found if the portfolio doesn't exist
+// Error: 500 Internal Server Error if retrieval fails
+func (h *AuthHandler) GetPortfolioShares(w http.ResponseWriter, r *http.Request) {
+	portfolioID := chi.URLParam(r, "uuid")
+
+	if _, err := h.portfolioService.GetPortfolio(portfolioID); err != nil {
+		h.respondPortfolioError(w, r, err, portfolioID)
+		return
+	}
+
+	shares, err := h.authService.GetPortfolioShares(portfolioID)
+	if err != nil {
+		authLog.ErrorContext(r.Context(), "failed to get portfolio shares", "error", err, "portfolio_id", portfolioID)
+		response.RespondInternalError(w, r, apperrors.ErrFailedToRetrieveShares.Error())
+		return
+	}
+
+	response.RespondJSON(w, http.StatusOK, shares)
+}
+
+// SharePortfolio handles PUT requests to grant a user read or write access to a portfolio,
+// replacing any access granted earlier. Portfolio owners and administrators only.
+//
+// Endpoint: PUT /api/portfolio/{uuid}/shares
+// Request Body: SharePortfolioRequest (userId, access: read|write)
+// Response: 200 OK with PortfolioShare
+// Error: 400 Bad Request if validation fails or the user owns the portfolio
+// Error: 404 Not Found if the portfolio or user doesn't exist
+// Error: 500 Internal Server Error if sharing fails
+func (h *AuthHandler) SharePortfolio(w http.ResponseWriter, r *http.Request) {
+	portfolioID := chi.URLParam(r, "uuid")
+
+	req, err := parseJSON[request.SharePortfolioRequest](r)
+	if err != nil {
+		response.RespondError(w, http.StatusBadRequest, "invalid request body", err.Error())
+		return
+	}
+
+	if err := validation.ValidateSharePortfolio(req); err != nil {
+		response.RespondError(w, http.StatusBadRequest, "validation failed", err.Error())
+		return
+	}
+
+	portfolio, err := h.portfolioService.GetPortfolio(portfolioID)
+	if err != nil {
+		h.respondPortfolioError(w, r, err, portfolioID)
+		return
+	}
+
+	share, err := h.authService.SharePortfolio(r.Context(), portfolio, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, apperrors.ErrInvalidShareTarget):
+			response.RespondError(w, http.StatusBadRequest, apperrors.ErrInvalidShareTarget.Error(), "")
+		case errors.Is(err, apperrors.ErrUserNotFound):
+			response.RespondError(w, http.StatusNotFound, apperrors.ErrUserNotFound.Error(), "")
+		default:
+			authLog.ErrorContext(r.Context(), "failed to share portfolio", "error", err, "portfolio_id", portfolioID)
+			response.RespondInternalError(w, r, apperrors.ErrFailedToSharePortfolio.Error())
+		}
+		return
+	}
+
+	response.RespondJSON(w, http.StatusOK, share)
+}
+
+// UnsharePortfolio handles DELETE requests to revoke a user's access to a portfolio.
+// Portfolio owners and administrators only.
+//
+// Endpoint: DELETE /api/portfolio/{uuid}/shares/{userId}
+// Response: 204 No Content
+// Error: 400 Bad Request if userId is not a valid UUID
+// Error: 404 Not Found if the portfolio is not shared with the user
+// Error: 500 Internal Server Error if revoking fails
+func (h *AuthHandler) UnsharePortfolio(w http.ResponseWriter, r *http.Request) {
+	portfolioID := chi.URLParam(r, "uuid")
+	userID := chi.URLParam(r, "userId")
+
+	if err := validation.ValidateUUID(userID); err != nil {
+		response.RespondError(w, http.StatusBadRequest, "invalid UUID format", err.Error())
+		return
+	}
+
+	if err := h.authService.UnsharePortfolio(r.Context(), portfolioID, userID); err != nil {
+		if errors.Is(err, apperrors.ErrPortfolioShareNotFound) {
+			response.RespondError(w, http.StatusNotFound, apperrors.ErrPortfolioShareNotFound.Error(), "")
+			return
+		}
+		authLog.ErrorContext(r.Context(), "failed to unshare portfolio", "error", err, "portfolio_id", portfolioID)
+		response.RespondInternalError(w, r, apperrors.ErrFailedToUnsharePortfolio.Error())
+		return
+	}
+
+	response.RespondJSON(w, http.StatusNoContent, nil)
+}
+
+// respondPortfolioError maps a portfolio lookup failure to a response.
+func (h *AuthHandler) respondPortfolioError(w http.ResponseWriter, r *http.Request, err error, portfolioID string) {
+	if errors.Is(err, apperrors.ErrPortfolioNotFound) {
+		response.RespondError(w, http.StatusNotFound, apperrors.ErrPortfolioNotFound.Error(), "")
+		return
+	}
+	authLog.ErrorContext(r.Context(), "failed to get portfolio", "error", err, "portfolio_id", portfolioID)
+	response.RespondInternalError(w, r, apperrors.ErrFailedToRetrievePortfolios.Error())
+}
@@ -0,0 +1,472 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/api/request"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/auth"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/model"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/service"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/testutil"
+)
+
+func setupAuthHandler(t *testing.T) (*AuthHandler, *service.AuthService, *sql.DB) {
+	t.Helper()
+	db := testutil.SetupTestDB(t)
+	svc := testutil.NewTestAuthService(t, db)
+	return NewAuthHandler(svc, testutil.NewTestPortfolioService(t, db)), svc, db
+}
+
+// setupAdminAccount completes initial setup and returns the administrator's login response.
+func setupAdminAccount(t *testing.T, svc *service.AuthService) *model.LoginResponse {
+	t.Helper()
+	resp, err := svc.Setup(context.Background(), request.SetupRequest{Username: "admin", Password: "correct horse"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return resp
+}
+
+// withPrincipal returns req with p attached as the authenticated caller.
+func withPrincipal(req *http.Request, p *auth.Principal) *http.Request {
+	return req.WithContext(auth.WithPrincipal(req.Context(), p))
+}
+
+func TestAuthHandler_Setup(t *testing.T) {
+	t.Run("creates administrator", func(t *testing.T) {
+		handler, _, _ := setupAuthHandler(t)
+
+		req := testutil.NewRequestWithBody(http.MethodPost, "/api/auth/setup", `{"username":"admin","password":"correct horse"}`)
+		w := httptest.NewRecorder()
+
+		handler.Setup(w, req)
+
+		if w.Code != http.StatusCreated {
+			t.Fatalf("Expected 201, got %d: %s", w.Code, w.Body.String())
+		}
+
+		var response model.LoginResponse
+		//nolint:errcheck // Test assertion - decode failure would cause test to fail anyway
+		json.NewDecoder(w.Body).Decode(&response)
+
+		if response.Token == "" || !response.User.IsAdmin {
+			t.Errorf("Unexpected response: %+v", response)
+		}
+	})
+
+	t.Run("short password returns 400", func(t *testing.T) {
+		handler, _, _ := setupAuthHandler(t)
+
+		req := testutil.NewRequestWithBody(http.MethodPost, "/api/auth/setup", `{"username":"admin","password":"short"}`)
+		w := httptest.NewRecorder()
+
+		handler.Setup(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected 400, got %d", w.Code)
+		}
+	})
+
+	t.Run("second setup returns 409", func(t *testing.T) {
+		handler, svc, _ := setupAuthHandler(t)
+		setupAdminAccount(t, svc)
+
+		req := testutil.NewRequestWithBody(http.MethodPost, "/api/auth/setup", `{"username":"other","password":"correct horse"}`)
+		w := httptest.NewRecorder()
+
+		handler.Setup(w, req)
+
+		if w.Code != http.StatusConflict {
+			t.Errorf("Expected 409, got %d", w.Code)
+		}
+	})
+}
+
+func TestAuthHandler_Status(t *testing.T) {
+	handler, svc, _ := setupAuthHandler(t)
+
+	check := func(want bool) {
+		t.Helper()
+		w := httptest.NewRecorder()
+		handler.Status(w, httptest.NewRequest(http.MethodGet, "/api/auth/status", nil))
+
+		var response model.AuthStatus
+		//nolint:errcheck // Test assertion - decode failure would cause test to fail anyway
+		json.NewDecoder(w.Body).Decode(&response)
+
+		if w.Code != http.StatusOK || response.AuthEnabled != want {
+			t.Errorf("Expected 200 with authEnabled=%v, got %d %+v", want, w.Code, response)
+		}
+	}
+
+	check(false)
+	setupAdminAccount(t, svc)
+	check(true)
+}
+
+func TestAuthHandler_Login(t *testing.T) {
+	handler, svc, _ := setupAuthHandler(t)
+	setupAdminAccount(t, svc)
+
+	t.Run("valid credentials return token", func(t *testing.T) {
+		req := testutil.NewRequestWithBody(http.MethodPost, "/api/auth/login", `{"username":"admin","password":"correct horse"}`)
+		w := httptest.NewRecorder()
+
+		handler.Login(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("wrong password returns 401", func(t *testing.T) {
+		req := testutil.NewRequestWithBody(http.MethodPost, "/api/auth/login", `{"username":"admin","password":"wrong password"}`)
+		w := httptest.NewRecorder()
+
+		handler.Login(w, req)
+
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("Expected 401, got %d", w.Code)
+		}
+	})
+
+	t.Run("missing fields return 400", func(t *testing.T) {
+		req := testutil.NewRequestWithBody(http.MethodPost, "/api/auth/login", `{}`)
+		w := httptest.NewRecorder()
+
+		handler.Login(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected 400, got %d", w.Code)
+		}
+	})
+}
+
+func TestAuthHandler_Me(t *testing.T) {
+	handler, svc, _ := setupAuthHandler(t)
+	admin := setupAdminAccount(t, svc)
+
+	t.Run("returns caller", func(t *testing.T) {
+		req := withPrincipal(httptest.NewRequest(http.MethodGet, "/api/auth/me", nil), auth.NewPrincipal(admin.User, nil, nil))
+		w := httptest.NewRecorder()
+
+		handler.Me(w, req)
+
+		var response model.User
+		//nolint:errcheck // Test assertion - decode failure would cause test to fail anyway
+		json.NewDecoder(w.Body).Decode(&response)
+
+		if w.Code != http.StatusOK || response.ID != admin.User.ID {
+			t.Errorf("Expected 200 with caller, got %d %+v", w.Code, response)
+		}
+	})
+
+	t.Run("unauthenticated returns 401", func(t *testing.T) {
+		w := httptest.NewRecorder()
+
+		handler.Me(w, httptest.NewRequest(http.MethodGet, "/api/auth/me", nil))
+
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("Expected 401, got %d", w.Code)
+		}
+	})
+}
+
+func TestAuthHandler_Users(t *testing.T) {
+	handler, svc, _ := setupAuthHandler(t)
+	admin := setupAdminAccount(t, svc)
+	principal := auth.NewPrincipal(admin.User, nil, nil)
+
+	var created model.User
+	t.Run("creates user", func(t *testing.T) {
+		req := testutil.NewRequestWithBody(http.MethodPost, "/api/user", `{"username":"alice","password":"alice-password"}`)
+		w := httptest.NewRecorder()
+
+		handler.CreateUser(w, withPrincipal(req, principal))
+
+		if w.Code != http.StatusCreated {
+			t.Fatalf("Expected 201, got %d: %s", w.Code, w.Body.String())
+		}
+		//nolint:errcheck // Test assertion - decode failure would cause test to fail anyway
+		json.NewDecoder(w.Body).Decode(&created)
+	})
+
+	t.Run("duplicate username returns 409", func(t *testing.T) {
+		req := testutil.NewRequestWithBody(http.MethodPost, "/api/user", `{"username":"alice","password":"alice-password"}`)
+		w := httptest.NewRecorder()
+
+		handler.CreateUser(w, withPrincipal(req, principal))
+
+		if w.Code != http.StatusConflict {
+			t.Errorf("Expected 409, got %d", w.Code)
+		}
+	})
+
+	t.Run("lists users", func(t *testing.T) {
+		w := httptest.NewRecorder()
+
+		handler.GetUsers(w, httptest.NewRequest(http.MethodGet, "/api/user", nil))
+
+		var response []model.User
+		//nolint:errcheck // Test assertion - decode failure would cause test to fail anyway
+		json.NewDecoder(w.Body).Decode(&response)
+
+		if w.Code != http.StatusOK || len(response) != 2 {
+			t.Errorf("Expected 200 with 2 users, got %d %+v", w.Code, response)
+		}
+	})
+
+	t.Run("deleting self returns 400", func(t *testing.T) {
+		req := testutil.NewRequestWithURLParams(http.MethodDelete, "/api/user/"+admin.User.ID, map[string]string{"uuid": admin.User.ID})
+		w := httptest.NewRecorder()
+
+		handler.DeleteUser(w, withPrincipal(req, principal))
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected 400, got %d", w.Code)
+		}
+	})
+
+	t.Run("deletes user", func(t *testing.T) {
+		req := testutil.NewRequestWithURLParams(http.MethodDelete, "/api/user/"+created.ID, map[string]string{"uuid": created.ID})
+		w := httptest.NewRecorder()
+
+		handler.DeleteUser(w, withPrincipal(req, principal))
+
+		if w.Code != http.StatusNoContent {
+			t.Errorf("Expected 204, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("unknown user returns 404", func(t *testing.T) {
+		id := testutil.MakeID()
+		req := testutil.NewRequestWithURLParams(http.MethodDelete, "/api/user/"+id, map[string]string{"uuid": id})
+		w := httptest.NewRecorder()
+
+		handler.DeleteUser(w, withPrincipal(req, principal))
+
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Expected 404, got %d", w.Code)
+		}
+	})
+}
+
+func TestAuthHandler_PortfolioShares(t *testing.T) {
+	handler, svc, db := setupAuthHandler(t)
+	portfolio := testutil.NewPortfolio().Build(t, db)
+	admin := setupAdminAccount(t, svc)
+	user, err := svc.CreateUser(context.Background(), request.CreateUserRequest{Username: "alice", Password: "alice-password"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	params := map[string]string{"uuid": portfolio.ID, "userId": user.ID}
+
+	t.Run("shares portfolio", func(t *testing.T) {
+		req := testutil.NewRequestWithURLParamsAndBody(http.MethodPut, "/api/portfolio/"+portfolio.ID+"/shares", params,
+			`{"userId":"`+user.ID+`","access":"write"}`)
+		w := httptest.NewRecorder()
+
+		handler.SharePortfolio(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("sharing with owner returns 400", func(t *testing.T) {
+		req := testutil.NewRequestWithURLParamsAndBody(http.MethodPut, "/api/portfolio/"+portfolio.ID+"/shares", params,
+			`{"userId":"`+admin.User.ID+`","access":"read"}`)
+		w := httptest.NewRecorder()
+
+		handler.SharePortfolio(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected 400, got %d", w.Code)
+		}
+	})
+
+	t.Run("invalid access returns 400", func(t *testing.T) {
+		req := testutil.NewRequestWithURLParamsAndBody(http.MethodPut, "/api/portfolio/"+portfolio.ID+"/shares", params,
+			`{"userId":"`+user.ID+`","access":"owner"}`)
+		w := httptest.NewRecorder()
+
+		handler.SharePortfolio(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected 400, got %d", w.Code)
+		}
+	})
+
+	t.Run("lists shares", func(t *testing.T) {
+		req := testutil.NewRequestWithURLParams(http.MethodGet, "/api/portfolio/"+portfolio.ID+"/shares", params)
+		w := httptest.NewRecorder()
+
+		handler.GetPortfolioShares(w, req)
+
+		var response []model.PortfolioShare
+		//nolint:errcheck // Test assertion - decode failure would cause test to fail anyway
+		json.NewDecoder(w.Body).Decode(&response)
+
+		if w.Code != http.StatusOK || len(response) != 1 || response[0].Access != model.PortfolioAccessWrite {
+			t.Errorf("Expected 200 with 1 write share, got %d %+v", w.Code, response)
+		}
+	})
+
+	t.Run("unshares portfolio", func(t *testing.T) {
+		req := testutil.NewRequestWithURLParams(http.MethodDelete, "/api/portfolio/"+portfolio.ID+"/shares/"+user.ID, params)
+		w := httptest.NewRecorder()
+
+		handler.UnsharePortfolio(w, req)
+
+		if w.Code != http.StatusNoContent {
+			t.Fatalf("Expected 204, got %d: %s", w.Code, w.Body.String())
+		}
+
+		w = httptest.NewRecorder()
+		handler.UnsharePortfolio(w, req)
+
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Expected 404 for second unshare, got %d", w.Code)
+		}
+	})
+
+	t.Run("unknown portfolio returns 404", func(t *testing.T) {
+		id := testutil.MakeID()
+		req := testutil.NewRequestWithURLParams(http.MethodGet, "/api/portfolio/"+id+"/shares", map[string]string{"uuid": id})
+		w := httptest.NewRecorder()
+
+		handler.GetPortfolioShares(w, req)
+
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Expected 404, got %d", w.Code)
+		}
+	})
+}
+
+func TestPortfolioHandler_Portfolios_FiltersByCaller(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	handler := NewPortfolioHandler(
+		testutil.NewTestPortfolioService(t, db),
+		testutil.NewTestFundService(t, db),
+		testutil.NewTestMaterializedService(t, db),
+	)
+	visible := testutil.NewPortfolio().Build(t, db)
+	testutil.NewPortfolio().Build(t, db)
+
+	principal := auth.NewPrincipal(model.User{ID: testutil.MakeID()},
+		map[string]model.PortfolioAccess{visible.ID: model.PortfolioAccessRead}, nil)
+	req := withPrincipal(httptest.NewRequest(http.MethodGet, "/api/portfolio", nil), principal)
+	w := httptest.NewRecorder()
+
+	handler.Portfolios(w, req)
+
+	var response []model.Portfolio
+	//nolint:errcheck // Test assertion - decode failure would cause test to fail anyway
+	json.NewDecoder(w.Body).Decode(&response)
+
+	if w.Code != http.StatusOK || len(response) != 1 || response[0].ID != visible.ID {
+		t.Errorf("Expected only the shared portfolio, got %d %+v", w.Code, response)
+	}
+}
+
+func TestAuthHandler_APITokens(t *testing.T) {
+	handler, svc, _ := setupAuthHandler(t)
+	admin := setupAdminAccount(t, svc)
+	user, err := svc.CreateUser(context.Background(), request.CreateUserRequest{Username: "alice", Password: "alice-password"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	alice := auth.NewPrincipal(*user, nil, nil)
+
+	var created model.CreateAPITokenResponse
+	t.Run("creates token", func(t *testing.T) {
+		req := testutil.NewRequestWithBody(http.MethodPost, "/api/auth/tokens", `{"name":"dashboard","scopes":["read:portfolio"],"expiresInDays":30}`)
+		w := httptest.NewRecorder()
+
+		handler.CreateAPIToken(w, withPrincipal(req, alice))
+
+		if w.Code != http.StatusCreated {
+			t.Fatalf("Expected 201, got %d: %s", w.Code, w.Body.String())
+		}
+		//nolint:errcheck // Test assertion - decode failure would cause test to fail anyway
+		json.NewDecoder(w.Body).Decode(&created)
+
+		if !strings.HasPrefix(created.Token, auth.APITokenPrefix) || created.APIToken.ExpiresAt == nil {
+			t.Errorf("Unexpected response: %+v", created)
+		}
+	})
+
+	t.Run("admin scope returns 403 for non-admin", func(t *testing.T) {
+		req := testutil.NewRequestWithBody(http.MethodPost, "/api/auth/tokens", `{"name":"x","scopes":["developer"]}`)
+		w := httptest.NewRecorder()
+
+		handler.CreateAPIToken(w, withPrincipal(req, alice))
+
+		if w.Code != http.StatusForbidden {
+			t.Errorf("Expected 403, got %d", w.Code)
+		}
+	})
+
+	t.Run("unknown scope returns 400", func(t *testing.T) {
+		req := testutil.NewRequestWithBody(http.MethodPost, "/api/auth/tokens", `{"name":"x","scopes":["read:all"]}`)
+		w := httptest.NewRecorder()
+
+		handler.CreateAPIToken(w, withPrincipal(req, alice))
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected 400, got %d", w.Code)
+		}
+	})
+
+	t.Run("lists only own tokens", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/auth/tokens", nil)
+		w := httptest.NewRecorder()
+
+		handler.GetAPITokens(w, withPrincipal(req, auth.NewPrincipal(admin.User, nil, nil)))
+
+		var response []model.APIToken
+		//nolint:errcheck // Test assertion - decode failure would cause test to fail anyway
+		json.NewDecoder(w.Body).Decode(&response)
+
+		if w.Code != http.StatusOK || len(response) != 0 {
+			t.Errorf("Expected 200 with no tokens, got %d %+v", w.Code, response)
+		}
+	})
+
+	t.Run("revokes token", func(t *testing.T) {
+		id := created.APIToken.ID
+		req := testutil.NewRequestWithURLParams(http.MethodDelete, "/api/auth/tokens/"+id, map[string]string{"uuid": id})
+		w := httptest.NewRecorder()
+
+		handler.RevokeAPIToken(w, withPrincipal(req, alice))
+
+		if w.Code != http.StatusNoContent {
+			t.Fatalf("Expected 204, got %d: %s", w.Code, w.Body.String())
+		}
+
+		w = httptest.NewRecorder()
+		handler.RevokeAPIToken(w, withPrincipal(req, alice))
+
+		if w.Code != http.StatusConflict {
+			t.Errorf("Expected 409 for second revoke, got %d", w.Code)
+		}
+	})
+
+	t.Run("unknown token returns 404", func(t *testing.T) {
+		id := testutil.MakeID()
+		req := testutil.NewRequestWithURLParams(http.MethodDelete, "/api/auth/tokens/"+id, map[string]string{"uuid": id})
+		w := httptest.NewRecorder()
+
+		handler.RevokeAPIToken(w, withPrincipal(req, alice))
+
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Expected 404, got %d", w.Code)
+		}
+	})
+}
@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/api/request"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/api/response"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/apperrors"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/service"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/validation"
+)
+
+// DigestHandler handles HTTP requests for the emailed portfolio digest.
+type DigestHandler struct {
+	digestService *service.DigestService
+}
+
+// NewDigestHandler creates a new DigestHandler with the provided service dependency.
+func NewDigestHandler(digestService *service.DigestService) *DigestHandler {
+	return &DigestHandler{
+		digestService: digestService,
+	}
+}
+
+// GetSettings handles GET requests to retrieve the digest settings.
+//
+// Endpoint: GET /api/digest/settings
+// Response: 200 OK with DigestSettings
+// Error: 500 Internal Server Error if retrieval fails
+func (h *DigestHandler) GetSettings(w http.ResponseWriter, r *http.Request) {
+	settings, err := h.digestService.GetSettings()
+	if err != nil {
+		sysLog.ErrorContext(r.Context(), "failed to get digest settings", "error", err)
+		response.RespondInternalError(w, r, apperrors.ErrFailedToRetrieveDigestSettings.Error())
+		return
+	}
+
+	response.RespondJSON(w, http.StatusOK, settings)
+}
+
+// UpdateSettings handles PUT requests to change whether the digest is sent, its period and
+// its recipients.
+//
+// Endpoint: PUT /api/digest/settings
+// Request body: UpdateDigestSettingsRequest (all fields optional)
+// Response: 200 OK with the updated DigestSettings
+// Error: 400 Bad Request if the body is invalid
+// Error: 500 Internal Server Error if the update fails
+func (h *DigestHandler) UpdateSettings(w http.ResponseWriter, r *http.Request) {
+	req, err := parseJSON[request.UpdateDigestSettingsRequest](r)
+	if err != nil {
+		response.RespondError(w, http.StatusBadRequest, "invalid request body", err.Error())
+		return
+	}
+
+	if err := validation.ValidateUpdateDigestSettings(req); err != nil {
+		response.RespondError(w, http.StatusBadRequest, "validation failed", err.Error())
+		return
+	}
+
+	settings, err := h.digestService.UpdateSettings(r.Context(), req)
+	if err != nil {
+		sysLog.ErrorContext(r.Context(), "failed to update digest settings", "error", err)
+		response.RespondInternalError(w, r, apperrors.ErrFailedToUpdateDigestSettings.Error())
+		return
+	}
+
+	response.RespondJSON(w, http.StatusOK, settings)
+}
+
+// Preview handles GET requests to build the digest without sending it.
+//
+// Endpoint: GET /api/digest/preview
+// Query parameters:
+//   - period: week or month (default: the period in the settings)
+//   - format: json (default) or html for the rendered email
+//
+// Response: 200 OK with Digest, or the HTML email body when format=html
+// Error: 400 Bad Request if a query parameter is invalid
+// Error: 500 Internal Server Error if the digest cannot be built
+func (h *DigestHandler) Preview(w http.ResponseWriter, r *http.Request) {
+	period, err := request.ParseDigestPeriod(r.URL.Query().Get("period"))
+	if err != nil {
+		response.RespondError(w, http.StatusBadRequest, "invalid query parameters", err.Error())
+		return
+	}
+
+	switch r.URL.Query().Get("format") {
+	case "", "json":
+		digest, err := h.digestService.BuildDigest(r.Context(), period)
+		if err != nil {
+			sysLog.ErrorContext(r.Context(), "failed to build digest", "error", err)
+			response.RespondInternalError(w, r, apperrors.ErrFailedToBuildDigest.Error())
+			return
+		}
+		response.RespondJSON(w, http.StatusOK, digest)
+	case "html":
+		msg, err := h.digestService.RenderDigest(r.Context(), period)
+		if err != nil {
+			sysLog.ErrorContext(r.Context(), "failed to render digest", "error", err)
+			response.RespondInternalError(w, r, apperrors.ErrFailedToBuildDigest.Error())
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(msg.HTML)) //nolint:errcheck // Nothing useful can be done if the client went away.
+	default:
+		response.RespondError(w, http.StatusBadRequest, "invalid query parameters", "invalid format: must be json or html")
+	}
+}
+
+// Send handles POST requests to email the digest to its recipients now, whether or not
+// the scheduled digest is enabled.
+//
+// Endpoint: POST /api/digest/send
+// Response: 204 No Content once the mail server accepted the message
+// Error: 409 Conflict if no SMTP server is configured or the digest has no recipients
+// Error: 500 Internal Server Error if the digest cannot be built or sent
+func (h *DigestHandler) Send(w http.ResponseWriter, r *http.Request) {
+	if _, err := h.digestService.SendDigest(r.Context(), false); err != nil {
+		if errors.Is(err, apperrors.ErrEmailNotConfigured) || errors.Is(err, apperrors.ErrDigestNoRecipients) {
+			response.RespondError(w, http.StatusConflict, err.Error(), "")
+			return
+		}
+		sysLog.ErrorContext(r.Context(), "failed to send digest", "error", err)
+		response.RespondInternalError(w, r, apperrors.ErrFailedToSendDigest.Error())
+		return
+	}
+
+	response.RespondJSON(w, http.StatusNoContent, nil)
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/model"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/testutil"
+)
+
+func setupDigestHandler(t *testing.T) *DigestHandler {
+	t.Helper()
+	db := testutil.SetupTestDB(t)
+	return NewDigestHandler(testutil.NewTestDigestService(t, db, nil))
+}
+
+func TestDigestHandler_UpdateSettings(t *testing.T) {
+	newRequest := func(body string) *http.Request {
+		req := httptest.NewRequest(http.MethodPut, "/api/digest/settings", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		return req
+	}
+
+	t.Run("updates the settings", func(t *testing.T) {
+		handler := setupDigestHandler(t)
+		w := httptest.NewRecorder()
+
+		handler.UpdateSettings(w, newRequest(`{"enabled":true,"period":"month","recipients":["alice@example.com"]}`))
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+		var response model.DigestSettings
+		//nolint:errcheck // Test assertion - decode failure would cause test to fail anyway
+		json.NewDecoder(w.Body).Decode(&response)
+		if !response.Enabled || response.Period != model.DigestPeriodMonth || len(response.Recipients) != 1 {
+			t.Errorf("Expected the updated settings, got %+v", response)
+		}
+	})
+
+	t.Run("invalid recipient returns 400", func(t *testing.T) {
+		handler := setupDigestHandler(t)
+		w := httptest.NewRecorder()
+
+		handler.UpdateSettings(w, newRequest(`{"recipients":["not an address"]}`))
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected 400, got %d", w.Code)
+		}
+	})
+}
+
+func TestDigestHandler_Preview(t *testing.T) {
+	t.Run("returns the digest", func(t *testing.T) {
+		handler := setupDigestHandler(t)
+		w := httptest.NewRecorder()
+
+		handler.Preview(w, httptest.NewRequest(http.MethodGet, "/api/digest/preview?period=month", nil))
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+		var response model.Digest
+		//nolint:errcheck // Test assertion - decode failure would cause test to fail anyway
+		json.NewDecoder(w.Body).Decode(&response)
+		if response.Period != model.DigestPeriodMonth {
+			t.Errorf("Expected a monthly digest, got %+v", response)
+		}
+	})
+
+	t.Run("renders the email as html", func(t *testing.T) {
+		handler := setupDigestHandler(t)
+		w := httptest.NewRecorder()
+
+		handler.Preview(w, httptest.NewRequest(http.MethodGet, "/api/digest/preview?format=html", nil))
+
+		if w.Code != http.StatusOK || !strings.HasPrefix(w.Header().Get("Content-Type"), "text/html") {
+			t.Fatalf("Expected 200 with HTML, got %d %q", w.Code, w.Header().Get("Content-Type"))
+		}
+		if !strings.Contains(w.Body.String(), "weekly digest") {
+			t.Errorf("Expected the weekly digest, got %s", w.Body.String())
+		}
+	})
+
+	t.Run("invalid period returns 400", func(t *testing.T) {
+		handler := setupDigestHandler(t)
+		w := httptest.NewRecorder()
+
+		handler.Preview(w, httptest.NewRequest(http.MethodGet, "/api/digest/preview?period=year", nil))
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected 400, got %d", w.Code)
+		}
+	})
+}
+
+func TestDigestHandler_Send(t *testing.T) {
+	t.Run("returns 409 without an SMTP server", func(t *testing.T) {
+		handler := setupDigestHandler(t)
+		w := httptest.NewRecorder()
+
+		handler.Send(w, httptest.NewRequest(http.MethodPost, "/api/digest/send", nil))
+
+		if w.Code != http.StatusConflict {
+			t.Errorf("Expected 409, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+}
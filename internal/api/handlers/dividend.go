@@ -9,6 +9,7 @@ import (
 	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/api/response"
 	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/apperrors"
 	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/logging"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/model"
 	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/service"
 	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/validation"
 )
@@ -30,7 +31,7 @@ func NewDividendHandler(dividendService *service.DividendService) *DividendHandl
 }
 
 // GetAllDividend handles GET requests to retrieve all dividends.
-// Returns a list of all available dividends that can be held in portfolios.
+// Returns the dividends of every portfolio the caller can read.
 //
 // Endpoint: GET /api/dividend
 // Response: 200 OK with array of Dividend
@@ -38,7 +39,7 @@ func NewDividendHandler(dividendService *service.DividendService) *DividendHandl
 func (h *DividendHandler) GetAllDividend(w http.ResponseWriter, r *http.Request) {
 	divLog.DebugContext(r.Context(), "get all dividends request")
 
-	dividends, err := h.dividendService.GetAllDividend()
+	dividends, err := h.dividendService.GetAllDividend(r.Context())
 	if err != nil {
 		divLog.ErrorContext(r.Context(), "failed to get all dividends", "error", err)
 		response.RespondInternalError(w, r, apperrors.ErrFailedToRetrieveDividends.Error())
@@ -62,7 +63,7 @@ func (h *DividendHandler) DividendPerPortfolio(w http.ResponseWriter, r *http.Re
 
 	divLog.DebugContext(r.Context(), "get dividends per portfolio request", "portfolio_id", portfolioID)
 
-	dividends, err := h.dividendService.GetDividendFund(portfolioID, "")
+	dividends, err := h.dividendService.GetDividendFund(r.Context(), portfolioID, "")
 	if err != nil {
 		if errors.Is(err, apperrors.ErrPortfolioNotFound) {
 			response.RespondError(w, http.StatusNotFound, apperrors.ErrPortfolioNotFound.Error(), "")
@@ -82,7 +83,7 @@ func (h *DividendHandler) DividendPerPortfolio(w http.ResponseWriter, r *http.Re
 
 // DividendPerFund handles GET requests to retrieve all dividends for a specific fund.
 // Returns dividend details including fund information, amounts, dates, and reinvestment status
-// for all portfolio positions that held the specified fund.
+// for all portfolio positions the caller can read that held the specified fund.
 //
 // Endpoint: GET /api/dividend/fund/{uuid}
 // Response: 200 OK with array of DividendFund
@@ -95,7 +96,7 @@ func (h *DividendHandler) DividendPerFund(w http.ResponseWriter, r *http.Request
 
 	divLog.DebugContext(r.Context(), "get dividends per fund request", "fund_id", fundID)
 
-	dividends, err := h.dividendService.GetDividendFund("", fundID)
+	dividends, err := h.dividendService.GetDividendFund(r.Context(), "", fundID)
 	if err != nil {
 		if errors.Is(err, apperrors.ErrFundNotFound) {
 			response.RespondError(w, http.StatusNotFound, apperrors.ErrFundNotFound.Error(), "")
@@ -145,6 +146,7 @@ func (h *DividendHandler) GetDividend(w http.ResponseWriter, r *http.Request) {
 // Request Body: CreateDividendRequest (portfolioFundId, recordDate, exDividendDate, dividendPerShare, and optionally, buyOrderDate, reinvestmentShares and reinvestmentPrice)
 // Response: 201 Created with DividendFund
 // Error: 400 Bad Request if validation fails or request body is invalid
+// Error: 403 Forbidden if the caller has read-only access to the portfolio
 // Error: 500 Internal Server Error if creation fails
 func (h *DividendHandler) CreateDividend(w http.ResponseWriter, r *http.Request) {
 	divLog.DebugContext(r.Context(), "create dividend request")
@@ -160,6 +162,10 @@ func (h *DividendHandler) CreateDividend(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	if !authorizePortfolioFund(w, r, req.PortfolioFundID, model.PortfolioAccessWrite) {
+		return
+	}
+
 	dividend, err := h.dividendService.CreateDividend(r.Context(), req)
 	if err != nil {
 		divLog.ErrorContext(r.Context(), "failed to create dividend", "error", err)
@@ -196,6 +202,10 @@ func (h *DividendHandler) UpdateDividend(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	if req.PortfolioFundID != nil && !authorizePortfolioFund(w, r, *req.PortfolioFundID, model.PortfolioAccessWrite) {
+		return
+	}
+
 	dividend, err := h.dividendService.UpdateDividend(r.Context(), dividendID, req)
 	if err != nil {
 		if errors.Is(err, apperrors.ErrDividendNotFound) {
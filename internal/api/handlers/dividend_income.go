@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/api/response"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/apperrors"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/service"
+)
+
+// DividendIncomeHandler handles HTTP requests for the dividend income endpoint.
+type DividendIncomeHandler struct {
+	incomeService *service.DividendIncomeService
+}
+
+// NewDividendIncomeHandler creates a new DividendIncomeHandler with the provided service dependency.
+func NewDividendIncomeHandler(incomeService *service.DividendIncomeService) *DividendIncomeHandler {
+	return &DividendIncomeHandler{
+		incomeService: incomeService,
+	}
+}
+
+// GetDividendIncome handles GET requests for the dividend income of a portfolio: received
+// income per month and year, trailing-12-month yields and a 12-month forecast, for the
+// portfolio and each of its funds.
+//
+// Endpoint: GET /api/dividend/portfolio/{uuid}/income
+// Response: 200 OK with DividendIncome
+// Error: 404 Not Found if the portfolio does not exist
+// Error: 500 Internal Server Error if retrieval fails
+func (h *DividendIncomeHandler) GetDividendIncome(w http.ResponseWriter, r *http.Request) {
+	portfolioID := chi.URLParam(r, "uuid")
+
+	income, err := h.incomeService.GetDividendIncome(r.Context(), portfolioID)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrPortfolioNotFound) {
+			response.RespondError(w, http.StatusNotFound, apperrors.ErrPortfolioNotFound.Error(), "")
+			return
+		}
+		divLog.ErrorContext(r.Context(), "failed to get dividend income", "error", err, "portfolio_id", portfolioID)
+		response.RespondInternalError(w, r, apperrors.ErrFailedToRetrieveDividendIncome.Error())
+		return
+	}
+
+	response.RespondJSON(w, http.StatusOK, income)
+}
@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/model"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/testutil"
+)
+
+func TestDividendIncomeHandler_GetDividendIncome(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	handler := NewDividendIncomeHandler(testutil.NewTestDividendIncomeService(t, db))
+
+	income := func(portfolioID string) *httptest.ResponseRecorder {
+		req := testutil.NewRequestWithURLParams(http.MethodGet, "/api/dividend/portfolio/"+portfolioID+"/income", map[string]string{"uuid": portfolioID})
+		w := httptest.NewRecorder()
+		handler.GetDividendIncome(w, req)
+		return w
+	}
+
+	t.Run("portfolio without dividends", func(t *testing.T) {
+		portfolio := testutil.NewPortfolio().Build(t, db)
+		w := income(portfolio.ID)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+		var response model.DividendIncome
+		//nolint:errcheck // Test assertion - decode failure would cause test to fail anyway
+		json.NewDecoder(w.Body).Decode(&response)
+		if response.PortfolioID != portfolio.ID || response.TotalReceived != 0 || response.Funds == nil {
+			t.Errorf("Expected an empty income report, got %+v", response)
+		}
+	})
+
+	t.Run("unknown portfolio returns 404", func(t *testing.T) {
+		if w := income(testutil.MakeID()); w.Code != http.StatusNotFound {
+			t.Errorf("Expected 404, got %d", w.Code)
+		}
+	})
+}
@@ -26,14 +26,17 @@ type FundHandler struct {
 	fundService *service.FundService
 
 	materializedService *service.MaterializedService
+	jobService          *service.JobService
 }
 
-// NewFundHandler creates a new FundHandler with the provided service dependency.
-func NewFundHandler(fundService *service.FundService, materializedService *service.MaterializedService) *FundHandler {
+// NewFundHandler creates a new FundHandler with the provided service dependencies.
+// jobService is used for asynchronous price updates and may be nil if they are not offered.
+func NewFundHandler(fundService *service.FundService, materializedService *service.MaterializedService, jobService *service.JobService) *FundHandler {
 	return &FundHandler{
 		fundService: fundService,
 
 		materializedService: materializedService,
+		jobService:          jobService,
 	}
 }
 
@@ -145,7 +148,7 @@ func (h *FundHandler) GetFundHistory(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	fundHistory, err := h.materializedService.GetFundHistoryWithFallback(portfolioID, startDate, endDate)
+	fundHistory, err := h.materializedService.GetFundHistoryWithFallback(r.Context(), portfolioID, startDate, endDate)
 	if err != nil {
 		fundLog.ErrorContext(r.Context(), "failed to get fund history", "error", err, "portfolio_id", portfolioID)
 		response.RespondInternalError(w, r, apperrors.ErrFailedToRetrieveFundHistory.Error())
@@ -202,7 +205,7 @@ func (h *FundHandler) CheckUsage(w http.ResponseWriter, r *http.Request) {
 
 	fundLog.DebugContext(r.Context(), "check fund usage request", "fund_id", fundID)
 
-	fundUsage, err := h.fundService.CheckUsage(fundID)
+	fundUsage, err := h.fundService.CheckUsage(r.Context(), fundID)
 	if err != nil {
 		fundLog.ErrorContext(r.Context(), "failed to check fund usage", "error", err, "fund_id", fundID)
 		response.RespondInternalError(w, r, apperrors.ErrFailedToRetrieveUsage.Error())
@@ -411,9 +414,23 @@ func (h *FundHandler) UpdateFundPrice(w http.ResponseWriter, r *http.Request) {
 // UpdateAllFundHistory updates historical prices for all funds in the database.
 // Returns 200 on full or partial success, 500 on total failure, 404 if no funds exist.
 // The response includes detailed success/error information for each fund.
+//
+// With ?async=true the update runs as a fund_price_update job instead: the response is
+// 202 Accepted with the Job, whose result holds the per-fund details once it finishes.
 func (h *FundHandler) UpdateAllFundHistory(w http.ResponseWriter, r *http.Request) {
 	fundLog.DebugContext(r.Context(), "update all fund history request")
 
+	if r.URL.Query().Get("async") == "true" && h.jobService != nil {
+		job, err := h.jobService.Submit(r.Context(), model.JobTypeFundPriceUpdate, nil)
+		if err != nil {
+			fundLog.ErrorContext(r.Context(), "failed to start fund price update job", "error", err)
+			response.RespondInternalError(w, r, apperrors.ErrFailedToStartJob.Error())
+			return
+		}
+		response.RespondJSON(w, http.StatusAccepted, job)
+		return
+	}
+
 	resp, err := h.fundService.UpdateAllFundHistory(r.Context())
 	if err != nil {
 		if errors.Is(err, apperrors.ErrFundNotFound) {
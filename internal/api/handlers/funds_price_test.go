@@ -1,6 +1,7 @@
 package handlers_test
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -19,7 +20,7 @@ func TestFundHandler_GetFundPrices(t *testing.T) {
 		db := testutil.SetupTestDB(t)
 		fs := testutil.NewTestFundService(t, db)
 		ms := testutil.NewTestMaterializedService(t, db)
-		handler := handlers.NewFundHandler(fs, ms)
+		handler := handlers.NewFundHandler(fs, ms, nil)
 
 		fund := testutil.NewFund().Build(t, db)
 
@@ -52,7 +53,7 @@ func TestFundHandler_GetFundPrices(t *testing.T) {
 		db := testutil.SetupTestDB(t)
 		fs := testutil.NewTestFundService(t, db)
 		ms := testutil.NewTestMaterializedService(t, db)
-		handler := handlers.NewFundHandler(fs, ms)
+		handler := handlers.NewFundHandler(fs, ms, nil)
 
 		fund := testutil.NewFund().Build(t, db)
 
@@ -90,7 +91,7 @@ func TestFundHandler_UpdateFundPrice_Today(t *testing.T) {
 		mockYahoo := testutil.NewMockYahooClient()
 		fs := testutil.NewTestFundServiceWithMockYahoo(t, db, mockYahoo)
 		ms := testutil.NewTestMaterializedService(t, db)
-		handler := handlers.NewFundHandler(fs, ms)
+		handler := handlers.NewFundHandler(fs, ms, nil)
 
 		// Create fund with symbol
 		fund := testutil.NewFund().WithSymbol("AAPL").Build(t, db)
@@ -133,7 +134,7 @@ func TestFundHandler_UpdateFundPrice_Today(t *testing.T) {
 		mockYahoo := testutil.NewMockYahooClient()
 		fs := testutil.NewTestFundServiceWithMockYahoo(t, db, mockYahoo)
 		ms := testutil.NewTestMaterializedService(t, db)
-		handler := handlers.NewFundHandler(fs, ms)
+		handler := handlers.NewFundHandler(fs, ms, nil)
 
 		// Create fund with symbol
 		fund := testutil.NewFund().WithSymbol("AAPL").Build(t, db)
@@ -184,7 +185,7 @@ func TestFundHandler_UpdateFundPrice_Today(t *testing.T) {
 		mockYahoo := testutil.NewMockYahooClient()
 		fs := testutil.NewTestFundServiceWithMockYahoo(t, db, mockYahoo)
 		ms := testutil.NewTestMaterializedService(t, db)
-		handler := handlers.NewFundHandler(fs, ms)
+		handler := handlers.NewFundHandler(fs, ms, nil)
 
 		// Create fund WITHOUT symbol
 		fund := testutil.NewFund().WithSymbol("").Build(t, db)
@@ -219,7 +220,7 @@ func TestFundHandler_UpdateFundPrice_Today(t *testing.T) {
 		mockYahoo := testutil.NewMockYahooClient()
 		fs := testutil.NewTestFundServiceWithMockYahoo(t, db, mockYahoo)
 		ms := testutil.NewTestMaterializedService(t, db)
-		handler := handlers.NewFundHandler(fs, ms)
+		handler := handlers.NewFundHandler(fs, ms, nil)
 
 		req := testutil.NewRequestWithQueryAndURLParams(
 			http.MethodPost,
@@ -251,7 +252,7 @@ func TestFundHandler_UpdateFundPrice_Today(t *testing.T) {
 		mockYahoo := testutil.NewMockYahooClient().WithError(fmt.Errorf("yahoo api error"))
 		fs := testutil.NewTestFundServiceWithMockYahoo(t, db, mockYahoo)
 		ms := testutil.NewTestMaterializedService(t, db)
-		handler := handlers.NewFundHandler(fs, ms)
+		handler := handlers.NewFundHandler(fs, ms, nil)
 
 		fund := testutil.NewFund().WithSymbol("AAPL").Build(t, db)
 
@@ -275,7 +276,7 @@ func TestFundHandler_UpdateFundPrice_Today(t *testing.T) {
 		mockYahoo := testutil.NewMockYahooClient().WithEmptyResponse()
 		fs := testutil.NewTestFundServiceWithMockYahoo(t, db, mockYahoo)
 		ms := testutil.NewTestMaterializedService(t, db)
-		handler := handlers.NewFundHandler(fs, ms)
+		handler := handlers.NewFundHandler(fs, ms, nil)
 
 		fund := testutil.NewFund().WithSymbol("AAPL").Build(t, db)
 
@@ -298,7 +299,7 @@ func TestFundHandler_UpdateFundPrice_Today(t *testing.T) {
 		db := testutil.SetupTestDB(t)
 		fs := testutil.NewTestFundService(t, db)
 		ms := testutil.NewTestMaterializedService(t, db)
-		handler := handlers.NewFundHandler(fs, ms)
+		handler := handlers.NewFundHandler(fs, ms, nil)
 
 		fund := testutil.NewFund().WithSymbol("AAPL").Build(t, db)
 
@@ -321,7 +322,7 @@ func TestFundHandler_UpdateFundPrice_Today(t *testing.T) {
 		db := testutil.SetupTestDB(t)
 		fs := testutil.NewTestFundService(t, db)
 		ms := testutil.NewTestMaterializedService(t, db)
-		handler := handlers.NewFundHandler(fs, ms)
+		handler := handlers.NewFundHandler(fs, ms, nil)
 
 		fund := testutil.NewFund().WithSymbol("AAPL").Build(t, db)
 
@@ -350,7 +351,7 @@ func TestFundHandler_UpdateFundPrice_Historical(t *testing.T) {
 		mockYahoo := testutil.NewMockYahooClient()
 		fs := testutil.NewTestFundServiceWithMockYahoo(t, db, mockYahoo)
 		ms := testutil.NewTestMaterializedService(t, db)
-		handler := handlers.NewFundHandler(fs, ms)
+		handler := handlers.NewFundHandler(fs, ms, nil)
 
 		// Create fund and portfolio
 		portfolio := testutil.NewPortfolio().Build(t, db)
@@ -403,7 +404,7 @@ func TestFundHandler_UpdateFundPrice_Historical(t *testing.T) {
 		mockYahoo := testutil.NewMockYahooClient()
 		fs := testutil.NewTestFundServiceWithMockYahoo(t, db, mockYahoo)
 		ms := testutil.NewTestMaterializedService(t, db)
-		handler := handlers.NewFundHandler(fs, ms)
+		handler := handlers.NewFundHandler(fs, ms, nil)
 
 		// Create fund and portfolio
 		portfolio := testutil.NewPortfolio().Build(t, db)
@@ -465,7 +466,7 @@ func TestFundHandler_UpdateFundPrice_Historical(t *testing.T) {
 		mockYahoo := testutil.NewMockYahooClient()
 		fs := testutil.NewTestFundServiceWithMockYahoo(t, db, mockYahoo)
 		ms := testutil.NewTestMaterializedService(t, db)
-		handler := handlers.NewFundHandler(fs, ms)
+		handler := handlers.NewFundHandler(fs, ms, nil)
 
 		// Create fund and portfolio WITHOUT transactions
 		portfolio := testutil.NewPortfolio().Build(t, db)
@@ -493,7 +494,7 @@ func TestFundHandler_UpdateFundPrice_Historical(t *testing.T) {
 		mockYahoo := testutil.NewMockYahooClient()
 		fs := testutil.NewTestFundServiceWithMockYahoo(t, db, mockYahoo)
 		ms := testutil.NewTestMaterializedService(t, db)
-		handler := handlers.NewFundHandler(fs, ms)
+		handler := handlers.NewFundHandler(fs, ms, nil)
 
 		// Create fund WITHOUT portfolio_fund relationship
 		fund := testutil.NewFund().WithSymbol("AAPL").Build(t, db)
@@ -520,7 +521,7 @@ func TestFundHandler_UpdateFundPrice_Historical(t *testing.T) {
 		mockYahoo := testutil.NewMockYahooClient()
 		fs := testutil.NewTestFundServiceWithMockYahoo(t, db, mockYahoo)
 		ms := testutil.NewTestMaterializedService(t, db)
-		handler := handlers.NewFundHandler(fs, ms)
+		handler := handlers.NewFundHandler(fs, ms, nil)
 
 		portfolio := testutil.NewPortfolio().Build(t, db)
 		fund := testutil.NewFund().WithSymbol("AAPL").Build(t, db)
@@ -572,7 +573,7 @@ func TestFundHandler_UpdateAllFundHistory(t *testing.T) {
 		mockYahoo := testutil.NewMockYahooClient()
 		fs := testutil.NewTestFundServiceWithMockYahoo(t, db, mockYahoo)
 		ms := testutil.NewTestMaterializedService(t, db)
-		handler := handlers.NewFundHandler(fs, ms)
+		handler := handlers.NewFundHandler(fs, ms, nil)
 
 		// Create portfolio and funds with transactions
 		portfolio := testutil.NewPortfolio().Build(t, db)
@@ -644,7 +645,7 @@ func TestFundHandler_UpdateAllFundHistory(t *testing.T) {
 		mockYahoo := testutil.NewMockYahooClient()
 		fs := testutil.NewTestFundServiceWithMockYahoo(t, db, mockYahoo)
 		ms := testutil.NewTestMaterializedService(t, db)
-		handler := handlers.NewFundHandler(fs, ms)
+		handler := handlers.NewFundHandler(fs, ms, nil)
 
 		// Create funds: one with symbol (will succeed) and one without (will fail)
 		portfolio := testutil.NewPortfolio().Build(t, db)
@@ -711,7 +712,7 @@ func TestFundHandler_UpdateAllFundHistory(t *testing.T) {
 		mockYahoo := testutil.NewMockYahooClient()
 		fs := testutil.NewTestFundServiceWithMockYahoo(t, db, mockYahoo)
 		ms := testutil.NewTestMaterializedService(t, db)
-		handler := handlers.NewFundHandler(fs, ms)
+		handler := handlers.NewFundHandler(fs, ms, nil)
 
 		// Create funds without symbols or transactions (will fail)
 		fund1 := testutil.NewFund().WithSymbol("").WithName("Fund1").Build(t, db)
@@ -758,7 +759,7 @@ func TestFundHandler_UpdateAllFundHistory(t *testing.T) {
 		db := testutil.SetupTestDB(t)
 		fs := testutil.NewTestFundService(t, db)
 		ms := testutil.NewTestMaterializedService(t, db)
-		handler := handlers.NewFundHandler(fs, ms)
+		handler := handlers.NewFundHandler(fs, ms, nil)
 
 		req := httptest.NewRequest(http.MethodPost, "/api/fund/update-all-prices", nil)
 		w := httptest.NewRecorder()
@@ -784,7 +785,7 @@ func TestFundHandler_UpdateAllFundHistory(t *testing.T) {
 		db := testutil.SetupTestDB(t)
 		fs := testutil.NewTestFundService(t, db)
 		ms := testutil.NewTestMaterializedService(t, db)
-		handler := handlers.NewFundHandler(fs, ms)
+		handler := handlers.NewFundHandler(fs, ms, nil)
 
 		// Create a fund first to ensure GetAllFunds would have data
 		testutil.NewFund().Build(t, db)
@@ -817,7 +818,7 @@ func TestFundHandler_UpdateAllFundHistory(t *testing.T) {
 		mockYahoo := testutil.NewMockYahooClient()
 		fs := testutil.NewTestFundServiceWithMockYahoo(t, db, mockYahoo)
 		ms := testutil.NewTestMaterializedService(t, db)
-		handler := handlers.NewFundHandler(fs, ms)
+		handler := handlers.NewFundHandler(fs, ms, nil)
 
 		// Create fund with transaction and all prices already populated
 		portfolio := testutil.NewPortfolio().Build(t, db)
@@ -870,4 +871,59 @@ func TestFundHandler_UpdateAllFundHistory(t *testing.T) {
 			t.Errorf("Expected 0 Yahoo API calls, got %d", mockYahoo.QueryCount)
 		}
 	})
+
+	t.Run("async runs the update as a job", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		fs := testutil.NewTestFundServiceWithMockYahoo(t, db, testutil.NewMockYahooClient())
+		ms := testutil.NewTestMaterializedService(t, db)
+		js := testutil.NewTestJobService(t, db)
+		js.Register(model.JobTypeFundPriceUpdate, func(ctx context.Context, _ json.RawMessage) (any, error) {
+			return fs.UpdateAllFundHistory(ctx)
+		})
+		handler := handlers.NewFundHandler(fs, ms, js)
+
+		portfolio := testutil.NewPortfolio().Build(t, db)
+		fund := testutil.NewFund().WithSymbol("AAPL").WithName("Apple").Build(t, db)
+		pf := testutil.NewPortfolioFund(portfolio.ID, fund.ID).Build(t, db)
+		testutil.NewTransaction(pf.ID).WithDate(time.Now().UTC().AddDate(0, 0, -5).Truncate(24*time.Hour)).Build(t, db)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/fund/update-all-prices?async=true", nil)
+		w := httptest.NewRecorder()
+
+		handler.UpdateAllFundHistory(w, req)
+
+		if w.Code != http.StatusAccepted {
+			t.Fatalf("Expected status 202, got %d: %s", w.Code, w.Body.String())
+		}
+
+		var job model.Job
+		if err := json.NewDecoder(w.Body).Decode(&job); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		if job.Type != string(model.JobTypeFundPriceUpdate) || job.ID == "" {
+			t.Fatalf("Expected a fund_price_update job, got %+v", job)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := js.Wait(ctx); err != nil {
+			t.Fatalf("job did not finish: %v", err)
+		}
+
+		finished, err := js.GetJob(job.ID)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if finished.Status != string(model.JobStatusSucceeded) || finished.Progress != 100 {
+			t.Errorf("Expected succeeded job at 100%%, got %s at %d%%: %s", finished.Status, finished.Progress, finished.Error)
+		}
+
+		var result model.AllFundUpdateResponse
+		if err := json.Unmarshal(finished.Result, &result); err != nil {
+			t.Fatalf("Failed to decode job result: %v", err)
+		}
+		if result.TotalUpdated != 1 {
+			t.Errorf("Expected 1 updated fund in the job result, got %d", result.TotalUpdated)
+		}
+	})
 }
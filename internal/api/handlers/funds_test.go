@@ -23,7 +23,7 @@ func TestFundHandler_GetAllFunds(t *testing.T) {
 		db := testutil.SetupTestDB(t)
 		fs := testutil.NewTestFundService(t, db)
 		ms := testutil.NewTestMaterializedService(t, db)
-		handler := handlers.NewFundHandler(fs, ms)
+		handler := handlers.NewFundHandler(fs, ms, nil)
 
 		req := httptest.NewRequest(http.MethodGet, "/api/fund/", nil)
 		w := httptest.NewRecorder()
@@ -54,7 +54,7 @@ func TestFundHandler_GetAllFunds(t *testing.T) {
 		db := testutil.SetupTestDB(t)
 		fs := testutil.NewTestFundService(t, db)
 		ms := testutil.NewTestMaterializedService(t, db)
-		handler := handlers.NewFundHandler(fs, ms)
+		handler := handlers.NewFundHandler(fs, ms, nil)
 
 		f1 := testutil.NewFund().WithName("AAPL").Build(t, db)
 		f2 := testutil.NewFund().WithName("GOOGL").Build(t, db)
@@ -108,7 +108,7 @@ func TestFundHandler_GetAllFunds(t *testing.T) {
 		db := testutil.SetupTestDB(t)
 		fs := testutil.NewTestFundService(t, db)
 		ms := testutil.NewTestMaterializedService(t, db)
-		handler := handlers.NewFundHandler(fs, ms)
+		handler := handlers.NewFundHandler(fs, ms, nil)
 
 		f := testutil.NewFund().
 			WithName("Apple").
@@ -172,7 +172,7 @@ func TestFundHandler_GetAllFunds(t *testing.T) {
 		db := testutil.SetupTestDB(t)
 		fs := testutil.NewTestFundService(t, db)
 		ms := testutil.NewTestMaterializedService(t, db)
-		handler := handlers.NewFundHandler(fs, ms)
+		handler := handlers.NewFundHandler(fs, ms, nil)
 
 		db.Close() // Force database error
 
@@ -203,7 +203,7 @@ func TestFundHandler_GetFund(t *testing.T) {
 		db := testutil.SetupTestDB(t)
 		fs := testutil.NewTestFundService(t, db)
 		ms := testutil.NewTestMaterializedService(t, db)
-		handler := handlers.NewFundHandler(fs, ms)
+		handler := handlers.NewFundHandler(fs, ms, nil)
 
 		fund := testutil.NewFund().WithName("AAPL").Build(t, db)
 
@@ -240,7 +240,7 @@ func TestFundHandler_GetFund(t *testing.T) {
 		db := testutil.SetupTestDB(t)
 		fs := testutil.NewTestFundService(t, db)
 		ms := testutil.NewTestMaterializedService(t, db)
-		handler := handlers.NewFundHandler(fs, ms)
+		handler := handlers.NewFundHandler(fs, ms, nil)
 
 		nonExistentID := testutil.MakeID()
 
@@ -270,7 +270,7 @@ func TestFundHandler_GetFund(t *testing.T) {
 		db := testutil.SetupTestDB(t)
 		fs := testutil.NewTestFundService(t, db)
 		ms := testutil.NewTestMaterializedService(t, db)
-		handler := handlers.NewFundHandler(fs, ms)
+		handler := handlers.NewFundHandler(fs, ms, nil)
 
 		fund := testutil.NewFund().WithName("AAPL").Build(t, db)
 
@@ -307,7 +307,7 @@ func TestFundHandler_GetSymbol(t *testing.T) {
 		db := testutil.SetupTestDB(t)
 		fs := testutil.NewTestFundService(t, db)
 		ms := testutil.NewTestMaterializedService(t, db)
-		handler := handlers.NewFundHandler(fs, ms)
+		handler := handlers.NewFundHandler(fs, ms, nil)
 
 		symbol := testutil.NewSymbol().WithSymbol("AAPL").Build(t, db)
 
@@ -343,7 +343,7 @@ func TestFundHandler_GetSymbol(t *testing.T) {
 		db := testutil.SetupTestDB(t)
 		fs := testutil.NewTestFundService(t, db)
 		ms := testutil.NewTestMaterializedService(t, db)
-		handler := handlers.NewFundHandler(fs, ms)
+		handler := handlers.NewFundHandler(fs, ms, nil)
 
 		req := testutil.NewRequestWithURLParams(
 			http.MethodGet,
@@ -372,7 +372,7 @@ func TestFundHandler_GetSymbol(t *testing.T) {
 		mockYahoo := testutil.NewMockYahooClient().WithError(fmt.Errorf("no results returned for symbol NONEXISTENT"))
 		fs := testutil.NewTestFundServiceWithMockYahoo(t, db, mockYahoo)
 		ms := testutil.NewTestMaterializedService(t, db)
-		handler := handlers.NewFundHandler(fs, ms)
+		handler := handlers.NewFundHandler(fs, ms, nil)
 
 		req := testutil.NewRequestWithURLParams(
 			http.MethodGet,
@@ -392,7 +392,7 @@ func TestFundHandler_GetSymbol(t *testing.T) {
 		db := testutil.SetupTestDB(t)
 		fs := testutil.NewTestFundService(t, db)
 		ms := testutil.NewTestMaterializedService(t, db)
-		handler := handlers.NewFundHandler(fs, ms)
+		handler := handlers.NewFundHandler(fs, ms, nil)
 
 		symbol := testutil.NewSymbol().WithSymbol("AAPL").Build(t, db)
 
@@ -428,7 +428,7 @@ func TestFundHandler_GetFundHistory(t *testing.T) {
 		db := testutil.SetupTestDB(t)
 		fs := testutil.NewTestFundService(t, db)
 		ms := testutil.NewTestMaterializedService(t, db)
-		handler := handlers.NewFundHandler(fs, ms)
+		handler := handlers.NewFundHandler(fs, ms, nil)
 
 		portfolio := testutil.NewPortfolio().Build(t, db)
 
@@ -461,7 +461,7 @@ func TestFundHandler_GetFundHistory(t *testing.T) {
 		db := testutil.SetupTestDB(t)
 		fs := testutil.NewTestFundService(t, db)
 		ms := testutil.NewTestMaterializedService(t, db)
-		handler := handlers.NewFundHandler(fs, ms)
+		handler := handlers.NewFundHandler(fs, ms, nil)
 
 		portfolio := testutil.NewPortfolio().Build(t, db)
 
@@ -492,7 +492,7 @@ func TestFundHandler_GetFundHistory(t *testing.T) {
 		db := testutil.SetupTestDB(t)
 		fs := testutil.NewTestFundService(t, db)
 		ms := testutil.NewTestMaterializedService(t, db)
-		handler := handlers.NewFundHandler(fs, ms)
+		handler := handlers.NewFundHandler(fs, ms, nil)
 
 		portfolio := testutil.NewPortfolio().Build(t, db)
 
@@ -528,7 +528,7 @@ func TestFundHandler_CheckUsage(t *testing.T) {
 		db := testutil.SetupTestDB(t)
 		fs := testutil.NewTestFundService(t, db)
 		ms := testutil.NewTestMaterializedService(t, db)
-		handler := handlers.NewFundHandler(fs, ms)
+		handler := handlers.NewFundHandler(fs, ms, nil)
 
 		fund := testutil.NewFund().Build(t, db)
 
@@ -564,7 +564,7 @@ func TestFundHandler_CheckUsage(t *testing.T) {
 		db := testutil.SetupTestDB(t)
 		fs := testutil.NewTestFundService(t, db)
 		ms := testutil.NewTestMaterializedService(t, db)
-		handler := handlers.NewFundHandler(fs, ms)
+		handler := handlers.NewFundHandler(fs, ms, nil)
 
 		fund := testutil.NewFund().Build(t, db)
 		portfolio := testutil.NewPortfolio().Build(t, db)
@@ -606,7 +606,7 @@ func TestFundHandler_CheckUsage(t *testing.T) {
 		db := testutil.SetupTestDB(t)
 		fs := testutil.NewTestFundService(t, db)
 		ms := testutil.NewTestMaterializedService(t, db)
-		handler := handlers.NewFundHandler(fs, ms)
+		handler := handlers.NewFundHandler(fs, ms, nil)
 
 		fund := testutil.NewFund().Build(t, db)
 		db.Close() // Force database error
@@ -642,7 +642,7 @@ func TestFundHandler_CreateFund(t *testing.T) {
 		db := testutil.SetupTestDB(t)
 		fs := testutil.NewTestFundService(t, db)
 		ms := testutil.NewTestMaterializedService(t, db)
-		handler := handlers.NewFundHandler(fs, ms)
+		handler := handlers.NewFundHandler(fs, ms, nil)
 
 		reqBody := `{
 			"name":           "Apple Inc.",
@@ -702,7 +702,7 @@ func TestFundHandler_CreateFund(t *testing.T) {
 		db := testutil.SetupTestDB(t)
 		fs := testutil.NewTestFundService(t, db)
 		ms := testutil.NewTestMaterializedService(t, db)
-		handler := handlers.NewFundHandler(fs, ms)
+		handler := handlers.NewFundHandler(fs, ms, nil)
 
 		reqBody := `{
 			"isin":           "US0378331005",
@@ -736,7 +736,7 @@ func TestFundHandler_CreateFund(t *testing.T) {
 		db := testutil.SetupTestDB(t)
 		fs := testutil.NewTestFundService(t, db)
 		ms := testutil.NewTestMaterializedService(t, db)
-		handler := handlers.NewFundHandler(fs, ms)
+		handler := handlers.NewFundHandler(fs, ms, nil)
 
 		reqBody := `{
 			"name":           "Apple Inc.",
@@ -762,7 +762,7 @@ func TestFundHandler_CreateFund(t *testing.T) {
 		db := testutil.SetupTestDB(t)
 		fs := testutil.NewTestFundService(t, db)
 		ms := testutil.NewTestMaterializedService(t, db)
-		handler := handlers.NewFundHandler(fs, ms)
+		handler := handlers.NewFundHandler(fs, ms, nil)
 
 		reqBody := `{
 			"name":           "Apple Inc.",
@@ -788,7 +788,7 @@ func TestFundHandler_CreateFund(t *testing.T) {
 		db := testutil.SetupTestDB(t)
 		fs := testutil.NewTestFundService(t, db)
 		ms := testutil.NewTestMaterializedService(t, db)
-		handler := handlers.NewFundHandler(fs, ms)
+		handler := handlers.NewFundHandler(fs, ms, nil)
 
 		reqBody := `{
 			"name":            "Apple Inc.",
@@ -814,7 +814,7 @@ func TestFundHandler_CreateFund(t *testing.T) {
 		db := testutil.SetupTestDB(t)
 		fs := testutil.NewTestFundService(t, db)
 		ms := testutil.NewTestMaterializedService(t, db)
-		handler := handlers.NewFundHandler(fs, ms)
+		handler := handlers.NewFundHandler(fs, ms, nil)
 
 		req := httptest.NewRequest(http.MethodPost, "/api/fund", bytes.NewReader([]byte("invalid json")))
 		req.Header.Set("Content-Type", "application/json")
@@ -840,7 +840,7 @@ func TestFundHandler_CreateFund(t *testing.T) {
 		db := testutil.SetupTestDB(t)
 		fs := testutil.NewTestFundService(t, db)
 		ms := testutil.NewTestMaterializedService(t, db)
-		handler := handlers.NewFundHandler(fs, ms)
+		handler := handlers.NewFundHandler(fs, ms, nil)
 
 		db.Close() // Force database error
 
@@ -870,7 +870,7 @@ func TestFundHandler_UpdateFund(t *testing.T) {
 		db := testutil.SetupTestDB(t)
 		fs := testutil.NewTestFundService(t, db)
 		ms := testutil.NewTestMaterializedService(t, db)
-		handler := handlers.NewFundHandler(fs, ms)
+		handler := handlers.NewFundHandler(fs, ms, nil)
 
 		fund := testutil.NewFund().
 			WithName("Old Name").
@@ -915,7 +915,7 @@ func TestFundHandler_UpdateFund(t *testing.T) {
 		db := testutil.SetupTestDB(t)
 		fs := testutil.NewTestFundService(t, db)
 		ms := testutil.NewTestMaterializedService(t, db)
-		handler := handlers.NewFundHandler(fs, ms)
+		handler := handlers.NewFundHandler(fs, ms, nil)
 
 		nonExistentID := testutil.MakeID()
 
@@ -952,7 +952,7 @@ func TestFundHandler_UpdateFund(t *testing.T) {
 		db := testutil.SetupTestDB(t)
 		fs := testutil.NewTestFundService(t, db)
 		ms := testutil.NewTestMaterializedService(t, db)
-		handler := handlers.NewFundHandler(fs, ms)
+		handler := handlers.NewFundHandler(fs, ms, nil)
 
 		fund := testutil.NewFund().Build(t, db)
 
@@ -980,7 +980,7 @@ func TestFundHandler_UpdateFund(t *testing.T) {
 		db := testutil.SetupTestDB(t)
 		fs := testutil.NewTestFundService(t, db)
 		ms := testutil.NewTestMaterializedService(t, db)
-		handler := handlers.NewFundHandler(fs, ms)
+		handler := handlers.NewFundHandler(fs, ms, nil)
 
 		fund := testutil.NewFund().Build(t, db)
 
@@ -1013,7 +1013,7 @@ func TestFundHandler_UpdateFund(t *testing.T) {
 		db := testutil.SetupTestDB(t)
 		fs := testutil.NewTestFundService(t, db)
 		ms := testutil.NewTestMaterializedService(t, db)
-		handler := handlers.NewFundHandler(fs, ms)
+		handler := handlers.NewFundHandler(fs, ms, nil)
 
 		fund := testutil.NewFund().Build(t, db)
 		db.Close() // Force database error
@@ -1044,7 +1044,7 @@ func TestFundHandler_DeleteFund(t *testing.T) {
 		db := testutil.SetupTestDB(t)
 		fs := testutil.NewTestFundService(t, db)
 		ms := testutil.NewTestMaterializedService(t, db)
-		handler := handlers.NewFundHandler(fs, ms)
+		handler := handlers.NewFundHandler(fs, ms, nil)
 
 		fund := testutil.NewFund().Build(t, db)
 
@@ -1074,7 +1074,7 @@ func TestFundHandler_DeleteFund(t *testing.T) {
 		db := testutil.SetupTestDB(t)
 		fs := testutil.NewTestFundService(t, db)
 		ms := testutil.NewTestMaterializedService(t, db)
-		handler := handlers.NewFundHandler(fs, ms)
+		handler := handlers.NewFundHandler(fs, ms, nil)
 
 		nonExistentID := testutil.MakeID()
 
@@ -1105,7 +1105,7 @@ func TestFundHandler_DeleteFund(t *testing.T) {
 		db := testutil.SetupTestDB(t)
 		fs := testutil.NewTestFundService(t, db)
 		ms := testutil.NewTestMaterializedService(t, db)
-		handler := handlers.NewFundHandler(fs, ms)
+		handler := handlers.NewFundHandler(fs, ms, nil)
 
 		// Create fund with portfolio and transaction (makes it "in use")
 		fund := testutil.NewFund().Build(t, db)
@@ -1145,7 +1145,7 @@ func TestFundHandler_DeleteFund(t *testing.T) {
 		db := testutil.SetupTestDB(t)
 		fs := testutil.NewTestFundService(t, db)
 		ms := testutil.NewTestMaterializedService(t, db)
-		handler := handlers.NewFundHandler(fs, ms)
+		handler := handlers.NewFundHandler(fs, ms, nil)
 
 		fund := testutil.NewFund().Build(t, db)
 		db.Close() // Force database error
@@ -334,6 +334,27 @@ func (h *IbkrHandler) TestIbkrConnection(w http.ResponseWriter, r *http.Request)
 	response.RespondJSON(w, http.StatusOK, map[string]bool{"success": true})
 }
 
+// RotateEncryptionKey handles POST requests to re-encrypt the stored IBKR flex token with the
+// newest configured encryption key. Older keys remain usable for decryption until they are
+// removed from the configuration.
+//
+// Endpoint: POST /api/ibkr/config/rotate-key
+// Response: 200 OK with model.IbkrKeyRotation
+// Error: 500 Internal Server Error if no key decrypts the stored token or the update fails
+func (h *IbkrHandler) RotateEncryptionKey(w http.ResponseWriter, r *http.Request) {
+	ibkrLog.DebugContext(r.Context(), "rotate encryption key request")
+
+	rotation, err := h.ibkrService.RotateEncryptionKey(r.Context())
+	if err != nil {
+		ibkrLog.ErrorContext(r.Context(), "failed to rotate encryption key", "error", err)
+		response.RespondInternalError(w, r, apperrors.ErrFailedToRotateEncryptionKey.Error())
+		return
+	}
+
+	ibkrLog.InfoContext(r.Context(), "encryption key rotated", "keyId", rotation.KeyID, "reencrypted", rotation.Reencrypted)
+	response.RespondJSON(w, http.StatusOK, rotation)
+}
+
 // GetTransaction handles GET /api/ibkr/inbox/{uuid}
 // Retrieves a single IBKR transaction with its allocation details (if processed).
 //
@@ -1545,6 +1545,65 @@ func TestIbkrHandler_ImportFlexReport(t *testing.T) {
 	})
 }
 
+func TestIbkrHandler_RotateEncryptionKey(t *testing.T) {
+	t.Run("returns 200 and re-encrypts the stored token", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		oldKey := decodedTestFernetKey(t)
+		var newKey fernet.Key
+		if err := newKey.Generate(); err != nil {
+			t.Fatalf("Failed to generate key: %v", err)
+		}
+
+		encToken, err := fernet.EncryptAndSign([]byte("some_token"), oldKey)
+		if err != nil {
+			t.Fatalf("Failed to encrypt token: %v", err)
+		}
+		_, err = db.Exec(`
+			INSERT INTO ibkr_config (
+				id, flex_token, flex_query_id, auto_import_enabled, enabled,
+				default_allocation_enabled, created_at, updated_at
+			) VALUES (?, ?, ?, ?, ?, ?, datetime('now'), datetime('now'))
+		`, testutil.MakeID(), string(encToken), validFlexQueryID, false, true, false)
+		if err != nil {
+			t.Fatalf("Failed to insert test config: %v", err)
+		}
+
+		is := testutil.NewTestIbkrService(t, db, service.IbkrWithEncryptionKeys([]*fernet.Key{&newKey, oldKey}))
+		handler := NewIbkrHandler(is)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/ibkr/config/rotate-key", nil)
+		w := httptest.NewRecorder()
+
+		handler.RotateEncryptionKey(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+
+		var rotation model.IbkrKeyRotation
+		if err := json.NewDecoder(w.Body).Decode(&rotation); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		if !rotation.Reencrypted || rotation.ConfiguredKeys != 2 {
+			t.Errorf("Expected a re-encryption with 2 configured keys, got %+v", rotation)
+		}
+	})
+
+	t.Run("returns 500 without an encryption key", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		handler := NewIbkrHandler(testutil.NewTestIbkrService(t, db))
+
+		req := httptest.NewRequest(http.MethodPost, "/api/ibkr/config/rotate-key", nil)
+		w := httptest.NewRecorder()
+
+		handler.RotateEncryptionKey(w, req)
+
+		if w.Code != http.StatusInternalServerError {
+			t.Errorf("Expected 500, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+}
+
 func TestIbkrHandler_TestIbkrConnection(t *testing.T) {
 	// validBody contains a 24-digit token (minimum accepted) and a numeric queryId.
 	const validBody = `{"flexToken":"123456789012345678901234","flexQueryId":"12345"}`
@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/api/request"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/api/response"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/apperrors"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/model"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/service"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/validation"
+)
+
+// InboxHandler handles HTTP requests for the source-agnostic transaction inbox.
+// Listing and staging are served here; per-transaction actions (allocate, ignore,
+// match-dividend, ...) are shared with IbkrHandler.
+type InboxHandler struct {
+	inboxService *service.InboxService
+}
+
+// NewInboxHandler creates a new InboxHandler with the provided service dependency.
+func NewInboxHandler(inboxService *service.InboxService) *InboxHandler {
+	return &InboxHandler{
+		inboxService: inboxService,
+	}
+}
+
+// GetInbox handles GET requests to retrieve staged transactions from every importer.
+//
+// Endpoint: GET /api/inbox
+// Query params:
+//   - source: Filter by importer source (optional, e.g., "ibkr", "csv")
+//   - status: Filter by transaction status (optional, defaults to "pending")
+//   - transactionType: Filter by transaction type (optional, e.g., "dividend", "buy")
+//
+// Response: 200 OK with array of IBKRTransaction
+// Error: 400 Bad Request if source is malformed
+// Error: 500 Internal Server Error if retrieval fails
+func (h *InboxHandler) GetInbox(w http.ResponseWriter, r *http.Request) {
+	filter := model.InboxFilter{
+		Source:          r.URL.Query().Get("source"),
+		Status:          r.URL.Query().Get("status"),
+		TransactionType: r.URL.Query().Get("transactionType"),
+	}
+
+	ibkrLog.DebugContext(r.Context(), "get inbox request", "inbox_source", filter.Source, "transaction_type", filter.TransactionType)
+
+	if filter.Source != "" {
+		if err := validation.ValidateInboxSource(filter.Source); err != nil {
+			response.RespondError(w, http.StatusBadRequest, "validation failed", err.Error())
+			return
+		}
+	}
+
+	inbox, err := h.inboxService.GetInbox(filter)
+	if err != nil {
+		ibkrLog.ErrorContext(r.Context(), "failed to get inbox", "error", err, "inbox_source", filter.Source)
+		response.RespondInternalError(w, r, apperrors.ErrFailedToRetrieveInboxTransactions.Error())
+		return
+	}
+
+	response.RespondJSON(w, http.StatusOK, inbox)
+}
+
+// GetInboxCount handles GET requests to retrieve the number of pending inbox transactions.
+//
+// Endpoint: GET /api/inbox/count
+// Query params:
+//   - source: Count only this importer's transactions (optional, defaults to all sources)
+//
+// Response: 200 OK with {"count": <number>}
+// Error: 400 Bad Request if source is malformed
+// Error: 500 Internal Server Error if retrieval fails
+func (h *InboxHandler) GetInboxCount(w http.ResponseWriter, r *http.Request) {
+	source := r.URL.Query().Get("source")
+
+	ibkrLog.DebugContext(r.Context(), "get inbox count request", "inbox_source", source)
+
+	if source != "" {
+		if err := validation.ValidateInboxSource(source); err != nil {
+			response.RespondError(w, http.StatusBadRequest, "validation failed", err.Error())
+			return
+		}
+	}
+
+	count, err := h.inboxService.GetInboxCount(source)
+	if err != nil {
+		ibkrLog.ErrorContext(r.Context(), "failed to get inbox count", "error", err, "inbox_source", source)
+		response.RespondInternalError(w, r, apperrors.ErrFailedToRetrieveInboxTransactions.Error())
+		return
+	}
+
+	response.RespondJSON(w, http.StatusOK, count)
+}
+
+// StageTransactions handles POST requests to stage a batch of transactions in the inbox.
+// Entries already staged under the same source and external reference are skipped.
+//
+// Endpoint: POST /api/inbox
+// Request body: StageInboxTransactionsRequest
+// Response: 201 Created with InboxStageResult
+// Error: 400 Bad Request if the body is invalid or validation fails
+// Error: 500 Internal Server Error if staging fails
+func (h *InboxHandler) StageTransactions(w http.ResponseWriter, r *http.Request) {
+	req, err := parseJSON[request.StageInboxTransactionsRequest](r)
+	if err != nil {
+		response.RespondError(w, http.StatusBadRequest, "invalid request body", err.Error())
+		return
+	}
+
+	ibkrLog.DebugContext(r.Context(), "stage inbox transactions request", "inbox_source", req.Source, "count", len(req.Transactions))
+
+	if err := validation.ValidateStageInboxTransactions(req); err != nil {
+		response.RespondError(w, http.StatusBadRequest, "validation failed", err.Error())
+		return
+	}
+
+	result, err := h.inboxService.StageTransactions(r.Context(), req)
+	if err != nil {
+		ibkrLog.ErrorContext(r.Context(), "failed to stage inbox transactions", "error", err, "inbox_source", req.Source)
+		response.RespondInternalError(w, r, apperrors.ErrFailedToStageInboxTransactions.Error())
+		return
+	}
+
+	response.RespondJSON(w, http.StatusCreated, result)
+}
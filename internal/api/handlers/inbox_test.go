@@ -0,0 +1,214 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/model"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/testutil"
+)
+
+func setupInboxHandler(t *testing.T) (*InboxHandler, *sql.DB) {
+	t.Helper()
+	db := testutil.SetupTestDB(t)
+	return NewInboxHandler(testutil.NewTestInboxService(t, db)), db
+}
+
+func TestInboxHandler_GetInbox(t *testing.T) {
+	t.Run("returns pending transactions from every source", func(t *testing.T) {
+		handler, db := setupInboxHandler(t)
+
+		testutil.NewIBKRTransaction().WithStatus("pending").Build(t, db)
+		testutil.NewIBKRTransaction().WithStatus("pending").WithSource("csv").Build(t, db)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/inbox", nil)
+		w := httptest.NewRecorder()
+
+		handler.GetInbox(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+
+		var response []model.IBKRTransaction
+		//nolint:errcheck // Test assertion - decode failure would cause test to fail anyway
+		json.NewDecoder(w.Body).Decode(&response)
+
+		if len(response) != 2 {
+			t.Errorf("Expected 2 transactions, got %d", len(response))
+		}
+	})
+
+	t.Run("filters by source", func(t *testing.T) {
+		handler, db := setupInboxHandler(t)
+
+		testutil.NewIBKRTransaction().WithStatus("pending").Build(t, db)
+		testutil.NewIBKRTransaction().WithStatus("pending").WithSource("csv").Build(t, db)
+
+		req := testutil.NewRequestWithQueryParams(http.MethodGet, "/api/inbox", map[string]string{"source": "csv"})
+		w := httptest.NewRecorder()
+
+		handler.GetInbox(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+
+		var response []model.IBKRTransaction
+		//nolint:errcheck // Test assertion - decode failure would cause test to fail anyway
+		json.NewDecoder(w.Body).Decode(&response)
+
+		if len(response) != 1 || response[0].Source != "csv" {
+			t.Errorf("Expected 1 csv transaction, got %+v", response)
+		}
+	})
+
+	t.Run("returns 400 for malformed source", func(t *testing.T) {
+		handler, _ := setupInboxHandler(t)
+
+		req := testutil.NewRequestWithQueryParams(http.MethodGet, "/api/inbox", map[string]string{"source": "Not A Slug"})
+		w := httptest.NewRecorder()
+
+		handler.GetInbox(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected 400, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("returns 500 when database is closed", func(t *testing.T) {
+		handler, db := setupInboxHandler(t)
+		db.Close()
+
+		req := httptest.NewRequest(http.MethodGet, "/api/inbox", nil)
+		w := httptest.NewRecorder()
+
+		handler.GetInbox(w, req)
+
+		if w.Code != http.StatusInternalServerError {
+			t.Errorf("Expected 500, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+}
+
+func TestInboxHandler_GetInboxCount(t *testing.T) {
+	t.Run("counts every source by default", func(t *testing.T) {
+		handler, db := setupInboxHandler(t)
+
+		testutil.NewIBKRTransaction().WithStatus("pending").Build(t, db)
+		testutil.NewIBKRTransaction().WithStatus("pending").WithSource("manual").Build(t, db)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/inbox/count", nil)
+		w := httptest.NewRecorder()
+
+		handler.GetInboxCount(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+
+		var response model.IBKRInboxCount
+		//nolint:errcheck // Test assertion - decode failure would cause test to fail anyway
+		json.NewDecoder(w.Body).Decode(&response)
+
+		if response.Count != 2 {
+			t.Errorf("Expected count 2, got %d", response.Count)
+		}
+	})
+
+	t.Run("counts a single source", func(t *testing.T) {
+		handler, db := setupInboxHandler(t)
+
+		testutil.NewIBKRTransaction().WithStatus("pending").Build(t, db)
+		testutil.NewIBKRTransaction().WithStatus("pending").WithSource("manual").Build(t, db)
+
+		req := testutil.NewRequestWithQueryParams(http.MethodGet, "/api/inbox/count", map[string]string{"source": "manual"})
+		w := httptest.NewRecorder()
+
+		handler.GetInboxCount(w, req)
+
+		var response model.IBKRInboxCount
+		//nolint:errcheck // Test assertion - decode failure would cause test to fail anyway
+		json.NewDecoder(w.Body).Decode(&response)
+
+		if response.Count != 1 {
+			t.Errorf("Expected count 1, got %d", response.Count)
+		}
+	})
+}
+
+func TestInboxHandler_StageTransactions(t *testing.T) {
+	body := `{"source":"csv","transactions":[{"externalId":"row-1","date":"2025-03-14","isin":"IE00B3RBWM25","type":"buy","quantity":10,"price":100,"totalAmount":1000,"currency":"EUR","fees":0}]}`
+
+	t.Run("stages transactions", func(t *testing.T) {
+		handler, _ := setupInboxHandler(t)
+
+		req := testutil.NewRequestWithBody(http.MethodPost, "/api/inbox", body)
+		w := httptest.NewRecorder()
+
+		handler.StageTransactions(w, req)
+
+		if w.Code != http.StatusCreated {
+			t.Fatalf("Expected 201, got %d: %s", w.Code, w.Body.String())
+		}
+
+		var response model.InboxStageResult
+		//nolint:errcheck // Test assertion - decode failure would cause test to fail anyway
+		json.NewDecoder(w.Body).Decode(&response)
+
+		if response.Source != "csv" || response.Staged != 1 || response.Skipped != 0 {
+			t.Errorf("Unexpected result: %+v", response)
+		}
+	})
+
+	t.Run("resubmitting the same batch skips duplicates", func(t *testing.T) {
+		handler, _ := setupInboxHandler(t)
+
+		first := httptest.NewRecorder()
+		handler.StageTransactions(first, testutil.NewRequestWithBody(http.MethodPost, "/api/inbox", body))
+		if first.Code != http.StatusCreated {
+			t.Fatalf("Expected 201, got %d: %s", first.Code, first.Body.String())
+		}
+
+		w := httptest.NewRecorder()
+		handler.StageTransactions(w, testutil.NewRequestWithBody(http.MethodPost, "/api/inbox", body))
+
+		var response model.InboxStageResult
+		//nolint:errcheck // Test assertion - decode failure would cause test to fail anyway
+		json.NewDecoder(w.Body).Decode(&response)
+
+		if response.Staged != 0 || response.Skipped != 1 {
+			t.Errorf("Expected all entries skipped, got %+v", response)
+		}
+	})
+
+	t.Run("returns 400 for invalid JSON", func(t *testing.T) {
+		handler, _ := setupInboxHandler(t)
+
+		req := testutil.NewRequestWithBody(http.MethodPost, "/api/inbox", `{invalid`)
+		w := httptest.NewRecorder()
+
+		handler.StageTransactions(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected 400, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("returns 400 for reserved ibkr source", func(t *testing.T) {
+		handler, _ := setupInboxHandler(t)
+
+		req := testutil.NewRequestWithBody(http.MethodPost, "/api/inbox",
+			`{"source":"ibkr","transactions":[{"date":"2025-03-14","isin":"IE00B3RBWM25","type":"buy","quantity":1,"price":1,"totalAmount":1,"currency":"EUR","fees":0}]}`)
+		w := httptest.NewRecorder()
+
+		handler.StageTransactions(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected 400, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+}
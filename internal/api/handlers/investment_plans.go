@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/api/request"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/api/response"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/apperrors"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/model"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/service"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/validation"
+)
+
+// InvestmentPlanHandler handles HTTP requests for recurring investment plan endpoints.
+type InvestmentPlanHandler struct {
+	planService *service.InvestmentPlanService
+}
+
+// NewInvestmentPlanHandler creates a new InvestmentPlanHandler with the provided service dependency.
+func NewInvestmentPlanHandler(planService *service.InvestmentPlanService) *InvestmentPlanHandler {
+	return &InvestmentPlanHandler{
+		planService: planService,
+	}
+}
+
+// AllInvestmentPlans handles GET requests to list the investment plans of the portfolios the caller can read.
+//
+// Endpoint: GET /api/investment-plan
+// Response: 200 OK with array of InvestmentPlan
+// Error: 500 Internal Server Error if retrieval fails
+func (h *InvestmentPlanHandler) AllInvestmentPlans(w http.ResponseWriter, r *http.Request) {
+	plans, err := h.planService.GetInvestmentPlans(r.Context(), "")
+	if err != nil {
+		txLog.ErrorContext(r.Context(), "failed to get investment plans", "error", err)
+		response.RespondInternalError(w, r, apperrors.ErrFailedToRetrieveInvestmentPlans.Error())
+		return
+	}
+
+	response.RespondJSON(w, http.StatusOK, plans)
+}
+
+// InvestmentPlansPerPortfolio handles GET requests to list the investment plans of a portfolio.
+//
+// Endpoint: GET /api/investment-plan/portfolio/{uuid}
+// Response: 200 OK with array of InvestmentPlan
+// Error: 400 Bad Request if portfolio ID is invalid (validated by middleware)
+// Error: 500 Internal Server Error if retrieval fails
+func (h *InvestmentPlanHandler) InvestmentPlansPerPortfolio(w http.ResponseWriter, r *http.Request) {
+	portfolioID := chi.URLParam(r, "uuid")
+
+	plans, err := h.planService.GetInvestmentPlans(r.Context(), portfolioID)
+	if err != nil {
+		txLog.ErrorContext(r.Context(), "failed to get investment plans", "error", err, "portfolio_id", portfolioID)
+		response.RespondInternalError(w, r, apperrors.ErrFailedToRetrieveInvestmentPlans.Error())
+		return
+	}
+
+	response.RespondJSON(w, http.StatusOK, plans)
+}
+
+// GetInvestmentPlan handles GET requests to retrieve a single investment plan.
+//
+// Endpoint: GET /api/investment-plan/{uuid}
+// Response: 200 OK with InvestmentPlan
+// Error: 404 Not Found if the plan does not exist
+// Error: 500 Internal Server Error if retrieval fails
+func (h *InvestmentPlanHandler) GetInvestmentPlan(w http.ResponseWriter, r *http.Request) {
+	planID := chi.URLParam(r, "uuid")
+
+	plan, err := h.planService.GetInvestmentPlan(planID)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrInvestmentPlanNotFound) {
+			response.RespondError(w, http.StatusNotFound, apperrors.ErrInvestmentPlanNotFound.Error(), "")
+			return
+		}
+		txLog.ErrorContext(r.Context(), "failed to get investment plan", "error", err, "plan_id", planID)
+		response.RespondInternalError(w, r, apperrors.ErrFailedToRetrieveInvestmentPlans.Error())
+		return
+	}
+
+	response.RespondJSON(w, http.StatusOK, plan)
+}
+
+// CreateInvestmentPlan handles POST requests to create an investment plan. Its buy
+// transactions are generated by the investment_plans scheduled task.
+//
+// Endpoint: POST /api/investment-plan
+// Request Body: CreateInvestmentPlanRequest (portfolioFundId, amount, frequency, dayOfMonth, startDate, endDate, enabled)
+// Response: 201 Created with InvestmentPlan
+// Error: 400 Bad Request if validation fails or the portfolio fund does not exist
+// Error: 403 Forbidden if the caller has read-only access to the portfolio
+// Error: 500 Internal Server Error if creation fails
+func (h *InvestmentPlanHandler) CreateInvestmentPlan(w http.ResponseWriter, r *http.Request) {
+	req, err := parseJSON[request.CreateInvestmentPlanRequest](r)
+	if err != nil {
+		response.RespondError(w, http.StatusBadRequest, "invalid request body", err.Error())
+		return
+	}
+	if err := validation.ValidateCreateInvestmentPlan(req); err != nil {
+		response.RespondError(w, http.StatusBadRequest, "validation failed", err.Error())
+		return
+	}
+	if !authorizePortfolioFund(w, r, req.PortfolioFundID, model.PortfolioAccessWrite) {
+		return
+	}
+
+	plan, err := h.planService.CreateInvestmentPlan(r.Context(), req)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrPortfolioFundNotFound) {
+			response.RespondError(w, http.StatusBadRequest, apperrors.ErrPortfolioFundNotFound.Error(), "")
+			return
+		}
+		txLog.ErrorContext(r.Context(), "failed to create investment plan", "error", err)
+		response.RespondInternalError(w, r, apperrors.ErrFailedToCreateInvestmentPlan.Error())
+		return
+	}
+
+	response.RespondJSON(w, http.StatusCreated, plan)
+}
+
+// UpdateInvestmentPlan handles PUT requests to replace the amount, schedule and enabled
+// flag of an investment plan. Already generated transactions are not changed.
+//
+// Endpoint: PUT /api/investment-plan/{uuid}
+// Request Body: UpdateInvestmentPlanRequest (amount, frequency, dayOfMonth, startDate, endDate, enabled)
+// Response: 200 OK with InvestmentPlan
+// Error: 400 Bad Request if validation fails or request body is invalid
+// Error: 404 Not Found if the plan does not exist
+// Error: 500 Internal Server Error if the update fails
+func (h *InvestmentPlanHandler) UpdateInvestmentPlan(w http.ResponseWriter, r *http.Request) {
+	planID := chi.URLParam(r, "uuid")
+
+	req, err := parseJSON[request.UpdateInvestmentPlanRequest](r)
+	if err != nil {
+		response.RespondError(w, http.StatusBadRequest, "invalid request body", err.Error())
+		return
+	}
+	if err := validation.ValidateUpdateInvestmentPlan(req); err != nil {
+		response.RespondError(w, http.StatusBadRequest, "validation failed", err.Error())
+		return
+	}
+
+	plan, err := h.planService.UpdateInvestmentPlan(r.Context(), planID, req)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrInvestmentPlanNotFound) {
+			response.RespondError(w, http.StatusNotFound, apperrors.ErrInvestmentPlanNotFound.Error(), "")
+			return
+		}
+		txLog.ErrorContext(r.Context(), "failed to update investment plan", "error", err, "plan_id", planID)
+		response.RespondInternalError(w, r, apperrors.ErrFailedToUpdateInvestmentPlan.Error())
+		return
+	}
+
+	response.RespondJSON(w, http.StatusOK, plan)
+}
+
+// DeleteInvestmentPlan handles DELETE requests to remove an investment plan. The
+// transactions it generated are kept as regular transactions.
+//
+// Endpoint: DELETE /api/investment-plan/{uuid}
+// Response: 204 No Content
+// Error: 404 Not Found if the plan does not exist
+// Error: 500 Internal Server Error if deletion fails
+func (h *InvestmentPlanHandler) DeleteInvestmentPlan(w http.ResponseWriter, r *http.Request) {
+	planID := chi.URLParam(r, "uuid")
+
+	if err := h.planService.DeleteInvestmentPlan(r.Context(), planID); err != nil {
+		if errors.Is(err, apperrors.ErrInvestmentPlanNotFound) {
+			response.RespondError(w, http.StatusNotFound, apperrors.ErrInvestmentPlanNotFound.Error(), "")
+			return
+		}
+		txLog.ErrorContext(r.Context(), "failed to delete investment plan", "error", err, "plan_id", planID)
+		response.RespondInternalError(w, r, apperrors.ErrFailedToDeleteInvestmentPlan.Error())
+		return
+	}
+
+	response.RespondJSON(w, http.StatusNoContent, nil)
+}
@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/model"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/testutil"
+)
+
+func TestInvestmentPlanHandler(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	handler := NewInvestmentPlanHandler(testutil.NewTestInvestmentPlanService(t, db))
+	portfolio := testutil.NewPortfolio().Build(t, db)
+	fund := testutil.NewFund().Build(t, db)
+	pf := testutil.NewPortfolioFund(portfolio.ID, fund.ID).Build(t, db)
+
+	var plan model.InvestmentPlan
+
+	t.Run("invalid plan returns 400", func(t *testing.T) {
+		req := testutil.NewRequestWithBody(http.MethodPost, "/api/investment-plan",
+			`{"portfolioFundId":"`+pf.ID+`","amount":100,"frequency":"weekly","dayOfMonth":1,"startDate":"2024-01-01"}`)
+		w := httptest.NewRecorder()
+		handler.CreateInvestmentPlan(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected 400, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("creates a plan", func(t *testing.T) {
+		req := testutil.NewRequestWithBody(http.MethodPost, "/api/investment-plan",
+			`{"portfolioFundId":"`+pf.ID+`","amount":100,"frequency":"monthly","dayOfMonth":15,"startDate":"2024-01-01"}`)
+		w := httptest.NewRecorder()
+		handler.CreateInvestmentPlan(w, req)
+		if w.Code != http.StatusCreated {
+			t.Fatalf("Expected 201, got %d: %s", w.Code, w.Body.String())
+		}
+		//nolint:errcheck // Test assertion - decode failure would cause test to fail anyway
+		json.NewDecoder(w.Body).Decode(&plan)
+		if plan.PortfolioID != portfolio.ID || !plan.Enabled || plan.NextRunDate == nil {
+			t.Errorf("Expected an enabled plan, got %+v", plan)
+		}
+	})
+
+	t.Run("lists the portfolio's plans", func(t *testing.T) {
+		req := testutil.NewRequestWithURLParams(http.MethodGet, "/api/investment-plan/portfolio/"+portfolio.ID, map[string]string{"uuid": portfolio.ID})
+		w := httptest.NewRecorder()
+		handler.InvestmentPlansPerPortfolio(w, req)
+		var plans []model.InvestmentPlan
+		//nolint:errcheck // Test assertion - decode failure would cause test to fail anyway
+		json.NewDecoder(w.Body).Decode(&plans)
+		if w.Code != http.StatusOK || len(plans) != 1 || plans[0].ID != plan.ID {
+			t.Errorf("Expected the created plan, got %d: %+v", w.Code, plans)
+		}
+	})
+
+	t.Run("updates a plan", func(t *testing.T) {
+		req := testutil.NewRequestWithURLParamsAndBody(http.MethodPut, "/api/investment-plan/"+plan.ID, map[string]string{"uuid": plan.ID},
+			`{"amount":250,"frequency":"quarterly","dayOfMonth":1,"startDate":"2024-01-01","enabled":false}`)
+		w := httptest.NewRecorder()
+		handler.UpdateInvestmentPlan(w, req)
+		var updated model.InvestmentPlan
+		//nolint:errcheck // Test assertion - decode failure would cause test to fail anyway
+		json.NewDecoder(w.Body).Decode(&updated)
+		if w.Code != http.StatusOK || updated.Amount != 250 || updated.Enabled {
+			t.Errorf("Expected the updated plan, got %d: %+v", w.Code, updated)
+		}
+	})
+
+	t.Run("deletes a plan", func(t *testing.T) {
+		params := map[string]string{"uuid": plan.ID}
+		w := httptest.NewRecorder()
+		handler.DeleteInvestmentPlan(w, testutil.NewRequestWithURLParams(http.MethodDelete, "/api/investment-plan/"+plan.ID, params))
+		if w.Code != http.StatusNoContent {
+			t.Fatalf("Expected 204, got %d: %s", w.Code, w.Body.String())
+		}
+
+		w = httptest.NewRecorder()
+		handler.GetInvestmentPlan(w, testutil.NewRequestWithURLParams(http.MethodGet, "/api/investment-plan/"+plan.ID, params))
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Expected 404, got %d", w.Code)
+		}
+	})
+}
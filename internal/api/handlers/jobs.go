@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/api/request"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/api/response"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/apperrors"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/logging"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/service"
+)
+
+var jobLog = logging.NewLogger("system")
+
+// JobHandler handles HTTP requests for background jobs.
+type JobHandler struct {
+	jobService *service.JobService
+}
+
+// NewJobHandler creates a new JobHandler with the provided service dependency.
+func NewJobHandler(jobService *service.JobService) *JobHandler {
+	return &JobHandler{
+		jobService: jobService,
+	}
+}
+
+// GetJobs handles GET requests to list background jobs, newest first.
+//
+// Endpoint: GET /api/jobs
+// Query params:
+//   - type: Comma-separated job types (fund_price_update, ibkr_import, materialized_regen, etc.)
+//   - status: Comma-separated statuses (pending, running, succeeded, failed)
+//   - limit: Maximum number of jobs to return (1-250, default: 50)
+//
+// Response: 200 OK with array of Job
+// Error: 400 Bad Request if a filter is invalid
+// Error: 500 Internal Server Error if retrieval fails
+func (h *JobHandler) GetJobs(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	jobLog.DebugContext(r.Context(), "get jobs request", "type", q.Get("type"), "status", q.Get("status"))
+
+	filters, err := request.ParseJobFilters(q.Get("type"), q.Get("status"), q.Get("limit"))
+	if err != nil {
+		response.RespondError(w, http.StatusBadRequest, "invalid filter parameters", err.Error())
+		return
+	}
+
+	jobs, err := h.jobService.GetJobs(filters)
+	if err != nil {
+		jobLog.ErrorContext(r.Context(), "failed to get jobs", "error", err)
+		response.RespondInternalError(w, r, apperrors.ErrFailedToRetrieveJobs.Error())
+		return
+	}
+
+	response.RespondJSON(w, http.StatusOK, jobs)
+}
+
+// GetJob handles GET requests to retrieve a single job, including its progress and result.
+//
+// Endpoint: GET /api/jobs/{uuid}
+// Response: 200 OK with Job
+// Error: 404 Not Found if the job doesn't exist
+// Error: 500 Internal Server Error if retrieval fails
+func (h *JobHandler) GetJob(w http.ResponseWriter, r *http.Request) {
+	jobID := chi.URLParam(r, "uuid")
+
+	jobLog.DebugContext(r.Context(), "get job request", "job_id", jobID)
+
+	job, err := h.jobService.GetJob(jobID)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrJobNotFound) {
+			response.RespondError(w, http.StatusNotFound, apperrors.ErrJobNotFound.Error(), "")
+			return
+		}
+		jobLog.ErrorContext(r.Context(), "failed to get job", "error", err, "job_id", jobID)
+		response.RespondInternalError(w, r, apperrors.ErrFailedToRetrieveJobs.Error())
+		return
+	}
+
+	response.RespondJSON(w, http.StatusOK, job)
+}
+
+// RetryJob handles POST requests to run a failed job again with the same parameters.
+// The new job references the failed one in retryOf.
+//
+// Endpoint: POST /api/jobs/{uuid}/retry
+// Response: 202 Accepted with the new Job
+// Error: 404 Not Found if the job doesn't exist
+// Error: 409 Conflict if the job has not failed
+// Error: 500 Internal Server Error if the job cannot be started
+func (h *JobHandler) RetryJob(w http.ResponseWriter, r *http.Request) {
+	jobID := chi.URLParam(r, "uuid")
+
+	jobLog.DebugContext(r.Context(), "retry job request", "job_id", jobID)
+
+	job, err := h.jobService.Retry(r.Context(), jobID)
+	if err != nil {
+		switch {
+		case errors.Is(err, apperrors.ErrJobNotFound):
+			response.RespondError(w, http.StatusNotFound, apperrors.ErrJobNotFound.Error(), "")
+		case errors.Is(err, apperrors.ErrJobNotRetryable):
+			response.RespondError(w, http.StatusConflict, apperrors.ErrJobNotRetryable.Error(), "")
+		default:
+			jobLog.ErrorContext(r.Context(), "failed to retry job", "error", err, "job_id", jobID)
+			response.RespondInternalError(w, r, apperrors.ErrFailedToRetryJob.Error())
+		}
+		return
+	}
+
+	jobLog.InfoContext(r.Context(), "job retried", "job_id", jobID, "new_job_id", job.ID)
+	response.RespondJSON(w, http.StatusAccepted, job)
+}
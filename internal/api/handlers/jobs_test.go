@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/model"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/service"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/testutil"
+)
+
+// setupJobHandler returns a JobHandler whose trash_purge jobs fail and whose
+// session_purge jobs succeed.
+func setupJobHandler(t *testing.T) (*JobHandler, *service.JobService, *sql.DB) {
+	t.Helper()
+	db := testutil.SetupTestDB(t)
+	svc := testutil.NewTestJobService(t, db)
+	svc.Register(model.JobTypeTrashPurge, func(context.Context, json.RawMessage) (any, error) {
+		return nil, errors.New("boom")
+	})
+	svc.Register(model.JobTypeSessionPurge, func(context.Context, json.RawMessage) (any, error) {
+		return nil, nil
+	})
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = svc.Wait(ctx) //nolint:errcheck // Best effort: let background jobs finish before the DB closes.
+	})
+	return NewJobHandler(svc), svc, db
+}
+
+func TestJobHandler_GetJobs(t *testing.T) {
+	t.Run("lists jobs filtered by status", func(t *testing.T) {
+		handler, svc, _ := setupJobHandler(t)
+		failed, _ := svc.Run(context.Background(), model.JobTypeTrashPurge, nil)
+		_, _ = svc.Run(context.Background(), model.JobTypeSessionPurge, nil)
+
+		req := testutil.NewRequestWithQueryParams(http.MethodGet, "/api/jobs", map[string]string{"status": "failed"})
+		w := httptest.NewRecorder()
+
+		handler.GetJobs(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+
+		var response []model.Job
+		//nolint:errcheck // Test assertion - decode failure would cause test to fail anyway
+		json.NewDecoder(w.Body).Decode(&response)
+
+		if len(response) != 1 || response[0].ID != failed.ID || response[0].Error != "boom" {
+			t.Errorf("Expected only the failed job, got %+v", response)
+		}
+	})
+
+	t.Run("invalid filter returns 400", func(t *testing.T) {
+		handler, _, _ := setupJobHandler(t)
+
+		req := testutil.NewRequestWithQueryParams(http.MethodGet, "/api/jobs", map[string]string{"limit": "1000"})
+		w := httptest.NewRecorder()
+
+		handler.GetJobs(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected 400, got %d", w.Code)
+		}
+	})
+
+	t.Run("database error returns 500", func(t *testing.T) {
+		handler, _, db := setupJobHandler(t)
+		db.Close()
+
+		req := httptest.NewRequest(http.MethodGet, "/api/jobs", nil)
+		w := httptest.NewRecorder()
+
+		handler.GetJobs(w, req)
+
+		if w.Code != http.StatusInternalServerError {
+			t.Errorf("Expected 500, got %d", w.Code)
+		}
+	})
+}
+
+func TestJobHandler_GetJob(t *testing.T) {
+	t.Run("returns job", func(t *testing.T) {
+		handler, svc, _ := setupJobHandler(t)
+		job, _ := svc.Run(context.Background(), model.JobTypeSessionPurge, nil)
+
+		req := testutil.NewRequestWithURLParams(http.MethodGet, "/api/jobs/"+job.ID, map[string]string{"uuid": job.ID})
+		w := httptest.NewRecorder()
+
+		handler.GetJob(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+
+		var response model.Job
+		//nolint:errcheck // Test assertion - decode failure would cause test to fail anyway
+		json.NewDecoder(w.Body).Decode(&response)
+
+		if response.ID != job.ID || response.Status != string(model.JobStatusSucceeded) {
+			t.Errorf("Expected succeeded job %s, got %+v", job.ID, response)
+		}
+	})
+
+	t.Run("unknown job returns 404", func(t *testing.T) {
+		handler, _, _ := setupJobHandler(t)
+		id := testutil.MakeID()
+
+		req := testutil.NewRequestWithURLParams(http.MethodGet, "/api/jobs/"+id, map[string]string{"uuid": id})
+		w := httptest.NewRecorder()
+
+		handler.GetJob(w, req)
+
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Expected 404, got %d", w.Code)
+		}
+	})
+}
+
+func TestJobHandler_RetryJob(t *testing.T) {
+	t.Run("retries failed job", func(t *testing.T) {
+		handler, svc, _ := setupJobHandler(t)
+		failed, _ := svc.Run(context.Background(), model.JobTypeTrashPurge, nil)
+
+		req := testutil.NewRequestWithURLParams(http.MethodPost, "/api/jobs/"+failed.ID+"/retry", map[string]string{"uuid": failed.ID})
+		w := httptest.NewRecorder()
+
+		handler.RetryJob(w, req)
+
+		if w.Code != http.StatusAccepted {
+			t.Fatalf("Expected 202, got %d: %s", w.Code, w.Body.String())
+		}
+
+		var response model.Job
+		//nolint:errcheck // Test assertion - decode failure would cause test to fail anyway
+		json.NewDecoder(w.Body).Decode(&response)
+
+		if response.RetryOf != failed.ID || response.Type != string(model.JobTypeTrashPurge) {
+			t.Errorf("Expected retry of %s, got %+v", failed.ID, response)
+		}
+	})
+
+	t.Run("succeeded job returns 409", func(t *testing.T) {
+		handler, svc, _ := setupJobHandler(t)
+		job, _ := svc.Run(context.Background(), model.JobTypeSessionPurge, nil)
+
+		req := testutil.NewRequestWithURLParams(http.MethodPost, "/api/jobs/"+job.ID+"/retry", map[string]string{"uuid": job.ID})
+		w := httptest.NewRecorder()
+
+		handler.RetryJob(w, req)
+
+		if w.Code != http.StatusConflict {
+			t.Errorf("Expected 409, got %d", w.Code)
+		}
+	})
+
+	t.Run("unknown job returns 404", func(t *testing.T) {
+		handler, _, _ := setupJobHandler(t)
+		id := testutil.MakeID()
+
+		req := testutil.NewRequestWithURLParams(http.MethodPost, "/api/jobs/"+id+"/retry", map[string]string{"uuid": id})
+		w := httptest.NewRecorder()
+
+		handler.RetryJob(w, req)
+
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Expected 404, got %d", w.Code)
+		}
+	})
+}
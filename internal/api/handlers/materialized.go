@@ -0,0 +1,209 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/api/request"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/api/response"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/apperrors"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/logging"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/service"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/validation"
+)
+
+var materializedLog = logging.NewLogger("system")
+
+// MaterializedHandler handles admin HTTP requests for the materialized history tables.
+type MaterializedHandler struct {
+	materializedService *service.MaterializedService
+}
+
+// NewMaterializedHandler creates a new MaterializedHandler with the provided service dependency.
+func NewMaterializedHandler(materializedService *service.MaterializedService) *MaterializedHandler {
+	return &MaterializedHandler{
+		materializedService: materializedService,
+	}
+}
+
+// GetRegenQueue handles GET requests to list pending materialized regenerations: each
+// portfolio whose materialized history is dirty, from which date, and its retry state.
+//
+// Endpoint: GET /api/materialized/queue
+// Response: 200 OK with array of RegenQueueEntry, next due first
+// Error: 500 Internal Server Error if retrieval fails
+func (h *MaterializedHandler) GetRegenQueue(w http.ResponseWriter, r *http.Request) {
+	materializedLog.DebugContext(r.Context(), "get regeneration queue request")
+
+	entries, err := h.materializedService.GetRegenQueue()
+	if err != nil {
+		materializedLog.ErrorContext(r.Context(), "failed to get regeneration queue", "error", err)
+		response.RespondInternalError(w, r, apperrors.ErrFailedToRetrieveRegenQueue.Error())
+		return
+	}
+
+	response.RespondJSON(w, http.StatusOK, entries)
+}
+
+// parsePortfolioIDParam reads the optional portfolioId query parameter, responding 400
+// and returning false if it is not a UUID.
+func parsePortfolioIDParam(w http.ResponseWriter, r *http.Request) (string, bool) {
+	portfolioID := r.URL.Query().Get("portfolioId")
+	if portfolioID == "" {
+		return "", true
+	}
+	if err := validation.ValidateUUID(portfolioID); err != nil {
+		response.RespondError(w, http.StatusBadRequest, apperrors.ErrInvalidUUID.Error(), err.Error())
+		return "", false
+	}
+	return portfolioID, true
+}
+
+// GetCoverage handles GET requests to inspect the materialized history per portfolio:
+// the first and last materialized date, the latest calculated_at, the number of rows,
+// and whether (and why) reads currently treat it as stale.
+//
+// Query Parameters:
+//   - portfolioId (optional): Limit to one portfolio. Defaults to all portfolios,
+//     including archived and excluded ones.
+//
+// Endpoint: GET /api/materialized/coverage
+// Response: 200 OK with array of MaterializedCoverage
+// Error: 400 Bad Request if portfolioId is not a UUID
+// Error: 404 Not Found if the portfolio does not exist
+// Error: 500 Internal Server Error if retrieval fails
+func (h *MaterializedHandler) GetCoverage(w http.ResponseWriter, r *http.Request) {
+	materializedLog.DebugContext(r.Context(), "get materialized coverage request")
+
+	portfolioID, ok := parsePortfolioIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	coverage, err := h.materializedService.GetMaterializedCoverage(r.Context(), portfolioID)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrPortfolioNotFound) {
+			response.RespondError(w, http.StatusNotFound, apperrors.ErrPortfolioNotFound.Error(), "")
+			return
+		}
+		materializedLog.ErrorContext(r.Context(), "failed to get materialized coverage", "error", err)
+		response.RespondInternalError(w, r, apperrors.ErrFailedToRetrieveCoverage.Error())
+		return
+	}
+
+	response.RespondJSON(w, http.StatusOK, coverage)
+}
+
+// Rebuild handles POST requests to force a rebuild of the materialized history, without
+// waiting for a stale read or a queued regeneration.
+//
+// Request Body (all fields optional):
+//   - portfolioId: Rebuild one portfolio. Defaults to all portfolios.
+//   - startDate: Rebuild from this date (YYYY-MM-DD) onward. Defaults to the full history.
+//
+// Query Parameters:
+//   - async (optional): "true" runs the rebuild as a background materialized_regen job
+//
+// Endpoint: POST /api/materialized/rebuild
+// Response: 200 OK with array of MaterializedCoverage after the rebuild
+// Response: 202 Accepted with the Job when async=true
+// Error: 400 Bad Request if the body is invalid
+// Error: 404 Not Found if the portfolio does not exist
+// Error: 500 Internal Server Error if the rebuild fails
+func (h *MaterializedHandler) Rebuild(w http.ResponseWriter, r *http.Request) {
+	materializedLog.DebugContext(r.Context(), "rebuild materialized history request")
+
+	var req request.RebuildMaterializedRequest
+	if r.ContentLength != 0 {
+		var err error
+		req, err = parseJSON[request.RebuildMaterializedRequest](r)
+		if err != nil {
+			response.RespondError(w, http.StatusBadRequest, "invalid request body", err.Error())
+			return
+		}
+	}
+
+	if err := validation.ValidateRebuildMaterialized(req); err != nil {
+		response.RespondError(w, http.StatusBadRequest, "validation failed", err.Error())
+		return
+	}
+
+	var startDate time.Time
+	if req.StartDate != "" {
+		//nolint:errcheck // Validated above.
+		startDate, _ = time.Parse("2006-01-02", req.StartDate)
+	}
+
+	if r.URL.Query().Get("async") == "true" {
+		job, err := h.materializedService.SubmitRebuild(r.Context(), req.PortfolioID, startDate)
+		if err != nil {
+			if errors.Is(err, apperrors.ErrPortfolioNotFound) {
+				response.RespondError(w, http.StatusNotFound, apperrors.ErrPortfolioNotFound.Error(), "")
+				return
+			}
+			materializedLog.ErrorContext(r.Context(), "failed to start materialized rebuild job", "error", err)
+			response.RespondInternalError(w, r, apperrors.ErrFailedToStartJob.Error())
+			return
+		}
+		response.RespondJSON(w, http.StatusAccepted, job)
+		return
+	}
+
+	coverage, err := h.materializedService.RebuildMaterialized(r.Context(), req.PortfolioID, startDate)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrPortfolioNotFound) {
+			response.RespondError(w, http.StatusNotFound, apperrors.ErrPortfolioNotFound.Error(), "")
+			return
+		}
+		materializedLog.ErrorContext(r.Context(), "failed to rebuild materialized history", "error", err)
+		response.RespondInternalError(w, r, apperrors.ErrFailedToRebuildMaterialized.Error())
+		return
+	}
+
+	materializedLog.InfoContext(r.Context(), "materialized history rebuilt", "portfolio_id", req.PortfolioID, "start_date", req.StartDate)
+	response.RespondJSON(w, http.StatusOK, coverage)
+}
+
+// Verify handles GET requests to compare the materialized fund rows against a fresh
+// calculation and report drift: rows that are missing, unexpected, or differ in any field.
+//
+// Query Parameters:
+//   - portfolioId (optional): Limit to one portfolio. Defaults to all portfolios.
+//   - start_date (optional): First date to compare (YYYY-MM-DD). Defaults to the full history.
+//   - end_date (optional): Last date to compare (YYYY-MM-DD). Defaults to today.
+//
+// Recalculating is as expensive as an uncached history read for every portfolio in scope.
+//
+// Endpoint: GET /api/materialized/verify
+// Response: 200 OK with array of MaterializedVerification
+// Error: 400 Bad Request if a parameter is invalid
+// Error: 404 Not Found if the portfolio does not exist
+// Error: 500 Internal Server Error if verification fails
+func (h *MaterializedHandler) Verify(w http.ResponseWriter, r *http.Request) {
+	materializedLog.DebugContext(r.Context(), "verify materialized history request")
+
+	portfolioID, ok := parsePortfolioIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	startDate, endDate, err := parseDateParams(r)
+	if err != nil {
+		response.RespondError(w, http.StatusBadRequest, "Invalid date parameters", err.Error())
+		return
+	}
+
+	result, err := h.materializedService.VerifyMaterialized(r.Context(), portfolioID, startDate, endDate)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrPortfolioNotFound) {
+			response.RespondError(w, http.StatusNotFound, apperrors.ErrPortfolioNotFound.Error(), "")
+			return
+		}
+		materializedLog.ErrorContext(r.Context(), "failed to verify materialized history", "error", err)
+		response.RespondInternalError(w, r, apperrors.ErrFailedToVerifyMaterialized.Error())
+		return
+	}
+
+	response.RespondJSON(w, http.StatusOK, result)
+}
@@ -0,0 +1,236 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/model"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/testutil"
+)
+
+func TestMaterializedHandler_GetRegenQueue(t *testing.T) {
+	t.Run("lists dirty portfolios", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		svc := testutil.NewTestMaterializedService(t, db)
+		handler := NewMaterializedHandler(svc)
+
+		portfolio := testutil.NewPortfolio().WithName("Dirty").Build(t, db)
+		svc.ScheduleRegeneration(context.Background(), time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC), []string{portfolio.ID}, "", "")
+
+		req := httptest.NewRequest(http.MethodGet, "/api/materialized/queue", nil)
+		w := httptest.NewRecorder()
+
+		handler.GetRegenQueue(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+
+		var response []model.RegenQueueEntry
+		//nolint:errcheck // Test assertion - decode failure would cause test to fail anyway
+		json.NewDecoder(w.Body).Decode(&response)
+
+		if len(response) != 1 || response[0].PortfolioName != "Dirty" || response[0].StartDate.Format("2006-01-02") != "2025-04-01" {
+			t.Errorf("Expected the dirty portfolio from 2025-04-01, got %+v", response)
+		}
+	})
+
+	t.Run("database error returns 500", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		handler := NewMaterializedHandler(testutil.NewTestMaterializedService(t, db))
+		db.Close()
+
+		req := httptest.NewRequest(http.MethodGet, "/api/materialized/queue", nil)
+		w := httptest.NewRecorder()
+
+		handler.GetRegenQueue(w, req)
+
+		if w.Code != http.StatusInternalServerError {
+			t.Errorf("Expected 500, got %d", w.Code)
+		}
+	})
+}
+
+// buildMaterializedPortfolio creates a portfolio with one fund, a buy and a few prices.
+func buildMaterializedPortfolio(t *testing.T, db *sql.DB) model.Portfolio {
+	t.Helper()
+	portfolio := testutil.NewPortfolio().WithName("Checked").Build(t, db)
+	fund := testutil.NewFund().Build(t, db)
+	pf := testutil.NewPortfolioFund(portfolio.ID, fund.ID).Build(t, db)
+
+	txDate := time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)
+	testutil.NewTransaction(pf.ID).WithDate(txDate).WithShares(10).WithCostPerShare(10.0).Build(t, db)
+	for i := range 5 {
+		testutil.NewFundPrice(fund.ID).WithDate(txDate.AddDate(0, 0, i)).WithPrice(10.0+float64(i)).Build(t, db)
+	}
+	return portfolio
+}
+
+func TestMaterializedHandler_GetCoverage(t *testing.T) {
+	t.Run("reports stale portfolios without data", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		handler := NewMaterializedHandler(testutil.NewTestMaterializedService(t, db))
+		portfolio := buildMaterializedPortfolio(t, db)
+
+		req := testutil.NewRequestWithQueryParams(http.MethodGet, "/api/materialized/coverage", map[string]string{"portfolioId": portfolio.ID})
+		w := httptest.NewRecorder()
+
+		handler.GetCoverage(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+
+		var response []model.MaterializedCoverage
+		//nolint:errcheck // Test assertion - decode failure would cause test to fail anyway
+		json.NewDecoder(w.Body).Decode(&response)
+
+		if len(response) != 1 || !response[0].Stale || response[0].StaleReason != model.StaleReasonNoData {
+			t.Errorf("Expected one stale portfolio without data, got %+v", response)
+		}
+	})
+
+	t.Run("invalid portfolioId returns 400", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		handler := NewMaterializedHandler(testutil.NewTestMaterializedService(t, db))
+
+		req := testutil.NewRequestWithQueryParams(http.MethodGet, "/api/materialized/coverage", map[string]string{"portfolioId": "nope"})
+		w := httptest.NewRecorder()
+
+		handler.GetCoverage(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected 400, got %d", w.Code)
+		}
+	})
+
+	t.Run("unknown portfolio returns 404", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		handler := NewMaterializedHandler(testutil.NewTestMaterializedService(t, db))
+
+		req := testutil.NewRequestWithQueryParams(http.MethodGet, "/api/materialized/coverage", map[string]string{"portfolioId": testutil.MakeID()})
+		w := httptest.NewRecorder()
+
+		handler.GetCoverage(w, req)
+
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Expected 404, got %d", w.Code)
+		}
+	})
+}
+
+func TestMaterializedHandler_Rebuild(t *testing.T) {
+	t.Run("rebuilds and returns fresh coverage", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		handler := NewMaterializedHandler(testutil.NewTestMaterializedService(t, db))
+		portfolio := buildMaterializedPortfolio(t, db)
+
+		req := testutil.NewRequestWithBody(http.MethodPost, "/api/materialized/rebuild", `{"portfolioId":"`+portfolio.ID+`"}`)
+		w := httptest.NewRecorder()
+
+		handler.Rebuild(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+
+		var response []model.MaterializedCoverage
+		//nolint:errcheck // Test assertion - decode failure would cause test to fail anyway
+		json.NewDecoder(w.Body).Decode(&response)
+
+		if len(response) != 1 || response[0].Stale || response[0].FirstDate == nil || response[0].FirstDate.Format("2006-01-02") != "2025-01-15" {
+			t.Errorf("Expected fresh coverage from 2025-01-15, got %+v", response)
+		}
+	})
+
+	t.Run("empty body rebuilds all portfolios", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		handler := NewMaterializedHandler(testutil.NewTestMaterializedService(t, db))
+		buildMaterializedPortfolio(t, db)
+		testutil.NewPortfolio().Archived().Build(t, db)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/materialized/rebuild", nil)
+		w := httptest.NewRecorder()
+
+		handler.Rebuild(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+
+		var response []model.MaterializedCoverage
+		//nolint:errcheck // Test assertion - decode failure would cause test to fail anyway
+		json.NewDecoder(w.Body).Decode(&response)
+
+		if len(response) != 2 {
+			t.Errorf("Expected coverage for both portfolios, got %+v", response)
+		}
+	})
+
+	t.Run("invalid start date returns 400", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		handler := NewMaterializedHandler(testutil.NewTestMaterializedService(t, db))
+
+		req := testutil.NewRequestWithBody(http.MethodPost, "/api/materialized/rebuild", `{"startDate":"15-01-2025"}`)
+		w := httptest.NewRecorder()
+
+		handler.Rebuild(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected 400, got %d", w.Code)
+		}
+	})
+}
+
+func TestMaterializedHandler_Verify(t *testing.T) {
+	t.Run("reports no drift after a rebuild", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		svc := testutil.NewTestMaterializedService(t, db)
+		handler := NewMaterializedHandler(svc)
+		portfolio := buildMaterializedPortfolio(t, db)
+
+		if _, err := svc.RebuildMaterialized(context.Background(), portfolio.ID, time.Time{}); err != nil {
+			t.Fatalf("RebuildMaterialized() error: %v", err)
+		}
+
+		req := testutil.NewRequestWithQueryParams(http.MethodGet, "/api/materialized/verify", map[string]string{
+			"portfolioId": portfolio.ID,
+			"start_date":  "2025-01-15",
+			"end_date":    "2025-01-19",
+		})
+		w := httptest.NewRecorder()
+
+		handler.Verify(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+
+		var response []model.MaterializedVerification
+		//nolint:errcheck // Test assertion - decode failure would cause test to fail anyway
+		json.NewDecoder(w.Body).Decode(&response)
+
+		if len(response) != 1 || response[0].RowsChecked != 5 || response[0].DriftCount != 0 {
+			t.Errorf("Expected 5 clean rows, got %+v", response)
+		}
+	})
+
+	t.Run("invalid date returns 400", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		handler := NewMaterializedHandler(testutil.NewTestMaterializedService(t, db))
+
+		req := testutil.NewRequestWithQueryParams(http.MethodGet, "/api/materialized/verify", map[string]string{"start_date": "yesterday"})
+		w := httptest.NewRecorder()
+
+		handler.Verify(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected 400, got %d", w.Code)
+		}
+	})
+}
@@ -36,7 +36,8 @@ func NewPortfolioHandler(portfolioService *service.PortfolioService, fundService
 }
 
 // Portfolios handles GET requests to retrieve all portfolios.
-// This endpoint returns all portfolios including archived and excluded ones.
+// This endpoint returns all portfolios including archived and excluded ones
+// that the caller owns or that were shared with them.
 //
 // Endpoint: GET /api/portfolio
 // Response: 200 OK with array of PortfoliosResponse
@@ -44,7 +45,7 @@ func NewPortfolioHandler(portfolioService *service.PortfolioService, fundService
 func (h *PortfolioHandler) Portfolios(w http.ResponseWriter, r *http.Request) {
 	pfLog.DebugContext(r.Context(), "get all portfolios request")
 
-	portfolios, err := h.portfolioService.GetAllPortfolios()
+	portfolios, err := h.portfolioService.GetAllPortfolios(r.Context())
 	if err != nil {
 		pfLog.ErrorContext(r.Context(), "failed to get all portfolios", "error", err)
 		response.RespondInternalError(w, r, apperrors.ErrFailedToRetrievePortfolios.Error())
@@ -71,7 +72,7 @@ func (h *PortfolioHandler) GetPortfolio(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	summaries, err := h.materializedService.GetPortfolioSummaryWithFallback(portfolioID)
+	summaries, err := h.materializedService.GetPortfolioSummaryWithFallback(r.Context(), portfolioID)
 	if err != nil {
 		if errors.Is(err, apperrors.ErrPortfolioNotFound) {
 			response.RespondError(w, http.StatusNotFound, apperrors.ErrPortfolioNotFound.Error(), "")
@@ -110,7 +111,7 @@ func (h *PortfolioHandler) GetPortfolio(w http.ResponseWriter, r *http.Request)
 func (h *PortfolioHandler) PortfolioSummary(w http.ResponseWriter, r *http.Request) {
 	pfLog.DebugContext(r.Context(), "get portfolio summary request")
 
-	summaries, err := h.materializedService.GetPortfolioSummaryWithFallback("")
+	summaries, err := h.materializedService.GetPortfolioSummaryWithFallback(r.Context(), "")
 	if err != nil {
 		pfLog.ErrorContext(r.Context(), "failed to get portfolio summaries", "error", err)
 		response.RespondInternalError(w, r, apperrors.ErrFailedToGetPortfolioSummary.Error())
@@ -127,19 +128,22 @@ func (h *PortfolioHandler) PortfolioSummary(w http.ResponseWriter, r *http.Reque
 //     Defaults to 1970-01-01 if not provided
 //   - end_date (optional): Last date to include (YYYY-MM-DD or RFC3339 format)
 //     Defaults to current date if not provided
+//   - interval (optional): day, week or month. Defaults to day. Weekly and monthly
+//     history has one point per period: the last day of the period within the data.
 //
-// The endpoint returns daily portfolio valuations for the requested date range.
+// The endpoint returns portfolio valuations for the requested date range.
 // Only active portfolios are included, and the actual returned range may be
 // narrowed to the range where transaction data exists.
 //
-// Endpoint: GET /api/portfolio/history?start_date=YYYY-MM-DD&end_date=YYYY-MM-DD
-// Response: 200 OK with array of PortfolioHistoryResponse (one per day)
-// Error: 400 Bad Request if date parsing fails
+// Endpoint: GET /api/portfolio/history?start_date=YYYY-MM-DD&end_date=YYYY-MM-DD&interval=day
+// Response: 200 OK with array of PortfolioHistoryResponse (one per day, week or month)
+// Error: 400 Bad Request if date or interval parsing fails
 // Error: 500 Internal Server Error if calculation fails
 func (h *PortfolioHandler) PortfolioHistory(w http.ResponseWriter, r *http.Request) {
 	pfLog.DebugContext(r.Context(), "get portfolio history request",
 		"start_date", r.URL.Query().Get("start_date"),
 		"end_date", r.URL.Query().Get("end_date"),
+		"interval", r.URL.Query().Get("interval"),
 	)
 
 	startDate, endDate, err := parseDateParams(r)
@@ -148,7 +152,13 @@ func (h *PortfolioHandler) PortfolioHistory(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	portfolioHistory, err := h.materializedService.GetPortfolioHistoryWithFallback(startDate, endDate, "")
+	interval, err := request.ParseHistoryInterval(r.URL.Query().Get("interval"))
+	if err != nil {
+		response.RespondError(w, http.StatusBadRequest, "Invalid interval parameter", err.Error())
+		return
+	}
+
+	portfolioHistory, err := h.materializedService.GetPortfolioHistoryWithFallback(r.Context(), startDate, endDate, "", interval)
 	if err != nil {
 		pfLog.ErrorContext(r.Context(), "failed to get portfolio history", "error", err)
 		response.RespondInternalError(w, r, apperrors.ErrFailedToGetPortfolioHistory.Error())
@@ -196,7 +206,7 @@ func parseDateParams(r *http.Request) (time.Time, time.Time, error) {
 func (h *PortfolioHandler) PortfolioFunds(w http.ResponseWriter, r *http.Request) {
 	pfLog.DebugContext(r.Context(), "get all portfolio funds request")
 
-	listings, err := h.fundService.GetAllPortfolioFundListings()
+	listings, err := h.fundService.GetAllPortfolioFundListings(r.Context())
 	if err != nil {
 		pfLog.ErrorContext(r.Context(), "failed to get portfolio fund listings", "error", err)
 		response.RespondInternalError(w, r, apperrors.ErrFailedToRetrievePortfolioFunds.Error())
@@ -318,7 +328,8 @@ func (h *PortfolioHandler) UpdatePortfolio(w http.ResponseWriter, r *http.Reques
 }
 
 // DeletePortfolio handles DELETE requests to delete a portfolio.
-// Permanently deletes the portfolio and all related data (cascading delete).
+// Deletes the portfolio and all related data (cascading delete); the portfolio and its
+// cascaded rows are kept in the trash and can be restored via /api/trash.
 //
 // Endpoint: DELETE /api/portfolio/{portfolioId}
 // Response: 204 No Content on successful deletion
@@ -417,6 +428,7 @@ func (h *PortfolioHandler) UnarchivePortfolio(w http.ResponseWriter, r *http.Req
 // Returns 201 Created on success.
 //
 // Error: 400 Bad Request if request body is invalid
+// Error: 403 Forbidden if the caller has read-only access to the portfolio
 // Error: 404 Not Found if portfolio or fund doesn't exist
 // Error: 500 Internal Server Error if creation fails
 func (h *PortfolioHandler) CreatePortfolioFund(w http.ResponseWriter, r *http.Request) {
@@ -433,6 +445,10 @@ func (h *PortfolioHandler) CreatePortfolioFund(w http.ResponseWriter, r *http.Re
 		return
 	}
 
+	if !authorizePortfolio(w, r, req.PortfolioID, model.PortfolioAccessWrite) {
+		return
+	}
+
 	err = h.fundService.CreatePortfolioFund(r.Context(), req)
 	if err != nil {
 		if errors.Is(err, apperrors.ErrPortfolioNotFound) {
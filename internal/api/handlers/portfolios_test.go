@@ -1238,6 +1238,26 @@ func TestPortfolioHandler_PortfolioHistory(t *testing.T) {
 		}
 	})
 
+	t.Run("returns 400 for invalid interval", func(t *testing.T) {
+		handler, _ := setupHandler(t)
+
+		req := testutil.NewRequestWithQueryParams(
+			http.MethodGet,
+			"/api/portfolio/history",
+			map[string]string{
+				"start_date": "2024-01-01",
+				"interval":   "year",
+			},
+		)
+		w := httptest.NewRecorder()
+
+		handler.PortfolioHistory(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected 400, got %d", w.Code)
+		}
+	})
+
 	// Edge case: Single day range
 	t.Run("handles single day date range", func(t *testing.T) {
 		handler, db := setupHandler(t)
@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/api/request"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/api/response"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/apperrors"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/service"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/validation"
+)
+
+// ScheduleHandler handles HTTP requests for scheduled tasks.
+type ScheduleHandler struct {
+	schedulerService *service.SchedulerService
+}
+
+// NewScheduleHandler creates a new ScheduleHandler with the provided service dependency.
+func NewScheduleHandler(schedulerService *service.SchedulerService) *ScheduleHandler {
+	return &ScheduleHandler{
+		schedulerService: schedulerService,
+	}
+}
+
+// GetScheduledTasks handles GET requests to list the scheduled tasks with their schedule,
+// next run and last run.
+//
+// Endpoint: GET /api/jobs/schedules
+// Response: 200 OK with array of ScheduledTask
+// Error: 500 Internal Server Error if retrieval fails
+func (h *ScheduleHandler) GetScheduledTasks(w http.ResponseWriter, r *http.Request) {
+	tasks, err := h.schedulerService.GetTasks()
+	if err != nil {
+		jobLog.ErrorContext(r.Context(), "failed to get scheduled tasks", "error", err)
+		response.RespondInternalError(w, r, apperrors.ErrFailedToRetrieveScheduledTasks.Error())
+		return
+	}
+
+	response.RespondJSON(w, http.StatusOK, tasks)
+}
+
+// GetScheduledTask handles GET requests to retrieve a single scheduled task.
+//
+// Endpoint: GET /api/jobs/schedules/{name}
+// Response: 200 OK with ScheduledTask
+// Error: 404 Not Found if no task has the name
+// Error: 500 Internal Server Error if retrieval fails
+func (h *ScheduleHandler) GetScheduledTask(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+
+	task, err := h.schedulerService.GetTask(name)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrScheduledTaskNotFound) {
+			response.RespondError(w, http.StatusNotFound, apperrors.ErrScheduledTaskNotFound.Error(), "")
+			return
+		}
+		jobLog.ErrorContext(r.Context(), "failed to get scheduled task", "error", err, "task", name)
+		response.RespondInternalError(w, r, apperrors.ErrFailedToRetrieveScheduledTasks.Error())
+		return
+	}
+
+	response.RespondJSON(w, http.StatusOK, task)
+}
+
+// UpdateScheduledTask handles PUT requests to change a task's schedule, time zone or
+// enabled flag. The change applies to the running scheduler without a restart.
+//
+// Endpoint: PUT /api/jobs/schedules/{name}
+// Request body: UpdateScheduledTaskRequest (all fields optional)
+// Response: 200 OK with the updated ScheduledTask
+// Error: 400 Bad Request if the body is invalid
+// Error: 404 Not Found if no task has the name
+// Error: 500 Internal Server Error if the update fails
+func (h *ScheduleHandler) UpdateScheduledTask(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+
+	req, err := parseJSON[request.UpdateScheduledTaskRequest](r)
+	if err != nil {
+		response.RespondError(w, http.StatusBadRequest, "invalid request body", err.Error())
+		return
+	}
+
+	if err := validation.ValidateUpdateScheduledTask(req); err != nil {
+		response.RespondError(w, http.StatusBadRequest, "validation failed", err.Error())
+		return
+	}
+
+	task, err := h.schedulerService.UpdateTask(r.Context(), name, req)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrScheduledTaskNotFound) {
+			response.RespondError(w, http.StatusNotFound, apperrors.ErrScheduledTaskNotFound.Error(), "")
+			return
+		}
+		jobLog.ErrorContext(r.Context(), "failed to update scheduled task", "error", err, "task", name)
+		response.RespondInternalError(w, r, apperrors.ErrFailedToUpdateScheduledTask.Error())
+		return
+	}
+
+	response.RespondJSON(w, http.StatusOK, task)
+}
+
+// RunScheduledTask handles POST requests to run a task now as a background job, whether
+// or not it is enabled.
+//
+// Endpoint: POST /api/jobs/schedules/{name}/run
+// Response: 202 Accepted with the started Job
+// Error: 404 Not Found if no task has the name
+// Error: 500 Internal Server Error if the job cannot be started
+func (h *ScheduleHandler) RunScheduledTask(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+
+	job, err := h.schedulerService.RunTask(r.Context(), name)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrScheduledTaskNotFound) {
+			response.RespondError(w, http.StatusNotFound, apperrors.ErrScheduledTaskNotFound.Error(), "")
+			return
+		}
+		jobLog.ErrorContext(r.Context(), "failed to run scheduled task", "error", err, "task", name)
+		response.RespondInternalError(w, r, apperrors.ErrFailedToRunScheduledTask.Error())
+		return
+	}
+
+	jobLog.InfoContext(r.Context(), "scheduled task started", "task", name, "job_id", job.ID)
+	response.RespondJSON(w, http.StatusAccepted, job)
+}
@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/model"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/testutil"
+)
+
+func setupScheduleHandler(t *testing.T) *ScheduleHandler {
+	t.Helper()
+	db := testutil.SetupTestDB(t)
+	jobs := testutil.NewTestJobService(t, db)
+	jobs.Register(model.JobTypeTrashPurge, func(context.Context, json.RawMessage) (any, error) {
+		return nil, nil
+	})
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = jobs.Wait(ctx) //nolint:errcheck // Best effort: let background jobs finish before the DB closes.
+	})
+	return NewScheduleHandler(testutil.NewTestSchedulerService(t, db, jobs))
+}
+
+func TestScheduleHandler_GetScheduledTasks(t *testing.T) {
+	handler := setupScheduleHandler(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/jobs/schedules", nil)
+	w := httptest.NewRecorder()
+
+	handler.GetScheduledTasks(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var response []model.ScheduledTask
+	//nolint:errcheck // Test assertion - decode failure would cause test to fail anyway
+	json.NewDecoder(w.Body).Decode(&response)
+
+	if len(response) != 7 || response[0].Name != model.ScheduledTaskFundPriceUpdate {
+		t.Errorf("Expected the 7 built-in tasks, got %+v", response)
+	}
+}
+
+func TestScheduleHandler_GetScheduledTask(t *testing.T) {
+	t.Run("unknown task returns 404", func(t *testing.T) {
+		handler := setupScheduleHandler(t)
+
+		req := testutil.NewRequestWithURLParams(http.MethodGet, "/api/jobs/schedules/nope", map[string]string{"name": "nope"})
+		w := httptest.NewRecorder()
+
+		handler.GetScheduledTask(w, req)
+
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Expected 404, got %d", w.Code)
+		}
+	})
+}
+
+func TestScheduleHandler_UpdateScheduledTask(t *testing.T) {
+	newRequest := func(name, body string) *http.Request {
+		req := testutil.NewRequestWithURLParams(http.MethodPut, "/api/jobs/schedules/"+name, map[string]string{"name": name})
+		req.Body = io.NopCloser(strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		return req
+	}
+
+	t.Run("updates the schedule", func(t *testing.T) {
+		handler := setupScheduleHandler(t)
+		w := httptest.NewRecorder()
+
+		handler.UpdateScheduledTask(w, newRequest(model.ScheduledTaskTrashPurge, `{"schedule":"0 4 * * 0","timezone":"Europe/Amsterdam"}`))
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+		var response model.ScheduledTask
+		//nolint:errcheck // Test assertion - decode failure would cause test to fail anyway
+		json.NewDecoder(w.Body).Decode(&response)
+		if response.Schedule != "0 4 * * 0" || response.Timezone != "Europe/Amsterdam" || !response.Enabled {
+			t.Errorf("Expected the updated setting, got %+v", response)
+		}
+	})
+
+	t.Run("invalid schedule returns 400", func(t *testing.T) {
+		handler := setupScheduleHandler(t)
+		w := httptest.NewRecorder()
+
+		handler.UpdateScheduledTask(w, newRequest(model.ScheduledTaskTrashPurge, `{"schedule":"every day"}`))
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected 400, got %d", w.Code)
+		}
+	})
+
+	t.Run("unknown task returns 404", func(t *testing.T) {
+		handler := setupScheduleHandler(t)
+		w := httptest.NewRecorder()
+
+		handler.UpdateScheduledTask(w, newRequest("nope", `{"enabled":false}`))
+
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Expected 404, got %d", w.Code)
+		}
+	})
+}
+
+func TestScheduleHandler_RunScheduledTask(t *testing.T) {
+	handler := setupScheduleHandler(t)
+
+	req := testutil.NewRequestWithURLParams(http.MethodPost, "/api/jobs/schedules/trash_purge/run", map[string]string{"name": model.ScheduledTaskTrashPurge})
+	w := httptest.NewRecorder()
+
+	handler.RunScheduledTask(w, req)
+
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("Expected 202, got %d: %s", w.Code, w.Body.String())
+	}
+	var response model.Job
+	//nolint:errcheck // Test assertion - decode failure would cause test to fail anyway
+	json.NewDecoder(w.Body).Decode(&response)
+	if response.Type != string(model.JobTypeTrashPurge) {
+		t.Errorf("Expected a trash_purge job, got %+v", response)
+	}
+}
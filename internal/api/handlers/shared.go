@@ -4,6 +4,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/api/response"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/apperrors"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/auth"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/model"
 )
 
 // parseJSON parses a JSON request body into the specified type T.
@@ -24,3 +29,32 @@ func parseJSON[T any](r *http.Request) (T, error) {
 
 	return req, nil
 }
+
+// authorizePortfolio checks the caller's access to a portfolio named in a request body
+// (routes with the portfolio in the URL are guarded by middleware instead).
+// It responds 404 when the caller cannot see the portfolio and 403 when its access is
+// insufficient, and reports whether the request may proceed.
+func authorizePortfolio(w http.ResponseWriter, r *http.Request, portfolioID string, required model.PortfolioAccess) bool {
+	if auth.CanAccessPortfolio(r.Context(), portfolioID, required) {
+		return true
+	}
+	if auth.CanAccessPortfolio(r.Context(), portfolioID, model.PortfolioAccessRead) {
+		response.RespondError(w, http.StatusForbidden, apperrors.ErrPortfolioAccessDenied.Error(), "")
+		return false
+	}
+	response.RespondError(w, http.StatusNotFound, apperrors.ErrPortfolioNotFound.Error(), "")
+	return false
+}
+
+// authorizePortfolioFund is authorizePortfolio for a portfolio_fund named in a request body.
+func authorizePortfolioFund(w http.ResponseWriter, r *http.Request, portfolioFundID string, required model.PortfolioAccess) bool {
+	if auth.CanAccessPortfolioFund(r.Context(), portfolioFundID, required) {
+		return true
+	}
+	if auth.CanAccessPortfolioFund(r.Context(), portfolioFundID, model.PortfolioAccessRead) {
+		response.RespondError(w, http.StatusForbidden, apperrors.ErrPortfolioAccessDenied.Error(), "")
+		return false
+	}
+	response.RespondError(w, http.StatusNotFound, apperrors.ErrPortfolioFundNotFound.Error(), "")
+	return false
+}
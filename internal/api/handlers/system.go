@@ -89,3 +89,23 @@ func (h *SystemHandler) Version(w http.ResponseWriter, r *http.Request) {
 
 	response.RespondJSON(w, http.StatusOK, versionResponse)
 }
+
+// GetConfig handles GET requests to retrieve the effective configuration: defaults, overridden
+// by the config file, overridden by environment variables. Secrets are reported only as
+// whether they are set.
+//
+// Endpoint: GET /api/system/config
+// Response: 200 OK with config.RedactedConfig
+// Error: 500 Internal Server Error if no configuration is loaded
+func (h *SystemHandler) GetConfig(w http.ResponseWriter, r *http.Request) {
+	sysLog.DebugContext(r.Context(), "get config request")
+
+	cfg, err := h.systemService.EffectiveConfig()
+	if err != nil {
+		sysLog.ErrorContext(r.Context(), "failed to get config", "error", err)
+		response.RespondInternalError(w, r, apperrors.ErrFailedToGetConfig.Error())
+		return
+	}
+
+	response.RespondJSON(w, http.StatusOK, cfg)
+}
@@ -5,8 +5,11 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/config"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/service"
 	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/testutil"
 )
 
@@ -105,3 +108,54 @@ func TestSystemHandler_Version(t *testing.T) {
 	// it doesn't require active database queries - it reads version from schema
 	// which is cached or handled gracefully. No database error test needed.
 }
+
+func TestSystemHandler_GetConfig(t *testing.T) {
+	t.Run("returns the redacted configuration", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		cfg := config.Defaults()
+		cfg.InternalAPIKey = "secret-api-key"
+		handler := NewSystemHandler(service.NewSystemService(db, service.SystemWithConfig(&cfg)))
+
+		req := httptest.NewRequest(http.MethodGet, "/api/system/config", nil)
+		w := httptest.NewRecorder()
+
+		handler.GetConfig(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+		if strings.Contains(w.Body.String(), "secret-api-key") {
+			t.Errorf("Expected the API key to be redacted, got %s", w.Body.String())
+		}
+
+		var response struct {
+			Server struct {
+				Port string `json:"port"`
+			} `json:"server"`
+			Scheduler struct {
+				FundPriceUpdate string `json:"fundPriceUpdate"`
+			} `json:"scheduler"`
+			InternalAPIKeySet bool `json:"internalApiKeySet"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		if response.Server.Port != "5000" || response.Scheduler.FundPriceUpdate == "" || !response.InternalAPIKeySet {
+			t.Errorf("Unexpected response: %+v", response)
+		}
+	})
+
+	t.Run("returns 500 without a configuration", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		handler := NewSystemHandler(testutil.NewTestSystemService(t, db))
+
+		req := httptest.NewRequest(http.MethodGet, "/api/system/config", nil)
+		w := httptest.NewRecorder()
+
+		handler.GetConfig(w, req)
+
+		if w.Code != http.StatusInternalServerError {
+			t.Errorf("Expected 500, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+}
@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/api/request"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/api/response"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/apperrors"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/logging"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/service"
+)
+
+var reportLog = logging.NewLogger("portfolio")
+
+// TaxReportHandler handles HTTP requests for the yearly tax report endpoints.
+type TaxReportHandler struct {
+	taxReportService *service.TaxReportService
+}
+
+// NewTaxReportHandler creates a new TaxReportHandler with the provided service dependency.
+func NewTaxReportHandler(taxReportService *service.TaxReportService) *TaxReportHandler {
+	return &TaxReportHandler{
+		taxReportService: taxReportService,
+	}
+}
+
+// GetBox3Report handles GET requests for the Dutch Box 3 report of a year across the
+// portfolios the caller can read: their value on 1 January and the year's dividends and
+// withholding tax, in EUR.
+//
+// Endpoint: GET /api/report/box3
+// Query parameters:
+//   - year: Year of the report; holdings are valued on 1 January (default: last year)
+//   - format: json, csv or pdf (default: json)
+//
+// Response: 200 OK with Box3Report, or a box3-{year}.csv or .pdf download
+// Error: 400 Bad Request if the year or format is invalid
+// Error: 409 Conflict if the materialized history does not cover 1 January
+// Error: 500 Internal Server Error if retrieval fails
+func (h *TaxReportHandler) GetBox3Report(w http.ResponseWriter, r *http.Request) {
+	year, format, ok := parseReportParams(w, r)
+	if !ok {
+		return
+	}
+
+	report, err := h.taxReportService.GetBox3Report(r.Context(), year)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrMaterializedDataNotAvailable) {
+			response.RespondError(w, http.StatusConflict, apperrors.ErrMaterializedDataNotAvailable.Error(), err.Error())
+			return
+		}
+		reportLog.ErrorContext(r.Context(), "failed to get box 3 report", "error", err, "year", year)
+		response.RespondInternalError(w, r, apperrors.ErrFailedToRetrieveTaxReport.Error())
+		return
+	}
+
+	respondTaxReport(w, r, format, fmt.Sprintf("box3-%d", year), report,
+		func(w io.Writer) error { return service.WriteBox3CSV(w, report) },
+		func(w io.Writer) error { return service.WriteBox3PDF(w, report) })
+}
+
+// GetCapitalGainsReport handles GET requests for the realized capital gains of a year across
+// the portfolios the caller can read: every sale with its acquisitions, holding period and gain
+// in the fund's and the base currency, with totals per fund and portfolio.
+//
+// Endpoint: GET /api/report/capital-gains
+// Query parameters:
+//   - year: Year of the sales (default: last year)
+//   - format: json, csv or pdf (default: json)
+//
+// Response: 200 OK with CapitalGainsReport, or a capital-gains-{year}.csv or .pdf download
+// Error: 400 Bad Request if the year or format is invalid
+// Error: 500 Internal Server Error if retrieval fails
+func (h *TaxReportHandler) GetCapitalGainsReport(w http.ResponseWriter, r *http.Request) {
+	year, format, ok := parseReportParams(w, r)
+	if !ok {
+		return
+	}
+
+	report, err := h.taxReportService.GetCapitalGainsReport(r.Context(), year)
+	if err != nil {
+		reportLog.ErrorContext(r.Context(), "failed to get capital gains report", "error", err, "year", year)
+		response.RespondInternalError(w, r, apperrors.ErrFailedToRetrieveTaxReport.Error())
+		return
+	}
+
+	respondTaxReport(w, r, format, fmt.Sprintf("capital-gains-%d", year), report,
+		func(w io.Writer) error { return service.WriteCapitalGainsCSV(w, report) },
+		func(w io.Writer) error { return service.WriteCapitalGainsPDF(w, report) })
+}
+
+// parseReportParams parses the year and format query parameters of a report request,
+// responding with 400 Bad Request and returning false if either is invalid.
+func parseReportParams(w http.ResponseWriter, r *http.Request) (int, request.ExportFormat, bool) {
+	year, err := request.ParseReportYear(r.URL.Query().Get("year"), time.Now().UTC())
+	if err != nil {
+		response.RespondError(w, http.StatusBadRequest, "invalid query parameters", err.Error())
+		return 0, "", false
+	}
+	format, err := request.ParseExportFormat(r.URL.Query().Get("format"))
+	if err != nil {
+		response.RespondError(w, http.StatusBadRequest, "invalid query parameters", err.Error())
+		return 0, "", false
+	}
+	return year, format, true
+}
+
+// respondTaxReport sends a report as JSON, or as a CSV or PDF download named name with the
+// matching extension. The file is rendered in memory first so a rendering failure still
+// results in a 500 response.
+func respondTaxReport(w http.ResponseWriter, r *http.Request, format request.ExportFormat, name string, report any,
+	writeCSV, writePDF func(io.Writer) error) {
+	if format == request.ExportFormatJSON {
+		response.RespondJSON(w, http.StatusOK, report)
+		return
+	}
+
+	write, contentType := writeCSV, "text/csv; charset=utf-8"
+	if format == request.ExportFormatPDF {
+		write, contentType = writePDF, "application/pdf"
+	}
+	var buf bytes.Buffer
+	if err := write(&buf); err != nil {
+		reportLog.ErrorContext(r.Context(), "failed to render report", "error", err, "report", name, "format", format)
+		response.RespondInternalError(w, r, apperrors.ErrFailedToRetrieveTaxReport.Error())
+		return
+	}
+	response.RespondFile(w, contentType, name+"."+string(format), buf.Bytes())
+}
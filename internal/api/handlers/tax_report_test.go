@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/model"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/testutil"
+)
+
+func TestTaxReportHandler_GetBox3Report(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	handler := NewTaxReportHandler(testutil.NewTestTaxReportService(t, db))
+
+	box3 := func(query map[string]string) *httptest.ResponseRecorder {
+		req := testutil.NewRequestWithQueryParams(http.MethodGet, "/api/report/box3", query)
+		w := httptest.NewRecorder()
+		handler.GetBox3Report(w, req)
+		return w
+	}
+
+	t.Run("json", func(t *testing.T) {
+		w := box3(map[string]string{"year": "2025"})
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+		var response model.Box3Report
+		//nolint:errcheck // Test assertion - decode failure would cause test to fail anyway
+		json.NewDecoder(w.Body).Decode(&response)
+		if response.Year != 2025 || response.ReferenceDate != "2025-01-01" || response.Portfolios == nil {
+			t.Errorf("Unexpected report %+v", response)
+		}
+	})
+
+	t.Run("csv and pdf downloads", func(t *testing.T) {
+		w := box3(map[string]string{"year": "2025", "format": "csv"})
+		if w.Code != http.StatusOK || !strings.HasPrefix(w.Header().Get("Content-Type"), "text/csv") {
+			t.Fatalf("Expected a CSV, got %d %q", w.Code, w.Header().Get("Content-Type"))
+		}
+		if cd := w.Header().Get("Content-Disposition"); cd != `attachment; filename="box3-2025.csv"` {
+			t.Errorf("Unexpected Content-Disposition %q", cd)
+		}
+
+		w = box3(map[string]string{"year": "2025", "format": "pdf"})
+		if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "application/pdf" || !strings.HasPrefix(w.Body.String(), "%PDF-") {
+			t.Errorf("Expected a PDF, got %d %q", w.Code, w.Header().Get("Content-Type"))
+		}
+	})
+
+	t.Run("invalid parameters return 400", func(t *testing.T) {
+		for _, query := range []map[string]string{{"year": "1800"}, {"format": "xlsx"}} {
+			if w := box3(query); w.Code != http.StatusBadRequest {
+				t.Errorf("%v: expected 400, got %d", query, w.Code)
+			}
+		}
+	})
+
+	t.Run("missing materialized data returns 409", func(t *testing.T) {
+		portfolio := testutil.NewPortfolio().Build(t, db)
+		pf := testutil.NewPortfolioFund(portfolio.ID, testutil.NewFund().Build(t, db).ID).Build(t, db)
+		testutil.NewTransaction(pf.ID).WithDate(time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)).Build(t, db)
+
+		if w := box3(map[string]string{"year": "2025"}); w.Code != http.StatusConflict {
+			t.Errorf("Expected 409, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+}
+
+func TestTaxReportHandler_GetCapitalGainsReport(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	handler := NewTaxReportHandler(testutil.NewTestTaxReportService(t, db))
+
+	portfolio := testutil.NewPortfolio().Build(t, db)
+	fund := testutil.NewFund().WithCurrency("EUR").Build(t, db)
+	pf := testutil.NewPortfolioFund(portfolio.ID, fund.ID).Build(t, db)
+	saleDate := time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)
+	testutil.NewTransaction(pf.ID).WithDate(saleDate.AddDate(-2, 0, 0)).Build(t, db)
+	sale := testutil.NewTransaction(pf.ID).WithType("sell").WithDate(saleDate).WithShares(30).Build(t, db)
+	testutil.NewRealizedGainLoss(portfolio.ID, fund.ID, sale.ID).WithDate(saleDate).Build(t, db)
+
+	capitalGains := func(query map[string]string) *httptest.ResponseRecorder {
+		req := testutil.NewRequestWithQueryParams(http.MethodGet, "/api/report/capital-gains", query)
+		w := httptest.NewRecorder()
+		handler.GetCapitalGainsReport(w, req)
+		return w
+	}
+
+	t.Run("json", func(t *testing.T) {
+		w := capitalGains(map[string]string{"year": "2025"})
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+		var response model.CapitalGainsReport
+		//nolint:errcheck // Test assertion - decode failure would cause test to fail anyway
+		json.NewDecoder(w.Body).Decode(&response)
+		if response.Year != 2025 || response.GainBase != 150 || response.LongTermGainBase != 150 || len(response.Portfolios) != 1 {
+			t.Errorf("Unexpected report %+v", response)
+		}
+	})
+
+	t.Run("csv and pdf downloads", func(t *testing.T) {
+		w := capitalGains(map[string]string{"year": "2025", "format": "csv"})
+		if w.Code != http.StatusOK || !strings.HasPrefix(w.Header().Get("Content-Type"), "text/csv") {
+			t.Fatalf("Expected a CSV, got %d %q", w.Code, w.Header().Get("Content-Type"))
+		}
+		if cd := w.Header().Get("Content-Disposition"); cd != `attachment; filename="capital-gains-2025.csv"` {
+			t.Errorf("Unexpected Content-Disposition %q", cd)
+		}
+
+		w = capitalGains(map[string]string{"year": "2025", "format": "pdf"})
+		if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "application/pdf" || !strings.HasPrefix(w.Body.String(), "%PDF-") {
+			t.Errorf("Expected a PDF, got %d %q", w.Code, w.Header().Get("Content-Type"))
+		}
+	})
+
+	t.Run("invalid parameters return 400", func(t *testing.T) {
+		for _, query := range []map[string]string{{"year": "abc"}, {"format": "xml"}} {
+			if w := capitalGains(query); w.Code != http.StatusBadRequest {
+				t.Errorf("%v: expected 400, got %d", query, w.Code)
+			}
+		}
+	})
+}
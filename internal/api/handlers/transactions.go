@@ -9,6 +9,7 @@ import (
 	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/api/response"
 	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/apperrors"
 	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/logging"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/model"
 	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/service"
 	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/validation"
 )
@@ -41,7 +42,7 @@ func (h *TransactionHandler) TransactionPerPortfolio(w http.ResponseWriter, r *h
 
 	txLog.DebugContext(r.Context(), "get transactions per portfolio request", "portfolio_id", portfolioID)
 
-	transactions, err := h.transactionService.GetTransactionsperPortfolio(portfolioID)
+	transactions, err := h.transactionService.GetTransactionsperPortfolio(r.Context(), portfolioID)
 	if err != nil {
 		txLog.ErrorContext(r.Context(), "failed to get transactions per portfolio", "error", err, "portfolio_id", portfolioID)
 		response.RespondInternalError(w, r, apperrors.ErrFailedToRetrieveTransactions.Error())
@@ -51,7 +52,7 @@ func (h *TransactionHandler) TransactionPerPortfolio(w http.ResponseWriter, r *h
 	response.RespondJSON(w, http.StatusOK, transactions)
 }
 
-// AllTransactions handles GET requests to retrieve all transactions across all portfolios.
+// AllTransactions handles GET requests to retrieve all transactions across the portfolios the caller can read.
 // Returns transaction details including fund information, dates, shares, and IBKR linkage status.
 //
 // Endpoint: GET /api/transaction
@@ -60,7 +61,7 @@ func (h *TransactionHandler) TransactionPerPortfolio(w http.ResponseWriter, r *h
 func (h *TransactionHandler) AllTransactions(w http.ResponseWriter, r *http.Request) {
 	txLog.DebugContext(r.Context(), "get all transactions request")
 
-	transactions, err := h.transactionService.GetTransactionsperPortfolio("")
+	transactions, err := h.transactionService.GetTransactionsperPortfolio(r.Context(), "")
 	if err != nil {
 		txLog.ErrorContext(r.Context(), "failed to get all transactions", "error", err)
 		response.RespondInternalError(w, r, apperrors.ErrFailedToRetrieveTransactions.Error())
@@ -105,6 +106,7 @@ func (h *TransactionHandler) GetTransaction(w http.ResponseWriter, r *http.Reque
 // Request Body: CreateTransactionRequest (portfolioFundId, date, type, shares, costPerShare)
 // Response: 201 Created with Transaction
 // Error: 400 Bad Request if validation fails or request body is invalid
+// Error: 403 Forbidden if the caller has read-only access to the portfolio
 // Error: 500 Internal Server Error if creation fails
 func (h *TransactionHandler) CreateTransaction(w http.ResponseWriter, r *http.Request) {
 	txLog.DebugContext(r.Context(), "create transaction request")
@@ -120,6 +122,10 @@ func (h *TransactionHandler) CreateTransaction(w http.ResponseWriter, r *http.Re
 		return
 	}
 
+	if !authorizePortfolioFund(w, r, req.PortfolioFundID, model.PortfolioAccessWrite) {
+		return
+	}
+
 	transaction, err := h.transactionService.CreateTransaction(r.Context(), req)
 	if err != nil {
 		if errors.Is(err, apperrors.ErrInsufficientShares) {
@@ -164,6 +170,10 @@ func (h *TransactionHandler) UpdateTransaction(w http.ResponseWriter, r *http.Re
 		return
 	}
 
+	if req.PortfolioFundID != nil && !authorizePortfolioFund(w, r, *req.PortfolioFundID, model.PortfolioAccessWrite) {
+		return
+	}
+
 	transaction, err := h.transactionService.UpdateTransaction(r.Context(), transactionID, req)
 	if err != nil {
 		if errors.Is(err, apperrors.ErrTransactionNotFound) {
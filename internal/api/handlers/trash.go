@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/api/response"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/apperrors"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/logging"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/service"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/validation"
+)
+
+var trashLog = logging.NewLogger("database")
+
+// TrashHandler handles HTTP requests for the trash of deleted portfolios, funds,
+// transactions and dividends.
+type TrashHandler struct {
+	trashService *service.TrashService
+}
+
+// NewTrashHandler creates a new TrashHandler with the provided service dependency.
+func NewTrashHandler(trashService *service.TrashService) *TrashHandler {
+	return &TrashHandler{
+		trashService: trashService,
+	}
+}
+
+// GetTrash handles GET requests to list deleted entities awaiting restore or purge.
+//
+// Endpoint: GET /api/trash
+// Query params:
+//   - entityType: Filter by entity type (optional: portfolio, fund, transaction, dividend)
+//
+// Response: 200 OK with array of TrashItem, newest first
+// Error: 400 Bad Request if entityType is invalid
+// Error: 500 Internal Server Error if retrieval fails
+func (h *TrashHandler) GetTrash(w http.ResponseWriter, r *http.Request) {
+	entityType := r.URL.Query().Get("entityType")
+
+	trashLog.DebugContext(r.Context(), "get trash request", "entity_type", entityType)
+
+	if entityType != "" {
+		if err := validation.ValidateTrashEntityType(entityType); err != nil {
+			response.RespondError(w, http.StatusBadRequest, "validation failed", err.Error())
+			return
+		}
+	}
+
+	items, err := h.trashService.GetTrash(entityType)
+	if err != nil {
+		trashLog.ErrorContext(r.Context(), "failed to get trash", "error", err)
+		response.RespondInternalError(w, r, apperrors.ErrFailedToRetrieveTrash.Error())
+		return
+	}
+
+	response.RespondJSON(w, http.StatusOK, items)
+}
+
+// RestoreTrashItem handles POST requests to restore a deleted entity and everything its
+// deletion cascaded to. Materialized history is regenerated in the background.
+//
+// Endpoint: POST /api/trash/{uuid}/restore
+// Response: 200 OK with the restored TrashItem
+// Error: 404 Not Found if the trash item doesn't exist
+// Error: 409 Conflict if the rows collide with current data or depend on deleted rows
+// Error: 500 Internal Server Error if the restore fails
+func (h *TrashHandler) RestoreTrashItem(w http.ResponseWriter, r *http.Request) {
+	trashID := chi.URLParam(r, "uuid")
+
+	trashLog.DebugContext(r.Context(), "restore trash item request", "trash_id", trashID)
+
+	item, err := h.trashService.RestoreTrashItem(r.Context(), trashID)
+	if err != nil {
+		switch {
+		case errors.Is(err, apperrors.ErrTrashItemNotFound):
+			response.RespondError(w, http.StatusNotFound, apperrors.ErrTrashItemNotFound.Error(), "")
+		case errors.Is(err, apperrors.ErrTrashRestoreConflict):
+			response.RespondError(w, http.StatusConflict, apperrors.ErrTrashRestoreConflict.Error(), err.Error())
+		default:
+			trashLog.ErrorContext(r.Context(), "failed to restore trash item", "error", err, "trash_id", trashID)
+			response.RespondInternalError(w, r, apperrors.ErrFailedToRestoreTrashItem.Error())
+		}
+		return
+	}
+
+	response.RespondJSON(w, http.StatusOK, item)
+}
+
+// PurgeTrashItem handles DELETE requests to permanently remove an item from the trash.
+//
+// Endpoint: DELETE /api/trash/{uuid}
+// Response: 204 No Content on success
+// Error: 404 Not Found if the trash item doesn't exist
+// Error: 500 Internal Server Error if the purge fails
+func (h *TrashHandler) PurgeTrashItem(w http.ResponseWriter, r *http.Request) {
+	trashID := chi.URLParam(r, "uuid")
+
+	trashLog.DebugContext(r.Context(), "purge trash item request", "trash_id", trashID)
+
+	if err := h.trashService.PurgeTrashItem(r.Context(), trashID); err != nil {
+		if errors.Is(err, apperrors.ErrTrashItemNotFound) {
+			response.RespondError(w, http.StatusNotFound, apperrors.ErrTrashItemNotFound.Error(), "")
+			return
+		}
+		trashLog.ErrorContext(r.Context(), "failed to purge trash item", "error", err, "trash_id", trashID)
+		response.RespondInternalError(w, r, apperrors.ErrFailedToPurgeTrashItem.Error())
+		return
+	}
+
+	response.RespondJSON(w, http.StatusNoContent, nil)
+}
@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/model"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/testutil"
+)
+
+func setupTrashHandler(t *testing.T) (*TrashHandler, *sql.DB) {
+	t.Helper()
+	db := testutil.SetupTestDB(t)
+	return NewTrashHandler(testutil.NewTestTrashService(t, db)), db
+}
+
+// trashPortfolio deletes a new portfolio through the service and returns its trash item ID.
+func trashPortfolio(t *testing.T, db *sql.DB) string {
+	t.Helper()
+	portfolio := testutil.NewPortfolio().Build(t, db)
+	if err := testutil.NewTestPortfolioService(t, db).DeletePortfolio(context.Background(), portfolio.ID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var id string
+	if err := db.QueryRow(`SELECT id FROM trash WHERE entity_id = ?`, portfolio.ID).Scan(&id); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return id
+}
+
+func TestTrashHandler_GetTrash(t *testing.T) {
+	t.Run("lists trashed items", func(t *testing.T) {
+		handler, db := setupTrashHandler(t)
+		trashPortfolio(t, db)
+
+		req := testutil.NewRequestWithQueryParams(http.MethodGet, "/api/trash", map[string]string{"entityType": "portfolio"})
+		w := httptest.NewRecorder()
+
+		handler.GetTrash(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+
+		var response []model.TrashItem
+		//nolint:errcheck // Test assertion - decode failure would cause test to fail anyway
+		json.NewDecoder(w.Body).Decode(&response)
+
+		if len(response) != 1 || response[0].EntityType != "portfolio" {
+			t.Errorf("Expected 1 portfolio item, got %+v", response)
+		}
+	})
+
+	t.Run("invalid entity type returns 400", func(t *testing.T) {
+		handler, _ := setupTrashHandler(t)
+
+		req := testutil.NewRequestWithQueryParams(http.MethodGet, "/api/trash", map[string]string{"entityType": "log"})
+		w := httptest.NewRecorder()
+
+		handler.GetTrash(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected 400, got %d", w.Code)
+		}
+	})
+
+	t.Run("database error returns 500", func(t *testing.T) {
+		handler, db := setupTrashHandler(t)
+		db.Close()
+
+		req := httptest.NewRequest(http.MethodGet, "/api/trash", nil)
+		w := httptest.NewRecorder()
+
+		handler.GetTrash(w, req)
+
+		if w.Code != http.StatusInternalServerError {
+			t.Errorf("Expected 500, got %d", w.Code)
+		}
+	})
+}
+
+func TestTrashHandler_RestoreTrashItem(t *testing.T) {
+	t.Run("restores item", func(t *testing.T) {
+		handler, db := setupTrashHandler(t)
+		id := trashPortfolio(t, db)
+
+		req := testutil.NewRequestWithURLParams(http.MethodPost, "/api/trash/"+id+"/restore", map[string]string{"uuid": id})
+		w := httptest.NewRecorder()
+
+		handler.RestoreTrashItem(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+
+		var response model.TrashItem
+		//nolint:errcheck // Test assertion - decode failure would cause test to fail anyway
+		json.NewDecoder(w.Body).Decode(&response)
+
+		if response.ID != id {
+			t.Errorf("Expected restored item %s, got %s", id, response.ID)
+		}
+	})
+
+	t.Run("conflict returns 409", func(t *testing.T) {
+		handler, db := setupTrashHandler(t)
+		id := trashPortfolio(t, db)
+		var portfolioID string
+		if err := db.QueryRow(`SELECT entity_id FROM trash WHERE id = ?`, id).Scan(&portfolioID); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		testutil.NewPortfolio().WithID(portfolioID).Build(t, db)
+
+		req := testutil.NewRequestWithURLParams(http.MethodPost, "/api/trash/"+id+"/restore", map[string]string{"uuid": id})
+		w := httptest.NewRecorder()
+
+		handler.RestoreTrashItem(w, req)
+
+		if w.Code != http.StatusConflict {
+			t.Errorf("Expected 409, got %d", w.Code)
+		}
+	})
+
+	t.Run("unknown item returns 404", func(t *testing.T) {
+		handler, _ := setupTrashHandler(t)
+		id := testutil.MakeID()
+
+		req := testutil.NewRequestWithURLParams(http.MethodPost, "/api/trash/"+id+"/restore", map[string]string{"uuid": id})
+		w := httptest.NewRecorder()
+
+		handler.RestoreTrashItem(w, req)
+
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Expected 404, got %d", w.Code)
+		}
+	})
+}
+
+func TestTrashHandler_PurgeTrashItem(t *testing.T) {
+	t.Run("purges item", func(t *testing.T) {
+		handler, db := setupTrashHandler(t)
+		id := trashPortfolio(t, db)
+
+		req := testutil.NewRequestWithURLParams(http.MethodDelete, "/api/trash/"+id, map[string]string{"uuid": id})
+		w := httptest.NewRecorder()
+
+		handler.PurgeTrashItem(w, req)
+
+		if w.Code != http.StatusNoContent {
+			t.Errorf("Expected 204, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("unknown item returns 404", func(t *testing.T) {
+		handler, _ := setupTrashHandler(t)
+		id := testutil.MakeID()
+
+		req := testutil.NewRequestWithURLParams(http.MethodDelete, "/api/trash/"+id, map[string]string{"uuid": id})
+		w := httptest.NewRecorder()
+
+		handler.PurgeTrashItem(w, req)
+
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Expected 404, got %d", w.Code)
+		}
+	})
+}
@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/api/request"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/api/response"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/apperrors"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/service"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/validation"
+)
+
+// WebhookHandler handles HTTP requests for webhook subscriptions and their deliveries.
+type WebhookHandler struct {
+	webhookService *service.WebhookService
+}
+
+// NewWebhookHandler creates a new WebhookHandler with the provided service dependency.
+func NewWebhookHandler(webhookService *service.WebhookService) *WebhookHandler {
+	return &WebhookHandler{
+		webhookService: webhookService,
+	}
+}
+
+// GetWebhooks handles GET requests to list all webhooks. Secrets are not included.
+//
+// Endpoint: GET /api/webhooks
+// Response: 200 OK with array of Webhook
+// Error: 500 Internal Server Error if retrieval fails
+func (h *WebhookHandler) GetWebhooks(w http.ResponseWriter, r *http.Request) {
+	webhooks, err := h.webhookService.GetWebhooks()
+	if err != nil {
+		sysLog.ErrorContext(r.Context(), "failed to get webhooks", "error", err)
+		response.RespondInternalError(w, r, apperrors.ErrFailedToRetrieveWebhooks.Error())
+		return
+	}
+
+	response.RespondJSON(w, http.StatusOK, webhooks)
+}
+
+// GetWebhook handles GET requests to retrieve a single webhook. The secret is not included.
+//
+// Endpoint: GET /api/webhooks/{uuid}
+// Response: 200 OK with Webhook
+// Error: 404 Not Found if the webhook doesn't exist
+// Error: 500 Internal Server Error if retrieval fails
+func (h *WebhookHandler) GetWebhook(w http.ResponseWriter, r *http.Request) {
+	webhookID := chi.URLParam(r, "uuid")
+
+	webhook, err := h.webhookService.GetWebhook(webhookID)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrWebhookNotFound) {
+			response.RespondError(w, http.StatusNotFound, apperrors.ErrWebhookNotFound.Error(), "")
+			return
+		}
+		sysLog.ErrorContext(r.Context(), "failed to get webhook", "error", err, "webhook_id", webhookID)
+		response.RespondInternalError(w, r, apperrors.ErrFailedToRetrieveWebhooks.Error())
+		return
+	}
+
+	response.RespondJSON(w, http.StatusOK, webhook)
+}
+
+// CreateWebhook handles POST requests to subscribe a URL to events.
+//
+// Endpoint: POST /api/webhooks
+// Request body: CreateWebhookRequest
+// Response: 201 Created with the Webhook, including its secret
+// Error: 400 Bad Request if the body is invalid
+// Error: 500 Internal Server Error if creation fails
+func (h *WebhookHandler) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	req, err := parseJSON[request.CreateWebhookRequest](r)
+	if err != nil {
+		response.RespondError(w, http.StatusBadRequest, "invalid request body", err.Error())
+		return
+	}
+
+	if err := validation.ValidateCreateWebhook(req); err != nil {
+		response.RespondError(w, http.StatusBadRequest, "validation failed", err.Error())
+		return
+	}
+
+	webhook, err := h.webhookService.CreateWebhook(r.Context(), req)
+	if err != nil {
+		sysLog.ErrorContext(r.Context(), "failed to create webhook", "error", err)
+		response.RespondInternalError(w, r, apperrors.ErrFailedToCreateWebhook.Error())
+		return
+	}
+
+	response.RespondJSON(w, http.StatusCreated, webhook)
+}
+
+// UpdateWebhook handles PUT requests to change a webhook's URL, events, secret or enabled flag.
+//
+// Endpoint: PUT /api/webhooks/{uuid}
+// Request body: UpdateWebhookRequest (all fields optional)
+// Response: 200 OK with the updated Webhook; the secret is included only when it changed
+// Error: 400 Bad Request if the body is invalid
+// Error: 404 Not Found if the webhook doesn't exist
+// Error: 500 Internal Server Error if the update fails
+func (h *WebhookHandler) UpdateWebhook(w http.ResponseWriter, r *http.Request) {
+	webhookID := chi.URLParam(r, "uuid")
+
+	req, err := parseJSON[request.UpdateWebhookRequest](r)
+	if err != nil {
+		response.RespondError(w, http.StatusBadRequest, "invalid request body", err.Error())
+		return
+	}
+
+	if err := validation.ValidateUpdateWebhook(req); err != nil {
+		response.RespondError(w, http.StatusBadRequest, "validation failed", err.Error())
+		return
+	}
+
+	webhook, err := h.webhookService.UpdateWebhook(r.Context(), webhookID, req)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrWebhookNotFound) {
+			response.RespondError(w, http.StatusNotFound, apperrors.ErrWebhookNotFound.Error(), "")
+			return
+		}
+		sysLog.ErrorContext(r.Context(), "failed to update webhook", "error", err, "webhook_id", webhookID)
+		response.RespondInternalError(w, r, apperrors.ErrFailedToUpdateWebhook.Error())
+		return
+	}
+
+	response.RespondJSON(w, http.StatusOK, webhook)
+}
+
+// DeleteWebhook handles DELETE requests to remove a webhook and its delivery log.
+//
+// Endpoint: DELETE /api/webhooks/{uuid}
+// Response: 204 No Content on success
+// Error: 404 Not Found if the webhook doesn't exist
+// Error: 500 Internal Server Error if the deletion fails
+func (h *WebhookHandler) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	webhookID := chi.URLParam(r, "uuid")
+
+	if err := h.webhookService.DeleteWebhook(r.Context(), webhookID); err != nil {
+		if errors.Is(err, apperrors.ErrWebhookNotFound) {
+			response.RespondError(w, http.StatusNotFound, apperrors.ErrWebhookNotFound.Error(), "")
+			return
+		}
+		sysLog.ErrorContext(r.Context(), "failed to delete webhook", "error", err, "webhook_id", webhookID)
+		response.RespondInternalError(w, r, apperrors.ErrFailedToDeleteWebhook.Error())
+		return
+	}
+
+	response.RespondJSON(w, http.StatusNoContent, nil)
+}
+
+// GetDeliveries handles GET requests to list a webhook's most recent deliveries.
+//
+// Endpoint: GET /api/webhooks/{uuid}/deliveries
+// Query parameters:
+//   - limit: Number of deliveries to return, 1-250 (default 50)
+//
+// Response: 200 OK with array of WebhookDelivery, newest first
+// Error: 400 Bad Request if the limit is invalid
+// Error: 404 Not Found if the webhook doesn't exist
+// Error: 500 Internal Server Error if retrieval fails
+func (h *WebhookHandler) GetDeliveries(w http.ResponseWriter, r *http.Request) {
+	webhookID := chi.URLParam(r, "uuid")
+
+	limit, err := request.ParseDeliveryLimit(r.URL.Query().Get("limit"))
+	if err != nil {
+		response.RespondError(w, http.StatusBadRequest, "invalid query parameters", err.Error())
+		return
+	}
+
+	deliveries, err := h.webhookService.GetDeliveries(webhookID, limit)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrWebhookNotFound) {
+			response.RespondError(w, http.StatusNotFound, apperrors.ErrWebhookNotFound.Error(), "")
+			return
+		}
+		sysLog.ErrorContext(r.Context(), "failed to get webhook deliveries", "error", err, "webhook_id", webhookID)
+		response.RespondInternalError(w, r, apperrors.ErrFailedToRetrieveDeliveries.Error())
+		return
+	}
+
+	response.RespondJSON(w, http.StatusOK, deliveries)
+}
+
+// TestWebhook handles POST requests to send a webhook.test event right away. A delivery
+// that fails is still a successful test call; the outcome is in the returned delivery.
+//
+// Endpoint: POST /api/webhooks/{uuid}/test
+// Response: 200 OK with the WebhookDelivery after its first attempt
+// Error: 404 Not Found if the webhook doesn't exist
+// Error: 500 Internal Server Error if the test event cannot be sent
+func (h *WebhookHandler) TestWebhook(w http.ResponseWriter, r *http.Request) {
+	webhookID := chi.URLParam(r, "uuid")
+
+	delivery, err := h.webhookService.TestWebhook(r.Context(), webhookID)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrWebhookNotFound) {
+			response.RespondError(w, http.StatusNotFound, apperrors.ErrWebhookNotFound.Error(), "")
+			return
+		}
+		sysLog.ErrorContext(r.Context(), "failed to test webhook", "error", err, "webhook_id", webhookID)
+		response.RespondInternalError(w, r, apperrors.ErrFailedToTestWebhook.Error())
+		return
+	}
+
+	response.RespondJSON(w, http.StatusOK, delivery)
+}
@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/api/request"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/config"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/model"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/service"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/testutil"
+)
+
+func setupWebhookHandler(t *testing.T) (*WebhookHandler, *service.WebhookService) {
+	t.Helper()
+	db := testutil.SetupTestDB(t)
+	svc := testutil.NewTestWebhookService(t, db, config.Defaults().Webhooks)
+	return NewWebhookHandler(svc), svc
+}
+
+func TestWebhookHandler_CreateWebhook(t *testing.T) {
+	newRequest := func(body string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/api/webhooks", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		return req
+	}
+
+	t.Run("creates the webhook and returns its secret", func(t *testing.T) {
+		handler, _ := setupWebhookHandler(t)
+		w := httptest.NewRecorder()
+
+		handler.CreateWebhook(w, newRequest(`{"url":"https://example.com/hook","eventTypes":["ibkr.inbox_items"]}`))
+
+		if w.Code != http.StatusCreated {
+			t.Fatalf("Expected 201, got %d: %s", w.Code, w.Body.String())
+		}
+		var response model.Webhook
+		//nolint:errcheck // Test assertion - decode failure would cause test to fail anyway
+		json.NewDecoder(w.Body).Decode(&response)
+		if response.ID == "" || response.Secret == "" || !response.Enabled {
+			t.Errorf("Expected an enabled webhook with a secret, got %+v", response)
+		}
+	})
+
+	t.Run("unknown event returns 400", func(t *testing.T) {
+		handler, _ := setupWebhookHandler(t)
+		w := httptest.NewRecorder()
+
+		handler.CreateWebhook(w, newRequest(`{"url":"https://example.com/hook","eventTypes":["nope"]}`))
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected 400, got %d", w.Code)
+		}
+	})
+}
+
+func TestWebhookHandler_GetWebhook(t *testing.T) {
+	t.Run("omits the secret", func(t *testing.T) {
+		handler, svc := setupWebhookHandler(t)
+		created, err := svc.CreateWebhook(context.Background(), request.CreateWebhookRequest{
+			URL:        "https://example.com/hook",
+			EventTypes: []string{string(model.WebhookEventPriceUpdateFailed)},
+		})
+		if err != nil {
+			t.Fatalf("CreateWebhook: %v", err)
+		}
+
+		req := testutil.NewRequestWithURLParams(http.MethodGet, "/api/webhooks/"+created.ID, map[string]string{"uuid": created.ID})
+		w := httptest.NewRecorder()
+
+		handler.GetWebhook(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+		if strings.Contains(w.Body.String(), created.Secret) {
+			t.Error("Expected the secret to be omitted")
+		}
+	})
+
+	t.Run("unknown webhook returns 404", func(t *testing.T) {
+		handler, _ := setupWebhookHandler(t)
+		id := testutil.MakeID()
+
+		req := testutil.NewRequestWithURLParams(http.MethodGet, "/api/webhooks/"+id, map[string]string{"uuid": id})
+		w := httptest.NewRecorder()
+
+		handler.GetWebhook(w, req)
+
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Expected 404, got %d", w.Code)
+		}
+	})
+}
+
+func TestWebhookHandler_UpdateWebhook(t *testing.T) {
+	t.Run("unknown webhook returns 404", func(t *testing.T) {
+		handler, _ := setupWebhookHandler(t)
+		id := testutil.MakeID()
+
+		req := testutil.NewRequestWithURLParams(http.MethodPut, "/api/webhooks/"+id, map[string]string{"uuid": id})
+		req.Body = io.NopCloser(strings.NewReader(`{"enabled":false}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		handler.UpdateWebhook(w, req)
+
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Expected 404, got %d", w.Code)
+		}
+	})
+}
+
+func TestWebhookHandler_GetDeliveries(t *testing.T) {
+	t.Run("invalid limit returns 400", func(t *testing.T) {
+		handler, _ := setupWebhookHandler(t)
+		id := testutil.MakeID()
+
+		req := testutil.NewRequestWithURLParams(http.MethodGet, "/api/webhooks/"+id+"/deliveries?limit=0", map[string]string{"uuid": id})
+		w := httptest.NewRecorder()
+
+		handler.GetDeliveries(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected 400, got %d", w.Code)
+		}
+	})
+}
+
+func TestWebhookHandler_TestWebhook(t *testing.T) {
+	handler, svc := setupWebhookHandler(t)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(receiver.Close)
+	created, err := svc.CreateWebhook(context.Background(), request.CreateWebhookRequest{
+		URL:        receiver.URL,
+		EventTypes: []string{string(model.WebhookEventIbkrInboxItems)},
+	})
+	if err != nil {
+		t.Fatalf("CreateWebhook: %v", err)
+	}
+
+	req := testutil.NewRequestWithURLParams(http.MethodPost, "/api/webhooks/"+created.ID+"/test", map[string]string{"uuid": created.ID})
+	w := httptest.NewRecorder()
+
+	handler.TestWebhook(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var response model.WebhookDelivery
+	//nolint:errcheck // Test assertion - decode failure would cause test to fail anyway
+	json.NewDecoder(w.Body).Decode(&response)
+	if response.Status != model.WebhookDeliverySucceeded || response.ResponseStatus == nil || *response.ResponseStatus != http.StatusNoContent {
+		t.Errorf("Expected a succeeded test delivery, got %+v", response)
+	}
+}
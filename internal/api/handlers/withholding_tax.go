@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/api/request"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/api/response"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/apperrors"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/service"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/validation"
+)
+
+// WithholdingTaxHandler handles HTTP requests for dividend withholding tax endpoints.
+type WithholdingTaxHandler struct {
+	withholdingService *service.WithholdingTaxService
+}
+
+// NewWithholdingTaxHandler creates a new WithholdingTaxHandler with the provided service dependency.
+func NewWithholdingTaxHandler(withholdingService *service.WithholdingTaxService) *WithholdingTaxHandler {
+	return &WithholdingTaxHandler{
+		withholdingService: withholdingService,
+	}
+}
+
+// GetWithholdingTaxReport handles GET requests for the yearly withholding tax report across
+// the portfolios the caller can read.
+//
+// Endpoint: GET /api/dividend/withholding
+// Query parameters:
+//   - year: Year of the ex-dividend dates to report (default: last year)
+//
+// Response: 200 OK with WithholdingTaxReport
+// Error: 400 Bad Request if the year is invalid
+// Error: 500 Internal Server Error if retrieval fails
+func (h *WithholdingTaxHandler) GetWithholdingTaxReport(w http.ResponseWriter, r *http.Request) {
+	h.respondReport(w, r, "")
+}
+
+// GetPortfolioWithholdingTaxReport handles GET requests for the yearly withholding tax report
+// of a single portfolio.
+//
+// Endpoint: GET /api/dividend/portfolio/{uuid}/withholding
+// Query parameters:
+//   - year: Year of the ex-dividend dates to report (default: last year)
+//
+// Response: 200 OK with WithholdingTaxReport
+// Error: 400 Bad Request if the portfolio ID or year is invalid
+// Error: 500 Internal Server Error if retrieval fails
+func (h *WithholdingTaxHandler) GetPortfolioWithholdingTaxReport(w http.ResponseWriter, r *http.Request) {
+	h.respondReport(w, r, chi.URLParam(r, "uuid"))
+}
+
+func (h *WithholdingTaxHandler) respondReport(w http.ResponseWriter, r *http.Request, portfolioID string) {
+	year, err := request.ParseReportYear(r.URL.Query().Get("year"), time.Now().UTC())
+	if err != nil {
+		response.RespondError(w, http.StatusBadRequest, "invalid query parameters", err.Error())
+		return
+	}
+
+	report, err := h.withholdingService.GetWithholdingTaxReport(r.Context(), year, portfolioID)
+	if err != nil {
+		divLog.ErrorContext(r.Context(), "failed to get withholding tax report", "error", err, "year", year, "portfolio_id", portfolioID)
+		response.RespondInternalError(w, r, apperrors.ErrFailedToRetrieveWithholdingTax.Error())
+		return
+	}
+
+	response.RespondJSON(w, http.StatusOK, report)
+}
+
+// GetWithholdingTaxRates handles GET requests to list the default withholding tax rates per
+// fund domicile.
+//
+// Endpoint: GET /api/dividend/withholding/rates
+// Response: 200 OK with array of WithholdingTaxRate
+// Error: 500 Internal Server Error if retrieval fails
+func (h *WithholdingTaxHandler) GetWithholdingTaxRates(w http.ResponseWriter, r *http.Request) {
+	rates, err := h.withholdingService.GetWithholdingTaxRates()
+	if err != nil {
+		divLog.ErrorContext(r.Context(), "failed to get withholding tax rates", "error", err)
+		response.RespondInternalError(w, r, apperrors.ErrFailedToRetrieveWithholdingTax.Error())
+		return
+	}
+
+	response.RespondJSON(w, http.StatusOK, rates)
+}
+
+// SetWithholdingTaxRate handles PUT requests to create or replace the default withholding tax
+// rate of a fund domicile. Existing dividends are not changed.
+//
+// Endpoint: PUT /api/dividend/withholding/rates/{country}
+// Request Body: SetWithholdingTaxRateRequest (ratePercent)
+// Response: 200 OK with WithholdingTaxRate
+// Error: 400 Bad Request if validation fails or request body is invalid
+// Error: 403 Forbidden if the caller is not an administrator
+// Error: 500 Internal Server Error if the update fails
+func (h *WithholdingTaxHandler) SetWithholdingTaxRate(w http.ResponseWriter, r *http.Request) {
+	country := strings.ToUpper(chi.URLParam(r, "country"))
+
+	req, err := parseJSON[request.SetWithholdingTaxRateRequest](r)
+	if err != nil {
+		response.RespondError(w, http.StatusBadRequest, "invalid request body", err.Error())
+		return
+	}
+	if err := validation.ValidateSetWithholdingTaxRate(country, req); err != nil {
+		response.RespondError(w, http.StatusBadRequest, "validation failed", err.Error())
+		return
+	}
+
+	rate, err := h.withholdingService.SetWithholdingTaxRate(r.Context(), country, req)
+	if err != nil {
+		divLog.ErrorContext(r.Context(), "failed to set withholding tax rate", "error", err, "country", country)
+		response.RespondInternalError(w, r, apperrors.ErrFailedToUpdateWithholdingTaxRate.Error())
+		return
+	}
+
+	response.RespondJSON(w, http.StatusOK, rate)
+}
+
+// DeleteWithholdingTaxRate handles DELETE requests to remove the default withholding tax rate
+// of a fund domicile.
+//
+// Endpoint: DELETE /api/dividend/withholding/rates/{country}
+// Response: 204 No Content
+// Error: 403 Forbidden if the caller is not an administrator
+// Error: 404 Not Found if the country has no rate
+// Error: 500 Internal Server Error if deletion fails
+func (h *WithholdingTaxHandler) DeleteWithholdingTaxRate(w http.ResponseWriter, r *http.Request) {
+	country := strings.ToUpper(chi.URLParam(r, "country"))
+
+	if err := h.withholdingService.DeleteWithholdingTaxRate(r.Context(), country); err != nil {
+		if errors.Is(err, apperrors.ErrWithholdingTaxRateNotFound) {
+			response.RespondError(w, http.StatusNotFound, apperrors.ErrWithholdingTaxRateNotFound.Error(), "")
+			return
+		}
+		divLog.ErrorContext(r.Context(), "failed to delete withholding tax rate", "error", err, "country", country)
+		response.RespondInternalError(w, r, apperrors.ErrFailedToDeleteWithholdingTaxRate.Error())
+		return
+	}
+
+	response.RespondJSON(w, http.StatusNoContent, nil)
+}
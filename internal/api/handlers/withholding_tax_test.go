@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/model"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/testutil"
+)
+
+func TestWithholdingTaxHandler_GetWithholdingTaxReport(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	handler := NewWithholdingTaxHandler(testutil.NewTestWithholdingTaxService(t, db))
+
+	portfolio := testutil.NewPortfolio().Build(t, db)
+	fund := testutil.NewFund().WithISIN("US0378331005").Build(t, db)
+	pf := testutil.NewPortfolioFund(portfolio.ID, fund.ID).Build(t, db)
+	testutil.NewDividend(fund.ID, pf.ID).
+		WithExDividendDate(time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)).
+		WithWithholdingTax(7.5, model.WithholdingSourceManual).
+		Build(t, db)
+
+	t.Run("portfolio report for a year", func(t *testing.T) {
+		req := testutil.NewRequestWithQueryAndURLParams(http.MethodGet, "/api/dividend/portfolio/"+portfolio.ID+"/withholding",
+			map[string]string{"uuid": portfolio.ID}, map[string]string{"year": "2025"})
+		w := httptest.NewRecorder()
+		handler.GetPortfolioWithholdingTaxReport(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+		var response model.WithholdingTaxReport
+		//nolint:errcheck // Test assertion - decode failure would cause test to fail anyway
+		json.NewDecoder(w.Body).Decode(&response)
+		if response.Year != 2025 || len(response.Countries) != 1 || response.Countries[0].WithholdingTax != 7.5 {
+			t.Errorf("Unexpected report %+v", response)
+		}
+	})
+
+	t.Run("invalid year returns 400", func(t *testing.T) {
+		req := testutil.NewRequestWithQueryParams(http.MethodGet, "/api/dividend/withholding", map[string]string{"year": "abc"})
+		w := httptest.NewRecorder()
+		handler.GetWithholdingTaxReport(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected 400, got %d", w.Code)
+		}
+	})
+}
+
+func TestWithholdingTaxHandler_Rates(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	handler := NewWithholdingTaxHandler(testutil.NewTestWithholdingTaxService(t, db))
+
+	set := func(country, body string) *httptest.ResponseRecorder {
+		req := testutil.NewRequestWithURLParamsAndBody(http.MethodPut, "/api/dividend/withholding/rates/"+country,
+			map[string]string{"country": country}, body)
+		w := httptest.NewRecorder()
+		handler.SetWithholdingTaxRate(w, req)
+		return w
+	}
+	remove := func(country string) *httptest.ResponseRecorder {
+		req := testutil.NewRequestWithURLParams(http.MethodDelete, "/api/dividend/withholding/rates/"+country,
+			map[string]string{"country": country})
+		w := httptest.NewRecorder()
+		handler.DeleteWithholdingTaxRate(w, req)
+		return w
+	}
+
+	t.Run("set, list and delete", func(t *testing.T) {
+		if w := set("us", `{"ratePercent": 15}`); w.Code != http.StatusOK {
+			t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+
+		w := httptest.NewRecorder()
+		handler.GetWithholdingTaxRates(w, httptest.NewRequest(http.MethodGet, "/api/dividend/withholding/rates", nil))
+		var rates []model.WithholdingTaxRate
+		//nolint:errcheck // Test assertion - decode failure would cause test to fail anyway
+		json.NewDecoder(w.Body).Decode(&rates)
+		if len(rates) != 1 || rates[0].Country != "US" || rates[0].RatePercent != 15 {
+			t.Errorf("Expected the US rate of 15, got %+v", rates)
+		}
+
+		if w := remove("US"); w.Code != http.StatusNoContent {
+			t.Errorf("Expected 204, got %d", w.Code)
+		}
+	})
+
+	t.Run("rate above 100 returns 400", func(t *testing.T) {
+		if w := set("US", `{"ratePercent": 101}`); w.Code != http.StatusBadRequest {
+			t.Errorf("Expected 400, got %d", w.Code)
+		}
+	})
+
+	t.Run("deleting a missing rate returns 404", func(t *testing.T) {
+		if w := remove("ZZ"); w.Code != http.StatusNotFound {
+			t.Errorf("Expected 404, got %d", w.Code)
+		}
+	})
+}
@@ -0,0 +1,229 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/api/response"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/apperrors"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/auth"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/model"
+)
+
+// Authenticator resolves bearer tokens to the caller they belong to.
+type Authenticator interface {
+	// AuthEnabled reports whether requests must authenticate (i.e. any account exists).
+	AuthEnabled() (bool, error)
+	// Authenticate resolves a session or API token, returning apperrors.ErrSessionInvalid
+	// if it is unknown, expired or revoked.
+	Authenticate(ctx context.Context, token string) (*auth.Principal, error)
+}
+
+// PortfolioResolver maps the ID in a route's uuid parameter to the portfolio it belongs to.
+// It returns the entity's not-found error from apperrors when the entity does not exist.
+type PortfolioResolver func(id string) (string, error)
+
+// PortfolioIDParam is the PortfolioResolver for routes whose uuid parameter is the portfolio ID itself.
+func PortfolioIDParam(id string) (string, error) {
+	return id, nil
+}
+
+// BearerToken returns the token from an "Authorization: Bearer <token>" header, or "".
+func BearerToken(r *http.Request) string {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
+
+// Authenticate returns a middleware that requires a valid session token and attaches
+// the caller to the request context. While no account exists yet, requests pass
+// through unauthenticated so an existing single-user installation keeps working
+// until POST /api/auth/setup is called.
+func Authenticate(a Authenticator) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			enabled, err := a.AuthEnabled()
+			if err != nil {
+				log.ErrorContext(r.Context(), "failed to check whether authentication is enabled", "error", err)
+				response.RespondInternalError(w, r, apperrors.ErrFailedToAuthenticate.Error())
+				return
+			}
+			if !enabled {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			token := BearerToken(r)
+			if token == "" {
+				response.RespondError(w, http.StatusUnauthorized, apperrors.ErrAuthenticationRequired.Error(), "Missing bearer token")
+				return
+			}
+
+			principal, err := a.Authenticate(r.Context(), token)
+			if errors.Is(err, apperrors.ErrSessionInvalid) {
+				response.RespondError(w, http.StatusUnauthorized, apperrors.ErrSessionInvalid.Error(), "")
+				return
+			}
+			if err != nil {
+				log.ErrorContext(r.Context(), "failed to authenticate request", "error", err)
+				response.RespondInternalError(w, r, apperrors.ErrFailedToAuthenticate.Error())
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(auth.WithPrincipal(r.Context(), principal)))
+		})
+	}
+}
+
+// RequireAdmin rejects callers that are not administrators with 403 Forbidden.
+// Requests without a Principal (authentication not yet enabled) pass through.
+func RequireAdmin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if p := auth.PrincipalFromContext(r.Context()); p != nil && !p.User.IsAdmin {
+			response.RespondError(w, http.StatusForbidden, apperrors.ErrAdminRequired.Error(), "")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// RequireScope returns a middleware that rejects API tokens lacking scope with 403 Forbidden.
+// Login sessions and requests without a Principal pass through.
+func RequireScope(scope model.APIScope) func(http.Handler) http.Handler {
+	return RequireMethodScope(scope, scope)
+}
+
+// RequireMethodScope is like RequireScope but picks the scope by method:
+// GET and HEAD need readScope, every other method needs writeScope.
+func RequireMethodScope(readScope, writeScope model.APIScope) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			scope := writeScope
+			if r.Method == http.MethodGet || r.Method == http.MethodHead {
+				scope = readScope
+			}
+			if p := auth.PrincipalFromContext(r.Context()); p != nil && !p.HasScope(scope) {
+				response.RespondError(w, http.StatusForbidden, apperrors.ErrScopeRequired.Error(), string(scope))
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireSession rejects requests authenticated with an API token with 403 Forbidden.
+// It guards managing API tokens and the account itself, which a token must not be able to do.
+func RequireSession(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if p := auth.PrincipalFromContext(r.Context()); p != nil && p.APITokenID != "" {
+			response.RespondError(w, http.StatusForbidden, apperrors.ErrSessionRequired.Error(), "")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// BearerOrAPIKey lets a route accept either the internal API key or an API token.
+// Requests with an Authorization header must authenticate as a user holding scope;
+// all others are checked by APIKeyMiddleware. Unlike Authenticate, a bearer token is
+// required even before the first account exists, so the route never becomes open.
+func BearerOrAPIKey(a Authenticator, validAPIKey string, scope model.APIScope) func(http.Handler) http.Handler {
+	apiKey := APIKeyMiddleware(validAPIKey)
+	requireScope := RequireScope(scope)
+
+	return func(next http.Handler) http.Handler {
+		viaAPIKey := apiKey(next)
+		viaToken := requireScope(next)
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") == "" {
+				viaAPIKey.ServeHTTP(w, r)
+				return
+			}
+
+			principal, err := a.Authenticate(r.Context(), BearerToken(r))
+			if errors.Is(err, apperrors.ErrSessionInvalid) {
+				response.RespondError(w, http.StatusUnauthorized, apperrors.ErrSessionInvalid.Error(), "")
+				return
+			}
+			if err != nil {
+				log.ErrorContext(r.Context(), "failed to authenticate request", "error", err)
+				response.RespondInternalError(w, r, apperrors.ErrFailedToAuthenticate.Error())
+				return
+			}
+
+			viaToken.ServeHTTP(w, r.WithContext(auth.WithPrincipal(r.Context(), principal)))
+		})
+	}
+}
+
+// RequirePortfolioAccess returns a middleware that checks the caller's access to the
+// portfolio owning the entity in the "uuid" URL parameter. GET and HEAD need read
+// access; every other method needs write access.
+//
+// Entities in portfolios the caller cannot see are reported as 404 Not Found so their
+// existence is not revealed; callers with too little access get 403 Forbidden.
+// Entities that do not exist pass through to the handler, which reports them as usual.
+func RequirePortfolioAccess(resolve PortfolioResolver) func(http.Handler) http.Handler {
+	return requirePortfolio(resolve, func(r *http.Request) model.PortfolioAccess {
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			return model.PortfolioAccessRead
+		}
+		return model.PortfolioAccessWrite
+	})
+}
+
+// RequirePortfolioOwner is like RequirePortfolioAccess but requires ownership for every
+// method. It guards deleting a portfolio and managing who it is shared with.
+func RequirePortfolioOwner(resolve PortfolioResolver) func(http.Handler) http.Handler {
+	return requirePortfolio(resolve, func(*http.Request) model.PortfolioAccess {
+		return model.PortfolioAccessOwner
+	})
+}
+
+func requirePortfolio(resolve PortfolioResolver, required func(*http.Request) model.PortfolioAccess) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			p := auth.PrincipalFromContext(r.Context())
+			if p == nil || p.User.IsAdmin {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			portfolioID, err := resolve(chi.URLParam(r, "uuid"))
+			if err != nil {
+				if isNotFound(err) {
+					next.ServeHTTP(w, r)
+					return
+				}
+				log.ErrorContext(r.Context(), "failed to resolve portfolio for access check", "error", err)
+				response.RespondInternalError(w, r, apperrors.ErrFailedToAuthenticate.Error())
+				return
+			}
+
+			access, ok := p.PortfolioAccess(portfolioID)
+			switch {
+			case !ok:
+				response.RespondError(w, http.StatusNotFound, "not found", "")
+			case !access.Allows(required(r)):
+				response.RespondError(w, http.StatusForbidden, apperrors.ErrPortfolioAccessDenied.Error(), "")
+			default:
+				next.ServeHTTP(w, r)
+			}
+		})
+	}
+}
+
+// isNotFound reports whether err is one of the entity-not-found errors a resolver returns.
+func isNotFound(err error) bool {
+	return errors.Is(err, apperrors.ErrPortfolioNotFound) ||
+		errors.Is(err, apperrors.ErrPortfolioFundNotFound) ||
+		errors.Is(err, apperrors.ErrTransactionNotFound) ||
+		errors.Is(err, apperrors.ErrDividendNotFound) ||
+		errors.Is(err, apperrors.ErrInvestmentPlanNotFound)
+}
@@ -0,0 +1,284 @@
+package middleware_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/api/middleware"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/apperrors"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/auth"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/model"
+)
+
+type fakeAuthenticator struct {
+	enabled   bool
+	principal *auth.Principal
+	err       error
+}
+
+func (f fakeAuthenticator) AuthEnabled() (bool, error) { return f.enabled, nil }
+
+func (f fakeAuthenticator) Authenticate(_ context.Context, token string) (*auth.Principal, error) {
+	if token != "valid" {
+		return nil, apperrors.ErrSessionInvalid
+	}
+	return f.principal, f.err
+}
+
+// principalRecorder returns a handler that stores the request's Principal in *got.
+func principalRecorder(got **auth.Principal) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*got = auth.PrincipalFromContext(r.Context())
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+func TestAuthenticate(t *testing.T) {
+	user := auth.NewPrincipal(model.User{ID: "user-1"}, nil, nil)
+
+	tests := []struct {
+		name       string
+		auth       fakeAuthenticator
+		header     string
+		wantStatus int
+		wantUser   bool
+	}{
+		{name: "passes through before setup", auth: fakeAuthenticator{}, wantStatus: http.StatusOK},
+		{name: "missing token", auth: fakeAuthenticator{enabled: true}, wantStatus: http.StatusUnauthorized},
+		{name: "non-bearer scheme", auth: fakeAuthenticator{enabled: true}, header: "Basic valid", wantStatus: http.StatusUnauthorized},
+		{name: "invalid token", auth: fakeAuthenticator{enabled: true}, header: "Bearer nope", wantStatus: http.StatusUnauthorized},
+		{name: "lookup failure", auth: fakeAuthenticator{enabled: true, err: errors.New("db down")}, header: "Bearer valid", wantStatus: http.StatusInternalServerError},
+		{name: "valid token", auth: fakeAuthenticator{enabled: true, principal: user}, header: "Bearer valid", wantStatus: http.StatusOK, wantUser: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got *auth.Principal
+			mw := middleware.Authenticate(tt.auth)(principalRecorder(&got))
+
+			req := httptest.NewRequest(http.MethodGet, "/test", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			w := httptest.NewRecorder()
+			mw.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("Expected %d, got %d", tt.wantStatus, w.Code)
+			}
+			if tt.wantUser && got != user {
+				t.Error("Expected principal in request context")
+			}
+		})
+	}
+}
+
+func TestRequireAdmin(t *testing.T) {
+	tests := []struct {
+		name       string
+		principal  *auth.Principal
+		wantStatus int
+	}{
+		{name: "no principal", wantStatus: http.StatusOK},
+		{name: "admin", principal: auth.NewPrincipal(model.User{IsAdmin: true}, nil, nil), wantStatus: http.StatusOK},
+		{name: "regular user", principal: auth.NewPrincipal(model.User{}, nil, nil), wantStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got *auth.Principal
+			mw := middleware.RequireAdmin(principalRecorder(&got))
+
+			req := httptest.NewRequest(http.MethodGet, "/test", nil)
+			if tt.principal != nil {
+				req = req.WithContext(auth.WithPrincipal(req.Context(), tt.principal))
+			}
+			w := httptest.NewRecorder()
+			mw.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("Expected %d, got %d", tt.wantStatus, w.Code)
+			}
+		})
+	}
+}
+
+func TestRequirePortfolioAccess(t *testing.T) {
+	principal := auth.NewPrincipal(model.User{ID: "user-1"}, map[string]model.PortfolioAccess{
+		"owned":  model.PortfolioAccessOwner,
+		"shared": model.PortfolioAccessRead,
+	}, nil)
+
+	resolve := func(id string) (string, error) {
+		switch id {
+		case "missing":
+			return "", apperrors.ErrTransactionNotFound
+		case "broken":
+			return "", errors.New("db down")
+		}
+		return id, nil
+	}
+
+	tests := []struct {
+		name       string
+		mw         func(middleware.PortfolioResolver) func(http.Handler) http.Handler
+		method     string
+		id         string
+		wantStatus int
+	}{
+		{name: "read own", mw: middleware.RequirePortfolioAccess, method: http.MethodGet, id: "owned", wantStatus: http.StatusOK},
+		{name: "write own", mw: middleware.RequirePortfolioAccess, method: http.MethodPut, id: "owned", wantStatus: http.StatusOK},
+		{name: "read shared", mw: middleware.RequirePortfolioAccess, method: http.MethodGet, id: "shared", wantStatus: http.StatusOK},
+		{name: "write read-only share", mw: middleware.RequirePortfolioAccess, method: http.MethodPut, id: "shared", wantStatus: http.StatusForbidden},
+		{name: "foreign portfolio is hidden", mw: middleware.RequirePortfolioAccess, method: http.MethodGet, id: "foreign", wantStatus: http.StatusNotFound},
+		{name: "missing entity reaches handler", mw: middleware.RequirePortfolioAccess, method: http.MethodGet, id: "missing", wantStatus: http.StatusOK},
+		{name: "resolver failure", mw: middleware.RequirePortfolioAccess, method: http.MethodGet, id: "broken", wantStatus: http.StatusInternalServerError},
+		{name: "owner action on own", mw: middleware.RequirePortfolioOwner, method: http.MethodDelete, id: "owned", wantStatus: http.StatusOK},
+		{name: "owner action on share", mw: middleware.RequirePortfolioOwner, method: http.MethodGet, id: "shared", wantStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got *auth.Principal
+			mw := tt.mw(resolve)(principalRecorder(&got))
+
+			req := httptest.NewRequest(tt.method, "/test", nil)
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("uuid", tt.id)
+			ctx := context.WithValue(req.Context(), chi.RouteCtxKey, rctx)
+			req = req.WithContext(auth.WithPrincipal(ctx, principal))
+
+			w := httptest.NewRecorder()
+			mw.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("Expected %d, got %d", tt.wantStatus, w.Code)
+			}
+		})
+	}
+}
+
+func TestRequireMethodScope(t *testing.T) {
+	session := auth.NewPrincipal(model.User{ID: "user-1"}, nil, nil)
+	token := session.WithAPIToken("token-1", []model.APIScope{model.ScopeWriteTransaction, model.ScopeReadFund})
+
+	tests := []struct {
+		name       string
+		principal  *auth.Principal
+		method     string
+		read       model.APIScope
+		write      model.APIScope
+		wantStatus int
+	}{
+		{name: "no principal", method: http.MethodPost, read: model.ScopeReadPortfolio, write: model.ScopeWritePortfolio, wantStatus: http.StatusOK},
+		{name: "session has every scope", principal: session, method: http.MethodDelete, read: model.ScopeReadPortfolio, write: model.ScopeWritePortfolio, wantStatus: http.StatusOK},
+		{name: "write scope grants read", principal: token, method: http.MethodGet, read: model.ScopeReadTransaction, write: model.ScopeWriteTransaction, wantStatus: http.StatusOK},
+		{name: "write scope grants write", principal: token, method: http.MethodPost, read: model.ScopeReadTransaction, write: model.ScopeWriteTransaction, wantStatus: http.StatusOK},
+		{name: "read scope does not grant write", principal: token, method: http.MethodPut, read: model.ScopeReadFund, write: model.ScopeWriteFund, wantStatus: http.StatusForbidden},
+		{name: "missing scope", principal: token, method: http.MethodGet, read: model.ScopeReadPortfolio, write: model.ScopeWritePortfolio, wantStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got *auth.Principal
+			mw := middleware.RequireMethodScope(tt.read, tt.write)(principalRecorder(&got))
+
+			req := httptest.NewRequest(tt.method, "/test", nil)
+			if tt.principal != nil {
+				req = req.WithContext(auth.WithPrincipal(req.Context(), tt.principal))
+			}
+			w := httptest.NewRecorder()
+			mw.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("Expected %d, got %d", tt.wantStatus, w.Code)
+			}
+		})
+	}
+}
+
+func TestRequireSession(t *testing.T) {
+	session := auth.NewPrincipal(model.User{ID: "user-1"}, nil, nil)
+
+	for _, tt := range []struct {
+		name       string
+		principal  *auth.Principal
+		wantStatus int
+	}{
+		{name: "session", principal: session, wantStatus: http.StatusOK},
+		{name: "API token", principal: session.WithAPIToken("token-1", nil), wantStatus: http.StatusForbidden},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var got *auth.Principal
+			mw := middleware.RequireSession(principalRecorder(&got))
+
+			req := httptest.NewRequest(http.MethodPost, "/test", nil)
+			req = req.WithContext(auth.WithPrincipal(req.Context(), tt.principal))
+			w := httptest.NewRecorder()
+			mw.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("Expected %d, got %d", tt.wantStatus, w.Code)
+			}
+		})
+	}
+}
+
+func TestBearerOrAPIKey(t *testing.T) {
+	apiKey := "test-api-key-12345"
+	user := auth.NewPrincipal(model.User{ID: "user-1"}, nil, nil)
+
+	tests := []struct {
+		name       string
+		principal  *auth.Principal
+		headers    map[string]string
+		wantStatus int
+	}{
+		{
+			name:       "valid API key",
+			headers:    map[string]string{"X-API-Key": apiKey, "X-Time-Token": middleware.GenerateTimeToken(apiKey)},
+			wantStatus: http.StatusOK,
+		},
+		{name: "no credentials", wantStatus: http.StatusUnauthorized},
+		{
+			name:       "token with scope",
+			principal:  user.WithAPIToken("token-1", []model.APIScope{model.ScopeWriteFund}),
+			headers:    map[string]string{"Authorization": "Bearer valid"},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "token without scope",
+			principal:  user.WithAPIToken("token-1", []model.APIScope{model.ScopeReadFund}),
+			headers:    map[string]string{"Authorization": "Bearer valid"},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "invalid token is not passed through before setup",
+			headers:    map[string]string{"Authorization": "Bearer nope"},
+			wantStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got *auth.Principal
+			a := fakeAuthenticator{principal: tt.principal}
+			mw := middleware.BearerOrAPIKey(a, apiKey, model.ScopeWriteFund)(principalRecorder(&got))
+
+			req := httptest.NewRequest(http.MethodPost, "/test", nil)
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			w := httptest.NewRecorder()
+			mw.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("Expected %d, got %d", tt.wantStatus, w.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/api/middleware"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/metrics"
+)
+
+func TestMetrics(t *testing.T) {
+	r := chi.NewRouter()
+	r.Use(middleware.Metrics)
+	r.Get("/metrics-test/{uuid}", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+
+	requests := metrics.HTTPRequests.WithLabelValues(http.MethodGet, "/metrics-test/{uuid}", "418")
+	unmatched := metrics.HTTPRequests.WithLabelValues(http.MethodGet, "unmatched", "404")
+	beforeRequests := testutil.ToFloat64(requests)
+	beforeUnmatched := testutil.ToFloat64(unmatched)
+
+	for _, path := range []string{"/metrics-test/a", "/metrics-test/b", "/no-such-route"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	if got := testutil.ToFloat64(requests) - beforeRequests; got != 2 {
+		t.Errorf("Expected 2 requests recorded under the route pattern, got %v", got)
+	}
+	if got := testutil.ToFloat64(unmatched) - beforeUnmatched; got != 1 {
+		t.Errorf("Expected 1 unmatched request, got %v", got)
+	}
+}
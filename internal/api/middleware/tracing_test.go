@@ -0,0 +1,51 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/api/middleware"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/testutil"
+)
+
+func TestTracing(t *testing.T) {
+	recorder := testutil.RecordSpans(t)
+
+	var handlerSpan trace.SpanContext
+	r := chi.NewRouter()
+	r.Use(middleware.Tracing)
+	r.Get("/portfolio/{uuid}", func(w http.ResponseWriter, req *http.Request) {
+		handlerSpan = trace.SpanContextFromContext(req.Context())
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/portfolio/abc", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("Expected 1 span, got %d", len(spans))
+	}
+	span := spans[0]
+	if span.Name() != "GET /portfolio/{uuid}" {
+		t.Errorf("Expected span named after the route pattern, got %q", span.Name())
+	}
+	if span.SpanContext().TraceID().String() != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("Expected the incoming trace to be continued, got trace %s", span.SpanContext().TraceID())
+	}
+	if span.SpanKind() != trace.SpanKindServer {
+		t.Errorf("Expected server span, got %v", span.SpanKind())
+	}
+	if span.Status().Code != codes.Error {
+		t.Errorf("Expected error status for a 500, got %v", span.Status().Code)
+	}
+	if span.SpanContext().SpanID() != handlerSpan.SpanID() {
+		t.Error("Expected the span in the handler's request context")
+	}
+}
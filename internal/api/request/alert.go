@@ -0,0 +1,54 @@
+package request
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/model"
+)
+
+// CreateAlertRuleRequest is the request body for creating an alert rule. Which of
+// PortfolioID, FundID, Direction and Period are required depends on Type; the others are ignored.
+type CreateAlertRuleRequest struct {
+	Name        string   `json:"name"`
+	Type        string   `json:"type"`
+	PortfolioID string   `json:"portfolioId"`
+	FundID      string   `json:"fundId"`
+	Direction   string   `json:"direction"`
+	Period      string   `json:"period"`
+	Threshold   *float64 `json:"threshold"`
+	Enabled     *bool    `json:"enabled"` // Defaults to true
+}
+
+// UpdateAlertRuleRequest is the request body for replacing an alert rule. It takes the
+// same fields as CreateAlertRuleRequest; an omitted enabled flag keeps its current value.
+type UpdateAlertRuleRequest CreateAlertRuleRequest
+
+// ParseAlertFilters extracts and validates the alert list filters from query parameters.
+// Statuses are comma-separated (active, acknowledged, resolved) and default to all; the
+// limit must be between 1 and 250 and defaults to 50.
+func ParseAlertFilters(statusParam, limitParam string) ([]model.AlertStatus, int, error) {
+	var statuses []model.AlertStatus
+	if statusParam != "" {
+		for status := range strings.SplitSeq(statusParam, ",") {
+			status = strings.TrimSpace(strings.ToLower(status))
+			if !model.ValidAlertStatuses[model.AlertStatus(status)] {
+				return nil, 0, fmt.Errorf("invalid status: %s", status)
+			}
+			statuses = append(statuses, model.AlertStatus(status))
+		}
+	}
+
+	limit := 50
+	if limitParam != "" {
+		var err error
+		if limit, err = strconv.Atoi(limitParam); err != nil {
+			return nil, 0, fmt.Errorf("invalid limit: must be a number")
+		}
+		if limit < 1 || limit > 250 {
+			return nil, 0, fmt.Errorf("invalid limit: must be between 1 and 250")
+		}
+	}
+	return statuses, limit, nil
+}
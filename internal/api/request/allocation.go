@@ -0,0 +1,52 @@
+package request
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/model"
+)
+
+// AllocationTargetRequest is one target weight in a SetAllocationTargetsRequest. Exactly
+// one of FundID and AssetClass must be set.
+type AllocationTargetRequest struct {
+	FundID           string  `json:"fundId"`
+	AssetClass       string  `json:"assetClass"`
+	TargetPercent    float64 `json:"targetPercent"`
+	TolerancePercent float64 `json:"tolerancePercent"`
+}
+
+// SetAllocationTargetsRequest is the request body for replacing the allocation targets of
+// a portfolio. An empty list removes the targets.
+type SetAllocationTargetsRequest struct {
+	Targets []AllocationTargetRequest `json:"targets"`
+}
+
+// ParseRebalanceParams extracts and validates the rebalance mode and contribution from
+// query parameters. The mode defaults to full and the contribution to 0; new_money needs
+// a positive contribution because it never sells.
+func ParseRebalanceParams(modeParam, contributionParam string) (model.RebalanceMode, float64, error) {
+	mode := model.RebalanceModeFull
+	if modeParam != "" {
+		mode = model.RebalanceMode(strings.ToLower(strings.TrimSpace(modeParam)))
+		if !model.ValidRebalanceModes[mode] {
+			return "", 0, fmt.Errorf("invalid mode: must be full or new_money")
+		}
+	}
+
+	var contribution float64
+	if contributionParam != "" {
+		var err error
+		if contribution, err = strconv.ParseFloat(contributionParam, 64); err != nil {
+			return "", 0, fmt.Errorf("invalid contribution: must be a number")
+		}
+		if contribution < 0 {
+			return "", 0, fmt.Errorf("invalid contribution: must not be negative")
+		}
+	}
+	if mode == model.RebalanceModeNewMoney && contribution <= 0 {
+		return "", 0, fmt.Errorf("invalid contribution: new_money mode needs a contribution greater than 0")
+	}
+	return mode, contribution, nil
+}
@@ -0,0 +1,94 @@
+package request
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/model"
+)
+
+// ParseAuditFilters extracts and validates audit trail filters from query parameters.
+// Mirrors ParseLogFilters: entity types and actions are comma-separated, all parameters
+// are optional.
+//
+// Validation rules:
+//   - entityType: Must be valid audit entity types (portfolio, fund, transaction, etc.)
+//   - action: Must be valid audit actions (create, update, delete, allocate, etc.)
+//   - startDate/endDate: Must be valid date/datetime strings (YYYY-MM-DD or RFC3339)
+//   - sortDir: Must be "asc" or "desc" (defaults to "desc")
+//   - perPage: Must be between 1 and 250 (defaults to 50)
+//
+// Returns an error if any parameter fails validation.
+//
+//nolint:gocyclo // Sequential parameter validation; mirrors ParseLogFilters.
+func ParseAuditFilters(
+	entityTypeParam, entityIDParam, actionParam, requestIDParam, userIDParam,
+	startDateParam, endDateParam, sortDirParam, cursorParam, perPageParam string,
+) (*model.AuditFilters, error) {
+	filters := &model.AuditFilters{
+		EntityID:  strings.TrimSpace(entityIDParam),
+		RequestID: strings.TrimSpace(requestIDParam),
+		UserID:    strings.TrimSpace(userIDParam),
+		Cursor:    cursorParam,
+		SortDir:   "desc",
+		PerPage:   50,
+	}
+
+	if entityTypeParam != "" {
+		for entityType := range strings.SplitSeq(entityTypeParam, ",") {
+			entityType = strings.TrimSpace(strings.ToLower(entityType))
+			if !model.ValidAuditEntityTypes[model.AuditEntityType(entityType)] {
+				return nil, fmt.Errorf("invalid entity type: %s", entityType)
+			}
+			filters.EntityTypes = append(filters.EntityTypes, entityType)
+		}
+	}
+
+	if actionParam != "" {
+		for action := range strings.SplitSeq(actionParam, ",") {
+			action = strings.TrimSpace(strings.ToLower(action))
+			if !model.ValidAuditActions[model.AuditAction(action)] {
+				return nil, fmt.Errorf("invalid action: %s", action)
+			}
+			filters.Actions = append(filters.Actions, action)
+		}
+	}
+
+	if startDateParam != "" {
+		startTime, err := parseFilterTime(startDateParam)
+		if err != nil {
+			return nil, fmt.Errorf("invalid start_date format: %w", err)
+		}
+		filters.StartDate = &startTime
+	}
+
+	if endDateParam != "" {
+		endTime, err := parseFilterTime(endDateParam)
+		if err != nil {
+			return nil, fmt.Errorf("invalid end_date format: %w", err)
+		}
+		filters.EndDate = &endTime
+	}
+
+	if sortDirParam != "" {
+		sortDir := strings.ToLower(sortDirParam)
+		if sortDir != "asc" && sortDir != "desc" {
+			return nil, fmt.Errorf("invalid sort_dir: must be 'asc' or 'desc'")
+		}
+		filters.SortDir = sortDir
+	}
+
+	if perPageParam != "" {
+		perPage, err := strconv.Atoi(perPageParam)
+		if err != nil {
+			return nil, fmt.Errorf("invalid per_page: must be a number")
+		}
+		if perPage < 1 || perPage > 250 {
+			return nil, fmt.Errorf("invalid per_page: must be between 1 and 250")
+		}
+		filters.PerPage = perPage
+	}
+
+	return filters, nil
+}
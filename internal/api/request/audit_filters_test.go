@@ -0,0 +1,70 @@
+package request
+
+import (
+	"testing"
+)
+
+func TestParseAuditFilters(t *testing.T) {
+	t.Run("default values when no parameters provided", func(t *testing.T) {
+		filters, err := ParseAuditFilters("", "", "", "", "", "", "", "", "", "")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if filters.SortDir != "desc" {
+			t.Errorf("Expected default SortDir 'desc', got '%s'", filters.SortDir)
+		}
+		if filters.PerPage != 50 {
+			t.Errorf("Expected default PerPage 50, got %d", filters.PerPage)
+		}
+	})
+
+	t.Run("multiple entity types and actions", func(t *testing.T) {
+		filters, err := ParseAuditFilters("Transaction, dividend", "abc", "create,DELETE", "req-1", "user-1", "", "", "asc", "", "10")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(filters.EntityTypes) != 2 || filters.EntityTypes[0] != "transaction" || filters.EntityTypes[1] != "dividend" {
+			t.Errorf("Unexpected entity types: %v", filters.EntityTypes)
+		}
+		if len(filters.Actions) != 2 || filters.Actions[1] != "delete" {
+			t.Errorf("Unexpected actions: %v", filters.Actions)
+		}
+		if filters.EntityID != "abc" || filters.RequestID != "req-1" || filters.UserID != "user-1" {
+			t.Errorf("Unexpected entity/request/user ID: %q %q %q", filters.EntityID, filters.RequestID, filters.UserID)
+		}
+		if filters.SortDir != "asc" || filters.PerPage != 10 {
+			t.Errorf("Unexpected sort/perPage: %s %d", filters.SortDir, filters.PerPage)
+		}
+	})
+
+	t.Run("date range", func(t *testing.T) {
+		filters, err := ParseAuditFilters("", "", "", "", "", "2025-01-01", "2025-01-31T23:59:59Z", "", "", "")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if filters.StartDate == nil || filters.EndDate == nil {
+			t.Fatal("Expected both dates to be set")
+		}
+	})
+
+	invalid := []struct {
+		name   string
+		params [9]string
+	}{
+		{"invalid entity type", [9]string{"widget"}},
+		{"invalid action", [9]string{"", "", "explode"}},
+		{"invalid start date", [9]string{"", "", "", "", "01-01-2025"}},
+		{"invalid end date", [9]string{"", "", "", "", "", "tomorrow"}},
+		{"invalid sort dir", [9]string{"", "", "", "", "", "", "sideways"}},
+		{"non-numeric perPage", [9]string{"", "", "", "", "", "", "", "", "many"}},
+		{"perPage out of range", [9]string{"", "", "", "", "", "", "", "", "251"}},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			p := tt.params
+			if _, err := ParseAuditFilters(p[0], p[1], p[2], p[3], "", p[4], p[5], p[6], p[7], p[8]); err == nil {
+				t.Error("Expected error, got nil")
+			}
+		})
+	}
+}
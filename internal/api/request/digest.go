@@ -0,0 +1,27 @@
+package request
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/model"
+)
+
+// UpdateDigestSettingsRequest is the request body for changing the email digest settings.
+// Omitted fields keep their current value; an empty recipient list removes every recipient.
+type UpdateDigestSettingsRequest struct {
+	Enabled    *bool    `json:"enabled"`
+	Period     *string  `json:"period"`
+	Recipients []string `json:"recipients"`
+}
+
+// ParseDigestPeriod validates the period query parameter of the digest endpoints.
+// Accepts week or month (case-insensitive); an empty value is returned as is, meaning the
+// period in the digest settings.
+func ParseDigestPeriod(param string) (model.DigestPeriod, error) {
+	period := model.DigestPeriod(strings.TrimSpace(strings.ToLower(param)))
+	if period != "" && !model.ValidDigestPeriods[period] {
+		return "", fmt.Errorf("invalid period: must be week or month")
+	}
+	return period, nil
+}
@@ -1,15 +1,17 @@
 package request
 
 // CreateDividendRequest represents the request body for creating a new dividend.
-// All fields are required.
+// All fields are required except the reinvestment fields and WithholdingTax, which defaults
+// to the gross amount times the default rate of the fund's domicile.
 type CreateDividendRequest struct {
-	PortfolioFundID    string  `json:"portfolioFundId"`
-	RecordDate         string  `json:"recordDate"`
-	ExDividendDate     string  `json:"exDividendDate"`
-	DividendPerShare   float64 `json:"dividendPerShare"`
-	BuyOrderDate       string  `json:"buyOrderDate,omitempty"`
-	ReinvestmentShares float64 `json:"reinvestmentShares,omitempty"`
-	ReinvestmentPrice  float64 `json:"reinvestmentPrice,omitempty"`
+	PortfolioFundID    string   `json:"portfolioFundId"`
+	RecordDate         string   `json:"recordDate"`
+	ExDividendDate     string   `json:"exDividendDate"`
+	DividendPerShare   float64  `json:"dividendPerShare"`
+	BuyOrderDate       string   `json:"buyOrderDate,omitempty"`
+	ReinvestmentShares float64  `json:"reinvestmentShares,omitempty"`
+	ReinvestmentPrice  float64  `json:"reinvestmentPrice,omitempty"`
+	WithholdingTax     *float64 `json:"withholdingTax,omitempty"`
 }
 
 // UpdateDividendRequest represents the request body for updating an existing dividend.
@@ -22,4 +24,5 @@ type UpdateDividendRequest struct {
 	BuyOrderDate       *string  `json:"buyOrderDate,omitempty"`
 	ReinvestmentShares *float64 `json:"reinvestmentShares,omitempty"`
 	ReinvestmentPrice  *float64 `json:"reinvestmentPrice,omitempty"`
+	WithholdingTax     *float64 `json:"withholdingTax,omitempty"`
 }
@@ -0,0 +1,21 @@
+package request
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/model"
+)
+
+// ParseHistoryInterval validates the interval query parameter of history endpoints.
+// Accepts day, week or month (case-insensitive); an empty value defaults to day.
+func ParseHistoryInterval(param string) (model.HistoryInterval, error) {
+	switch interval := model.HistoryInterval(strings.TrimSpace(strings.ToLower(param))); interval {
+	case "":
+		return model.HistoryIntervalDay, nil
+	case model.HistoryIntervalDay, model.HistoryIntervalWeek, model.HistoryIntervalMonth:
+		return interval, nil
+	default:
+		return "", fmt.Errorf("invalid interval: must be day, week or month")
+	}
+}
@@ -0,0 +1,31 @@
+package request
+
+import (
+	"testing"
+
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/model"
+)
+
+func TestParseHistoryInterval(t *testing.T) {
+	valid := map[string]model.HistoryInterval{
+		"":      model.HistoryIntervalDay,
+		"day":   model.HistoryIntervalDay,
+		"Week":  model.HistoryIntervalWeek,
+		"MONTH": model.HistoryIntervalMonth,
+	}
+	for param, want := range valid {
+		got, err := ParseHistoryInterval(param)
+		if err != nil {
+			t.Errorf("ParseHistoryInterval(%q): unexpected error %v", param, err)
+		}
+		if got != want {
+			t.Errorf("ParseHistoryInterval(%q) = %q, want %q", param, got, want)
+		}
+	}
+
+	for _, param := range []string{"year", "daily", "1"} {
+		if _, err := ParseHistoryInterval(param); err == nil {
+			t.Errorf("ParseHistoryInterval(%q): expected error", param)
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package request
+
+// StageInboxTransactionsRequest is the request body for staging transactions in the inbox.
+// Any importer (CSV profiles, other brokers, manual bulk paste) can submit a batch under its own source.
+type StageInboxTransactionsRequest struct {
+	Source       string                  `json:"source"`
+	Transactions []InboxTransactionEntry `json:"transactions"`
+}
+
+// InboxTransactionEntry is a single staged transaction. ExternalID is the importer's own reference
+// and is used to skip duplicates when the same batch is submitted twice; when omitted, a reference
+// is derived from the transaction's content.
+type InboxTransactionEntry struct {
+	ExternalID  string  `json:"externalId,omitempty"`
+	Date        string  `json:"date"`
+	Symbol      string  `json:"symbol,omitempty"`
+	ISIN        string  `json:"isin,omitempty"`
+	Description string  `json:"description,omitempty"`
+	Type        string  `json:"type"`
+	Quantity    float64 `json:"quantity"`
+	Price       float64 `json:"price"`
+	TotalAmount float64 `json:"totalAmount"`
+	Currency    string  `json:"currency"`
+	Fees        float64 `json:"fees"`
+	Notes       string  `json:"notes,omitempty"`
+}
@@ -0,0 +1,24 @@
+package request
+
+// CreateInvestmentPlanRequest is the request body for creating an investment plan.
+// Dates are YYYY-MM-DD; an empty endDate runs the plan indefinitely.
+type CreateInvestmentPlanRequest struct {
+	PortfolioFundID string  `json:"portfolioFundId"`
+	Amount          float64 `json:"amount"`
+	Frequency       string  `json:"frequency"`
+	DayOfMonth      int     `json:"dayOfMonth"`
+	StartDate       string  `json:"startDate"`
+	EndDate         string  `json:"endDate"`
+	Enabled         *bool   `json:"enabled"` // Defaults to true
+}
+
+// UpdateInvestmentPlanRequest is the request body for replacing an investment plan. The
+// portfolio fund cannot change; an omitted enabled flag keeps its current value.
+type UpdateInvestmentPlanRequest struct {
+	Amount     float64 `json:"amount"`
+	Frequency  string  `json:"frequency"`
+	DayOfMonth int     `json:"dayOfMonth"`
+	StartDate  string  `json:"startDate"`
+	EndDate    string  `json:"endDate"`
+	Enabled    *bool   `json:"enabled"`
+}
@@ -0,0 +1,55 @@
+package request
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/model"
+)
+
+// ParseJobFilters extracts and validates job list filters from query parameters.
+// Types and statuses are comma-separated; all parameters are optional.
+//
+// Validation rules:
+//   - type: Must be valid job types (fund_price_update, ibkr_import, materialized_regen, etc.)
+//   - status: Must be valid job statuses (pending, running, succeeded, failed)
+//   - limit: Must be between 1 and 250 (defaults to 50)
+//
+// Returns an error if any parameter fails validation.
+func ParseJobFilters(typeParam, statusParam, limitParam string) (*model.JobFilters, error) {
+	filters := &model.JobFilters{Limit: 50}
+
+	if typeParam != "" {
+		for jobType := range strings.SplitSeq(typeParam, ",") {
+			jobType = strings.TrimSpace(strings.ToLower(jobType))
+			if !model.ValidJobTypes[model.JobType(jobType)] {
+				return nil, fmt.Errorf("invalid job type: %s", jobType)
+			}
+			filters.Types = append(filters.Types, jobType)
+		}
+	}
+
+	if statusParam != "" {
+		for status := range strings.SplitSeq(statusParam, ",") {
+			status = strings.TrimSpace(strings.ToLower(status))
+			if !model.ValidJobStatuses[model.JobStatus(status)] {
+				return nil, fmt.Errorf("invalid status: %s", status)
+			}
+			filters.Statuses = append(filters.Statuses, status)
+		}
+	}
+
+	if limitParam != "" {
+		limit, err := strconv.Atoi(limitParam)
+		if err != nil {
+			return nil, fmt.Errorf("invalid limit: must be a number")
+		}
+		if limit < 1 || limit > 250 {
+			return nil, fmt.Errorf("invalid limit: must be between 1 and 250")
+		}
+		filters.Limit = limit
+	}
+
+	return filters, nil
+}
@@ -0,0 +1,54 @@
+package request
+
+import (
+	"testing"
+)
+
+func TestParseJobFilters(t *testing.T) {
+	t.Run("default values when no parameters provided", func(t *testing.T) {
+		filters, err := ParseJobFilters("", "", "")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if filters.Limit != 50 {
+			t.Errorf("Expected default Limit 50, got %d", filters.Limit)
+		}
+		if len(filters.Types) != 0 || len(filters.Statuses) != 0 {
+			t.Errorf("Expected no type/status filters, got %v %v", filters.Types, filters.Statuses)
+		}
+	})
+
+	t.Run("multiple types and statuses", func(t *testing.T) {
+		filters, err := ParseJobFilters("Fund_Price_Update, materialized_regen", "failed,RUNNING", "10")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(filters.Types) != 2 || filters.Types[0] != "fund_price_update" || filters.Types[1] != "materialized_regen" {
+			t.Errorf("Unexpected types: %v", filters.Types)
+		}
+		if len(filters.Statuses) != 2 || filters.Statuses[1] != "running" {
+			t.Errorf("Unexpected statuses: %v", filters.Statuses)
+		}
+		if filters.Limit != 10 {
+			t.Errorf("Expected Limit 10, got %d", filters.Limit)
+		}
+	})
+
+	invalid := []struct {
+		name   string
+		params [3]string
+	}{
+		{"invalid type", [3]string{"reticulate_splines"}},
+		{"invalid status", [3]string{"", "done"}},
+		{"non-numeric limit", [3]string{"", "", "many"}},
+		{"limit out of range", [3]string{"", "", "251"}},
+		{"zero limit", [3]string{"", "", "0"}},
+	}
+	for _, tc := range invalid {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := ParseJobFilters(tc.params[0], tc.params[1], tc.params[2]); err == nil {
+				t.Error("Expected error, got nil")
+			}
+		})
+	}
+}
@@ -0,0 +1,7 @@
+package request
+
+// RebuildMaterializedRequest is the request body for forcing a materialized history rebuild.
+type RebuildMaterializedRequest struct {
+	PortfolioID string `json:"portfolioId"` // PortfolioID limits the rebuild to one portfolio. Empty rebuilds all portfolios.
+	StartDate   string `json:"startDate"`   // StartDate in YYYY-MM-DD format rebuilds from that date onward. Empty rebuilds the full history.
+}
@@ -0,0 +1,48 @@
+package request
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseReportYear extracts the year of a yearly tax report from a query parameter.
+// It defaults to the year before now, the year a tax return is usually filed for, and
+// must be between 1900 and now's year.
+func ParseReportYear(yearParam string, now time.Time) (int, error) {
+	if strings.TrimSpace(yearParam) == "" {
+		return now.Year() - 1, nil
+	}
+	year, err := strconv.Atoi(strings.TrimSpace(yearParam))
+	if err != nil {
+		return 0, fmt.Errorf("invalid year: must be a number")
+	}
+	if year < 1900 || year > now.Year() {
+		return 0, fmt.Errorf("invalid year: must be between 1900 and %d", now.Year())
+	}
+	return year, nil
+}
+
+// ExportFormat is the format a report is returned in.
+type ExportFormat string
+
+// Export formats.
+const (
+	ExportFormatJSON ExportFormat = "json"
+	ExportFormatCSV  ExportFormat = "csv"
+	ExportFormatPDF  ExportFormat = "pdf"
+)
+
+// ParseExportFormat extracts the format of a report from a query parameter. It defaults to
+// JSON and accepts "json", "csv" and "pdf" in any case.
+func ParseExportFormat(formatParam string) (ExportFormat, error) {
+	switch format := ExportFormat(strings.ToLower(strings.TrimSpace(formatParam))); format {
+	case "":
+		return ExportFormatJSON, nil
+	case ExportFormatJSON, ExportFormatCSV, ExportFormatPDF:
+		return format, nil
+	default:
+		return "", fmt.Errorf("invalid format: must be json, csv or pdf")
+	}
+}
@@ -0,0 +1,54 @@
+package request
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseReportYear(t *testing.T) {
+	now := time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC)
+
+	valid := map[string]int{
+		"":       2025,
+		"2024":   2024,
+		" 2026 ": 2026,
+		"1900":   1900,
+	}
+	for param, want := range valid {
+		got, err := ParseReportYear(param, now)
+		if err != nil {
+			t.Errorf("ParseReportYear(%q): unexpected error %v", param, err)
+		}
+		if got != want {
+			t.Errorf("ParseReportYear(%q) = %d, want %d", param, got, want)
+		}
+	}
+
+	for _, param := range []string{"2027", "1899", "last", "2024.5"} {
+		if _, err := ParseReportYear(param, now); err == nil {
+			t.Errorf("ParseReportYear(%q): expected error", param)
+		}
+	}
+}
+
+func TestParseExportFormat(t *testing.T) {
+	valid := map[string]ExportFormat{
+		"":      ExportFormatJSON,
+		"json":  ExportFormatJSON,
+		"CSV":   ExportFormatCSV,
+		" pdf ": ExportFormatPDF,
+	}
+	for param, want := range valid {
+		got, err := ParseExportFormat(param)
+		if err != nil {
+			t.Errorf("ParseExportFormat(%q): unexpected error %v", param, err)
+		}
+		if got != want {
+			t.Errorf("ParseExportFormat(%q) = %q, want %q", param, got, want)
+		}
+	}
+
+	if _, err := ParseExportFormat("xlsx"); err == nil {
+		t.Error(`ParseExportFormat("xlsx"): expected error`)
+	}
+}
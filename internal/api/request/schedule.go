@@ -0,0 +1,9 @@
+package request
+
+// UpdateScheduledTaskRequest is the request body for editing a scheduled task.
+// Omitted fields keep their current value.
+type UpdateScheduledTaskRequest struct {
+	Schedule *string `json:"schedule"` // Schedule is a standard five-field cron expression, e.g. "55 0 * * 1-5".
+	Timezone *string `json:"timezone"` // Timezone is the IANA time zone the schedule runs in, e.g. "Europe/Amsterdam".
+	Enabled  *bool   `json:"enabled"`  // Enabled controls whether the task runs on its schedule.
+}
@@ -0,0 +1,40 @@
+package request
+
+// SetupRequest is the request body for creating the first (administrator) account.
+type SetupRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// LoginRequest is the request body for logging in.
+type LoginRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// ChangePasswordRequest is the request body for changing the caller's own password.
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"currentPassword"`
+	NewPassword     string `json:"newPassword"`
+}
+
+// CreateUserRequest is the request body for an administrator creating an account.
+type CreateUserRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+	IsAdmin  bool   `json:"isAdmin"`
+}
+
+// SharePortfolioRequest is the request body for granting a user access to a portfolio.
+type SharePortfolioRequest struct {
+	UserID string `json:"userId"`
+	Access string `json:"access"`
+}
+
+// CreateAPITokenRequest is the request body for creating a personal API token.
+// ExpiresInDays is optional; tokens without it never expire.
+type CreateAPITokenRequest struct {
+	Name          string   `json:"name"`
+	Scopes        []string `json:"scopes"`
+	ExpiresInDays *int     `json:"expiresInDays"`
+}
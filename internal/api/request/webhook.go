@@ -0,0 +1,40 @@
+package request
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// CreateWebhookRequest is the request body for subscribing a webhook to events.
+// Secret is optional; a random secret is generated when it is empty.
+type CreateWebhookRequest struct {
+	URL        string   `json:"url"`
+	EventTypes []string `json:"eventTypes"`
+	Secret     string   `json:"secret"`
+	Enabled    *bool    `json:"enabled"` // Defaults to true
+}
+
+// UpdateWebhookRequest is the request body for changing a webhook. Omitted fields keep
+// their current value; an empty secret replaces it with a newly generated one.
+type UpdateWebhookRequest struct {
+	URL        *string  `json:"url"`
+	EventTypes []string `json:"eventTypes"`
+	Secret     *string  `json:"secret"`
+	Enabled    *bool    `json:"enabled"`
+}
+
+// ParseDeliveryLimit parses the limit query parameter of the webhook delivery log.
+// The limit must be between 1 and 250 and defaults to 50.
+func ParseDeliveryLimit(limitParam string) (int, error) {
+	if limitParam == "" {
+		return 50, nil
+	}
+	limit, err := strconv.Atoi(limitParam)
+	if err != nil {
+		return 0, fmt.Errorf("invalid limit: must be a number")
+	}
+	if limit < 1 || limit > 250 {
+		return 0, fmt.Errorf("invalid limit: must be between 1 and 250")
+	}
+	return limit, nil
+}
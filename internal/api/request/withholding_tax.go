@@ -0,0 +1,7 @@
+package request
+
+// SetWithholdingTaxRateRequest represents the request body for setting the default
+// withholding tax rate of a fund domicile.
+type SetWithholdingTaxRateRequest struct {
+	RatePercent float64 `json:"ratePercent"`
+}
@@ -4,6 +4,7 @@ package response
 
 import (
 	"encoding/json"
+	"fmt"
 	"log/slog"
 	"net/http"
 
@@ -34,6 +35,17 @@ func RespondJSON(w http.ResponseWriter, status int, data any) {
 	}
 }
 
+// RespondFile sends body as a 200 OK download named filename with the given Content-Type.
+// Logs write errors but does not fail the response.
+func RespondFile(w http.ResponseWriter, contentType, filename string, body []byte) {
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(body); err != nil {
+		slog.Error("failed to write file response", "error", err)
+	}
+}
+
 // RespondError sends a structured error response with the given status code.
 // Use for 4xx client errors where details are safe to expose (validation, not-found, etc.).
 //
@@ -56,6 +56,25 @@ func TestRespondJSON_StatusCreated(t *testing.T) {
 	}
 }
 
+func TestRespondFile(t *testing.T) {
+	w := httptest.NewRecorder()
+
+	RespondFile(w, "text/csv", "report-2025.csv", []byte("a,b\n"))
+
+	if w.Code != http.StatusOK {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "text/csv" {
+		t.Errorf("Content-Type = %q, want %q", ct, "text/csv")
+	}
+	if cd := w.Header().Get("Content-Disposition"); cd != `attachment; filename="report-2025.csv"` {
+		t.Errorf("Content-Disposition = %q", cd)
+	}
+	if w.Body.String() != "a,b\n" {
+		t.Errorf("body = %q, want %q", w.Body.String(), "a,b\n")
+	}
+}
+
 func TestRespondError(t *testing.T) {
 	w := httptest.NewRecorder()
 
@@ -11,6 +11,8 @@ import (
 	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/api/handlers"
 	custommiddleware "github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/api/middleware"
 	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/config"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/metrics"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/model"
 	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/service"
 )
 
@@ -25,7 +27,21 @@ func NewRouter(
 	dividendService *service.DividendService,
 	transactionService *service.TransactionService,
 	ibkrService *service.IbkrService,
+	inboxService *service.InboxService,
+	auditService *service.AuditService,
+	trashService *service.TrashService,
 	developerService *service.DeveloperService,
+	authService *service.AuthService,
+	jobService *service.JobService,
+	schedulerService *service.SchedulerService,
+	webhookService *service.WebhookService,
+	digestService *service.DigestService,
+	alertService *service.AlertService,
+	allocationService *service.AllocationService,
+	planService *service.InvestmentPlanService,
+	incomeService *service.DividendIncomeService,
+	withholdingService *service.WithholdingTaxService,
+	taxReportService *service.TaxReportService,
 	cfg *config.Config,
 ) http.Handler {
 	r := chi.NewRouter()
@@ -33,13 +49,18 @@ func NewRouter(
 	// Global middleware
 	r.Use(middleware.RequestID)
 	r.Use(middleware.RealIP)
+	r.Use(custommiddleware.Tracing)
 	r.Use(custommiddleware.Logger)
+	r.Use(custommiddleware.Metrics)
 	r.Use(middleware.Recoverer)
 
 	// CORS middleware
 	corsMiddleware := custommiddleware.NewCORS(cfg.CORS.AllowedOrigins)
 	r.Use(corsMiddleware.Handler)
 
+	// Prometheus scrape endpoint
+	r.Handle("/metrics", metrics.Handler())
+
 	// API routes
 	r.Route("/api", func(r chi.Router) {
 		// System namespace
@@ -47,149 +68,403 @@ func NewRouter(
 			systemHandler := handlers.NewSystemHandler(systemService)
 			r.Get("/health", systemHandler.Health)
 			r.Get("/version", systemHandler.Version)
+
+			r.Group(func(r chi.Router) {
+				r.Use(custommiddleware.Authenticate(authService))
+				r.Use(custommiddleware.RequireAdmin)
+				r.Use(custommiddleware.RequireScope(model.ScopeDeveloper))
+				r.Get("/config", systemHandler.GetConfig)
+			})
 		})
 
-		r.Route("/portfolio", func(r chi.Router) {
-			portfolioHandler := handlers.NewPortfolioHandler(portfolioService, fundService, materializedService)
-			r.Get("/", portfolioHandler.Portfolios)
-			r.Get("/summary", portfolioHandler.PortfolioSummary)
-			r.Get("/history", portfolioHandler.PortfolioHistory)
-			r.Get("/funds", portfolioHandler.PortfolioFunds)
-			r.Post("/", portfolioHandler.CreatePortfolio)
-			r.Route("/fund/{uuid:[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}}", func(r chi.Router) {
-				r.Use(custommiddleware.ValidateUUIDMiddleware)
-				r.Delete("/", portfolioHandler.DeletePortfolioFund)
-			})
-			r.Route("/funds/{uuid:[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}}", func(r chi.Router) {
-				r.Use(custommiddleware.ValidateUUIDMiddleware)
-				r.Get("/", portfolioHandler.GetPortfolioFunds)
-			})
-			r.Post("/funds", portfolioHandler.CreatePortfolioFund)
-
-			r.Route("/{uuid:[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}}", func(r chi.Router) {
-				r.Use(custommiddleware.ValidateUUIDMiddleware)
-				r.Get("/", portfolioHandler.GetPortfolio)
-				r.Put("/", portfolioHandler.UpdatePortfolio)
-				r.Delete("/", portfolioHandler.DeletePortfolio)
-				r.Post("/archive", portfolioHandler.ArchivePortfolio)
-				r.Post("/unarchive", portfolioHandler.UnarchivePortfolio)
+		authHandler := handlers.NewAuthHandler(authService, portfolioService)
+		fundHandler := handlers.NewFundHandler(fundService, materializedService, jobService)
+
+		// Public authentication endpoints
+		r.Route("/auth", func(r chi.Router) {
+			r.Get("/status", authHandler.Status)
+			r.Post("/setup", authHandler.Setup)
+			r.Post("/login", authHandler.Login)
+
+			r.Group(func(r chi.Router) {
+				r.Use(custommiddleware.Authenticate(authService))
+				r.Get("/me", authHandler.Me)
+
+				// Account and token management needs a login session, not an API token.
+				r.Group(func(r chi.Router) {
+					r.Use(custommiddleware.RequireSession)
+					r.Post("/logout", authHandler.Logout)
+					r.Put("/password", authHandler.ChangePassword)
+					r.Get("/tokens", authHandler.GetAPITokens)
+					r.Post("/tokens", authHandler.CreateAPIToken)
+
+					r.Route("/tokens/{uuid:[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}}", func(r chi.Router) {
+						r.Use(custommiddleware.ValidateUUIDMiddleware)
+						r.Delete("/", authHandler.RevokeAPIToken)
+					})
+				})
 			})
 		})
 
-		r.Route("/fund", func(r chi.Router) {
-			fundHandler := handlers.NewFundHandler(fundService, materializedService)
-			r.Get("/", fundHandler.GetAllFunds)
-			r.Post("/", fundHandler.CreateFund)
-			r.Get("/symbol/{symbol}", fundHandler.GetSymbol)
+		// Scheduled price updates authenticate with the internal API key or an API token
+		// with the write:fund scope rather than a session.
+		r.Route("/fund/update-all-prices", func(r chi.Router) {
+			r.Use(custommiddleware.BearerOrAPIKey(authService, cfg.InternalAPIKey, model.ScopeWriteFund))
+			r.Post("/", fundHandler.UpdateAllFundHistory)
+		})
+
+		// Everything below requires a session once the first account exists.
+		r.Group(func(r chi.Router) {
+			r.Use(custommiddleware.Authenticate(authService))
 
-			r.Route("/fund-prices/{uuid:[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}}", func(r chi.Router) {
-				r.Use(custommiddleware.ValidateUUIDMiddleware)
-				r.Get("/", fundHandler.GetFundPrices)
-				r.Post("/update", fundHandler.UpdateFundPrice)
+			r.Route("/user", func(r chi.Router) {
+				r.Use(custommiddleware.RequireAdmin)
+				r.Use(custommiddleware.RequireScope(model.ScopeAdminUser))
+				r.Get("/", authHandler.GetUsers)
+				r.Post("/", authHandler.CreateUser)
+
+				r.Route("/{uuid:[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}}", func(r chi.Router) {
+					r.Use(custommiddleware.ValidateUUIDMiddleware)
+					r.Delete("/", authHandler.DeleteUser)
+				})
 			})
 
-			r.Route("/history/{uuid:[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}}", func(r chi.Router) {
-				r.Use(custommiddleware.ValidateUUIDMiddleware)
-				r.Get("/", fundHandler.GetFundHistory)
+			r.Route("/portfolio", func(r chi.Router) {
+				r.Use(custommiddleware.RequireMethodScope(model.ScopeReadPortfolio, model.ScopeWritePortfolio))
+				portfolioHandler := handlers.NewPortfolioHandler(portfolioService, fundService, materializedService)
+				allocationHandler := handlers.NewAllocationHandler(allocationService)
+				r.Get("/", portfolioHandler.Portfolios)
+				r.Get("/summary", portfolioHandler.PortfolioSummary)
+				r.Get("/history", portfolioHandler.PortfolioHistory)
+				r.Get("/funds", portfolioHandler.PortfolioFunds)
+				r.Post("/", portfolioHandler.CreatePortfolio)
+				r.Route("/fund/{uuid:[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}}", func(r chi.Router) {
+					r.Use(custommiddleware.ValidateUUIDMiddleware)
+					r.Use(custommiddleware.RequirePortfolioAccess(authService.PortfolioIDForPortfolioFund))
+					r.Delete("/", portfolioHandler.DeletePortfolioFund)
+				})
+				r.Route("/funds/{uuid:[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}}", func(r chi.Router) {
+					r.Use(custommiddleware.ValidateUUIDMiddleware)
+					r.Use(custommiddleware.RequirePortfolioAccess(custommiddleware.PortfolioIDParam))
+					r.Get("/", portfolioHandler.GetPortfolioFunds)
+				})
+				r.Post("/funds", portfolioHandler.CreatePortfolioFund)
+
+				r.Route("/{uuid:[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}}", func(r chi.Router) {
+					r.Use(custommiddleware.ValidateUUIDMiddleware)
+					r.Use(custommiddleware.RequirePortfolioAccess(custommiddleware.PortfolioIDParam))
+					r.Get("/", portfolioHandler.GetPortfolio)
+					r.Put("/", portfolioHandler.UpdatePortfolio)
+					r.Post("/archive", portfolioHandler.ArchivePortfolio)
+					r.Post("/unarchive", portfolioHandler.UnarchivePortfolio)
+					r.Get("/allocation", allocationHandler.GetAllocationTargets)
+					r.Put("/allocation", allocationHandler.SetAllocationTargets)
+					r.Get("/rebalance", allocationHandler.GetRebalancePlan)
+
+					r.Group(func(r chi.Router) {
+						r.Use(custommiddleware.RequirePortfolioOwner(custommiddleware.PortfolioIDParam))
+						r.Delete("/", portfolioHandler.DeletePortfolio)
+						r.Get("/shares", authHandler.GetPortfolioShares)
+						r.Put("/shares", authHandler.SharePortfolio)
+						r.Delete("/shares/{userId}", authHandler.UnsharePortfolio)
+					})
+				})
 			})
 
-			r.Route("/{uuid:[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}}", func(r chi.Router) {
-				r.Use(custommiddleware.ValidateUUIDMiddleware)
-				r.Get("/", fundHandler.GetFund)
-				r.Put("/", fundHandler.UpdateFund)
-				r.Get("/check-usage", fundHandler.CheckUsage)
-				r.Delete("/", fundHandler.DeleteFund)
+			r.Route("/fund", func(r chi.Router) {
+				r.Use(custommiddleware.RequireMethodScope(model.ScopeReadFund, model.ScopeWriteFund))
+				r.Get("/", fundHandler.GetAllFunds)
+				r.Post("/", fundHandler.CreateFund)
+				r.Get("/symbol/{symbol}", fundHandler.GetSymbol)
+
+				r.Route("/fund-prices/{uuid:[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}}", func(r chi.Router) {
+					r.Use(custommiddleware.ValidateUUIDMiddleware)
+					r.Get("/", fundHandler.GetFundPrices)
+					r.Post("/update", fundHandler.UpdateFundPrice)
+				})
+
+				r.Route("/history/{uuid:[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}}", func(r chi.Router) {
+					r.Use(custommiddleware.ValidateUUIDMiddleware)
+					r.Use(custommiddleware.RequireScope(model.ScopeReadPortfolio))
+					r.Use(custommiddleware.RequirePortfolioAccess(custommiddleware.PortfolioIDParam))
+					r.Get("/", fundHandler.GetFundHistory)
+				})
+
+				r.Route("/{uuid:[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}}", func(r chi.Router) {
+					r.Use(custommiddleware.ValidateUUIDMiddleware)
+					r.Get("/", fundHandler.GetFund)
+					r.Put("/", fundHandler.UpdateFund)
+					r.Get("/check-usage", fundHandler.CheckUsage)
+					r.Delete("/", fundHandler.DeleteFund)
+				})
 			})
 
-			r.Route("/update-all-prices", func(r chi.Router) {
-				r.Use(custommiddleware.APIKeyMiddleware(cfg.InternalAPIKey))
-				r.Post("/", fundHandler.UpdateAllFundHistory)
+			r.Route("/dividend", func(r chi.Router) {
+				r.Use(custommiddleware.RequireMethodScope(model.ScopeReadDividend, model.ScopeWriteDividend))
+				dividendHandler := handlers.NewDividendHandler(dividendService)
+				incomeHandler := handlers.NewDividendIncomeHandler(incomeService)
+				withholdingHandler := handlers.NewWithholdingTaxHandler(withholdingService)
+				r.Get("/", dividendHandler.GetAllDividend)
+				r.Post("/", dividendHandler.CreateDividend)
+
+				r.Route("/withholding", func(r chi.Router) {
+					r.Get("/", withholdingHandler.GetWithholdingTaxReport)
+					r.Get("/rates", withholdingHandler.GetWithholdingTaxRates)
+
+					r.Group(func(r chi.Router) {
+						r.Use(custommiddleware.RequireAdmin)
+						r.Put("/rates/{country:[A-Za-z]{2}}", withholdingHandler.SetWithholdingTaxRate)
+						r.Delete("/rates/{country:[A-Za-z]{2}}", withholdingHandler.DeleteWithholdingTaxRate)
+					})
+				})
+
+				r.Route("/{uuid:[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}}", func(r chi.Router) {
+					r.Use(custommiddleware.ValidateUUIDMiddleware)
+					r.Use(custommiddleware.RequirePortfolioAccess(authService.PortfolioIDForDividend))
+					r.Get("/", dividendHandler.GetDividend)
+					r.Put("/", dividendHandler.UpdateDividend)
+					r.Delete("/", dividendHandler.DeleteDividend)
+				})
+
+				r.Route("/portfolio/{uuid:[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}}", func(r chi.Router) {
+					r.Use(custommiddleware.ValidateUUIDMiddleware)
+					r.Use(custommiddleware.RequirePortfolioAccess(custommiddleware.PortfolioIDParam))
+					r.Get("/", dividendHandler.DividendPerPortfolio)
+					r.Get("/income", incomeHandler.GetDividendIncome)
+					r.Get("/withholding", withholdingHandler.GetPortfolioWithholdingTaxReport)
+				})
+
+				r.Route("/fund/{uuid:[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}}", func(r chi.Router) {
+					r.Use(custommiddleware.ValidateUUIDMiddleware)
+					r.Get("/", dividendHandler.DividendPerFund)
+				})
 			})
-		})
 
-		r.Route("/dividend", func(r chi.Router) {
-			dividendHandler := handlers.NewDividendHandler(dividendService)
-			r.Get("/", dividendHandler.GetAllDividend)
-			r.Post("/", dividendHandler.CreateDividend)
+			r.Route("/transaction", func(r chi.Router) {
+				r.Use(custommiddleware.RequireMethodScope(model.ScopeReadTransaction, model.ScopeWriteTransaction))
+				transactionHandler := handlers.NewTransactionHandler(transactionService)
+				r.Get("/", transactionHandler.AllTransactions)
+				r.Post("/", transactionHandler.CreateTransaction)
+
+				r.Route("/portfolio/{uuid:[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}}", func(r chi.Router) {
+					r.Use(custommiddleware.ValidateUUIDMiddleware)
+					r.Use(custommiddleware.RequirePortfolioAccess(custommiddleware.PortfolioIDParam))
+					r.Get("/", transactionHandler.TransactionPerPortfolio)
+				})
+
+				r.Route("/{uuid:[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}}", func(r chi.Router) {
+					r.Use(custommiddleware.ValidateUUIDMiddleware)
+					r.Use(custommiddleware.RequirePortfolioAccess(authService.PortfolioIDForTransaction))
+					r.Get("/", transactionHandler.GetTransaction)
+					r.Put("/", transactionHandler.UpdateTransaction)
+					r.Delete("/", transactionHandler.DeleteTransaction)
+				})
+			})
+
+			r.Route("/investment-plan", func(r chi.Router) {
+				r.Use(custommiddleware.RequireMethodScope(model.ScopeReadTransaction, model.ScopeWriteTransaction))
+				planHandler := handlers.NewInvestmentPlanHandler(planService)
+				r.Get("/", planHandler.AllInvestmentPlans)
+				r.Post("/", planHandler.CreateInvestmentPlan)
+
+				r.Route("/portfolio/{uuid:[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}}", func(r chi.Router) {
+					r.Use(custommiddleware.ValidateUUIDMiddleware)
+					r.Use(custommiddleware.RequirePortfolioAccess(custommiddleware.PortfolioIDParam))
+					r.Get("/", planHandler.InvestmentPlansPerPortfolio)
+				})
 
-			r.Route("/{uuid:[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}}", func(r chi.Router) {
-				r.Use(custommiddleware.ValidateUUIDMiddleware)
-				r.Get("/", dividendHandler.GetDividend)
-				r.Put("/", dividendHandler.UpdateDividend)
-				r.Delete("/", dividendHandler.DeleteDividend)
+				r.Route("/{uuid:[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}}", func(r chi.Router) {
+					r.Use(custommiddleware.ValidateUUIDMiddleware)
+					r.Use(custommiddleware.RequirePortfolioAccess(authService.PortfolioIDForInvestmentPlan))
+					r.Get("/", planHandler.GetInvestmentPlan)
+					r.Put("/", planHandler.UpdateInvestmentPlan)
+					r.Delete("/", planHandler.DeleteInvestmentPlan)
+				})
 			})
 
-			r.Route("/portfolio/{uuid:[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}}", func(r chi.Router) {
-				r.Use(custommiddleware.ValidateUUIDMiddleware)
-				r.Get("/", dividendHandler.DividendPerPortfolio)
+			r.Route("/report", func(r chi.Router) {
+				r.Use(custommiddleware.RequireScope(model.ScopeReadPortfolio))
+				taxReportHandler := handlers.NewTaxReportHandler(taxReportService)
+				r.Get("/box3", taxReportHandler.GetBox3Report)
+				r.Get("/capital-gains", taxReportHandler.GetCapitalGainsReport)
 			})
 
-			r.Route("/fund/{uuid:[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}}", func(r chi.Router) {
-				r.Use(custommiddleware.ValidateUUIDMiddleware)
-				r.Get("/", dividendHandler.DividendPerFund)
+			r.Route("/ibkr", func(r chi.Router) {
+				r.Use(custommiddleware.RequireAdmin)
+				r.Use(custommiddleware.RequireScope(model.ScopeAdminIbkr))
+				ibkrHandler := handlers.NewIbkrHandler(ibkrService)
+				r.Get("/config", ibkrHandler.GetConfig)
+				r.Post("/config", ibkrHandler.UpdateIbkrConfig)
+				r.Post("/config/test", ibkrHandler.TestIbkrConnection)
+				r.Post("/config/rotate-key", ibkrHandler.RotateEncryptionKey)
+				r.Delete("/config", ibkrHandler.DeleteIbkrConfig)
+				r.Get("/portfolios", ibkrHandler.GetActivePortfolios)
+				r.Get("/dividend/pending", ibkrHandler.GetPendingDividends)
+				r.Get("/inbox", ibkrHandler.GetInbox)
+				r.Get("/inbox/count", ibkrHandler.GetInboxCount)
+				r.Post("/import", ibkrHandler.ImportFlexReport)
+				r.Post("/inbox/bulk-allocate", ibkrHandler.BulkAllocate)
+
+				r.Route("/inbox/{uuid:[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}}", func(r chi.Router) {
+					r.Use(custommiddleware.ValidateUUIDMiddleware)
+					r.Get("/", ibkrHandler.GetTransaction)
+					r.Delete("/", ibkrHandler.DeleteTransaction)
+					r.Get("/allocations", ibkrHandler.GetTransactionAllocations)
+					r.Put("/allocations", ibkrHandler.ModifyAllocations)
+					r.Get("/eligible-portfolios", ibkrHandler.GetEligiblePortfolios)
+					r.Post("/ignore", ibkrHandler.IgnoreTransaction)
+					r.Post("/allocate", ibkrHandler.AllocateTransaction)
+					r.Post("/unallocate", ibkrHandler.UnallocateTransaction)
+					r.Post("/match-dividend", ibkrHandler.MatchDividend)
+				})
 			})
-		})
 
-		r.Route("/transaction", func(r chi.Router) {
-			transactionHandler := handlers.NewTransactionHandler(transactionService)
-			r.Get("/", transactionHandler.AllTransactions)
-			r.Post("/", transactionHandler.CreateTransaction)
+			r.Route("/inbox", func(r chi.Router) {
+				r.Use(custommiddleware.RequireAdmin)
+				r.Use(custommiddleware.RequireScope(model.ScopeAdminIbkr))
+				inboxHandler := handlers.NewInboxHandler(inboxService)
+				// Per-transaction actions are source-agnostic and shared with the IBKR namespace.
+				ibkrHandler := handlers.NewIbkrHandler(ibkrService)
+				r.Get("/", inboxHandler.GetInbox)
+				r.Post("/", inboxHandler.StageTransactions)
+				r.Get("/count", inboxHandler.GetInboxCount)
+				r.Post("/bulk-allocate", ibkrHandler.BulkAllocate)
 
-			r.Route("/portfolio/{uuid:[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}}", func(r chi.Router) {
-				r.Use(custommiddleware.ValidateUUIDMiddleware)
-				r.Get("/", transactionHandler.TransactionPerPortfolio)
+				r.Route("/{uuid:[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}}", func(r chi.Router) {
+					r.Use(custommiddleware.ValidateUUIDMiddleware)
+					r.Get("/", ibkrHandler.GetTransaction)
+					r.Delete("/", ibkrHandler.DeleteTransaction)
+					r.Get("/allocations", ibkrHandler.GetTransactionAllocations)
+					r.Put("/allocations", ibkrHandler.ModifyAllocations)
+					r.Get("/eligible-portfolios", ibkrHandler.GetEligiblePortfolios)
+					r.Post("/ignore", ibkrHandler.IgnoreTransaction)
+					r.Post("/allocate", ibkrHandler.AllocateTransaction)
+					r.Post("/unallocate", ibkrHandler.UnallocateTransaction)
+					r.Post("/match-dividend", ibkrHandler.MatchDividend)
+				})
 			})
 
-			r.Route("/{uuid:[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}}", func(r chi.Router) {
-				r.Use(custommiddleware.ValidateUUIDMiddleware)
-				r.Get("/", transactionHandler.GetTransaction)
-				r.Put("/", transactionHandler.UpdateTransaction)
-				r.Delete("/", transactionHandler.DeleteTransaction)
+			r.Route("/audit", func(r chi.Router) {
+				r.Use(custommiddleware.RequireAdmin)
+				r.Use(custommiddleware.RequireScope(model.ScopeAdminAudit))
+				auditHandler := handlers.NewAuditHandler(auditService)
+				r.Get("/", auditHandler.GetAuditEvents)
 			})
-		})
 
-		r.Route("/ibkr", func(r chi.Router) {
-			ibkrHandler := handlers.NewIbkrHandler(ibkrService)
-			r.Get("/config", ibkrHandler.GetConfig)
-			r.Post("/config", ibkrHandler.UpdateIbkrConfig)
-			r.Post("/config/test", ibkrHandler.TestIbkrConnection)
-			r.Delete("/config", ibkrHandler.DeleteIbkrConfig)
-			r.Get("/portfolios", ibkrHandler.GetActivePortfolios)
-			r.Get("/dividend/pending", ibkrHandler.GetPendingDividends)
-			r.Get("/inbox", ibkrHandler.GetInbox)
-			r.Get("/inbox/count", ibkrHandler.GetInboxCount)
-			r.Post("/import", ibkrHandler.ImportFlexReport)
-			r.Post("/inbox/bulk-allocate", ibkrHandler.BulkAllocate)
-
-			r.Route("/inbox/{uuid:[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}}", func(r chi.Router) {
-				r.Use(custommiddleware.ValidateUUIDMiddleware)
-				r.Get("/", ibkrHandler.GetTransaction)
-				r.Delete("/", ibkrHandler.DeleteTransaction)
-				r.Get("/allocations", ibkrHandler.GetTransactionAllocations)
-				r.Put("/allocations", ibkrHandler.ModifyAllocations)
-				r.Get("/eligible-portfolios", ibkrHandler.GetEligiblePortfolios)
-				r.Post("/ignore", ibkrHandler.IgnoreTransaction)
-				r.Post("/allocate", ibkrHandler.AllocateTransaction)
-				r.Post("/unallocate", ibkrHandler.UnallocateTransaction)
-				r.Post("/match-dividend", ibkrHandler.MatchDividend)
+			r.Route("/trash", func(r chi.Router) {
+				r.Use(custommiddleware.RequireAdmin)
+				r.Use(custommiddleware.RequireScope(model.ScopeAdminTrash))
+				trashHandler := handlers.NewTrashHandler(trashService)
+				r.Get("/", trashHandler.GetTrash)
+
+				r.Route("/{uuid:[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}}", func(r chi.Router) {
+					r.Use(custommiddleware.ValidateUUIDMiddleware)
+					r.Delete("/", trashHandler.PurgeTrashItem)
+					r.Post("/restore", trashHandler.RestoreTrashItem)
+				})
+			})
+
+			r.Route("/jobs", func(r chi.Router) {
+				r.Use(custommiddleware.RequireAdmin)
+				r.Use(custommiddleware.RequireScope(model.ScopeAdminJob))
+				jobHandler := handlers.NewJobHandler(jobService)
+				r.Get("/", jobHandler.GetJobs)
+
+				r.Route("/schedules", func(r chi.Router) {
+					scheduleHandler := handlers.NewScheduleHandler(schedulerService)
+					r.Get("/", scheduleHandler.GetScheduledTasks)
+					r.Get("/{name}", scheduleHandler.GetScheduledTask)
+					r.Put("/{name}", scheduleHandler.UpdateScheduledTask)
+					r.Post("/{name}/run", scheduleHandler.RunScheduledTask)
+				})
+
+				r.Route("/{uuid:[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}}", func(r chi.Router) {
+					r.Use(custommiddleware.ValidateUUIDMiddleware)
+					r.Get("/", jobHandler.GetJob)
+					r.Post("/retry", jobHandler.RetryJob)
+				})
+			})
+
+			r.Route("/materialized", func(r chi.Router) {
+				r.Use(custommiddleware.RequireAdmin)
+				r.Use(custommiddleware.RequireScope(model.ScopeAdminMaterialized))
+				materializedHandler := handlers.NewMaterializedHandler(materializedService)
+				r.Get("/queue", materializedHandler.GetRegenQueue)
+				r.Get("/coverage", materializedHandler.GetCoverage)
+				r.Post("/rebuild", materializedHandler.Rebuild)
+				r.Get("/verify", materializedHandler.Verify)
+			})
+
+			r.Route("/webhooks", func(r chi.Router) {
+				r.Use(custommiddleware.RequireAdmin)
+				r.Use(custommiddleware.RequireScope(model.ScopeAdminWebhook))
+				webhookHandler := handlers.NewWebhookHandler(webhookService)
+				r.Get("/", webhookHandler.GetWebhooks)
+				r.Post("/", webhookHandler.CreateWebhook)
+
+				r.Route("/{uuid:[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}}", func(r chi.Router) {
+					r.Use(custommiddleware.ValidateUUIDMiddleware)
+					r.Get("/", webhookHandler.GetWebhook)
+					r.Put("/", webhookHandler.UpdateWebhook)
+					r.Delete("/", webhookHandler.DeleteWebhook)
+					r.Get("/deliveries", webhookHandler.GetDeliveries)
+					r.Post("/test", webhookHandler.TestWebhook)
+				})
+			})
+
+			r.Route("/digest", func(r chi.Router) {
+				r.Use(custommiddleware.RequireAdmin)
+				r.Use(custommiddleware.RequireScope(model.ScopeAdminDigest))
+				digestHandler := handlers.NewDigestHandler(digestService)
+				r.Get("/settings", digestHandler.GetSettings)
+				r.Put("/settings", digestHandler.UpdateSettings)
+				r.Get("/preview", digestHandler.Preview)
+				r.Post("/send", digestHandler.Send)
 			})
-		})
 
-		r.Route("/developer", func(r chi.Router) {
-			developerHandler := handlers.NewDeveloperHandler(developerService)
-			r.Get("/logs/filter-options", developerHandler.GetLogFilterOptions)
-			r.Get("/logs", developerHandler.GetLogs)
-			r.Delete("/logs", developerHandler.DeleteLogs)
-			r.Get("/system-settings/logging", developerHandler.GetLoggingConfig)
-			r.Put("/system-settings/logging", developerHandler.SetLoggingConfig)
-			r.Get("/csv/fund-prices/template", developerHandler.GetFundPriceCSVTemplate)
-			r.Get("/csv/transactions/template", developerHandler.GetTransactionCSVTemplate)
-			r.Get("/exchange-rate", developerHandler.GetExchangeRate)
-			r.Post("/exchange-rate", developerHandler.UpdateExchangeRate)
-			r.Get("/fund-price", developerHandler.GetFundPrice)
-			r.Post("/fund-price", developerHandler.UpdateFundPrice)
-			r.Post("/import-fund-prices", developerHandler.ImportFundPrices)
-			r.Post("/import-transactions", developerHandler.ImportTransactions)
+			r.Route("/alerts", func(r chi.Router) {
+				r.Use(custommiddleware.RequireAdmin)
+				r.Use(custommiddleware.RequireScope(model.ScopeAdminAlert))
+				alertHandler := handlers.NewAlertHandler(alertService)
+				r.Get("/", alertHandler.GetAlerts)
+				r.Post("/evaluate", alertHandler.EvaluateAlerts)
+
+				r.Route("/{uuid:[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}}", func(r chi.Router) {
+					r.Use(custommiddleware.ValidateUUIDMiddleware)
+					r.Post("/acknowledge", alertHandler.AcknowledgeAlert)
+				})
+
+				r.Route("/rules", func(r chi.Router) {
+					r.Get("/", alertHandler.GetAlertRules)
+					r.Post("/", alertHandler.CreateAlertRule)
+
+					r.Route("/{uuid:[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}}", func(r chi.Router) {
+						r.Use(custommiddleware.ValidateUUIDMiddleware)
+						r.Get("/", alertHandler.GetAlertRule)
+						r.Put("/", alertHandler.UpdateAlertRule)
+						r.Delete("/", alertHandler.DeleteAlertRule)
+					})
+				})
+			})
+
+			r.Route("/developer", func(r chi.Router) {
+				r.Use(custommiddleware.RequireAdmin)
+				r.Use(custommiddleware.RequireScope(model.ScopeDeveloper))
+				developerHandler := handlers.NewDeveloperHandler(developerService)
+				r.Get("/logs/filter-options", developerHandler.GetLogFilterOptions)
+				r.Get("/logs", developerHandler.GetLogs)
+				r.Delete("/logs", developerHandler.DeleteLogs)
+				r.Get("/system-settings/logging", developerHandler.GetLoggingConfig)
+				r.Put("/system-settings/logging", developerHandler.SetLoggingConfig)
+				r.Get("/csv/fund-prices/template", developerHandler.GetFundPriceCSVTemplate)
+				r.Get("/csv/transactions/template", developerHandler.GetTransactionCSVTemplate)
+				r.Get("/exchange-rate", developerHandler.GetExchangeRate)
+				r.Post("/exchange-rate", developerHandler.UpdateExchangeRate)
+				r.Get("/fund-price", developerHandler.GetFundPrice)
+				r.Post("/fund-price", developerHandler.UpdateFundPrice)
+				r.Post("/import-fund-prices", developerHandler.ImportFundPrices)
+				r.Post("/import-transactions", developerHandler.ImportTransactions)
+			})
 		})
 	})
 
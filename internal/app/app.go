@@ -0,0 +1,277 @@
+// Package app wires the repositories and services shared by the server and the ipmctl
+// admin tool, so both entrypoints run against the same dependency graph.
+package app
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/fernet/fernet-go"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/config"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/email"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/ibkr"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/repository"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/service"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/yahoo"
+)
+
+// Services holds every service an entrypoint may need.
+type Services struct {
+	System         *service.SystemService
+	Portfolio      *service.PortfolioService
+	Fund           *service.FundService
+	Materialized   *service.MaterializedService
+	Dividend       *service.DividendService
+	Transaction    *service.TransactionService
+	Ibkr           *service.IbkrService
+	Inbox          *service.InboxService
+	Audit          *service.AuditService
+	Trash          *service.TrashService
+	Developer      *service.DeveloperService
+	Auth           *service.AuthService
+	Job            *service.JobService
+	Scheduler      *service.SchedulerService
+	Webhook        *service.WebhookService
+	Digest         *service.DigestService
+	Alert          *service.AlertService
+	Allocation     *service.AllocationService
+	InvestmentPlan *service.InvestmentPlanService
+	DividendIncome *service.DividendIncomeService
+	WithholdingTax *service.WithholdingTaxService
+	TaxReport      *service.TaxReportService
+}
+
+// NewServices creates all repositories and services against db and wires the
+// materialized invalidators, webhook event publishers, the email sender and background
+// job handlers between them. fernetKeys are the IBKR encryption keys, newest first.
+//
+//nolint:funlen // Wiring function that creates all repos and services; splitting would obscure the dependency graph.
+func NewServices(db *sql.DB, fernetKeys []*fernet.Key, cfg *config.Config) *Services {
+	// Create repositories
+	portfolioRepo := repository.NewPortfolioRepository(db)
+	transactionRepo := repository.NewTransactionRepository(db)
+	fundRepo := repository.NewFundRepository(db)
+	pfRepo := repository.NewPortfolioFundRepository(db)
+	dividendRepo := repository.NewDividendRepository(db)
+	realizedGainLossRepo := repository.NewRealizedGainLossRepository(db)
+	materializedRepo := repository.NewMaterializedRepository(db)
+	ibkrRepo := repository.NewIbkrRepository(db)
+	developerRepo := repository.NewDeveloperRepository(db)
+	auditRepo := repository.NewAuditRepository(db)
+	trashRepo := repository.NewTrashRepository(db)
+	userRepo := repository.NewUserRepository(db)
+	apiTokenRepo := repository.NewAPITokenRepository(db)
+	jobRepo := repository.NewJobRepository(db)
+	webhookRepo := repository.NewWebhookRepository(db)
+	alertRepo := repository.NewAlertRepository(db)
+	allocationRepo := repository.NewAllocationRepository(db)
+	planRepo := repository.NewInvestmentPlanRepository(db)
+	withholdingRepo := repository.NewWithholdingTaxRepository(db)
+
+	// Create services
+	systemService := service.NewSystemService(db, service.SystemWithConfig(cfg))
+	jobService := service.NewJobService(jobRepo)
+
+	// Create web clients
+	yahooClient := yahoo.NewFinanceClientWithConfig(cfg.Providers.Yahoo)
+	ibkrClient := ibkr.NewFinanceClientWithConfig(cfg.Providers.IBKR)
+
+	developerService := service.NewDeveloperService(
+		db,
+		developerRepo,
+		fundRepo,
+		transactionRepo,
+		pfRepo,
+	)
+
+	realizedGainLossService := service.NewRealizedGainLossService(
+		realizedGainLossRepo,
+	)
+	transactionService := service.NewTransactionService(
+		db,
+		transactionRepo,
+		pfRepo,
+		realizedGainLossRepo,
+		ibkrRepo,
+	)
+	dividendService := service.NewDividendService(
+		db,
+		dividendRepo,
+		pfRepo,
+		transactionRepo,
+	)
+	portfolioService := service.NewPortfolioService(
+		db,
+		portfolioRepo,
+		pfRepo,
+	)
+	dataloaderService := service.NewDataLoaderService(
+		service.DataLoaderWithPortfolioFundRepository(pfRepo),
+		service.DataLoaderWithFundRepository(fundRepo),
+		service.DataLoaderWithTransactionService(transactionService),
+		service.DataLoaderWithDividendService(dividendService),
+		service.DataLoaderWithRealizedGainLossService(realizedGainLossService),
+	)
+	fundService := service.NewFundService(
+		db,
+		service.FundWithFundRepo(fundRepo),
+		service.FundWithPortfolioFundRepo(pfRepo),
+		service.FundWithTransactionService(transactionService),
+		service.FundWithDividendService(dividendService),
+		service.FundWithRealizedGainLossService(realizedGainLossService),
+		service.FundWithDataLoaderService(dataloaderService),
+		service.FundWithPortfolioRepo(portfolioRepo),
+		service.FundWithYahooClient(yahooClient),
+	)
+	ibkrService := service.NewIbkrService(
+		db,
+		service.IbkrWithIbkrRepo(ibkrRepo),
+		service.IbkrWithPortfolioRepo(portfolioRepo),
+		service.IbkrWithFundRepo(fundRepo),
+		service.IbkrWithDeveloperRepo(developerRepo),
+		service.IbkrWithClient(ibkrClient),
+		service.IbkrWithPortfolioFundRepo(pfRepo),
+		service.IbkrWithTransactionRepo(transactionRepo),
+		service.IbkrWithDividendRepo(dividendRepo),
+		service.IbkrWithWithholdingTaxRepo(withholdingRepo),
+		service.IbkrWithEncryptionKeys(fernetKeys),
+	)
+	inboxService := service.NewInboxService(
+		db,
+		ibkrRepo,
+	)
+	auditService := service.NewAuditService(auditRepo)
+	trashService := service.NewTrashService(
+		db,
+		trashRepo,
+		ibkrRepo,
+		time.Duration(cfg.Trash.RetentionDays)*24*time.Hour,
+	)
+	authService := service.NewAuthService(
+		db,
+		userRepo,
+		apiTokenRepo,
+		auditRepo,
+		time.Duration(cfg.Auth.SessionTTLHours)*time.Hour,
+	)
+	materializedService := service.NewMaterializedService(db,
+		service.MaterializedWithMaterializedRepository(materializedRepo),
+		service.MaterializedWithPortfolioRepository(portfolioRepo),
+		service.MaterializedWithFundRepository(fundRepo),
+		service.MaterializedWithFundService(fundService),
+		service.MaterializedWithDividendService(dividendService),
+		service.MaterializedWithRealizedGainLossService(realizedGainLossService),
+		service.MaterializedWithDataLoaderService(dataloaderService),
+		service.MaterializedWithPortfolioService(portfolioService),
+		service.MaterializedWithPortfolioFundRepository(pfRepo),
+		service.MaterializedWithJobService(jobService),
+	)
+	fundService.SetMaterializedInvalidator(materializedService)
+	transactionService.SetMaterializedInvalidator(materializedService)
+	dividendService.SetMaterializedInvalidator(materializedService)
+	ibkrService.SetMaterializedInvalidator(materializedService)
+	developerService.SetMaterializedInvalidator(materializedService)
+	trashService.SetMaterializedInvalidator(materializedService)
+	webhookService := service.NewWebhookService(
+		db,
+		webhookRepo,
+		auditRepo,
+		cfg.Webhooks,
+	)
+	fundService.SetEventPublisher(webhookService)
+	ibkrService.SetEventPublisher(webhookService)
+	materializedService.SetEventPublisher(webhookService, cfg.Webhooks.PortfolioDropPercent)
+	var emailSender email.Sender
+	if cfg.SMTP.Host != "" {
+		emailSender = email.NewSMTPClientWithConfig(cfg.SMTP)
+	}
+	digestService := service.NewDigestService(
+		db,
+		developerRepo,
+		auditRepo,
+		materializedService,
+		ibkrService,
+		emailSender,
+	)
+	alertService := service.NewAlertService(
+		db,
+		alertRepo,
+		auditRepo,
+		portfolioRepo,
+		fundRepo,
+		pfRepo,
+		materializedRepo,
+	)
+	alertService.SetEventPublisher(webhookService)
+	alertService.SetNotifier(digestService)
+	fundService.SetAlertEvaluator(alertService)
+	materializedService.SetAlertEvaluator(alertService)
+	allocationService := service.NewAllocationService(
+		db,
+		allocationRepo,
+		auditRepo,
+		portfolioRepo,
+		pfRepo,
+		fundService,
+	)
+	planService := service.NewInvestmentPlanService(
+		db,
+		planRepo,
+		auditRepo,
+		pfRepo,
+		fundRepo,
+		transactionRepo,
+	)
+	planService.SetMaterializedInvalidator(materializedService)
+	incomeService := service.NewDividendIncomeService(
+		dividendRepo,
+		transactionRepo,
+		fundService,
+	)
+	withholdingService := service.NewWithholdingTaxService(
+		db,
+		withholdingRepo,
+		auditRepo,
+	)
+	taxReportService := service.NewTaxReportService(
+		repository.NewTaxReportRepository(db),
+		portfolioRepo,
+		transactionRepo,
+		withholdingRepo,
+		developerRepo,
+	)
+	service.RegisterJobHandlers(jobService, fundService, ibkrService, trashService, authService, materializedService, digestService, planService)
+	schedulerService := service.NewSchedulerService(
+		db,
+		developerRepo,
+		jobRepo,
+		auditRepo,
+		jobService,
+		cfg.Scheduler,
+	)
+
+	return &Services{
+		System:         systemService,
+		Portfolio:      portfolioService,
+		Fund:           fundService,
+		Materialized:   materializedService,
+		Dividend:       dividendService,
+		Transaction:    transactionService,
+		Ibkr:           ibkrService,
+		Inbox:          inboxService,
+		Audit:          auditService,
+		Trash:          trashService,
+		Developer:      developerService,
+		Auth:           authService,
+		Job:            jobService,
+		Scheduler:      schedulerService,
+		Webhook:        webhookService,
+		Digest:         digestService,
+		Alert:          alertService,
+		Allocation:     allocationService,
+		InvestmentPlan: planService,
+		DividendIncome: incomeService,
+		WithholdingTax: withholdingService,
+		TaxReport:      taxReportService,
+	}
+}
@@ -0,0 +1,90 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fernet/fernet-go"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/logging"
+)
+
+var appLog = logging.NewLogger("system")
+
+// EncryptionKeyFile is the name of the generated key file, stored next to the database.
+// It holds one key per line, newest first.
+const EncryptionKeyFile = ".ibkr_encryption_key"
+
+// EncryptionKeyPath returns the path of the key file for the database at dbPath.
+func EncryptionKeyPath(dbPath string) string {
+	return filepath.Join(filepath.Dir(dbPath), EncryptionKeyFile)
+}
+
+// ResolveEncryptionKeys returns the encryption keys, newest first, from config, file, or
+// auto-generation. Priority: env vars > file > generate-and-write.
+func ResolveEncryptionKeys(cfgKeys []string, dbPath string) ([]string, error) {
+	// 1. Env vars already loaded into cfgKeys
+	if len(cfgKeys) > 0 {
+		return cfgKeys, nil
+	}
+
+	// 2. Try file
+	keyPath := EncryptionKeyPath(dbPath)
+	keys, err := ReadEncryptionKeys(keyPath)
+	if err == nil && len(keys) > 0 {
+		return keys, nil
+	}
+
+	// 3. Generate new key and write to file
+	var k fernet.Key
+	if err := k.Generate(); err != nil {
+		return nil, fmt.Errorf("generate encryption key: %w", err)
+	}
+	encoded := k.Encode()
+
+	if err := WriteEncryptionKeys(keyPath, encoded); err != nil {
+		return nil, err
+	}
+	appLog.Info("generated new IBKR encryption key", "path", keyPath)
+
+	return []string{encoded}, nil
+}
+
+// LoadEncryptionKeys resolves and decodes the fernet keys for the database at dbPath,
+// newest first.
+func LoadEncryptionKeys(cfgKeys []string, dbPath string) ([]*fernet.Key, error) {
+	encoded, err := ResolveEncryptionKeys(cfgKeys, dbPath)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := fernet.DecodeKeys(encoded...)
+	if err != nil {
+		return nil, fmt.Errorf("invalid IBKR encryption key: %w", err)
+	}
+	return keys, nil
+}
+
+// ReadEncryptionKeys reads the keys in keyPath, newest first, skipping blank lines.
+func ReadEncryptionKeys(keyPath string) ([]string, error) {
+	data, err := os.ReadFile(keyPath) //nolint:gosec // Path is derived from the configured database path.
+	if err != nil {
+		return nil, fmt.Errorf("read encryption key file: %w", err)
+	}
+	var keys []string
+	for line := range strings.SplitSeq(string(data), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			keys = append(keys, line)
+		}
+	}
+	return keys, nil
+}
+
+// WriteEncryptionKeys writes encoded keys to keyPath, one per line and readable only by
+// the owner. Pass them newest first.
+func WriteEncryptionKeys(keyPath string, encoded ...string) error {
+	if err := os.WriteFile(keyPath, []byte(strings.Join(encoded, "\n")+"\n"), 0o600); err != nil {
+		return fmt.Errorf("write encryption key to %s: %w", keyPath, err)
+	}
+	return nil
+}
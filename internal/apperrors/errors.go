@@ -43,6 +43,42 @@ var (
 
 	// ErrExchangeRateNotFound indicates no record for a specific currency and date combination
 	ErrExchangeRateNotFound = errors.New("exchange rate for currency/date not found")
+
+	// ErrTrashItemNotFound indicates that a trash item with the given ID does not exist.
+	ErrTrashItemNotFound = errors.New("trash item not found")
+
+	// ErrUserNotFound indicates that a user with the given ID does not exist.
+	ErrUserNotFound = errors.New("user not found")
+
+	// ErrPortfolioShareNotFound indicates that the portfolio is not shared with the given user.
+	ErrPortfolioShareNotFound = errors.New("portfolio share not found")
+
+	// ErrAPITokenNotFound indicates that an API token with the given ID does not exist.
+	ErrAPITokenNotFound = errors.New("API token not found")
+
+	// ErrJobNotFound indicates that a job with the given ID does not exist.
+	ErrJobNotFound = errors.New("job not found")
+
+	// ErrScheduledTaskNotFound indicates that no scheduled task has the given name.
+	ErrScheduledTaskNotFound = errors.New("scheduled task not found")
+
+	// ErrWebhookNotFound indicates that a webhook with the given ID does not exist.
+	ErrWebhookNotFound = errors.New("webhook not found")
+
+	// ErrWebhookDeliveryNotFound indicates that a webhook delivery with the given ID does not exist.
+	ErrWebhookDeliveryNotFound = errors.New("webhook delivery not found")
+
+	// ErrAlertRuleNotFound indicates that an alert rule with the given ID does not exist.
+	ErrAlertRuleNotFound = errors.New("alert rule not found")
+
+	// ErrAlertNotFound indicates that an alert with the given ID does not exist.
+	ErrAlertNotFound = errors.New("alert not found")
+
+	// ErrInvestmentPlanNotFound indicates that an investment plan with the given ID does not exist.
+	ErrInvestmentPlanNotFound = errors.New("investment plan not found")
+
+	// ErrWithholdingTaxRateNotFound indicates that no default withholding tax rate exists for the given country.
+	ErrWithholdingTaxRateNotFound = errors.New("withholding tax rate not found")
 )
 
 // Business logic errors represent validation failures or constraint violations.
@@ -80,6 +116,50 @@ var (
 	// ErrFundInUse indicates that a fund cannot be deleted because it is being used by portfolios.
 	ErrFundInUse = errors.New("fund is in use")
 
+	// ErrTrashRestoreConflict indicates that a trashed entity cannot be restored because its rows
+	// collide with current data or depend on rows that no longer exist.
+	ErrTrashRestoreConflict = errors.New("trash item conflicts with existing data")
+
+	// ErrUsernameTaken indicates that another account already uses the requested username.
+	ErrUsernameTaken = errors.New("username already taken")
+
+	// ErrSetupAlreadyCompleted indicates that the initial admin account has already been created.
+	ErrSetupAlreadyCompleted = errors.New("setup already completed")
+
+	// ErrCannotDeleteSelf indicates that an admin tried to delete their own account.
+	ErrCannotDeleteSelf = errors.New("cannot delete your own account")
+
+	// ErrInvalidShareTarget indicates a portfolio cannot be shared with its own owner.
+	ErrInvalidShareTarget = errors.New("cannot share a portfolio with its owner")
+
+	// ErrAPITokenRevoked indicates that an API token was already revoked.
+	ErrAPITokenRevoked = errors.New("API token already revoked")
+
+	// ErrJobNotRetryable indicates that only failed jobs can be retried.
+	ErrJobNotRetryable = errors.New("only failed jobs can be retried")
+
+	// ErrUnknownJobType indicates that no handler is registered for a job type.
+	ErrUnknownJobType = errors.New("unknown job type")
+
+	// ErrEmailNotConfigured indicates that email cannot be sent because no SMTP server is configured.
+	ErrEmailNotConfigured = errors.New("email is not configured")
+
+	// ErrDigestNoRecipients indicates that the email digest has no recipients to send to.
+	ErrDigestNoRecipients = errors.New("email digest has no recipients")
+
+	// ErrAlertNotActive indicates that only active alerts can be acknowledged.
+	ErrAlertNotActive = errors.New("only active alerts can be acknowledged")
+
+	// ErrNoAllocationTargets indicates that a portfolio has no target allocation to rebalance against.
+	ErrNoAllocationTargets = errors.New("portfolio has no allocation targets")
+
+	// ErrMaterializedDataNotAvailable indicates that the materialized history does not cover a date
+	// a report needs; rebuilding the materialized history resolves it.
+	ErrMaterializedDataNotAvailable = errors.New("materialized data not available")
+
+	// ErrFundNotInPortfolio indicates that an allocation target refers to a fund the portfolio does not hold.
+	ErrFundNotInPortfolio = errors.New("fund is not part of the portfolio")
+
 	// Generic operation failure constants
 	ErrFailedToRetrieve = errors.New("failed to retrieve data")
 )
@@ -100,6 +180,7 @@ var (
 	// Dividend operation errors
 	ErrFailedToRetrieveDividends       = errors.New("failed to retrieve dividends")
 	ErrFailedToRetrievePendingDividend = errors.New("failed to retrieve pending dividend")
+	ErrFailedToRetrieveDividendIncome  = errors.New("failed to retrieve dividend income")
 
 	// Fund operation errors
 	ErrFailedToRetrieveFunds       = errors.New("failed to retrieve funds")
@@ -123,6 +204,7 @@ var (
 	ErrFailedToRetrieveIbkrConfig        = errors.New("failed to retrieve ibkr config")
 	ErrFailedToUpdateIbkrConfig          = errors.New("failed to update ibkr config")
 	ErrFailedToDeleteIbkrConfig          = errors.New("failed to delete ibkr config")
+	ErrFailedToRotateEncryptionKey       = errors.New("failed to rotate encryption key")
 	ErrFailedToRetrieveInboxTransactions = errors.New("failed to retrieve inbox transactions")
 	ErrFailedToGetTransactionAllocations = errors.New("failed to get transaction allocations")
 	ErrFailedToGetEligiblePortfolios     = errors.New("failed to get eligible portfolios")
@@ -135,8 +217,12 @@ var (
 	ErrFailedToMatchDividend             = errors.New("failed to match dividend")
 	ErrFailedToGetIbkrTransaction        = errors.New("failed to get ibkr transaction")
 
+	// Inbox operation errors
+	ErrFailedToStageInboxTransactions = errors.New("failed to stage inbox transactions")
+
 	// System operation errors
 	ErrFailedToGetVersionInfo = errors.New("failed to get version information")
+	ErrFailedToGetConfig      = errors.New("failed to get configuration")
 
 	// Developer operation errors
 	ErrFailedToRetrieveLogFilterOpts = errors.New("failed to retrieve log filter options")
@@ -151,6 +237,114 @@ var (
 	ErrFailedToImportFundPrices      = errors.New("failed to import fund prices")
 	ErrFailedToImportTransactions    = errors.New("failed to import transactions")
 	ErrInvalidCSVHeaders             = errors.New("invalid CSV headers")
+
+	// Audit operation errors
+	ErrFailedToRetrieveAuditEvents = errors.New("failed to retrieve audit events")
+
+	// Trash operation errors
+	ErrFailedToRetrieveTrash    = errors.New("failed to retrieve trash")
+	ErrFailedToRestoreTrashItem = errors.New("failed to restore trash item")
+	ErrFailedToPurgeTrashItem   = errors.New("failed to purge trash item")
+)
+
+// Authentication and authorization errors.
+var (
+	// ErrInvalidCredentials indicates that the username or password is wrong.
+	// The two cases are deliberately indistinguishable.
+	ErrInvalidCredentials = errors.New("invalid username or password")
+
+	// ErrSessionInvalid indicates that a bearer token is unknown or expired.
+	ErrSessionInvalid = errors.New("session is invalid or expired")
+
+	// ErrAuthenticationRequired indicates that the request carries no credentials.
+	ErrAuthenticationRequired = errors.New("authentication required")
+
+	// ErrAdminRequired indicates that the endpoint is restricted to administrators.
+	ErrAdminRequired = errors.New("administrator access required")
+
+	// ErrPortfolioAccessDenied indicates that the caller may read but not modify a portfolio.
+	ErrPortfolioAccessDenied = errors.New("insufficient access to portfolio")
+
+	// ErrScopeRequired indicates that the caller's API token lacks the scope an endpoint needs.
+	ErrScopeRequired = errors.New("API token is missing the required scope")
+
+	// ErrSessionRequired indicates that the endpoint must be called with a login session
+	// rather than an API token, e.g. to manage tokens or change the password.
+	ErrSessionRequired = errors.New("endpoint requires a login session")
+
+	// ErrScopeNotAllowed indicates that a non-administrator requested an administrator-only scope.
+	ErrScopeNotAllowed = errors.New("scope is restricted to administrators")
+
+	// Auth operation errors
+	ErrFailedToAuthenticate     = errors.New("failed to authenticate")
+	ErrFailedToRetrieveUsers    = errors.New("failed to retrieve users")
+	ErrFailedToCreateUser       = errors.New("failed to create user")
+	ErrFailedToDeleteUser       = errors.New("failed to delete user")
+	ErrFailedToChangePassword   = errors.New("failed to change password")
+	ErrFailedToRetrieveShares   = errors.New("failed to retrieve portfolio shares")
+	ErrFailedToSharePortfolio   = errors.New("failed to share portfolio")
+	ErrFailedToUnsharePortfolio = errors.New("failed to remove portfolio share")
+	ErrFailedToRetrieveTokens   = errors.New("failed to retrieve API tokens")
+	ErrFailedToCreateToken      = errors.New("failed to create API token")
+	ErrFailedToRevokeToken      = errors.New("failed to revoke API token")
+
+	// Job operation errors
+	ErrFailedToRetrieveJobs = errors.New("failed to retrieve jobs")
+	ErrFailedToRetryJob     = errors.New("failed to retry job")
+	ErrFailedToStartJob     = errors.New("failed to start job")
+
+	// Scheduled task operation errors
+	ErrFailedToRetrieveScheduledTasks = errors.New("failed to retrieve scheduled tasks")
+	ErrFailedToUpdateScheduledTask    = errors.New("failed to update scheduled task")
+	ErrFailedToRunScheduledTask       = errors.New("failed to run scheduled task")
+
+	// Webhook operation errors
+	ErrFailedToRetrieveWebhooks   = errors.New("failed to retrieve webhooks")
+	ErrFailedToCreateWebhook      = errors.New("failed to create webhook")
+	ErrFailedToUpdateWebhook      = errors.New("failed to update webhook")
+	ErrFailedToDeleteWebhook      = errors.New("failed to delete webhook")
+	ErrFailedToRetrieveDeliveries = errors.New("failed to retrieve webhook deliveries")
+	ErrFailedToTestWebhook        = errors.New("failed to send test event")
+
+	// Email digest operation errors
+	ErrFailedToRetrieveDigestSettings = errors.New("failed to retrieve digest settings")
+	ErrFailedToUpdateDigestSettings   = errors.New("failed to update digest settings")
+	ErrFailedToBuildDigest            = errors.New("failed to build digest")
+	ErrFailedToSendDigest             = errors.New("failed to send digest")
+
+	// Alert operation errors
+	ErrFailedToRetrieveAlertRules = errors.New("failed to retrieve alert rules")
+	ErrFailedToCreateAlertRule    = errors.New("failed to create alert rule")
+	ErrFailedToUpdateAlertRule    = errors.New("failed to update alert rule")
+	ErrFailedToDeleteAlertRule    = errors.New("failed to delete alert rule")
+	ErrFailedToRetrieveAlerts     = errors.New("failed to retrieve alerts")
+	ErrFailedToAcknowledgeAlert   = errors.New("failed to acknowledge alert")
+	ErrFailedToEvaluateAlerts     = errors.New("failed to evaluate alerts")
+
+	// Allocation operation errors
+	ErrFailedToRetrieveAllocationTargets = errors.New("failed to retrieve allocation targets")
+	ErrFailedToUpdateAllocationTargets   = errors.New("failed to update allocation targets")
+	ErrFailedToCalculateRebalance        = errors.New("failed to calculate rebalancing")
+
+	// Investment plan operation errors
+	ErrFailedToRetrieveInvestmentPlans = errors.New("failed to retrieve investment plans")
+	ErrFailedToCreateInvestmentPlan    = errors.New("failed to create investment plan")
+	ErrFailedToUpdateInvestmentPlan    = errors.New("failed to update investment plan")
+	ErrFailedToDeleteInvestmentPlan    = errors.New("failed to delete investment plan")
+
+	// Withholding tax operation errors
+	ErrFailedToRetrieveWithholdingTax   = errors.New("failed to retrieve withholding tax")
+	ErrFailedToUpdateWithholdingTaxRate = errors.New("failed to update withholding tax rate")
+	ErrFailedToDeleteWithholdingTaxRate = errors.New("failed to delete withholding tax rate")
+
+	// Tax report operation errors
+	ErrFailedToRetrieveTaxReport = errors.New("failed to retrieve tax report")
+
+	// Materialized view operation errors
+	ErrFailedToRetrieveRegenQueue  = errors.New("failed to retrieve regeneration queue")
+	ErrFailedToRetrieveCoverage    = errors.New("failed to retrieve materialized coverage")
+	ErrFailedToRebuildMaterialized = errors.New("failed to rebuild materialized history")
+	ErrFailedToVerifyMaterialized  = errors.New("failed to verify materialized history")
 )
 
 // Data integrity errors represent inconsistencies or corruption in the data.
@@ -0,0 +1,185 @@
+// Package auth provides password hashing, session token generation and the
+// per-request Principal that describes who is calling and which portfolios they may use.
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/logging"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/model"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// tokenBytes is the amount of randomness in a session or API token.
+const tokenBytes = 32
+
+// APITokenPrefix marks personal API tokens so they can be told apart from session tokens.
+const APITokenPrefix = "ipm_"
+
+// HashPassword returns the bcrypt hash of password.
+func HashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", fmt.Errorf("hash password: %w", err)
+	}
+	return string(hash), nil
+}
+
+// CheckPassword reports whether password matches the bcrypt hash.
+func CheckPassword(hash, password string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
+
+// NewToken returns a random, URL-safe bearer token.
+func NewToken() (string, error) {
+	b := make([]byte, tokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// NewAPIToken returns a random personal API token carrying APITokenPrefix.
+func NewAPIToken() (string, error) {
+	token, err := NewToken()
+	if err != nil {
+		return "", err
+	}
+	return APITokenPrefix + token, nil
+}
+
+// IsAPIToken reports whether a bearer token is a personal API token rather than a session token.
+func IsAPIToken(token string) bool {
+	return strings.HasPrefix(token, APITokenPrefix)
+}
+
+// HashToken returns the hex SHA-256 of a bearer token, which is what gets stored.
+// Tokens carry enough entropy that a fast hash is sufficient.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// Principal is the authenticated caller of a request.
+// Administrators may access every portfolio; other users only the portfolios
+// they own or that were shared with them. Callers using a personal API token
+// are further limited to the token's scopes.
+type Principal struct {
+	User model.User
+	// APITokenID is the ID of the API token the request authenticated with,
+	// or empty for a login session.
+	APITokenID string
+
+	portfolios     map[string]model.PortfolioAccess // portfolio ID -> access level
+	portfolioFunds map[string]string                // portfolio_fund ID -> portfolio ID
+	scopes         []model.APIScope
+}
+
+// NewPrincipal creates a Principal for user with the given portfolio access and the
+// portfolio_fund to portfolio mapping of those portfolios.
+func NewPrincipal(user model.User, portfolios map[string]model.PortfolioAccess, portfolioFunds map[string]string) *Principal {
+	return &Principal{
+		User:           user,
+		portfolios:     portfolios,
+		portfolioFunds: portfolioFunds,
+	}
+}
+
+// WithAPIToken returns a copy of p limited to the scopes of the API token it authenticated with.
+func (p *Principal) WithAPIToken(tokenID string, scopes []model.APIScope) *Principal {
+	restricted := *p
+	restricted.APITokenID = tokenID
+	restricted.scopes = scopes
+	return &restricted
+}
+
+// HasScope reports whether the caller may use endpoints requiring scope.
+// Login sessions carry every scope.
+func (p *Principal) HasScope(required model.APIScope) bool {
+	if p.APITokenID == "" {
+		return true
+	}
+	for _, s := range p.scopes {
+		if s.Satisfies(required) {
+			return true
+		}
+	}
+	return false
+}
+
+// PortfolioAccess returns the caller's access level to a portfolio, and false if it has none.
+func (p *Principal) PortfolioAccess(portfolioID string) (model.PortfolioAccess, bool) {
+	if p.User.IsAdmin {
+		return model.PortfolioAccessOwner, true
+	}
+	access, ok := p.portfolios[portfolioID]
+	return access, ok
+}
+
+// CanAccessPortfolio reports whether the caller has at least the required access to a portfolio.
+func (p *Principal) CanAccessPortfolio(portfolioID string, required model.PortfolioAccess) bool {
+	access, ok := p.PortfolioAccess(portfolioID)
+	return ok && access.Allows(required)
+}
+
+// CanAccessPortfolioFund reports whether the caller has at least the required access to
+// the portfolio a portfolio_fund belongs to.
+func (p *Principal) CanAccessPortfolioFund(portfolioFundID string, required model.PortfolioAccess) bool {
+	if p.User.IsAdmin {
+		return true
+	}
+	portfolioID, ok := p.portfolioFunds[portfolioFundID]
+	return ok && p.CanAccessPortfolio(portfolioID, required)
+}
+
+type ctxKey struct{}
+
+// WithPrincipal returns a context carrying the authenticated caller.
+// The user ID is also attached for logging and the audit trail.
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	ctx = context.WithValue(ctx, ctxKey{}, p)
+	return logging.WithUserID(ctx, p.User.ID)
+}
+
+// PrincipalFromContext returns the authenticated caller, or nil when the context
+// is not bound to a user (scheduled jobs, internal calls, or before the first
+// account is created). A nil Principal is not restricted.
+func PrincipalFromContext(ctx context.Context) *Principal {
+	p, _ := ctx.Value(ctxKey{}).(*Principal)
+	return p
+}
+
+// CanAccessPortfolio reports whether the caller in ctx has at least the required access
+// to a portfolio. Always true for contexts without a Principal.
+func CanAccessPortfolio(ctx context.Context, portfolioID string, required model.PortfolioAccess) bool {
+	p := PrincipalFromContext(ctx)
+	return p == nil || p.CanAccessPortfolio(portfolioID, required)
+}
+
+// CanAccessPortfolioFund reports whether the caller in ctx has at least the required access
+// to a portfolio_fund's portfolio. Always true for contexts without a Principal.
+func CanAccessPortfolioFund(ctx context.Context, portfolioFundID string, required model.PortfolioAccess) bool {
+	p := PrincipalFromContext(ctx)
+	return p == nil || p.CanAccessPortfolioFund(portfolioFundID, required)
+}
+
+// Scope returns the repository scope of the caller in ctx: every portfolio for
+// administrators and contexts without a Principal, otherwise the portfolios the
+// caller owns or that were shared with them.
+func Scope(ctx context.Context) model.PortfolioScope {
+	p := PrincipalFromContext(ctx)
+	if p == nil || p.User.IsAdmin {
+		return model.AllPortfolios()
+	}
+	ids := make([]string, 0, len(p.portfolios))
+	for id := range p.portfolios {
+		ids = append(ids, id)
+	}
+	return model.PortfolioScope{PortfolioIDs: ids}
+}
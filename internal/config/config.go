@@ -1,49 +1,224 @@
-// Package config loads and exposes application configuration from environment variables and .env files.
+// Package config loads and exposes application configuration from an optional YAML file,
+// environment variables and .env files.
 package config
 
 import (
+	"errors"
 	"fmt"
+	"net/mail"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
+	"github.com/robfig/cron/v3"
 )
 
 // Config holds all configuration for the application.
 type Config struct {
-	Server         ServerConfig
-	Database       DatabaseConfig
-	Log            LogConfig
-	CORS           CORSConfig
-	EncryptionKey  string // IBKR_ENCRYPTION_KEY (fernet, base64-encoded)
-	InternalAPIKey string // INTERNAL_API_KEY
+	Server         ServerConfig    `yaml:"server" json:"server"`
+	Database       DatabaseConfig  `yaml:"database" json:"database"`
+	Log            LogConfig       `yaml:"log" json:"log"`
+	CORS           CORSConfig      `yaml:"cors" json:"cors"`
+	Scheduler      SchedulerConfig `yaml:"scheduler" json:"scheduler"`
+	Providers      ProvidersConfig `yaml:"providers" json:"providers"`
+	Trash          TrashConfig     `yaml:"trash" json:"trash"`
+	Auth           AuthConfig      `yaml:"auth" json:"auth"`
+	Tracing        TracingConfig   `yaml:"tracing" json:"tracing"`
+	Webhooks       WebhooksConfig  `yaml:"webhooks" json:"webhooks"`
+	SMTP           SMTPConfig      `yaml:"smtp" json:"smtp"`
+	EncryptionKeys []string        `yaml:"-" json:"-"`              // IBKR_ENCRYPTION_KEYS or IBKR_ENCRYPTION_KEY (fernet, base64-encoded), newest first
+	InternalAPIKey string          `yaml:"-" json:"-"`              // INTERNAL_API_KEY
+	File           string          `yaml:"-" json:"file,omitempty"` // Config file that was loaded, empty when none
 }
 
 // ServerConfig holds server-specific configuration.
 type ServerConfig struct {
-	Port string
-	Host string
-	Addr string // Combined host:port for convenience
+	Port            string   `yaml:"port" json:"port"`
+	Host            string   `yaml:"host" json:"host"`
+	Addr            string   `yaml:"-" json:"addr"` // Combined host:port for convenience
+	ReadTimeout     Duration `yaml:"read_timeout" json:"readTimeout"`
+	WriteTimeout    Duration `yaml:"write_timeout" json:"writeTimeout"`
+	IdleTimeout     Duration `yaml:"idle_timeout" json:"idleTimeout"`
+	ShutdownTimeout Duration `yaml:"shutdown_timeout" json:"shutdownTimeout"` // Time to drain requests, cron and jobs on shutdown
 }
 
 // DatabaseConfig holds database-specific configuration.
 type DatabaseConfig struct {
-	Path string
+	Path              string   `yaml:"path" json:"path"`
+	BusyTimeout       Duration `yaml:"busy_timeout" json:"busyTimeout"`             // PRAGMA busy_timeout
+	JournalMode       string   `yaml:"journal_mode" json:"journalMode"`             // PRAGMA journal_mode
+	WALAutocheckpoint int      `yaml:"wal_autocheckpoint" json:"walAutocheckpoint"` // PRAGMA wal_autocheckpoint, in pages
 }
 
 // LogConfig holds log-specific configuration.
 type LogConfig struct {
-	Dir string
+	Dir           string   `yaml:"dir" json:"dir"`
+	QueueSize     int      `yaml:"queue_size" json:"queueSize"`         // Entries buffered for the database writer
+	MaxBatchSize  int      `yaml:"max_batch_size" json:"maxBatchSize"`  // Entries per database transaction
+	FlushInterval Duration `yaml:"flush_interval" json:"flushInterval"` // Max time between database flushes
+	WriteTimeout  Duration `yaml:"write_timeout" json:"writeTimeout"`   // Per-batch database write timeout
 }
 
 // CORSConfig holds CORS-specific configuration.
 type CORSConfig struct {
-	AllowedOrigins []string
+	AllowedOrigins []string `yaml:"allowed_origins" json:"allowedOrigins"`
 }
 
-// getCORSOrigins returns the allowed CORS origins from environment variables.
-func getCORSOrigins() []string {
+// SchedulerConfig holds the cron expressions (standard five-field syntax, UTC) of the
+// scheduled tasks.
+type SchedulerConfig struct {
+	FundPriceUpdate string `yaml:"fund_price_update" json:"fundPriceUpdate"`
+	IbkrImport      string `yaml:"ibkr_import" json:"ibkrImport"`
+	TrashPurge      string `yaml:"trash_purge" json:"trashPurge"`
+	SessionPurge    string `yaml:"session_purge" json:"sessionPurge"`
+	JobPurge        string `yaml:"job_purge" json:"jobPurge"`
+	EmailDigest     string `yaml:"email_digest" json:"emailDigest"`
+	InvestmentPlans string `yaml:"investment_plans" json:"investmentPlans"`
+}
+
+// ProvidersConfig holds the settings of the external data providers.
+type ProvidersConfig struct {
+	Yahoo YahooConfig `yaml:"yahoo" json:"yahoo"`
+	IBKR  IBKRConfig  `yaml:"ibkr" json:"ibkr"`
+}
+
+// YahooConfig holds the Yahoo Finance client settings.
+type YahooConfig struct {
+	Timeout        Duration `yaml:"timeout" json:"timeout"`                // Per-request HTTP timeout, 0 for none
+	MaxAttempts    int      `yaml:"max_attempts" json:"maxAttempts"`       // Total attempts per request
+	InitialBackoff Duration `yaml:"initial_backoff" json:"initialBackoff"` // First retry wait, doubled per attempt
+	MaxBackoff     Duration `yaml:"max_backoff" json:"maxBackoff"`         // Backoff cap
+}
+
+// IBKRConfig holds the IBKR Flex client settings.
+type IBKRConfig struct {
+	Timeout            Duration `yaml:"timeout" json:"timeout"`                         // Per-request HTTP timeout, 0 for none
+	PollAttempts       int      `yaml:"poll_attempts" json:"pollAttempts"`              // Statement polls before giving up
+	PollInitialBackoff Duration `yaml:"poll_initial_backoff" json:"pollInitialBackoff"` // First wait between polls, doubled per poll
+	PollMaxBackoff     Duration `yaml:"poll_max_backoff" json:"pollMaxBackoff"`         // Poll backoff cap
+}
+
+// TrashConfig holds configuration for the trash of deleted entities.
+type TrashConfig struct {
+	RetentionDays int `yaml:"retention_days" json:"retentionDays"` // Days a deleted entity stays restorable before it is purged
+}
+
+// AuthConfig holds configuration for user authentication.
+type AuthConfig struct {
+	SessionTTLHours int `yaml:"session_ttl_hours" json:"sessionTtlHours"` // Hours a login session stays valid
+}
+
+// TracingConfig holds OpenTelemetry tracing configuration.
+type TracingConfig struct {
+	Exporter    string  `yaml:"exporter" json:"exporter"`        // "none" (disabled), "stdout" or "otlp"
+	SampleRatio float64 `yaml:"sample_ratio" json:"sampleRatio"` // Fraction of new traces to sample, 0 to 1
+	ServiceName string  `yaml:"service_name" json:"serviceName"` // service.name resource attribute
+}
+
+// WebhooksConfig holds webhook delivery settings.
+type WebhooksConfig struct {
+	Timeout              Duration `yaml:"timeout" json:"timeout"`                             // Per-delivery HTTP timeout
+	MaxAttempts          int      `yaml:"max_attempts" json:"maxAttempts"`                    // Attempts before a delivery is marked failed
+	InitialBackoff       Duration `yaml:"initial_backoff" json:"initialBackoff"`              // First retry wait, doubled per attempt
+	MaxBackoff           Duration `yaml:"max_backoff" json:"maxBackoff"`                      // Backoff cap
+	PortfolioDropPercent float64  `yaml:"portfolio_drop_percent" json:"portfolioDropPercent"` // Daily loss that emits portfolio.value_drop, 0 to disable
+}
+
+// SMTPConfig holds the mail server used to send email. Email is disabled while Host is empty.
+type SMTPConfig struct {
+	Host     string   `yaml:"host" json:"host"`
+	Port     int      `yaml:"port" json:"port"`
+	Username string   `yaml:"username" json:"username"` // Empty for servers without authentication
+	Password string   `yaml:"-" json:"-"`               // SMTP_PASSWORD
+	From     string   `yaml:"from" json:"from"`         // Sender address, e.g. "Portfolio Manager <ipm@example.com>"
+	Security string   `yaml:"security" json:"security"` // "starttls", "tls" (implicit TLS) or "none"
+	Timeout  Duration `yaml:"timeout" json:"timeout"`   // Per-message timeout
+}
+
+// Defaults returns the configuration used when neither a config file nor environment
+// variables override a setting.
+func Defaults() Config {
+	return Config{
+		Server: ServerConfig{
+			Port:            "5000",
+			Host:            "0.0.0.0",
+			ReadTimeout:     Duration(15 * time.Second),
+			WriteTimeout:    Duration(15 * time.Second),
+			IdleTimeout:     Duration(60 * time.Second),
+			ShutdownTimeout: Duration(30 * time.Second),
+		},
+		Database: DatabaseConfig{
+			Path:              "./data/portfolio_manager.db",
+			BusyTimeout:       Duration(5 * time.Second),
+			JournalMode:       "WAL",
+			WALAutocheckpoint: 100,
+		},
+		Log: LogConfig{
+			Dir:           "./data/logs",
+			QueueSize:     1024,
+			MaxBatchSize:  50,
+			FlushInterval: Duration(250 * time.Millisecond),
+			WriteTimeout:  Duration(5 * time.Second),
+		},
+		CORS: CORSConfig{
+			AllowedOrigins: []string{"http://localhost:3000"},
+		},
+		Scheduler: SchedulerConfig{
+			FundPriceUpdate: "55 00 * * 1-5",  // 00:55 UTC every weekday
+			IbkrImport:      "30 5-7 * * 2-6", // 05:30-07:30 UTC Tue-Sat, after the previous business day's close
+			TrashPurge:      "15 03 * * *",
+			SessionPurge:    "30 03 * * *",
+			JobPurge:        "45 03 * * *",
+			EmailDigest:     "00 07 * * 1", // 07:00 UTC on Mondays
+			InvestmentPlans: "30 01 * * *", // 01:30 UTC, after the weekday price update
+		},
+		Providers: ProvidersConfig{
+			Yahoo: YahooConfig{
+				Timeout:        Duration(30 * time.Second),
+				MaxAttempts:    3,
+				InitialBackoff: Duration(1 * time.Second),
+				MaxBackoff:     Duration(4 * time.Second),
+			},
+			IBKR: IBKRConfig{
+				Timeout:            Duration(30 * time.Second),
+				PollAttempts:       10,
+				PollInitialBackoff: Duration(2 * time.Second),
+				PollMaxBackoff:     Duration(30 * time.Second),
+			},
+		},
+		Trash: TrashConfig{
+			RetentionDays: 30,
+		},
+		Auth: AuthConfig{
+			SessionTTLHours: 168,
+		},
+		Tracing: TracingConfig{
+			Exporter:    "none",
+			SampleRatio: 1,
+			ServiceName: "investment-portfolio-manager",
+		},
+		Webhooks: WebhooksConfig{
+			Timeout:              Duration(10 * time.Second),
+			MaxAttempts:          8,
+			InitialBackoff:       Duration(30 * time.Second),
+			MaxBackoff:           Duration(time.Hour),
+			PortfolioDropPercent: 5,
+		},
+		SMTP: SMTPConfig{
+			Port:     587,
+			Security: "starttls",
+			Timeout:  Duration(30 * time.Second),
+		},
+	}
+}
+
+// getCORSOrigins returns the allowed CORS origins from environment variables, or
+// defaultOrigins when neither is set.
+func getCORSOrigins(defaultOrigins []string) []string {
 	// Check for explicit CORS config first
 	if origins := os.Getenv("CORS_ALLOWED_ORIGINS"); origins != "" {
 		return strings.Split(origins, ",")
@@ -56,49 +231,207 @@ func getCORSOrigins() []string {
 			fmt.Sprintf("http://%s", domain),
 		}
 	}
-	// If nether, return development setting
-	return []string{"http://localhost:3000"}
+	// If neither, keep the file or development setting
+	return defaultOrigins
 }
 
-// Load reads configuration from environment variables and .env file.
+// Load reads the configuration in layers: defaults, then the YAML file named by CONFIG_FILE
+// (if any), then environment variables and the .env file. It fails on unknown file keys,
+// unparsable environment variables and out-of-range values.
 func Load() (*Config, error) {
 	// Try to load .env file (ignore error if it doesn't exist)
 	if err := godotenv.Load(); err != nil {
 		fmt.Fprintf(os.Stderr, "Warning: .env file not loaded: %v (this is OK if using env vars)\n", err)
 	}
 
-	config := &Config{
-		Server: ServerConfig{
-			Port: getEnv("SERVER_PORT", "5000"),
-			Host: getEnv("SERVER_HOST", "0.0.0.0"),
-		},
-		Database: DatabaseConfig{
-			Path: getDBPath(),
-		},
-		Log: LogConfig{
-			Dir: getEnv("LOG_DIR", "./data/logs"),
-		},
-		CORS: CORSConfig{
-			AllowedOrigins: getCORSOrigins(),
-		},
-		EncryptionKey:  getEnv("IBKR_ENCRYPTION_KEY", ""),
-		InternalAPIKey: getEnv("INTERNAL_API_KEY", ""),
+	config := Defaults()
+	if path := os.Getenv("CONFIG_FILE"); path != "" {
+		if err := loadFile(path, &config); err != nil {
+			return nil, err
+		}
+		config.File = path
 	}
+	if err := applyEnv(&config); err != nil {
+		return nil, err
+	}
+
+	config.Database.JournalMode = strings.ToUpper(config.Database.JournalMode)
 
 	// Combine host and port
 	config.Server.Addr = fmt.Sprintf("%s:%s", config.Server.Host, config.Server.Port)
 
-	return config, nil
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+// applyEnv overrides config with the environment variables that are set.
+func applyEnv(config *Config) error {
+	var errs []error
+	envInt := func(key string, dst *int) {
+		v, err := getEnvInt(key, *dst)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		*dst = v
+	}
+
+	config.Server.Port = getEnv("SERVER_PORT", config.Server.Port)
+	config.Server.Host = getEnv("SERVER_HOST", config.Server.Host)
+	config.Database.Path = getDBPath(config.Database.Path)
+	config.Log.Dir = getEnv("LOG_DIR", config.Log.Dir)
+	config.CORS.AllowedOrigins = getCORSOrigins(config.CORS.AllowedOrigins)
+	envInt("TRASH_RETENTION_DAYS", &config.Trash.RetentionDays)
+	envInt("SESSION_TTL_HOURS", &config.Auth.SessionTTLHours)
+	config.Tracing.Exporter = strings.ToLower(getEnv("TRACING_EXPORTER", config.Tracing.Exporter))
+	ratio, err := getEnvFloat("TRACING_SAMPLE_RATIO", config.Tracing.SampleRatio)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	config.Tracing.SampleRatio = ratio
+	config.Tracing.ServiceName = getEnv("OTEL_SERVICE_NAME", config.Tracing.ServiceName)
+	config.EncryptionKeys = getEncryptionKeys()
+	config.InternalAPIKey = getEnv("INTERNAL_API_KEY", "")
+	config.SMTP.Host = getEnv("SMTP_HOST", config.SMTP.Host)
+	envInt("SMTP_PORT", &config.SMTP.Port)
+	config.SMTP.Username = getEnv("SMTP_USERNAME", config.SMTP.Username)
+	config.SMTP.Password = getEnv("SMTP_PASSWORD", "")
+	config.SMTP.From = getEnv("SMTP_FROM", config.SMTP.From)
+	config.SMTP.Security = strings.ToLower(getEnv("SMTP_SECURITY", config.SMTP.Security))
+
+	return errors.Join(errs...)
+}
+
+// journalModes are the values SQLite accepts for PRAGMA journal_mode.
+var journalModes = []string{"DELETE", "TRUNCATE", "PERSIST", "MEMORY", "WAL", "OFF"}
+
+// Validate checks that every setting is in range and every cron expression parses.
+// It reports all problems at once.
+//
+//nolint:gocyclo // One check per setting.
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	port, err := strconv.Atoi(c.Server.Port)
+	check(err == nil && port > 0 && port <= 65535, "server.port: %q is not a valid port", c.Server.Port)
+	check(c.Server.ReadTimeout > 0, "server.read_timeout must be positive")
+	check(c.Server.WriteTimeout > 0, "server.write_timeout must be positive")
+	check(c.Server.IdleTimeout > 0, "server.idle_timeout must be positive")
+	check(c.Server.ShutdownTimeout > 0, "server.shutdown_timeout must be positive")
+
+	check(c.Database.Path != "", "database.path must be set")
+	check(c.Database.BusyTimeout >= 0, "database.busy_timeout must not be negative")
+	check(slices.Contains(journalModes, c.Database.JournalMode), "database.journal_mode: %q must be one of %s", c.Database.JournalMode, strings.Join(journalModes, ", "))
+	check(c.Database.WALAutocheckpoint >= 0, "database.wal_autocheckpoint must not be negative")
+
+	check(c.Log.QueueSize > 0, "log.queue_size must be positive")
+	check(c.Log.MaxBatchSize > 0, "log.max_batch_size must be positive")
+	check(c.Log.FlushInterval > 0, "log.flush_interval must be positive")
+	check(c.Log.WriteTimeout > 0, "log.write_timeout must be positive")
+
+	for name, spec := range map[string]string{
+		"fund_price_update": c.Scheduler.FundPriceUpdate,
+		"ibkr_import":       c.Scheduler.IbkrImport,
+		"trash_purge":       c.Scheduler.TrashPurge,
+		"session_purge":     c.Scheduler.SessionPurge,
+		"job_purge":         c.Scheduler.JobPurge,
+		"email_digest":      c.Scheduler.EmailDigest,
+		"investment_plans":  c.Scheduler.InvestmentPlans,
+	} {
+		_, err := cron.ParseStandard(spec)
+		check(err == nil, "scheduler.%s: invalid cron expression %q: %v", name, spec, err)
+	}
+
+	yahoo := c.Providers.Yahoo
+	check(yahoo.Timeout >= 0, "providers.yahoo.timeout must not be negative")
+	check(yahoo.MaxAttempts > 0, "providers.yahoo.max_attempts must be positive")
+	check(yahoo.InitialBackoff > 0, "providers.yahoo.initial_backoff must be positive")
+	check(yahoo.MaxBackoff >= yahoo.InitialBackoff, "providers.yahoo.max_backoff must not be below initial_backoff")
+	ibkr := c.Providers.IBKR
+	check(ibkr.Timeout >= 0, "providers.ibkr.timeout must not be negative")
+	check(ibkr.PollAttempts > 0, "providers.ibkr.poll_attempts must be positive")
+	check(ibkr.PollInitialBackoff > 0, "providers.ibkr.poll_initial_backoff must be positive")
+	check(ibkr.PollMaxBackoff >= ibkr.PollInitialBackoff, "providers.ibkr.poll_max_backoff must not be below poll_initial_backoff")
+
+	check(c.Trash.RetentionDays > 0, "trash.retention_days (TRASH_RETENTION_DAYS) must be positive")
+	check(c.Auth.SessionTTLHours > 0, "auth.session_ttl_hours (SESSION_TTL_HOURS) must be positive")
+	check(slices.Contains([]string{"none", "stdout", "otlp"}, c.Tracing.Exporter), "tracing.exporter (TRACING_EXPORTER): %q must be none, stdout or otlp", c.Tracing.Exporter)
+	check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1, "tracing.sample_ratio (TRACING_SAMPLE_RATIO) must be between 0 and 1")
+
+	webhooks := c.Webhooks
+	check(webhooks.Timeout > 0, "webhooks.timeout must be positive")
+	check(webhooks.MaxAttempts > 0, "webhooks.max_attempts must be positive")
+	check(webhooks.InitialBackoff > 0, "webhooks.initial_backoff must be positive")
+	check(webhooks.MaxBackoff >= webhooks.InitialBackoff, "webhooks.max_backoff must not be below initial_backoff")
+	check(webhooks.PortfolioDropPercent >= 0 && webhooks.PortfolioDropPercent <= 100, "webhooks.portfolio_drop_percent must be between 0 and 100")
+
+	smtp := c.SMTP
+	check(smtp.Timeout > 0, "smtp.timeout must be positive")
+	check(slices.Contains([]string{"starttls", "tls", "none"}, smtp.Security), "smtp.security (SMTP_SECURITY): %q must be starttls, tls or none", smtp.Security)
+	if smtp.Host != "" {
+		check(smtp.Port > 0 && smtp.Port <= 65535, "smtp.port (SMTP_PORT): %d is not a valid port", smtp.Port)
+		_, err := mail.ParseAddress(smtp.From)
+		check(err == nil, "smtp.from (SMTP_FROM): %q is not a valid address", smtp.From)
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+	return nil
+}
+
+// RedactedConfig is the effective configuration with its secrets replaced by whether they
+// are set, safe to return from the API.
+type RedactedConfig struct {
+	Config
+	EncryptionKeyCount int  `json:"encryptionKeyCount"` // Keys from IBKR_ENCRYPTION_KEYS/IBKR_ENCRYPTION_KEY; 0 means the key file is used
+	InternalAPIKeySet  bool `json:"internalApiKeySet"`
+	SMTPPasswordSet    bool `json:"smtpPasswordSet"`
+}
+
+// Redacted returns c without its secrets.
+func (c *Config) Redacted() RedactedConfig {
+	return RedactedConfig{
+		Config:             *c,
+		EncryptionKeyCount: len(c.EncryptionKeys),
+		InternalAPIKeySet:  c.InternalAPIKey != "",
+		SMTPPasswordSet:    c.SMTP.Password != "",
+	}
+}
+
+// getEncryptionKeys returns the configured IBKR encryption keys, newest first.
+// IBKR_ENCRYPTION_KEYS holds a comma-separated list for key rotation and takes precedence
+// over the single IBKR_ENCRYPTION_KEY. Returns nil when neither is set.
+func getEncryptionKeys() []string {
+	if list := os.Getenv("IBKR_ENCRYPTION_KEYS"); list != "" {
+		var keys []string
+		for key := range strings.SplitSeq(list, ",") {
+			if key = strings.TrimSpace(key); key != "" {
+				keys = append(keys, key)
+			}
+		}
+		return keys
+	}
+	if key := os.Getenv("IBKR_ENCRYPTION_KEY"); key != "" {
+		return []string{key}
+	}
+	return nil
 }
 
 // getDBPath resolves the database file path.
 // DB_DIR (set by Docker) takes precedence: DB_DIR/portfolio_manager.db.
-// Falls back to DB_PATH, then the default local path.
-func getDBPath() string {
+// Falls back to DB_PATH, then defaultPath.
+func getDBPath(defaultPath string) string {
 	if dir := os.Getenv("DB_DIR"); dir != "" {
 		return filepath.Join(dir, "portfolio_manager.db")
 	}
-	return getEnv("DB_PATH", "./data/portfolio_manager.db")
+	return getEnv("DB_PATH", defaultPath)
 }
 
 // getEnv gets an environment variable or returns a default value
@@ -109,3 +442,31 @@ func getEnv(key, defaultValue string) string {
 	}
 	return value
 }
+
+// getEnvInt gets an integer environment variable, or returns a default value when it is
+// unset. Returns an error when it is set but not an integer.
+func getEnvInt(key string, defaultValue int) (int, error) {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return defaultValue, nil
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil {
+		return defaultValue, fmt.Errorf("%s: %q is not an integer", key, raw)
+	}
+	return value, nil
+}
+
+// getEnvFloat gets a floating-point environment variable, or returns a default value when
+// it is unset. Returns an error when it is set but not a number.
+func getEnvFloat(key string, defaultValue float64) (float64, error) {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return defaultValue, nil
+	}
+	value, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return defaultValue, fmt.Errorf("%s: %q is not a number", key, raw)
+	}
+	return value, nil
+}
@@ -1,7 +1,12 @@
 package config
 
 import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestGetEnv_Present(t *testing.T) {
@@ -31,7 +36,7 @@ func TestGetCORSOrigins_Explicit(t *testing.T) {
 	t.Setenv("CORS_ALLOWED_ORIGINS", "https://a.com,https://b.com")
 	t.Setenv("DOMAIN", "") // should be ignored
 
-	origins := getCORSOrigins()
+	origins := getCORSOrigins([]string{"http://localhost:3000"})
 	if len(origins) != 2 {
 		t.Fatalf("expected 2 origins, got %d", len(origins))
 	}
@@ -44,7 +49,7 @@ func TestGetCORSOrigins_DomainFallback(t *testing.T) {
 	t.Setenv("CORS_ALLOWED_ORIGINS", "")
 	t.Setenv("DOMAIN", "example.com")
 
-	origins := getCORSOrigins()
+	origins := getCORSOrigins([]string{"http://localhost:3000"})
 	if len(origins) != 2 {
 		t.Fatalf("expected 2 origins, got %d", len(origins))
 	}
@@ -60,7 +65,7 @@ func TestGetCORSOrigins_Default(t *testing.T) {
 	t.Setenv("CORS_ALLOWED_ORIGINS", "")
 	t.Setenv("DOMAIN", "")
 
-	origins := getCORSOrigins()
+	origins := getCORSOrigins([]string{"http://localhost:3000"})
 	if len(origins) != 1 || origins[0] != "http://localhost:3000" {
 		t.Errorf("expected default [http://localhost:3000], got %v", origins)
 	}
@@ -70,7 +75,7 @@ func TestGetDBPath_DBDir(t *testing.T) {
 	t.Setenv("DB_DIR", "/data/mydir")
 	t.Setenv("DB_PATH", "/other/path.db")
 
-	got := getDBPath()
+	got := getDBPath("./data/portfolio_manager.db")
 	want := "/data/mydir/portfolio_manager.db"
 	if got != want {
 		t.Errorf("getDBPath() = %q, want %q", got, want)
@@ -81,7 +86,7 @@ func TestGetDBPath_DBPath(t *testing.T) {
 	t.Setenv("DB_DIR", "")
 	t.Setenv("DB_PATH", "/custom/path.db")
 
-	got := getDBPath()
+	got := getDBPath("./data/portfolio_manager.db")
 	if got != "/custom/path.db" {
 		t.Errorf("getDBPath() = %q, want %q", got, "/custom/path.db")
 	}
@@ -91,7 +96,7 @@ func TestGetDBPath_Default(t *testing.T) {
 	t.Setenv("DB_DIR", "")
 	t.Setenv("DB_PATH", "")
 
-	got := getDBPath()
+	got := getDBPath("./data/portfolio_manager.db")
 	if got != "./data/portfolio_manager.db" {
 		t.Errorf("getDBPath() = %q, want %q", got, "./data/portfolio_manager.db")
 	}
@@ -99,6 +104,7 @@ func TestGetDBPath_Default(t *testing.T) {
 
 func TestLoad_Defaults(t *testing.T) {
 	// Clear relevant env vars so defaults are used.
+	t.Setenv("CONFIG_FILE", "")
 	t.Setenv("SERVER_PORT", "")
 	t.Setenv("SERVER_HOST", "")
 	t.Setenv("DB_DIR", "")
@@ -107,7 +113,10 @@ func TestLoad_Defaults(t *testing.T) {
 	t.Setenv("CORS_ALLOWED_ORIGINS", "")
 	t.Setenv("DOMAIN", "")
 	t.Setenv("IBKR_ENCRYPTION_KEY", "")
+	t.Setenv("IBKR_ENCRYPTION_KEYS", "")
 	t.Setenv("INTERNAL_API_KEY", "")
+	t.Setenv("TRASH_RETENTION_DAYS", "")
+	t.Setenv("SESSION_TTL_HOURS", "")
 
 	cfg, err := Load()
 	if err != nil {
@@ -129,9 +138,19 @@ func TestLoad_Defaults(t *testing.T) {
 	if cfg.Log.Dir != "./data/logs" {
 		t.Errorf("Log.Dir = %q, want %q", cfg.Log.Dir, "./data/logs")
 	}
+	if cfg.Trash.RetentionDays != 30 {
+		t.Errorf("Trash.RetentionDays = %d, want %d", cfg.Trash.RetentionDays, 30)
+	}
+	if cfg.Auth.SessionTTLHours != 168 {
+		t.Errorf("Auth.SessionTTLHours = %d, want %d", cfg.Auth.SessionTTLHours, 168)
+	}
+	if cfg.EncryptionKeys != nil {
+		t.Errorf("EncryptionKeys = %v, want nil", cfg.EncryptionKeys)
+	}
 }
 
 func TestLoad_CustomValues(t *testing.T) {
+	clearEnv(t)
 	t.Setenv("SERVER_PORT", "8080")
 	t.Setenv("SERVER_HOST", "127.0.0.1")
 	t.Setenv("DB_DIR", "")
@@ -140,6 +159,7 @@ func TestLoad_CustomValues(t *testing.T) {
 	t.Setenv("CORS_ALLOWED_ORIGINS", "https://mysite.com")
 	t.Setenv("DOMAIN", "")
 	t.Setenv("IBKR_ENCRYPTION_KEY", "secret123")
+	t.Setenv("IBKR_ENCRYPTION_KEYS", "")
 	t.Setenv("INTERNAL_API_KEY", "apikey456")
 
 	cfg, err := Load()
@@ -156,8 +176,8 @@ func TestLoad_CustomValues(t *testing.T) {
 	if cfg.Log.Dir != "/var/log/app" {
 		t.Errorf("Log.Dir = %q", cfg.Log.Dir)
 	}
-	if cfg.EncryptionKey != "secret123" {
-		t.Errorf("EncryptionKey = %q", cfg.EncryptionKey)
+	if len(cfg.EncryptionKeys) != 1 || cfg.EncryptionKeys[0] != "secret123" {
+		t.Errorf("EncryptionKeys = %v", cfg.EncryptionKeys)
 	}
 	if cfg.InternalAPIKey != "apikey456" {
 		t.Errorf("InternalAPIKey = %q", cfg.InternalAPIKey)
@@ -166,3 +186,277 @@ func TestLoad_CustomValues(t *testing.T) {
 		t.Errorf("CORS.AllowedOrigins = %v", cfg.CORS.AllowedOrigins)
 	}
 }
+
+func TestGetEncryptionKeys(t *testing.T) {
+	tests := []struct {
+		name   string
+		keys   string
+		single string
+		want   []string
+	}{
+		{"neither set", "", "", nil},
+		{"single key", "", "only", []string{"only"}},
+		{"list takes precedence", "new, old", "only", []string{"new", "old"}},
+		{"blank entries skipped", "new,, old ,", "", []string{"new", "old"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("IBKR_ENCRYPTION_KEYS", tt.keys)
+			t.Setenv("IBKR_ENCRYPTION_KEY", tt.single)
+
+			got := getEncryptionKeys()
+			if len(got) != len(tt.want) {
+				t.Fatalf("getEncryptionKeys() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("getEncryptionKeys()[%d] = %q, want %q", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestGetEnvInt(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    int
+		wantErr bool
+	}{
+		{"set", "7", 7, false},
+		{"unset", "", 30, false},
+		{"negative is left to validation", "-5", -5, false},
+		{"not a number", "week", 30, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("TEST_GETENV_INT", tt.value)
+			got, err := getEnvInt("TEST_GETENV_INT", 30)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("getEnvInt() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("getEnvInt() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetEnvFloat(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    float64
+		wantErr bool
+	}{
+		{"set", "0.25", 0.25, false},
+		{"zero", "0", 0, false},
+		{"unset", "", 0.5, false},
+		{"not a number", "half", 0.5, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("TEST_GETENV_FLOAT", tt.value)
+			got, err := getEnvFloat("TEST_GETENV_FLOAT", 0.5)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("getEnvFloat() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("getEnvFloat() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// clearEnv unsets every environment variable Load reads, so tests only see what they set.
+func clearEnv(t *testing.T) {
+	t.Helper()
+	for _, key := range []string{
+		"CONFIG_FILE", "SERVER_PORT", "SERVER_HOST", "DB_DIR", "DB_PATH", "LOG_DIR",
+		"CORS_ALLOWED_ORIGINS", "DOMAIN", "TRASH_RETENTION_DAYS", "SESSION_TTL_HOURS",
+		"TRACING_EXPORTER", "TRACING_SAMPLE_RATIO", "OTEL_SERVICE_NAME",
+		"IBKR_ENCRYPTION_KEY", "IBKR_ENCRYPTION_KEYS", "INTERNAL_API_KEY",
+		"SMTP_HOST", "SMTP_PORT", "SMTP_USERNAME", "SMTP_PASSWORD", "SMTP_FROM", "SMTP_SECURITY",
+	} {
+		t.Setenv(key, "")
+	}
+}
+
+// writeConfigFile writes content to a config file in a temp dir and points CONFIG_FILE at it.
+func writeConfigFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write config file: %v", err)
+	}
+	t.Setenv("CONFIG_FILE", path)
+	return path
+}
+
+func TestLoad_ConfigFile(t *testing.T) {
+	clearEnv(t)
+	path := writeConfigFile(t, `
+server:
+  port: "6000"
+  read_timeout: 30s
+database:
+  journal_mode: delete
+  busy_timeout: 10s
+scheduler:
+  fund_price_update: "0 1 * * *"
+providers:
+  yahoo:
+    max_attempts: 5
+log:
+  queue_size: 4096
+trash:
+  retention_days: 14
+`)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+
+	if cfg.File != path {
+		t.Errorf("File = %q, want %q", cfg.File, path)
+	}
+	if cfg.Server.Addr != "0.0.0.0:6000" {
+		t.Errorf("Server.Addr = %q, want %q", cfg.Server.Addr, "0.0.0.0:6000")
+	}
+	if time.Duration(cfg.Server.ReadTimeout) != 30*time.Second {
+		t.Errorf("Server.ReadTimeout = %v, want 30s", cfg.Server.ReadTimeout)
+	}
+	if time.Duration(cfg.Server.WriteTimeout) != 15*time.Second {
+		t.Errorf("Server.WriteTimeout = %v, want the 15s default", cfg.Server.WriteTimeout)
+	}
+	if cfg.Database.JournalMode != "DELETE" {
+		t.Errorf("Database.JournalMode = %q, want DELETE", cfg.Database.JournalMode)
+	}
+	if time.Duration(cfg.Database.BusyTimeout) != 10*time.Second {
+		t.Errorf("Database.BusyTimeout = %v, want 10s", cfg.Database.BusyTimeout)
+	}
+	if cfg.Scheduler.FundPriceUpdate != "0 1 * * *" {
+		t.Errorf("Scheduler.FundPriceUpdate = %q", cfg.Scheduler.FundPriceUpdate)
+	}
+	if cfg.Scheduler.TrashPurge != Defaults().Scheduler.TrashPurge {
+		t.Errorf("Scheduler.TrashPurge = %q, want the default", cfg.Scheduler.TrashPurge)
+	}
+	if cfg.Providers.Yahoo.MaxAttempts != 5 {
+		t.Errorf("Providers.Yahoo.MaxAttempts = %d, want 5", cfg.Providers.Yahoo.MaxAttempts)
+	}
+	if cfg.Log.QueueSize != 4096 {
+		t.Errorf("Log.QueueSize = %d, want 4096", cfg.Log.QueueSize)
+	}
+	if cfg.Trash.RetentionDays != 14 {
+		t.Errorf("Trash.RetentionDays = %d, want 14", cfg.Trash.RetentionDays)
+	}
+}
+
+func TestLoad_EnvOverridesConfigFile(t *testing.T) {
+	clearEnv(t)
+	writeConfigFile(t, `
+server:
+  port: "6000"
+cors:
+  allowed_origins: ["https://file.example"]
+trash:
+  retention_days: 14
+`)
+	t.Setenv("SERVER_PORT", "7000")
+	t.Setenv("TRASH_RETENTION_DAYS", "7")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+
+	if cfg.Server.Port != "7000" {
+		t.Errorf("Server.Port = %q, want the env value 7000", cfg.Server.Port)
+	}
+	if cfg.Trash.RetentionDays != 7 {
+		t.Errorf("Trash.RetentionDays = %d, want the env value 7", cfg.Trash.RetentionDays)
+	}
+	if len(cfg.CORS.AllowedOrigins) != 1 || cfg.CORS.AllowedOrigins[0] != "https://file.example" {
+		t.Errorf("CORS.AllowedOrigins = %v, want the file value", cfg.CORS.AllowedOrigins)
+	}
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		env  map[string]string
+		want string
+	}{
+		{name: "unknown file key", file: "server:\n  prot: \"6000\"\n", want: "prot"},
+		{name: "bad duration", file: "server:\n  read_timeout: soon\n", want: "invalid duration"},
+		{name: "bad cron expression", file: "scheduler:\n  job_purge: \"every night\"\n", want: "scheduler.job_purge"},
+		{name: "bad journal mode", file: "database:\n  journal_mode: fast\n", want: "database.journal_mode"},
+		{name: "zero queue size", file: "log:\n  queue_size: 0\n", want: "log.queue_size"},
+		{name: "backoff cap below initial", file: "providers:\n  yahoo:\n    max_backoff: 100ms\n", want: "providers.yahoo.max_backoff"},
+		{name: "drop percent out of range", file: "webhooks:\n  portfolio_drop_percent: 150\n", want: "webhooks.portfolio_drop_percent"},
+		{name: "env not a number", env: map[string]string{"TRASH_RETENTION_DAYS": "week"}, want: "TRASH_RETENTION_DAYS"},
+		{name: "env out of range", env: map[string]string{"SESSION_TTL_HOURS": "0"}, want: "SESSION_TTL_HOURS"},
+		{name: "env bad ratio", env: map[string]string{"TRACING_SAMPLE_RATIO": "1.5"}, want: "TRACING_SAMPLE_RATIO"},
+		{name: "env bad port", env: map[string]string{"SERVER_PORT": "http"}, want: "server.port"},
+		{name: "smtp without sender", env: map[string]string{"SMTP_HOST": "mail.example.com"}, want: "smtp.from"},
+		{name: "smtp bad security", env: map[string]string{"SMTP_SECURITY": "ssl"}, want: "smtp.security"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearEnv(t)
+			if tt.file != "" {
+				writeConfigFile(t, tt.file)
+			}
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			_, err := Load()
+			if err == nil {
+				t.Fatal("expected Load() to fail")
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error %q does not mention %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoad_MissingConfigFile(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("CONFIG_FILE", filepath.Join(t.TempDir(), "missing.yaml"))
+
+	if _, err := Load(); err == nil {
+		t.Fatal("expected Load() to fail for a missing config file")
+	}
+}
+
+func TestRedacted(t *testing.T) {
+	cfg := Defaults()
+	cfg.EncryptionKeys = []string{"new-key", "old-key"}
+	cfg.InternalAPIKey = "secret-api-key"
+	cfg.SMTP.Password = "secret-smtp-password"
+
+	data, err := json.Marshal(cfg.Redacted())
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	body := string(data)
+
+	for _, secret := range []string{"new-key", "old-key", "secret-api-key", "secret-smtp-password"} {
+		if strings.Contains(body, secret) {
+			t.Errorf("redacted config contains secret %q: %s", secret, body)
+		}
+	}
+	for _, want := range []string{`"encryptionKeyCount":2`, `"internalApiKeySet":true`, `"smtpPasswordSet":true`, `"readTimeout":"15s"`} {
+		if !strings.Contains(body, want) {
+			t.Errorf("redacted config missing %s: %s", want, body)
+		}
+	}
+}
@@ -7,7 +7,9 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/config"
 	_ "modernc.org/sqlite" // SQLite driver
 )
 
@@ -20,8 +22,15 @@ func EnsureDir(dbPath string) error {
 	return os.MkdirAll(dir, 0o750)
 }
 
-// Open opens a connection to the SQLite database.
+// Open opens a connection to the SQLite database with the default pragmas.
 func Open(dbPath string) (*sql.DB, error) {
+	cfg := config.Defaults().Database
+	cfg.Path = dbPath
+	return OpenWithConfig(cfg)
+}
+
+// OpenWithConfig opens a connection to the SQLite database at cfg.Path with the pragmas in cfg.
+func OpenWithConfig(cfg config.DatabaseConfig) (*sql.DB, error) {
 	// Build DSN with per-connection PRAGMA parameters.
 	//
 	// Critically, PRAGMAs must be in the DSN rather than executed via db.Exec
@@ -34,23 +43,23 @@ func Open(dbPath string) (*sql.DB, error) {
 	// connection and always sorts busy_timeout first (before journal_mode, etc.)
 	// so the timeout is active before any locking occurs.
 	//
-	// Parameters:
+	// Parameters (defaults in parentheses):
 	//   _texttotime=1          — auto-parse DATE/DATETIME TEXT columns to time.Time (v1.46.0+)
-	//   busy_timeout(5000)     — wait up to 5 s when another writer holds the lock
+	//   busy_timeout (5000)    — wait up to 5 s when another writer holds the lock
 	//   foreign_keys(on)       — enforce FK constraints (off by default in SQLite)
-	//   journal_mode(WAL)      — WAL allows concurrent readers alongside a writer
-	//   wal_autocheckpoint(100)— checkpoint every ~400 KB instead of the default 4 MB;
+	//   journal_mode (WAL)     — WAL allows concurrent readers alongside a writer
+	//   wal_autocheckpoint (100) — checkpoint every ~400 KB instead of the default 4 MB;
 	//                            keeps the WAL file small and changes visible sooner
 	sep := "?"
-	if strings.Contains(dbPath, "?") {
+	if strings.Contains(cfg.Path, "?") {
 		sep = "&"
 	}
-	dsn := dbPath + sep +
+	dsn := cfg.Path + sep +
 		"_texttotime=1" +
-		"&_pragma=busy_timeout(5000)" +
+		fmt.Sprintf("&_pragma=busy_timeout(%d)", time.Duration(cfg.BusyTimeout).Milliseconds()) +
 		"&_pragma=foreign_keys(on)" +
-		"&_pragma=journal_mode(WAL)" +
-		"&_pragma=wal_autocheckpoint(100)"
+		fmt.Sprintf("&_pragma=journal_mode(%s)", cfg.JournalMode) +
+		fmt.Sprintf("&_pragma=wal_autocheckpoint(%d)", cfg.WALAutocheckpoint)
 
 	db, err := sql.Open("sqlite", dsn)
 	if err != nil {
@@ -0,0 +1,159 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// Backup writes a consistent, compacted copy of db to destPath using VACUUM INTO.
+// It is safe to run while the server is writing; the copy reflects one point in time.
+// destPath must not exist yet.
+func Backup(db *sql.DB, destPath string) error {
+	if _, err := os.Stat(destPath); err == nil {
+		return fmt.Errorf("backup destination %s already exists", destPath)
+	}
+	if err := EnsureDir(destPath); err != nil {
+		return fmt.Errorf("create backup directory: %w", err)
+	}
+	if _, err := db.Exec("VACUUM INTO ?", destPath); err != nil {
+		return fmt.Errorf("vacuum into %s: %w", destPath, err)
+	}
+	return nil
+}
+
+// Restore replaces the database at dbPath with the backup at srcPath. The backup must pass
+// SQLite's quick_check first. The copy is written next to dbPath and renamed into place,
+// and any WAL and shared-memory files of the old database are removed so they cannot be
+// replayed onto the restored file. Nothing may have the database open while it runs.
+func Restore(srcPath, dbPath string) error {
+	if err := checkBackup(srcPath); err != nil {
+		return err
+	}
+	if err := EnsureDir(dbPath); err != nil {
+		return fmt.Errorf("create database directory: %w", err)
+	}
+
+	tmpPath := dbPath + ".restore"
+	defer func() { _ = os.Remove(tmpPath) }() //nolint:errcheck // The file is already gone after a successful rename.
+	if err := copyFile(srcPath, tmpPath); err != nil {
+		return err
+	}
+	for _, suffix := range []string{"-wal", "-shm"} {
+		if err := os.Remove(dbPath + suffix); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("remove %s: %w", filepath.Base(dbPath+suffix), err)
+		}
+	}
+	if err := os.Rename(tmpPath, dbPath); err != nil {
+		return fmt.Errorf("replace database: %w", err)
+	}
+	return nil
+}
+
+// checkBackup opens srcPath read-only and runs a quick_check against it.
+func checkBackup(srcPath string) error {
+	if _, err := os.Stat(srcPath); err != nil {
+		return fmt.Errorf("open backup: %w", err)
+	}
+	db, err := sql.Open("sqlite", "file:"+srcPath+"?mode=ro")
+	if err != nil {
+		return fmt.Errorf("open backup: %w", err)
+	}
+	defer db.Close()
+
+	var result string
+	if err := db.QueryRow("PRAGMA quick_check").Scan(&result); err != nil {
+		return fmt.Errorf("check backup %s: %w", srcPath, err)
+	}
+	if result != "ok" {
+		return fmt.Errorf("backup %s is corrupt: %s", srcPath, result)
+	}
+	return nil
+}
+
+// copyFile copies src to dst and syncs dst to disk.
+func copyFile(src, dst string) error {
+	in, err := os.Open(src) //nolint:gosec // Path is supplied by the operator running the restore.
+	if err != nil {
+		return fmt.Errorf("open backup: %w", err)
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600) //nolint:gosec // Path is derived from the configured database path.
+	if err != nil {
+		return fmt.Errorf("create %s: %w", dst, err)
+	}
+	defer out.Close()
+	if _, err := io.Copy(out, in); err != nil {
+		return fmt.Errorf("copy backup: %w", err)
+	}
+	if err := out.Sync(); err != nil {
+		return fmt.Errorf("sync %s: %w", dst, err)
+	}
+	return nil
+}
+
+// ForeignKeyViolation is one row whose foreign key points at a missing parent row.
+type ForeignKeyViolation struct {
+	Table  string
+	RowID  int64
+	Parent string
+}
+
+// IntegrityReport is the outcome of CheckIntegrity.
+type IntegrityReport struct {
+	Problems    []string // integrity_check messages; empty when the file is sound
+	ForeignKeys []ForeignKeyViolation
+}
+
+// OK reports whether the check found no problems.
+func (r IntegrityReport) OK() bool {
+	return len(r.Problems) == 0 && len(r.ForeignKeys) == 0
+}
+
+// CheckIntegrity runs SQLite's integrity_check and foreign_key_check against db.
+func CheckIntegrity(db *sql.DB) (IntegrityReport, error) {
+	var report IntegrityReport
+
+	rows, err := db.Query("PRAGMA integrity_check")
+	if err != nil {
+		return report, fmt.Errorf("integrity check: %w", err)
+	}
+	for rows.Next() {
+		var msg string
+		if err := rows.Scan(&msg); err != nil {
+			rows.Close()
+			return report, fmt.Errorf("scan integrity check: %w", err)
+		}
+		if msg != "ok" {
+			report.Problems = append(report.Problems, msg)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return report, fmt.Errorf("integrity check: %w", err)
+	}
+
+	rows, err = db.Query("PRAGMA foreign_key_check")
+	if err != nil {
+		return report, fmt.Errorf("foreign key check: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var v ForeignKeyViolation
+		var rowID sql.NullInt64
+		var fkid int
+		if err := rows.Scan(&v.Table, &rowID, &v.Parent, &fkid); err != nil {
+			return report, fmt.Errorf("scan foreign key check: %w", err)
+		}
+		v.RowID = rowID.Int64
+		report.ForeignKeys = append(report.ForeignKeys, v)
+	}
+	if err := rows.Err(); err != nil {
+		return report, fmt.Errorf("foreign key check: %w", err)
+	}
+	return report, nil
+}
@@ -0,0 +1,122 @@
+package database_test
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/database"
+)
+
+// openFileDB opens a migrated database file in a temp directory.
+func openFileDB(t *testing.T) (*sql.DB, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "test.db")
+	db, err := database.Open(path)
+	if err != nil {
+		t.Fatalf("Open() error: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if err := database.Migrate(db); err != nil {
+		t.Fatalf("Migrate() error: %v", err)
+	}
+	return db, path
+}
+
+func TestBackupAndRestore(t *testing.T) {
+	db, path := openFileDB(t)
+	if _, err := db.Exec(`INSERT INTO system_setting (id, "key", value) VALUES ('backup-test', 'BACKUP_TEST', 'before')`); err != nil {
+		t.Fatalf("insert setting: %v", err)
+	}
+
+	backupPath := filepath.Join(t.TempDir(), "backups", "backup.db")
+	if err := database.Backup(db, backupPath); err != nil {
+		t.Fatalf("Backup() error: %v", err)
+	}
+	if err := database.Backup(db, backupPath); err == nil {
+		t.Error("expected Backup() to refuse an existing destination")
+	}
+
+	if _, err := db.Exec(`UPDATE system_setting SET value = 'after' WHERE "key" = 'BACKUP_TEST'`); err != nil {
+		t.Fatalf("update setting: %v", err)
+	}
+	db.Close()
+
+	if err := database.Restore(backupPath, path); err != nil {
+		t.Fatalf("Restore() error: %v", err)
+	}
+	if _, err := os.Stat(path + ".restore"); !os.IsNotExist(err) {
+		t.Error("expected the temporary restore file to be removed")
+	}
+
+	restored, err := database.Open(path)
+	if err != nil {
+		t.Fatalf("Open() restored error: %v", err)
+	}
+	defer restored.Close()
+
+	var value string
+	if err := restored.QueryRow(`SELECT value FROM system_setting WHERE "key" = 'BACKUP_TEST'`).Scan(&value); err != nil {
+		t.Fatalf("read setting: %v", err)
+	}
+	if value != "before" {
+		t.Errorf("expected restored value %q, got %q", "before", value)
+	}
+}
+
+func TestRestore_RejectsInvalidBackup(t *testing.T) {
+	_, path := openFileDB(t)
+
+	missing := filepath.Join(t.TempDir(), "missing.db")
+	if err := database.Restore(missing, path); err == nil {
+		t.Error("expected error for a missing backup")
+	}
+
+	garbage := filepath.Join(t.TempDir(), "garbage.db")
+	if err := os.WriteFile(garbage, []byte("this is not a database"), 0o600); err != nil {
+		t.Fatalf("write garbage: %v", err)
+	}
+	if err := database.Restore(garbage, path); err == nil {
+		t.Error("expected error for a file that is not a database")
+	}
+}
+
+func TestCheckIntegrity(t *testing.T) {
+	t.Run("clean database", func(t *testing.T) {
+		db, _ := openFileDB(t)
+
+		report, err := database.CheckIntegrity(db)
+		if err != nil {
+			t.Fatalf("CheckIntegrity() error: %v", err)
+		}
+		if !report.OK() {
+			t.Errorf("expected a clean report, got %+v", report)
+		}
+	})
+
+	t.Run("reports foreign key violations", func(t *testing.T) {
+		db, _ := openFileDB(t)
+
+		db.SetMaxOpenConns(1) // Keep the pragma on the connection used below.
+		if _, err := db.Exec(`PRAGMA foreign_keys = OFF`); err != nil {
+			t.Fatalf("disable foreign keys: %v", err)
+		}
+		if _, err := db.Exec(`INSERT INTO portfolio_fund (id, portfolio_id, fund_id) VALUES ('pf-1', 'missing-portfolio', 'missing-fund')`); err != nil {
+			t.Fatalf("insert orphan: %v", err)
+		}
+
+		report, err := database.CheckIntegrity(db)
+		if err != nil {
+			t.Fatalf("CheckIntegrity() error: %v", err)
+		}
+		if report.OK() {
+			t.Fatal("expected foreign key violations")
+		}
+		for _, v := range report.ForeignKeys {
+			if v.Table != "portfolio_fund" {
+				t.Errorf("unexpected violation in %s", v.Table)
+			}
+		}
+	})
+}
@@ -5,8 +5,10 @@ import (
 	"embed"
 	"fmt"
 	"io/fs"
+	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/pressly/goose/v3"
 )
@@ -21,9 +23,8 @@ var goldenSchema string
 // On a fresh DB this creates the full schema.
 // On an existing DB it applies only new migrations.
 func Migrate(db *sql.DB) error {
-	goose.SetBaseFS(migrations)
-	if err := goose.SetDialect("sqlite"); err != nil {
-		return fmt.Errorf("set goose dialect: %w", err)
+	if err := setupGoose(); err != nil {
+		return err
 	}
 	if err := goose.Up(db, "migrations"); err != nil {
 		return fmt.Errorf("run migrations: %w", err)
@@ -31,6 +32,85 @@ func Migrate(db *sql.DB) error {
 	return nil
 }
 
+// MigrateDown rolls back the most recently applied migration.
+func MigrateDown(db *sql.DB) error {
+	if err := setupGoose(); err != nil {
+		return err
+	}
+	if err := goose.Down(db, "migrations"); err != nil {
+		return fmt.Errorf("roll back migration: %w", err)
+	}
+	return nil
+}
+
+// MigrationState describes one known migration and whether it is applied to the database.
+type MigrationState struct {
+	Version   int64
+	Name      string
+	Applied   bool
+	AppliedAt time.Time // Zero when not applied
+}
+
+// MigrationStatus lists every embedded SQL and registered Go migration in version order,
+// marking the ones applied to db. On a database goose has never touched, nothing is applied.
+func MigrationStatus(db *sql.DB) ([]MigrationState, error) {
+	if err := setupGoose(); err != nil {
+		return nil, err
+	}
+	known, err := goose.CollectMigrations("migrations", 0, goose.MaxVersion)
+	if err != nil {
+		return nil, fmt.Errorf("collect migrations: %w", err)
+	}
+
+	// goose appends a row per up and down; the newest row for a version is its state.
+	applied := make(map[int64]time.Time)
+	rows, err := db.Query("SELECT version_id, is_applied, tstamp FROM goose_db_version ORDER BY id DESC")
+	if err == nil {
+		defer rows.Close()
+		seen := make(map[int64]bool)
+		for rows.Next() {
+			var version int64
+			var isApplied bool
+			var tstamp sql.NullTime
+			if err := rows.Scan(&version, &isApplied, &tstamp); err != nil {
+				return nil, fmt.Errorf("scan migration version: %w", err)
+			}
+			if seen[version] {
+				continue
+			}
+			seen[version] = true
+			if isApplied {
+				applied[version] = tstamp.Time
+			}
+		}
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("read migration versions: %w", err)
+		}
+	}
+	// On error the version table does not exist yet and nothing is applied.
+
+	states := make([]MigrationState, len(known))
+	for i, m := range known {
+		at, ok := applied[m.Version]
+		states[i] = MigrationState{
+			Version:   m.Version,
+			Name:      filepath.Base(m.Source),
+			Applied:   ok,
+			AppliedAt: at,
+		}
+	}
+	return states, nil
+}
+
+// setupGoose points goose at the embedded migrations and the SQLite dialect.
+func setupGoose() error {
+	goose.SetBaseFS(migrations)
+	if err := goose.SetDialect("sqlite"); err != nil {
+		return fmt.Errorf("set goose dialect: %w", err)
+	}
+	return nil
+}
+
 // ApplyGoldenSchema creates all tables and indexes by executing the golden schema DDL directly.
 // This is much faster than running goose migrations (no goose overhead, no version tracking)
 // and is intended for test databases that need a fresh schema quickly.
@@ -45,7 +125,7 @@ func ApplyGoldenSchema(db *sql.DB) error {
 		if stmt == "" {
 			continue
 		}
-		if strings.HasPrefix(stmt, "CREATE INDEX") {
+		if strings.HasPrefix(stmt, "CREATE INDEX") || strings.HasPrefix(stmt, "CREATE UNIQUE INDEX") {
 			indexes = append(indexes, stmt)
 		} else if strings.HasPrefix(stmt, "CREATE TABLE sqlite_") {
 			// Skip internal SQLite tables (e.g. sqlite_sequence) — created automatically
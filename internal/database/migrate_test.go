@@ -65,6 +65,11 @@ func TestMigrate_NewDatabase(t *testing.T) {
 	}
 
 	expectedTables := []string{
+		"alert",
+		"alert_rule",
+		"allocation_target",
+		"api_token",
+		"audit_event",
 		"dividend",
 		"exchange_rate",
 		"fund",
@@ -75,13 +80,26 @@ func TestMigrate_NewDatabase(t *testing.T) {
 		"ibkr_import_cache",
 		"ibkr_transaction",
 		"ibkr_transaction_allocation",
+		"ibkr_withholding_tax",
+		"investment_plan",
+		"job",
 		"log",
+		"materialized_regen_queue",
 		"portfolio",
 		"portfolio_fund",
+		"portfolio_history_materialized",
+		"portfolio_history_rollup",
+		"portfolio_share",
 		"realized_gain_loss",
 		"symbol_info",
 		"system_setting",
 		"transaction",
+		"trash",
+		"user_account",
+		"user_session",
+		"webhook",
+		"webhook_delivery",
+		"withholding_tax_rate",
 	}
 
 	for _, table := range expectedTables {
@@ -192,3 +210,85 @@ func TestMigrate_SchemaMatchesGoldenFile(t *testing.T) {
 		t.Error("schema drift detected; run `go test ./internal/database/... -update-golden` to regenerate")
 	}
 }
+
+// TestMigrationStatus verifies that every known migration is listed and marked applied after Migrate.
+func TestMigrationStatus(t *testing.T) {
+	db := setupFreshDB(t)
+
+	before, err := database.MigrationStatus(db)
+	if err != nil {
+		t.Fatalf("MigrationStatus() before migrate error: %v", err)
+	}
+	if len(before) == 0 {
+		t.Fatal("expected known migrations to be listed")
+	}
+	for _, s := range before {
+		if s.Applied {
+			t.Fatalf("migration %d reported applied on a fresh database", s.Version)
+		}
+	}
+
+	if err := database.Migrate(db); err != nil {
+		t.Fatalf("Migrate() error: %v", err)
+	}
+
+	after, err := database.MigrationStatus(db)
+	if err != nil {
+		t.Fatalf("MigrationStatus() error: %v", err)
+	}
+	if len(after) != len(before) {
+		t.Fatalf("expected %d migrations, got %d", len(before), len(after))
+	}
+	for i, s := range after {
+		if !s.Applied {
+			t.Errorf("migration %d (%s) not applied", s.Version, s.Name)
+		}
+		if i > 0 && s.Version <= after[i-1].Version {
+			t.Errorf("migrations not in version order at %d", s.Version)
+		}
+	}
+}
+
+// TestMigrateDown verifies that MigrateDown rolls back only the latest migration and Migrate reapplies it.
+func TestMigrateDown(t *testing.T) {
+	db := setupFreshDB(t)
+
+	if err := database.Migrate(db); err != nil {
+		t.Fatalf("Migrate() error: %v", err)
+	}
+	if err := database.MigrateDown(db); err != nil {
+		t.Fatalf("MigrateDown() error: %v", err)
+	}
+
+	states, err := database.MigrationStatus(db)
+	if err != nil {
+		t.Fatalf("MigrationStatus() error: %v", err)
+	}
+	last := states[len(states)-1]
+	if last.Applied {
+		t.Errorf("expected latest migration %d to be rolled back", last.Version)
+	}
+	if !states[len(states)-2].Applied {
+		t.Error("expected earlier migrations to stay applied")
+	}
+
+	pending, err := database.HasPendingMigrations(db)
+	if err != nil {
+		t.Fatalf("HasPendingMigrations() error: %v", err)
+	}
+	if !pending {
+		t.Error("expected pending migrations after rollback")
+	}
+
+	if err := database.Migrate(db); err != nil {
+		t.Fatalf("Migrate() after rollback error: %v", err)
+	}
+	actual := dumpSchema(t, db)
+	expected, err := os.ReadFile("testdata/golden_schema.sql")
+	if err != nil {
+		t.Fatalf("read golden file: %v", err)
+	}
+	if actual != string(expected) {
+		t.Error("schema after down and up does not match the golden file")
+	}
+}
@@ -0,0 +1,13 @@
+-- +goose Up
+
+-- Generalise the IBKR inbox into a source-agnostic staging inbox.
+-- Existing rows all originate from the IBKR Flex importer.
+ALTER TABLE ibkr_transaction ADD COLUMN source VARCHAR(20) NOT NULL DEFAULT 'ibkr';
+
+CREATE INDEX IF NOT EXISTS ix_ibkr_transaction_source ON ibkr_transaction(source);
+
+-- +goose Down
+
+DROP INDEX IF EXISTS ix_ibkr_transaction_source;
+
+ALTER TABLE ibkr_transaction DROP COLUMN source;
@@ -0,0 +1,28 @@
+-- +goose Up
+
+-- Audit trail of data mutations. Rows are written by the service layer inside the
+-- same database transaction as the change they describe, so an audit event exists
+-- if and only if the mutation was committed.
+CREATE TABLE IF NOT EXISTS audit_event (
+    id VARCHAR(36) NOT NULL PRIMARY KEY,
+    timestamp DATETIME NOT NULL,
+    entity_type VARCHAR(30) NOT NULL,
+    entity_id VARCHAR(36) NOT NULL,
+    action VARCHAR(20) NOT NULL,
+    before_state TEXT,
+    after_state TEXT,
+    request_id VARCHAR(36),
+    ip_address VARCHAR(45),
+    user_agent VARCHAR(255)
+);
+
+CREATE INDEX IF NOT EXISTS ix_audit_event_timestamp_id ON audit_event(timestamp, id);
+CREATE INDEX IF NOT EXISTS ix_audit_event_entity ON audit_event(entity_type, entity_id);
+CREATE INDEX IF NOT EXISTS ix_audit_event_request_id ON audit_event(request_id);
+
+-- +goose Down
+
+DROP INDEX IF EXISTS ix_audit_event_request_id;
+DROP INDEX IF EXISTS ix_audit_event_entity;
+DROP INDEX IF EXISTS ix_audit_event_timestamp_id;
+DROP TABLE IF EXISTS audit_event;
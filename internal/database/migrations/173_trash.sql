@@ -0,0 +1,26 @@
+-- +goose Up
+
+-- Trash for soft-deleted portfolios, funds, transactions and dividends.
+-- payload holds a row-level snapshot of the deleted entity and every row its
+-- deletion cascaded to, in foreign-key insertion order, so a restore can
+-- re-insert them unchanged. Materialized history is not stored; it is
+-- regenerated on restore.
+CREATE TABLE IF NOT EXISTS trash (
+    id VARCHAR(36) NOT NULL PRIMARY KEY,
+    entity_type VARCHAR(30) NOT NULL,
+    entity_id VARCHAR(36) NOT NULL,
+    label VARCHAR(255) NOT NULL,
+    deleted_at DATETIME NOT NULL,
+    payload TEXT NOT NULL,
+    request_id VARCHAR(36)
+);
+
+CREATE INDEX IF NOT EXISTS ix_trash_deleted_at ON trash(deleted_at);
+CREATE INDEX IF NOT EXISTS ix_trash_entity ON trash(entity_type, entity_id);
+
+-- +goose Down
+
+DROP INDEX IF EXISTS ix_trash_entity;
+DROP INDEX IF EXISTS ix_trash_deleted_at;
+
+DROP TABLE IF EXISTS trash;
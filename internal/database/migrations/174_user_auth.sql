@@ -0,0 +1,65 @@
+-- +goose Up
+
+-- User accounts. Passwords are stored as bcrypt hashes.
+CREATE TABLE IF NOT EXISTS user_account (
+    id VARCHAR(36) NOT NULL PRIMARY KEY,
+    username VARCHAR(100) NOT NULL UNIQUE,
+    password_hash VARCHAR(255) NOT NULL,
+    is_admin BOOLEAN NOT NULL DEFAULT 0,
+    created_at DATETIME NOT NULL,
+    last_login_at DATETIME
+);
+
+-- Login sessions. Only the SHA-256 hash of the bearer token is stored.
+CREATE TABLE IF NOT EXISTS user_session (
+    id VARCHAR(36) NOT NULL PRIMARY KEY,
+    user_id VARCHAR(36) NOT NULL,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    created_at DATETIME NOT NULL,
+    expires_at DATETIME NOT NULL,
+    FOREIGN KEY (user_id) REFERENCES user_account(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS ix_user_session_user_id ON user_session(user_id);
+CREATE INDEX IF NOT EXISTS ix_user_session_expires_at ON user_session(expires_at);
+
+-- Portfolio ownership. Existing portfolios have no owner until the first
+-- account is created, which claims them. No foreign key so the column can be
+-- dropped again; deleting a user hands their portfolios to the deleting admin.
+ALTER TABLE portfolio ADD COLUMN owner_id VARCHAR(36);
+
+CREATE INDEX IF NOT EXISTS ix_portfolio_owner_id ON portfolio(owner_id);
+
+-- Read or write access to a portfolio granted to users other than its owner.
+CREATE TABLE IF NOT EXISTS portfolio_share (
+    portfolio_id VARCHAR(36) NOT NULL,
+    user_id VARCHAR(36) NOT NULL,
+    access VARCHAR(5) NOT NULL,
+    created_at DATETIME NOT NULL,
+    PRIMARY KEY (portfolio_id, user_id),
+    FOREIGN KEY (portfolio_id) REFERENCES portfolio(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES user_account(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS ix_portfolio_share_user_id ON portfolio_share(user_id);
+
+ALTER TABLE audit_event ADD COLUMN user_id VARCHAR(36);
+
+CREATE INDEX IF NOT EXISTS ix_audit_event_user_id ON audit_event(user_id);
+
+-- +goose Down
+
+DROP INDEX IF EXISTS ix_audit_event_user_id;
+ALTER TABLE audit_event DROP COLUMN user_id;
+
+DROP INDEX IF EXISTS ix_portfolio_share_user_id;
+DROP TABLE IF EXISTS portfolio_share;
+
+DROP INDEX IF EXISTS ix_portfolio_owner_id;
+ALTER TABLE portfolio DROP COLUMN owner_id;
+
+DROP INDEX IF EXISTS ix_user_session_expires_at;
+DROP INDEX IF EXISTS ix_user_session_user_id;
+DROP TABLE IF EXISTS user_session;
+
+DROP TABLE IF EXISTS user_account;
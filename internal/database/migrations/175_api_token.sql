@@ -0,0 +1,23 @@
+-- +goose Up
+
+-- Personal API tokens for scripts and dashboards. Only the SHA-256 hash of the
+-- token is stored; scopes is a space-separated list of scope names.
+CREATE TABLE IF NOT EXISTS api_token (
+    id VARCHAR(36) NOT NULL PRIMARY KEY,
+    user_id VARCHAR(36) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    scopes TEXT NOT NULL,
+    created_at DATETIME NOT NULL,
+    expires_at DATETIME,
+    last_used_at DATETIME,
+    revoked_at DATETIME,
+    FOREIGN KEY (user_id) REFERENCES user_account(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS ix_api_token_user_id ON api_token(user_id);
+
+-- +goose Down
+
+DROP INDEX IF EXISTS ix_api_token_user_id;
+DROP TABLE IF EXISTS api_token;
@@ -0,0 +1,30 @@
+-- +goose Up
+
+-- Background jobs (scheduled tasks, materialized regeneration, long-running API
+-- operations). params and result are JSON; progress is a percentage. retry_of
+-- points at the failed job a retry was created from.
+CREATE TABLE IF NOT EXISTS job (
+    id VARCHAR(36) NOT NULL PRIMARY KEY,
+    type VARCHAR(50) NOT NULL,
+    params TEXT,
+    status VARCHAR(20) NOT NULL,
+    progress INTEGER NOT NULL DEFAULT 0,
+    result TEXT,
+    error TEXT,
+    retry_of VARCHAR(36),
+    user_id VARCHAR(36),
+    request_id VARCHAR(36),
+    created_at DATETIME NOT NULL,
+    started_at DATETIME,
+    finished_at DATETIME,
+    FOREIGN KEY (retry_of) REFERENCES job(id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS ix_job_created_at ON job(created_at);
+CREATE INDEX IF NOT EXISTS ix_job_status ON job(status);
+
+-- +goose Down
+
+DROP INDEX IF EXISTS ix_job_status;
+DROP INDEX IF EXISTS ix_job_created_at;
+DROP TABLE IF EXISTS job;
@@ -0,0 +1,23 @@
+-- +goose Up
+
+-- Pending materialized history regenerations, one row per portfolio. start_date is
+-- the earliest dirty date; later requests for the same portfolio lower it and bump
+-- version, so the worker only removes a row when nothing was requested while it ran.
+-- Failed attempts are retried with backoff from next_attempt_at.
+CREATE TABLE IF NOT EXISTS materialized_regen_queue (
+    portfolio_id VARCHAR(36) NOT NULL PRIMARY KEY,
+    start_date DATE NOT NULL,
+    version INTEGER NOT NULL DEFAULT 1,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    requested_at DATETIME NOT NULL,
+    next_attempt_at DATETIME NOT NULL,
+    FOREIGN KEY (portfolio_id) REFERENCES portfolio(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS ix_materialized_regen_queue_next_attempt_at ON materialized_regen_queue(next_attempt_at);
+
+-- +goose Down
+
+DROP INDEX IF EXISTS ix_materialized_regen_queue_next_attempt_at;
+DROP TABLE IF EXISTS materialized_regen_queue;
@@ -0,0 +1,52 @@
+-- +goose Up
+
+-- Scope pending regenerations to a single portfolio fund. portfolio_fund_id is empty
+-- when every fund in the portfolio is dirty (e.g. after an import or a staleness check).
+-- SQLite cannot change a primary key in place, so the table is rebuilt; queued
+-- entries carry over as whole-portfolio regenerations.
+CREATE TABLE materialized_regen_queue_new (
+    portfolio_id VARCHAR(36) NOT NULL,
+    portfolio_fund_id VARCHAR(36) NOT NULL DEFAULT '',
+    start_date DATE NOT NULL,
+    version INTEGER NOT NULL DEFAULT 1,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    requested_at DATETIME NOT NULL,
+    next_attempt_at DATETIME NOT NULL,
+    PRIMARY KEY (portfolio_id, portfolio_fund_id),
+    FOREIGN KEY (portfolio_id) REFERENCES portfolio(id) ON DELETE CASCADE
+);
+
+INSERT INTO materialized_regen_queue_new (portfolio_id, start_date, version, attempts, last_error, requested_at, next_attempt_at)
+SELECT portfolio_id, start_date, version, attempts, last_error, requested_at, next_attempt_at
+FROM materialized_regen_queue;
+
+DROP INDEX IF EXISTS ix_materialized_regen_queue_next_attempt_at;
+DROP TABLE materialized_regen_queue;
+ALTER TABLE materialized_regen_queue_new RENAME TO materialized_regen_queue;
+
+CREATE INDEX IF NOT EXISTS ix_materialized_regen_queue_next_attempt_at ON materialized_regen_queue(next_attempt_at);
+
+-- +goose Down
+
+CREATE TABLE materialized_regen_queue_old (
+    portfolio_id VARCHAR(36) NOT NULL PRIMARY KEY,
+    start_date DATE NOT NULL,
+    version INTEGER NOT NULL DEFAULT 1,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    requested_at DATETIME NOT NULL,
+    next_attempt_at DATETIME NOT NULL,
+    FOREIGN KEY (portfolio_id) REFERENCES portfolio(id) ON DELETE CASCADE
+);
+
+INSERT INTO materialized_regen_queue_old (portfolio_id, start_date, version, attempts, last_error, requested_at, next_attempt_at)
+SELECT portfolio_id, MIN(start_date), MAX(version), MAX(attempts), MAX(last_error), MAX(requested_at), MIN(next_attempt_at)
+FROM materialized_regen_queue
+GROUP BY portfolio_id;
+
+DROP INDEX IF EXISTS ix_materialized_regen_queue_next_attempt_at;
+DROP TABLE materialized_regen_queue;
+ALTER TABLE materialized_regen_queue_old RENAME TO materialized_regen_queue;
+
+CREATE INDEX IF NOT EXISTS ix_materialized_regen_queue_next_attempt_at ON materialized_regen_queue(next_attempt_at);
@@ -0,0 +1,79 @@
+-- +goose Up
+
+-- Daily portfolio totals, summed from fund_history_materialized. Rewritten from the
+-- regeneration start date in the same transaction as the fund rows.
+CREATE TABLE IF NOT EXISTS portfolio_history_materialized (
+    portfolio_id VARCHAR(36) NOT NULL,
+    date VARCHAR(10) NOT NULL,
+    value FLOAT NOT NULL,
+    cost FLOAT NOT NULL,
+    realized_gain FLOAT NOT NULL,
+    unrealized_gain FLOAT NOT NULL,
+    total_gain_loss FLOAT NOT NULL,
+    total_dividends FLOAT NOT NULL,
+    total_sale_proceeds FLOAT NOT NULL,
+    total_original_cost FLOAT NOT NULL,
+    calculated_at DATETIME NOT NULL,
+    PRIMARY KEY (portfolio_id, date),
+    FOREIGN KEY (portfolio_id) REFERENCES portfolio(id) ON DELETE CASCADE
+);
+
+-- Weekly (ISO weeks, starting Monday) and monthly rollups of the daily totals. Each
+-- row is the last daily snapshot within its period; date is that snapshot's date.
+CREATE TABLE IF NOT EXISTS portfolio_history_rollup (
+    portfolio_id VARCHAR(36) NOT NULL,
+    period VARCHAR(5) NOT NULL,
+    period_start VARCHAR(10) NOT NULL,
+    date VARCHAR(10) NOT NULL,
+    value FLOAT NOT NULL,
+    cost FLOAT NOT NULL,
+    realized_gain FLOAT NOT NULL,
+    unrealized_gain FLOAT NOT NULL,
+    total_gain_loss FLOAT NOT NULL,
+    total_dividends FLOAT NOT NULL,
+    total_sale_proceeds FLOAT NOT NULL,
+    total_original_cost FLOAT NOT NULL,
+    calculated_at DATETIME NOT NULL,
+    PRIMARY KEY (portfolio_id, period, period_start),
+    FOREIGN KEY (portfolio_id) REFERENCES portfolio(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS ix_portfolio_history_rollup_date ON portfolio_history_rollup(portfolio_id, period, date);
+
+-- Backfill from the existing fund rows.
+INSERT INTO portfolio_history_materialized (portfolio_id, date, value, cost, realized_gain, unrealized_gain,
+    total_gain_loss, total_dividends, total_sale_proceeds, total_original_cost, calculated_at)
+SELECT pf.portfolio_id, fh.date, SUM(fh.value), SUM(fh.cost), SUM(fh.realized_gain), SUM(fh.unrealized_gain),
+    SUM(fh.unrealized_gain) + SUM(fh.realized_gain), SUM(fh.dividends), SUM(fh.sale_proceeds),
+    SUM(fh.original_cost), MAX(fh.calculated_at)
+FROM fund_history_materialized fh
+JOIN portfolio_fund pf ON fh.portfolio_fund_id = pf.id
+GROUP BY pf.portfolio_id, fh.date;
+
+INSERT INTO portfolio_history_rollup (portfolio_id, period, period_start, date, value, cost, realized_gain,
+    unrealized_gain, total_gain_loss, total_dividends, total_sale_proceeds, total_original_cost, calculated_at)
+SELECT s.portfolio_id, 'week', p.period_start, s.date, s.value, s.cost, s.realized_gain, s.unrealized_gain,
+    s.total_gain_loss, s.total_dividends, s.total_sale_proceeds, s.total_original_cost, s.calculated_at
+FROM portfolio_history_materialized s
+JOIN (
+    SELECT portfolio_id, date(date, 'weekday 0', '-6 days') AS period_start, MAX(date) AS date
+    FROM portfolio_history_materialized
+    GROUP BY portfolio_id, period_start
+) p ON p.portfolio_id = s.portfolio_id AND p.date = s.date;
+
+INSERT INTO portfolio_history_rollup (portfolio_id, period, period_start, date, value, cost, realized_gain,
+    unrealized_gain, total_gain_loss, total_dividends, total_sale_proceeds, total_original_cost, calculated_at)
+SELECT s.portfolio_id, 'month', p.period_start, s.date, s.value, s.cost, s.realized_gain, s.unrealized_gain,
+    s.total_gain_loss, s.total_dividends, s.total_sale_proceeds, s.total_original_cost, s.calculated_at
+FROM portfolio_history_materialized s
+JOIN (
+    SELECT portfolio_id, strftime('%Y-%m-01', date) AS period_start, MAX(date) AS date
+    FROM portfolio_history_materialized
+    GROUP BY portfolio_id, period_start
+) p ON p.portfolio_id = s.portfolio_id AND p.date = s.date;
+
+-- +goose Down
+
+DROP INDEX IF EXISTS ix_portfolio_history_rollup_date;
+DROP TABLE IF EXISTS portfolio_history_rollup;
+DROP TABLE IF EXISTS portfolio_history_materialized;
@@ -0,0 +1,44 @@
+-- +goose Up
+
+-- Webhook subscriptions. event_types is a space-separated list of event names; secret
+-- signs each delivery with HMAC-SHA256 and is needed in plain text to do so.
+CREATE TABLE IF NOT EXISTS webhook (
+    id VARCHAR(36) NOT NULL PRIMARY KEY,
+    url TEXT NOT NULL,
+    event_types TEXT NOT NULL,
+    secret VARCHAR(128) NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT 1,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL
+);
+
+-- Webhook deliveries, one row per event and subscribed webhook. Pending deliveries are
+-- retried with backoff from next_attempt_at. event_key, when set, makes an event
+-- deliver at most once per webhook (e.g. one value drop alert per portfolio per day).
+CREATE TABLE IF NOT EXISTS webhook_delivery (
+    id VARCHAR(36) NOT NULL PRIMARY KEY,
+    webhook_id VARCHAR(36) NOT NULL,
+    event_type VARCHAR(50) NOT NULL,
+    event_key VARCHAR(200),
+    payload TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    response_status INTEGER,
+    last_error TEXT,
+    created_at DATETIME NOT NULL,
+    next_attempt_at DATETIME,
+    delivered_at DATETIME,
+    FOREIGN KEY (webhook_id) REFERENCES webhook(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS ix_webhook_delivery_event_key ON webhook_delivery(webhook_id, event_key);
+CREATE INDEX IF NOT EXISTS ix_webhook_delivery_next_attempt_at ON webhook_delivery(status, next_attempt_at);
+CREATE INDEX IF NOT EXISTS ix_webhook_delivery_created_at ON webhook_delivery(webhook_id, created_at);
+
+-- +goose Down
+
+DROP INDEX IF EXISTS ix_webhook_delivery_created_at;
+DROP INDEX IF EXISTS ix_webhook_delivery_next_attempt_at;
+DROP INDEX IF EXISTS ix_webhook_delivery_event_key;
+DROP TABLE IF EXISTS webhook_delivery;
+DROP TABLE IF EXISTS webhook;
@@ -0,0 +1,45 @@
+-- +goose Up
+
+-- Alert rules. Which of portfolio_id, fund_id, direction and period are used depends on
+-- type; threshold is a price, a percentage or a value in the portfolio's currency.
+CREATE TABLE IF NOT EXISTS alert_rule (
+    id VARCHAR(36) NOT NULL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    type VARCHAR(30) NOT NULL,
+    portfolio_id VARCHAR(36),
+    fund_id VARCHAR(36),
+    direction VARCHAR(10),
+    period VARCHAR(10),
+    threshold REAL NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT 1,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL,
+    FOREIGN KEY (portfolio_id) REFERENCES portfolio(id) ON DELETE CASCADE,
+    FOREIGN KEY (fund_id) REFERENCES fund(id) ON DELETE CASCADE
+);
+
+-- Triggered alerts. A rule has at most one open (active or acknowledged) alert; it is
+-- resolved when the rule's condition no longer holds.
+CREATE TABLE IF NOT EXISTS alert (
+    id VARCHAR(36) NOT NULL PRIMARY KEY,
+    rule_id VARCHAR(36) NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    message TEXT NOT NULL,
+    value REAL NOT NULL,
+    triggered_at DATETIME NOT NULL,
+    acknowledged_at DATETIME,
+    resolved_at DATETIME,
+    FOREIGN KEY (rule_id) REFERENCES alert_rule(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS ix_alert_rule_portfolio_id ON alert_rule(portfolio_id);
+CREATE INDEX IF NOT EXISTS ix_alert_rule_id_status ON alert(rule_id, status);
+CREATE INDEX IF NOT EXISTS ix_alert_status_triggered_at ON alert(status, triggered_at);
+
+-- +goose Down
+
+DROP INDEX IF EXISTS ix_alert_status_triggered_at;
+DROP INDEX IF EXISTS ix_alert_rule_id_status;
+DROP INDEX IF EXISTS ix_alert_rule_portfolio_id;
+DROP TABLE IF EXISTS alert;
+DROP TABLE IF EXISTS alert_rule;
@@ -0,0 +1,27 @@
+-- +goose Up
+
+-- Target weights of a portfolio. A target is set either per fund or per asset class (the
+-- fund's investment_type), never both; a portfolio uses one kind at a time. Percentages
+-- are of the portfolio's total value; tolerance is the allowed drift in percentage points.
+CREATE TABLE IF NOT EXISTS allocation_target (
+    id VARCHAR(36) NOT NULL PRIMARY KEY,
+    portfolio_id VARCHAR(36) NOT NULL,
+    fund_id VARCHAR(36),
+    asset_class VARCHAR(5),
+    target_percent REAL NOT NULL,
+    tolerance_percent REAL NOT NULL DEFAULT 0,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL,
+    FOREIGN KEY (portfolio_id) REFERENCES portfolio(id) ON DELETE CASCADE,
+    FOREIGN KEY (fund_id) REFERENCES fund(id) ON DELETE CASCADE,
+    CHECK ((fund_id IS NULL) <> (asset_class IS NULL))
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS ux_allocation_target_portfolio_fund ON allocation_target(portfolio_id, fund_id);
+CREATE UNIQUE INDEX IF NOT EXISTS ux_allocation_target_portfolio_asset_class ON allocation_target(portfolio_id, asset_class);
+
+-- +goose Down
+
+DROP INDEX IF EXISTS ux_allocation_target_portfolio_asset_class;
+DROP INDEX IF EXISTS ux_allocation_target_portfolio_fund;
+DROP TABLE IF EXISTS allocation_target;
@@ -0,0 +1,36 @@
+-- +goose Up
+
+-- Recurring investment plans. Each run buys amount worth of the portfolio fund on
+-- day_of_month (or the month's last day) every frequency, starting from start_date's month.
+-- last_run_date is the scheduled date of the latest run that generated a transaction.
+CREATE TABLE IF NOT EXISTS investment_plan (
+    id VARCHAR(36) NOT NULL PRIMARY KEY,
+    portfolio_fund_id VARCHAR(36) NOT NULL,
+    amount REAL NOT NULL,
+    frequency VARCHAR(10) NOT NULL,
+    day_of_month INTEGER NOT NULL,
+    start_date DATE NOT NULL,
+    end_date DATE,
+    enabled BOOLEAN NOT NULL DEFAULT 1,
+    last_run_date DATE,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL,
+    FOREIGN KEY (portfolio_fund_id) REFERENCES portfolio_fund(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS ix_investment_plan_portfolio_fund_id ON investment_plan(portfolio_fund_id);
+
+-- The plan that generated a transaction. No foreign key so the column can be dropped
+-- again; deleting a plan clears it on the plan's transactions.
+ALTER TABLE "transaction" ADD COLUMN investment_plan_id VARCHAR(36);
+
+CREATE INDEX IF NOT EXISTS ix_transaction_investment_plan_id ON "transaction"(investment_plan_id);
+
+-- +goose Down
+
+DROP INDEX IF EXISTS ix_transaction_investment_plan_id;
+
+ALTER TABLE "transaction" DROP COLUMN investment_plan_id;
+
+DROP INDEX IF EXISTS ix_investment_plan_portfolio_fund_id;
+DROP TABLE IF EXISTS investment_plan;
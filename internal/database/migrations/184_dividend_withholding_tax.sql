@@ -0,0 +1,57 @@
+-- +goose Up
+
+-- Tax withheld at source on a dividend, in the fund's currency. total_amount stays the
+-- gross amount; the net amount is total_amount - withholding_tax. withholding_source
+-- records where the amount came from: DEFAULT (the domicile's treaty rate), MANUAL or IBKR.
+-- Existing dividends are kept as MANUAL with nothing withheld.
+ALTER TABLE dividend ADD COLUMN withholding_tax REAL NOT NULL DEFAULT 0;
+ALTER TABLE dividend ADD COLUMN withholding_source VARCHAR(10) NOT NULL DEFAULT 'MANUAL';
+
+-- Default withholding tax rate per fund domicile, the first two letters of the fund's ISIN.
+-- Applied to new dividends unless a withholding amount is given. The seeded rates are the
+-- treaty rates for residents of the Netherlands.
+CREATE TABLE IF NOT EXISTS withholding_tax_rate (
+    country VARCHAR(2) NOT NULL PRIMARY KEY,
+    rate_percent REAL NOT NULL,
+    updated_at DATETIME NOT NULL
+);
+
+INSERT OR IGNORE INTO withholding_tax_rate (country, rate_percent, updated_at) VALUES
+    ('BE', 15, CURRENT_TIMESTAMP),
+    ('CH', 15, CURRENT_TIMESTAMP),
+    ('DE', 15, CURRENT_TIMESTAMP),
+    ('FR', 12.8, CURRENT_TIMESTAMP),
+    ('GB', 0, CURRENT_TIMESTAMP),
+    ('IE', 0, CURRENT_TIMESTAMP),
+    ('LU', 0, CURRENT_TIMESTAMP),
+    ('NL', 15, CURRENT_TIMESTAMP),
+    ('US', 15, CURRENT_TIMESTAMP);
+
+-- "Withholding Tax" cash transactions from IBKR Flex statements. amount is positive for
+-- tax withheld and negative for refunds. applied_at is set once the amount has been
+-- applied to the matching dividends; rows without a matching dividend are retried on
+-- every import.
+CREATE TABLE IF NOT EXISTS ibkr_withholding_tax (
+    id VARCHAR(36) NOT NULL PRIMARY KEY,
+    ibkr_transaction_id VARCHAR(50) NOT NULL UNIQUE,
+    isin VARCHAR(12),
+    symbol VARCHAR(20),
+    currency VARCHAR(3) NOT NULL,
+    amount REAL NOT NULL,
+    payment_date DATE NOT NULL,
+    ex_dividend_date DATE,
+    description TEXT,
+    applied_at DATETIME,
+    imported_at DATETIME NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS ix_ibkr_withholding_tax_applied_at ON ibkr_withholding_tax(applied_at);
+
+-- +goose Down
+
+DROP INDEX IF EXISTS ix_ibkr_withholding_tax_applied_at;
+DROP TABLE IF EXISTS ibkr_withholding_tax;
+DROP TABLE IF EXISTS withholding_tax_rate;
+
+ALTER TABLE dividend DROP COLUMN withholding_source;
+ALTER TABLE dividend DROP COLUMN withholding_tax;
@@ -1,3 +1,71 @@
+CREATE TABLE alert (
+    id VARCHAR(36) NOT NULL PRIMARY KEY,
+    rule_id VARCHAR(36) NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    message TEXT NOT NULL,
+    value REAL NOT NULL,
+    triggered_at DATETIME NOT NULL,
+    acknowledged_at DATETIME,
+    resolved_at DATETIME,
+    FOREIGN KEY (rule_id) REFERENCES alert_rule(id) ON DELETE CASCADE
+)
+
+CREATE TABLE alert_rule (
+    id VARCHAR(36) NOT NULL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    type VARCHAR(30) NOT NULL,
+    portfolio_id VARCHAR(36),
+    fund_id VARCHAR(36),
+    direction VARCHAR(10),
+    period VARCHAR(10),
+    threshold REAL NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT 1,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL,
+    FOREIGN KEY (portfolio_id) REFERENCES portfolio(id) ON DELETE CASCADE,
+    FOREIGN KEY (fund_id) REFERENCES fund(id) ON DELETE CASCADE
+)
+
+CREATE TABLE allocation_target (
+    id VARCHAR(36) NOT NULL PRIMARY KEY,
+    portfolio_id VARCHAR(36) NOT NULL,
+    fund_id VARCHAR(36),
+    asset_class VARCHAR(5),
+    target_percent REAL NOT NULL,
+    tolerance_percent REAL NOT NULL DEFAULT 0,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL,
+    FOREIGN KEY (portfolio_id) REFERENCES portfolio(id) ON DELETE CASCADE,
+    FOREIGN KEY (fund_id) REFERENCES fund(id) ON DELETE CASCADE,
+    CHECK ((fund_id IS NULL) <> (asset_class IS NULL))
+)
+
+CREATE TABLE api_token (
+    id VARCHAR(36) NOT NULL PRIMARY KEY,
+    user_id VARCHAR(36) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    scopes TEXT NOT NULL,
+    created_at DATETIME NOT NULL,
+    expires_at DATETIME,
+    last_used_at DATETIME,
+    revoked_at DATETIME,
+    FOREIGN KEY (user_id) REFERENCES user_account(id) ON DELETE CASCADE
+)
+
+CREATE TABLE audit_event (
+    id VARCHAR(36) NOT NULL PRIMARY KEY,
+    timestamp DATETIME NOT NULL,
+    entity_type VARCHAR(30) NOT NULL,
+    entity_id VARCHAR(36) NOT NULL,
+    action VARCHAR(20) NOT NULL,
+    before_state TEXT,
+    after_state TEXT,
+    request_id VARCHAR(36),
+    ip_address VARCHAR(45),
+    user_agent VARCHAR(255)
+, user_id VARCHAR(36))
+
 CREATE TABLE dividend (
     id VARCHAR(36) NOT NULL PRIMARY KEY,
     fund_id VARCHAR(36) NOT NULL,
@@ -10,7 +78,7 @@ CREATE TABLE dividend (
     reinvestment_status VARCHAR(9) NOT NULL,
     buy_order_date DATE,
     reinvestment_transaction_id VARCHAR(36),
-    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP, withholding_tax REAL NOT NULL DEFAULT 0, withholding_source VARCHAR(10) NOT NULL DEFAULT 'MANUAL',
     FOREIGN KEY(fund_id) REFERENCES fund(id),
     FOREIGN KEY(portfolio_fund_id) REFERENCES portfolio_fund(id) ON DELETE CASCADE,
     FOREIGN KEY(reinvestment_transaction_id) REFERENCES "transaction"(id) ON DELETE RESTRICT
@@ -112,7 +180,7 @@ CREATE TABLE "ibkr_transaction" (
     processed_at DATETIME,
     raw_data TEXT,
     report_date DATE NOT NULL,
-    notes VARCHAR(255) NOT NULL,
+    notes VARCHAR(255) NOT NULL, source VARCHAR(20) NOT NULL DEFAULT 'ibkr',
     PRIMARY KEY (id),
     UNIQUE (ibkr_transaction_id)
 )
@@ -131,12 +199,57 @@ CREATE TABLE ibkr_transaction_allocation (
     FOREIGN KEY(transaction_id) REFERENCES "transaction"(id) ON DELETE CASCADE
 )
 
+CREATE TABLE ibkr_withholding_tax (
+    id VARCHAR(36) NOT NULL PRIMARY KEY,
+    ibkr_transaction_id VARCHAR(50) NOT NULL UNIQUE,
+    isin VARCHAR(12),
+    symbol VARCHAR(20),
+    currency VARCHAR(3) NOT NULL,
+    amount REAL NOT NULL,
+    payment_date DATE NOT NULL,
+    ex_dividend_date DATE,
+    description TEXT,
+    applied_at DATETIME,
+    imported_at DATETIME NOT NULL
+)
+
 CREATE INDEX idx_fund_history_date ON fund_history_materialized(date)
 
 CREATE INDEX idx_fund_history_fund_id ON fund_history_materialized(fund_id)
 
 CREATE INDEX idx_fund_history_pf_date ON fund_history_materialized(portfolio_fund_id, date)
 
+CREATE TABLE investment_plan (
+    id VARCHAR(36) NOT NULL PRIMARY KEY,
+    portfolio_fund_id VARCHAR(36) NOT NULL,
+    amount REAL NOT NULL,
+    frequency VARCHAR(10) NOT NULL,
+    day_of_month INTEGER NOT NULL,
+    start_date DATE NOT NULL,
+    end_date DATE,
+    enabled BOOLEAN NOT NULL DEFAULT 1,
+    last_run_date DATE,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL,
+    FOREIGN KEY (portfolio_fund_id) REFERENCES portfolio_fund(id) ON DELETE CASCADE
+)
+
+CREATE INDEX ix_alert_rule_id_status ON alert(rule_id, status)
+
+CREATE INDEX ix_alert_rule_portfolio_id ON alert_rule(portfolio_id)
+
+CREATE INDEX ix_alert_status_triggered_at ON alert(status, triggered_at)
+
+CREATE INDEX ix_api_token_user_id ON api_token(user_id)
+
+CREATE INDEX ix_audit_event_entity ON audit_event(entity_type, entity_id)
+
+CREATE INDEX ix_audit_event_request_id ON audit_event(request_id)
+
+CREATE INDEX ix_audit_event_timestamp_id ON audit_event(timestamp, id)
+
+CREATE INDEX ix_audit_event_user_id ON audit_event(user_id)
+
 CREATE INDEX ix_dividend_fund_id ON dividend(fund_id)
 
 CREATE INDEX ix_dividend_portfolio_fund_id ON dividend(portfolio_fund_id)
@@ -163,14 +276,32 @@ CREATE INDEX ix_ibkr_transaction_date ON ibkr_transaction(transaction_date)
 
 CREATE INDEX ix_ibkr_transaction_ibkr_id ON ibkr_transaction(ibkr_transaction_id)
 
+CREATE INDEX ix_ibkr_transaction_source ON ibkr_transaction(source)
+
 CREATE INDEX ix_ibkr_transaction_status ON ibkr_transaction(status)
 
+CREATE INDEX ix_ibkr_withholding_tax_applied_at ON ibkr_withholding_tax(applied_at)
+
+CREATE INDEX ix_investment_plan_portfolio_fund_id ON investment_plan(portfolio_fund_id)
+
+CREATE INDEX ix_job_created_at ON job(created_at)
+
+CREATE INDEX ix_job_status ON job(status)
+
 CREATE INDEX ix_log_category ON log(category)
 
 CREATE INDEX ix_log_level ON log(level)
 
 CREATE INDEX ix_log_timestamp_id ON log(timestamp, id)
 
+CREATE INDEX ix_materialized_regen_queue_next_attempt_at ON materialized_regen_queue(next_attempt_at)
+
+CREATE INDEX ix_portfolio_history_rollup_date ON portfolio_history_rollup(portfolio_id, period, date)
+
+CREATE INDEX ix_portfolio_owner_id ON portfolio(owner_id)
+
+CREATE INDEX ix_portfolio_share_user_id ON portfolio_share(user_id)
+
 CREATE INDEX ix_realized_gain_loss_fund_id ON realized_gain_loss(fund_id)
 
 CREATE INDEX ix_realized_gain_loss_portfolio_id ON realized_gain_loss(portfolio_id)
@@ -181,10 +312,43 @@ CREATE INDEX ix_realized_gain_loss_transaction_id ON realized_gain_loss(transact
 
 CREATE INDEX ix_transaction_date ON "transaction"(date)
 
+CREATE INDEX ix_transaction_investment_plan_id ON "transaction"(investment_plan_id)
+
 CREATE INDEX ix_transaction_portfolio_fund_id ON "transaction"(portfolio_fund_id)
 
 CREATE INDEX ix_transaction_portfolio_fund_id_date ON "transaction"(portfolio_fund_id, date)
 
+CREATE INDEX ix_trash_deleted_at ON trash(deleted_at)
+
+CREATE INDEX ix_trash_entity ON trash(entity_type, entity_id)
+
+CREATE INDEX ix_user_session_expires_at ON user_session(expires_at)
+
+CREATE INDEX ix_user_session_user_id ON user_session(user_id)
+
+CREATE INDEX ix_webhook_delivery_created_at ON webhook_delivery(webhook_id, created_at)
+
+CREATE UNIQUE INDEX ix_webhook_delivery_event_key ON webhook_delivery(webhook_id, event_key)
+
+CREATE INDEX ix_webhook_delivery_next_attempt_at ON webhook_delivery(status, next_attempt_at)
+
+CREATE TABLE job (
+    id VARCHAR(36) NOT NULL PRIMARY KEY,
+    type VARCHAR(50) NOT NULL,
+    params TEXT,
+    status VARCHAR(20) NOT NULL,
+    progress INTEGER NOT NULL DEFAULT 0,
+    result TEXT,
+    error TEXT,
+    retry_of VARCHAR(36),
+    user_id VARCHAR(36),
+    request_id VARCHAR(36),
+    created_at DATETIME NOT NULL,
+    started_at DATETIME,
+    finished_at DATETIME,
+    FOREIGN KEY (retry_of) REFERENCES job(id) ON DELETE SET NULL
+)
+
 CREATE TABLE log (
     id VARCHAR(36) NOT NULL PRIMARY KEY,
     timestamp DATETIME NOT NULL,
@@ -201,13 +365,26 @@ CREATE TABLE log (
     user_agent VARCHAR(255)
 )
 
+CREATE TABLE "materialized_regen_queue" (
+    portfolio_id VARCHAR(36) NOT NULL,
+    portfolio_fund_id VARCHAR(36) NOT NULL DEFAULT '',
+    start_date DATE NOT NULL,
+    version INTEGER NOT NULL DEFAULT 1,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    requested_at DATETIME NOT NULL,
+    next_attempt_at DATETIME NOT NULL,
+    PRIMARY KEY (portfolio_id, portfolio_fund_id),
+    FOREIGN KEY (portfolio_id) REFERENCES portfolio(id) ON DELETE CASCADE
+)
+
 CREATE TABLE portfolio (
     id VARCHAR(36) NOT NULL PRIMARY KEY,
     name VARCHAR(100) NOT NULL,
     description TEXT,
     is_archived BOOLEAN,
     exclude_from_overview BOOLEAN DEFAULT FALSE NOT NULL
-)
+, owner_id VARCHAR(36))
 
 CREATE TABLE portfolio_fund (
     id VARCHAR(36) NOT NULL PRIMARY KEY,
@@ -218,6 +395,50 @@ CREATE TABLE portfolio_fund (
     CONSTRAINT unique_portfolio_fund UNIQUE (portfolio_id, fund_id)
 )
 
+CREATE TABLE portfolio_history_materialized (
+    portfolio_id VARCHAR(36) NOT NULL,
+    date VARCHAR(10) NOT NULL,
+    value FLOAT NOT NULL,
+    cost FLOAT NOT NULL,
+    realized_gain FLOAT NOT NULL,
+    unrealized_gain FLOAT NOT NULL,
+    total_gain_loss FLOAT NOT NULL,
+    total_dividends FLOAT NOT NULL,
+    total_sale_proceeds FLOAT NOT NULL,
+    total_original_cost FLOAT NOT NULL,
+    calculated_at DATETIME NOT NULL,
+    PRIMARY KEY (portfolio_id, date),
+    FOREIGN KEY (portfolio_id) REFERENCES portfolio(id) ON DELETE CASCADE
+)
+
+CREATE TABLE portfolio_history_rollup (
+    portfolio_id VARCHAR(36) NOT NULL,
+    period VARCHAR(5) NOT NULL,
+    period_start VARCHAR(10) NOT NULL,
+    date VARCHAR(10) NOT NULL,
+    value FLOAT NOT NULL,
+    cost FLOAT NOT NULL,
+    realized_gain FLOAT NOT NULL,
+    unrealized_gain FLOAT NOT NULL,
+    total_gain_loss FLOAT NOT NULL,
+    total_dividends FLOAT NOT NULL,
+    total_sale_proceeds FLOAT NOT NULL,
+    total_original_cost FLOAT NOT NULL,
+    calculated_at DATETIME NOT NULL,
+    PRIMARY KEY (portfolio_id, period, period_start),
+    FOREIGN KEY (portfolio_id) REFERENCES portfolio(id) ON DELETE CASCADE
+)
+
+CREATE TABLE portfolio_share (
+    portfolio_id VARCHAR(36) NOT NULL,
+    user_id VARCHAR(36) NOT NULL,
+    access VARCHAR(5) NOT NULL,
+    created_at DATETIME NOT NULL,
+    PRIMARY KEY (portfolio_id, user_id),
+    FOREIGN KEY (portfolio_id) REFERENCES portfolio(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES user_account(id) ON DELETE CASCADE
+)
+
 CREATE TABLE realized_gain_loss (
     id VARCHAR(36) NOT NULL PRIMARY KEY,
     portfolio_id VARCHAR(36) NOT NULL,
@@ -262,6 +483,70 @@ CREATE TABLE "transaction" (
     type VARCHAR(10) NOT NULL,
     shares FLOAT NOT NULL,
     cost_per_share FLOAT NOT NULL,
-    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP, investment_plan_id VARCHAR(36),
     FOREIGN KEY(portfolio_fund_id) REFERENCES portfolio_fund(id) ON DELETE CASCADE
 )
+
+CREATE TABLE trash (
+    id VARCHAR(36) NOT NULL PRIMARY KEY,
+    entity_type VARCHAR(30) NOT NULL,
+    entity_id VARCHAR(36) NOT NULL,
+    label VARCHAR(255) NOT NULL,
+    deleted_at DATETIME NOT NULL,
+    payload TEXT NOT NULL,
+    request_id VARCHAR(36)
+)
+
+CREATE TABLE user_account (
+    id VARCHAR(36) NOT NULL PRIMARY KEY,
+    username VARCHAR(100) NOT NULL UNIQUE,
+    password_hash VARCHAR(255) NOT NULL,
+    is_admin BOOLEAN NOT NULL DEFAULT 0,
+    created_at DATETIME NOT NULL,
+    last_login_at DATETIME
+)
+
+CREATE TABLE user_session (
+    id VARCHAR(36) NOT NULL PRIMARY KEY,
+    user_id VARCHAR(36) NOT NULL,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    created_at DATETIME NOT NULL,
+    expires_at DATETIME NOT NULL,
+    FOREIGN KEY (user_id) REFERENCES user_account(id) ON DELETE CASCADE
+)
+
+CREATE UNIQUE INDEX ux_allocation_target_portfolio_asset_class ON allocation_target(portfolio_id, asset_class)
+
+CREATE UNIQUE INDEX ux_allocation_target_portfolio_fund ON allocation_target(portfolio_id, fund_id)
+
+CREATE TABLE webhook (
+    id VARCHAR(36) NOT NULL PRIMARY KEY,
+    url TEXT NOT NULL,
+    event_types TEXT NOT NULL,
+    secret VARCHAR(128) NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT 1,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL
+)
+
+CREATE TABLE webhook_delivery (
+    id VARCHAR(36) NOT NULL PRIMARY KEY,
+    webhook_id VARCHAR(36) NOT NULL,
+    event_type VARCHAR(50) NOT NULL,
+    event_key VARCHAR(200),
+    payload TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    response_status INTEGER,
+    last_error TEXT,
+    created_at DATETIME NOT NULL,
+    next_attempt_at DATETIME,
+    delivered_at DATETIME,
+    FOREIGN KEY (webhook_id) REFERENCES webhook(id) ON DELETE CASCADE
+)
+
+CREATE TABLE withholding_tax_rate (
+    country VARCHAR(2) NOT NULL PRIMARY KEY,
+    rate_percent REAL NOT NULL,
+    updated_at DATETIME NOT NULL
+)
@@ -0,0 +1,246 @@
+// Package email provides an SMTP client for sending multipart text and HTML messages, and the
+// templates those messages are rendered from.
+package email
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/config"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/logging"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/metrics"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/tracing"
+)
+
+var log = logging.NewLogger("system")
+
+// metricsOperation labels message deliveries in the external call metrics.
+const metricsOperation = "send"
+
+// ErrNotConfigured is returned by Send when no SMTP host is configured.
+var ErrNotConfigured = errors.New("smtp server is not configured")
+
+// Message is a single email with a plain text body and an optional HTML alternative.
+type Message struct {
+	To      []string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// Sender defines the interface for delivering email.
+// This interface enables dependency injection and testing with mock implementations.
+type Sender interface {
+	// Send delivers msg to all of its recipients.
+	Send(ctx context.Context, msg Message) error
+}
+
+// SMTPClient delivers email through the SMTP server in its configuration.
+// Every call to Send opens its own connection, which suits the low volume of digest mail.
+type SMTPClient struct {
+	cfg config.SMTPConfig
+}
+
+// NewSMTPClientWithConfig creates an SMTP client for the server, credentials and security
+// mode in cfg.
+func NewSMTPClientWithConfig(cfg config.SMTPConfig) *SMTPClient {
+	return &SMTPClient{cfg: cfg}
+}
+
+// Configured reports whether an SMTP host is set.
+func (c *SMTPClient) Configured() bool {
+	return c.cfg.Host != ""
+}
+
+// Send delivers msg through the configured SMTP server.
+// The whole exchange, from dialing to QUIT, is bounded by the configured timeout and ctx.
+// Returns ErrNotConfigured when no host is set, or an error if the server rejects the
+// sender, a recipient or the message.
+func (c *SMTPClient) Send(ctx context.Context, msg Message) (err error) {
+	ctx, span := tracing.Start(ctx, "email.Send")
+	span.SetAttributes(attribute.Int("email.recipients", len(msg.To)))
+	defer func() { tracing.End(span, err) }()
+
+	if !c.Configured() {
+		return ErrNotConfigured
+	}
+	if len(msg.To) == 0 {
+		return errors.New("message has no recipients")
+	}
+	defer func() { metrics.ObserveExternalCall(metrics.ClientSMTP, metricsOperation, err) }()
+
+	from, err := mail.ParseAddress(c.cfg.From)
+	if err != nil {
+		return fmt.Errorf("parse sender address: %w", err)
+	}
+	recipients := make([]string, 0, len(msg.To))
+	for _, to := range msg.To {
+		addr, err := mail.ParseAddress(to)
+		if err != nil {
+			return fmt.Errorf("parse recipient address %q: %w", to, err)
+		}
+		recipients = append(recipients, addr.Address)
+	}
+	body, err := buildMessage(from, msg)
+	if err != nil {
+		return fmt.Errorf("build message: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(c.cfg.Timeout))
+	defer cancel()
+
+	client, err := c.dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if err := c.exchange(client, from.Address, recipients, body); err != nil {
+		return err
+	}
+	log.Info("email sent", "subject", msg.Subject, "recipients", len(recipients))
+	return nil
+}
+
+// dial connects to the server and returns a client that has completed the TLS setup of the
+// configured security mode.
+func (c *SMTPClient) dial(ctx context.Context) (*smtp.Client, error) {
+	addr := net.JoinHostPort(c.cfg.Host, strconv.Itoa(c.cfg.Port))
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("connect to smtp server: %w", err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline) //nolint:errcheck // A failed deadline only loses the timeout; the exchange still reports its own errors.
+	}
+
+	tlsConfig := &tls.Config{ServerName: c.cfg.Host, MinVersion: tls.VersionTLS12}
+	if c.cfg.Security == "tls" {
+		conn = tls.Client(conn, tlsConfig)
+	}
+	client, err := smtp.NewClient(conn, c.cfg.Host)
+	if err != nil {
+		_ = conn.Close() //nolint:errcheck // The greeting error is the one worth reporting.
+		return nil, fmt.Errorf("open smtp session: %w", err)
+	}
+	if c.cfg.Security == "starttls" {
+		if err := client.StartTLS(tlsConfig); err != nil {
+			_ = client.Close() //nolint:errcheck // The STARTTLS error is the one worth reporting.
+			return nil, fmt.Errorf("start tls: %w", err)
+		}
+	}
+	return client, nil
+}
+
+// exchange authenticates when credentials are configured and transmits one message.
+func (c *SMTPClient) exchange(client *smtp.Client, from string, recipients []string, body []byte) error {
+	if c.cfg.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", c.cfg.Username, c.cfg.Password, c.cfg.Host)); err != nil {
+			return fmt.Errorf("authenticate: %w", err)
+		}
+	}
+	if err := client.Mail(from); err != nil {
+		return fmt.Errorf("set sender: %w", err)
+	}
+	for _, rcpt := range recipients {
+		if err := client.Rcpt(rcpt); err != nil {
+			return fmt.Errorf("add recipient %s: %w", rcpt, err)
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("start message data: %w", err)
+	}
+	if _, err := w.Write(body); err != nil {
+		return fmt.Errorf("write message: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("send message: %w", err)
+	}
+	return client.Quit()
+}
+
+// buildMessage renders msg as a MIME message. The text body is always included; the HTML body
+// is added as a multipart/alternative part when present.
+func buildMessage(from *mail.Address, msg Message) ([]byte, error) {
+	var buf bytes.Buffer
+	header := func(key, value string) { fmt.Fprintf(&buf, "%s: %s\r\n", key, value) }
+
+	header("From", from.String())
+	header("To", strings.Join(msg.To, ", "))
+	header("Subject", mime.QEncoding.Encode("utf-8", msg.Subject))
+	header("Date", time.Now().Format(time.RFC1123Z))
+	header("Message-ID", messageID(from.Address))
+	header("MIME-Version", "1.0")
+
+	if msg.HTML == "" {
+		header("Content-Type", "text/plain; charset=utf-8")
+		header("Content-Transfer-Encoding", "quoted-printable")
+		buf.WriteString("\r\n")
+		if err := writeQuotedPrintable(&buf, msg.Text); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+
+	mw := multipart.NewWriter(&buf)
+	header("Content-Type", "multipart/alternative; boundary="+mw.Boundary())
+	buf.WriteString("\r\n")
+	for _, part := range []struct{ contentType, body string }{
+		{"text/plain; charset=utf-8", msg.Text},
+		{"text/html; charset=utf-8", msg.HTML},
+	} {
+		pw, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if err := writeQuotedPrintable(pw, part.body); err != nil {
+			return nil, err
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeQuotedPrintable(w io.Writer, body string) error {
+	qp := quotedprintable.NewWriter(w)
+	if _, err := qp.Write([]byte(body)); err != nil {
+		return err
+	}
+	return qp.Close()
+}
+
+// messageID returns a unique Message-ID in the domain of the sender address.
+func messageID(from string) string {
+	domain := "localhost"
+	if at := strings.LastIndex(from, "@"); at >= 0 {
+		domain = from[at+1:]
+	}
+	b := make([]byte, 16)
+	_, _ = rand.Read(b) //nolint:errcheck // crypto/rand.Read never returns an error.
+	return "<" + hex.EncodeToString(b) + "@" + domain + ">"
+}
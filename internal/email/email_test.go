@@ -0,0 +1,247 @@
+package email
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/config"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/model"
+)
+
+// testSMTPServer is a minimal local SMTP server that accepts every message and records it.
+type testSMTPServer struct {
+	addr       *net.TCPAddr
+	rejectRcpt string // Recipient answered with 550
+	mu         sync.Mutex
+	from       string
+	rcpts      []string
+	data       string
+}
+
+func startTestSMTPServer(t *testing.T) *testSMTPServer {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { _ = ln.Close() }) //nolint:errcheck // Test cleanup.
+
+	srv := &testSMTPServer{addr: ln.Addr().(*net.TCPAddr)}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go srv.serve(conn)
+		}
+	}()
+	return srv
+}
+
+func (s *testSMTPServer) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { _, _ = io.WriteString(conn, line+"\r\n") } //nolint:errcheck // Test server.
+
+	reply("220 localhost test server")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		cmd := strings.TrimSpace(line)
+		switch verb := strings.ToUpper(strings.SplitN(cmd, " ", 2)[0]); verb {
+		case "EHLO", "HELO":
+			reply("250 localhost")
+		case "MAIL":
+			s.mu.Lock()
+			s.from = cmd
+			s.mu.Unlock()
+			reply("250 OK")
+		case "RCPT":
+			s.mu.Lock()
+			rejected := s.rejectRcpt != "" && strings.Contains(cmd, s.rejectRcpt)
+			if !rejected {
+				s.rcpts = append(s.rcpts, cmd)
+			}
+			s.mu.Unlock()
+			if rejected {
+				reply("550 no such user")
+				continue
+			}
+			reply("250 OK")
+		case "DATA":
+			reply("354 end with .")
+			var data strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				data.WriteString(strings.TrimPrefix(l, "."))
+			}
+			s.mu.Lock()
+			s.data = data.String()
+			s.mu.Unlock()
+			reply("250 queued")
+		case "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("502 not implemented")
+		}
+	}
+}
+
+func (s *testSMTPServer) config() config.SMTPConfig {
+	return config.SMTPConfig{
+		Host:     s.addr.IP.String(),
+		Port:     s.addr.Port,
+		From:     "Portfolio Manager <ipm@example.com>",
+		Security: "none",
+		Timeout:  config.Duration(5 * time.Second),
+	}
+}
+
+func TestSMTPClient_Send(t *testing.T) {
+	t.Run("delivers a multipart message to a local server", func(t *testing.T) {
+		srv := startTestSMTPServer(t)
+		client := NewSMTPClientWithConfig(srv.config())
+
+		err := client.Send(context.Background(), Message{
+			To:      []string{"alice@example.com", "Bob <bob@example.com>"},
+			Subject: "Weekly digest – 2026",
+			Text:    "Total value: 1000.00",
+			HTML:    "<p>Total value: <strong>1000.00</strong></p>",
+		})
+		if err != nil {
+			t.Fatalf("Send: %v", err)
+		}
+
+		srv.mu.Lock()
+		defer srv.mu.Unlock()
+		if !strings.HasPrefix(srv.from, "MAIL FROM:<ipm@example.com>") {
+			t.Errorf("unexpected sender %q", srv.from)
+		}
+		if len(srv.rcpts) != 2 || !strings.Contains(srv.rcpts[1], "<bob@example.com>") {
+			t.Errorf("unexpected recipients %v", srv.rcpts)
+		}
+
+		msg, err := mail.ReadMessage(strings.NewReader(srv.data))
+		if err != nil {
+			t.Fatalf("parse message: %v", err)
+		}
+		subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+		if err != nil || subject != "Weekly digest – 2026" {
+			t.Errorf("unexpected subject %q (%v)", subject, err)
+		}
+		mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+		if err != nil || mediaType != "multipart/alternative" {
+			t.Fatalf("unexpected content type %q", msg.Header.Get("Content-Type"))
+		}
+		mr := multipart.NewReader(msg.Body, params["boundary"])
+		var parts []string
+		for {
+			part, err := mr.NextPart()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				t.Fatalf("read part: %v", err)
+			}
+			body, _ := io.ReadAll(part)
+			parts = append(parts, part.Header.Get("Content-Type")+": "+string(body))
+		}
+		if len(parts) != 2 || !strings.Contains(parts[0], "Total value: 1000.00") || !strings.Contains(parts[1], "<strong>1000.00</strong>") {
+			t.Errorf("unexpected parts %q", parts)
+		}
+	})
+
+	t.Run("rejected recipient", func(t *testing.T) {
+		srv := startTestSMTPServer(t)
+		srv.mu.Lock()
+		srv.rejectRcpt = "nobody@example.com"
+		srv.mu.Unlock()
+		client := NewSMTPClientWithConfig(srv.config())
+
+		err := client.Send(context.Background(), Message{To: []string{"nobody@example.com"}, Subject: "x", Text: "x"})
+		if err == nil || !strings.Contains(err.Error(), "550") {
+			t.Errorf("expected the 550 rejection, got %v", err)
+		}
+	})
+
+	t.Run("not configured", func(t *testing.T) {
+		client := NewSMTPClientWithConfig(config.Defaults().SMTP)
+		if err := client.Send(context.Background(), Message{To: []string{"alice@example.com"}}); !errors.Is(err, ErrNotConfigured) {
+			t.Errorf("expected ErrNotConfigured, got %v", err)
+		}
+	})
+
+	t.Run("unreachable server", func(t *testing.T) {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("listen: %v", err)
+		}
+		port := ln.Addr().(*net.TCPAddr).Port
+		_ = ln.Close() //nolint:errcheck // Free the port so the dial fails.
+
+		cfg := config.SMTPConfig{Host: "127.0.0.1", Port: port, From: "ipm@example.com", Security: "none", Timeout: config.Duration(time.Second)}
+		err = NewSMTPClientWithConfig(cfg).Send(context.Background(), Message{To: []string{"alice@example.com"}, Text: "x"})
+		if err == nil || !strings.Contains(err.Error(), "connect to smtp server") {
+			t.Errorf("expected a connection error, got %v", err)
+		}
+	})
+}
+
+func TestRender_Digest(t *testing.T) {
+	end := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+	digest := model.Digest{
+		Period:    model.DigestPeriodWeek,
+		StartDate: end.AddDate(0, 0, -7),
+		EndDate:   end,
+		Portfolios: []model.DigestPortfolio{{
+			Name:          "Pension <family>",
+			Value:         1200,
+			Change:        -25.5,
+			ChangePercent: -2.08,
+			Dividends:     3,
+			TopMovers:     []model.DigestMover{{FundName: "World ETF", Change: -25.5, ChangePercent: -2.08}},
+		}},
+		TotalValue:        1200,
+		TotalChange:       -25.5,
+		TotalDividends:    3,
+		PendingInboxCount: 2,
+		GeneratedAt:       end,
+	}
+
+	text, html, err := Render("digest", digest)
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	for _, want := range []string{"weekly digest", "12 Oct 2026 - 19 Oct 2026", "Pension <family>", "Change: -25.50 (-2.08%)", "World ETF: -25.50", "2 IBKR transactions are waiting"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected text to contain %q, got:\n%s", want, text)
+		}
+	}
+	if !strings.Contains(html, "Pension &lt;family&gt;") || strings.Contains(html, "<family>") {
+		t.Errorf("expected the portfolio name to be escaped in HTML, got:\n%s", html)
+	}
+
+	if _, _, err := Render("nope", digest); err == nil {
+		t.Error("expected an error for an unknown template")
+	}
+}
@@ -0,0 +1,41 @@
+package email
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"math"
+	texttemplate "text/template"
+	"time"
+)
+
+//go:embed templates/*.tmpl
+var templateFS embed.FS
+
+// templateFuncs format amounts and dates the same way in the text and HTML templates.
+var templateFuncs = map[string]any{
+	"money":   func(v float64) string { return fmt.Sprintf("%.2f", v) },
+	"signed":  func(v float64) string { return fmt.Sprintf("%+.2f", v) },
+	"percent": func(v float64) string { return fmt.Sprintf("%+.2f%%", v) },
+	"date":    func(t time.Time) string { return t.Format("2 Jan 2006") },
+	"loss":    func(v float64) bool { return v < 0 && math.Abs(v) >= 0.005 },
+}
+
+var (
+	textTemplates = texttemplate.Must(texttemplate.New("").Funcs(templateFuncs).ParseFS(templateFS, "templates/*.txt.tmpl"))
+	htmlTemplates = htmltemplate.Must(htmltemplate.New("").Funcs(templateFuncs).ParseFS(templateFS, "templates/*.html.tmpl"))
+)
+
+// Render executes the text and HTML templates of the named message with data, e.g.
+// "digest" renders templates/digest.txt.tmpl and templates/digest.html.tmpl.
+func Render(name string, data any) (text, html string, err error) {
+	var textBuf, htmlBuf bytes.Buffer
+	if err := textTemplates.ExecuteTemplate(&textBuf, name+".txt.tmpl", data); err != nil {
+		return "", "", fmt.Errorf("render %s text: %w", name, err)
+	}
+	if err := htmlTemplates.ExecuteTemplate(&htmlBuf, name+".html.tmpl", data); err != nil {
+		return "", "", fmt.Errorf("render %s html: %w", name, err)
+	}
+	return textBuf.String(), htmlBuf.String(), nil
+}
@@ -10,13 +10,24 @@ import (
 	"net/url"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/sync/singleflight"
 
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/config"
 	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/logging"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/metrics"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/tracing"
 )
 
 var log = logging.NewLogger("ibkr")
 
+// Operation labels for the external call metrics.
+const (
+	operationSendRequest  = "send_request"
+	operationGetStatement = "get_statement"
+)
+
 // Client defines the interface for fetching financial data from Interactive Brokers.
 // This interface enables dependency injection and testing with mock implementations.
 type Client interface {
@@ -32,17 +43,24 @@ type Client interface {
 // is made to IBKR regardless of how many goroutines call simultaneously.
 type FinanceClient struct {
 	httpClient *http.Client
+	cfg        config.IBKRConfig  // statement polling attempts and backoff
 	sf         singleflight.Group // deduplicates concurrent IBKR API calls per queryID
 }
 
-// NewFinanceClient creates a new IBKR client with default HTTP settings.
-// The client uses a standard http.Client for making requests to Interactive Brokers endpoints.
+// NewFinanceClient creates a new IBKR client with the default timeout and polling settings.
 //
 // Returns:
 //   - *FinanceClient: A new client instance ready for use
 func NewFinanceClient() *FinanceClient {
+	return NewFinanceClientWithConfig(config.Defaults().Providers.IBKR)
+}
+
+// NewFinanceClientWithConfig creates a new IBKR client with the request timeout and
+// statement polling settings in cfg.
+func NewFinanceClientWithConfig(cfg config.IBKRConfig) *FinanceClient {
 	return &FinanceClient{
-		httpClient: &http.Client{},
+		httpClient: &http.Client{Timeout: time.Duration(cfg.Timeout)},
+		cfg:        cfg,
 	}
 }
 
@@ -114,7 +132,16 @@ func (c *FinanceClient) TestIbkrConnection(ctx context.Context, token string, qu
 	return true, nil
 }
 
+// requestIBKRFlexReport submits a Flex query and returns the reference code to poll for.
 func (c *FinanceClient) requestIBKRFlexReport(ctx context.Context, token string, queryID string) (FlexRequestResponse, error) {
+	ctx, span := tracing.StartKind(ctx, "ibkr.send_request", trace.SpanKindClient, attribute.String("ibkr.query_id", queryID))
+	response, err := c.sendFlexRequest(ctx, token, queryID)
+	metrics.ObserveExternalCall(metrics.ClientIBKR, operationSendRequest, err)
+	tracing.End(span, err)
+	return response, err
+}
+
+func (c *FinanceClient) sendFlexRequest(ctx context.Context, token string, queryID string) (FlexRequestResponse, error) {
 	if queryID == "" || token == "" {
 		return FlexRequestResponse{}, fmt.Errorf("not all parameters set")
 	}
@@ -166,8 +193,19 @@ func (c *FinanceClient) requestIBKRFlexReport(ctx context.Context, token string,
 	return response, nil
 }
 
-//nolint:gocyclo // retry mechanism needs room to breath
+// retrieveIBKRFlexReport polls for the statement behind a reference code until it is ready.
+// Each poll for a statement that is not ready yet counts as a retry.
 func (c *FinanceClient) retrieveIBKRFlexReport(ctx context.Context, token string, request FlexRequestResponse) (FlexQueryResponse, []byte, error) {
+	ctx, span := tracing.StartKind(ctx, "ibkr.get_statement", trace.SpanKindClient,
+		attribute.Int64("ibkr.reference_code", int64(request.ReferenceCode)))
+	response, data, err := c.pollFlexStatement(ctx, token, request)
+	metrics.ObserveExternalCall(metrics.ClientIBKR, operationGetStatement, err)
+	tracing.End(span, err)
+	return response, data, err
+}
+
+//nolint:gocyclo // retry mechanism needs room to breath
+func (c *FinanceClient) pollFlexStatement(ctx context.Context, token string, request FlexRequestResponse) (FlexQueryResponse, []byte, error) {
 	if request.Status == "fail" {
 		return FlexQueryResponse{}, nil, fmt.Errorf("failed request submitted")
 	}
@@ -180,9 +218,9 @@ func (c *FinanceClient) retrieveIBKRFlexReport(ctx context.Context, token string
 
 	log.Debug("retrieving IBKR flex report data", "reference_code", request.ReferenceCode, "url", queryURL)
 
-	backoff := 2 * time.Second // start at 2s
-	maxBackoff := 30 * time.Second
-	maxAttempts := 10
+	backoff := time.Duration(c.cfg.PollInitialBackoff)
+	maxBackoff := time.Duration(c.cfg.PollMaxBackoff)
+	maxAttempts := c.cfg.PollAttempts
 
 	for attempt := range maxAttempts {
 		if attempt > 0 {
@@ -192,6 +230,8 @@ func (c *FinanceClient) retrieveIBKRFlexReport(ctx context.Context, token string
 				"backoff", backoff.String(),
 				"reference_code", request.ReferenceCode,
 			)
+			metrics.ExternalRetries.WithLabelValues(metrics.ClientIBKR, operationGetStatement).Inc()
+			trace.SpanFromContext(ctx).AddEvent("poll", trace.WithAttributes(attribute.Int("attempt", attempt+1)))
 			select {
 			case <-ctx.Done():
 				return FlexQueryResponse{}, nil, ctx.Err()
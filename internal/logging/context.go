@@ -8,6 +8,7 @@ const (
 	ctxKeyRequestID ctxKey = iota
 	ctxKeyIP
 	ctxKeyUserAgent
+	ctxKeyUserID
 )
 
 // WithRequestInfo returns a context enriched with HTTP request metadata.
@@ -41,3 +42,16 @@ func UserAgentFromContext(ctx context.Context) string {
 	}
 	return ""
 }
+
+// WithUserID returns a context carrying the authenticated user's ID.
+func WithUserID(ctx context.Context, userID string) context.Context {
+	return context.WithValue(ctx, ctxKeyUserID, userID)
+}
+
+// UserIDFromContext extracts the authenticated user's ID from context.
+func UserIDFromContext(ctx context.Context) string {
+	if v, ok := ctx.Value(ctxKeyUserID).(string); ok {
+		return v
+	}
+	return ""
+}
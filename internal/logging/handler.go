@@ -14,26 +14,21 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/config"
 	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/model"
 )
 
-// Writer constants.
-const (
-	queueSize      = 1024                   // Buffered channel capacity for log entries.
-	maxBatchSize   = 50                     // Max entries per DB transaction.
-	flushInterval  = 250 * time.Millisecond // Max time between DB flushes.
-	dbWriteTimeout = 5 * time.Second        // Per-batch DB write timeout.
-)
-
 // writerCore is the shared state for the single background writer goroutine.
 // Created once by NewLogHandler; shared by all WithAttrs/WithGroup clones via pointer.
 type writerCore struct {
 	db        *sql.DB
-	queue     chan model.Log     // buffered (queueSize)
+	cfg       config.LogConfig   // queue size, batch size, flush interval and write timeout
+	queue     chan model.Log     // buffered (cfg.QueueSize)
 	flushCh   chan chan struct{} // synchronous flush signal
 	done      chan struct{}      // closed to signal shutdown
 	stopped   chan struct{}      // closed when writer exits
 	closeOnce sync.Once
+	dropped   atomic.Uint64 // entries dropped (queue full or failed DB write)
 }
 
 // LogHandler is a dual-write slog.Handler that writes structured logs to both
@@ -47,9 +42,15 @@ type LogHandler struct {
 	groups  []string      // pre-bound groups from WithGroup
 }
 
-// NewLogHandler creates a LogHandler with defaults (enabled=true, level=INFO)
-// and starts the background writer goroutine.
+// NewLogHandler creates a LogHandler with defaults (enabled=true, level=INFO) and the
+// default writer settings, and starts the background writer goroutine.
 func NewLogHandler(db *sql.DB) *LogHandler {
+	return NewLogHandlerWithConfig(db, config.Defaults().Log)
+}
+
+// NewLogHandlerWithConfig creates a LogHandler like NewLogHandler, with the writer's queue
+// size, batch size, flush interval and write timeout taken from cfg.
+func NewLogHandlerWithConfig(db *sql.DB, cfg config.LogConfig) *LogHandler {
 	enabled := &atomic.Bool{}
 	enabled.Store(true)
 	level := &atomic.Int32{}
@@ -57,7 +58,8 @@ func NewLogHandler(db *sql.DB) *LogHandler {
 
 	w := &writerCore{
 		db:      db,
-		queue:   make(chan model.Log, queueSize),
+		cfg:     cfg,
+		queue:   make(chan model.Log, cfg.QueueSize),
 		flushCh: make(chan chan struct{}),
 		done:    make(chan struct{}),
 		stopped: make(chan struct{}),
@@ -98,6 +100,7 @@ func (h *LogHandler) Handle(ctx context.Context, record slog.Record) error {
 	select {
 	case h.writer.queue <- entry:
 	default:
+		h.writer.dropped.Add(1)
 		_, _ = fmt.Fprintf(os.Stderr, "logging: queue full, dropping DB entry: %s\n", entry.Message)
 	}
 
@@ -164,6 +167,7 @@ func (h *LogHandler) buildEntry(ctx context.Context, record slog.Record) model.L
 	requestID := RequestIDFromContext(ctx)
 	ipAddress := IPFromContext(ctx)
 	userAgent := UserAgentFromContext(ctx)
+	userID := UserIDFromContext(ctx)
 
 	// Source: use explicit override if provided, otherwise derive from PC.
 	source := sourceOverride
@@ -190,6 +194,7 @@ func (h *LogHandler) buildEntry(ctx context.Context, record slog.Record) model.L
 		Message:    record.Message,
 		Details:    strings.Join(details, "; "),
 		Source:     source,
+		UserID:     userID,
 		RequestID:  requestID,
 		StackTrace: stackTrace,
 		HTTPStatus: httpStatus,
@@ -202,7 +207,8 @@ func (h *LogHandler) buildEntry(ctx context.Context, record slog.Record) model.L
 func (w *writerCore) startWriter() {
 	defer close(w.stopped)
 
-	batch := make([]model.Log, 0, maxBatchSize)
+	flushInterval := time.Duration(w.cfg.FlushInterval)
+	batch := make([]model.Log, 0, w.cfg.MaxBatchSize)
 	ticker := time.NewTicker(flushInterval)
 	defer ticker.Stop()
 
@@ -210,7 +216,7 @@ func (w *writerCore) startWriter() {
 		select {
 		case entry := <-w.queue:
 			batch = append(batch, entry)
-			if len(batch) >= maxBatchSize {
+			if len(batch) >= w.cfg.MaxBatchSize {
 				w.flushBatch(batch)
 				batch = batch[:0]
 				ticker.Reset(flushInterval)
@@ -264,19 +270,21 @@ func (w *writerCore) flushBatch(batch []model.Log) {
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), dbWriteTimeout)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(w.cfg.WriteTimeout))
 	defer cancel()
 
 	tx, err := w.db.BeginTx(ctx, nil)
 	if err != nil {
+		w.dropped.Add(uint64(len(batch)))
 		_, _ = fmt.Fprintf(os.Stderr, "logging: begin tx failed: %v (dropping %d entries)\n", err, len(batch))
 		return
 	}
 
 	stmt, err := tx.PrepareContext(ctx,
-		`INSERT INTO log (id, timestamp, level, category, message, details, source, request_id, stack_trace, http_status, ip_address, user_agent)
-		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+		`INSERT INTO log (id, timestamp, level, category, message, details, source, user_id, request_id, stack_trace, http_status, ip_address, user_agent)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
 	if err != nil {
+		w.dropped.Add(uint64(len(batch)))
 		_, _ = fmt.Fprintf(os.Stderr, "logging: prepare stmt failed: %v (dropping %d entries)\n", err, len(batch))
 		defer func() { _ = tx.Rollback() }() //nolint:errcheck // Rollback is a no-op after Commit; error is intentionally ignored.
 		return
@@ -300,8 +308,9 @@ func (w *writerCore) flushBatch(batch []model.Log) {
 
 		_, execErr := stmt.ExecContext(ctx, e.ID, e.Timestamp.Format("2006-01-02 15:04:05"),
 			e.Level, e.Category, e.Message, e.Details,
-			e.Source, e.RequestID, e.StackTrace, httpStatus, e.IPAddress, e.UserAgent)
+			e.Source, e.UserID, e.RequestID, e.StackTrace, httpStatus, e.IPAddress, e.UserAgent)
 		if execErr != nil {
+			w.dropped.Add(uint64(len(batch)))
 			_, _ = fmt.Fprintf(os.Stderr, "logging: insert failed: %v (msg=%q)\n", execErr, e.Message)
 			defer func() { _ = tx.Rollback() }() //nolint:errcheck // Rollback is a no-op after Commit; error is intentionally ignored.
 			return
@@ -309,10 +318,17 @@ func (w *writerCore) flushBatch(batch []model.Log) {
 	}
 
 	if err := tx.Commit(); err != nil {
+		w.dropped.Add(uint64(len(batch)))
 		_, _ = fmt.Fprintf(os.Stderr, "logging: commit failed: %v (dropping %d entries)\n", err, len(batch))
 	}
 }
 
+// QueueStats returns the number of entries waiting in the DB writer queue, the queue
+// capacity and the total number of entries dropped since startup.
+func (h *LogHandler) QueueStats() (length, capacity int, dropped uint64) {
+	return len(h.writer.queue), cap(h.writer.queue), h.writer.dropped.Load()
+}
+
 // Flush blocks until all queued entries have been written to the database.
 func (h *LogHandler) Flush() {
 	ack := make(chan struct{})
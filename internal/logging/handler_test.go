@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/config"
 	_ "modernc.org/sqlite"
 )
 
@@ -320,6 +321,35 @@ func TestDBHandler_DBFailureFallback(t *testing.T) {
 	h.Flush()
 }
 
+func TestDBHandler_QueueStatsCountsDrops(t *testing.T) {
+	// A DB without the log table makes every batch fail.
+	dsn := fmt.Sprintf("file:%s?mode=memory&cache=shared", uuid.New().String())
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	h := NewLogHandler(db)
+	defer h.Close()
+	logger := slog.New(h)
+
+	logger.Info("first")
+	logger.Info("second")
+	h.Flush()
+
+	length, capacity, dropped := h.QueueStats()
+	if length != 0 {
+		t.Errorf("length = %d, want 0 after flush", length)
+	}
+	if want := config.Defaults().Log.QueueSize; capacity != want {
+		t.Errorf("capacity = %d, want %d", capacity, want)
+	}
+	if dropped != 2 {
+		t.Errorf("dropped = %d, want 2", dropped)
+	}
+}
+
 func TestDBHandler_ConcurrentWrites(t *testing.T) {
 	db := setupTestDB(t)
 	h := NewLogHandler(db)
@@ -589,7 +619,7 @@ func TestInit_DefaultsWhenNoConfig(t *testing.T) {
 	db := setupTestDB(t)
 
 	// No system_setting rows — Init should use defaults.
-	h := Init(db)
+	h := Init(db, config.Defaults().Log)
 	defer h.Close()
 
 	if !h.enabled.Load() {
@@ -616,7 +646,7 @@ func TestInit_ReadsConfigFromDB(t *testing.T) {
 		t.Fatalf("insert level: %v", err)
 	}
 
-	h := Init(db)
+	h := Init(db, config.Defaults().Log)
 	defer h.Close()
 
 	if h.enabled.Load() {
@@ -6,16 +6,18 @@ package logging
 import (
 	"database/sql"
 	"log/slog"
+
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/config"
 )
 
-// Init creates a LogHandler, reads logging config from system_setting, and
-// sets slog.SetDefault. Returns the handler for runtime config wiring.
+// Init creates a LogHandler with the writer settings in cfg, reads logging config from
+// system_setting, and sets slog.SetDefault. Returns the handler for runtime config wiring.
 //
 // Chicken-and-egg: If called before migrations (table doesn't exist yet),
 // config queries fail gracefully and defaults apply. DB writes will fail
 // until the log table is created, falling back to stderr.
-func Init(db *sql.DB) *LogHandler {
-	h := NewLogHandler(db)
+func Init(db *sql.DB, cfg config.LogConfig) *LogHandler {
+	h := NewLogHandlerWithConfig(db, cfg)
 
 	// Read LOGGING_ENABLED (direct query — no repo import).
 	var enabledStr string
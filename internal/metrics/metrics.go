@@ -0,0 +1,185 @@
+// Package metrics defines the Prometheus collectors exposed on /metrics.
+//
+// Collectors are registered on a private registry rather than the global default so
+// tests can create services and routers repeatedly without duplicate-registration panics.
+// Instrumented packages update the exported collectors directly; values that are owned
+// by long-lived objects (queue depths, DB pool stats) are wired in once at startup via
+// the Register* functions.
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// namespace prefixes every application metric.
+const namespace = "ipm"
+
+// Outcome label values for external calls and background work.
+const (
+	OutcomeSuccess = "success"
+	OutcomeFailure = "failure"
+)
+
+// Client label values for external API calls.
+const (
+	ClientYahoo = "yahoo"
+	ClientIBKR  = "ibkr"
+	ClientSMTP  = "smtp"
+)
+
+var registry = prometheus.NewRegistry()
+
+var (
+	// HTTPRequests counts handled HTTP requests by method, chi route pattern and status code.
+	HTTPRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests handled, by method, route pattern and status code.",
+	}, []string{"method", "route", "status"})
+
+	// HTTPRequestDuration observes HTTP request latency by method and chi route pattern.
+	HTTPRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency, by method and route pattern.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	// ExternalCalls counts logical calls to external APIs by client, operation and outcome.
+	// A call that succeeds after retries counts once as a success.
+	ExternalCalls = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "external_calls_total",
+		Help:      "Calls to external APIs, by client, operation and outcome.",
+	}, []string{"client", "operation", "outcome"})
+
+	// ExternalRetries counts retry attempts against external APIs by client and operation.
+	ExternalRetries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "external_retries_total",
+		Help:      "Retry attempts against external APIs, by client and operation.",
+	}, []string{"client", "operation"})
+
+	// MaterializedRegenDuration observes background materialized regeneration runs by outcome.
+	MaterializedRegenDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "materialized_regen_duration_seconds",
+		Help:      "Duration of background materialized table regenerations, by outcome.",
+		Buckets:   []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120, 300},
+	}, []string{"outcome"})
+
+	// CronLastRun holds the Unix time each scheduled job last started.
+	CronLastRun = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "cron_last_run_timestamp_seconds",
+		Help:      "Unix time a scheduled job last started.",
+	}, []string{"job"})
+
+	// CronLastSuccess holds the Unix time each scheduled job last completed without error.
+	CronLastSuccess = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "cron_last_success_timestamp_seconds",
+		Help:      "Unix time a scheduled job last completed without error.",
+	}, []string{"job"})
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		HTTPRequests,
+		HTTPRequestDuration,
+		ExternalCalls,
+		ExternalRetries,
+		MaterializedRegenDuration,
+		CronLastRun,
+		CronLastSuccess,
+	)
+}
+
+// Handler returns the HTTP handler that serves the registry in the Prometheus exposition format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{Registry: registry})
+}
+
+// ObserveExternalCall records the outcome of one external API call.
+func ObserveExternalCall(client, operation string, err error) {
+	outcome := OutcomeSuccess
+	if err != nil {
+		outcome = OutcomeFailure
+	}
+	ExternalCalls.WithLabelValues(client, operation, outcome).Inc()
+}
+
+// TrackCronJob records the start of a scheduled job and returns a function that records
+// its completion. The returned function marks the job successful when err is nil.
+func TrackCronJob(job string) func(err error) {
+	CronLastRun.WithLabelValues(job).Set(float64(time.Now().Unix()))
+	return func(err error) {
+		if err == nil {
+			CronLastSuccess.WithLabelValues(job).Set(float64(time.Now().Unix()))
+		}
+	}
+}
+
+// LogQueue reports the state of the asynchronous database log writer.
+type LogQueue interface {
+	// QueueStats returns the number of queued entries, the queue capacity and the
+	// total number of entries dropped since startup.
+	QueueStats() (length, capacity int, dropped uint64)
+}
+
+// RegisterDB exposes connection pool statistics (including open connections) for db.
+// Call once at startup.
+func RegisterDB(db *sql.DB) {
+	registry.MustRegister(collectors.NewDBStatsCollector(db, namespace))
+}
+
+// RegisterLogQueue exposes the fill level and dropped-entry count of the log writer queue.
+// Call once at startup.
+func RegisterLogQueue(q LogQueue) {
+	registry.MustRegister(
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "log_queue_length",
+			Help:      "Log entries waiting to be written to the database.",
+		}, func() float64 {
+			length, _, _ := q.QueueStats()
+			return float64(length)
+		}),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "log_queue_capacity",
+			Help:      "Capacity of the database log writer queue.",
+		}, func() float64 {
+			_, capacity, _ := q.QueueStats()
+			return float64(capacity)
+		}),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "log_dropped_entries_total",
+			Help:      "Log entries dropped because the queue was full or the database write failed.",
+		}, func() float64 {
+			_, _, dropped := q.QueueStats()
+			return float64(dropped)
+		}),
+	)
+}
+
+// RegisterRegenQueue exposes the number of portfolios with a background materialized
+// regeneration in flight. depth is called on every scrape. Call once at startup.
+func RegisterRegenQueue(depth func() int) {
+	registry.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "materialized_regen_in_flight",
+		Help:      "Portfolios and portfolio funds with a background materialized regeneration queued or running.",
+	}, func() float64 {
+		return float64(depth())
+	}))
+}
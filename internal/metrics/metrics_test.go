@@ -0,0 +1,70 @@
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+type fakeLogQueue struct{}
+
+func (fakeLogQueue) QueueStats() (length, capacity int, dropped uint64) { return 3, 1024, 7 }
+
+func TestObserveExternalCall(t *testing.T) {
+	success := ExternalCalls.WithLabelValues(ClientYahoo, "test", OutcomeSuccess)
+	failure := ExternalCalls.WithLabelValues(ClientYahoo, "test", OutcomeFailure)
+
+	ObserveExternalCall(ClientYahoo, "test", nil)
+	ObserveExternalCall(ClientYahoo, "test", errors.New("boom"))
+	ObserveExternalCall(ClientYahoo, "test", errors.New("boom"))
+
+	if got := testutil.ToFloat64(success); got != 1 {
+		t.Errorf("success = %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(failure); got != 2 {
+		t.Errorf("failure = %v, want 2", got)
+	}
+}
+
+func TestTrackCronJob(t *testing.T) {
+	TrackCronJob("failing_job")(errors.New("boom"))
+	TrackCronJob("passing_job")(nil)
+
+	if got := testutil.ToFloat64(CronLastRun.WithLabelValues("failing_job")); got == 0 {
+		t.Error("Expected last run to be set for failing job")
+	}
+	if got := testutil.ToFloat64(CronLastSuccess.WithLabelValues("failing_job")); got != 0 {
+		t.Errorf("Expected no last success for failing job, got %v", got)
+	}
+	if got := testutil.ToFloat64(CronLastSuccess.WithLabelValues("passing_job")); got == 0 {
+		t.Error("Expected last success to be set for passing job")
+	}
+}
+
+func TestHandler(t *testing.T) {
+	RegisterLogQueue(fakeLogQueue{})
+	RegisterRegenQueue(func() int { return 2 })
+
+	w := httptest.NewRecorder()
+	Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", w.Code)
+	}
+	body := w.Body.String()
+	for _, want := range []string{
+		"ipm_log_queue_length 3",
+		"ipm_log_queue_capacity 1024",
+		"ipm_log_dropped_entries_total 7",
+		"ipm_materialized_regen_in_flight 2",
+		"go_goroutines",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("Expected %q in metrics output", want)
+		}
+	}
+}
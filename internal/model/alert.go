@@ -0,0 +1,111 @@
+package model
+
+import "time"
+
+// AlertRuleType identifies the condition an alert rule watches.
+type AlertRuleType string
+
+// Alert rule type constants. Fund rules are evaluated after fund prices are updated,
+// portfolio rules after the portfolio's materialized history is regenerated.
+const (
+	AlertRuleFundPrice      AlertRuleType = "fund_price"      // The latest price of FundID is above or below Threshold
+	AlertRuleFundMove       AlertRuleType = "fund_move"       // The price of FundID moved at least Threshold percent over Period
+	AlertRulePortfolioValue AlertRuleType = "portfolio_value" // The value of PortfolioID is below Threshold
+	AlertRulePositionLoss   AlertRuleType = "position_loss"   // FundID in PortfolioID has an unrealized loss of at least Threshold percent
+)
+
+// ValidAlertRuleTypes is the authoritative set of alert rule types.
+var ValidAlertRuleTypes = map[AlertRuleType]bool{
+	AlertRuleFundPrice:      true,
+	AlertRuleFundMove:       true,
+	AlertRulePortfolioValue: true,
+	AlertRulePositionLoss:   true,
+}
+
+// IsFundRule reports whether rules of this type watch fund prices rather than a portfolio.
+func (t AlertRuleType) IsFundRule() bool {
+	return t == AlertRuleFundPrice || t == AlertRuleFundMove
+}
+
+// AlertDirection is the side of the threshold a fund_price rule triggers on.
+type AlertDirection string
+
+// Alert direction constants.
+const (
+	AlertDirectionAbove AlertDirection = "above"
+	AlertDirectionBelow AlertDirection = "below"
+)
+
+// AlertPeriod is the window a fund_move rule measures the price change over.
+type AlertPeriod string
+
+// Alert period constants.
+const (
+	AlertPeriodDay  AlertPeriod = "day"
+	AlertPeriodWeek AlertPeriod = "week"
+)
+
+// Days returns the length of the period in days.
+func (p AlertPeriod) Days() int {
+	if p == AlertPeriodWeek {
+		return 7
+	}
+	return 1
+}
+
+// AlertRule is a condition on fund prices or portfolio values that raises an alert
+// while it holds. Which of PortfolioID, FundID, Direction and Period are set depends on Type.
+type AlertRule struct {
+	ID          string         `json:"id"`
+	Name        string         `json:"name"`
+	Type        AlertRuleType  `json:"type"`
+	PortfolioID string         `json:"portfolioId,omitempty"`
+	FundID      string         `json:"fundId,omitempty"`
+	Direction   AlertDirection `json:"direction,omitempty"`
+	Period      AlertPeriod    `json:"period,omitempty"`
+	Threshold   float64        `json:"threshold"`
+	Enabled     bool           `json:"enabled"`
+	CreatedAt   time.Time      `json:"createdAt"`
+	UpdatedAt   time.Time      `json:"updatedAt"`
+}
+
+// AlertStatus is the state of a triggered alert.
+type AlertStatus string
+
+// Alert status constants. Active and acknowledged alerts are open; an open alert is
+// resolved automatically once its rule's condition no longer holds.
+const (
+	AlertStatusActive       AlertStatus = "active"
+	AlertStatusAcknowledged AlertStatus = "acknowledged"
+	AlertStatusResolved     AlertStatus = "resolved"
+)
+
+// ValidAlertStatuses is the authoritative set of alert statuses.
+var ValidAlertStatuses = map[AlertStatus]bool{
+	AlertStatusActive:       true,
+	AlertStatusAcknowledged: true,
+	AlertStatusResolved:     true,
+}
+
+// Alert is one occurrence of an alert rule's condition, from trigger to resolution.
+// Value is the observed price, percentage or portfolio value when it triggered.
+type Alert struct {
+	ID             string        `json:"id"`
+	RuleID         string        `json:"ruleId"`
+	RuleName       string        `json:"ruleName"`
+	RuleType       AlertRuleType `json:"ruleType"`
+	Status         AlertStatus   `json:"status"`
+	Message        string        `json:"message"`
+	Value          float64       `json:"value"`
+	TriggeredAt    time.Time     `json:"triggeredAt"`
+	AcknowledgedAt *time.Time    `json:"acknowledgedAt,omitempty"`
+	ResolvedAt     *time.Time    `json:"resolvedAt,omitempty"`
+}
+
+// AlertEvaluation summarizes one evaluation of alert rules.
+type AlertEvaluation struct {
+	Evaluated int `json:"evaluated"` // Rules with enough data to evaluate
+	Triggered int `json:"triggered"` // New alerts raised
+	Resolved  int `json:"resolved"`  // Open alerts resolved
+	Failed    int `json:"failed"`    // Rules that could not be evaluated
+}
@@ -0,0 +1,91 @@
+package model
+
+import "time"
+
+// AllocationTarget is the target weight of one fund or one asset class in a portfolio.
+// Exactly one of FundID and AssetClass is set; AssetClass is a fund investment type.
+type AllocationTarget struct {
+	ID               string    `json:"id"`
+	PortfolioID      string    `json:"portfolioId"`
+	FundID           string    `json:"fundId,omitempty"`
+	FundName         string    `json:"fundName,omitempty"`
+	AssetClass       string    `json:"assetClass,omitempty"`
+	TargetPercent    float64   `json:"targetPercent"`
+	TolerancePercent float64   `json:"tolerancePercent"` // Allowed drift in percentage points
+	CreatedAt        time.Time `json:"createdAt"`
+	UpdatedAt        time.Time `json:"updatedAt"`
+}
+
+// AllocationBasis is what a portfolio's allocation targets are set on.
+type AllocationBasis string
+
+// Allocation basis constants.
+const (
+	AllocationBasisFund       AllocationBasis = "fund"
+	AllocationBasisAssetClass AllocationBasis = "asset_class"
+)
+
+// RebalanceMode selects how a rebalancing plan reaches the targets.
+type RebalanceMode string
+
+// Rebalance mode constants.
+const (
+	RebalanceModeFull     RebalanceMode = "full"      // Buy and sell to reach the targets
+	RebalanceModeNewMoney RebalanceMode = "new_money" // Only invest the contribution, never sell
+)
+
+// ValidRebalanceModes is the authoritative set of rebalance modes.
+var ValidRebalanceModes = map[RebalanceMode]bool{
+	RebalanceModeFull:     true,
+	RebalanceModeNewMoney: true,
+}
+
+// TradeAction is the side of a suggested trade.
+type TradeAction string
+
+// Trade action constants.
+const (
+	TradeActionBuy  TradeAction = "buy"
+	TradeActionSell TradeAction = "sell"
+)
+
+// RebalancePlan compares a portfolio's current weights with its allocation targets and
+// suggests the trades that close the gap.
+type RebalancePlan struct {
+	PortfolioID      string                `json:"portfolioId"`
+	Basis            AllocationBasis       `json:"basis"`
+	Mode             RebalanceMode         `json:"mode"`
+	Contribution     float64               `json:"contribution"`
+	CurrentValue     float64               `json:"currentValue"`
+	TargetValue      float64               `json:"targetValue"`      // CurrentValue plus Contribution
+	NeedsRebalancing bool                  `json:"needsRebalancing"` // An allocation drifted outside its tolerance
+	Unallocated      float64               `json:"unallocated"`      // Contribution left over because no held fund can receive it
+	Allocations      []RebalanceAllocation `json:"allocations"`
+	Trades           []RebalanceTrade      `json:"trades"`
+}
+
+// RebalanceAllocation is the current and target weight of one fund or asset class.
+// Drift is CurrentPercent minus TargetPercent in percentage points.
+type RebalanceAllocation struct {
+	FundID           string  `json:"fundId,omitempty"`
+	FundName         string  `json:"fundName,omitempty"`
+	AssetClass       string  `json:"assetClass,omitempty"`
+	CurrentValue     float64 `json:"currentValue"`
+	CurrentPercent   float64 `json:"currentPercent"`
+	TargetPercent    float64 `json:"targetPercent"`
+	TolerancePercent float64 `json:"tolerancePercent"`
+	Drift            float64 `json:"drift"`
+	WithinTolerance  bool    `json:"withinTolerance"`
+}
+
+// RebalanceTrade is a suggested buy or sell of one fund at its latest price.
+type RebalanceTrade struct {
+	PortfolioFundID string      `json:"portfolioFundId"`
+	FundID          string      `json:"fundId"`
+	FundName        string      `json:"fundName"`
+	Action          TradeAction `json:"action"`
+	Price           float64     `json:"price"`
+	PriceDate       string      `json:"priceDate"`
+	Shares          float64     `json:"shares"`
+	Amount          float64     `json:"amount"`
+}
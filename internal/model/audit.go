@@ -0,0 +1,132 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// AuditEntityType identifies the kind of record an audit event describes.
+type AuditEntityType string
+
+// Audit entity type constants define the records whose mutations are audited.
+const (
+	AuditEntityPortfolio       AuditEntityType = "portfolio"
+	AuditEntityFund            AuditEntityType = "fund"
+	AuditEntityPortfolioFund   AuditEntityType = "portfolio_fund"
+	AuditEntityTransaction     AuditEntityType = "transaction"
+	AuditEntityDividend        AuditEntityType = "dividend"
+	AuditEntityIbkrTransaction AuditEntityType = "ibkr_transaction"
+	AuditEntityIbkrConfig      AuditEntityType = "ibkr_config"
+	AuditEntityFundPrice       AuditEntityType = "fund_price"
+	AuditEntityExchangeRate    AuditEntityType = "exchange_rate"
+	AuditEntitySystemSetting   AuditEntityType = "system_setting"
+	AuditEntityUser            AuditEntityType = "user"
+	AuditEntityPortfolioShare  AuditEntityType = "portfolio_share"
+	AuditEntityAPIToken        AuditEntityType = "api_token"
+	AuditEntityWebhook         AuditEntityType = "webhook"
+	AuditEntityAlertRule       AuditEntityType = "alert_rule"
+	AuditEntityAlert           AuditEntityType = "alert"
+	AuditEntityAllocation      AuditEntityType = "allocation"
+	AuditEntityInvestmentPlan  AuditEntityType = "investment_plan"
+	AuditEntityWithholdingTax  AuditEntityType = "withholding_tax_rate"
+)
+
+// ValidAuditEntityTypes is the authoritative set of allowed audit entity type values.
+var ValidAuditEntityTypes = map[AuditEntityType]bool{
+	AuditEntityPortfolio:       true,
+	AuditEntityFund:            true,
+	AuditEntityPortfolioFund:   true,
+	AuditEntityTransaction:     true,
+	AuditEntityDividend:        true,
+	AuditEntityIbkrTransaction: true,
+	AuditEntityIbkrConfig:      true,
+	AuditEntityFundPrice:       true,
+	AuditEntityExchangeRate:    true,
+	AuditEntitySystemSetting:   true,
+	AuditEntityUser:            true,
+	AuditEntityPortfolioShare:  true,
+	AuditEntityAPIToken:        true,
+	AuditEntityWebhook:         true,
+	AuditEntityAlertRule:       true,
+	AuditEntityAlert:           true,
+	AuditEntityAllocation:      true,
+	AuditEntityInvestmentPlan:  true,
+	AuditEntityWithholdingTax:  true,
+}
+
+// AuditAction describes what happened to the audited record.
+type AuditAction string
+
+// Audit action constants define the kinds of mutation recorded in the audit trail.
+const (
+	AuditActionCreate         AuditAction = "create"
+	AuditActionUpdate         AuditAction = "update"
+	AuditActionDelete         AuditAction = "delete"
+	AuditActionAllocate       AuditAction = "allocate"
+	AuditActionUnallocate     AuditAction = "unallocate"
+	AuditActionIgnore         AuditAction = "ignore"
+	AuditActionMatchDividend  AuditAction = "match_dividend"
+	AuditActionRestore        AuditAction = "restore"
+	AuditActionPurge          AuditAction = "purge"
+	AuditActionRotateKey      AuditAction = "rotate_key"
+	AuditActionImport         AuditAction = "import"
+	AuditActionChangePassword AuditAction = "change_password"
+	AuditActionAcknowledge    AuditAction = "acknowledge"
+)
+
+// ValidAuditActions is the authoritative set of allowed audit action values.
+var ValidAuditActions = map[AuditAction]bool{
+	AuditActionCreate:         true,
+	AuditActionUpdate:         true,
+	AuditActionDelete:         true,
+	AuditActionAllocate:       true,
+	AuditActionUnallocate:     true,
+	AuditActionIgnore:         true,
+	AuditActionMatchDividend:  true,
+	AuditActionRestore:        true,
+	AuditActionPurge:          true,
+	AuditActionRotateKey:      true,
+	AuditActionImport:         true,
+	AuditActionChangePassword: true,
+	AuditActionAcknowledge:    true,
+}
+
+// AuditEvent is a single entry in the audit trail.
+// Before is empty for creations and After is empty for deletions. Request metadata
+// is empty for mutations that did not originate from an HTTP request (e.g. scheduled jobs).
+type AuditEvent struct {
+	ID         string          `json:"id"`
+	Timestamp  time.Time       `json:"timestamp"`
+	EntityType string          `json:"entityType"`
+	EntityID   string          `json:"entityId"`
+	Action     string          `json:"action"`
+	Before     json.RawMessage `json:"before,omitempty"`
+	After      json.RawMessage `json:"after,omitempty"`
+	UserID     string          `json:"userId,omitempty"`
+	RequestID  string          `json:"requestId,omitempty"`
+	IPAddress  string          `json:"ipAddress,omitempty"`
+	UserAgent  string          `json:"userAgent,omitempty"`
+}
+
+// AuditFilters represents parsed and validated parameters for querying the audit trail.
+// All fields are optional and can be combined.
+type AuditFilters struct {
+	EntityTypes []string   // Entity types to filter by (OR logic)
+	EntityID    string     // Exact entity ID
+	Actions     []string   // Actions to filter by (OR logic)
+	RequestID   string     // Exact request ID, to see everything one request changed
+	UserID      string     // Exact user ID, to see everything one user changed
+	StartDate   *time.Time // Filter events from this timestamp onwards (inclusive)
+	EndDate     *time.Time // Filter events up to this timestamp (inclusive)
+	SortDir     string     // Sort direction: "asc" or "desc" (default: "desc")
+	Cursor      string     // Pagination cursor from previous response (format: "timestamp_id")
+	PerPage     int        // Number of results per page (1-250, default: 50)
+}
+
+// AuditEventResponse represents a paginated response containing audit events.
+type AuditEventResponse struct {
+	Events     []AuditEvent `json:"events"`
+	NextCursor string       `json:"nextCursor"`
+	HasMore    bool         `json:"hasMore"`
+	Count      int          `json:"count"`
+}
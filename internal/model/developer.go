@@ -97,6 +97,7 @@ type Log struct {
 	Message    string    `json:"message"`             // Primary log message
 	Details    string    `json:"details,omitempty"`   // Additional details or context (optional)
 	Source     string    `json:"source"`              // Source component that generated the log
+	UserID     string    `json:"userId,omitempty"`    // ID of the authenticated user (optional)
 	RequestID  string    `json:"requestId,omitempty"` // Request ID for tracing (optional)
 	StackTrace string    `json:"stack_trace,omitempty"`
 	HTTPStatus string    `json:"httpStatus,omitempty"` // HTTP status code if applicable (optional)
@@ -0,0 +1,76 @@
+package model
+
+import "time"
+
+// DigestPeriod is the period an email digest covers.
+type DigestPeriod string
+
+// Digest periods. A weekly digest covers the last 7 days, a monthly digest the last calendar
+// month counted back from the day it is sent.
+const (
+	DigestPeriodWeek  DigestPeriod = "week"
+	DigestPeriodMonth DigestPeriod = "month"
+)
+
+// ValidDigestPeriods is the authoritative set of allowed digest periods.
+var ValidDigestPeriods = map[DigestPeriod]bool{
+	DigestPeriodWeek:  true,
+	DigestPeriodMonth: true,
+}
+
+// Start returns the first day covered by a digest of period p that ends on end.
+func (p DigestPeriod) Start(end time.Time) time.Time {
+	if p == DigestPeriodMonth {
+		return end.AddDate(0, -1, 0)
+	}
+	return end.AddDate(0, 0, -7)
+}
+
+// DigestSettings configures the email digest. It is stored in system_setting as JSON under
+// EMAIL_DIGEST; when it is sent is the schedule of the email_digest task.
+type DigestSettings struct {
+	Enabled    bool         `json:"enabled"`
+	Period     DigestPeriod `json:"period"`
+	Recipients []string     `json:"recipients"`
+}
+
+// Digest is the content of an email digest: the state of every active portfolio at the end
+// of the period and how it changed since the start.
+type Digest struct {
+	Period            DigestPeriod      `json:"period"`
+	StartDate         time.Time         `json:"startDate"`
+	EndDate           time.Time         `json:"endDate"`
+	Portfolios        []DigestPortfolio `json:"portfolios"`
+	TotalValue        float64           `json:"totalValue"`
+	TotalChange       float64           `json:"totalChange"`
+	TotalDividends    float64           `json:"totalDividends"`
+	PendingInboxCount int               `json:"pendingInboxCount"` // Pending IBKR inbox transactions
+	GeneratedAt       time.Time         `json:"generatedAt"`
+}
+
+// DigestPortfolio is one portfolio in a digest. Change is the change in total gain/loss over
+// the period, so deposits and withdrawals do not count as gains or losses.
+type DigestPortfolio struct {
+	ID            string        `json:"id"`
+	Name          string        `json:"name"`
+	Value         float64       `json:"value"`      // Value at the end of the period
+	StartValue    float64       `json:"startValue"` // Value at the start of the period
+	Change        float64       `json:"change"`
+	ChangePercent float64       `json:"changePercent"` // Change relative to StartValue, 0 when it is 0
+	Dividends     float64       `json:"dividends"`     // Dividends received during the period
+	TopMovers     []DigestMover `json:"topMovers"`
+}
+
+// DigestMover is a fund with one of the largest gains or losses in a portfolio over the period.
+type DigestMover struct {
+	FundName      string  `json:"fundName"`
+	Change        float64 `json:"change"`
+	ChangePercent float64 `json:"changePercent"`
+}
+
+// EmailDigestParams are the parameters of an email_digest job.
+type EmailDigestParams struct {
+	// Scheduled marks runs started by the scheduler. They are skipped while the digest is
+	// disabled.
+	Scheduled bool `json:"scheduled,omitempty"`
+}
@@ -1,6 +1,9 @@
 package model
 
-import "time"
+import (
+	"math"
+	"time"
+)
 
 // Dividend represents a dividend record from the database.
 // Used internally for calculations and data processing.
@@ -13,6 +16,8 @@ type Dividend struct {
 	SharesOwned               float64
 	DividendPerShare          float64
 	TotalAmount               float64
+	WithholdingTax            float64
+	WithholdingSource         WithholdingSource
 	ReinvestmentStatus        string
 	BuyOrderDate              time.Time
 	ReinvestmentTransactionID string
@@ -23,19 +28,32 @@ type Dividend struct {
 // This structure is used for API responses that combine dividend data with fund metadata.
 // It includes all dividend payment details along with the associated fund name and dividend type.
 type DividendFund struct {
-	ID                        string     `json:"id"`                                  // Unique dividend record ID
-	FundID                    string     `json:"fundId"`                              // Fund identifier
-	FundName                  string     `json:"fundName"`                            // Name of the fund paying the dividend
-	PortfolioFundID           string     `json:"portfolioFundId"`                     // Portfolio fund relationship ID
-	RecordDate                time.Time  `json:"recordDate"`                          // Date of record for dividend eligibility
-	ExDividendDate            time.Time  `json:"exDividendDate"`                      // Ex-dividend date
-	SharesOwned               float64    `json:"sharesOwned"`                         // Number of shares owned on ex-dividend date
-	DividendPerShare          float64    `json:"dividendPerShare"`                    // Dividend amount per share
-	TotalAmount               float64    `json:"totalAmount"`                         // Total dividend amount (sharesOwned × dividendPerShare)
-	ReinvestmentStatus        string     `json:"reinvestmentStatus"`                  // Status: "reinvested", "paid", etc.
-	BuyOrderDate              *time.Time `json:"buyOrderDate,omitempty"`              // Date when reinvestment buy order was placed (nil if not reinvested)
-	ReinvestmentTransactionID string     `json:"reinvestmentTransactionId,omitempty"` // Transaction ID if dividend was reinvested (empty if not)
-	DividendType              string     `json:"dividendType"`                        // Type of dividend: "accumulating", "distributing"
+	ID                        string            `json:"id"`                                  // Unique dividend record ID
+	FundID                    string            `json:"fundId"`                              // Fund identifier
+	FundName                  string            `json:"fundName"`                            // Name of the fund paying the dividend
+	PortfolioFundID           string            `json:"portfolioFundId"`                     // Portfolio fund relationship ID
+	RecordDate                time.Time         `json:"recordDate"`                          // Date of record for dividend eligibility
+	ExDividendDate            time.Time         `json:"exDividendDate"`                      // Ex-dividend date
+	SharesOwned               float64           `json:"sharesOwned"`                         // Number of shares owned on ex-dividend date
+	DividendPerShare          float64           `json:"dividendPerShare"`                    // Dividend amount per share
+	TotalAmount               float64           `json:"totalAmount"`                         // Gross dividend amount (sharesOwned × dividendPerShare)
+	WithholdingTax            float64           `json:"withholdingTax"`                      // Tax withheld at source
+	WithholdingRate           float64           `json:"withholdingRate"`                     // Withholding tax as a percentage of the gross amount
+	WithholdingSource         WithholdingSource `json:"withholdingSource"`                   // Origin of the withholding tax: "DEFAULT", "MANUAL" or "IBKR"
+	NetAmount                 float64           `json:"netAmount"`                           // Amount received after withholding tax
+	ReinvestmentStatus        string            `json:"reinvestmentStatus"`                  // Status: "reinvested", "paid", etc.
+	BuyOrderDate              *time.Time        `json:"buyOrderDate,omitempty"`              // Date when reinvestment buy order was placed (nil if not reinvested)
+	ReinvestmentTransactionID string            `json:"reinvestmentTransactionId,omitempty"` // Transaction ID if dividend was reinvested (empty if not)
+	DividendType              string            `json:"dividendType"`                        // Type of dividend: "accumulating", "distributing"
+}
+
+// SetNetAmount derives NetAmount and WithholdingRate from TotalAmount and WithholdingTax.
+func (d *DividendFund) SetNetAmount() {
+	d.NetAmount = d.TotalAmount - d.WithholdingTax
+	d.WithholdingRate = 0
+	if d.TotalAmount > 0 {
+		d.WithholdingRate = math.Round(d.WithholdingTax/d.TotalAmount*100*1e6) / 1e6
+	}
 }
 
 // PendingDividend represents a dividend record awaiting processing or matching.
@@ -0,0 +1,57 @@
+package model
+
+import "time"
+
+// DividendIncome is the dividend income of a portfolio: what it received per month and
+// year, its trailing-12-month yields and a forecast of the next 12 months. Income is
+// dated by ex-dividend date, like the materialized portfolio history.
+type DividendIncome struct {
+	PortfolioID      string               `json:"portfolioId"`
+	AsOf             string               `json:"asOf"`
+	TotalReceived    float64              `json:"totalReceived"`
+	Trailing12Months float64              `json:"trailing12Months"`
+	CostBasis        float64              `json:"costBasis"`
+	CurrentValue     float64              `json:"currentValue"`
+	YieldOnCost      float64              `json:"yieldOnCost"`  // Trailing income as a percentage of CostBasis
+	CurrentYield     float64              `json:"currentYield"` // Trailing income as a percentage of CurrentValue
+	Forecast12Months float64              `json:"forecast12Months"`
+	Monthly          []IncomePeriod       `json:"monthly"`
+	Yearly           []IncomePeriod       `json:"yearly"`
+	Forecast         []IncomePeriod       `json:"forecast"` // Forecast income per month
+	Funds            []FundDividendIncome `json:"funds"`
+}
+
+// FundDividendIncome is the dividend income of one fund in a portfolio. The forecast
+// repeats the payments of the last 12 months a year later, at their dividend per share
+// and the current share count.
+type FundDividendIncome struct {
+	PortfolioFundID  string            `json:"portfolioFundId"`
+	FundID           string            `json:"fundId"`
+	FundName         string            `json:"fundName"`
+	Shares           float64           `json:"shares"`
+	TotalReceived    float64           `json:"totalReceived"`
+	Trailing12Months float64           `json:"trailing12Months"`
+	PaymentsPerYear  int               `json:"paymentsPerYear"` // Payments in the last 12 months
+	CostBasis        float64           `json:"costBasis"`
+	CurrentValue     float64           `json:"currentValue"`
+	YieldOnCost      float64           `json:"yieldOnCost"`
+	CurrentYield     float64           `json:"currentYield"`
+	Forecast12Months float64           `json:"forecast12Months"`
+	Monthly          []IncomePeriod    `json:"monthly"`
+	Yearly           []IncomePeriod    `json:"yearly"`
+	Payments         []ForecastPayment `json:"payments"` // Expected payments, soonest first
+}
+
+// IncomePeriod is the income of one month (YYYY-MM) or year (YYYY).
+type IncomePeriod struct {
+	Period string  `json:"period"`
+	Amount float64 `json:"amount"`
+}
+
+// ForecastPayment is an expected dividend payment.
+type ForecastPayment struct {
+	ExDividendDate   time.Time `json:"exDividendDate"`
+	DividendPerShare float64   `json:"dividendPerShare"`
+	Shares           float64   `json:"shares"`
+	Amount           float64   `json:"amount"`
+}
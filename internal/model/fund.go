@@ -20,9 +20,9 @@ type Fund struct {
 
 // PortfolioFund represents a portfolio_fund record from the database
 type PortfolioFund struct {
-	ID          string
-	PortfolioID string
-	FundID      string
+	ID          string `json:"id"`
+	PortfolioID string `json:"portfolioId"`
+	FundID      string `json:"fundId"`
 }
 
 // PortfolioFundResponse represents a fund held within a portfolio with calculated metrics.
@@ -28,11 +28,24 @@ type IbkrConfig struct {
 	UpdatedAt                time.Time    `json:"updatedAt"`
 }
 
-// IBKRTransaction represents a transaction imported from Interactive Brokers.
+// IbkrKeyRotation is the outcome of re-encrypting the stored flex token with the newest
+// configured encryption key. Keys are identified by a fingerprint, never by their value.
+type IbkrKeyRotation struct {
+	Reencrypted    bool      `json:"reencrypted"`             // False when no flex token is stored
+	KeyID          string    `json:"keyId"`                   // Fingerprint of the key the token is now encrypted with
+	PreviousKeyID  string    `json:"previousKeyId,omitempty"` // Fingerprint of the key that decrypted it
+	ConfiguredKeys int       `json:"configuredKeys"`
+	RotatedAt      time.Time `json:"rotatedAt"`
+}
+
+// IBKRTransaction represents a transaction staged in the transaction inbox.
 // Stores transaction details including trades, dividends, fees, and other account activities.
 // Transactions are initially imported with status "pending" and require allocation to portfolios.
+// The type predates the source-agnostic inbox: Source records which importer staged the row
+// (see [InboxSource]), and IBKRTransactionID holds that importer's external reference.
 type IBKRTransaction struct {
 	ID                string     `json:"id"`
+	Source            string     `json:"source"`
 	IBKRTransactionID string     `json:"ibkrTransactionId"`
 	TransactionDate   time.Time  `json:"transactionDate"`
 	Symbol            string     `json:"symbol,omitempty"`
@@ -83,16 +96,16 @@ type IBKRTransactionAllocationResponse struct {
 // Stores the complete record of how an IBKR transaction was allocated to a portfolio,
 // including the created transaction reference and allocation type (e.g., "trade", "fee").
 type IBKRTransactionAllocation struct {
-	ID                   string
-	IBKRTransactionID    string
-	PortfolioID          string
-	PortfolioName        string
-	AllocationPercentage float64
-	AllocatedAmount      float64
-	AllocatedShares      float64
-	TransactionID        string
-	Type                 string
-	CreatedAt            time.Time
+	ID                   string    `json:"id"`
+	IBKRTransactionID    string    `json:"ibkrTransactionId"`
+	PortfolioID          string    `json:"portfolioId"`
+	PortfolioName        string    `json:"portfolioName"`
+	AllocationPercentage float64   `json:"allocationPercentage"`
+	AllocatedAmount      float64   `json:"allocatedAmount"`
+	AllocatedShares      float64   `json:"allocatedShares"`
+	TransactionID        string    `json:"transactionId"`
+	Type                 string    `json:"type"`
+	CreatedAt            time.Time `json:"createdAt"`
 }
 
 // IBKREligiblePortfolioResponse represents the result of finding eligible portfolios for an IBKR transaction.
@@ -0,0 +1,38 @@
+package model
+
+// InboxSource identifies the importer that staged a transaction in the inbox.
+// Any lowercase slug is accepted; the constants below are the sources known to the application.
+type InboxSource string
+
+// Inbox source constants define the importers that feed the transaction inbox.
+const (
+	InboxSourceIBKR   InboxSource = "ibkr"
+	InboxSourceCSV    InboxSource = "csv"
+	InboxSourceManual InboxSource = "manual"
+)
+
+// InboxStatus represents the lifecycle state of a staged inbox transaction.
+type InboxStatus string
+
+// Inbox status constants define the valid values for the Status field of an [IBKRTransaction].
+const (
+	InboxStatusPending   InboxStatus = "pending"
+	InboxStatusProcessed InboxStatus = "processed"
+	InboxStatusIgnored   InboxStatus = "ignored"
+)
+
+// InboxFilter holds the optional filters for listing inbox transactions.
+// An empty Source matches every importer; an empty Status defaults to "pending".
+type InboxFilter struct {
+	Source          string
+	Status          string
+	TransactionType string
+}
+
+// InboxStageResult reports the outcome of staging a batch of transactions in the inbox.
+// Skipped counts entries whose external reference was already present for the same source.
+type InboxStageResult struct {
+	Source  string `json:"source"`
+	Staged  int    `json:"staged"`
+	Skipped int    `json:"skipped"`
+}
@@ -0,0 +1,61 @@
+package model
+
+import "time"
+
+// InvestmentPlanFrequency is how often an investment plan buys.
+type InvestmentPlanFrequency string
+
+// Investment plan frequency constants.
+const (
+	InvestmentPlanMonthly   InvestmentPlanFrequency = "monthly"
+	InvestmentPlanQuarterly InvestmentPlanFrequency = "quarterly"
+	InvestmentPlanYearly    InvestmentPlanFrequency = "yearly"
+)
+
+// ValidInvestmentPlanFrequencies is the authoritative set of investment plan frequencies.
+var ValidInvestmentPlanFrequencies = map[InvestmentPlanFrequency]bool{
+	InvestmentPlanMonthly:   true,
+	InvestmentPlanQuarterly: true,
+	InvestmentPlanYearly:    true,
+}
+
+// Months returns the number of months between two runs.
+func (f InvestmentPlanFrequency) Months() int {
+	switch f {
+	case InvestmentPlanQuarterly:
+		return 3
+	case InvestmentPlanYearly:
+		return 12
+	default:
+		return 1
+	}
+}
+
+// InvestmentPlan is a recurring purchase of a fund in a portfolio. Every run buys Amount
+// worth of shares on DayOfMonth, or the last day of shorter months, at that date's price
+// or the next available one. Runs are scheduled from the month of StartDate until EndDate.
+type InvestmentPlan struct {
+	ID              string                  `json:"id"`
+	PortfolioFundID string                  `json:"portfolioFundId"`
+	PortfolioID     string                  `json:"portfolioId"`
+	FundID          string                  `json:"fundId"`
+	FundName        string                  `json:"fundName"`
+	Amount          float64                 `json:"amount"`
+	Frequency       InvestmentPlanFrequency `json:"frequency"`
+	DayOfMonth      int                     `json:"dayOfMonth"`
+	StartDate       time.Time               `json:"startDate"`
+	EndDate         *time.Time              `json:"endDate,omitempty"`
+	Enabled         bool                    `json:"enabled"`
+	LastRunDate     *time.Time              `json:"lastRunDate,omitempty"` // Scheduled date of the latest generated run
+	NextRunDate     *time.Time              `json:"nextRunDate,omitempty"` // Nil when the plan has ended
+	CreatedAt       time.Time               `json:"createdAt"`
+	UpdatedAt       time.Time               `json:"updatedAt"`
+}
+
+// InvestmentPlanRun summarizes one run of the due investment plans.
+type InvestmentPlanRun struct {
+	Plans        int `json:"plans"`        // Enabled plans checked
+	Transactions int `json:"transactions"` // Buy transactions generated
+	Waiting      int `json:"waiting"`      // Plans with a due run that has no fund price yet
+	Failed       int `json:"failed"`       // Plans whose runs could not be stored
+}
@@ -0,0 +1,93 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// JobType identifies the kind of work a background job performs.
+type JobType string
+
+// Job type constants define the background work that is tracked in the job table.
+const (
+	JobTypeFundPriceUpdate   JobType = "fund_price_update"
+	JobTypeIbkrImport        JobType = "ibkr_import"
+	JobTypeTrashPurge        JobType = "trash_purge"
+	JobTypeSessionPurge      JobType = "session_purge"
+	JobTypeMaterializedRegen JobType = "materialized_regen"
+	JobTypeJobPurge          JobType = "job_purge"
+	JobTypeEmailDigest       JobType = "email_digest"
+	JobTypeInvestmentPlans   JobType = "investment_plans"
+)
+
+// ValidJobTypes is the authoritative set of allowed job type values.
+var ValidJobTypes = map[JobType]bool{
+	JobTypeFundPriceUpdate:   true,
+	JobTypeIbkrImport:        true,
+	JobTypeTrashPurge:        true,
+	JobTypeSessionPurge:      true,
+	JobTypeMaterializedRegen: true,
+	JobTypeJobPurge:          true,
+	JobTypeEmailDigest:       true,
+	JobTypeInvestmentPlans:   true,
+}
+
+// JobStatus is the lifecycle state of a job.
+type JobStatus string
+
+// Job status constants. A job moves from pending to running to succeeded or failed.
+const (
+	JobStatusPending   JobStatus = "pending"
+	JobStatusRunning   JobStatus = "running"
+	JobStatusSucceeded JobStatus = "succeeded"
+	JobStatusFailed    JobStatus = "failed"
+)
+
+// ValidJobStatuses is the authoritative set of allowed job status values.
+var ValidJobStatuses = map[JobStatus]bool{
+	JobStatusPending:   true,
+	JobStatusRunning:   true,
+	JobStatusSucceeded: true,
+	JobStatusFailed:    true,
+}
+
+// Job is a tracked unit of background work.
+// Params and Result hold the JSON-encoded input and output of the job, if any.
+type Job struct {
+	ID         string          `json:"id"`
+	Type       string          `json:"type"`
+	Params     json.RawMessage `json:"params,omitempty"`
+	Status     string          `json:"status"`
+	Progress   int             `json:"progress"`
+	Result     json.RawMessage `json:"result,omitempty"`
+	Error      string          `json:"error,omitempty"`
+	RetryOf    string          `json:"retryOf,omitempty"`
+	UserID     string          `json:"userId,omitempty"`
+	RequestID  string          `json:"requestId,omitempty"`
+	CreatedAt  time.Time       `json:"createdAt"`
+	StartedAt  *time.Time      `json:"startedAt,omitempty"`
+	FinishedAt *time.Time      `json:"finishedAt,omitempty"`
+}
+
+// JobFilters represents parsed and validated parameters for listing jobs.
+type JobFilters struct {
+	Types    []string // Job types to filter by (OR logic)
+	Statuses []string // Statuses to filter by (OR logic)
+	Limit    int      // Maximum number of jobs to return, newest first (1-250, default: 50)
+}
+
+// MaterializedRegenParams are the parameters of a materialized_regen job.
+// They mirror the arguments of MaterializedService.RegenerateMaterializedTable.
+type MaterializedRegenParams struct {
+	StartDate       string   `json:"startDate"`
+	PortfolioIDs    []string `json:"portfolioIds,omitempty"`
+	FundID          string   `json:"fundId,omitempty"`
+	PortfolioFundID string   `json:"portfolioFundId,omitempty"`
+}
+
+// IbkrImportParams are the parameters of an ibkr_import job.
+type IbkrImportParams struct {
+	// Scheduled marks runs started by the scheduler. They are skipped while the IBKR
+	// integration or its automatic import is disabled.
+	Scheduled bool `json:"scheduled,omitempty"`
+}
@@ -0,0 +1,100 @@
+package model
+
+import "time"
+
+// RegenTarget identifies the materialized history a regeneration covers: a single
+// portfolio fund, or every fund in the portfolio when PortfolioFundID is empty.
+type RegenTarget struct {
+	PortfolioID     string
+	PortfolioFundID string
+}
+
+// RegenQueueEntry is a pending materialized history regeneration for one portfolio fund,
+// or for the whole portfolio when PortfolioFundID is empty. Materialized rows from
+// StartDate onward are considered dirty until the entry is drained.
+type RegenQueueEntry struct {
+	PortfolioID     string    `json:"portfolioId"`
+	PortfolioName   string    `json:"portfolioName"`
+	PortfolioFundID string    `json:"portfolioFundId,omitempty"`
+	FundName        string    `json:"fundName,omitempty"`
+	StartDate       time.Time `json:"startDate"`
+	Version         int       `json:"version"`
+	Attempts        int       `json:"attempts"`
+	LastError       string    `json:"lastError,omitempty"`
+	RequestedAt     time.Time `json:"requestedAt"`
+	NextAttemptAt   time.Time `json:"nextAttemptAt"`
+}
+
+// Target returns the portfolio or portfolio fund the entry regenerates.
+func (e RegenQueueEntry) Target() RegenTarget {
+	return RegenTarget{PortfolioID: e.PortfolioID, PortfolioFundID: e.PortfolioFundID}
+}
+
+// StaleReason explains why the materialized history of a portfolio is considered stale.
+// The empty reason means the history is fresh.
+type StaleReason string
+
+// Stale reasons, in the order checkStaleData tests them.
+const (
+	StaleReasonNone         StaleReason = ""
+	StaleReasonError        StaleReason = "error"        // The check itself failed
+	StaleReasonNoData       StaleReason = "no_data"      // Nothing is materialized
+	StaleReasonCoverage     StaleReason = "coverage"     // Materialized dates end before today
+	StaleReasonTransactions StaleReason = "transactions" // A transaction was created after calculated_at
+	StaleReasonPrices       StaleReason = "prices"       // A fund price is dated after the last materialized date
+	StaleReasonDividends    StaleReason = "dividends"    // A dividend was created after calculated_at
+)
+
+// MaterializedCoverage describes the materialized history of one portfolio: the range
+// of dates it covers, when it was last calculated and whether reads would consider it
+// stale. The date fields are nil when nothing is materialized.
+type MaterializedCoverage struct {
+	PortfolioID   string      `json:"portfolioId"`
+	PortfolioName string      `json:"portfolioName"`
+	FirstDate     *time.Time  `json:"firstDate"`
+	LastDate      *time.Time  `json:"lastDate"`
+	CalculatedAt  *time.Time  `json:"calculatedAt"`
+	Rows          int         `json:"rows"`
+	Funds         int         `json:"funds"`
+	Stale         bool        `json:"stale"`
+	StaleReason   StaleReason `json:"staleReason,omitempty"`
+}
+
+// MaterializedDriftKind classifies a difference between materialized and recalculated history.
+type MaterializedDriftKind string
+
+// Drift kinds reported by a materialized verification.
+const (
+	DriftMissing    MaterializedDriftKind = "missing"    // Recalculated row has no materialized counterpart
+	DriftUnexpected MaterializedDriftKind = "unexpected" // Materialized row that recalculation does not produce
+	DriftMismatch   MaterializedDriftKind = "mismatch"   // Both rows exist but a field differs
+)
+
+// MaterializedDrift is one difference found by a materialized verification. Field,
+// Materialized and Expected are set for mismatches only; missing and unexpected rows
+// leave them empty.
+type MaterializedDrift struct {
+	Kind            MaterializedDriftKind `json:"kind"`
+	PortfolioFundID string                `json:"portfolioFundId"`
+	FundName        string                `json:"fundName"`
+	Date            time.Time             `json:"date"`
+	Field           string                `json:"field,omitempty"`
+	Materialized    float64               `json:"materialized"`
+	Expected        float64               `json:"expected"`
+}
+
+// MaterializedVerification is the result of comparing one portfolio's materialized fund
+// rows against a fresh calculation over the same range. Drift lists at most the first
+// MaxReportedDrift differences; DriftCount counts all of them.
+type MaterializedVerification struct {
+	PortfolioID   string              `json:"portfolioId"`
+	PortfolioName string              `json:"portfolioName"`
+	StartDate     time.Time           `json:"startDate"`
+	EndDate       time.Time           `json:"endDate"`
+	RowsChecked   int                 `json:"rowsChecked"`
+	DriftCount    int                 `json:"driftCount"`
+	Drift         []MaterializedDrift `json:"drift"`
+}
+
+// MaxReportedDrift caps the differences listed per portfolio in a MaterializedVerification.
+const MaxReportedDrift = 100
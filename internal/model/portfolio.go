@@ -9,12 +9,27 @@ type Portfolio struct {
 	Description         string `json:"description"`
 	IsArchived          bool   `json:"isArchived"`
 	ExcludeFromOverview bool   `json:"excludeFromOverview"`
+	OwnerID             string `json:"ownerId,omitempty"`
 }
 
 // PortfolioFilter holds filter options for querying portfolios.
 type PortfolioFilter struct {
 	IncludeArchived bool
 	IncludeExcluded bool
+	Scope           PortfolioScope
+}
+
+// PortfolioScope limits a repository read to the portfolios a caller may read. The zero
+// value matches no portfolio, so a read that was not given a scope returns nothing.
+// Requests get theirs from auth.Scope; jobs and other internal callers use AllPortfolios.
+type PortfolioScope struct {
+	All          bool     // Every portfolio
+	PortfolioIDs []string // Otherwise only these portfolios
+}
+
+// AllPortfolios returns the scope matching every portfolio.
+func AllPortfolios() PortfolioScope {
+	return PortfolioScope{All: true}
 }
 
 // PortfolioSummary represents the current state of a portfolio at a specific point in time.
@@ -42,8 +57,34 @@ type PortfolioHistory struct {
 	Portfolios []PortfolioSummary `json:"portfolios"` // Portfolio states for this date
 }
 
+// HistoryInterval is the spacing of portfolio history points.
+type HistoryInterval string
+
+// History intervals. Weekly and monthly history has one point per period: the last
+// day with data in that week (starting Monday) or calendar month.
+const (
+	HistoryIntervalDay   HistoryInterval = "day"
+	HistoryIntervalWeek  HistoryInterval = "week"
+	HistoryIntervalMonth HistoryInterval = "month"
+)
+
+// PeriodStart returns the first day of the period containing date: the day itself,
+// the Monday of its week, or the first of its month.
+func (i HistoryInterval) PeriodStart(date time.Time) time.Time {
+	date = time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	switch i {
+	case HistoryIntervalWeek:
+		return date.AddDate(0, 0, -(int(date.Weekday())+6)%7)
+	case HistoryIntervalMonth:
+		return date.AddDate(0, 0, 1-date.Day())
+	default:
+		return date
+	}
+}
+
 // PortfolioHistoryMaterialized represents a pre-calculated portfolio state for a specific date.
-// This is used for fast retrieval of historical portfolio data from the materialized view table.
+// This is used for fast retrieval of historical portfolio data from the
+// portfolio_history_materialized table and its weekly/monthly rollups.
 type PortfolioHistoryMaterialized struct {
 	ID                string    // Primary key
 	PortfolioID       string    // Portfolio identifier
@@ -0,0 +1,40 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Scheduled task names. Each task runs one job type on its cron schedule.
+const (
+	ScheduledTaskFundPriceUpdate = "fund_price_update"
+	ScheduledTaskIbkrImport      = "ibkr_import"
+	ScheduledTaskTrashPurge      = "trash_purge"
+	ScheduledTaskSessionPurge    = "session_purge"
+	ScheduledTaskJobPurge        = "job_purge"
+	ScheduledTaskEmailDigest     = "email_digest"
+	ScheduledTaskInvestmentPlans = "investment_plans"
+)
+
+// ScheduledTaskSetting is the user-editable part of a scheduled task, stored in
+// system_setting as JSON under SCHEDULE_<NAME>.
+type ScheduledTaskSetting struct {
+	Schedule string `json:"schedule"` // Standard five-field cron expression
+	Timezone string `json:"timezone"` // IANA time zone the schedule is evaluated in
+	Enabled  bool   `json:"enabled"`
+}
+
+// ScheduledTask is a scheduled task with its settings and run state.
+// The last run is the most recent job of the task's type, whether it was started by the
+// schedule, by "run now" or by another endpoint.
+type ScheduledTask struct {
+	Name    string  `json:"name"`
+	JobType JobType `json:"jobType"`
+	ScheduledTaskSetting
+	NextRunAt  *time.Time      `json:"nextRunAt,omitempty"` // Nil when the task is disabled
+	LastJobID  string          `json:"lastJobId,omitempty"`
+	LastRunAt  *time.Time      `json:"lastRunAt,omitempty"`
+	LastStatus string          `json:"lastStatus,omitempty"`
+	LastResult json.RawMessage `json:"lastResult,omitempty"`
+	LastError  string          `json:"lastError,omitempty"`
+}
@@ -0,0 +1,170 @@
+package model
+
+import "time"
+
+// TaxReportCurrency is the currency tax reports are converted to.
+const TaxReportCurrency = "EUR"
+
+// TaxHolding is a portfolio's position in a fund on a date, from the materialized history.
+type TaxHolding struct {
+	PortfolioID string
+	FundID      string
+	FundName    string
+	ISIN        string
+	Currency    string
+	Shares      float64
+	Price       float64
+	Value       float64
+}
+
+// TaxRealizedGain is a sale's realized_gain_loss record with its portfolio fund and fund.
+type TaxRealizedGain struct {
+	PortfolioID     string
+	PortfolioFundID string
+	TransactionID   string
+	FundID          string
+	FundName        string
+	ISIN            string
+	Currency        string
+	SaleDate        time.Time
+	SharesSold      float64
+	CostBasis       float64
+	Proceeds        float64
+	Gain            float64
+}
+
+// Box3Report is the Dutch Box 3 (wealth tax) report of a year: the value of every portfolio
+// on the reference date, 1 January, and the dividends and withholding tax of the year.
+// Amounts without a currency in their name are in EUR. Holdings in a currency listed in
+// MissingExchangeRates could not be converted and count as zero in the EUR amounts.
+type Box3Report struct {
+	Year                 int                `json:"year"`
+	ReferenceDate        string             `json:"referenceDate"`
+	Currency             string             `json:"currency"`
+	TotalValue           float64            `json:"totalValue"`
+	TotalDividends       float64            `json:"totalDividends"`
+	TotalWithholdingTax  float64            `json:"totalWithholdingTax"`
+	Portfolios           []Box3Portfolio    `json:"portfolios"`
+	ExchangeRates        []Box3ExchangeRate `json:"exchangeRates"`        // Rates used for the reference date values
+	MissingExchangeRates []string           `json:"missingExchangeRates"` // Currencies without a rate
+}
+
+// Box3Portfolio totals one portfolio of a Box3Report in EUR.
+type Box3Portfolio struct {
+	PortfolioID    string     `json:"portfolioId"`
+	PortfolioName  string     `json:"portfolioName"`
+	Value          float64    `json:"value"`
+	Dividends      float64    `json:"dividends"`
+	WithholdingTax float64    `json:"withholdingTax"`
+	Funds          []Box3Fund `json:"funds"`
+}
+
+// Box3Fund is a fund held on the reference date or paying dividends during the year. Shares,
+// Price, Value, Dividends and WithholdingTax are in the fund's currency; the *EUR fields are
+// converted at the rate of the reference date (value) or of each ex-dividend date (dividends).
+type Box3Fund struct {
+	FundID            string  `json:"fundId"`
+	FundName          string  `json:"fundName"`
+	ISIN              string  `json:"isin"`
+	Currency          string  `json:"currency"`
+	Shares            float64 `json:"shares"`
+	Price             float64 `json:"price"`
+	Value             float64 `json:"value"`
+	ExchangeRate      float64 `json:"exchangeRate"`
+	ValueEUR          float64 `json:"valueEur"`
+	Dividends         float64 `json:"dividends"`
+	DividendsEUR      float64 `json:"dividendsEur"`
+	WithholdingTax    float64 `json:"withholdingTax"`
+	WithholdingTaxEUR float64 `json:"withholdingTaxEur"`
+}
+
+// Box3ExchangeRate is the rate converting a currency to EUR on the reference date, taken
+// from the latest exchange_rate on or before it.
+type Box3ExchangeRate struct {
+	Currency string  `json:"currency"`
+	Rate     float64 `json:"rate"`
+	Date     string  `json:"date"`
+}
+
+// Holding periods of a sale or acquisition. A position held for more than one year is long
+// term. A sale of shares acquired on both sides of that line is mixed.
+const (
+	HoldingPeriodShort = "short"
+	HoldingPeriodLong  = "long"
+	HoldingPeriodMixed = "mixed"
+)
+
+// CapitalGainsReport lists the sales of a year with their realized gains, totalled per fund
+// and portfolio. Amounts ending in Base are in BaseCurrency; sales in a currency listed in
+// MissingExchangeRates could not be converted and count as zero in them.
+type CapitalGainsReport struct {
+	Year                 int                     `json:"year"`
+	BaseCurrency         string                  `json:"baseCurrency"`
+	ProceedsBase         float64                 `json:"proceedsBase"`
+	CostBasisBase        float64                 `json:"costBasisBase"`
+	GainBase             float64                 `json:"gainBase"`
+	ShortTermGainBase    float64                 `json:"shortTermGainBase"`
+	LongTermGainBase     float64                 `json:"longTermGainBase"`
+	Portfolios           []CapitalGainsPortfolio `json:"portfolios"`
+	MissingExchangeRates []string                `json:"missingExchangeRates"`
+}
+
+// CapitalGainsPortfolio totals one portfolio of a CapitalGainsReport in the base currency.
+type CapitalGainsPortfolio struct {
+	PortfolioID       string             `json:"portfolioId"`
+	PortfolioName     string             `json:"portfolioName"`
+	ProceedsBase      float64            `json:"proceedsBase"`
+	CostBasisBase     float64            `json:"costBasisBase"`
+	GainBase          float64            `json:"gainBase"`
+	ShortTermGainBase float64            `json:"shortTermGainBase"`
+	LongTermGainBase  float64            `json:"longTermGainBase"`
+	Funds             []CapitalGainsFund `json:"funds"`
+}
+
+// CapitalGainsFund totals the sales of one fund in a portfolio, in the fund's currency and
+// in the base currency.
+type CapitalGainsFund struct {
+	FundID            string             `json:"fundId"`
+	FundName          string             `json:"fundName"`
+	ISIN              string             `json:"isin"`
+	Currency          string             `json:"currency"`
+	SharesSold        float64            `json:"sharesSold"`
+	Proceeds          float64            `json:"proceeds"`
+	CostBasis         float64            `json:"costBasis"`
+	Gain              float64            `json:"gain"`
+	ShortTermGain     float64            `json:"shortTermGain"`
+	LongTermGain      float64            `json:"longTermGain"`
+	ProceedsBase      float64            `json:"proceedsBase"`
+	CostBasisBase     float64            `json:"costBasisBase"`
+	GainBase          float64            `json:"gainBase"`
+	ShortTermGainBase float64            `json:"shortTermGainBase"`
+	LongTermGainBase  float64            `json:"longTermGainBase"`
+	Sales             []CapitalGainsSale `json:"sales"`
+}
+
+// CapitalGainsSale is one sale. CostBasis is the average cost recorded when the shares were
+// sold; Acquisitions assigns the sold shares to earlier purchases first-in, first-out, which
+// determines the holding period and the rates converting the cost basis to the base currency.
+type CapitalGainsSale struct {
+	TransactionID string                    `json:"transactionId"`
+	SaleDate      string                    `json:"saleDate"`
+	SharesSold    float64                   `json:"sharesSold"`
+	Proceeds      float64                   `json:"proceeds"`
+	CostBasis     float64                   `json:"costBasis"`
+	Gain          float64                   `json:"gain"`
+	ProceedsBase  float64                   `json:"proceedsBase"`
+	CostBasisBase float64                   `json:"costBasisBase"`
+	GainBase      float64                   `json:"gainBase"`
+	HoldingPeriod string                    `json:"holdingPeriod"`
+	Acquisitions  []CapitalGainsAcquisition `json:"acquisitions"`
+}
+
+// CapitalGainsAcquisition is the part of a sale acquired on one date. Shares without a
+// matching purchase, from inconsistent history, have an empty AcquisitionDate and count as
+// short term.
+type CapitalGainsAcquisition struct {
+	AcquisitionDate string  `json:"acquisitionDate"`
+	Shares          float64 `json:"shares"`
+	HoldingDays     int     `json:"holdingDays"`
+	HoldingPeriod   string  `json:"holdingPeriod"`
+}
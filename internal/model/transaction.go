@@ -25,13 +25,14 @@ var ValidTransactionTypes = map[TransactionType]bool{
 // Transaction represents a buy or sell transaction for a portfolio fund.
 // Used internally for calculations and data processing.
 type Transaction struct {
-	ID              string    `json:"id"`
-	PortfolioFundID string    `json:"portfolioFundId"`
-	Date            time.Time `json:"date"`
-	Type            string    `json:"type"`
-	Shares          float64   `json:"shares"`
-	CostPerShare    float64   `json:"costPerShare"`
-	CreatedAt       time.Time `json:"createdAt"`
+	ID               string    `json:"id"`
+	PortfolioFundID  string    `json:"portfolioFundId"`
+	Date             time.Time `json:"date"`
+	Type             string    `json:"type"`
+	Shares           float64   `json:"shares"`
+	CostPerShare     float64   `json:"costPerShare"`
+	InvestmentPlanID string    `json:"investmentPlanId,omitempty"` // Set on transactions generated by an investment plan
+	CreatedAt        time.Time `json:"createdAt"`
 }
 
 // TransactionResponse represents a transaction with enriched data for API responses.
@@ -46,4 +47,6 @@ type TransactionResponse struct {
 	CostPerShare      float64   `json:"costPerShare"`
 	IbkrTransactionID string    `json:"ibkrTransactionId,omitempty"`
 	IbkrLinked        bool      `json:"ibkrLinked"`
+	InvestmentPlanID  string    `json:"investmentPlanId,omitempty"`
+	PlanGenerated     bool      `json:"planGenerated"`
 }
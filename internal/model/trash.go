@@ -0,0 +1,50 @@
+package model
+
+import "time"
+
+// TrashEntityType identifies the kind of entity held in the trash.
+type TrashEntityType string
+
+// Trash entity type constants define the entities whose deletion is soft and restorable.
+const (
+	TrashEntityPortfolio   TrashEntityType = "portfolio"
+	TrashEntityFund        TrashEntityType = "fund"
+	TrashEntityTransaction TrashEntityType = "transaction"
+	TrashEntityDividend    TrashEntityType = "dividend"
+)
+
+// ValidTrashEntityTypes is the authoritative set of allowed trash entity type values.
+var ValidTrashEntityTypes = map[TrashEntityType]bool{
+	TrashEntityPortfolio:   true,
+	TrashEntityFund:        true,
+	TrashEntityTransaction: true,
+	TrashEntityDividend:    true,
+}
+
+// TrashTable is a snapshot of the rows one deletion removed from a single table.
+// Column values are kept in their SQLite text representation (nil for NULL) so rows
+// can be re-inserted exactly as they were, relying on column affinity for the types.
+type TrashTable struct {
+	Table string               `json:"table"`
+	Rows  []map[string]*string `json:"rows"`
+}
+
+// TrashItem is a deleted entity awaiting restore or purge.
+// Payload lists the entity's own row first, followed by every row its deletion
+// cascaded to, in foreign-key insertion order.
+type TrashItem struct {
+	ID         string         `json:"id"`
+	EntityType string         `json:"entityType"`
+	EntityID   string         `json:"entityId"`
+	Label      string         `json:"label"`
+	DeletedAt  time.Time      `json:"deletedAt"`
+	ExpiresAt  time.Time      `json:"expiresAt"`
+	RequestID  string         `json:"requestId,omitempty"`
+	Contents   map[string]int `json:"contents"` // Row count per table, for display
+	Payload    []TrashTable   `json:"-"`
+}
+
+// TrashPurgeResult reports how many trash items were permanently removed.
+type TrashPurgeResult struct {
+	Purged int64 `json:"purged"`
+}
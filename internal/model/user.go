@@ -0,0 +1,154 @@
+package model
+
+import (
+	"strings"
+	"time"
+)
+
+// User is an account that can log in to the API.
+// PasswordHash is never serialized.
+type User struct {
+	ID           string     `json:"id"`
+	Username     string     `json:"username"`
+	IsAdmin      bool       `json:"isAdmin"`
+	CreatedAt    time.Time  `json:"createdAt"`
+	LastLoginAt  *time.Time `json:"lastLoginAt,omitempty"`
+	PasswordHash string     `json:"-"`
+}
+
+// Session is a login session. Only the hash of its bearer token is stored.
+type Session struct {
+	ID        string
+	UserID    string
+	TokenHash string
+	CreatedAt time.Time
+	ExpiresAt time.Time
+}
+
+// LoginResponse is returned after a successful login or initial setup.
+// Token is the bearer token to send in the Authorization header.
+type LoginResponse struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expiresAt"`
+	User      User      `json:"user"`
+}
+
+// PortfolioAccess is the level of access a user has to a portfolio.
+// Levels are ordered: owner implies write, write implies read.
+type PortfolioAccess string
+
+// Portfolio access level constants.
+const (
+	PortfolioAccessRead  PortfolioAccess = "read"
+	PortfolioAccessWrite PortfolioAccess = "write"
+	PortfolioAccessOwner PortfolioAccess = "owner"
+)
+
+// ValidShareAccess is the set of access levels that can be granted through a share.
+var ValidShareAccess = map[PortfolioAccess]bool{
+	PortfolioAccessRead:  true,
+	PortfolioAccessWrite: true,
+}
+
+// Allows reports whether this access level satisfies the required level.
+func (a PortfolioAccess) Allows(required PortfolioAccess) bool {
+	rank := map[PortfolioAccess]int{
+		PortfolioAccessRead:  1,
+		PortfolioAccessWrite: 2,
+		PortfolioAccessOwner: 3,
+	}
+	return rank[a] >= rank[required] && rank[a] > 0
+}
+
+// PortfolioShare grants a user other than the owner access to a portfolio.
+type PortfolioShare struct {
+	PortfolioID string          `json:"portfolioId"`
+	UserID      string          `json:"userId"`
+	Username    string          `json:"username"`
+	Access      PortfolioAccess `json:"access"`
+	CreatedAt   time.Time       `json:"createdAt"`
+}
+
+// AuthStatus reports whether the API requires authentication.
+// AuthEnabled is false until the first account is created through setup.
+type AuthStatus struct {
+	AuthEnabled bool `json:"authEnabled"`
+}
+
+// APIScope is a permission granted to a personal API token.
+type APIScope string
+
+// API token scope constants. A write scope implies the matching read scope.
+const (
+	ScopeReadPortfolio     APIScope = "read:portfolio"
+	ScopeWritePortfolio    APIScope = "write:portfolio"
+	ScopeReadFund          APIScope = "read:fund"
+	ScopeWriteFund         APIScope = "write:fund"
+	ScopeReadTransaction   APIScope = "read:transaction"
+	ScopeWriteTransaction  APIScope = "write:transaction"
+	ScopeReadDividend      APIScope = "read:dividend"
+	ScopeWriteDividend     APIScope = "write:dividend"
+	ScopeAdminIbkr         APIScope = "admin:ibkr"
+	ScopeAdminAudit        APIScope = "admin:audit"
+	ScopeAdminTrash        APIScope = "admin:trash"
+	ScopeAdminUser         APIScope = "admin:user"
+	ScopeAdminJob          APIScope = "admin:job"
+	ScopeAdminMaterialized APIScope = "admin:materialized"
+	ScopeAdminWebhook      APIScope = "admin:webhook"
+	ScopeAdminDigest       APIScope = "admin:digest"
+	ScopeAdminAlert        APIScope = "admin:alert"
+	ScopeDeveloper         APIScope = "developer"
+)
+
+// ValidAPIScopes is the set of scopes a token can be created with. The value
+// reports whether the scope is reserved for administrators.
+var ValidAPIScopes = map[APIScope]bool{
+	ScopeReadPortfolio:     false,
+	ScopeWritePortfolio:    false,
+	ScopeReadFund:          false,
+	ScopeWriteFund:         false,
+	ScopeReadTransaction:   false,
+	ScopeWriteTransaction:  false,
+	ScopeReadDividend:      false,
+	ScopeWriteDividend:     false,
+	ScopeAdminIbkr:         true,
+	ScopeAdminAudit:        true,
+	ScopeAdminTrash:        true,
+	ScopeAdminUser:         true,
+	ScopeAdminJob:          true,
+	ScopeAdminMaterialized: true,
+	ScopeAdminWebhook:      true,
+	ScopeAdminDigest:       true,
+	ScopeAdminAlert:        true,
+	ScopeDeveloper:         true,
+}
+
+// Satisfies reports whether holding this scope grants the required scope.
+// "write:x" also grants "read:x".
+func (s APIScope) Satisfies(required APIScope) bool {
+	if s == required {
+		return true
+	}
+	resource, ok := strings.CutPrefix(string(required), "read:")
+	return ok && string(s) == "write:"+resource
+}
+
+// APIToken is a personal API token. The token itself is only returned once, on creation.
+type APIToken struct {
+	ID         string     `json:"id"`
+	UserID     string     `json:"userId"`
+	Name       string     `json:"name"`
+	Scopes     []APIScope `json:"scopes"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	ExpiresAt  *time.Time `json:"expiresAt,omitempty"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+	RevokedAt  *time.Time `json:"revokedAt,omitempty"`
+	TokenHash  string     `json:"-"`
+}
+
+// CreateAPITokenResponse is returned when a token is created. Token is the
+// bearer token to send in the Authorization header; it cannot be retrieved later.
+type CreateAPITokenResponse struct {
+	Token    string   `json:"token"`
+	APIToken APIToken `json:"apiToken"`
+}
@@ -0,0 +1,89 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// WebhookEvent identifies a domain event that can be delivered to webhooks.
+type WebhookEvent string
+
+// Webhook event constants. WebhookEventTest is only sent by the test-fire endpoint.
+const (
+	WebhookEventIbkrInboxItems      WebhookEvent = "ibkr.inbox_items"      // An import added transactions to the IBKR inbox
+	WebhookEventIbkrImportCompleted WebhookEvent = "ibkr.import_completed" // An IBKR flex report import finished
+	WebhookEventIbkrTokenExpiring   WebhookEvent = "ibkr.token_expiring"   // The IBKR flex token expires within 30 days
+	WebhookEventPriceUpdateFailed   WebhookEvent = "price_update.failed"   // A fund price update failed for one or more funds
+	WebhookEventPortfolioValueDrop  WebhookEvent = "portfolio.value_drop"  // A portfolio lost more than the configured percentage in a day
+	WebhookEventAlertTriggered      WebhookEvent = "alert.triggered"       // An alert rule's condition started to hold
+	WebhookEventAlertResolved       WebhookEvent = "alert.resolved"        // An alert rule's condition no longer holds
+	WebhookEventTest                WebhookEvent = "webhook.test"
+)
+
+// ValidWebhookEvents is the authoritative set of event types a webhook can subscribe to.
+var ValidWebhookEvents = map[WebhookEvent]bool{
+	WebhookEventIbkrInboxItems:      true,
+	WebhookEventIbkrImportCompleted: true,
+	WebhookEventIbkrTokenExpiring:   true,
+	WebhookEventPriceUpdateFailed:   true,
+	WebhookEventPortfolioValueDrop:  true,
+	WebhookEventAlertTriggered:      true,
+	WebhookEventAlertResolved:       true,
+}
+
+// Webhook is a subscription that receives the selected events as signed HTTP POSTs.
+// Secret is only returned when the webhook is created or its secret is replaced.
+type Webhook struct {
+	ID         string         `json:"id"`
+	URL        string         `json:"url"`
+	EventTypes []WebhookEvent `json:"eventTypes"`
+	Secret     string         `json:"secret,omitempty"`
+	Enabled    bool           `json:"enabled"`
+	CreatedAt  time.Time      `json:"createdAt"`
+	UpdatedAt  time.Time      `json:"updatedAt"`
+}
+
+// Subscribes reports whether the webhook receives event.
+func (w Webhook) Subscribes(event WebhookEvent) bool {
+	for _, e := range w.EventTypes {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDeliveryStatus is the state of a webhook delivery.
+type WebhookDeliveryStatus string
+
+// Webhook delivery status constants. A pending delivery is retried until it succeeds
+// or runs out of attempts.
+const (
+	WebhookDeliveryPending   WebhookDeliveryStatus = "pending"
+	WebhookDeliverySucceeded WebhookDeliveryStatus = "succeeded"
+	WebhookDeliveryFailed    WebhookDeliveryStatus = "failed"
+)
+
+// WebhookDelivery is one event sent, or to be sent, to one webhook.
+type WebhookDelivery struct {
+	ID             string                `json:"id"`
+	WebhookID      string                `json:"webhookId"`
+	EventType      WebhookEvent          `json:"eventType"`
+	EventKey       string                `json:"-"`
+	Payload        json.RawMessage       `json:"payload"`
+	Status         WebhookDeliveryStatus `json:"status"`
+	Attempts       int                   `json:"attempts"`
+	ResponseStatus *int                  `json:"responseStatus,omitempty"` // HTTP status of the last attempt
+	LastError      string                `json:"lastError,omitempty"`
+	CreatedAt      time.Time             `json:"createdAt"`
+	NextAttemptAt  *time.Time            `json:"nextAttemptAt,omitempty"`
+	DeliveredAt    *time.Time            `json:"deliveredAt,omitempty"`
+}
+
+// WebhookPayload is the JSON body POSTed to a webhook.
+type WebhookPayload struct {
+	ID        string       `json:"id"` // Delivery ID, stable across retries
+	Event     WebhookEvent `json:"event"`
+	CreatedAt time.Time    `json:"createdAt"`
+	Data      any          `json:"data"`
+}
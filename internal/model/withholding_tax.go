@@ -0,0 +1,83 @@
+package model
+
+import "time"
+
+// WithholdingSource is where a dividend's withholding tax amount came from.
+type WithholdingSource string
+
+// Withholding source constants.
+const (
+	WithholdingSourceDefault WithholdingSource = "DEFAULT" // Gross amount × the fund domicile's default rate
+	WithholdingSourceManual  WithholdingSource = "MANUAL"  // Entered by the user
+	WithholdingSourceIBKR    WithholdingSource = "IBKR"    // Imported from IBKR "Withholding Tax" cash transactions
+)
+
+// WithholdingTaxRate is the default withholding tax rate for funds domiciled in a country,
+// usually the tax treaty rate. Country is the ISIN country code.
+type WithholdingTaxRate struct {
+	Country     string    `json:"country"`
+	RatePercent float64   `json:"ratePercent"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+// IBKRWithholdingTax is a "Withholding Tax" cash transaction from an IBKR Flex statement.
+// Amount is positive for tax withheld and negative for a refund.
+type IBKRWithholdingTax struct {
+	ID                string
+	IBKRTransactionID string
+	ISIN              string
+	Symbol            string
+	Currency          string
+	Amount            float64
+	PaymentDate       time.Time
+	ExDividendDate    *time.Time
+	Description       string
+	AppliedAt         *time.Time
+	ImportedAt        time.Time
+}
+
+// WithholdingTaxDividend is a dividend with its fund's domicile, the input of the yearly
+// withholding tax report.
+type WithholdingTaxDividend struct {
+	DividendID     string
+	PortfolioID    string
+	FundID         string
+	FundName       string
+	ISIN           string
+	Currency       string
+	ExDividendDate time.Time
+	GrossAmount    float64
+	WithholdingTax float64
+}
+
+// WithholdingTaxReport is the withholding tax on the dividends of one year, per fund
+// domicile and currency, for filing a tax return and reclaiming excess foreign tax.
+type WithholdingTaxReport struct {
+	Year      int                     `json:"year"`
+	Countries []WithholdingTaxCountry `json:"countries"`
+}
+
+// WithholdingTaxCountry totals the dividends of the funds domiciled in one country and paid
+// in one currency. Reclaimable is the tax withheld above the country's default rate.
+type WithholdingTaxCountry struct {
+	Country        string               `json:"country"`
+	Currency       string               `json:"currency"`
+	GrossAmount    float64              `json:"grossAmount"`
+	WithholdingTax float64              `json:"withholdingTax"`
+	NetAmount      float64              `json:"netAmount"`
+	EffectiveRate  float64              `json:"effectiveRate"`
+	DefaultRate    float64              `json:"defaultRate"`
+	Reclaimable    float64              `json:"reclaimable"`
+	Funds          []WithholdingTaxFund `json:"funds"`
+}
+
+// WithholdingTaxFund totals one fund's dividends within a WithholdingTaxCountry.
+type WithholdingTaxFund struct {
+	FundID         string  `json:"fundId"`
+	FundName       string  `json:"fundName"`
+	ISIN           string  `json:"isin"`
+	Dividends      int     `json:"dividends"`
+	GrossAmount    float64 `json:"grossAmount"`
+	WithholdingTax float64 `json:"withholdingTax"`
+	NetAmount      float64 `json:"netAmount"`
+}
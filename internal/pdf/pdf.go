@@ -0,0 +1,245 @@
+// Package pdf writes simple text-only PDF documents: A4 pages of headings, paragraphs and
+// tables in the standard Helvetica fonts. It covers the report exports without an external
+// dependency; the standard fonts need no embedding, so documents stay small.
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// A4 page size and margins in points.
+const (
+	pageWidth    = 595.28
+	pageHeight   = 841.89
+	margin       = 40.0
+	contentWidth = pageWidth - 2*margin
+)
+
+// Font sizes and line heights in points.
+const (
+	titleSize   = 16.0
+	headingSize = 12.0
+	textSize    = 9.0
+	lineHeight  = 1.4
+)
+
+// Align is the horizontal alignment of a table column.
+type Align int
+
+// Column alignments.
+const (
+	AlignLeft Align = iota
+	AlignRight
+)
+
+// Column describes a table column. Width is a share of the page's content width; the
+// widths of a table's columns should add up to at most 1.
+type Column struct {
+	Header string
+	Width  float64
+	Align  Align
+}
+
+// Document is a PDF document under construction. Content flows down the page and onto a
+// new page when it does not fit. The zero value is not usable; create one with New.
+type Document struct {
+	title string
+	pages []*bytes.Buffer
+	y     float64
+}
+
+// New creates a document whose first page starts with title.
+func New(title string) *Document {
+	d := &Document{title: title}
+	d.newPage()
+	d.write(margin, titleSize, true, title)
+	d.y -= titleSize * 0.6
+	return d
+}
+
+// Heading adds a bold section heading.
+func (d *Document) Heading(text string) {
+	d.space(headingSize*lineHeight*3 + textSize*lineHeight)
+	d.y -= headingSize * 0.6
+	d.write(margin, headingSize, true, text)
+}
+
+// Text adds a line of text, truncated to the page width.
+func (d *Document) Text(text string) {
+	d.write(margin, textSize, false, truncate(text, contentWidth, textSize))
+}
+
+// Table adds a table with a bold header row. The header is repeated on every page the table
+// spans. Cells are truncated to their column width; missing cells are left empty.
+func (d *Document) Table(columns []Column, rows [][]string) {
+	header := make([]string, len(columns))
+	for i, c := range columns {
+		header[i] = c.Header
+	}
+	d.space(textSize * lineHeight * 2)
+	d.row(columns, header, true)
+	for _, row := range rows {
+		if d.y-textSize*lineHeight < margin {
+			d.newPage()
+			d.row(columns, header, true)
+		}
+		d.row(columns, row, false)
+	}
+	d.y -= textSize * 0.6
+}
+
+// row writes one table row.
+func (d *Document) row(columns []Column, cells []string, bold bool) {
+	d.y -= textSize * lineHeight
+	x := margin
+	for i, c := range columns {
+		width := c.Width * contentWidth
+		if i < len(cells) {
+			text := truncate(cells[i], width-4, textSize)
+			tx := x
+			if c.Align == AlignRight {
+				tx = x + width - 4 - textWidth(text, textSize)
+			}
+			d.text(tx, textSize, bold, text)
+		}
+		x += width
+	}
+}
+
+// write adds a line of text at x below the previous content.
+func (d *Document) write(x, size float64, bold bool, text string) {
+	d.space(size * lineHeight)
+	d.y -= size * lineHeight
+	d.text(x, size, bold, text)
+}
+
+// text draws text with its baseline at the current position.
+func (d *Document) text(x, size float64, bold bool, text string) {
+	font := "F1"
+	if bold {
+		font = "F2"
+	}
+	fmt.Fprintf(d.pages[len(d.pages)-1], "BT /%s %.1f Tf %.2f %.2f Td (%s) Tj ET\n", font, size, x, d.y, escape(text))
+}
+
+// space starts a new page unless height points fit on the current one.
+func (d *Document) space(height float64) {
+	if d.y-height < margin {
+		d.newPage()
+	}
+}
+
+// newPage starts a new page.
+func (d *Document) newPage() {
+	d.pages = append(d.pages, &bytes.Buffer{})
+	d.y = pageHeight - margin
+}
+
+// Pages returns the number of pages.
+func (d *Document) Pages() int {
+	return len(d.pages)
+}
+
+// WriteTo writes the document to w as PDF 1.4. Every page is numbered in its footer.
+func (d *Document) WriteTo(w io.Writer) (int64, error) {
+	var buf bytes.Buffer
+	offsets := []int{}
+	object := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// Objects 1-4 are the catalog, page tree, fonts and info; pages follow as pairs of
+	// page and content stream.
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 6+2*i)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	object(fmt.Sprintf("<< /Title (%s) /Producer (Investment Portfolio Manager) >>", escape(d.title)))
+
+	for i, page := range d.pages {
+		footer := fmt.Sprintf("%d / %d", i+1, len(d.pages))
+		content := page.String() + fmt.Sprintf("BT /F1 8.0 Tf %.2f %.2f Td (%s) Tj ET\n",
+			pageWidth-margin-textWidth(footer, 8), margin/2, footer)
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] "+
+			"/Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			pageWidth, pageHeight, len(offsets)+2))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", len(content), content))
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R /Info 5 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	return buf.WriteTo(w)
+}
+
+// escape encodes text as the body of a PDF string literal in WinAnsiEncoding. Characters
+// the encoding lacks are replaced by '?'.
+func escape(text string) string {
+	var b strings.Builder
+	for _, r := range text {
+		switch {
+		case r == '\\' || r == '(' || r == ')':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r == '€':
+			b.WriteString(`\200`)
+		case r >= 0x20 && r < 0x7f:
+			b.WriteRune(r)
+		case r >= 0xa0 && r <= 0xff:
+			fmt.Fprintf(&b, `\%03o`, r)
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
+
+// truncate shortens text with an ellipsis so it fits in width points.
+func truncate(text string, width, size float64) string {
+	if textWidth(text, size) <= width {
+		return text
+	}
+	runes := []rune(text)
+	for len(runes) > 0 && textWidth(string(runes)+"...", size) > width {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "..."
+}
+
+// textWidth approximates the width of text in points, using the Helvetica widths of
+// printable ASCII characters and the width of 'o' for the others.
+func textWidth(text string, size float64) float64 {
+	units := 0
+	for _, r := range text {
+		if r >= 0x20 && r < 0x7f {
+			units += helveticaWidths[r-0x20]
+		} else {
+			units += 556
+		}
+	}
+	return float64(units) * size / 1000
+}
+
+// helveticaWidths are the Helvetica glyph widths of the characters 0x20-0x7e in 1/1000 em.
+var helveticaWidths = [...]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
@@ -0,0 +1,61 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestDocument_WriteTo(t *testing.T) {
+	d := New("Report (2025)")
+	d.Heading("Holdings")
+	d.Text("Valued in €")
+
+	rows := make([][]string, 80)
+	for i := range rows {
+		rows[i] = []string{fmt.Sprintf("Fund %d", i), "1,234.56"}
+	}
+	d.Table([]Column{{Header: "Fund", Width: 0.7}, {Header: "Value", Width: 0.3, Align: AlignRight}}, rows)
+
+	if d.Pages() != 2 {
+		t.Fatalf("Pages() = %d, want 2", d.Pages())
+	}
+
+	var buf bytes.Buffer
+	if _, err := d.WriteTo(&buf); err != nil {
+		t.Fatalf("WriteTo() error: %v", err)
+	}
+	out := buf.String()
+
+	for _, want := range []string{"%PDF-1.4", "/Count 2", `(Report \(2025\))`, `(Valued in \200)`, "(Fund 79)", "(2 / 2)", "%%EOF"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output does not contain %q", want)
+		}
+	}
+	if n := strings.Count(out, "(Fund) Tj"); n != 2 {
+		t.Errorf("table header written %d times, want once per page", n)
+	}
+
+	// The xref table must point at each object.
+	xref := out[strings.Index(out, "\nxref\n")+1:]
+	for i, line := range strings.Split(xref, "\n")[3:9] {
+		var offset int
+		if _, err := fmt.Sscanf(line, "%010d", &offset); err != nil {
+			t.Fatalf("bad xref line %q: %v", line, err)
+		}
+		if !strings.HasPrefix(out[offset:], fmt.Sprintf("%d 0 obj", i+1)) {
+			t.Errorf("xref entry %d does not point at its object", i+1)
+		}
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	if got := truncate("short", 100, 9); got != "short" {
+		t.Errorf("truncate() = %q, want unchanged", got)
+	}
+	got := truncate(strings.Repeat("wide ", 20), 50, 9)
+	if !strings.HasSuffix(got, "...") || textWidth(got, 9) > 50 {
+		t.Errorf("truncate() = %q (%.1fpt), want an ellipsis within 50pt", got, textWidth(got, 9))
+	}
+}
@@ -0,0 +1,334 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/apperrors"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/model"
+)
+
+// alertRuleColumns is the column list shared by every alert rule SELECT.
+const alertRuleColumns = `id, name, type, portfolio_id, fund_id, direction, period, threshold, enabled, created_at, updated_at`
+
+// alertColumns is the column list shared by every alert SELECT. Alerts are always joined
+// with their rule as r.
+const alertColumns = `a.id, a.rule_id, r.name, r.type, a.status, a.message, a.value,
+	a.triggered_at, a.acknowledged_at, a.resolved_at`
+
+// AlertRepository provides data access methods for alert rules and the alerts they raise.
+type AlertRepository struct {
+	db *sql.DB
+	tx *sql.Tx
+}
+
+// NewAlertRepository creates a new AlertRepository with the provided database connection.
+func NewAlertRepository(db *sql.DB) *AlertRepository {
+	return &AlertRepository{db: db}
+}
+
+// WithTx returns a new AlertRepository scoped to the provided transaction.
+func (r *AlertRepository) WithTx(tx *sql.Tx) *AlertRepository {
+	return &AlertRepository{
+		db: r.db,
+		tx: tx,
+	}
+}
+
+// getQuerier returns the active transaction if one is set, otherwise the database connection.
+func (r *AlertRepository) getQuerier() Querier {
+	if r.tx != nil {
+		return traced(r.tx)
+	}
+	return traced(r.db)
+}
+
+// GetAlertRules retrieves all alert rules, oldest first.
+func (r *AlertRepository) GetAlertRules() ([]model.AlertRule, error) {
+	return r.queryAlertRules(`SELECT ` + alertRuleColumns + ` FROM alert_rule ORDER BY created_at, id`)
+}
+
+// GetEnabledFundAlertRules retrieves the enabled rules that watch fund prices.
+func (r *AlertRepository) GetEnabledFundAlertRules() ([]model.AlertRule, error) {
+	return r.queryAlertRules(`
+		SELECT `+alertRuleColumns+`
+		FROM alert_rule
+		WHERE enabled = 1 AND type IN (?, ?)
+		ORDER BY created_at, id
+	`, model.AlertRuleFundPrice, model.AlertRuleFundMove)
+}
+
+// GetEnabledPortfolioAlertRules retrieves the enabled rules that watch a portfolio.
+// An empty portfolioID returns the rules of every portfolio.
+func (r *AlertRepository) GetEnabledPortfolioAlertRules(portfolioID string) ([]model.AlertRule, error) {
+	query := `
+		SELECT ` + alertRuleColumns + `
+		FROM alert_rule
+		WHERE enabled = 1 AND type IN (?, ?)`
+	args := []any{model.AlertRulePortfolioValue, model.AlertRulePositionLoss}
+	if portfolioID != "" {
+		query += ` AND portfolio_id = ?`
+		args = append(args, portfolioID)
+	}
+	return r.queryAlertRules(query+` ORDER BY created_at, id`, args...)
+}
+
+func (r *AlertRepository) queryAlertRules(query string, args ...any) ([]model.AlertRule, error) {
+	rows, err := r.getQuerier().Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query alert rules: %w", err)
+	}
+	defer rows.Close()
+
+	rules := []model.AlertRule{}
+	for rows.Next() {
+		rule, err := scanAlertRule(rows)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating alert rules: %w", err)
+	}
+	return rules, nil
+}
+
+// GetAlertRule retrieves an alert rule by ID. Returns ErrAlertRuleNotFound if it does not exist.
+func (r *AlertRepository) GetAlertRule(ruleID string) (model.AlertRule, error) {
+	row := r.getQuerier().QueryRow(`SELECT `+alertRuleColumns+` FROM alert_rule WHERE id = ?`, ruleID)
+
+	rule, err := scanAlertRule(row)
+	if err == sql.ErrNoRows {
+		return model.AlertRule{}, apperrors.ErrAlertRuleNotFound
+	}
+	if err != nil {
+		return model.AlertRule{}, err
+	}
+	return rule, nil
+}
+
+func scanAlertRule(s scanner) (model.AlertRule, error) {
+	var rule model.AlertRule
+	var portfolioID, fundID, direction, period sql.NullString
+	var createdAtStr, updatedAtStr string
+
+	if err := s.Scan(&rule.ID, &rule.Name, &rule.Type, &portfolioID, &fundID, &direction, &period,
+		&rule.Threshold, &rule.Enabled, &createdAtStr, &updatedAtStr); err != nil {
+		if err == sql.ErrNoRows {
+			return model.AlertRule{}, err
+		}
+		return model.AlertRule{}, fmt.Errorf("failed to scan alert rule: %w", err)
+	}
+	rule.PortfolioID = portfolioID.String
+	rule.FundID = fundID.String
+	rule.Direction = model.AlertDirection(direction.String)
+	rule.Period = model.AlertPeriod(period.String)
+
+	var err error
+	if rule.CreatedAt, err = ParseTime(createdAtStr); err != nil {
+		return model.AlertRule{}, fmt.Errorf("failed to parse created_at: %w", err)
+	}
+	if rule.UpdatedAt, err = ParseTime(updatedAtStr); err != nil {
+		return model.AlertRule{}, fmt.Errorf("failed to parse updated_at: %w", err)
+	}
+	return rule, nil
+}
+
+// alertRuleArgs returns the nullable columns of a rule, with empty values stored as NULL.
+func alertRuleArgs(rule *model.AlertRule) (portfolioID, fundID, direction, period any) {
+	return nullableString(rule.PortfolioID), nullableString(rule.FundID),
+		nullableString(string(rule.Direction)), nullableString(string(rule.Period))
+}
+
+// InsertAlertRule stores a new alert rule.
+func (r *AlertRepository) InsertAlertRule(ctx context.Context, rule *model.AlertRule) error {
+	portfolioID, fundID, direction, period := alertRuleArgs(rule)
+	_, err := r.getQuerier().ExecContext(ctx, `
+		INSERT INTO alert_rule (id, name, type, portfolio_id, fund_id, direction, period, threshold, enabled, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, rule.ID, rule.Name, rule.Type, portfolioID, fundID, direction, period, rule.Threshold, rule.Enabled,
+		rule.CreatedAt.UTC().Format("2006-01-02 15:04:05"), rule.UpdatedAt.UTC().Format("2006-01-02 15:04:05"))
+	if err != nil {
+		return fmt.Errorf("failed to insert alert rule: %w", err)
+	}
+	return nil
+}
+
+// UpdateAlertRule replaces every field of an alert rule except its ID and creation time.
+// Returns ErrAlertRuleNotFound if it does not exist.
+func (r *AlertRepository) UpdateAlertRule(ctx context.Context, rule *model.AlertRule) error {
+	portfolioID, fundID, direction, period := alertRuleArgs(rule)
+	result, err := r.getQuerier().ExecContext(ctx, `
+		UPDATE alert_rule
+		SET name = ?, type = ?, portfolio_id = ?, fund_id = ?, direction = ?, period = ?, threshold = ?, enabled = ?, updated_at = ?
+		WHERE id = ?
+	`, rule.Name, rule.Type, portfolioID, fundID, direction, period, rule.Threshold, rule.Enabled,
+		rule.UpdatedAt.UTC().Format("2006-01-02 15:04:05"), rule.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update alert rule: %w", err)
+	}
+	return requireAffected(result, apperrors.ErrAlertRuleNotFound)
+}
+
+// DeleteAlertRule removes an alert rule and its alerts. Returns ErrAlertRuleNotFound if it does not exist.
+func (r *AlertRepository) DeleteAlertRule(ctx context.Context, ruleID string) error {
+	result, err := r.getQuerier().ExecContext(ctx, `DELETE FROM alert_rule WHERE id = ?`, ruleID)
+	if err != nil {
+		return fmt.Errorf("failed to delete alert rule: %w", err)
+	}
+	return requireAffected(result, apperrors.ErrAlertRuleNotFound)
+}
+
+// GetAlerts retrieves the most recently triggered alerts, newest first. When statuses is
+// not empty only alerts in one of those statuses are returned.
+func (r *AlertRepository) GetAlerts(statuses []model.AlertStatus, limit int) ([]model.Alert, error) {
+	query := `SELECT ` + alertColumns + ` FROM alert a JOIN alert_rule r ON a.rule_id = r.id`
+	args := make([]any, 0, len(statuses)+1)
+	if len(statuses) > 0 {
+		query += ` WHERE a.status IN (` + placeholders(len(statuses)) + `)`
+		for _, status := range statuses {
+			args = append(args, status)
+		}
+	}
+	query += ` ORDER BY a.triggered_at DESC, a.id DESC LIMIT ?`
+	args = append(args, limit)
+
+	rows, err := r.getQuerier().Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query alerts: %w", err)
+	}
+	defer rows.Close()
+
+	alerts := []model.Alert{}
+	for rows.Next() {
+		alert, err := scanAlert(rows)
+		if err != nil {
+			return nil, err
+		}
+		alerts = append(alerts, alert)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating alerts: %w", err)
+	}
+	return alerts, nil
+}
+
+// GetAlert retrieves an alert by ID. Returns ErrAlertNotFound if it does not exist.
+func (r *AlertRepository) GetAlert(alertID string) (model.Alert, error) {
+	row := r.getQuerier().QueryRow(`
+		SELECT `+alertColumns+`
+		FROM alert a JOIN alert_rule r ON a.rule_id = r.id
+		WHERE a.id = ?
+	`, alertID)
+
+	alert, err := scanAlert(row)
+	if err == sql.ErrNoRows {
+		return model.Alert{}, apperrors.ErrAlertNotFound
+	}
+	if err != nil {
+		return model.Alert{}, err
+	}
+	return alert, nil
+}
+
+// GetOpenAlerts retrieves the open (active or acknowledged) alert of each of the given
+// rules, keyed by rule ID. Rules without an open alert are absent from the map.
+func (r *AlertRepository) GetOpenAlerts(ruleIDs []string) (map[string]model.Alert, error) {
+	alerts := make(map[string]model.Alert)
+	if len(ruleIDs) == 0 {
+		return alerts, nil
+	}
+
+	args := []any{model.AlertStatusActive, model.AlertStatusAcknowledged}
+	for _, id := range ruleIDs {
+		args = append(args, id)
+	}
+	rows, err := r.getQuerier().Query(`
+		SELECT `+alertColumns+`
+		FROM alert a JOIN alert_rule r ON a.rule_id = r.id
+		WHERE a.status IN (?, ?) AND a.rule_id IN (`+placeholders(len(ruleIDs))+`)
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query open alerts: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		alert, err := scanAlert(rows)
+		if err != nil {
+			return nil, err
+		}
+		alerts[alert.RuleID] = alert
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating open alerts: %w", err)
+	}
+	return alerts, nil
+}
+
+func scanAlert(s scanner) (model.Alert, error) {
+	var a model.Alert
+	var triggeredAtStr string
+	var acknowledgedAtStr, resolvedAtStr sql.NullString
+
+	if err := s.Scan(&a.ID, &a.RuleID, &a.RuleName, &a.RuleType, &a.Status, &a.Message, &a.Value,
+		&triggeredAtStr, &acknowledgedAtStr, &resolvedAtStr); err != nil {
+		if err == sql.ErrNoRows {
+			return model.Alert{}, err
+		}
+		return model.Alert{}, fmt.Errorf("failed to scan alert: %w", err)
+	}
+
+	var err error
+	if a.TriggeredAt, err = ParseTime(triggeredAtStr); err != nil {
+		return model.Alert{}, fmt.Errorf("failed to parse triggered_at: %w", err)
+	}
+	for _, col := range []struct {
+		name string
+		src  sql.NullString
+		dst  **time.Time
+	}{
+		{"acknowledged_at", acknowledgedAtStr, &a.AcknowledgedAt},
+		{"resolved_at", resolvedAtStr, &a.ResolvedAt},
+	} {
+		if !col.src.Valid {
+			continue
+		}
+		parsed, err := ParseTime(col.src.String)
+		if err != nil {
+			return model.Alert{}, fmt.Errorf("failed to parse %s: %w", col.name, err)
+		}
+		*col.dst = &parsed
+	}
+	return a, nil
+}
+
+// InsertAlert stores a newly triggered alert.
+func (r *AlertRepository) InsertAlert(ctx context.Context, a *model.Alert) error {
+	_, err := r.getQuerier().ExecContext(ctx, `
+		INSERT INTO alert (id, rule_id, status, message, value, triggered_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, a.ID, a.RuleID, a.Status, a.Message, a.Value, a.TriggeredAt.UTC().Format("2006-01-02 15:04:05"))
+	if err != nil {
+		return fmt.Errorf("failed to insert alert: %w", err)
+	}
+	return nil
+}
+
+// UpdateAlertStatus moves an alert to status and records when: acknowledged_at for
+// acknowledged alerts, resolved_at for resolved ones. Returns ErrAlertNotFound if it does not exist.
+func (r *AlertRepository) UpdateAlertStatus(ctx context.Context, alertID string, status model.AlertStatus, at time.Time) error {
+	column := "resolved_at"
+	if status == model.AlertStatusAcknowledged {
+		column = "acknowledged_at"
+	}
+	result, err := r.getQuerier().ExecContext(ctx, `UPDATE alert SET status = ?, `+column+` = ? WHERE id = ?`,
+		status, at.UTC().Format("2006-01-02 15:04:05"), alertID)
+	if err != nil {
+		return fmt.Errorf("failed to update alert status: %w", err)
+	}
+	return requireAffected(result, apperrors.ErrAlertNotFound)
+}
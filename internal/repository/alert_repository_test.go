@@ -0,0 +1,98 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/apperrors"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/model"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/repository"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/testutil"
+)
+
+func TestAlertRepository(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	repo := repository.NewAlertRepository(db)
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Second)
+
+	portfolio := testutil.NewPortfolio().Build(t, db)
+	fund := testutil.NewFund().Build(t, db)
+	fundRule := model.AlertRule{ID: testutil.MakeID(), Name: "Above 100", Type: model.AlertRuleFundPrice, FundID: fund.ID,
+		Direction: model.AlertDirectionAbove, Threshold: 100, Enabled: true, CreatedAt: now, UpdatedAt: now}
+	floorRule := model.AlertRule{ID: testutil.MakeID(), Name: "Floor", Type: model.AlertRulePortfolioValue, PortfolioID: portfolio.ID,
+		Threshold: 1000, Enabled: true, CreatedAt: now, UpdatedAt: now}
+	for _, rule := range []*model.AlertRule{&fundRule, &floorRule} {
+		if err := repo.InsertAlertRule(ctx, rule); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	t.Run("rules round-trip and are filtered by kind", func(t *testing.T) {
+		got, err := repo.GetAlertRule(fundRule.ID)
+		if err != nil || got.FundID != fund.ID || got.PortfolioID != "" || got.Direction != model.AlertDirectionAbove || got.Period != "" {
+			t.Errorf("expected the fund rule, got %+v (%v)", got, err)
+		}
+		fundRules, err := repo.GetEnabledFundAlertRules()
+		if err != nil || len(fundRules) != 1 || fundRules[0].ID != fundRule.ID {
+			t.Errorf("expected only the fund rule, got %+v (%v)", fundRules, err)
+		}
+		portfolioRules, err := repo.GetEnabledPortfolioAlertRules(portfolio.ID)
+		if err != nil || len(portfolioRules) != 1 || portfolioRules[0].ID != floorRule.ID {
+			t.Errorf("expected only the floor rule, got %+v (%v)", portfolioRules, err)
+		}
+
+		floorRule.Enabled = false
+		if err := repo.UpdateAlertRule(ctx, &floorRule); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if rules, _ := repo.GetEnabledPortfolioAlertRules(""); len(rules) != 0 {
+			t.Errorf("expected the disabled rule to be skipped, got %+v", rules)
+		}
+	})
+
+	t.Run("alert states", func(t *testing.T) {
+		alert := model.Alert{ID: testutil.MakeID(), RuleID: fundRule.ID, Status: model.AlertStatusActive, Message: "up", Value: 101, TriggeredAt: now}
+		if err := repo.InsertAlert(ctx, &alert); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		open, err := repo.GetOpenAlerts([]string{fundRule.ID, floorRule.ID})
+		if err != nil || len(open) != 1 || open[fundRule.ID].RuleName != "Above 100" {
+			t.Errorf("expected the open alert with its rule name, got %+v (%v)", open, err)
+		}
+
+		if err := repo.UpdateAlertStatus(ctx, alert.ID, model.AlertStatusAcknowledged, now); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := repo.UpdateAlertStatus(ctx, alert.ID, model.AlertStatusResolved, now); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		got, err := repo.GetAlert(alert.ID)
+		if err != nil || got.Status != model.AlertStatusResolved || got.AcknowledgedAt == nil || got.ResolvedAt == nil {
+			t.Errorf("expected a resolved alert with both timestamps, got %+v (%v)", got, err)
+		}
+		if open, _ := repo.GetOpenAlerts([]string{fundRule.ID}); len(open) != 0 {
+			t.Errorf("expected no open alerts, got %+v", open)
+		}
+		if active, _ := repo.GetAlerts([]model.AlertStatus{model.AlertStatusActive}, 10); len(active) != 0 {
+			t.Errorf("expected no active alerts, got %+v", active)
+		}
+		if err := repo.UpdateAlertStatus(ctx, testutil.MakeID(), model.AlertStatusResolved, now); !errors.Is(err, apperrors.ErrAlertNotFound) {
+			t.Errorf("expected ErrAlertNotFound, got %v", err)
+		}
+	})
+
+	t.Run("deleting a rule removes its alerts", func(t *testing.T) {
+		if err := repo.DeleteAlertRule(ctx, fundRule.ID); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if alerts, _ := repo.GetAlerts(nil, 10); len(alerts) != 0 {
+			t.Errorf("expected the alerts to be deleted, got %+v", alerts)
+		}
+		if err := repo.DeleteAlertRule(ctx, fundRule.ID); !errors.Is(err, apperrors.ErrAlertRuleNotFound) {
+			t.Errorf("expected ErrAlertRuleNotFound, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/model"
+)
+
+// AllocationRepository provides data access methods for portfolio allocation targets.
+type AllocationRepository struct {
+	db *sql.DB
+	tx *sql.Tx
+}
+
+// NewAllocationRepository creates a new AllocationRepository with the provided database connection.
+func NewAllocationRepository(db *sql.DB) *AllocationRepository {
+	return &AllocationRepository{db: db}
+}
+
+// WithTx returns a new AllocationRepository scoped to the provided transaction.
+func (r *AllocationRepository) WithTx(tx *sql.Tx) *AllocationRepository {
+	return &AllocationRepository{
+		db: r.db,
+		tx: tx,
+	}
+}
+
+// getQuerier returns the active transaction if one is set, otherwise the database connection.
+func (r *AllocationRepository) getQuerier() Querier {
+	if r.tx != nil {
+		return traced(r.tx)
+	}
+	return traced(r.db)
+}
+
+// GetAllocationTargets retrieves the allocation targets of a portfolio, largest target first
+// and then by fund name or asset class. Fund targets include the fund name.
+func (r *AllocationRepository) GetAllocationTargets(portfolioID string) ([]model.AllocationTarget, error) {
+	rows, err := r.getQuerier().Query(`
+		SELECT t.id, t.portfolio_id, t.fund_id, f.name, t.asset_class, t.target_percent, t.tolerance_percent,
+			t.created_at, t.updated_at
+		FROM allocation_target t
+		LEFT JOIN fund f ON t.fund_id = f.id
+		WHERE t.portfolio_id = ?
+		ORDER BY t.target_percent DESC, COALESCE(f.name, t.asset_class), t.id
+	`, portfolioID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query allocation targets: %w", err)
+	}
+	defer rows.Close()
+
+	targets := []model.AllocationTarget{}
+	for rows.Next() {
+		var t model.AllocationTarget
+		var fundID, fundName, assetClass sql.NullString
+		var createdAtStr, updatedAtStr string
+		if err := rows.Scan(&t.ID, &t.PortfolioID, &fundID, &fundName, &assetClass, &t.TargetPercent,
+			&t.TolerancePercent, &createdAtStr, &updatedAtStr); err != nil {
+			return nil, fmt.Errorf("failed to scan allocation target: %w", err)
+		}
+		t.FundID = fundID.String
+		t.FundName = fundName.String
+		t.AssetClass = assetClass.String
+		if t.CreatedAt, err = ParseTime(createdAtStr); err != nil {
+			return nil, fmt.Errorf("failed to parse created_at: %w", err)
+		}
+		if t.UpdatedAt, err = ParseTime(updatedAtStr); err != nil {
+			return nil, fmt.Errorf("failed to parse updated_at: %w", err)
+		}
+		targets = append(targets, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating allocation targets: %w", err)
+	}
+	return targets, nil
+}
+
+// ReplaceAllocationTargets replaces every allocation target of a portfolio with targets.
+// Call it within a transaction so the portfolio is never left half-updated.
+func (r *AllocationRepository) ReplaceAllocationTargets(ctx context.Context, portfolioID string, targets []model.AllocationTarget) error {
+	if _, err := r.getQuerier().ExecContext(ctx, `DELETE FROM allocation_target WHERE portfolio_id = ?`, portfolioID); err != nil {
+		return fmt.Errorf("failed to delete allocation targets: %w", err)
+	}
+
+	for _, t := range targets {
+		_, err := r.getQuerier().ExecContext(ctx, `
+			INSERT INTO allocation_target (id, portfolio_id, fund_id, asset_class, target_percent, tolerance_percent, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		`, t.ID, portfolioID, nullableString(t.FundID), nullableString(t.AssetClass), t.TargetPercent, t.TolerancePercent,
+			t.CreatedAt.UTC().Format("2006-01-02 15:04:05"), t.UpdatedAt.UTC().Format("2006-01-02 15:04:05"))
+		if err != nil {
+			return fmt.Errorf("failed to insert allocation target: %w", err)
+		}
+	}
+	return nil
+}
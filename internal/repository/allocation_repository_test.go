@@ -0,0 +1,57 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/model"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/repository"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/testutil"
+)
+
+func TestAllocationRepository(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	repo := repository.NewAllocationRepository(db)
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Second)
+
+	portfolio := testutil.NewPortfolio().Build(t, db)
+	other := testutil.NewPortfolio().Build(t, db)
+	fund := testutil.NewFund().WithName("World ETF").Build(t, db)
+
+	t.Run("replace stores fund and asset class targets per portfolio", func(t *testing.T) {
+		fundTargets := []model.AllocationTarget{
+			{ID: testutil.MakeID(), FundID: fund.ID, TargetPercent: 100, TolerancePercent: 5, CreatedAt: now, UpdatedAt: now},
+		}
+		classTargets := []model.AllocationTarget{
+			{ID: testutil.MakeID(), AssetClass: "STOCK", TargetPercent: 30, CreatedAt: now, UpdatedAt: now},
+			{ID: testutil.MakeID(), AssetClass: "FUND", TargetPercent: 70, CreatedAt: now, UpdatedAt: now},
+		}
+		if err := repo.ReplaceAllocationTargets(ctx, portfolio.ID, fundTargets); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := repo.ReplaceAllocationTargets(ctx, other.ID, classTargets); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		got, err := repo.GetAllocationTargets(portfolio.ID)
+		if err != nil || len(got) != 1 || got[0].FundName != "World ETF" || got[0].AssetClass != "" || !got[0].CreatedAt.Equal(now) {
+			t.Errorf("expected the fund target, got %+v (%v)", got, err)
+		}
+		got, err = repo.GetAllocationTargets(other.ID)
+		if err != nil || len(got) != 2 || got[0].AssetClass != "FUND" || got[0].FundID != "" {
+			t.Errorf("expected the asset class targets, largest first, got %+v (%v)", got, err)
+		}
+	})
+
+	t.Run("replace with nothing removes the targets", func(t *testing.T) {
+		if err := repo.ReplaceAllocationTargets(ctx, portfolio.ID, nil); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		got, err := repo.GetAllocationTargets(portfolio.ID)
+		if err != nil || len(got) != 0 {
+			t.Errorf("expected no targets, got %+v (%v)", got, err)
+		}
+	})
+}
@@ -0,0 +1,189 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/apperrors"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/model"
+)
+
+// apiTokenColumns is the column list shared by every API token SELECT.
+const apiTokenColumns = `id, user_id, name, token_hash, scopes, created_at, expires_at, last_used_at, revoked_at`
+
+// APITokenRepository provides data access methods for personal API tokens.
+type APITokenRepository struct {
+	db *sql.DB
+	tx *sql.Tx
+}
+
+// NewAPITokenRepository creates a new APITokenRepository with the provided database connection.
+func NewAPITokenRepository(db *sql.DB) *APITokenRepository {
+	return &APITokenRepository{db: db}
+}
+
+// WithTx returns a new APITokenRepository scoped to the provided transaction.
+func (r *APITokenRepository) WithTx(tx *sql.Tx) *APITokenRepository {
+	return &APITokenRepository{
+		db: r.db,
+		tx: tx,
+	}
+}
+
+// getQuerier returns the active transaction if one is set, otherwise the database connection.
+func (r *APITokenRepository) getQuerier() Querier {
+	if r.tx != nil {
+		return traced(r.tx)
+	}
+	return traced(r.db)
+}
+
+// GetAPITokens retrieves all tokens of a user, including revoked and expired ones, newest first.
+func (r *APITokenRepository) GetAPITokens(userID string) ([]model.APIToken, error) {
+	rows, err := r.getQuerier().Query(`
+		SELECT `+apiTokenColumns+`
+		FROM api_token
+		WHERE user_id = ?
+		ORDER BY created_at DESC
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query API tokens: %w", err)
+	}
+	defer rows.Close()
+
+	tokens := []model.APIToken{}
+	for rows.Next() {
+		t, err := scanAPIToken(rows)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating API tokens: %w", err)
+	}
+	return tokens, nil
+}
+
+// GetAPIToken retrieves a token by ID. Returns ErrAPITokenNotFound if it does not exist.
+func (r *APITokenRepository) GetAPIToken(tokenID string) (model.APIToken, error) {
+	return r.getAPIToken(`WHERE id = ?`, tokenID)
+}
+
+// GetActiveAPITokenByHash retrieves the unrevoked, unexpired token with the given hash.
+// Returns ErrSessionInvalid if no such token exists.
+func (r *APITokenRepository) GetActiveAPITokenByHash(tokenHash string, now time.Time) (model.APIToken, error) {
+	t, err := r.getAPIToken(`WHERE token_hash = ? AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > ?)`,
+		tokenHash, now.UTC().Format("2006-01-02 15:04:05"))
+	if err == apperrors.ErrAPITokenNotFound {
+		return model.APIToken{}, apperrors.ErrSessionInvalid
+	}
+	return t, err
+}
+
+func (r *APITokenRepository) getAPIToken(where string, args ...any) (model.APIToken, error) {
+	//nolint:gosec // G202: where is a constant supplied by the caller, the values are parameterized
+	row := r.getQuerier().QueryRow(`SELECT `+apiTokenColumns+` FROM api_token `+where, args...)
+
+	t, err := scanAPIToken(row)
+	if err == sql.ErrNoRows {
+		return model.APIToken{}, apperrors.ErrAPITokenNotFound
+	}
+	if err != nil {
+		return model.APIToken{}, err
+	}
+	return t, nil
+}
+
+func scanAPIToken(s scanner) (model.APIToken, error) {
+	var t model.APIToken
+	var scopes, createdAtStr string
+	var expiresAtStr, lastUsedAtStr, revokedAtStr sql.NullString
+
+	if err := s.Scan(&t.ID, &t.UserID, &t.Name, &t.TokenHash, &scopes,
+		&createdAtStr, &expiresAtStr, &lastUsedAtStr, &revokedAtStr); err != nil {
+		if err == sql.ErrNoRows {
+			return model.APIToken{}, err
+		}
+		return model.APIToken{}, fmt.Errorf("failed to scan API token: %w", err)
+	}
+
+	t.Scopes = []model.APIScope{}
+	for _, scope := range strings.Fields(scopes) {
+		t.Scopes = append(t.Scopes, model.APIScope(scope))
+	}
+
+	var err error
+	if t.CreatedAt, err = ParseTime(createdAtStr); err != nil {
+		return model.APIToken{}, fmt.Errorf("failed to parse created_at: %w", err)
+	}
+	for _, col := range []struct {
+		name string
+		src  sql.NullString
+		dst  **time.Time
+	}{
+		{"expires_at", expiresAtStr, &t.ExpiresAt},
+		{"last_used_at", lastUsedAtStr, &t.LastUsedAt},
+		{"revoked_at", revokedAtStr, &t.RevokedAt},
+	} {
+		if !col.src.Valid {
+			continue
+		}
+		parsed, err := ParseTime(col.src.String)
+		if err != nil {
+			return model.APIToken{}, fmt.Errorf("failed to parse %s: %w", col.name, err)
+		}
+		*col.dst = &parsed
+	}
+
+	return t, nil
+}
+
+// InsertAPIToken stores a new API token.
+func (r *APITokenRepository) InsertAPIToken(ctx context.Context, t *model.APIToken) error {
+	scopes := make([]string, len(t.Scopes))
+	for i, s := range t.Scopes {
+		scopes[i] = string(s)
+	}
+
+	var expiresAt any
+	if t.ExpiresAt != nil {
+		expiresAt = t.ExpiresAt.UTC().Format("2006-01-02 15:04:05")
+	}
+
+	_, err := r.getQuerier().ExecContext(ctx, `
+		INSERT INTO api_token (id, user_id, name, token_hash, scopes, created_at, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, t.ID, t.UserID, t.Name, t.TokenHash, strings.Join(scopes, " "),
+		t.CreatedAt.UTC().Format("2006-01-02 15:04:05"), expiresAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert API token: %w", err)
+	}
+	return nil
+}
+
+// RevokeAPIToken marks a token as revoked. Returns ErrAPITokenNotFound if it does not exist.
+func (r *APITokenRepository) RevokeAPIToken(ctx context.Context, tokenID string, at time.Time) error {
+	result, err := r.getQuerier().ExecContext(ctx,
+		`UPDATE api_token SET revoked_at = ? WHERE id = ?`, at.UTC().Format("2006-01-02 15:04:05"), tokenID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke API token: %w", err)
+	}
+	return requireAffected(result, apperrors.ErrAPITokenNotFound)
+}
+
+// TouchAPIToken records that a token was used at the given time. To avoid a write on every
+// request the timestamp is only updated when the stored value is older than staleAfter.
+func (r *APITokenRepository) TouchAPIToken(ctx context.Context, tokenID string, at time.Time, staleAfter time.Duration) error {
+	_, err := r.getQuerier().ExecContext(ctx, `
+		UPDATE api_token SET last_used_at = ?
+		WHERE id = ? AND (last_used_at IS NULL OR last_used_at < ?)
+	`, at.UTC().Format("2006-01-02 15:04:05"), tokenID, at.Add(-staleAfter).UTC().Format("2006-01-02 15:04:05"))
+	if err != nil {
+		return fmt.Errorf("failed to update API token last use: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/apperrors"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/model"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/repository"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/testutil"
+)
+
+func TestAPITokenRepository(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	repo := repository.NewAPITokenRepository(db)
+	ctx := context.Background()
+	user := insertTestUser(t, repository.NewUserRepository(db), "alice")
+	now := time.Now().UTC().Truncate(time.Second)
+
+	expired := now.Add(-time.Hour)
+	tokens := []model.APIToken{
+		{ID: testutil.MakeID(), UserID: user.ID, Name: "dashboard", TokenHash: "live",
+			Scopes: []model.APIScope{model.ScopeReadPortfolio, model.ScopeReadFund}, CreatedAt: now},
+		{ID: testutil.MakeID(), UserID: user.ID, Name: "old", TokenHash: "expired",
+			Scopes: []model.APIScope{model.ScopeReadFund}, CreatedAt: now.Add(-time.Minute), ExpiresAt: &expired},
+	}
+	for i := range tokens {
+		if err := repo.InsertAPIToken(ctx, &tokens[i]); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	t.Run("lists tokens newest first with scopes", func(t *testing.T) {
+		got, err := repo.GetAPITokens(user.ID)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(got) != 2 || got[0].Name != "dashboard" {
+			t.Fatalf("unexpected tokens: %+v", got)
+		}
+		if len(got[0].Scopes) != 2 || got[0].Scopes[1] != model.ScopeReadFund {
+			t.Errorf("unexpected scopes: %v", got[0].Scopes)
+		}
+		if got[1].ExpiresAt == nil || !got[1].ExpiresAt.Equal(expired) {
+			t.Errorf("expected expiry %v, got %v", expired, got[1].ExpiresAt)
+		}
+	})
+
+	t.Run("active lookup skips expired tokens", func(t *testing.T) {
+		if _, err := repo.GetActiveAPITokenByHash("live", now); err != nil {
+			t.Errorf("expected live token, got %v", err)
+		}
+		if _, err := repo.GetActiveAPITokenByHash("expired", now); !errors.Is(err, apperrors.ErrSessionInvalid) {
+			t.Errorf("expected ErrSessionInvalid, got %v", err)
+		}
+	})
+
+	t.Run("touch is throttled", func(t *testing.T) {
+		if err := repo.TouchAPIToken(ctx, tokens[0].ID, now, time.Minute); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := repo.TouchAPIToken(ctx, tokens[0].ID, now.Add(30*time.Second), time.Minute); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		got, err := repo.GetAPIToken(tokens[0].ID)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got.LastUsedAt == nil || !got.LastUsedAt.Equal(now) {
+			t.Errorf("expected last use %v, got %v", now, got.LastUsedAt)
+		}
+	})
+
+	t.Run("revoked tokens are inactive", func(t *testing.T) {
+		if err := repo.RevokeAPIToken(ctx, tokens[0].ID, now); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if _, err := repo.GetActiveAPITokenByHash("live", now); !errors.Is(err, apperrors.ErrSessionInvalid) {
+			t.Errorf("expected ErrSessionInvalid, got %v", err)
+		}
+		if err := repo.RevokeAPIToken(ctx, testutil.MakeID(), now); !errors.Is(err, apperrors.ErrAPITokenNotFound) {
+			t.Errorf("expected ErrAPITokenNotFound, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,246 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/logging"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/model"
+)
+
+var auditLog = logging.NewLogger("security")
+
+// auditTimestampFormat keeps microseconds so that events written within the same
+// second (e.g. create then update in one request) sort in the order they happened.
+const auditTimestampFormat = "2006-01-02 15:04:05.000000"
+
+// AuditRepository provides data access methods for the audit_event table.
+type AuditRepository struct {
+	db *sql.DB
+	tx *sql.Tx
+}
+
+// NewAuditRepository creates a new AuditRepository with the provided database connection.
+func NewAuditRepository(db *sql.DB) *AuditRepository {
+	return &AuditRepository{db: db}
+}
+
+// WithTx returns a new AuditRepository scoped to the provided transaction.
+func (r *AuditRepository) WithTx(tx *sql.Tx) *AuditRepository {
+	return &AuditRepository{
+		db: r.db,
+		tx: tx,
+	}
+}
+
+// getQuerier returns the active transaction if one is set, otherwise the database connection.
+func (r *AuditRepository) getQuerier() Querier {
+	if r.tx != nil {
+		return traced(r.tx)
+	}
+	return traced(r.db)
+}
+
+// InsertAuditEvent inserts a single audit event.
+// Callers pass a transaction-scoped repository so the event commits or rolls back with the mutation it describes.
+func (r *AuditRepository) InsertAuditEvent(ctx context.Context, e *model.AuditEvent) error {
+	query := `
+		INSERT INTO audit_event (id, timestamp, entity_type, entity_id, action, before_state, after_state, user_id, request_id, ip_address, user_agent)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	_, err := r.getQuerier().ExecContext(ctx, query,
+		e.ID,
+		e.Timestamp.Format(auditTimestampFormat),
+		e.EntityType,
+		e.EntityID,
+		e.Action,
+		nullableJSON(e.Before),
+		nullableJSON(e.After),
+		nullableString(e.UserID),
+		nullableString(e.RequestID),
+		nullableString(e.IPAddress),
+		nullableString(e.UserAgent),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert audit event: %w", err)
+	}
+	return nil
+}
+
+// GetAuditEvents retrieves audit events with dynamic filtering and cursor-based pagination.
+// Uses the same (timestamp, id) cursor scheme as the log viewer; one extra row beyond
+// PerPage is fetched to determine whether more results exist.
+//
+//nolint:gocyclo,funlen // Dynamic WHERE clause construction mirrors GetLogs.
+func (r *AuditRepository) GetAuditEvents(filters *model.AuditFilters) (*model.AuditEventResponse, error) {
+	auditLog.Debug("getting audit events", "per_page", filters.PerPage, "sort_dir", filters.SortDir)
+
+	var whereClauses []string
+	var args []any
+
+	if len(filters.EntityTypes) > 0 {
+		whereClauses = append(whereClauses, "entity_type IN ("+placeholders(len(filters.EntityTypes))+")")
+		for _, t := range filters.EntityTypes {
+			args = append(args, t)
+		}
+	}
+
+	if filters.EntityID != "" {
+		whereClauses = append(whereClauses, "entity_id = ?")
+		args = append(args, filters.EntityID)
+	}
+
+	if len(filters.Actions) > 0 {
+		whereClauses = append(whereClauses, "action IN ("+placeholders(len(filters.Actions))+")")
+		for _, a := range filters.Actions {
+			args = append(args, a)
+		}
+	}
+
+	if filters.RequestID != "" {
+		whereClauses = append(whereClauses, "request_id = ?")
+		args = append(args, filters.RequestID)
+	}
+
+	if filters.UserID != "" {
+		whereClauses = append(whereClauses, "user_id = ?")
+		args = append(args, filters.UserID)
+	}
+
+	if filters.StartDate != nil {
+		whereClauses = append(whereClauses, "timestamp >= ?")
+		args = append(args, filters.StartDate.UTC().Format(auditTimestampFormat))
+	}
+
+	if filters.EndDate != nil {
+		whereClauses = append(whereClauses, "timestamp <= ?")
+		args = append(args, filters.EndDate.UTC().Format(auditTimestampFormat))
+	}
+
+	if filters.Cursor != "" {
+		parts := strings.Split(filters.Cursor, "_")
+		if len(parts) == 2 {
+			timestamp, err := time.Parse(time.RFC3339Nano, parts[0])
+			if err == nil {
+				ts := timestamp.UTC().Format(auditTimestampFormat)
+				if filters.SortDir == "asc" {
+					whereClauses = append(whereClauses, "(timestamp > ? OR (timestamp = ? AND id > ?))")
+				} else {
+					whereClauses = append(whereClauses, "(timestamp < ? OR (timestamp = ? AND id < ?))")
+				}
+				args = append(args, ts, ts, parts[1])
+			}
+		}
+	}
+
+	whereSQL := ""
+	if len(whereClauses) > 0 {
+		whereSQL = "WHERE " + strings.Join(whereClauses, " AND ")
+	}
+
+	orderSQL := "ORDER BY timestamp DESC, id DESC"
+	if filters.SortDir == "asc" {
+		orderSQL = "ORDER BY timestamp ASC, id ASC"
+	}
+
+	//nolint:gosec // G202: whereSQL and orderSQL contain no user input, all user values are parameterized
+	query := `
+		SELECT id, timestamp, entity_type, entity_id, action, before_state, after_state,
+		       user_id, request_id, ip_address, user_agent
+		FROM audit_event
+		` + whereSQL + `
+		` + orderSQL + `
+		LIMIT ?
+	`
+	args = append(args, filters.PerPage+1)
+
+	rows, err := r.getQuerier().Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query audit_event table: %w", err)
+	}
+	defer rows.Close()
+
+	events := []model.AuditEvent{}
+	for rows.Next() {
+		var timestampStr string
+		var beforeStr, afterStr, userIDStr, requestIDStr, ipStr, uaStr sql.NullString
+		var e model.AuditEvent
+
+		if err := rows.Scan(
+			&e.ID,
+			&timestampStr,
+			&e.EntityType,
+			&e.EntityID,
+			&e.Action,
+			&beforeStr,
+			&afterStr,
+			&userIDStr,
+			&requestIDStr,
+			&ipStr,
+			&uaStr,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan audit event: %w", err)
+		}
+
+		e.Timestamp, err = ParseTime(timestampStr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse timestamp: %w", err)
+		}
+
+		if beforeStr.Valid {
+			e.Before = []byte(beforeStr.String)
+		}
+		if afterStr.Valid {
+			e.After = []byte(afterStr.String)
+		}
+		e.UserID = userIDStr.String
+		e.RequestID = requestIDStr.String
+		e.IPAddress = ipStr.String
+		e.UserAgent = uaStr.String
+
+		events = append(events, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating audit events: %w", err)
+	}
+
+	hasMore := len(events) > filters.PerPage
+	var nextCursor string
+	if hasMore {
+		last := events[filters.PerPage-1]
+		nextCursor = fmt.Sprintf("%s_%s", last.Timestamp.Format(time.RFC3339Nano), last.ID)
+		events = events[:filters.PerPage]
+	}
+
+	return &model.AuditEventResponse{
+		Events:     events,
+		NextCursor: nextCursor,
+		HasMore:    hasMore,
+		Count:      len(events),
+	}, nil
+}
+
+// placeholders returns a comma-separated list of n "?" placeholders.
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?,", n), ",")
+}
+
+// nullableString converts an empty string to NULL.
+func nullableString(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+// nullableJSON converts an empty JSON document to NULL.
+func nullableJSON(b []byte) any {
+	if len(b) == 0 {
+		return nil
+	}
+	return string(b)
+}
@@ -0,0 +1,164 @@
+package repository_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/model"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/repository"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/testutil"
+)
+
+func newAuditEvent(ts time.Time, entityType model.AuditEntityType, entityID string, action model.AuditAction) *model.AuditEvent {
+	return &model.AuditEvent{
+		ID:         testutil.MakeID(),
+		Timestamp:  ts,
+		EntityType: string(entityType),
+		EntityID:   entityID,
+		Action:     string(action),
+	}
+}
+
+//nolint:gocyclo // Test function with multiple subtests and assertions.
+func TestAuditRepository_InsertAndGetAuditEvents(t *testing.T) {
+	t.Run("round-trips snapshots and request metadata", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		repo := repository.NewAuditRepository(db)
+
+		e := newAuditEvent(time.Now().UTC().Truncate(time.Second), model.AuditEntityPortfolio, "p1", model.AuditActionUpdate)
+		e.Before = json.RawMessage(`{"name":"Old"}`)
+		e.After = json.RawMessage(`{"name":"New"}`)
+		e.RequestID = "req-1"
+		e.IPAddress = "10.0.0.1"
+		e.UserAgent = "test-agent"
+
+		if err := repo.InsertAuditEvent(context.Background(), e); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		result, err := repo.GetAuditEvents(&model.AuditFilters{SortDir: "desc", PerPage: 50})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.Count != 1 {
+			t.Fatalf("expected 1 event, got %d", result.Count)
+		}
+		got := result.Events[0]
+		if string(got.Before) != `{"name":"Old"}` || string(got.After) != `{"name":"New"}` {
+			t.Errorf("unexpected snapshots: before=%s after=%s", got.Before, got.After)
+		}
+		if got.RequestID != "req-1" || got.IPAddress != "10.0.0.1" || got.UserAgent != "test-agent" {
+			t.Errorf("unexpected request metadata: %+v", got)
+		}
+		if !got.Timestamp.Equal(e.Timestamp) {
+			t.Errorf("expected timestamp %v, got %v", e.Timestamp, got.Timestamp)
+		}
+	})
+
+	t.Run("empty snapshots are stored as NULL", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		repo := repository.NewAuditRepository(db)
+
+		e := newAuditEvent(time.Now().UTC(), model.AuditEntityFund, "f1", model.AuditActionCreate)
+		e.After = json.RawMessage(`{"id":"f1"}`)
+		if err := repo.InsertAuditEvent(context.Background(), e); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		var beforeNull, requestNull bool
+		err := db.QueryRow(`SELECT before_state IS NULL, request_id IS NULL FROM audit_event WHERE id = ?`, e.ID).
+			Scan(&beforeNull, &requestNull)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !beforeNull || !requestNull {
+			t.Errorf("expected NULL before_state and request_id, got before=%v request=%v", beforeNull, requestNull)
+		}
+	})
+
+	t.Run("filters by entity, action and request", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		repo := repository.NewAuditRepository(db)
+		ctx := context.Background()
+		now := time.Now().UTC().Truncate(time.Second)
+
+		events := []*model.AuditEvent{
+			newAuditEvent(now.Add(-3*time.Second), model.AuditEntityTransaction, "t1", model.AuditActionCreate),
+			newAuditEvent(now.Add(-2*time.Second), model.AuditEntityTransaction, "t1", model.AuditActionDelete),
+			newAuditEvent(now.Add(-1*time.Second), model.AuditEntityDividend, "d1", model.AuditActionCreate),
+		}
+		events[2].RequestID = "req-42"
+		for _, e := range events {
+			if err := repo.InsertAuditEvent(ctx, e); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+
+		since := now.Add(-1 * time.Second)
+		cases := []struct {
+			name    string
+			filters model.AuditFilters
+			want    int
+		}{
+			{"entity type", model.AuditFilters{EntityTypes: []string{"transaction"}}, 2},
+			{"entity id and action", model.AuditFilters{EntityID: "t1", Actions: []string{"delete"}}, 1},
+			{"multiple actions", model.AuditFilters{Actions: []string{"create", "delete"}}, 3},
+			{"request id", model.AuditFilters{RequestID: "req-42"}, 1},
+			{"start date", model.AuditFilters{StartDate: &since}, 1},
+		}
+		for _, tc := range cases {
+			t.Run(tc.name, func(t *testing.T) {
+				f := tc.filters
+				f.SortDir = "desc"
+				f.PerPage = 50
+				result, err := repo.GetAuditEvents(&f)
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if result.Count != tc.want {
+					t.Errorf("expected %d events, got %d", tc.want, result.Count)
+				}
+			})
+		}
+	})
+
+	t.Run("cursor pagination walks all events", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		repo := repository.NewAuditRepository(db)
+		ctx := context.Background()
+		now := time.Now().UTC().Truncate(time.Second)
+
+		for i := range 5 {
+			e := newAuditEvent(now.Add(time.Duration(-i)*time.Second), model.AuditEntityPortfolio, "p1", model.AuditActionUpdate)
+			if err := repo.InsertAuditEvent(ctx, e); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+
+		filters := &model.AuditFilters{SortDir: "desc", PerPage: 2}
+		seen := map[string]bool{}
+		pages := 0
+		for {
+			result, err := repo.GetAuditEvents(filters)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			pages++
+			for _, e := range result.Events {
+				if seen[e.ID] {
+					t.Fatalf("event %s returned twice", e.ID)
+				}
+				seen[e.ID] = true
+			}
+			if !result.HasMore {
+				break
+			}
+			filters.Cursor = result.NextCursor
+		}
+		if len(seen) != 5 || pages != 3 {
+			t.Errorf("expected 5 events over 3 pages, got %d over %d", len(seen), pages)
+		}
+	})
+}
@@ -7,6 +7,12 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/tracing"
 )
 
 // Querier is satisfied by both *sql.DB and *sql.Tx, allowing repository methods to participate in a transaction or run standalone.
@@ -19,3 +25,97 @@ type Querier interface {
 	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
 	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
 }
+
+// tracedQuerier wraps a Querier so that context-aware calls made within a traced
+// request produce a child span per statement; context-aware calls whose context
+// carries no span pass straight through. Calls without a context have no request
+// to attach to, so each of their statements is traced as a span without a parent.
+type tracedQuerier struct {
+	Querier
+}
+
+// traced wraps q for tracing. Every getQuerier returns its Querier through it.
+func traced(q Querier) Querier {
+	return tracedQuerier{q}
+}
+
+// startQuerySpan starts a span for query if ctx is being traced.
+func startQuerySpan(ctx context.Context, name, query string) (context.Context, trace.Span, bool) {
+	if !tracing.Active(ctx) {
+		return ctx, nil, false
+	}
+	ctx, span := tracing.Start(ctx, name, querySpanAttributes(query)...)
+	return ctx, span, true
+}
+
+// startRootQuerySpan starts a span without a parent for a statement run without a context.
+func startRootQuerySpan(name, query string) (context.Context, trace.Span) {
+	return tracing.Start(context.Background(), name, querySpanAttributes(query)...)
+}
+
+func querySpanAttributes(query string) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.String("db.system.name", "sqlite"),
+		attribute.String("db.query.text", strings.Join(strings.Fields(query), " ")),
+	}
+}
+
+// Query traces the statement like QueryContext, as a span without a parent.
+func (q tracedQuerier) Query(query string, args ...any) (*sql.Rows, error) {
+	ctx, span := startRootQuerySpan("db.query", query)
+	rows, err := q.Querier.QueryContext(ctx, query, args...)
+	tracing.End(span, err)
+	return rows, err
+}
+
+func (q tracedQuerier) QueryRow(query string, args ...any) *sql.Row {
+	ctx, span := startRootQuerySpan("db.query", query)
+	row := q.Querier.QueryRowContext(ctx, query, args...)
+	tracing.End(span, row.Err())
+	return row
+}
+
+func (q tracedQuerier) Exec(query string, args ...any) (sql.Result, error) {
+	ctx, span := startRootQuerySpan("db.exec", query)
+	result, err := q.Querier.ExecContext(ctx, query, args...)
+	tracing.End(span, err)
+	return result, err
+}
+
+// QueryContext traces the statement up to the point the first row is available;
+// iterating the returned rows is not included in the span.
+func (q tracedQuerier) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	ctx, span, ok := startQuerySpan(ctx, "db.query", query)
+	rows, err := q.Querier.QueryContext(ctx, query, args...)
+	if ok {
+		tracing.End(span, err)
+	}
+	return rows, err
+}
+
+func (q tracedQuerier) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	ctx, span, ok := startQuerySpan(ctx, "db.query", query)
+	row := q.Querier.QueryRowContext(ctx, query, args...)
+	if ok {
+		tracing.End(span, row.Err())
+	}
+	return row
+}
+
+func (q tracedQuerier) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	ctx, span, ok := startQuerySpan(ctx, "db.exec", query)
+	result, err := q.Querier.ExecContext(ctx, query, args...)
+	if ok {
+		tracing.End(span, err)
+	}
+	return result, err
+}
+
+func (q tracedQuerier) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	ctx, span, ok := startQuerySpan(ctx, "db.prepare", query)
+	stmt, err := q.Querier.PrepareContext(ctx, query)
+	if ok {
+		tracing.End(span, err)
+	}
+	return stmt, err
+}
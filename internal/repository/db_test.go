@@ -0,0 +1,57 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/repository"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/testutil"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/tracing"
+)
+
+func TestQuerierTracing(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	recorder := testutil.RecordSpans(t)
+	repo := repository.NewUserRepository(db)
+
+	t.Run("untraced context creates no spans", func(t *testing.T) {
+		if _, err := repo.DeleteExpiredSessions(context.Background(), time.Now()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := len(recorder.Ended()); got != 0 {
+			t.Errorf("Expected no spans, got %d", got)
+		}
+	})
+
+	t.Run("traced context creates a child span per statement", func(t *testing.T) {
+		ctx, parent := tracing.Start(context.Background(), "request")
+		if _, err := repo.DeleteExpiredSessions(ctx, time.Now()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		parent.End()
+
+		spans := recorder.Ended()
+		if len(spans) != 2 {
+			t.Fatalf("Expected statement and parent spans, got %d", len(spans))
+		}
+		if spans[0].Name() != "db.exec" || spans[0].Parent().SpanID() != parent.SpanContext().SpanID() {
+			t.Errorf("Expected db.exec child span, got %q", spans[0].Name())
+		}
+	})
+
+	t.Run("call without a context creates a root span", func(t *testing.T) {
+		before := len(recorder.Ended())
+		if _, err := repo.GetUsers(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		spans := recorder.Ended()[before:]
+		if len(spans) != 1 {
+			t.Fatalf("Expected one statement span, got %d", len(spans))
+		}
+		if spans[0].Name() != "db.query" || spans[0].Parent().IsValid() {
+			t.Errorf("Expected db.query root span, got %q with parent %v", spans[0].Name(), spans[0].Parent().SpanID())
+		}
+	})
+}
@@ -38,9 +38,9 @@ func (r *DeveloperRepository) WithTx(tx *sql.Tx) *DeveloperRepository {
 // getQuerier returns the active transaction if one is set, otherwise the database connection.
 func (r *DeveloperRepository) getQuerier() Querier {
 	if r.tx != nil {
-		return r.tx
+		return traced(r.tx)
 	}
-	return r.db
+	return traced(r.db)
 }
 
 // GetLogs retrieves log entries from the database with dynamic filtering and cursor-based pagination.
@@ -156,7 +156,7 @@ func (r *DeveloperRepository) GetLogs(filters *model.LogFilters) (*model.LogResp
 
 	query := `
 		SELECT id, timestamp, level, category, message, details, source,
-		       request_id, stack_trace, http_status, ip_address, user_agent
+		       user_id, request_id, stack_trace, http_status, ip_address, user_agent
 		FROM log
 		` + whereSQL + `
 		` + orderSQL + `
@@ -176,7 +176,7 @@ func (r *DeveloperRepository) GetLogs(filters *model.LogFilters) (*model.LogResp
 
 	for rows.Next() {
 		var timestampStr string
-		var detailsStr, RequestIDStr, StackTraceStr, HTTPStatusStr, IPAddressStr, UserAgentStr sql.NullString
+		var detailsStr, UserIDStr, RequestIDStr, StackTraceStr, HTTPStatusStr, IPAddressStr, UserAgentStr sql.NullString
 		var l model.Log
 
 		err := rows.Scan(
@@ -187,6 +187,7 @@ func (r *DeveloperRepository) GetLogs(filters *model.LogFilters) (*model.LogResp
 			&l.Message,
 			&detailsStr,
 			&l.Source,
+			&UserIDStr,
 			&RequestIDStr,
 			&StackTraceStr,
 			&HTTPStatusStr,
@@ -206,6 +207,9 @@ func (r *DeveloperRepository) GetLogs(filters *model.LogFilters) (*model.LogResp
 		if detailsStr.Valid {
 			l.Details = detailsStr.String
 		}
+		if UserIDStr.Valid {
+			l.UserID = UserIDStr.String
+		}
 		if RequestIDStr.Valid {
 			l.RequestID = RequestIDStr.String
 		}
@@ -352,6 +356,11 @@ func (r *DeveloperRepository) GetLoggingConfig() (model.LoggingSetting, error) {
 // SetLoggingConfig persists a new logging configuration setting to the database.
 func (r *DeveloperRepository) SetLoggingConfig(ctx context.Context, setting model.SystemSetting) error {
 	devLog.DebugContext(ctx, "setting logging config", "key", setting.Key, "value", setting.Value)
+	return r.SetSystemSetting(ctx, setting)
+}
+
+// SetSystemSetting inserts a system setting or replaces the value of an existing one.
+func (r *DeveloperRepository) SetSystemSetting(ctx context.Context, setting model.SystemSetting) error {
 	query := `
         INSERT INTO system_setting (id, key, value, updated_at)
         VALUES (?, ?, ?, ?)
@@ -375,6 +384,20 @@ func (r *DeveloperRepository) SetLoggingConfig(ctx context.Context, setting mode
 	return nil
 }
 
+// GetSystemSetting retrieves the raw value of a system setting.
+// The boolean result is false if the setting has never been stored.
+func (r *DeveloperRepository) GetSystemSetting(key string) (string, bool, error) {
+	var value sql.NullString
+	err := r.getQuerier().QueryRow(`SELECT value FROM system_setting WHERE key = ?`, key).Scan(&value)
+	if err == sql.ErrNoRows {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("failed to query system setting %s: %w", key, err)
+	}
+	return value.String, true, nil
+}
+
 // GetExchangeRate retrieves an exchange rate for a specific currency pair and date.
 // Queries the exchange_rate table for an exact match on from_currency, to_currency, and date.
 // Returns ErrExchangeRateNotFound if no matching rate exists.
@@ -413,6 +436,43 @@ func (r *DeveloperRepository) GetExchangeRate(fromCurrency, toCurrency string, d
 	return &rate, nil
 }
 
+// GetLatestExchangeRate retrieves the most recent exchange rate for a currency pair on or
+// before the given date, for dates without a rate such as weekends and holidays.
+// Returns ErrExchangeRateNotFound if no rate exists on or before the date.
+func (r *DeveloperRepository) GetLatestExchangeRate(fromCurrency, toCurrency string, dateTime time.Time) (*model.ExchangeRate, error) {
+	query := `
+	SELECT from_currency, to_currency, rate, date
+	FROM exchange_rate
+	WHERE from_currency = ?
+	AND to_currency = ?
+	AND date <= ?
+	ORDER BY date DESC
+	LIMIT 1
+`
+	rate := model.ExchangeRate{}
+	var dateStr string
+	err := r.getQuerier().QueryRow(query, fromCurrency, toCurrency, dateTime.Format("2006-01-02")).Scan(
+		&rate.FromCurrency,
+		&rate.ToCurrency,
+		&rate.Rate,
+		&dateStr,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, apperrors.ErrExchangeRateNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get latest exchange rate %s→%s: %w", fromCurrency, toCurrency, err)
+	}
+
+	rate.Date, err = ParseTime(dateStr)
+	if err != nil || rate.Date.IsZero() {
+		return nil, fmt.Errorf("failed to parse date: %w", err)
+	}
+
+	return &rate, nil
+}
+
 // UpdateExchangeRate upserts an exchange rate record.
 // On conflict (same from_currency, to_currency, date), updates the rate and created_at fields.
 func (r *DeveloperRepository) UpdateExchangeRate(ctx context.Context, exRate model.ExchangeRate) error {
@@ -449,8 +509,8 @@ func (r *DeveloperRepository) AddLog(ctx context.Context, logEntry model.Log) er
 	devLog.DebugContext(ctx, "adding log entry", "level", logEntry.Level, "category", logEntry.Category)
 
 	query := `
-		INSERT INTO log (id, timestamp, level, category, message, details, source, request_id, stack_trace, http_status, ip_address, user_agent)
-        VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO log (id, timestamp, level, category, message, details, source, user_id, request_id, stack_trace, http_status, ip_address, user_agent)
+        VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
 	// Convert HTTPStatus string → *int for the INTEGER column.
@@ -473,6 +533,7 @@ func (r *DeveloperRepository) AddLog(ctx context.Context, logEntry model.Log) er
 		logEntry.Message,
 		logEntry.Details,
 		logEntry.Source,
+		logEntry.UserID,
 		logEntry.RequestID,
 		logEntry.StackTrace,
 		httpStatus,
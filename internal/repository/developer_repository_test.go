@@ -442,6 +442,41 @@ func TestDeveloperRepository_SetLoggingConfig(t *testing.T) {
 	})
 }
 
+func TestDeveloperRepository_SystemSetting(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	repo := repository.NewDeveloperRepository(db)
+	ctx := context.Background()
+
+	_, found, err := repo.GetSystemSetting("SCHEDULE_TEST")
+	if err != nil {
+		t.Fatalf("GetSystemSetting: %v", err)
+	}
+	if found {
+		t.Fatal("expected missing setting to be reported as not found")
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	for _, value := range []string{`{"enabled":true}`, `{"enabled":false}`} {
+		setting := model.SystemSetting{
+			ID:        testutil.MakeID(),
+			Key:       "SCHEDULE_TEST",
+			Value:     value,
+			UpdatedAt: &now,
+		}
+		if err := repo.SetSystemSetting(ctx, setting); err != nil {
+			t.Fatalf("SetSystemSetting: %v", err)
+		}
+	}
+
+	value, found, err := repo.GetSystemSetting("SCHEDULE_TEST")
+	if err != nil {
+		t.Fatalf("GetSystemSetting: %v", err)
+	}
+	if !found || value != `{"enabled":false}` {
+		t.Errorf("expected the latest value, got found=%v value=%q", found, value)
+	}
+}
+
 // ---------------------------------------------------------------------------
 // GetExchangeRate / UpdateExchangeRate
 // ---------------------------------------------------------------------------
@@ -494,6 +529,28 @@ func TestDeveloperRepository_GetExchangeRate(t *testing.T) {
 	})
 }
 
+func TestDeveloperRepository_GetLatestExchangeRate(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	repo := repository.NewDeveloperRepository(db)
+
+	testutil.NewExchangeRate("USD", "EUR", "2025-12-30", 0.95).Build(t, db)
+	testutil.NewExchangeRate("USD", "EUR", "2025-12-31", 0.96).Build(t, db)
+	testutil.NewExchangeRate("USD", "EUR", "2026-01-02", 0.97).Build(t, db)
+
+	rate, err := repo.GetLatestExchangeRate("USD", "EUR", time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("GetLatestExchangeRate: %v", err)
+	}
+	if rate.Rate != 0.96 || rate.Date.Format("2006-01-02") != "2025-12-31" {
+		t.Errorf("expected the 2025-12-31 rate of 0.96, got %v on %s", rate.Rate, rate.Date.Format("2006-01-02"))
+	}
+
+	_, err = repo.GetLatestExchangeRate("USD", "EUR", time.Date(2025, 12, 29, 0, 0, 0, 0, time.UTC))
+	if !errors.Is(err, apperrors.ErrExchangeRateNotFound) {
+		t.Fatalf("expected ErrExchangeRateNotFound before the first rate, got %v", err)
+	}
+}
+
 func TestDeveloperRepository_UpdateExchangeRate(t *testing.T) {
 	t.Run("insert new rate", func(t *testing.T) {
 		db := testutil.SetupTestDB(t)
@@ -37,29 +37,34 @@ func (r *DividendRepository) WithTx(tx *sql.Tx) *DividendRepository {
 // getQuerier returns the active transaction if one is set, otherwise the database connection.
 func (r *DividendRepository) getQuerier() Querier {
 	if r.tx != nil {
-		return r.tx
+		return traced(r.tx)
 	}
-	return r.db
+	return traced(r.db)
 }
 
-// GetAllDividend retrieves all dividends.
+// GetAllDividend retrieves all dividends of the portfolios in scope.
 // Dividends are filtered by ex-dividend date and sorted in ascending order by that date.
 //
 // Returns []Dividend.
 // Handles nullable fields like buy_order_date and reinvestment_transaction_id appropriately.
 // This grouping allows callers to decide how to aggregate (by portfolio, by fund, etc.) after retrieval.
-func (r *DividendRepository) GetAllDividend() ([]model.Dividend, error) {
+func (r *DividendRepository) GetAllDividend(scope model.PortfolioScope) ([]model.Dividend, error) {
 	dividendLog.Debug("getting all dividends")
 
+	condition, args := scopeCondition(scope, "pf.portfolio_id")
+
 	// Retrieve all dividend based on returned portfolio_fund IDs
+	//#nosec G202 -- Safe: placeholders are generated programmatically, not from user input
 	dividendQuery := `
 		SELECT id, fund_id, portfolio_fund_id, record_date, ex_dividend_date, shares_owned,
-		dividend_per_share, total_amount, reinvestment_status, buy_order_date, reinvestment_transaction_id, created_at
+		dividend_per_share, total_amount, withholding_tax, withholding_source, reinvestment_status, buy_order_date,
+		reinvestment_transaction_id, created_at
 		FROM dividend
+		WHERE portfolio_fund_id IN (SELECT pf.id FROM portfolio_fund pf WHERE ` + condition + `)
 		ORDER BY ex_dividend_date ASC
 	`
 
-	rows, err := r.db.Query(dividendQuery)
+	rows, err := r.getQuerier().Query(dividendQuery, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query dividend table: %w", err)
 	}
@@ -81,6 +86,8 @@ func (r *DividendRepository) GetAllDividend() ([]model.Dividend, error) {
 			&t.SharesOwned,
 			&t.DividendPerShare,
 			&t.TotalAmount,
+			&t.WithholdingTax,
+			&t.WithholdingSource,
 			&t.ReinvestmentStatus,
 			&buyOrderStr,
 			&reinvestmentTxID,
@@ -162,7 +169,8 @@ func (r *DividendRepository) GetDividendPerPF(pfIDs []string, startDate, endDate
 	//#nosec G202 -- Safe: placeholders are generated programmatically, not from user input
 	dividendQuery := `
 		SELECT id, fund_id, portfolio_fund_id, record_date, ex_dividend_date, shares_owned,
-		dividend_per_share, total_amount, reinvestment_status, buy_order_date, reinvestment_transaction_id, created_at
+		dividend_per_share, total_amount, withholding_tax, withholding_source, reinvestment_status, buy_order_date,
+		reinvestment_transaction_id, created_at
 		FROM dividend
 		WHERE portfolio_fund_id IN (` + strings.Join(dividendPlaceholders, ",") + `)
 		AND ex_dividend_date >= ?
@@ -177,7 +185,7 @@ func (r *DividendRepository) GetDividendPerPF(pfIDs []string, startDate, endDate
 	dividendArgs = append(dividendArgs, startDate.Format("2006-01-02"))
 	dividendArgs = append(dividendArgs, endDate.Format("2006-01-02"))
 
-	rows, err := r.db.Query(dividendQuery, dividendArgs...)
+	rows, err := r.getQuerier().Query(dividendQuery, dividendArgs...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query dividend table: %w", err)
 	}
@@ -199,6 +207,8 @@ func (r *DividendRepository) GetDividendPerPF(pfIDs []string, startDate, endDate
 			&t.SharesOwned,
 			&t.DividendPerShare,
 			&t.TotalAmount,
+			&t.WithholdingTax,
+			&t.WithholdingSource,
 			&t.ReinvestmentStatus,
 			&buyOrderStr,
 			&reinvestmentTxID,
@@ -223,6 +233,45 @@ func (r *DividendRepository) GetDividendPerPF(pfIDs []string, startDate, endDate
 	return dividend, nil
 }
 
+// GetFundDividends retrieves the dividends of a fund across all portfolios with an
+// ex-dividend date between startDate and endDate inclusive, ordered by ex-dividend date.
+func (r *DividendRepository) GetFundDividends(fundID string, startDate, endDate time.Time) ([]model.Dividend, error) {
+	rows, err := r.getQuerier().Query(`
+		SELECT id, fund_id, portfolio_fund_id, record_date, ex_dividend_date, shares_owned,
+		dividend_per_share, total_amount, withholding_tax, withholding_source, reinvestment_status, buy_order_date,
+		reinvestment_transaction_id, created_at
+		FROM dividend
+		WHERE fund_id = ?
+		AND DATE(ex_dividend_date) BETWEEN ? AND ?
+		ORDER BY ex_dividend_date ASC, id ASC
+	`, fundID, startDate.Format("2006-01-02"), endDate.Format("2006-01-02"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query dividend table: %w", err)
+	}
+	defer rows.Close()
+
+	dividends := []model.Dividend{}
+	for rows.Next() {
+		var recordDateStr, exDividendStr, createdAtStr string
+		var buyOrderStr, reinvestmentTxID sql.NullString
+		var t model.Dividend
+
+		if err := rows.Scan(&t.ID, &t.FundID, &t.PortfolioFundID, &recordDateStr, &exDividendStr, &t.SharesOwned,
+			&t.DividendPerShare, &t.TotalAmount, &t.WithholdingTax, &t.WithholdingSource, &t.ReinvestmentStatus,
+			&buyOrderStr, &reinvestmentTxID, &createdAtStr); err != nil {
+			return nil, fmt.Errorf("failed to scan dividend table results: %w", err)
+		}
+		if err := r.parseDividendRecords(&t, recordDateStr, exDividendStr, createdAtStr, buyOrderStr, reinvestmentTxID); err != nil {
+			return nil, fmt.Errorf("parse dividend records: %w", err)
+		}
+		dividends = append(dividends, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating dividend table: %w", err)
+	}
+	return dividends, nil
+}
+
 // parseDividendRecords parses date strings and nullable fields from database rows into a Dividend model.
 // This helper method was extracted from GetDividendPerPF to reduce cyclomatic complexity by isolating
 // the field parsing logic into a dedicated function.
@@ -289,9 +338,10 @@ func (r *DividendRepository) parseDividendRecords(t *model.Dividend, recordDateS
 // Parameters:
 //   - portfolioID: Filter by portfolio ID (mutually exclusive with fundID)
 //   - fundID:      Filter by fund ID (mutually exclusive with portfolioID; checked if portfolioID is empty)
+//   - scope:       Only dividends of portfolios in scope are returned
 //
 // Returns a slice of DividendFund ordered by ex_dividend_date ascending.
-func (r *DividendRepository) GetDividendPerPortfolioFund(portfolioID, fundID string) ([]model.DividendFund, error) {
+func (r *DividendRepository) GetDividendPerPortfolioFund(portfolioID, fundID string, scope model.PortfolioScope) ([]model.DividendFund, error) {
 	dividendLog.Debug("getting dividends per portfolio/fund", "portfolio_id", portfolioID, "fund_id", fundID)
 	var whereStatement, existsStatement, queryID string
 	var notFoundErr error
@@ -311,27 +361,29 @@ func (r *DividendRepository) GetDividendPerPortfolioFund(portfolioID, fundID str
 	}
 
 	var count int
-	if err := r.db.QueryRow(existsStatement, queryID).Scan(&count); err != nil {
+	if err := r.getQuerier().QueryRow(existsStatement, queryID).Scan(&count); err != nil {
 		return nil, fmt.Errorf("failed to check existence: %w", err)
 	}
 	if count == 0 {
 		return nil, notFoundErr
 	}
 
+	condition, args := scopeCondition(scope, "pf.portfolio_id")
+
 	//#nosec G202 -- Safe: placeholders are generated programmatically, not from user input
 	query := `
 	SELECT
 		d.id, d.fund_id, f.name, d.portfolio_fund_id, d.record_date, d.ex_dividend_date,
-		d.shares_owned, d.dividend_per_share, d.total_amount, d.reinvestment_status,
+		d.shares_owned, d.dividend_per_share, d.total_amount, d.withholding_tax, d.withholding_source, d.reinvestment_status,
 		d.buy_order_date, d.reinvestment_transaction_id, f.dividend_type
 	FROM dividend d
 	INNER JOIN portfolio_fund pf ON d.portfolio_fund_id = pf.id
 	INNER JOIN fund f ON pf.fund_id = f.id
-	` + whereStatement + `
+	` + whereStatement + ` AND ` + condition + `
 	ORDER BY d.ex_dividend_date ASC
 	`
 
-	rows, err := r.db.Query(query, queryID)
+	rows, err := r.getQuerier().Query(query, append([]any{queryID}, args...)...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query dividend table: %w", err)
 	}
@@ -369,6 +421,8 @@ func (r *DividendRepository) scanDividendFundRow(rows *sql.Rows) (model.Dividend
 		&t.SharesOwned,
 		&t.DividendPerShare,
 		&t.TotalAmount,
+		&t.WithholdingTax,
+		&t.WithholdingSource,
 		&t.ReinvestmentStatus,
 		&buyOrderStr,
 		&reinvestmentTxID,
@@ -399,6 +453,7 @@ func (r *DividendRepository) scanDividendFundRow(rows *sql.Rows) (model.Dividend
 	if reinvestmentTxID.Valid {
 		t.ReinvestmentTransactionID = reinvestmentTxID.String
 	}
+	t.SetNetAmount()
 
 	return t, nil
 }
@@ -410,8 +465,8 @@ func (r *DividendRepository) GetDividend(dividendID string) (model.Dividend, err
 	query := `
 		SELECT
 			id, fund_id, portfolio_fund_id, record_date, ex_dividend_date,
-			shares_owned, dividend_per_share, total_amount, reinvestment_status,
-			buy_order_date, reinvestment_transaction_id
+			shares_owned, dividend_per_share, total_amount, withholding_tax, withholding_source,
+			reinvestment_status, buy_order_date, reinvestment_transaction_id
 		FROM dividend
 		WHERE id = ?
       `
@@ -428,6 +483,8 @@ func (r *DividendRepository) GetDividend(dividendID string) (model.Dividend, err
 		&d.SharesOwned,
 		&d.DividendPerShare,
 		&d.TotalAmount,
+		&d.WithholdingTax,
+		&d.WithholdingSource,
 		&d.ReinvestmentStatus,
 		&buyOrderDateStr,
 		&reinvestmentTransactionIDString,
@@ -471,8 +528,9 @@ func (r *DividendRepository) InsertDividend(ctx context.Context, d *model.Divide
 	query := `
         INSERT INTO dividend (
 		id, fund_id, portfolio_fund_id, record_date, ex_dividend_date, shares_owned, dividend_per_share,
-		total_amount, reinvestment_status, buy_order_date, reinvestment_transaction_id, created_at)
-        VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		total_amount, withholding_tax, withholding_source, reinvestment_status, buy_order_date, reinvestment_transaction_id,
+		created_at)
+        VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
     `
 
 	var buyOrderDate any
@@ -494,6 +552,8 @@ func (r *DividendRepository) InsertDividend(ctx context.Context, d *model.Divide
 		d.SharesOwned,
 		d.DividendPerShare,
 		d.TotalAmount,
+		d.WithholdingTax,
+		d.WithholdingSource,
 		d.ReinvestmentStatus,
 		buyOrderDate,
 		reinvestmentTransactionID,
@@ -515,7 +575,7 @@ func (r *DividendRepository) UpdateDividend(ctx context.Context, d *model.Divide
 	query := `
         UPDATE dividend
         SET fund_id = ?, portfolio_fund_id = ?, record_date = ?, ex_dividend_date = ?, shares_owned = ?, dividend_per_share = ?,
-		total_amount = ?, reinvestment_status = ?, buy_order_date = ?, reinvestment_transaction_id = ?, created_at = ?
+		total_amount = ?, withholding_tax = ?, withholding_source = ?, reinvestment_status = ?, buy_order_date = ?, reinvestment_transaction_id = ?, created_at = ?
         WHERE id = ?
     `
 
@@ -537,6 +597,8 @@ func (r *DividendRepository) UpdateDividend(ctx context.Context, d *model.Divide
 		d.SharesOwned,
 		d.DividendPerShare,
 		d.TotalAmount,
+		d.WithholdingTax,
+		d.WithholdingSource,
 		d.ReinvestmentStatus,
 		buyOrderDate,
 		reinvestmentTransactionID,
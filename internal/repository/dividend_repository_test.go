@@ -19,7 +19,7 @@ func TestDividendRepository_GetAllDividend(t *testing.T) {
 		db := testutil.SetupTestDB(t)
 		repo := repository.NewDividendRepository(db)
 
-		result, err := repo.GetAllDividend()
+		result, err := repo.GetAllDividend(model.AllPortfolios())
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -44,7 +44,7 @@ func TestDividendRepository_GetAllDividend(t *testing.T) {
 			Build(t, db)
 
 		repo := repository.NewDividendRepository(db)
-		result, err := repo.GetAllDividend()
+		result, err := repo.GetAllDividend(model.AllPortfolios())
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -182,6 +182,38 @@ func TestDividendRepository_GetDividendPerPF(t *testing.T) {
 	})
 }
 
+// --- GetFundDividends ---
+
+func TestDividendRepository_GetFundDividends(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	repo := repository.NewDividendRepository(db)
+
+	fund := testutil.NewFund().Build(t, db)
+	otherFund := testutil.NewFund().Build(t, db)
+	pf1 := testutil.NewPortfolioFund(testutil.NewPortfolio().Build(t, db).ID, fund.ID).Build(t, db)
+	pf2 := testutil.NewPortfolioFund(testutil.NewPortfolio().Build(t, db).ID, fund.ID).Build(t, db)
+	otherPF := testutil.NewPortfolioFund(testutil.NewPortfolio().Build(t, db).ID, otherFund.ID).Build(t, db)
+
+	exDate := time.Date(2025, 3, 14, 0, 0, 0, 0, time.UTC)
+	testutil.NewDividend(fund.ID, pf1.ID).WithExDividendDate(exDate).Build(t, db)
+	testutil.NewDividend(fund.ID, pf2.ID).WithExDividendDate(exDate).Build(t, db)
+	testutil.NewDividend(fund.ID, pf1.ID).WithExDividendDate(exDate.AddDate(0, 0, 1)).Build(t, db)
+	testutil.NewDividend(otherFund.ID, otherPF.ID).WithExDividendDate(exDate).Build(t, db)
+
+	dividends, err := repo.GetFundDividends(fund.ID, exDate.AddDate(0, 0, -30), exDate)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(dividends) != 2 {
+		t.Fatalf("expected 2 dividends, got %d", len(dividends))
+	}
+	for _, d := range dividends {
+		if d.FundID != fund.ID || !d.ExDividendDate.Equal(exDate) {
+			t.Errorf("unexpected dividend %+v", d)
+		}
+	}
+}
+
 // --- GetDividendPerPortfolioFund ---
 
 func TestDividendRepository_GetDividendPerPortfolioFund(t *testing.T) {
@@ -189,7 +221,7 @@ func TestDividendRepository_GetDividendPerPortfolioFund(t *testing.T) {
 		db := testutil.SetupTestDB(t)
 		repo := repository.NewDividendRepository(db)
 
-		result, err := repo.GetDividendPerPortfolioFund("", "")
+		result, err := repo.GetDividendPerPortfolioFund("", "", model.AllPortfolios())
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -202,7 +234,7 @@ func TestDividendRepository_GetDividendPerPortfolioFund(t *testing.T) {
 		db := testutil.SetupTestDB(t)
 		repo := repository.NewDividendRepository(db)
 
-		_, err := repo.GetDividendPerPortfolioFund(testutil.MakeID(), "")
+		_, err := repo.GetDividendPerPortfolioFund(testutil.MakeID(), "", model.AllPortfolios())
 		if !errors.Is(err, apperrors.ErrPortfolioNotFound) {
 			t.Errorf("expected ErrPortfolioNotFound, got %v", err)
 		}
@@ -212,7 +244,7 @@ func TestDividendRepository_GetDividendPerPortfolioFund(t *testing.T) {
 		db := testutil.SetupTestDB(t)
 		repo := repository.NewDividendRepository(db)
 
-		_, err := repo.GetDividendPerPortfolioFund("", testutil.MakeID())
+		_, err := repo.GetDividendPerPortfolioFund("", testutil.MakeID(), model.AllPortfolios())
 		if !errors.Is(err, apperrors.ErrFundNotFound) {
 			t.Errorf("expected ErrFundNotFound, got %v", err)
 		}
@@ -230,7 +262,7 @@ func TestDividendRepository_GetDividendPerPortfolioFund(t *testing.T) {
 			Build(t, db)
 
 		repo := repository.NewDividendRepository(db)
-		result, err := repo.GetDividendPerPortfolioFund(portfolio.ID, "")
+		result, err := repo.GetDividendPerPortfolioFund(portfolio.ID, "", model.AllPortfolios())
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -254,7 +286,7 @@ func TestDividendRepository_GetDividendPerPortfolioFund(t *testing.T) {
 			Build(t, db)
 
 		repo := repository.NewDividendRepository(db)
-		result, err := repo.GetDividendPerPortfolioFund("", fund.ID)
+		result, err := repo.GetDividendPerPortfolioFund("", fund.ID, model.AllPortfolios())
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -270,7 +302,7 @@ func TestDividendRepository_GetDividendPerPortfolioFund(t *testing.T) {
 		testutil.NewPortfolioFund(portfolio.ID, fund.ID).Build(t, db)
 
 		repo := repository.NewDividendRepository(db)
-		result, err := repo.GetDividendPerPortfolioFund(portfolio.ID, "")
+		result, err := repo.GetDividendPerPortfolioFund(portfolio.ID, "", model.AllPortfolios())
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -429,6 +461,8 @@ func TestDividendRepository_UpdateDividend(t *testing.T) {
 			SharesOwned:        200,
 			DividendPerShare:   1.0,
 			TotalAmount:        200,
+			WithholdingTax:     30,
+			WithholdingSource:  model.WithholdingSourceIBKR,
 			ReinvestmentStatus: "completed",
 			CreatedAt:          time.Now().UTC(),
 		}
@@ -448,6 +482,9 @@ func TestDividendRepository_UpdateDividend(t *testing.T) {
 		if result.ReinvestmentStatus != "completed" {
 			t.Errorf("expected status 'completed', got '%s'", result.ReinvestmentStatus)
 		}
+		if result.WithholdingTax != 30 || result.WithholdingSource != model.WithholdingSourceIBKR {
+			t.Errorf("expected withholding 30 from IBKR, got %v from %s", result.WithholdingTax, result.WithholdingSource)
+		}
 	})
 
 	t.Run("returns ErrDividendNotFound for nonexistent ID", func(t *testing.T) {
@@ -37,9 +37,9 @@ func (r *FundRepository) WithTx(tx *sql.Tx) *FundRepository {
 // getQuerier returns the active transaction if one is set, otherwise the database connection.
 func (r *FundRepository) getQuerier() Querier {
 	if r.tx != nil {
-		return r.tx
+		return traced(r.tx)
 	}
-	return r.db
+	return traced(r.db)
 }
 
 // GetAllFunds retrieves all funds from the database with their latest prices.
@@ -308,6 +308,61 @@ func (r *FundRepository) GetFundPrice(fundIDs []string, startDate, endDate time.
 	return fundPriceByFund, nil
 }
 
+// GetLatestFundPrices retrieves the most recent price of each of the given funds.
+// Funds without any price are absent from the returned map.
+func (r *FundRepository) GetLatestFundPrices(fundIDs []string) (map[string]model.FundPrice, error) {
+	fundLog.Debug("getting latest fund prices", "fund_count", len(fundIDs))
+	latestPrices := make(map[string]model.FundPrice, len(fundIDs))
+	if len(fundIDs) == 0 {
+		return latestPrices, nil
+	}
+
+	placeholders := make([]string, len(fundIDs))
+	args := make([]any, len(fundIDs))
+	for i, id := range fundIDs {
+		placeholders[i] = "?"
+		args[i] = id
+	}
+
+	//#nosec G202 -- Safe: placeholders are generated programmatically, not from user input
+	query := `
+		SELECT fp.id, fp.fund_id, fp.date, fp.price
+		FROM fund_price fp
+		INNER JOIN (
+			SELECT fund_id, MAX(date) as latest_date
+			FROM fund_price
+			WHERE fund_id IN (` + strings.Join(placeholders, ",") + `)
+			GROUP BY fund_id
+		) latest ON fp.fund_id = latest.fund_id AND fp.date = latest.latest_date
+	`
+
+	rows, err := r.getQuerier().Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query latest fund prices: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var dateStr string
+		var fp model.FundPrice
+		if err := rows.Scan(&fp.ID, &fp.FundID, &dateStr, &fp.Price); err != nil {
+			return nil, fmt.Errorf("failed to scan latest fund price: %w", err)
+		}
+
+		fp.Date, err = ParseTime(dateStr)
+		if err != nil || fp.Date.IsZero() {
+			return nil, fmt.Errorf("failed to parse date: %w", err)
+		}
+
+		latestPrices[fp.FundID] = fp
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating latest fund prices: %w", err)
+	}
+
+	return latestPrices, nil
+}
+
 // GetSymbol retrieves symbol information by ticker symbol from the symbol_info table.
 // Returns nil and [apperrors.ErrSymbolNotFound] if the symbol is not found.
 // Returns nil, error if a database error occurs.
@@ -570,6 +625,17 @@ func (r *FundRepository) DeleteFund(ctx context.Context, fundID string) error {
 	return nil
 }
 
+// DeleteFundPrices removes all price history for a fund.
+// fund_price has no ON DELETE CASCADE, so prices must be removed before the fund itself.
+func (r *FundRepository) DeleteFundPrices(ctx context.Context, fundID string) error {
+	fundLog.DebugContext(ctx, "deleting fund prices", "fund_id", fundID)
+
+	if _, err := r.getQuerier().ExecContext(ctx, `DELETE FROM fund_price WHERE fund_id = ?`, fundID); err != nil {
+		return fmt.Errorf("failed to delete fund prices: %w", err)
+	}
+	return nil
+}
+
 // InsertFundPrice inserts a single fund price record into the database.
 // This method is used for single price updates, such as adding the latest daily price.
 //
@@ -222,6 +222,60 @@ func TestFundRepository_GetFundPrice(t *testing.T) {
 	})
 }
 
+func TestFundRepository_GetLatestFundPrices(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	repo := repository.NewFundRepository(db)
+
+	fund1 := testutil.NewFund().Build(t, db)
+	fund2 := testutil.NewFund().Build(t, db)
+	fund3 := testutil.NewFund().Build(t, db)
+
+	d1 := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	d2 := time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)
+	d3 := time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC)
+
+	testutil.NewFundPrice(fund1.ID).WithDate(d1).WithPrice(10.0).Build(t, db)
+	testutil.NewFundPrice(fund1.ID).WithDate(d3).WithPrice(12.0).Build(t, db)
+	testutil.NewFundPrice(fund1.ID).WithDate(d2).WithPrice(11.0).Build(t, db)
+	testutil.NewFundPrice(fund2.ID).WithDate(d1).WithPrice(20.0).Build(t, db)
+
+	t.Run("returns the most recent price of each fund", func(t *testing.T) {
+		result, err := repo.GetLatestFundPrices([]string{fund1.ID, fund2.ID})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := result[fund1.ID]; got.Price != 12.0 || !got.Date.Equal(d3) {
+			t.Errorf("expected fund1 price 12 on %s, got %f on %s", d3.Format("2006-01-02"), got.Price, got.Date.Format("2006-01-02"))
+		}
+		if got := result[fund2.ID]; got.Price != 20.0 || !got.Date.Equal(d1) {
+			t.Errorf("expected fund2 price 20 on %s, got %f on %s", d1.Format("2006-01-02"), got.Price, got.Date.Format("2006-01-02"))
+		}
+	})
+
+	t.Run("omits funds without prices", func(t *testing.T) {
+		result, err := repo.GetLatestFundPrices([]string{fund1.ID, fund3.ID})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if _, ok := result[fund3.ID]; ok {
+			t.Error("expected no price for fund3")
+		}
+		if len(result) != 1 {
+			t.Errorf("expected 1 price, got %d", len(result))
+		}
+	})
+
+	t.Run("returns empty map for no funds", func(t *testing.T) {
+		result, err := repo.GetLatestFundPrices(nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(result) != 0 {
+			t.Errorf("expected empty map, got %d entries", len(result))
+		}
+	})
+}
+
 func TestFundRepository_GetFundBySymbolOrIsin(t *testing.T) {
 	db := testutil.SetupTestDB(t)
 	repo := repository.NewFundRepository(db)
@@ -37,9 +37,9 @@ func (r *IbkrRepository) WithTx(tx *sql.Tx) *IbkrRepository {
 // getQuerier returns the active transaction if one is set, otherwise the database connection.
 func (r *IbkrRepository) getQuerier() Querier {
 	if r.tx != nil {
-		return r.tx
+		return traced(r.tx)
 	}
-	return r.db
+	return traced(r.db)
 }
 
 // GetIbkrConfig retrieves the IBKR integration configuration from the database.
@@ -196,32 +196,39 @@ func (r *IbkrRepository) GetPendingDividends(symbol, isin string) ([]model.Pendi
 
 }
 
-// GetInbox retrieves IBKR imported transactions from the ibkr_transaction table.
-// Filters by status (defaults to "pending" if not provided) and optionally by transaction_type.
+// GetInbox retrieves staged transactions from the ibkr_transaction table.
+// Filters by status (defaults to "pending" if not provided) and optionally by source and transaction_type.
+// An empty filter.Source returns transactions from every importer.
 // Returns transactions ordered by transaction_date descending.
 // Returns an empty slice if no transactions match the criteria.
-func (r *IbkrRepository) GetInbox(status, transactionType string) ([]model.IBKRTransaction, error) {
-	ibkrLog.Debug("getting ibkr inbox", "status", status, "transaction_type", transactionType)
+func (r *IbkrRepository) GetInbox(filter model.InboxFilter) ([]model.IBKRTransaction, error) {
+	ibkrLog.Debug("getting ibkr inbox", "inbox_source", filter.Source, "status", filter.Status, "transaction_type", filter.TransactionType)
 	var query string
 	var args []any
 
 	query = `
-	SELECT id, ibkr_transaction_id, transaction_date, symbol, isin, description,
+	SELECT id, source, ibkr_transaction_id, transaction_date, symbol, isin, description,
          transaction_type, quantity, price, total_amount, currency, fees,
          status, imported_at, report_date, notes
 	FROM ibkr_transaction
 	WHERE status = ?
   `
-	if status == "" {
-		args = append(args, "pending")
+	if filter.Status == "" {
+		args = append(args, string(model.InboxStatusPending))
 	} else {
-		args = append(args, status)
+		args = append(args, filter.Status)
 	}
-	if transactionType != "" {
+	if filter.Source != "" {
+		query += `
+			AND source = ?
+		`
+		args = append(args, filter.Source)
+	}
+	if filter.TransactionType != "" {
 		query += `
 			AND transaction_type = ?
 		`
-		args = append(args, transactionType)
+		args = append(args, filter.TransactionType)
 	}
 
 	query += `
@@ -240,6 +247,7 @@ func (r *IbkrRepository) GetInbox(status, transactionType string) ([]model.IBKRT
 		t := model.IBKRTransaction{}
 		err := rows.Scan(
 			&t.ID,
+			&t.Source,
 			&t.IBKRTransactionID,
 			&transactionDateStr,
 			&t.Symbol,
@@ -284,20 +292,26 @@ func (r *IbkrRepository) GetInbox(status, transactionType string) ([]model.IBKRT
 	return ibkrTransactions, nil
 }
 
-// GetIbkrInboxCount retrieves the count of IBKR imported transactions.
+// GetIbkrInboxCount retrieves the count of pending transactions in the inbox.
 // Uses a COUNT(*) query for efficiency rather than fetching all records.
+// An empty source counts pending transactions from every importer.
 // Returns 0 if no transactions exist.
-func (r *IbkrRepository) GetIbkrInboxCount() (model.IBKRInboxCount, error) {
-	ibkrLog.Debug("getting ibkr inbox count")
+func (r *IbkrRepository) GetIbkrInboxCount(source string) (model.IBKRInboxCount, error) {
+	ibkrLog.Debug("getting ibkr inbox count", "inbox_source", source)
 
 	query := `
         SELECT count(*)
 		FROM ibkr_transaction
 		WHERE status = 'pending'
       `
+	var args []any
+	if source != "" {
+		query += ` AND source = ?`
+		args = append(args, source)
+	}
 
 	count := model.IBKRInboxCount{}
-	err := r.getQuerier().QueryRow(query).Scan(&count.Count)
+	err := r.getQuerier().QueryRow(query, args...).Scan(&count.Count)
 	if err == sql.ErrNoRows {
 		return model.IBKRInboxCount{
 			Count: 0,
@@ -321,7 +335,7 @@ func (r *IbkrRepository) GetIbkrTransaction(transactionID string) (model.IBKRTra
 	ibkrLog.Debug("getting ibkr transaction", "transaction_id", transactionID)
 
 	query := `
-        SELECT id, ibkr_transaction_id, transaction_date, symbol, isin, description, transaction_type, quantity, price, total_amount, currency, fees, status, imported_at, processed_at, report_date, notes
+        SELECT id, source, ibkr_transaction_id, transaction_date, symbol, isin, description, transaction_type, quantity, price, total_amount, currency, fees, status, imported_at, processed_at, report_date, notes
 		FROM ibkr_transaction
 		WHERE id = ?
       `
@@ -332,6 +346,7 @@ func (r *IbkrRepository) GetIbkrTransaction(transactionID string) (model.IBKRTra
 
 	err := r.getQuerier().QueryRow(query, transactionID).Scan(
 		&t.ID,
+		&t.Source,
 		&t.IBKRTransactionID,
 		&transactionDateStr,
 		&t.Symbol,
@@ -480,7 +495,8 @@ func (r *IbkrRepository) GetIbkrTransactionAllocations(IBKRtransactionID string)
 
 }
 
-// AddIbkrTransactions inserts a batch of IBKR transactions using a prepared statement.
+// AddIbkrTransactions inserts a batch of inbox transactions using a prepared statement.
+// Transactions without a Source are recorded as originating from the IBKR importer.
 // Returns nil immediately if the slice is empty.
 // Returns an error if any individual insert fails.
 func (r *IbkrRepository) AddIbkrTransactions(ctx context.Context, transactions []model.IBKRTransaction) error {
@@ -490,8 +506,8 @@ func (r *IbkrRepository) AddIbkrTransactions(ctx context.Context, transactions [
 	}
 
 	stmt, err := r.getQuerier().PrepareContext(ctx, `
-        INSERT INTO ibkr_transaction (id, ibkr_transaction_id, transaction_date, symbol, isin, description, transaction_type, quantity, price, total_amount, currency, fees, status, imported_at, processed_at, raw_data, report_date, notes)
-        VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+        INSERT INTO ibkr_transaction (id, source, ibkr_transaction_id, transaction_date, symbol, isin, description, transaction_type, quantity, price, total_amount, currency, fees, status, imported_at, processed_at, raw_data, report_date, notes)
+        VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
     `)
 	if err != nil {
 		return fmt.Errorf("failed to prepare statement: %w", err)
@@ -505,8 +521,13 @@ func (r *IbkrRepository) AddIbkrTransactions(ctx context.Context, transactions [
 			processedAt.String = t.ProcessedAt.Format("2006-01-02 15:04:05")
 			processedAt.Valid = true
 		}
+		source := t.Source
+		if source == "" {
+			source = string(model.InboxSourceIBKR)
+		}
 		_, err := stmt.ExecContext(ctx,
 			t.ID,
+			source,
 			t.IBKRTransactionID,
 			t.TransactionDate.Format("2006-01-02"),
 			t.Symbol,
@@ -252,7 +252,7 @@ func TestIbkrRepository_GetInbox(t *testing.T) {
 		db := testutil.SetupTestDB(t)
 		repo := repository.NewIbkrRepository(db)
 
-		txns, err := repo.GetInbox("pending", "")
+		txns, err := repo.GetInbox(model.InboxFilter{Status: "pending"})
 		if err != nil {
 			t.Fatalf("GetInbox: %v", err)
 		}
@@ -268,7 +268,7 @@ func TestIbkrRepository_GetInbox(t *testing.T) {
 		testutil.NewIBKRTransaction().WithStatus("pending").Build(t, db)
 		testutil.NewIBKRTransaction().WithStatus("processed").Build(t, db)
 
-		txns, err := repo.GetInbox("", "")
+		txns, err := repo.GetInbox(model.InboxFilter{})
 		if err != nil {
 			t.Fatalf("GetInbox: %v", err)
 		}
@@ -288,7 +288,7 @@ func TestIbkrRepository_GetInbox(t *testing.T) {
 		testutil.NewIBKRTransaction().WithStatus("processed").Build(t, db)
 		testutil.NewIBKRTransaction().WithStatus("processed").Build(t, db)
 
-		txns, err := repo.GetInbox("processed", "")
+		txns, err := repo.GetInbox(model.InboxFilter{Status: "processed"})
 		if err != nil {
 			t.Fatalf("GetInbox: %v", err)
 		}
@@ -304,7 +304,7 @@ func TestIbkrRepository_GetInbox(t *testing.T) {
 		testutil.NewIBKRTransaction().WithStatus("pending").WithType("buy").Build(t, db)
 		testutil.NewIBKRTransaction().WithStatus("pending").WithType("sell").Build(t, db)
 
-		txns, err := repo.GetInbox("pending", "buy")
+		txns, err := repo.GetInbox(model.InboxFilter{Status: "pending", TransactionType: "buy"})
 		if err != nil {
 			t.Fatalf("GetInbox: %v", err)
 		}
@@ -315,6 +315,33 @@ func TestIbkrRepository_GetInbox(t *testing.T) {
 			t.Errorf("expected type=buy, got %s", txns[0].TransactionType)
 		}
 	})
+
+	t.Run("source filter", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		repo := repository.NewIbkrRepository(db)
+
+		testutil.NewIBKRTransaction().Build(t, db)
+		testutil.NewIBKRTransaction().WithSource("csv").Build(t, db)
+
+		txns, err := repo.GetInbox(model.InboxFilter{Source: "csv"})
+		if err != nil {
+			t.Fatalf("GetInbox: %v", err)
+		}
+		if len(txns) != 1 {
+			t.Fatalf("expected 1 csv transaction, got %d", len(txns))
+		}
+		if txns[0].Source != "csv" {
+			t.Errorf("expected source=csv, got %s", txns[0].Source)
+		}
+
+		all, err := repo.GetInbox(model.InboxFilter{})
+		if err != nil {
+			t.Fatalf("GetInbox: %v", err)
+		}
+		if len(all) != 2 {
+			t.Errorf("expected 2 transactions across sources, got %d", len(all))
+		}
+	})
 }
 
 // ---------------------------------------------------------------------------
@@ -326,7 +353,7 @@ func TestIbkrRepository_GetIbkrInboxCount(t *testing.T) {
 		db := testutil.SetupTestDB(t)
 		repo := repository.NewIbkrRepository(db)
 
-		count, err := repo.GetIbkrInboxCount()
+		count, err := repo.GetIbkrInboxCount("")
 		if err != nil {
 			t.Fatalf("GetIbkrInboxCount: %v", err)
 		}
@@ -343,7 +370,7 @@ func TestIbkrRepository_GetIbkrInboxCount(t *testing.T) {
 		testutil.NewIBKRTransaction().WithStatus("pending").Build(t, db)
 		testutil.NewIBKRTransaction().WithStatus("processed").Build(t, db)
 
-		count, err := repo.GetIbkrInboxCount()
+		count, err := repo.GetIbkrInboxCount("")
 		if err != nil {
 			t.Fatalf("GetIbkrInboxCount: %v", err)
 		}
@@ -351,6 +378,22 @@ func TestIbkrRepository_GetIbkrInboxCount(t *testing.T) {
 			t.Errorf("expected count=2, got %d", count.Count)
 		}
 	})
+
+	t.Run("counts only the requested source", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		repo := repository.NewIbkrRepository(db)
+
+		testutil.NewIBKRTransaction().WithStatus("pending").Build(t, db)
+		testutil.NewIBKRTransaction().WithStatus("pending").WithSource("manual").Build(t, db)
+
+		count, err := repo.GetIbkrInboxCount("ibkr")
+		if err != nil {
+			t.Fatalf("GetIbkrInboxCount: %v", err)
+		}
+		if count.Count != 1 {
+			t.Errorf("expected count=1, got %d", count.Count)
+		}
+	})
 }
 
 // ---------------------------------------------------------------------------
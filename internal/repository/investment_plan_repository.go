@@ -0,0 +1,208 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/apperrors"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/model"
+)
+
+// investmentPlanColumns is the column list shared by every investment plan SELECT. Plans
+// are always joined with their portfolio fund as pf and fund as f.
+const investmentPlanColumns = `p.id, p.portfolio_fund_id, pf.portfolio_id, pf.fund_id, f.name, p.amount, p.frequency,
+	p.day_of_month, p.start_date, p.end_date, p.enabled, p.last_run_date, p.created_at, p.updated_at`
+
+// investmentPlanFrom is the FROM clause shared by every investment plan SELECT.
+const investmentPlanFrom = ` FROM investment_plan p
+	JOIN portfolio_fund pf ON p.portfolio_fund_id = pf.id
+	JOIN fund f ON pf.fund_id = f.id`
+
+// InvestmentPlanRepository provides data access methods for recurring investment plans.
+type InvestmentPlanRepository struct {
+	db *sql.DB
+	tx *sql.Tx
+}
+
+// NewInvestmentPlanRepository creates a new InvestmentPlanRepository with the provided database connection.
+func NewInvestmentPlanRepository(db *sql.DB) *InvestmentPlanRepository {
+	return &InvestmentPlanRepository{db: db}
+}
+
+// WithTx returns a new InvestmentPlanRepository scoped to the provided transaction.
+func (r *InvestmentPlanRepository) WithTx(tx *sql.Tx) *InvestmentPlanRepository {
+	return &InvestmentPlanRepository{
+		db: r.db,
+		tx: tx,
+	}
+}
+
+// getQuerier returns the active transaction if one is set, otherwise the database connection.
+func (r *InvestmentPlanRepository) getQuerier() Querier {
+	if r.tx != nil {
+		return traced(r.tx)
+	}
+	return traced(r.db)
+}
+
+// GetInvestmentPlans retrieves the investment plans of a portfolio, oldest first.
+// An empty portfolioID returns the plans of every portfolio in scope.
+func (r *InvestmentPlanRepository) GetInvestmentPlans(portfolioID string, scope model.PortfolioScope) ([]model.InvestmentPlan, error) {
+	condition, args := scopeCondition(scope, "pf.portfolio_id")
+	//#nosec G202 -- Safe: placeholders are generated programmatically, not from user input
+	query := `SELECT ` + investmentPlanColumns + investmentPlanFrom + ` WHERE ` + condition
+	if portfolioID != "" {
+		query += ` AND pf.portfolio_id = ?`
+		args = append(args, portfolioID)
+	}
+	return r.queryInvestmentPlans(query+` ORDER BY p.created_at, p.id`, args...)
+}
+
+// GetEnabledInvestmentPlans retrieves every enabled investment plan, oldest first.
+func (r *InvestmentPlanRepository) GetEnabledInvestmentPlans() ([]model.InvestmentPlan, error) {
+	return r.queryInvestmentPlans(`SELECT ` + investmentPlanColumns + investmentPlanFrom + ` WHERE p.enabled = 1 ORDER BY p.created_at, p.id`)
+}
+
+func (r *InvestmentPlanRepository) queryInvestmentPlans(query string, args ...any) ([]model.InvestmentPlan, error) {
+	rows, err := r.getQuerier().Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query investment plans: %w", err)
+	}
+	defer rows.Close()
+
+	plans := []model.InvestmentPlan{}
+	for rows.Next() {
+		plan, err := scanInvestmentPlan(rows)
+		if err != nil {
+			return nil, err
+		}
+		plans = append(plans, plan)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating investment plans: %w", err)
+	}
+	return plans, nil
+}
+
+// GetInvestmentPlan retrieves an investment plan by ID. Returns ErrInvestmentPlanNotFound if it does not exist.
+func (r *InvestmentPlanRepository) GetInvestmentPlan(planID string) (model.InvestmentPlan, error) {
+	row := r.getQuerier().QueryRow(`SELECT `+investmentPlanColumns+investmentPlanFrom+` WHERE p.id = ?`, planID)
+
+	plan, err := scanInvestmentPlan(row)
+	if err == sql.ErrNoRows {
+		return model.InvestmentPlan{}, apperrors.ErrInvestmentPlanNotFound
+	}
+	if err != nil {
+		return model.InvestmentPlan{}, err
+	}
+	return plan, nil
+}
+
+func scanInvestmentPlan(s scanner) (model.InvestmentPlan, error) {
+	var plan model.InvestmentPlan
+	var startDateStr, createdAtStr, updatedAtStr string
+	var endDateStr, lastRunDateStr sql.NullString
+
+	if err := s.Scan(&plan.ID, &plan.PortfolioFundID, &plan.PortfolioID, &plan.FundID, &plan.FundName, &plan.Amount,
+		&plan.Frequency, &plan.DayOfMonth, &startDateStr, &endDateStr, &plan.Enabled, &lastRunDateStr,
+		&createdAtStr, &updatedAtStr); err != nil {
+		if err == sql.ErrNoRows {
+			return model.InvestmentPlan{}, err
+		}
+		return model.InvestmentPlan{}, fmt.Errorf("failed to scan investment plan: %w", err)
+	}
+
+	var err error
+	if plan.StartDate, err = ParseTime(startDateStr); err != nil {
+		return model.InvestmentPlan{}, fmt.Errorf("failed to parse start_date: %w", err)
+	}
+	if plan.EndDate, err = parseNullableDate(endDateStr); err != nil {
+		return model.InvestmentPlan{}, fmt.Errorf("failed to parse end_date: %w", err)
+	}
+	if plan.LastRunDate, err = parseNullableDate(lastRunDateStr); err != nil {
+		return model.InvestmentPlan{}, fmt.Errorf("failed to parse last_run_date: %w", err)
+	}
+	if plan.CreatedAt, err = ParseTime(createdAtStr); err != nil {
+		return model.InvestmentPlan{}, fmt.Errorf("failed to parse created_at: %w", err)
+	}
+	if plan.UpdatedAt, err = ParseTime(updatedAtStr); err != nil {
+		return model.InvestmentPlan{}, fmt.Errorf("failed to parse updated_at: %w", err)
+	}
+	return plan, nil
+}
+
+// parseNullableDate parses a nullable date column, returning nil for NULL.
+func parseNullableDate(s sql.NullString) (*time.Time, error) {
+	if !s.Valid {
+		return nil, nil
+	}
+	t, err := ParseTime(s.String)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// formatNullableDate formats an optional date for a DATE column, returning NULL for nil.
+func formatNullableDate(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return t.Format("2006-01-02")
+}
+
+// InsertInvestmentPlan stores a new investment plan.
+func (r *InvestmentPlanRepository) InsertInvestmentPlan(ctx context.Context, plan *model.InvestmentPlan) error {
+	_, err := r.getQuerier().ExecContext(ctx, `
+		INSERT INTO investment_plan (id, portfolio_fund_id, amount, frequency, day_of_month, start_date, end_date, enabled,
+			last_run_date, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, plan.ID, plan.PortfolioFundID, plan.Amount, plan.Frequency, plan.DayOfMonth, plan.StartDate.Format("2006-01-02"),
+		formatNullableDate(plan.EndDate), plan.Enabled, formatNullableDate(plan.LastRunDate),
+		plan.CreatedAt.UTC().Format("2006-01-02 15:04:05"), plan.UpdatedAt.UTC().Format("2006-01-02 15:04:05"))
+	if err != nil {
+		return fmt.Errorf("failed to insert investment plan: %w", err)
+	}
+	return nil
+}
+
+// UpdateInvestmentPlan replaces the schedule, amount and enabled flag of an investment plan.
+// Returns ErrInvestmentPlanNotFound if it does not exist.
+func (r *InvestmentPlanRepository) UpdateInvestmentPlan(ctx context.Context, plan *model.InvestmentPlan) error {
+	result, err := r.getQuerier().ExecContext(ctx, `
+		UPDATE investment_plan
+		SET amount = ?, frequency = ?, day_of_month = ?, start_date = ?, end_date = ?, enabled = ?, updated_at = ?
+		WHERE id = ?
+	`, plan.Amount, plan.Frequency, plan.DayOfMonth, plan.StartDate.Format("2006-01-02"), formatNullableDate(plan.EndDate),
+		plan.Enabled, plan.UpdatedAt.UTC().Format("2006-01-02 15:04:05"), plan.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update investment plan: %w", err)
+	}
+	return requireAffected(result, apperrors.ErrInvestmentPlanNotFound)
+}
+
+// UpdateInvestmentPlanLastRun records the scheduled date of the latest generated run.
+// Returns ErrInvestmentPlanNotFound if the plan does not exist.
+func (r *InvestmentPlanRepository) UpdateInvestmentPlanLastRun(ctx context.Context, planID string, lastRunDate time.Time) error {
+	result, err := r.getQuerier().ExecContext(ctx, `UPDATE investment_plan SET last_run_date = ? WHERE id = ?`,
+		lastRunDate.Format("2006-01-02"), planID)
+	if err != nil {
+		return fmt.Errorf("failed to update investment plan last run: %w", err)
+	}
+	return requireAffected(result, apperrors.ErrInvestmentPlanNotFound)
+}
+
+// DeleteInvestmentPlan removes an investment plan. The transactions it generated are kept
+// as regular transactions. Returns ErrInvestmentPlanNotFound if it does not exist.
+func (r *InvestmentPlanRepository) DeleteInvestmentPlan(ctx context.Context, planID string) error {
+	if _, err := r.getQuerier().ExecContext(ctx, `UPDATE "transaction" SET investment_plan_id = NULL WHERE investment_plan_id = ?`, planID); err != nil {
+		return fmt.Errorf("failed to unlink investment plan transactions: %w", err)
+	}
+	result, err := r.getQuerier().ExecContext(ctx, `DELETE FROM investment_plan WHERE id = ?`, planID)
+	if err != nil {
+		return fmt.Errorf("failed to delete investment plan: %w", err)
+	}
+	return requireAffected(result, apperrors.ErrInvestmentPlanNotFound)
+}
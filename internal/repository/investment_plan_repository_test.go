@@ -0,0 +1,127 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/apperrors"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/model"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/repository"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/testutil"
+)
+
+func TestInvestmentPlanRepository(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	repo := repository.NewInvestmentPlanRepository(db)
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Second)
+
+	portfolio := testutil.NewPortfolio().Build(t, db)
+	other := testutil.NewPortfolio().Build(t, db)
+	fund := testutil.NewFund().WithName("World ETF").Build(t, db)
+	pf := testutil.NewPortfolioFund(portfolio.ID, fund.ID).Build(t, db)
+	otherPF := testutil.NewPortfolioFund(other.ID, fund.ID).Build(t, db)
+
+	end := time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC)
+	plan := model.InvestmentPlan{
+		ID:              testutil.MakeID(),
+		PortfolioFundID: pf.ID,
+		Amount:          250,
+		Frequency:       model.InvestmentPlanMonthly,
+		DayOfMonth:      15,
+		StartDate:       time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:         &end,
+		Enabled:         true,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	disabled := model.InvestmentPlan{
+		ID:              testutil.MakeID(),
+		PortfolioFundID: otherPF.ID,
+		Amount:          100,
+		Frequency:       model.InvestmentPlanQuarterly,
+		DayOfMonth:      1,
+		StartDate:       time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+
+	t.Run("insert and get", func(t *testing.T) {
+		for _, p := range []*model.InvestmentPlan{&plan, &disabled} {
+			if err := repo.InsertInvestmentPlan(ctx, p); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+
+		got, err := repo.GetInvestmentPlan(plan.ID)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got.PortfolioID != portfolio.ID || got.FundID != fund.ID || got.FundName != "World ETF" ||
+			got.Frequency != model.InvestmentPlanMonthly || !got.StartDate.Equal(plan.StartDate) ||
+			got.EndDate == nil || !got.EndDate.Equal(end) || got.LastRunDate != nil || !got.Enabled {
+			t.Errorf("unexpected plan: %+v", got)
+		}
+	})
+
+	t.Run("list per portfolio, all and enabled", func(t *testing.T) {
+		got, err := repo.GetInvestmentPlans(portfolio.ID, model.AllPortfolios())
+		if err != nil || len(got) != 1 || got[0].ID != plan.ID {
+			t.Errorf("expected the portfolio's plan, got %+v (%v)", got, err)
+		}
+		got, err = repo.GetInvestmentPlans("", model.AllPortfolios())
+		if err != nil || len(got) != 2 {
+			t.Errorf("expected every plan, got %+v (%v)", got, err)
+		}
+		got, err = repo.GetEnabledInvestmentPlans()
+		if err != nil || len(got) != 1 || got[0].ID != plan.ID {
+			t.Errorf("expected only the enabled plan, got %+v (%v)", got, err)
+		}
+	})
+
+	t.Run("update and last run", func(t *testing.T) {
+		plan.Amount = 300
+		plan.EndDate = nil
+		if err := repo.UpdateInvestmentPlan(ctx, &plan); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		lastRun := time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)
+		if err := repo.UpdateInvestmentPlanLastRun(ctx, plan.ID, lastRun); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		got, err := repo.GetInvestmentPlan(plan.ID)
+		if err != nil || got.Amount != 300 || got.EndDate != nil || got.LastRunDate == nil || !got.LastRunDate.Equal(lastRun) {
+			t.Errorf("expected the updated plan, got %+v (%v)", got, err)
+		}
+	})
+
+	t.Run("delete keeps generated transactions", func(t *testing.T) {
+		tx := testutil.NewTransaction(pf.ID).Build(t, db)
+		if _, err := db.Exec(`UPDATE "transaction" SET investment_plan_id = ? WHERE id = ?`, plan.ID, tx.ID); err != nil {
+			t.Fatalf("link transaction: %v", err)
+		}
+
+		if err := repo.DeleteInvestmentPlan(ctx, plan.ID); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if _, err := repo.GetInvestmentPlan(plan.ID); !errors.Is(err, apperrors.ErrInvestmentPlanNotFound) {
+			t.Errorf("expected ErrInvestmentPlanNotFound, got %v", err)
+		}
+		got, err := repository.NewTransactionRepository(db).GetTransactionByID(tx.ID)
+		if err != nil || got.InvestmentPlanID != "" {
+			t.Errorf("expected the transaction to remain unlinked, got %+v (%v)", got, err)
+		}
+	})
+
+	t.Run("missing plan", func(t *testing.T) {
+		if err := repo.DeleteInvestmentPlan(ctx, testutil.MakeID()); !errors.Is(err, apperrors.ErrInvestmentPlanNotFound) {
+			t.Errorf("expected ErrInvestmentPlanNotFound on delete, got %v", err)
+		}
+		if err := repo.UpdateInvestmentPlanLastRun(ctx, testutil.MakeID(), now); !errors.Is(err, apperrors.ErrInvestmentPlanNotFound) {
+			t.Errorf("expected ErrInvestmentPlanNotFound on last run, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,236 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/apperrors"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/model"
+)
+
+// jobColumns is the column list shared by every job SELECT.
+const jobColumns = `id, type, params, status, progress, result, error, retry_of, user_id, request_id, created_at, started_at, finished_at`
+
+// JobRepository provides data access methods for background jobs.
+type JobRepository struct {
+	db *sql.DB
+	tx *sql.Tx
+}
+
+// NewJobRepository creates a new JobRepository with the provided database connection.
+func NewJobRepository(db *sql.DB) *JobRepository {
+	return &JobRepository{db: db}
+}
+
+// WithTx returns a new JobRepository scoped to the provided transaction.
+func (r *JobRepository) WithTx(tx *sql.Tx) *JobRepository {
+	return &JobRepository{
+		db: r.db,
+		tx: tx,
+	}
+}
+
+// getQuerier returns the active transaction if one is set, otherwise the database connection.
+func (r *JobRepository) getQuerier() Querier {
+	if r.tx != nil {
+		return traced(r.tx)
+	}
+	return traced(r.db)
+}
+
+// GetJobs retrieves jobs matching the filters, newest first.
+func (r *JobRepository) GetJobs(filters *model.JobFilters) ([]model.Job, error) {
+	var conditions []string
+	var args []any
+
+	if len(filters.Types) > 0 {
+		conditions = append(conditions, "type IN ("+placeholders(len(filters.Types))+")")
+		for _, t := range filters.Types {
+			args = append(args, t)
+		}
+	}
+	if len(filters.Statuses) > 0 {
+		conditions = append(conditions, "status IN ("+placeholders(len(filters.Statuses))+")")
+		for _, s := range filters.Statuses {
+			args = append(args, s)
+		}
+	}
+
+	query := `SELECT ` + jobColumns + ` FROM job`
+	if len(conditions) > 0 {
+		query += ` WHERE ` + strings.Join(conditions, " AND ")
+	}
+	query += ` ORDER BY created_at DESC, id DESC LIMIT ?`
+	args = append(args, filters.Limit)
+
+	rows, err := r.getQuerier().Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query jobs: %w", err)
+	}
+	defer rows.Close()
+
+	jobs := []model.Job{}
+	for rows.Next() {
+		j, err := scanJob(rows)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, j)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating jobs: %w", err)
+	}
+	return jobs, nil
+}
+
+// GetJob retrieves a job by ID. Returns ErrJobNotFound if it does not exist.
+func (r *JobRepository) GetJob(jobID string) (model.Job, error) {
+	row := r.getQuerier().QueryRow(`SELECT `+jobColumns+` FROM job WHERE id = ?`, jobID)
+
+	j, err := scanJob(row)
+	if err == sql.ErrNoRows {
+		return model.Job{}, apperrors.ErrJobNotFound
+	}
+	if err != nil {
+		return model.Job{}, err
+	}
+	return j, nil
+}
+
+func scanJob(s scanner) (model.Job, error) {
+	var j model.Job
+	var createdAtStr string
+	var params, result, errMsg, retryOf, userID, requestID, startedAtStr, finishedAtStr sql.NullString
+
+	if err := s.Scan(&j.ID, &j.Type, &params, &j.Status, &j.Progress, &result, &errMsg,
+		&retryOf, &userID, &requestID, &createdAtStr, &startedAtStr, &finishedAtStr); err != nil {
+		if err == sql.ErrNoRows {
+			return model.Job{}, err
+		}
+		return model.Job{}, fmt.Errorf("failed to scan job: %w", err)
+	}
+
+	if params.Valid {
+		j.Params = json.RawMessage(params.String)
+	}
+	if result.Valid {
+		j.Result = json.RawMessage(result.String)
+	}
+	j.Error = errMsg.String
+	j.RetryOf = retryOf.String
+	j.UserID = userID.String
+	j.RequestID = requestID.String
+
+	var err error
+	if j.CreatedAt, err = ParseTime(createdAtStr); err != nil {
+		return model.Job{}, fmt.Errorf("failed to parse created_at: %w", err)
+	}
+	for _, col := range []struct {
+		name string
+		src  sql.NullString
+		dst  **time.Time
+	}{
+		{"started_at", startedAtStr, &j.StartedAt},
+		{"finished_at", finishedAtStr, &j.FinishedAt},
+	} {
+		if !col.src.Valid {
+			continue
+		}
+		parsed, err := ParseTime(col.src.String)
+		if err != nil {
+			return model.Job{}, fmt.Errorf("failed to parse %s: %w", col.name, err)
+		}
+		*col.dst = &parsed
+	}
+
+	return j, nil
+}
+
+// InsertJob stores a new job.
+func (r *JobRepository) InsertJob(ctx context.Context, j *model.Job) error {
+	var startedAt any
+	if j.StartedAt != nil {
+		startedAt = j.StartedAt.UTC().Format("2006-01-02 15:04:05")
+	}
+
+	_, err := r.getQuerier().ExecContext(ctx, `
+		INSERT INTO job (id, type, params, status, progress, retry_of, user_id, request_id, created_at, started_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, j.ID, j.Type, nullableJSON(j.Params), j.Status, j.Progress,
+		nullableString(j.RetryOf), nullableString(j.UserID), nullableString(j.RequestID),
+		j.CreatedAt.UTC().Format("2006-01-02 15:04:05"), startedAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert job: %w", err)
+	}
+	return nil
+}
+
+// StartJob marks a pending job as running.
+func (r *JobRepository) StartJob(ctx context.Context, jobID string, at time.Time) error {
+	result, err := r.getQuerier().ExecContext(ctx,
+		`UPDATE job SET status = ?, started_at = ? WHERE id = ?`,
+		model.JobStatusRunning, at.UTC().Format("2006-01-02 15:04:05"), jobID)
+	if err != nil {
+		return fmt.Errorf("failed to start job: %w", err)
+	}
+	return requireAffected(result, apperrors.ErrJobNotFound)
+}
+
+// UpdateJobProgress records the completion percentage of a running job.
+func (r *JobRepository) UpdateJobProgress(ctx context.Context, jobID string, progress int) error {
+	_, err := r.getQuerier().ExecContext(ctx, `UPDATE job SET progress = ? WHERE id = ?`, progress, jobID)
+	if err != nil {
+		return fmt.Errorf("failed to update job progress: %w", err)
+	}
+	return nil
+}
+
+// FinishJob records the outcome of a job. A succeeded job is set to 100% progress.
+func (r *JobRepository) FinishJob(ctx context.Context, jobID string, status model.JobStatus, result json.RawMessage, errMsg string, at time.Time) error {
+	_, err := r.getQuerier().ExecContext(ctx, `
+		UPDATE job
+		SET status = ?, result = ?, error = ?, finished_at = ?,
+		    progress = CASE WHEN ? = 'succeeded' THEN 100 ELSE progress END
+		WHERE id = ?
+	`, status, nullableJSON(result), nullableString(errMsg), at.UTC().Format("2006-01-02 15:04:05"), status, jobID)
+	if err != nil {
+		return fmt.Errorf("failed to finish job: %w", err)
+	}
+	return nil
+}
+
+// FailUnfinishedJobs marks every pending or running job as failed with the given reason.
+// Used at startup: jobs run in-process, so anything unfinished was cut off by a restart.
+func (r *JobRepository) FailUnfinishedJobs(ctx context.Context, reason string, at time.Time) (int64, error) {
+	result, err := r.getQuerier().ExecContext(ctx, `
+		UPDATE job SET status = ?, error = ?, finished_at = ?
+		WHERE status IN (?, ?)
+	`, model.JobStatusFailed, reason, at.UTC().Format("2006-01-02 15:04:05"), model.JobStatusPending, model.JobStatusRunning)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fail unfinished jobs: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return n, nil
+}
+
+// DeleteFinishedJobs removes succeeded and failed jobs that finished before the cutoff.
+func (r *JobRepository) DeleteFinishedJobs(ctx context.Context, before time.Time) (int64, error) {
+	result, err := r.getQuerier().ExecContext(ctx, `
+		DELETE FROM job WHERE status IN (?, ?) AND finished_at < ?
+	`, model.JobStatusSucceeded, model.JobStatusFailed, before.UTC().Format("2006-01-02 15:04:05"))
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete finished jobs: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return n, nil
+}
@@ -0,0 +1,161 @@
+package repository_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/apperrors"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/model"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/repository"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/testutil"
+)
+
+func insertTestJob(t *testing.T, repo *repository.JobRepository, jobType model.JobType, createdAt time.Time) model.Job {
+	t.Helper()
+	job := model.Job{
+		ID:        testutil.MakeID(),
+		Type:      string(jobType),
+		Params:    json.RawMessage(`{"startDate":"2024-01-01"}`),
+		Status:    string(model.JobStatusPending),
+		CreatedAt: createdAt,
+	}
+	if err := repo.InsertJob(context.Background(), &job); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return job
+}
+
+//nolint:gocyclo // Test function with multiple subtests and assertions.
+func TestJobRepository_Lifecycle(t *testing.T) {
+	t.Run("job moves from pending to succeeded", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		repo := repository.NewJobRepository(db)
+		ctx := context.Background()
+		now := time.Now().UTC().Truncate(time.Second)
+
+		job := insertTestJob(t, repo, model.JobTypeMaterializedRegen, now)
+
+		got, err := repo.GetJob(job.ID)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got.Status != string(model.JobStatusPending) || got.StartedAt != nil || got.FinishedAt != nil {
+			t.Errorf("expected unstarted pending job, got %+v", got)
+		}
+		if string(got.Params) != `{"startDate":"2024-01-01"}` {
+			t.Errorf("expected params to round-trip, got %s", got.Params)
+		}
+
+		if err := repo.StartJob(ctx, job.ID, now); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := repo.UpdateJobProgress(ctx, job.ID, 40); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		got, _ = repo.GetJob(job.ID)
+		if got.Status != string(model.JobStatusRunning) || got.Progress != 40 || got.StartedAt == nil {
+			t.Errorf("expected running job at 40%%, got %+v", got)
+		}
+
+		if err := repo.FinishJob(ctx, job.ID, model.JobStatusSucceeded, json.RawMessage(`{"n":1}`), "", now); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		got, _ = repo.GetJob(job.ID)
+		if got.Status != string(model.JobStatusSucceeded) || got.Progress != 100 || got.FinishedAt == nil {
+			t.Errorf("expected finished job at 100%%, got %+v", got)
+		}
+		if string(got.Result) != `{"n":1}` || got.Error != "" {
+			t.Errorf("unexpected result/error: %s %q", got.Result, got.Error)
+		}
+	})
+
+	t.Run("failed job keeps its progress and error", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		repo := repository.NewJobRepository(db)
+		ctx := context.Background()
+
+		job := insertTestJob(t, repo, model.JobTypeFundPriceUpdate, time.Now().UTC())
+		_ = repo.UpdateJobProgress(ctx, job.ID, 25)
+		if err := repo.FinishJob(ctx, job.ID, model.JobStatusFailed, nil, "boom", time.Now().UTC()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		got, _ := repo.GetJob(job.ID)
+		if got.Status != string(model.JobStatusFailed) || got.Progress != 25 || got.Error != "boom" || got.Result != nil {
+			t.Errorf("unexpected failed job: %+v", got)
+		}
+	})
+
+	t.Run("unknown job", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		repo := repository.NewJobRepository(db)
+
+		if _, err := repo.GetJob(testutil.MakeID()); !errors.Is(err, apperrors.ErrJobNotFound) {
+			t.Errorf("expected ErrJobNotFound, got %v", err)
+		}
+		if err := repo.StartJob(context.Background(), testutil.MakeID(), time.Now()); !errors.Is(err, apperrors.ErrJobNotFound) {
+			t.Errorf("expected ErrJobNotFound, got %v", err)
+		}
+	})
+}
+
+func TestJobRepository_GetJobs(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	repo := repository.NewJobRepository(db)
+	ctx := context.Background()
+	base := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	oldest := insertTestJob(t, repo, model.JobTypeFundPriceUpdate, base)
+	middle := insertTestJob(t, repo, model.JobTypeMaterializedRegen, base.Add(time.Minute))
+	newest := insertTestJob(t, repo, model.JobTypeMaterializedRegen, base.Add(2*time.Minute))
+	_ = repo.FinishJob(ctx, middle.ID, model.JobStatusFailed, nil, "boom", base)
+
+	t.Run("newest first with limit", func(t *testing.T) {
+		jobs, err := repo.GetJobs(&model.JobFilters{Limit: 2})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(jobs) != 2 || jobs[0].ID != newest.ID || jobs[1].ID != middle.ID {
+			t.Errorf("expected [newest, middle], got %v", jobs)
+		}
+	})
+
+	t.Run("filter by type and status", func(t *testing.T) {
+		jobs, err := repo.GetJobs(&model.JobFilters{
+			Types:    []string{string(model.JobTypeMaterializedRegen)},
+			Statuses: []string{string(model.JobStatusPending)},
+			Limit:    50,
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(jobs) != 1 || jobs[0].ID != newest.ID {
+			t.Errorf("expected only the newest job, got %v", jobs)
+		}
+	})
+
+	t.Run("fail unfinished and delete finished", func(t *testing.T) {
+		n, err := repo.FailUnfinishedJobs(ctx, "interrupted", base)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if n != 2 {
+			t.Errorf("expected 2 unfinished jobs failed, got %d", n)
+		}
+		got, _ := repo.GetJob(oldest.ID)
+		if got.Status != string(model.JobStatusFailed) || got.Error != "interrupted" {
+			t.Errorf("expected interrupted failure, got %+v", got)
+		}
+
+		n, err = repo.DeleteFinishedJobs(ctx, base.Add(time.Second))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if n != 3 {
+			t.Errorf("expected 3 jobs deleted, got %d", n)
+		}
+	})
+}
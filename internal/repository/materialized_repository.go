@@ -35,31 +35,31 @@ func (r *MaterializedRepository) WithTx(tx *sql.Tx) *MaterializedRepository {
 // getQuerier returns the active transaction if one is set, otherwise the database connection.
 func (r *MaterializedRepository) getQuerier() Querier {
 	if r.tx != nil {
-		return r.tx
+		return traced(r.tx)
 	}
-	return r.db
+	return traced(r.db)
 }
 
-// GetMaterializedHistory retrieves aggregated portfolio history by querying fund_history_materialized.
+// portfolioHistoryColumns is the column list shared by the portfolio_history_materialized
+// and portfolio_history_rollup SELECTs (aliased s), read by scanPortfolioHistory.
+const portfolioHistoryColumns = `s.portfolio_id, s.date, s.value, s.cost, s.realized_gain, s.unrealized_gain,
+		s.total_dividends, s.total_sale_proceeds, s.total_original_cost, s.total_gain_loss, p.is_archived, s.calculated_at`
+
+// GetMaterializedHistory retrieves portfolio history from the portfolio-level snapshot tables.
 // This method streams results using a callback pattern to minimize memory usage.
 //
-// The method performs the following:
-//  1. Builds the SQL query and parameter list (delegates to buildMaterializedQuery)
-//  2. Executes the query against the fund_history_materialized table
-//  3. Scans each row into a PortfolioHistoryMaterialized struct
-//  4. Parses date fields (date, calculated_at)
-//  5. Invokes the callback function for each record
-//
-// The query aggregates fund-level data from fund_history_materialized using GROUP BY.
-// All values (realized_gain, sale_proceeds, original_cost, dividends) are read directly
-// from pre-computed columns in the materialized table — no correlated subqueries.
-// is_archived is fetched via a JOIN to the portfolio table.
+// Daily history is read from portfolio_history_materialized; weekly and monthly history from
+// portfolio_history_rollup, one row per period dated at the last snapshot within it. A
+// period's row is returned if that date lies within the range; the period containing
+// endDate is closed by the last snapshot on or before endDate. is_archived is fetched via
+// a JOIN to the portfolio table.
 //
 // Parameters:
 //   - portfolioIDs: Slice of portfolio IDs to retrieve history for
+//   - interval: Daily, weekly or monthly points
 //   - startDate: First date to include in results (inclusive)
 //   - endDate: Last date to include in results (inclusive)
-//   - callback: Function called for each aggregated record, receives the record and should return error if processing fails
+//   - callback: Function called for each record, receives the record and should return error if processing fails
 //
 // The callback pattern allows the caller to process records one at a time without loading
 // the entire result set into memory, which is efficient for large date ranges.
@@ -67,129 +67,86 @@ func (r *MaterializedRepository) getQuerier() Querier {
 // Returns an error if the query fails, date parsing fails, or if the callback returns an error during processing.
 func (r *MaterializedRepository) GetMaterializedHistory(
 	portfolioIDs []string,
+	interval model.HistoryInterval,
 	startDate, endDate time.Time,
 	callback func(record model.PortfolioHistoryMaterialized) error,
 ) error {
-	matLog.Debug("getting materialized history", "portfolio_count", len(portfolioIDs), "start_date", startDate.Format("2006-01-02"), "end_date", endDate.Format("2006-01-02"))
+	matLog.Debug("getting materialized history", "portfolio_count", len(portfolioIDs), "interval", interval, "start_date", startDate.Format("2006-01-02"), "end_date", endDate.Format("2006-01-02"))
 
 	if len(portfolioIDs) == 0 {
 		return nil
 	}
 
-	query, args := r.buildMaterializedQuery(portfolioIDs, startDate, endDate)
+	query, args := r.buildMaterializedQuery(portfolioIDs, interval, startDate, endDate)
 
 	rows, err := r.getQuerier().Query(query, args...)
 	if err != nil {
-		return fmt.Errorf("failed to query fund_history_materialized: %w", err)
+		return fmt.Errorf("failed to query portfolio history: %w", err)
 	}
-
 	defer rows.Close()
 
-	for rows.Next() {
-		var record model.PortfolioHistoryMaterialized
-		var dateStr, calculatedAtStr string
-
-		err := rows.Scan(
-			&record.ID,
-			&record.PortfolioID,
-			&dateStr,
-			&record.Value,
-			&record.Cost,
-			&record.RealizedGain,
-			&record.UnrealizedGain,
-			&record.TotalDividends,
-			&record.TotalSaleProceeds,
-			&record.TotalOriginalCost,
-			&record.TotalGainLoss,
-			&record.IsArchived,
-			&calculatedAtStr,
-		)
-		if err != nil {
-			return fmt.Errorf("failed to scan row: %w", err)
-		}
-
-		record.Date, err = ParseTime(dateStr)
-		if err != nil {
-			return fmt.Errorf("failed to parse date: %w", err)
-		}
-
-		record.CalculatedAt, err = ParseTime(calculatedAtStr)
-		if err != nil {
-			return fmt.Errorf("failed to parse calculated_at: %w", err)
-		}
-
-		if err := callback(record); err != nil {
-			return err
-		}
-	}
-
-	if err = rows.Err(); err != nil {
-		return fmt.Errorf("error iterating rows: %w", err)
-	}
-	return nil
+	return scanPortfolioHistory(rows, callback)
 }
 
-// buildMaterializedQuery constructs the SQL query and argument list for fetching portfolio history
-// from the materialized view. This method was extracted from GetMaterializedHistory to reduce
-// function complexity and improve readability.
-//
-// The query performs portfolio-level aggregation by:
-//   - Joining fund_history_materialized with portfolio_fund and portfolio tables
-//   - Grouping by date and portfolio_id to sum fund-level metrics
-//   - Summing pre-computed fund-level metrics (realized gains, dividends, sale proceeds, original cost)
-//   - Filtering by portfolio IDs and date range
-//
-// Parameters:
-//   - portfolioIDs: Slice of portfolio UUIDs to include in the query
-//   - startDate: Inclusive start date for the history range
-//   - endDate: Inclusive end date for the history range
-//
-// Returns:
-//   - SQL query string with placeholders for portfolioIDs and dates
-//   - Argument slice containing portfolio IDs followed by formatted start and end dates
+// buildMaterializedQuery constructs the SQL query and argument list for fetching portfolio
+// history at the given interval, filtered by portfolio IDs and date range.
 //
 // Security Note: The #nosec G202 directive is used because placeholder concatenation
 // is safe here - we're building "?" placeholders programmatically, not concatenating user input.
-func (r *MaterializedRepository) buildMaterializedQuery(portfolioIDs []string, startDate, endDate time.Time) (string, []any) {
-
-	placeholders := make([]string, len(portfolioIDs))
-	for i := range placeholders {
-		placeholders[i] = "?"
+func (r *MaterializedRepository) buildMaterializedQuery(portfolioIDs []string, interval model.HistoryInterval, startDate, endDate time.Time) (string, []any) {
+	start, end := startDate.Format("2006-01-02"), endDate.Format("2006-01-02")
+	in := placeholders(len(portfolioIDs))
+	ids := make([]any, len(portfolioIDs))
+	for i, id := range portfolioIDs {
+		ids[i] = id
+	}
+
+	if interval != model.HistoryIntervalWeek && interval != model.HistoryIntervalMonth {
+		//#nosec G202 -- Safe: placeholders are generated programmatically, not from user input
+		query := `
+	SELECT ` + portfolioHistoryColumns + `
+	FROM portfolio_history_materialized s
+	JOIN portfolio p ON s.portfolio_id = p.id
+	WHERE s.portfolio_id IN (` + in + `)
+	AND s.date >= ?
+	AND s.date <= ?
+	ORDER BY s.date ASC
+`
+		return query, append(ids, start, end)
 	}
 
+	// A range ending mid-period has no rollup row for its last period yet (the row is
+	// dated later), so the last daily snapshot on or before endDate closes the series.
 	//#nosec G202 -- Safe: placeholders are generated programmatically, not from user input
 	query := `
-	SELECT
-		'' as id,
-		pf.portfolio_id,
-		fh.date,
-		SUM(fh.value) as value,
-		SUM(fh.cost) as cost,
-		SUM(fh.realized_gain) as realized_gain,
-		SUM(fh.unrealized_gain) as unrealized_gain,
-		SUM(fh.dividends) as total_dividends,
-		SUM(fh.sale_proceeds) as total_sale_proceeds,
-		SUM(fh.original_cost) as total_original_cost,
-		SUM(fh.unrealized_gain) + SUM(fh.realized_gain) as total_gain_loss,
-		p.is_archived,
-		MAX(fh.calculated_at) as calculated_at
-	FROM fund_history_materialized fh
-	JOIN portfolio_fund pf ON fh.portfolio_fund_id = pf.id
-	JOIN portfolio p ON pf.portfolio_id = p.id
-	WHERE pf.portfolio_id IN (` + strings.Join(placeholders, ",") + `)
-	AND fh.date >= ?
-	AND fh.date <= ?
-	GROUP BY fh.date, pf.portfolio_id, p.is_archived
-	ORDER BY fh.date ASC
+	SELECT ` + portfolioHistoryColumns + `
+	FROM portfolio_history_rollup s
+	JOIN portfolio p ON s.portfolio_id = p.id
+	WHERE s.portfolio_id IN (` + in + `)
+	AND s.period = ?
+	AND s.date >= ?
+	AND s.date <= ?
+	UNION ALL
+	SELECT ` + portfolioHistoryColumns + `
+	FROM portfolio_history_materialized s
+	JOIN portfolio p ON s.portfolio_id = p.id
+	WHERE s.portfolio_id IN (` + in + `)
+	AND s.date >= ?
+	AND s.date = (
+		SELECT MAX(s2.date) FROM portfolio_history_materialized s2
+		WHERE s2.portfolio_id = s.portfolio_id AND s2.date <= ?
+	)
+	AND NOT EXISTS (
+		SELECT 1 FROM portfolio_history_rollup r
+		WHERE r.portfolio_id = s.portfolio_id AND r.period = ? AND r.date = s.date
+	)
+	ORDER BY 2 ASC
 `
-
-	args := make([]any, 0, len(portfolioIDs)+2)
-	for _, id := range portfolioIDs {
-		args = append(args, id)
-	}
-	args = append(args, startDate.Format("2006-01-02"))
-	args = append(args, endDate.Format("2006-01-02"))
-
+	args := make([]any, 0, 2*len(ids)+6)
+	args = append(args, ids...)
+	args = append(args, string(interval), start, end)
+	args = append(args, ids...)
+	args = append(args, start, end, string(interval))
 	return query, args
 }
 
@@ -344,6 +301,66 @@ func (r *MaterializedRepository) GetLatestMaterializedDate(portfolioIDs []string
 	return latestDate, latestCalc, true, nil
 }
 
+// GetMaterializedCoverage returns, per portfolio, the first and last materialized date,
+// the latest calculated_at and the number of fund rows and portfolio funds behind them.
+// Portfolios with nothing materialized are absent from the map; the caller fills in
+// PortfolioName and the staleness fields.
+func (r *MaterializedRepository) GetMaterializedCoverage(portfolioIDs []string) (map[string]model.MaterializedCoverage, error) {
+	matLog.Debug("getting materialized coverage", "portfolio_count", len(portfolioIDs))
+	coverage := make(map[string]model.MaterializedCoverage, len(portfolioIDs))
+	if len(portfolioIDs) == 0 {
+		return coverage, nil
+	}
+
+	args := make([]any, len(portfolioIDs))
+	for i, id := range portfolioIDs {
+		args[i] = id
+	}
+
+	//#nosec G202 -- Safe: placeholders are generated programmatically, not from user input
+	rows, err := r.getQuerier().Query(`
+		SELECT pf.portfolio_id, MIN(fhm.date), MAX(fhm.date), MAX(fhm.calculated_at),
+			COUNT(*), COUNT(DISTINCT fhm.portfolio_fund_id)
+		FROM fund_history_materialized fhm
+		JOIN portfolio_fund pf ON fhm.portfolio_fund_id = pf.id
+		WHERE pf.portfolio_id IN (`+placeholders(len(portfolioIDs))+`)
+		GROUP BY pf.portfolio_id
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query materialized coverage: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var c model.MaterializedCoverage
+		// Aggregates lose column type information, so parse the dates manually.
+		var firstStr, lastStr, calcStr string
+		if err := rows.Scan(&c.PortfolioID, &firstStr, &lastStr, &calcStr, &c.Rows, &c.Funds); err != nil {
+			return nil, fmt.Errorf("failed to scan materialized coverage: %w", err)
+		}
+
+		first, err := ParseTime(firstStr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse first date: %w", err)
+		}
+		last, err := ParseTime(lastStr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse last date: %w", err)
+		}
+		calc, err := ParseTime(calcStr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse calculated_at: %w", err)
+		}
+		c.FirstDate, c.LastDate, c.CalculatedAt = &first, &last, &calc
+		coverage[c.PortfolioID] = c
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating materialized coverage: %w", err)
+	}
+	return coverage, nil
+}
+
 // GetLatestSourceDates returns the most recent modification timestamps from the three
 // source tables (transaction, fund_price, dividend) for the given portfolio IDs in a
 // single query. fund_price uses MAX(date) since it has no created_at column.
@@ -407,6 +424,52 @@ func (r *MaterializedRepository) GetLatestSourceDates(portfolioIDs []string) (la
 	return latestTxn, latestPrice, latestDiv, nil
 }
 
+// GetMaterializedEntriesForDate retrieves the materialized rows of the given portfolio
+// funds on a single date, keyed by portfolio_fund_id. Funds without a row on that date
+// are absent from the map. FundName is not populated.
+func (r *MaterializedRepository) GetMaterializedEntriesForDate(pfIDs []string, date time.Time) (map[string]model.FundHistoryEntry, error) {
+	matLog.Debug("getting materialized entries for date", "date", date.Format("2006-01-02"), "pf_count", len(pfIDs))
+	entries := make(map[string]model.FundHistoryEntry, len(pfIDs))
+	if len(pfIDs) == 0 {
+		return entries, nil
+	}
+
+	args := make([]any, 0, len(pfIDs)+1)
+	args = append(args, date.Format("2006-01-02"))
+	for _, id := range pfIDs {
+		args = append(args, id)
+	}
+
+	rows, err := r.getQuerier().Query(`
+		SELECT id, portfolio_fund_id, fund_id, date, shares, price, value, cost, realized_gain,
+			unrealized_gain, total_gain_loss, dividends, fees, sale_proceeds, original_cost
+		FROM fund_history_materialized
+		WHERE date = ? AND portfolio_fund_id IN (`+placeholders(len(pfIDs))+`)
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query fund_history_materialized: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var e model.FundHistoryEntry
+		var dateStr string
+		if err := rows.Scan(&e.ID, &e.PortfolioFundID, &e.FundID, &dateStr, &e.Shares, &e.Price, &e.Value,
+			&e.Cost, &e.RealizedGain, &e.UnrealizedGain, &e.TotalGainLoss, &e.Dividends, &e.Fees,
+			&e.SaleProceeds, &e.OriginalCost); err != nil {
+			return nil, fmt.Errorf("failed to scan fund_history_materialized results: %w", err)
+		}
+		if e.Date, err = ParseTime(dateStr); err != nil {
+			return nil, fmt.Errorf("failed to parse date: %w", err)
+		}
+		entries[e.PortfolioFundID] = e
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating fund_history_materialized: %w", err)
+	}
+	return entries, nil
+}
+
 // InvalidateMaterializedTable deletes cached entries from the given date forward,
 // scoped to the specified portfolio_fund IDs. If pfIDs is empty, no rows are deleted.
 func (r *MaterializedRepository) InvalidateMaterializedTable(ctx context.Context, date time.Time, pfIDs []string) error {
@@ -482,9 +545,9 @@ func (r *MaterializedRepository) InsertMaterializedEntries(ctx context.Context,
 
 }
 
-// GetPortfolioSummaryLatest retrieves aggregated portfolio metrics for the most recent date only.
-// This is used by summary/detail endpoints that only need the current state, avoiding a full
-// date-range scan of the materialized table.
+// GetPortfolioSummaryLatest retrieves portfolio metrics for the most recent snapshot date only.
+// This is used by summary/detail endpoints that only need the current state, avoiding a
+// date-range scan of the snapshot table.
 func (r *MaterializedRepository) GetPortfolioSummaryLatest(
 	portfolioIDs []string,
 	callback func(record model.PortfolioHistoryMaterialized) error,
@@ -494,59 +557,39 @@ func (r *MaterializedRepository) GetPortfolioSummaryLatest(
 		return nil
 	}
 
-	placeholders := make([]string, len(portfolioIDs))
-	for i := range placeholders {
-		placeholders[i] = "?"
-	}
-	inClause := strings.Join(placeholders, ",")
-
-	//#nosec G202 -- Safe: placeholders are generated programmatically, not from user input
-	query := `
-	SELECT
-		'' as id,
-		pf.portfolio_id,
-		fh.date,
-		SUM(fh.value) as value,
-		SUM(fh.cost) as cost,
-		SUM(fh.realized_gain) as realized_gain,
-		SUM(fh.unrealized_gain) as unrealized_gain,
-		SUM(fh.dividends) as total_dividends,
-		SUM(fh.sale_proceeds) as total_sale_proceeds,
-		SUM(fh.original_cost) as total_original_cost,
-		SUM(fh.unrealized_gain) + SUM(fh.realized_gain) as total_gain_loss,
-		p.is_archived,
-		MAX(fh.calculated_at) as calculated_at
-	FROM fund_history_materialized fh
-	JOIN portfolio_fund pf ON fh.portfolio_fund_id = pf.id
-	JOIN portfolio p ON pf.portfolio_id = p.id
-	WHERE pf.portfolio_id IN (` + inClause + `)
-	AND fh.date = (
-		SELECT MAX(fh2.date)
-		FROM fund_history_materialized fh2
-		JOIN portfolio_fund pf2 ON fh2.portfolio_fund_id = pf2.id
-		WHERE pf2.portfolio_id = pf.portfolio_id
-	)
-	GROUP BY fh.date, pf.portfolio_id, p.is_archived
-	ORDER BY fh.date ASC
-`
-
 	args := make([]any, 0, len(portfolioIDs))
 	for _, id := range portfolioIDs {
 		args = append(args, id)
 	}
 
-	rows, err := r.getQuerier().Query(query, args...)
+	//#nosec G202 -- Safe: placeholders are generated programmatically, not from user input
+	rows, err := r.getQuerier().Query(`
+	SELECT `+portfolioHistoryColumns+`
+	FROM portfolio_history_materialized s
+	JOIN portfolio p ON s.portfolio_id = p.id
+	WHERE s.portfolio_id IN (`+placeholders(len(portfolioIDs))+`)
+	AND s.date = (
+		SELECT MAX(s2.date)
+		FROM portfolio_history_materialized s2
+		WHERE s2.portfolio_id = s.portfolio_id
+	)
+	ORDER BY s.date ASC
+`, args...)
 	if err != nil {
 		return fmt.Errorf("failed to query portfolio summary latest: %w", err)
 	}
 	defer rows.Close()
 
+	return scanPortfolioHistory(rows, callback)
+}
+
+// scanPortfolioHistory scans portfolioHistoryColumns rows and passes each record to callback.
+func scanPortfolioHistory(rows *sql.Rows, callback func(record model.PortfolioHistoryMaterialized) error) error {
 	for rows.Next() {
 		var record model.PortfolioHistoryMaterialized
 		var dateStr, calculatedAtStr string
 
 		err := rows.Scan(
-			&record.ID,
 			&record.PortfolioID,
 			&dateStr,
 			&record.Value,
@@ -579,8 +622,234 @@ func (r *MaterializedRepository) GetPortfolioSummaryLatest(
 		}
 	}
 
-	if err = rows.Err(); err != nil {
+	if err := rows.Err(); err != nil {
 		return fmt.Errorf("error iterating rows: %w", err)
 	}
 	return nil
 }
+
+// RefreshPortfolioSnapshots rebuilds the portfolio-level snapshots of the given portfolios
+// from fund_history_materialized, from the given date forward: the daily totals, and the
+// weekly and monthly rollups of every period overlapping that range. Call it in the same
+// transaction that rewrote the fund rows.
+func (r *MaterializedRepository) RefreshPortfolioSnapshots(ctx context.Context, portfolioIDs []string, from time.Time) error {
+	matLog.DebugContext(ctx, "refreshing portfolio snapshots", "portfolio_count", len(portfolioIDs), "from_date", from.Format("2006-01-02"))
+	if len(portfolioIDs) == 0 {
+		return nil
+	}
+
+	in := placeholders(len(portfolioIDs))
+	withDate := func(date time.Time, extra ...any) []any {
+		args := make([]any, 0, len(portfolioIDs)+1+len(extra))
+		args = append(args, extra...)
+		for _, id := range portfolioIDs {
+			args = append(args, id)
+		}
+		return append(args, date.Format("2006-01-02"))
+	}
+
+	//#nosec G202 -- Safe: placeholders are generated programmatically, not from user input
+	if _, err := r.getQuerier().ExecContext(ctx, `
+		DELETE FROM portfolio_history_materialized
+		WHERE portfolio_id IN (`+in+`) AND date >= ?
+	`, withDate(from)...); err != nil {
+		return fmt.Errorf("failed to delete portfolio snapshots: %w", err)
+	}
+
+	//#nosec G202 -- Safe: placeholders are generated programmatically, not from user input
+	if _, err := r.getQuerier().ExecContext(ctx, `
+		INSERT INTO portfolio_history_materialized (portfolio_id, date, value, cost, realized_gain, unrealized_gain,
+			total_gain_loss, total_dividends, total_sale_proceeds, total_original_cost, calculated_at)
+		SELECT pf.portfolio_id, fh.date, SUM(fh.value), SUM(fh.cost), SUM(fh.realized_gain), SUM(fh.unrealized_gain),
+			SUM(fh.unrealized_gain) + SUM(fh.realized_gain), SUM(fh.dividends), SUM(fh.sale_proceeds),
+			SUM(fh.original_cost), MAX(fh.calculated_at)
+		FROM fund_history_materialized fh
+		JOIN portfolio_fund pf ON fh.portfolio_fund_id = pf.id
+		WHERE pf.portfolio_id IN (`+in+`) AND fh.date >= ?
+		GROUP BY pf.portfolio_id, fh.date
+	`, withDate(from)...); err != nil {
+		return fmt.Errorf("failed to insert portfolio snapshots: %w", err)
+	}
+
+	for _, rollup := range []struct {
+		interval    model.HistoryInterval
+		periodStart string
+	}{
+		{model.HistoryIntervalWeek, `date(date, 'weekday 0', '-6 days')`},
+		{model.HistoryIntervalMonth, `strftime('%Y-%m-01', date)`},
+	} {
+		periodFrom := rollup.interval.PeriodStart(from)
+
+		//#nosec G202 -- Safe: placeholders are generated programmatically, not from user input
+		if _, err := r.getQuerier().ExecContext(ctx, `
+			DELETE FROM portfolio_history_rollup
+			WHERE period = ? AND portfolio_id IN (`+in+`) AND period_start >= ?
+		`, withDate(periodFrom, string(rollup.interval))...); err != nil {
+			return fmt.Errorf("failed to delete %s rollups: %w", rollup.interval, err)
+		}
+
+		//#nosec G202 -- Safe: placeholders and period expressions are not from user input
+		if _, err := r.getQuerier().ExecContext(ctx, `
+			INSERT INTO portfolio_history_rollup (portfolio_id, period, period_start, date, value, cost, realized_gain,
+				unrealized_gain, total_gain_loss, total_dividends, total_sale_proceeds, total_original_cost, calculated_at)
+			SELECT s.portfolio_id, ?, p.period_start, s.date, s.value, s.cost, s.realized_gain, s.unrealized_gain,
+				s.total_gain_loss, s.total_dividends, s.total_sale_proceeds, s.total_original_cost, s.calculated_at
+			FROM portfolio_history_materialized s
+			JOIN (
+				SELECT portfolio_id, `+rollup.periodStart+` AS period_start, MAX(date) AS date
+				FROM portfolio_history_materialized
+				WHERE portfolio_id IN (`+in+`) AND date >= ?
+				GROUP BY portfolio_id, period_start
+			) p ON p.portfolio_id = s.portfolio_id AND p.date = s.date
+		`, withDate(periodFrom, string(rollup.interval))...); err != nil {
+			return fmt.Errorf("failed to insert %s rollups: %w", rollup.interval, err)
+		}
+	}
+
+	return nil
+}
+
+// regenQueueColumns is the column list shared by every materialized_regen_queue SELECT.
+const regenQueueColumns = `q.portfolio_id, p.name, q.portfolio_fund_id, COALESCE(f.name, ''), q.start_date, q.version, q.attempts, q.last_error, q.requested_at, q.next_attempt_at`
+
+// regenQueueFrom is the FROM clause shared by every materialized_regen_queue SELECT.
+const regenQueueFrom = `
+		FROM materialized_regen_queue q
+		JOIN portfolio p ON p.id = q.portfolio_id
+		LEFT JOIN portfolio_fund pf ON pf.id = q.portfolio_fund_id
+		LEFT JOIN fund f ON f.id = pf.fund_id`
+
+// EnqueueRegen records that materialized history for the given targets is dirty from
+// startDate onward. A target that is already queued keeps the earlier of the two dates
+// and its retry schedule; its version is bumped so a regeneration running concurrently
+// does not remove the entry (see CompleteRegen).
+func (r *MaterializedRepository) EnqueueRegen(ctx context.Context, targets []model.RegenTarget, startDate, at time.Time) error {
+	matLog.DebugContext(ctx, "enqueueing materialized regeneration", "target_count", len(targets), "from_date", startDate.Format("2006-01-02"))
+	now := at.UTC().Format("2006-01-02 15:04:05")
+	for _, t := range targets {
+		_, err := r.getQuerier().ExecContext(ctx, `
+			INSERT INTO materialized_regen_queue (portfolio_id, portfolio_fund_id, start_date, requested_at, next_attempt_at)
+			VALUES (?, ?, ?, ?, ?)
+			ON CONFLICT(portfolio_id, portfolio_fund_id) DO UPDATE SET
+				start_date = MIN(start_date, excluded.start_date),
+				version = version + 1,
+				requested_at = excluded.requested_at
+		`, t.PortfolioID, t.PortfolioFundID, startDate.Format("2006-01-02"), now, now)
+		if err != nil {
+			return fmt.Errorf("failed to enqueue regeneration for portfolio %s: %w", t.PortfolioID, err)
+		}
+	}
+	return nil
+}
+
+// GetRegenQueue retrieves all pending regenerations, next due first.
+func (r *MaterializedRepository) GetRegenQueue() ([]model.RegenQueueEntry, error) {
+	rows, err := r.getQuerier().Query(`
+		SELECT ` + regenQueueColumns + regenQueueFrom + `
+		ORDER BY q.next_attempt_at, q.start_date
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query regeneration queue: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []model.RegenQueueEntry{}
+	for rows.Next() {
+		e, err := scanRegenQueueEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating regeneration queue: %w", err)
+	}
+	return entries, nil
+}
+
+// GetDueRegen returns the pending regeneration that is due soonest at or before the given
+// time. ok is false if nothing is due.
+func (r *MaterializedRepository) GetDueRegen(at time.Time) (entry model.RegenQueueEntry, ok bool, err error) {
+	row := r.getQuerier().QueryRow(`
+		SELECT `+regenQueueColumns+regenQueueFrom+`
+		WHERE q.next_attempt_at <= ?
+		ORDER BY q.next_attempt_at, q.start_date
+		LIMIT 1
+	`, at.UTC().Format("2006-01-02 15:04:05"))
+
+	entry, err = scanRegenQueueEntry(row)
+	if err == sql.ErrNoRows {
+		return model.RegenQueueEntry{}, false, nil
+	}
+	if err != nil {
+		return model.RegenQueueEntry{}, false, err
+	}
+	return entry, true, nil
+}
+
+// CountRegenQueue returns the number of pending regenerations.
+func (r *MaterializedRepository) CountRegenQueue() (int, error) {
+	var n int
+	if err := r.getQuerier().QueryRow(`SELECT COUNT(*) FROM materialized_regen_queue`).Scan(&n); err != nil {
+		return 0, fmt.Errorf("failed to count regeneration queue: %w", err)
+	}
+	return n, nil
+}
+
+// CompleteRegen removes a drained queue entry, but only if it is still at the given version.
+// Returns false if the target was re-enqueued while it was being regenerated; the entry
+// then stays due and is picked up again.
+func (r *MaterializedRepository) CompleteRegen(ctx context.Context, target model.RegenTarget, version int) (bool, error) {
+	result, err := r.getQuerier().ExecContext(ctx, `
+		DELETE FROM materialized_regen_queue
+		WHERE portfolio_id = ? AND portfolio_fund_id = ? AND version = ?
+	`, target.PortfolioID, target.PortfolioFundID, version)
+	if err != nil {
+		return false, fmt.Errorf("failed to complete regeneration: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return n > 0, nil
+}
+
+// FailRegen records a failed regeneration attempt and schedules the next one.
+func (r *MaterializedRepository) FailRegen(ctx context.Context, target model.RegenTarget, errMsg string, nextAttempt time.Time) error {
+	_, err := r.getQuerier().ExecContext(ctx, `
+		UPDATE materialized_regen_queue
+		SET attempts = attempts + 1, last_error = ?, next_attempt_at = ?
+		WHERE portfolio_id = ? AND portfolio_fund_id = ?
+	`, errMsg, nextAttempt.UTC().Format("2006-01-02 15:04:05"), target.PortfolioID, target.PortfolioFundID)
+	if err != nil {
+		return fmt.Errorf("failed to record regeneration failure: %w", err)
+	}
+	return nil
+}
+
+func scanRegenQueueEntry(s scanner) (model.RegenQueueEntry, error) {
+	var e model.RegenQueueEntry
+	var startDateStr, requestedAtStr, nextAttemptStr string
+	var lastError sql.NullString
+
+	if err := s.Scan(&e.PortfolioID, &e.PortfolioName, &e.PortfolioFundID, &e.FundName, &startDateStr, &e.Version, &e.Attempts,
+		&lastError, &requestedAtStr, &nextAttemptStr); err != nil {
+		if err == sql.ErrNoRows {
+			return model.RegenQueueEntry{}, err
+		}
+		return model.RegenQueueEntry{}, fmt.Errorf("failed to scan regeneration queue entry: %w", err)
+	}
+	e.LastError = lastError.String
+
+	var err error
+	if e.StartDate, err = ParseTime(startDateStr); err != nil {
+		return model.RegenQueueEntry{}, fmt.Errorf("failed to parse start_date: %w", err)
+	}
+	if e.RequestedAt, err = ParseTime(requestedAtStr); err != nil {
+		return model.RegenQueueEntry{}, fmt.Errorf("failed to parse requested_at: %w", err)
+	}
+	if e.NextAttemptAt, err = ParseTime(nextAttemptStr); err != nil {
+		return model.RegenQueueEntry{}, fmt.Errorf("failed to parse next_attempt_at: %w", err)
+	}
+	return e, nil
+}
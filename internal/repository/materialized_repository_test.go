@@ -3,6 +3,7 @@ package repository_test
 import (
 	"context"
 	"errors"
+	"slices"
 	"testing"
 	"time"
 
@@ -185,7 +186,7 @@ func TestMaterializedRepository_GetMaterializedHistory(t *testing.T) {
 		repo := repository.NewMaterializedRepository(db)
 
 		called := false
-		err := repo.GetMaterializedHistory(nil, time.Now(), time.Now(), func(_ model.PortfolioHistoryMaterialized) error {
+		err := repo.GetMaterializedHistory(nil, model.HistoryIntervalDay, time.Now(), time.Now(), func(_ model.PortfolioHistoryMaterialized) error {
 			called = true
 			return nil
 		})
@@ -229,10 +230,14 @@ func TestMaterializedRepository_GetMaterializedHistory(t *testing.T) {
 		if err := repo.InsertMaterializedEntries(ctx, entries); err != nil {
 			t.Fatalf("InsertMaterializedEntries: %v", err)
 		}
+		if err := repo.RefreshPortfolioSnapshots(ctx, []string{portfolio.ID}, date); err != nil {
+			t.Fatalf("RefreshPortfolioSnapshots: %v", err)
+		}
 
 		var records []model.PortfolioHistoryMaterialized
 		err := repo.GetMaterializedHistory(
 			[]string{portfolio.ID},
+			model.HistoryIntervalDay,
 			date,
 			date,
 			func(record model.PortfolioHistoryMaterialized) error {
@@ -280,10 +285,14 @@ func TestMaterializedRepository_GetMaterializedHistory(t *testing.T) {
 		if err := repo.InsertMaterializedEntries(ctx, entries); err != nil {
 			t.Fatalf("InsertMaterializedEntries: %v", err)
 		}
+		if err := repo.RefreshPortfolioSnapshots(ctx, []string{portfolio.ID}, base); err != nil {
+			t.Fatalf("RefreshPortfolioSnapshots: %v", err)
+		}
 
 		var count int
 		err := repo.GetMaterializedHistory(
 			[]string{portfolio.ID},
+			model.HistoryIntervalDay,
 			base.AddDate(0, 0, 1), // March 11
 			base.AddDate(0, 0, 3), // March 13
 			func(_ model.PortfolioHistoryMaterialized) error {
@@ -298,6 +307,74 @@ func TestMaterializedRepository_GetMaterializedHistory(t *testing.T) {
 			t.Errorf("expected 3 records in date range, got %d", count)
 		}
 	})
+
+	t.Run("weekly and monthly rollups keep the last day of each period", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		repo := repository.NewMaterializedRepository(db)
+		ctx := context.Background()
+
+		portfolio := testutil.NewPortfolio().Build(t, db)
+		fund := testutil.NewFund().Build(t, db)
+		pf := testutil.NewPortfolioFund(portfolio.ID, fund.ID).Build(t, db)
+
+		// Wednesday 2026-01-28 through Tuesday 2026-02-10: three ISO weeks, two months.
+		base := time.Date(2026, 1, 28, 0, 0, 0, 0, time.UTC)
+		entries := make([]model.FundHistoryEntry, 0, 14)
+		for i := range 14 {
+			entries = append(entries, model.FundHistoryEntry{
+				ID:              testutil.MakeID(),
+				PortfolioFundID: pf.ID,
+				FundID:          fund.ID,
+				Date:            base.AddDate(0, 0, i),
+				Shares:          100,
+				Price:           float64(10 + i),
+				Value:           float64(100 * (10 + i)),
+				Cost:            900,
+			})
+		}
+		if err := repo.InsertMaterializedEntries(ctx, entries); err != nil {
+			t.Fatalf("InsertMaterializedEntries: %v", err)
+		}
+		if err := repo.RefreshPortfolioSnapshots(ctx, []string{portfolio.ID}, base); err != nil {
+			t.Fatalf("RefreshPortfolioSnapshots: %v", err)
+		}
+
+		read := func(interval model.HistoryInterval) []string {
+			t.Helper()
+			var dates []string
+			err := repo.GetMaterializedHistory([]string{portfolio.ID}, interval, base, base.AddDate(0, 0, 13),
+				func(record model.PortfolioHistoryMaterialized) error {
+					dates = append(dates, record.Date.Format("2006-01-02"))
+					return nil
+				})
+			if err != nil {
+				t.Fatalf("GetMaterializedHistory(%s): %v", interval, err)
+			}
+			return dates
+		}
+
+		if got, want := read(model.HistoryIntervalWeek), []string{"2026-02-01", "2026-02-08", "2026-02-10"}; !slices.Equal(got, want) {
+			t.Errorf("weekly dates = %v, want %v", got, want)
+		}
+		if got, want := read(model.HistoryIntervalMonth), []string{"2026-01-31", "2026-02-10"}; !slices.Equal(got, want) {
+			t.Errorf("monthly dates = %v, want %v", got, want)
+		}
+
+		// A partial refresh from mid-week rebuilds that week's rollup from its
+		// earlier days and leaves the earlier periods in place.
+		if err := repo.InvalidateMaterializedTable(ctx, base.AddDate(0, 0, 13), []string{pf.ID}); err != nil {
+			t.Fatalf("InvalidateMaterializedTable: %v", err)
+		}
+		if err := repo.RefreshPortfolioSnapshots(ctx, []string{portfolio.ID}, base.AddDate(0, 0, 13)); err != nil {
+			t.Fatalf("RefreshPortfolioSnapshots: %v", err)
+		}
+		if got, want := read(model.HistoryIntervalWeek), []string{"2026-02-01", "2026-02-08", "2026-02-09"}; !slices.Equal(got, want) {
+			t.Errorf("weekly dates after partial refresh = %v, want %v", got, want)
+		}
+		if got, want := read(model.HistoryIntervalDay), 13; len(got) != want {
+			t.Errorf("expected %d daily snapshots after partial refresh, got %d", want, len(got))
+		}
+	})
 }
 
 // ---------------------------------------------------------------------------
@@ -556,6 +633,9 @@ func TestMaterializedRepository_GetPortfolioSummaryLatest(t *testing.T) {
 		if err := repo.InsertMaterializedEntries(ctx, entries); err != nil {
 			t.Fatalf("InsertMaterializedEntries: %v", err)
 		}
+		if err := repo.RefreshPortfolioSnapshots(ctx, []string{portfolio.ID}, date1); err != nil {
+			t.Fatalf("RefreshPortfolioSnapshots: %v", err)
+		}
 
 		var records []model.PortfolioHistoryMaterialized
 		err := repo.GetPortfolioSummaryLatest(
@@ -715,6 +795,9 @@ func TestMaterializedRepository_GetFundHistoryMaterialized(t *testing.T) {
 		if err := repo.InsertMaterializedEntries(ctx, entries); err != nil {
 			t.Fatalf("InsertMaterializedEntries: %v", err)
 		}
+		if err := repo.RefreshPortfolioSnapshots(ctx, []string{portfolio.ID}, base); err != nil {
+			t.Fatalf("RefreshPortfolioSnapshots: %v", err)
+		}
 
 		var count int
 		err := repo.GetFundHistoryMaterialized(
@@ -765,3 +848,198 @@ func TestMaterializedRepository_GetFundHistoryMaterialized(t *testing.T) {
 		}
 	})
 }
+
+// ---------------------------------------------------------------------------
+// Regeneration queue
+// ---------------------------------------------------------------------------
+
+//nolint:gocyclo // Test function with multiple subtests and assertions.
+func TestMaterializedRepository_RegenQueue(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2025, 3, d, 0, 0, 0, 0, time.UTC) }
+	now := time.Date(2025, 3, 20, 12, 0, 0, 0, time.UTC)
+
+	t.Run("re-enqueueing keeps the earliest date and bumps the version", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		repo := repository.NewMaterializedRepository(db)
+		ctx := context.Background()
+		portfolio := testutil.NewPortfolio().WithName("Queued").Build(t, db)
+
+		for _, d := range []int{10, 5, 12} {
+			if err := repo.EnqueueRegen(ctx, []model.RegenTarget{{PortfolioID: portfolio.ID}}, day(d), now); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+
+		entries, err := repo.GetRegenQueue()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(entries) != 1 {
+			t.Fatalf("expected 1 entry, got %d", len(entries))
+		}
+		e := entries[0]
+		if !e.StartDate.Equal(day(5)) || e.Version != 3 || e.PortfolioName != "Queued" {
+			t.Errorf("expected start 2025-03-05 at version 3, got %+v", e)
+		}
+	})
+
+	t.Run("portfolio fund entries are queued separately", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		repo := repository.NewMaterializedRepository(db)
+		ctx := context.Background()
+		portfolio := testutil.NewPortfolio().Build(t, db)
+		fund := testutil.NewFund().WithName("Queued Fund").Build(t, db)
+		pf := testutil.NewPortfolioFund(portfolio.ID, fund.ID).Build(t, db)
+
+		fundTarget := model.RegenTarget{PortfolioID: portfolio.ID, PortfolioFundID: pf.ID}
+		_ = repo.EnqueueRegen(ctx, []model.RegenTarget{fundTarget}, day(10), now)
+		_ = repo.EnqueueRegen(ctx, []model.RegenTarget{fundTarget}, day(7), now)
+		_ = repo.EnqueueRegen(ctx, []model.RegenTarget{{PortfolioID: portfolio.ID}}, day(12), now)
+
+		entries, err := repo.GetRegenQueue()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(entries) != 2 {
+			t.Fatalf("expected 2 entries, got %+v", entries)
+		}
+		for _, e := range entries {
+			switch e.PortfolioFundID {
+			case pf.ID:
+				if !e.StartDate.Equal(day(7)) || e.FundName != "Queued Fund" {
+					t.Errorf("unexpected portfolio fund entry: %+v", e)
+				}
+			case "":
+				if !e.StartDate.Equal(day(12)) || e.FundName != "" {
+					t.Errorf("unexpected portfolio entry: %+v", e)
+				}
+			default:
+				t.Errorf("unexpected entry: %+v", e)
+			}
+		}
+	})
+
+	t.Run("complete only removes an unchanged entry", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		repo := repository.NewMaterializedRepository(db)
+		ctx := context.Background()
+		portfolio := testutil.NewPortfolio().Build(t, db)
+
+		_ = repo.EnqueueRegen(ctx, []model.RegenTarget{{PortfolioID: portfolio.ID}}, day(10), now)
+		entry, ok, err := repo.GetDueRegen(now)
+		if err != nil || !ok {
+			t.Fatalf("expected a due entry, got ok=%v err=%v", ok, err)
+		}
+
+		// Requested again while "regenerating".
+		_ = repo.EnqueueRegen(ctx, []model.RegenTarget{{PortfolioID: portfolio.ID}}, day(8), now)
+
+		done, err := repo.CompleteRegen(ctx, entry.Target(), entry.Version)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if done {
+			t.Error("expected stale version not to be removed")
+		}
+
+		entry, ok, _ = repo.GetDueRegen(now)
+		if !ok || !entry.StartDate.Equal(day(8)) {
+			t.Fatalf("expected the follow-up entry from 2025-03-08 to be due, got ok=%v %+v", ok, entry)
+		}
+		done, _ = repo.CompleteRegen(ctx, entry.Target(), entry.Version)
+		if !done {
+			t.Error("expected current version to be removed")
+		}
+		if n, _ := repo.CountRegenQueue(); n != 0 {
+			t.Errorf("expected empty queue, got %d", n)
+		}
+	})
+
+	t.Run("failed entry is not due until its next attempt", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		repo := repository.NewMaterializedRepository(db)
+		ctx := context.Background()
+		portfolio := testutil.NewPortfolio().Build(t, db)
+
+		_ = repo.EnqueueRegen(ctx, []model.RegenTarget{{PortfolioID: portfolio.ID}}, day(10), now)
+		if err := repo.FailRegen(ctx, model.RegenTarget{PortfolioID: portfolio.ID}, "database is locked", now.Add(time.Minute)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if _, ok, _ := repo.GetDueRegen(now); ok {
+			t.Error("expected nothing due before the next attempt")
+		}
+
+		// A new request does not reset the backoff.
+		_ = repo.EnqueueRegen(ctx, []model.RegenTarget{{PortfolioID: portfolio.ID}}, day(9), now)
+
+		entry, ok, _ := repo.GetDueRegen(now.Add(time.Minute))
+		if !ok {
+			t.Fatal("expected entry due at its next attempt")
+		}
+		if entry.Attempts != 1 || entry.LastError != "database is locked" || !entry.StartDate.Equal(day(9)) {
+			t.Errorf("unexpected entry: %+v", entry)
+		}
+	})
+
+	t.Run("deleting the portfolio removes its entry", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		repo := repository.NewMaterializedRepository(db)
+		portfolio := testutil.NewPortfolio().Build(t, db)
+
+		_ = repo.EnqueueRegen(context.Background(), []model.RegenTarget{{PortfolioID: portfolio.ID}}, day(10), now)
+		if _, err := db.Exec(`DELETE FROM portfolio WHERE id = ?`, portfolio.ID); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if n, _ := repo.CountRegenQueue(); n != 0 {
+			t.Errorf("expected empty queue, got %d", n)
+		}
+	})
+}
+
+// ---------------------------------------------------------------------------
+// GetMaterializedCoverage
+// ---------------------------------------------------------------------------
+
+func TestMaterializedRepository_GetMaterializedCoverage(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	repo := repository.NewMaterializedRepository(db)
+	ctx := context.Background()
+
+	covered := testutil.NewPortfolio().Build(t, db)
+	empty := testutil.NewPortfolio().Build(t, db)
+	fund1 := testutil.NewFund().Build(t, db)
+	fund2 := testutil.NewFund().Build(t, db)
+	pf1 := testutil.NewPortfolioFund(covered.ID, fund1.ID).Build(t, db)
+	pf2 := testutil.NewPortfolioFund(covered.ID, fund2.ID).Build(t, db)
+
+	first := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+	entries := []model.FundHistoryEntry{
+		{ID: testutil.MakeID(), PortfolioFundID: pf1.ID, FundID: fund1.ID, Date: first, Shares: 1, Price: 1, Value: 1},
+		{ID: testutil.MakeID(), PortfolioFundID: pf1.ID, FundID: fund1.ID, Date: first.AddDate(0, 0, 1), Shares: 1, Price: 1, Value: 1},
+		{ID: testutil.MakeID(), PortfolioFundID: pf2.ID, FundID: fund2.ID, Date: first.AddDate(0, 0, 2), Shares: 1, Price: 1, Value: 1},
+	}
+	if err := repo.InsertMaterializedEntries(ctx, entries); err != nil {
+		t.Fatalf("InsertMaterializedEntries: %v", err)
+	}
+
+	coverage, err := repo.GetMaterializedCoverage([]string{covered.ID, empty.ID})
+	if err != nil {
+		t.Fatalf("GetMaterializedCoverage: %v", err)
+	}
+	if _, ok := coverage[empty.ID]; ok {
+		t.Error("expected no coverage for a portfolio without rows")
+	}
+
+	c, ok := coverage[covered.ID]
+	if !ok {
+		t.Fatal("expected coverage for the covered portfolio")
+	}
+	if !c.FirstDate.Equal(first) || !c.LastDate.Equal(first.AddDate(0, 0, 2)) {
+		t.Errorf("expected %s to %s, got %v to %v", first.Format("2006-01-02"), first.AddDate(0, 0, 2).Format("2006-01-02"), c.FirstDate, c.LastDate)
+	}
+	if c.Rows != 3 || c.Funds != 2 || c.CalculatedAt == nil || c.CalculatedAt.IsZero() {
+		t.Errorf("unexpected coverage: %+v", c)
+	}
+}
@@ -37,9 +37,9 @@ func (r *PortfolioFundRepository) WithTx(tx *sql.Tx) *PortfolioFundRepository {
 // getQuerier returns the active transaction if one is set, otherwise the database connection.
 func (r *PortfolioFundRepository) getQuerier() Querier {
 	if r.tx != nil {
-		return r.tx
+		return traced(r.tx)
 	}
-	return r.db
+	return traced(r.db)
 }
 
 // GetPortfolioFund retrieves a single portfolio_fund record by its ID.
@@ -110,10 +110,12 @@ func (r *PortfolioFundRepository) GetPortfolioFundListing(portfolioFundID string
 	return l, nil
 }
 
-// GetAllPortfolioFundListings retrieves all portfolio-fund relationships with enriched metadata.
-// Excludes archived portfolios. Used for the GET /api/portfolio/funds endpoint.
-func (r *PortfolioFundRepository) GetAllPortfolioFundListings() ([]model.PortfolioFundListing, error) {
+// GetAllPortfolioFundListings retrieves the portfolio-fund relationships of the portfolios in
+// scope with enriched metadata. Excludes archived portfolios. Used for the GET /api/portfolio/funds endpoint.
+func (r *PortfolioFundRepository) GetAllPortfolioFundListings(scope model.PortfolioScope) ([]model.PortfolioFundListing, error) {
 	pfLog.Debug("getting all portfolio fund listings")
+	condition, args := scopeCondition(scope, "p.id")
+	//#nosec G202 -- Safe: placeholders are generated programmatically, not from user input
 	query := `
 		SELECT
 			pf.id,
@@ -125,11 +127,11 @@ func (r *PortfolioFundRepository) GetAllPortfolioFundListings() ([]model.Portfol
 		FROM portfolio_fund pf
 		JOIN portfolio p ON pf.portfolio_id = p.id
 		JOIN fund f ON pf.fund_id = f.id
-		WHERE p.is_archived = 0
+		WHERE p.is_archived = 0 AND ` + condition + `
 		ORDER BY p.name ASC, f.name ASC
 	`
 
-	rows, err := r.getQuerier().Query(query)
+	rows, err := r.getQuerier().Query(query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query portfolio_fund listings: %w", err)
 	}
@@ -330,9 +332,9 @@ func (r *PortfolioFundRepository) GetPortfolioFundsOnPortfolioID(portfolios []mo
 	return fundsByPortfolio, portfolioFundToPortfolio, portfolioFundToFund, pfIDs, fundIDs, nil
 }
 
-// CheckUsage checks if a fund is in use by any portfolios and returns usage details.
+// CheckUsage checks if a fund is in use by any portfolios in scope and returns usage details.
 // Returns nil if the fund is not assigned to any portfolio.
-func (r *PortfolioFundRepository) CheckUsage(fundID string) ([]model.PortfolioTransaction, error) {
+func (r *PortfolioFundRepository) CheckUsage(fundID string, scope model.PortfolioScope) ([]model.PortfolioTransaction, error) {
 	pfLog.Debug("checking fund usage", "fund_id", fundID)
 	pfs, err := r.GetPortfolioFundsbyFundID(fundID)
 	if err != nil {
@@ -349,13 +351,16 @@ func (r *PortfolioFundRepository) CheckUsage(fundID string) ([]model.PortfolioTr
 		placeholders[i] = "?"
 	}
 
+	condition, scopeArgs := scopeCondition(scope, "pf.portfolio_id")
+	pfIDs = append(pfIDs, scopeArgs...)
+
 	//#nosec G202 -- Safe: placeholders are generated programmatically, not from user input
 	query := `
 		SELECT pf.portfolio_id, p.name, COALESCE(COUNT(t.id), 0) as transaction_count
         FROM portfolio_fund pf
         JOIN portfolio p ON p.id = pf.portfolio_id
         LEFT JOIN "transaction" t ON t.portfolio_fund_id = pf.id
-        WHERE pf.id IN (` + strings.Join(placeholders, ",") + `)
+        WHERE pf.id IN (` + strings.Join(placeholders, ",") + `) AND ` + condition + `
         GROUP BY pf.portfolio_id, p.name
 	`
 
@@ -90,7 +90,7 @@ func TestPortfolioFundRepository_GetAllPortfolioFundListings(t *testing.T) {
 	repo := repository.NewPortfolioFundRepository(db)
 
 	t.Run("returns empty slice when no data", func(t *testing.T) {
-		result, err := repo.GetAllPortfolioFundListings()
+		result, err := repo.GetAllPortfolioFundListings(model.AllPortfolios())
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -109,7 +109,7 @@ func TestPortfolioFundRepository_GetAllPortfolioFundListings(t *testing.T) {
 	testutil.NewPortfolioFund(archivedPortfolio.ID, fund1.ID).Build(t, db)
 
 	t.Run("excludes archived portfolios", func(t *testing.T) {
-		result, err := repo.GetAllPortfolioFundListings()
+		result, err := repo.GetAllPortfolioFundListings(model.AllPortfolios())
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -124,7 +124,7 @@ func TestPortfolioFundRepository_GetAllPortfolioFundListings(t *testing.T) {
 	})
 
 	t.Run("results are ordered by portfolio name then fund name", func(t *testing.T) {
-		result, err := repo.GetAllPortfolioFundListings()
+		result, err := repo.GetAllPortfolioFundListings(model.AllPortfolios())
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -363,7 +363,7 @@ func TestPortfolioFundRepository_CheckUsage(t *testing.T) {
 
 	t.Run("returns nil when fund is not assigned to any portfolio", func(t *testing.T) {
 		unusedFund := testutil.NewFund().Build(t, db)
-		result, err := repo.CheckUsage(unusedFund.ID)
+		result, err := repo.CheckUsage(unusedFund.ID, model.AllPortfolios())
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -377,7 +377,7 @@ func TestPortfolioFundRepository_CheckUsage(t *testing.T) {
 	pf := testutil.NewPortfolioFund(portfolio.ID, fund.ID).Build(t, db)
 
 	t.Run("returns usage with zero transactions", func(t *testing.T) {
-		result, err := repo.CheckUsage(fund.ID)
+		result, err := repo.CheckUsage(fund.ID, model.AllPortfolios())
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -397,7 +397,7 @@ func TestPortfolioFundRepository_CheckUsage(t *testing.T) {
 	testutil.NewTransaction(pf.ID).Build(t, db)
 
 	t.Run("returns correct transaction count", func(t *testing.T) {
-		result, err := repo.CheckUsage(fund.ID)
+		result, err := repo.CheckUsage(fund.ID, model.AllPortfolios())
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -35,22 +35,23 @@ func (r *PortfolioRepository) WithTx(tx *sql.Tx) *PortfolioRepository {
 // getQuerier returns the active transaction if one is set, otherwise the database connection.
 func (r *PortfolioRepository) getQuerier() Querier {
 	if r.tx != nil {
-		return r.tx
+		return traced(r.tx)
 	}
-	return r.db
+	return traced(r.db)
 }
 
 // GetPortfolios retrieves portfolios from the database based on filter criteria.
-// The filter allows control over whether archived and overview-excluded portfolios are included.
+// The filter allows control over whether archived and overview-excluded portfolios are included
+// and limits the result to the portfolios in its scope.
 // Returns an empty slice if no portfolios match the filter criteria.
 func (r *PortfolioRepository) GetPortfolios(filter model.PortfolioFilter) ([]model.Portfolio, error) {
 	portfolioLog.Debug("getting portfolios", "include_archived", filter.IncludeArchived, "include_excluded", filter.IncludeExcluded)
+	scope, args := scopeCondition(filter.Scope, "id")
+	//#nosec G202 -- Safe: placeholders are generated programmatically, not from user input
 	query := `
-          SELECT id, name, description, is_archived, exclude_from_overview
+          SELECT id, name, description, is_archived, exclude_from_overview, owner_id
           FROM portfolio
-          WHERE 1=1
-      `
-	var args []any
+          WHERE ` + scope
 
 	if !filter.IncludeArchived {
 		query += " AND is_archived = ?"
@@ -72,6 +73,7 @@ func (r *PortfolioRepository) GetPortfolios(filter model.PortfolioFilter) ([]mod
 
 	for rows.Next() {
 		var p model.Portfolio
+		var ownerID sql.NullString
 
 		err := rows.Scan(
 			&p.ID,
@@ -79,11 +81,13 @@ func (r *PortfolioRepository) GetPortfolios(filter model.PortfolioFilter) ([]mod
 			&p.Description,
 			&p.IsArchived,
 			&p.ExcludeFromOverview,
+			&ownerID,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan portfolio table results: %w", err)
 		}
 
+		p.OwnerID = ownerID.String
 		portfolios = append(portfolios, p)
 	}
 
@@ -98,11 +102,12 @@ func (r *PortfolioRepository) GetPortfolios(filter model.PortfolioFilter) ([]mod
 func (r *PortfolioRepository) GetPortfolioOnID(portfolioID string) (model.Portfolio, error) {
 	portfolioLog.Debug("getting portfolio by ID", "portfolio_id", portfolioID)
 	query := `
-          SELECT id, name, description, is_archived, exclude_from_overview
+          SELECT id, name, description, is_archived, exclude_from_overview, owner_id
           FROM portfolio
           WHERE id = ?
       `
 	var p model.Portfolio
+	var ownerID sql.NullString
 
 	err := r.getQuerier().QueryRow(query, portfolioID).Scan(
 		&p.ID,
@@ -110,6 +115,7 @@ func (r *PortfolioRepository) GetPortfolioOnID(portfolioID string) (model.Portfo
 		&p.Description,
 		&p.IsArchived,
 		&p.ExcludeFromOverview,
+		&ownerID,
 	)
 	if err == sql.ErrNoRows {
 		return model.Portfolio{}, apperrors.ErrPortfolioNotFound
@@ -117,6 +123,7 @@ func (r *PortfolioRepository) GetPortfolioOnID(portfolioID string) (model.Portfo
 	if err != nil {
 		return model.Portfolio{}, fmt.Errorf("failed to query portfolio: %w", err)
 	}
+	p.OwnerID = ownerID.String
 
 	return p, nil
 }
@@ -133,7 +140,7 @@ func (r *PortfolioRepository) GetPortfoliosByFundID(fundID string) ([]model.Port
 	portfolioLog.Debug("getting portfolios by fund ID", "fund_id", fundID)
 
 	fundQuery := `
-		SELECT p.id, p.name, p.description, p.is_archived, p.exclude_from_overview
+		SELECT p.id, p.name, p.description, p.is_archived, p.exclude_from_overview, p.owner_id
         FROM portfolio p
 		INNER JOIN portfolio_fund pf
 		ON pf.portfolio_id = p.id
@@ -150,6 +157,7 @@ func (r *PortfolioRepository) GetPortfoliosByFundID(fundID string) ([]model.Port
 
 	for rows.Next() {
 		var p model.Portfolio
+		var ownerID sql.NullString
 
 		err := rows.Scan(
 			&p.ID,
@@ -157,11 +165,13 @@ func (r *PortfolioRepository) GetPortfoliosByFundID(fundID string) ([]model.Port
 			&p.Description,
 			&p.IsArchived,
 			&p.ExcludeFromOverview,
+			&ownerID,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan portfolio_fund or portfolio table results: %w", err)
 		}
 
+		p.OwnerID = ownerID.String
 		portfolios = append(portfolios, p)
 	}
 
@@ -176,8 +186,8 @@ func (r *PortfolioRepository) GetPortfoliosByFundID(fundID string) ([]model.Port
 func (r *PortfolioRepository) InsertPortfolio(ctx context.Context, p *model.Portfolio) error {
 	portfolioLog.DebugContext(ctx, "inserting portfolio", "portfolio_id", p.ID, "name", p.Name)
 	query := `
-        INSERT INTO portfolio (id, name, description, is_archived, exclude_from_overview)
-        VALUES (?, ?, ?, ?, ?)
+        INSERT INTO portfolio (id, name, description, is_archived, exclude_from_overview, owner_id)
+        VALUES (?, ?, ?, ?, ?, ?)
     `
 
 	_, err := r.getQuerier().ExecContext(ctx, query,
@@ -186,6 +196,7 @@ func (r *PortfolioRepository) InsertPortfolio(ctx context.Context, p *model.Port
 		p.Description,
 		p.IsArchived,
 		p.ExcludeFromOverview,
+		nullableString(p.OwnerID),
 	)
 
 	if err != nil {
@@ -17,7 +17,7 @@ func TestPortfolioRepository_GetPortfolios(t *testing.T) {
 	repo := repository.NewPortfolioRepository(db)
 
 	t.Run("returns empty slice when no portfolios exist", func(t *testing.T) {
-		result, err := repo.GetPortfolios(model.PortfolioFilter{})
+		result, err := repo.GetPortfolios(model.PortfolioFilter{Scope: model.AllPortfolios()})
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -32,7 +32,7 @@ func TestPortfolioRepository_GetPortfolios(t *testing.T) {
 	excluded := testutil.NewPortfolio().WithName("Excluded").ExcludedFromOverview().Build(t, db)
 
 	t.Run("excludes archived and excluded by default", func(t *testing.T) {
-		result, err := repo.GetPortfolios(model.PortfolioFilter{})
+		result, err := repo.GetPortfolios(model.PortfolioFilter{Scope: model.AllPortfolios()})
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -45,7 +45,7 @@ func TestPortfolioRepository_GetPortfolios(t *testing.T) {
 	})
 
 	t.Run("includes archived when filter is set", func(t *testing.T) {
-		result, err := repo.GetPortfolios(model.PortfolioFilter{IncludeArchived: true})
+		result, err := repo.GetPortfolios(model.PortfolioFilter{IncludeArchived: true, Scope: model.AllPortfolios()})
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -62,7 +62,7 @@ func TestPortfolioRepository_GetPortfolios(t *testing.T) {
 	})
 
 	t.Run("includes excluded when filter is set", func(t *testing.T) {
-		result, err := repo.GetPortfolios(model.PortfolioFilter{IncludeExcluded: true})
+		result, err := repo.GetPortfolios(model.PortfolioFilter{IncludeExcluded: true, Scope: model.AllPortfolios()})
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -79,7 +79,7 @@ func TestPortfolioRepository_GetPortfolios(t *testing.T) {
 	})
 
 	t.Run("includes all when both filters set", func(t *testing.T) {
-		result, err := repo.GetPortfolios(model.PortfolioFilter{IncludeArchived: true, IncludeExcluded: true})
+		result, err := repo.GetPortfolios(model.PortfolioFilter{IncludeArchived: true, IncludeExcluded: true, Scope: model.AllPortfolios()})
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -87,6 +87,30 @@ func TestPortfolioRepository_GetPortfolios(t *testing.T) {
 			t.Fatalf("expected 3 portfolios, got %d", len(result))
 		}
 	})
+
+	t.Run("limits results to the portfolios in scope", func(t *testing.T) {
+		result, err := repo.GetPortfolios(model.PortfolioFilter{
+			IncludeArchived: true,
+			IncludeExcluded: true,
+			Scope:           model.PortfolioScope{PortfolioIDs: []string{archived.ID}},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(result) != 1 || result[0].ID != archived.ID {
+			t.Errorf("expected only the archived portfolio, got %+v", result)
+		}
+	})
+
+	t.Run("returns nothing without a scope", func(t *testing.T) {
+		result, err := repo.GetPortfolios(model.PortfolioFilter{IncludeArchived: true, IncludeExcluded: true})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(result) != 0 {
+			t.Errorf("expected no portfolios, got %d", len(result))
+		}
+	})
 }
 
 func TestPortfolioRepository_GetPortfolioOnID(t *testing.T) {
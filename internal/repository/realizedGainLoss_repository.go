@@ -36,9 +36,9 @@ func (s *RealizedGainLossRepository) WithTx(tx *sql.Tx) *RealizedGainLossReposit
 // getQuerier returns the active transaction if one is set, otherwise the database connection.
 func (s *RealizedGainLossRepository) getQuerier() Querier {
 	if s.tx != nil {
-		return s.tx
+		return traced(s.tx)
 	}
-	return s.db
+	return traced(s.db)
 }
 
 // GetRealizedGainLossByPortfolio retrieves all realized gain/loss records for the given portfolios within the specified date range.
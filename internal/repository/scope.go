@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"strings"
+
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/model"
+)
+
+// scopeCondition returns an SQL condition limiting column, a portfolio ID, to the portfolios
+// in scope, with its arguments. The zero scope matches nothing.
+func scopeCondition(scope model.PortfolioScope, column string) (string, []any) {
+	if scope.All {
+		return "1 = 1", nil
+	}
+	if len(scope.PortfolioIDs) == 0 {
+		return "1 = 0", nil
+	}
+
+	args := make([]any, len(scope.PortfolioIDs))
+	placeholders := make([]string, len(scope.PortfolioIDs))
+	for i, id := range scope.PortfolioIDs {
+		args[i] = id
+		placeholders[i] = "?"
+	}
+	return column + " IN (" + strings.Join(placeholders, ",") + ")", args
+}
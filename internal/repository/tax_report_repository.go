@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/apperrors"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/model"
+)
+
+// TaxReportRepository provides the data access for the yearly tax reports.
+type TaxReportRepository struct {
+	db *sql.DB
+	tx *sql.Tx
+}
+
+// NewTaxReportRepository creates a new TaxReportRepository with the provided database connection.
+func NewTaxReportRepository(db *sql.DB) *TaxReportRepository {
+	return &TaxReportRepository{db: db}
+}
+
+// WithTx returns a new TaxReportRepository scoped to the provided transaction.
+func (r *TaxReportRepository) WithTx(tx *sql.Tx) *TaxReportRepository {
+	return &TaxReportRepository{
+		db: r.db,
+		tx: tx,
+	}
+}
+
+// getQuerier returns the active transaction if one is set, otherwise the database connection.
+func (r *TaxReportRepository) getQuerier() Querier {
+	if r.tx != nil {
+		return traced(r.tx)
+	}
+	return traced(r.db)
+}
+
+// GetHoldingsOnDate retrieves every position of the portfolios in scope with shares on date,
+// ordered by portfolio and fund name. Each position is read from its latest
+// fund_history_materialized row on or before date, as no row exists on days without a price.
+// Returns [apperrors.ErrMaterializedDataNotAvailable] if a portfolio fund with a transaction on
+// or before date has no such row, or only one older than that transaction.
+func (r *TaxReportRepository) GetHoldingsOnDate(date time.Time, scope model.PortfolioScope) ([]model.TaxHolding, error) {
+	dateStr := date.Format("2006-01-02")
+	condition, args := scopeCondition(scope, "pf.portfolio_id")
+
+	var missing int
+	//#nosec G202 -- Safe: placeholders are generated programmatically, not from user input
+	err := r.getQuerier().QueryRow(`
+		SELECT COUNT(*)
+		FROM (
+			SELECT t.portfolio_fund_id, MAX(t.date) AS last_transaction
+			FROM "transaction" t
+			JOIN portfolio_fund pf ON t.portfolio_fund_id = pf.id
+			WHERE t.date <= ? AND `+condition+`
+			GROUP BY t.portfolio_fund_id
+		) t
+		LEFT JOIN (
+			SELECT portfolio_fund_id, MAX(date) AS last_row
+			FROM fund_history_materialized
+			WHERE date <= ?
+			GROUP BY portfolio_fund_id
+		) h ON h.portfolio_fund_id = t.portfolio_fund_id
+		WHERE h.last_row IS NULL OR h.last_row < t.last_transaction
+	`, append(append([]any{dateStr}, args...), dateStr)...).Scan(&missing)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check materialized coverage: %w", err)
+	}
+	if missing > 0 {
+		return nil, fmt.Errorf("%w: %d portfolio funds on %s", apperrors.ErrMaterializedDataNotAvailable, missing, dateStr)
+	}
+
+	//#nosec G202 -- Safe: placeholders are generated programmatically, not from user input
+	rows, err := r.getQuerier().Query(`
+		SELECT pf.portfolio_id, f.id, f.name, COALESCE(f.isin, ''), f.currency, h.shares, h.price, h.value
+		FROM fund_history_materialized h
+		JOIN (
+			SELECT portfolio_fund_id, MAX(date) AS latest_date
+			FROM fund_history_materialized
+			WHERE date <= ?
+			GROUP BY portfolio_fund_id
+		) latest ON h.portfolio_fund_id = latest.portfolio_fund_id AND h.date = latest.latest_date
+		JOIN portfolio_fund pf ON h.portfolio_fund_id = pf.id
+		JOIN fund f ON pf.fund_id = f.id
+		WHERE h.shares > 0 AND `+condition+`
+		ORDER BY pf.portfolio_id, f.name
+	`, append([]any{dateStr}, args...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query holdings: %w", err)
+	}
+	defer rows.Close()
+
+	holdings := []model.TaxHolding{}
+	for rows.Next() {
+		var h model.TaxHolding
+		if err := rows.Scan(&h.PortfolioID, &h.FundID, &h.FundName, &h.ISIN, &h.Currency, &h.Shares, &h.Price, &h.Value); err != nil {
+			return nil, fmt.Errorf("failed to scan holding: %w", err)
+		}
+		holdings = append(holdings, h)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating holdings: %w", err)
+	}
+	return holdings, nil
+}
+
+// GetRealizedGains retrieves the realized gains of the portfolios in scope from the sales dated
+// in year, with their portfolio fund and fund, ordered by sale date.
+func (r *TaxReportRepository) GetRealizedGains(year int, scope model.PortfolioScope) ([]model.TaxRealizedGain, error) {
+	condition, args := scopeCondition(scope, "rgl.portfolio_id")
+	//#nosec G202 -- Safe: placeholders are generated programmatically, not from user input
+	rows, err := r.getQuerier().Query(`
+		SELECT rgl.portfolio_id, t.portfolio_fund_id, rgl.transaction_id, f.id, f.name, COALESCE(f.isin, ''), f.currency,
+			rgl.transaction_date, rgl.shares_sold, rgl.cost_basis, rgl.sale_proceeds, rgl.realized_gain_loss
+		FROM realized_gain_loss rgl
+		JOIN "transaction" t ON rgl.transaction_id = t.id
+		JOIN fund f ON rgl.fund_id = f.id
+		WHERE strftime('%Y', rgl.transaction_date) = ? AND `+condition+`
+		ORDER BY rgl.transaction_date, rgl.created_at
+	`, append([]any{strconv.Itoa(year)}, args...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query realized gains: %w", err)
+	}
+	defer rows.Close()
+
+	gains := []model.TaxRealizedGain{}
+	for rows.Next() {
+		var g model.TaxRealizedGain
+		var dateStr string
+		if err := rows.Scan(&g.PortfolioID, &g.PortfolioFundID, &g.TransactionID, &g.FundID, &g.FundName, &g.ISIN, &g.Currency,
+			&dateStr, &g.SharesSold, &g.CostBasis, &g.Proceeds, &g.Gain); err != nil {
+			return nil, fmt.Errorf("failed to scan realized gain: %w", err)
+		}
+		if g.SaleDate, err = ParseTime(dateStr); err != nil {
+			return nil, fmt.Errorf("failed to parse transaction_date: %w", err)
+		}
+		gains = append(gains, g)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating realized gains: %w", err)
+	}
+	return gains, nil
+}
@@ -0,0 +1,133 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/apperrors"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/model"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/repository"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/testutil"
+)
+
+func TestTaxReportRepository_GetHoldingsOnDate(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	repo := repository.NewTaxReportRepository(db)
+
+	portfolio := testutil.NewPortfolio().Build(t, db)
+	held := testutil.NewFund().WithName("Held").WithISIN("IE00B4L5Y983").WithCurrency("EUR").Build(t, db)
+	sold := testutil.NewFund().WithName("Sold").Build(t, db)
+	heldPF := testutil.NewPortfolioFund(portfolio.ID, held.ID).Build(t, db)
+	soldPF := testutil.NewPortfolioFund(portfolio.ID, sold.ID).Build(t, db)
+
+	jan1 := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	entry := func(pfID, fundID string, date time.Time, shares, price float64) model.FundHistoryEntry {
+		return model.FundHistoryEntry{ID: testutil.MakeID(), PortfolioFundID: pfID, FundID: fundID, Date: date,
+			Shares: shares, Price: price, Value: shares * price}
+	}
+	err := repository.NewMaterializedRepository(db).InsertMaterializedEntries(context.Background(), []model.FundHistoryEntry{
+		entry(heldPF.ID, held.ID, jan1.AddDate(0, 0, -1), 10, 99),
+		entry(heldPF.ID, held.ID, jan1, 10, 100),
+		entry(soldPF.ID, sold.ID, jan1, 0, 50),
+	})
+	if err != nil {
+		t.Fatalf("InsertMaterializedEntries: %v", err)
+	}
+
+	holdings, err := repo.GetHoldingsOnDate(jan1, model.AllPortfolios())
+	if err != nil {
+		t.Fatalf("GetHoldingsOnDate: %v", err)
+	}
+	if len(holdings) != 1 {
+		t.Fatalf("expected only the fund with shares, got %+v", holdings)
+	}
+	h := holdings[0]
+	if h.PortfolioID != portfolio.ID || h.FundName != "Held" || h.ISIN != "IE00B4L5Y983" || h.Currency != "EUR" {
+		t.Errorf("unexpected holding %+v", h)
+	}
+	if h.Shares != 10 || h.Price != 100 || h.Value != 1000 {
+		t.Errorf("expected the 1 January row, got %+v", h)
+	}
+}
+
+func TestTaxReportRepository_GetHoldingsOnDate_LatestRow(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	repo := repository.NewTaxReportRepository(db)
+	materialized := repository.NewMaterializedRepository(db)
+
+	portfolio := testutil.NewPortfolio().Build(t, db)
+	fund := testutil.NewFund().Build(t, db)
+	pf := testutil.NewPortfolioFund(portfolio.ID, fund.ID).Build(t, db)
+
+	jan1 := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	dec31 := jan1.AddDate(0, 0, -1)
+	testutil.NewTransaction(pf.ID).WithDate(jan1.AddDate(0, -6, 0)).Build(t, db)
+
+	t.Run("missing materialized data is an error", func(t *testing.T) {
+		_, err := repo.GetHoldingsOnDate(jan1, model.AllPortfolios())
+		if !errors.Is(err, apperrors.ErrMaterializedDataNotAvailable) {
+			t.Fatalf("expected ErrMaterializedDataNotAvailable, got %v", err)
+		}
+	})
+
+	err := materialized.InsertMaterializedEntries(context.Background(), []model.FundHistoryEntry{
+		{ID: testutil.MakeID(), PortfolioFundID: pf.ID, FundID: fund.ID, Date: dec31.AddDate(0, 0, -1), Shares: 10, Price: 98, Value: 980},
+		{ID: testutil.MakeID(), PortfolioFundID: pf.ID, FundID: fund.ID, Date: dec31, Shares: 10, Price: 99, Value: 990},
+		{ID: testutil.MakeID(), PortfolioFundID: pf.ID, FundID: fund.ID, Date: jan1.AddDate(0, 0, 1), Shares: 10, Price: 101, Value: 1010},
+	})
+	if err != nil {
+		t.Fatalf("InsertMaterializedEntries: %v", err)
+	}
+
+	t.Run("uses the latest row before a date without one", func(t *testing.T) {
+		holdings, err := repo.GetHoldingsOnDate(jan1, model.AllPortfolios())
+		if err != nil {
+			t.Fatalf("GetHoldingsOnDate: %v", err)
+		}
+		if len(holdings) != 1 || holdings[0].Price != 99 || holdings[0].Value != 990 {
+			t.Errorf("expected the 31 December row, got %+v", holdings)
+		}
+	})
+
+	t.Run("a row older than the last transaction is an error", func(t *testing.T) {
+		testutil.NewTransaction(pf.ID).WithDate(jan1).Build(t, db)
+		_, err := repo.GetHoldingsOnDate(jan1, model.AllPortfolios())
+		if !errors.Is(err, apperrors.ErrMaterializedDataNotAvailable) {
+			t.Fatalf("expected ErrMaterializedDataNotAvailable, got %v", err)
+		}
+	})
+}
+
+func TestTaxReportRepository_GetRealizedGains(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	repo := repository.NewTaxReportRepository(db)
+
+	portfolio := testutil.NewPortfolio().Build(t, db)
+	fund := testutil.NewFund().WithName("US Equity").WithISIN("US0378331005").Build(t, db)
+	pf := testutil.NewPortfolioFund(portfolio.ID, fund.ID).Build(t, db)
+
+	sell := func(date time.Time) model.Transaction {
+		tx := testutil.NewTransaction(pf.ID).WithType("sell").WithDate(date).WithShares(30).Build(t, db)
+		testutil.NewRealizedGainLoss(portfolio.ID, fund.ID, tx.ID).WithDate(date).Build(t, db)
+		return tx
+	}
+	inYear := sell(time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC))
+	sell(time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC))
+
+	gains, err := repo.GetRealizedGains(2025, model.AllPortfolios())
+	if err != nil {
+		t.Fatalf("GetRealizedGains: %v", err)
+	}
+	if len(gains) != 1 {
+		t.Fatalf("expected one 2025 sale, got %+v", gains)
+	}
+	g := gains[0]
+	if g.TransactionID != inYear.ID || g.PortfolioID != portfolio.ID || g.PortfolioFundID != pf.ID || g.FundName != "US Equity" || g.ISIN != "US0378331005" {
+		t.Errorf("unexpected gain %+v", g)
+	}
+	if g.SharesSold != 30 || g.CostBasis != 300 || g.Proceeds != 450 || g.Gain != 150 || g.SaleDate.Format("2006-01-02") != "2025-05-01" {
+		t.Errorf("unexpected amounts %+v", g)
+	}
+}
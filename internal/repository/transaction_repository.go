@@ -37,9 +37,9 @@ func (r *TransactionRepository) WithTx(tx *sql.Tx) *TransactionRepository {
 // getQuerier returns the active transaction if one is set, otherwise the database connection.
 func (r *TransactionRepository) getQuerier() Querier {
 	if r.tx != nil {
-		return r.tx
+		return traced(r.tx)
 	}
-	return r.db
+	return traced(r.db)
 }
 
 // GetTransactions retrieves all transactions for the given portfolio_fund IDs within the specified date range.
@@ -169,9 +169,10 @@ func (r *TransactionRepository) GetOldestTransaction(pfIDs []string) time.Time {
 }
 
 // GetTransactionsPerPortfolio retrieves all transactions for a specific portfolio or all transactions if portfolioId is empty.
+// Only transactions of portfolios in scope are returned.
 // Returns enriched transaction data including fund names and IBKR linkage status.
 // Transactions are sorted by date in ascending order.
-func (r *TransactionRepository) GetTransactionsPerPortfolio(portfolioID string) ([]model.TransactionResponse, error) {
+func (r *TransactionRepository) GetTransactionsPerPortfolio(portfolioID string, scope model.PortfolioScope) ([]model.TransactionResponse, error) {
 	txnLog.Debug("getting transactions per portfolio", "portfolio_id", portfolioID)
 
 	transactionQuery := `
@@ -187,7 +188,8 @@ func (r *TransactionRepository) GetTransactionsPerPortfolio(portfolioID string)
 			CASE
 				WHEN ita.ibkr_transaction_id IS NOT NULL THEN 1
 				ELSE 0
-			END AS ibkr_linked
+			END AS ibkr_linked,
+			t.investment_plan_id
 		FROM "transaction" t
 		JOIN portfolio_fund pf ON t.portfolio_fund_id = pf.id
 		JOIN portfolio p ON pf.portfolio_id = p.id
@@ -195,15 +197,18 @@ func (r *TransactionRepository) GetTransactionsPerPortfolio(portfolioID string)
 		LEFT JOIN ibkr_transaction_allocation ita ON t.id = ita.transaction_id
 	`
 
-	var args []any
+	condition, args := scopeCondition(scope, "pf.portfolio_id")
 
 	if portfolioID == "" {
+		//#nosec G202 -- Safe: placeholders are generated programmatically, not from user input
 		transactionQuery += `
+		WHERE ` + condition + `
 		ORDER BY t.date ASC
 		`
 	} else {
+		//#nosec G202 -- Safe: placeholders are generated programmatically, not from user input
 		transactionQuery += `
-		WHERE pf.portfolio_id = ?
+		WHERE ` + condition + ` AND pf.portfolio_id = ?
 		ORDER BY t.date ASC
 		`
 		args = append(args, portfolioID)
@@ -220,7 +225,7 @@ func (r *TransactionRepository) GetTransactionsPerPortfolio(portfolioID string)
 	for rows.Next() {
 
 		var dateStr string
-		var ibkrTransactionIDStr sql.NullString
+		var ibkrTransactionIDStr, investmentPlanID sql.NullString
 		var t model.TransactionResponse
 
 		err := rows.Scan(
@@ -233,6 +238,7 @@ func (r *TransactionRepository) GetTransactionsPerPortfolio(portfolioID string)
 			&t.CostPerShare,
 			&ibkrTransactionIDStr,
 			&t.IbkrLinked,
+			&investmentPlanID,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan transaction table results: %w", err)
@@ -246,6 +252,8 @@ func (r *TransactionRepository) GetTransactionsPerPortfolio(portfolioID string)
 		if ibkrTransactionIDStr.Valid {
 			t.IbkrTransactionID = ibkrTransactionIDStr.String
 		}
+		t.InvestmentPlanID = investmentPlanID.String
+		t.PlanGenerated = investmentPlanID.Valid
 
 		transactionResponse = append(transactionResponse, t)
 	}
@@ -279,7 +287,8 @@ func (r *TransactionRepository) GetTransaction(transactionID string) (model.Tran
 			CASE
 				WHEN ita.ibkr_transaction_id IS NOT NULL THEN 1
 				ELSE 0
-			END AS ibkr_linked
+			END AS ibkr_linked,
+			t.investment_plan_id
 		FROM "transaction" t
 		JOIN portfolio_fund pf ON t.portfolio_fund_id = pf.id
 		JOIN portfolio p ON pf.portfolio_id = p.id
@@ -289,7 +298,7 @@ func (r *TransactionRepository) GetTransaction(transactionID string) (model.Tran
 	`
 	var t model.TransactionResponse
 	var dateStr string
-	var ibkrTransactionIDStr sql.NullString
+	var ibkrTransactionIDStr, investmentPlanID sql.NullString
 	err := r.getQuerier().QueryRow(transactionQuery, transactionID).Scan(
 		&t.ID,
 		&t.PortfolioFundID,
@@ -300,6 +309,7 @@ func (r *TransactionRepository) GetTransaction(transactionID string) (model.Tran
 		&t.CostPerShare,
 		&ibkrTransactionIDStr,
 		&t.IbkrLinked,
+		&investmentPlanID,
 	)
 	if err == sql.ErrNoRows {
 		return model.TransactionResponse{}, apperrors.ErrTransactionNotFound
@@ -316,6 +326,8 @@ func (r *TransactionRepository) GetTransaction(transactionID string) (model.Tran
 	if ibkrTransactionIDStr.Valid {
 		t.IbkrTransactionID = ibkrTransactionIDStr.String
 	}
+	t.InvestmentPlanID = investmentPlanID.String
+	t.PlanGenerated = investmentPlanID.Valid
 
 	return t, nil
 }
@@ -329,12 +341,13 @@ func (r *TransactionRepository) GetTransaction(transactionID string) (model.Tran
 func (r *TransactionRepository) GetTransactionByID(transactionID string) (model.Transaction, error) {
 	txnLog.Debug("getting transaction by ID", "transaction_id", transactionID)
 	query := `
-          SELECT id, portfolio_fund_id, date, type, shares, cost_per_share, created_at
+          SELECT id, portfolio_fund_id, date, type, shares, cost_per_share, investment_plan_id, created_at
           FROM "transaction"
           WHERE id = ?
       `
 	var t model.Transaction
 	var dateStr, createdAtStr string
+	var investmentPlanID sql.NullString
 
 	err := r.getQuerier().QueryRow(query, transactionID).Scan(
 		&t.ID,
@@ -343,6 +356,7 @@ func (r *TransactionRepository) GetTransactionByID(transactionID string) (model.
 		&t.Type,
 		&t.Shares,
 		&t.CostPerShare,
+		&investmentPlanID,
 		&createdAtStr,
 	)
 
@@ -352,6 +366,7 @@ func (r *TransactionRepository) GetTransactionByID(transactionID string) (model.
 	if err != nil {
 		return t, fmt.Errorf("failed to get transaction: %w", err)
 	}
+	t.InvestmentPlanID = investmentPlanID.String
 
 	t.Date, err = ParseTime(dateStr)
 	if err != nil || t.Date.IsZero() {
@@ -373,8 +388,8 @@ func (r *TransactionRepository) GetTransactionByID(transactionID string) (model.
 func (r *TransactionRepository) InsertTransaction(ctx context.Context, t *model.Transaction) error {
 	txnLog.DebugContext(ctx, "inserting transaction", "transaction_id", t.ID, "portfolio_fund_id", t.PortfolioFundID, "type", t.Type)
 	query := `
-        INSERT INTO "transaction" (id, portfolio_fund_id, date, type, shares, cost_per_share, created_at)
-        VALUES (?, ?, ?, ?, ?, ?, ?)
+        INSERT INTO "transaction" (id, portfolio_fund_id, date, type, shares, cost_per_share, investment_plan_id, created_at)
+        VALUES (?, ?, ?, ?, ?, ?, ?, ?)
     `
 
 	_, err := r.getQuerier().ExecContext(ctx, query,
@@ -384,6 +399,7 @@ func (r *TransactionRepository) InsertTransaction(ctx context.Context, t *model.
 		t.Type,
 		t.Shares,
 		t.CostPerShare,
+		nullableString(t.InvestmentPlanID),
 		t.CreatedAt.Format("2006-01-02 15:04:05"),
 	)
 
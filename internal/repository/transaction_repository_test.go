@@ -218,7 +218,7 @@ func TestTransactionRepository_GetTransactionsPerPortfolio(t *testing.T) {
 		testutil.NewTransaction(pf.ID).WithDate(time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)).Build(t, db)
 
 		repo := repository.NewTransactionRepository(db)
-		result, err := repo.GetTransactionsPerPortfolio(portfolio.ID)
+		result, err := repo.GetTransactionsPerPortfolio(portfolio.ID, model.AllPortfolios())
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -239,7 +239,7 @@ func TestTransactionRepository_GetTransactionsPerPortfolio(t *testing.T) {
 		testutil.NewTransaction(pf2.ID).WithDate(time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)).Build(t, db)
 
 		repo := repository.NewTransactionRepository(db)
-		result, err := repo.GetTransactionsPerPortfolio("")
+		result, err := repo.GetTransactionsPerPortfolio("", model.AllPortfolios())
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -248,12 +248,41 @@ func TestTransactionRepository_GetTransactionsPerPortfolio(t *testing.T) {
 		}
 	})
 
+	t.Run("returns only transactions of portfolios in scope", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		p1 := testutil.NewPortfolio().Build(t, db)
+		p2 := testutil.NewPortfolio().Build(t, db)
+		fund := testutil.NewFund().Build(t, db)
+		pf1 := testutil.NewPortfolioFund(p1.ID, fund.ID).Build(t, db)
+		pf2 := testutil.NewPortfolioFund(p2.ID, fund.ID).Build(t, db)
+
+		testutil.NewTransaction(pf1.ID).Build(t, db)
+		testutil.NewTransaction(pf2.ID).Build(t, db)
+
+		repo := repository.NewTransactionRepository(db)
+		result, err := repo.GetTransactionsPerPortfolio("", model.PortfolioScope{PortfolioIDs: []string{p1.ID}})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(result) != 1 || result[0].PortfolioFundID != pf1.ID {
+			t.Errorf("expected only the transaction of the scoped portfolio, got %+v", result)
+		}
+
+		result, err = repo.GetTransactionsPerPortfolio(p2.ID, model.PortfolioScope{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(result) != 0 {
+			t.Errorf("expected no transactions without a scope, got %d", len(result))
+		}
+	})
+
 	t.Run("returns empty slice for portfolio with no transactions", func(t *testing.T) {
 		db := testutil.SetupTestDB(t)
 		portfolio := testutil.NewPortfolio().Build(t, db)
 
 		repo := repository.NewTransactionRepository(db)
-		result, err := repo.GetTransactionsPerPortfolio(portfolio.ID)
+		result, err := repo.GetTransactionsPerPortfolio(portfolio.ID, model.AllPortfolios())
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -271,7 +300,7 @@ func TestTransactionRepository_GetTransactionsPerPortfolio(t *testing.T) {
 		testutil.NewTransaction(pf.ID).WithDate(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)).Build(t, db)
 
 		repo := repository.NewTransactionRepository(db)
-		result, err := repo.GetTransactionsPerPortfolio(portfolio.ID)
+		result, err := repo.GetTransactionsPerPortfolio(portfolio.ID, model.AllPortfolios())
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -0,0 +1,346 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/apperrors"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/logging"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/model"
+)
+
+var trashLog = logging.NewLogger("database")
+
+// TrashRepository provides data access methods for the trash table, and the
+// row-level snapshot/re-insert primitives used to soft-delete and restore entities.
+type TrashRepository struct {
+	db *sql.DB
+	tx *sql.Tx
+}
+
+// NewTrashRepository creates a new TrashRepository with the provided database connection.
+func NewTrashRepository(db *sql.DB) *TrashRepository {
+	return &TrashRepository{db: db}
+}
+
+// WithTx returns a new TrashRepository scoped to the provided transaction.
+func (r *TrashRepository) WithTx(tx *sql.Tx) *TrashRepository {
+	return &TrashRepository{
+		db: r.db,
+		tx: tx,
+	}
+}
+
+// getQuerier returns the active transaction if one is set, otherwise the database connection.
+func (r *TrashRepository) getQuerier() Querier {
+	if r.tx != nil {
+		return traced(r.tx)
+	}
+	return traced(r.db)
+}
+
+// SnapshotRows captures every row of table matching where as a TrashTable.
+// table and where are supplied by the service layer, never by the client; all values are bound via args.
+//
+// DATE/DATETIME columns are read back as their stored text rather than as time.Time so
+// that a restore writes them in exactly the format they were written in. Other columns
+// are formatted losslessly (floats with the shortest round-trip representation).
+func (r *TrashRepository) SnapshotRows(ctx context.Context, table, where string, args ...any) (model.TrashTable, error) {
+	columns, err := r.tableColumns(ctx, table)
+	if err != nil {
+		return model.TrashTable{}, err
+	}
+
+	selects := make([]string, len(columns))
+	for i, c := range columns {
+		if c.isTime {
+			selects[i] = fmt.Sprintf("CAST(%q AS TEXT)", c.name)
+		} else {
+			selects[i] = fmt.Sprintf("%q", c.name)
+		}
+	}
+
+	//nolint:gosec // G201: table, columns and where come from the service layer and schema, values are parameterized
+	query := fmt.Sprintf("SELECT %s FROM %q WHERE %s", strings.Join(selects, ", "), table, where)
+
+	rows, err := r.getQuerier().QueryContext(ctx, query, args...)
+	if err != nil {
+		return model.TrashTable{}, fmt.Errorf("failed to snapshot %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	snapshot := model.TrashTable{Table: table, Rows: []map[string]*string{}}
+	values := make([]any, len(columns))
+	ptrs := make([]any, len(columns))
+	for i := range values {
+		ptrs[i] = &values[i]
+	}
+
+	for rows.Next() {
+		if err := rows.Scan(ptrs...); err != nil {
+			return model.TrashTable{}, fmt.Errorf("failed to scan %s row: %w", table, err)
+		}
+		row := make(map[string]*string, len(columns))
+		for i, c := range columns {
+			row[c.name] = snapshotValue(values[i])
+		}
+		snapshot.Rows = append(snapshot.Rows, row)
+	}
+	if err := rows.Err(); err != nil {
+		return model.TrashTable{}, fmt.Errorf("error iterating %s rows: %w", table, err)
+	}
+
+	return snapshot, nil
+}
+
+// RestoreRows re-inserts every row of a snapshot into its table.
+// Returns ErrTrashRestoreConflict when a row collides with existing data or references
+// a row that no longer exists (e.g. the transaction's portfolio has since been deleted).
+func (r *TrashRepository) RestoreRows(ctx context.Context, t model.TrashTable) error {
+	for _, row := range t.Rows {
+		cols := make([]string, 0, len(row))
+		for c := range row {
+			cols = append(cols, c)
+		}
+		slices.Sort(cols)
+
+		quoted := make([]string, len(cols))
+		args := make([]any, len(cols))
+		for i, c := range cols {
+			quoted[i] = fmt.Sprintf("%q", c)
+			if v := row[c]; v != nil {
+				args[i] = *v
+			}
+		}
+
+		//nolint:gosec // G201: table and column names come from a snapshot taken from the schema, values are parameterized
+		query := fmt.Sprintf("INSERT INTO %q (%s) VALUES (%s)", t.Table, strings.Join(quoted, ", "), placeholders(len(cols)))
+
+		if _, err := r.getQuerier().ExecContext(ctx, query, args...); err != nil {
+			if strings.Contains(err.Error(), "constraint failed") {
+				return fmt.Errorf("%w: %s: %w", apperrors.ErrTrashRestoreConflict, t.Table, err)
+			}
+			return fmt.Errorf("failed to restore %s row: %w", t.Table, err)
+		}
+	}
+	return nil
+}
+
+// InsertTrashItem stores a deleted entity's snapshot.
+func (r *TrashRepository) InsertTrashItem(ctx context.Context, item *model.TrashItem) error {
+	payload, err := json.Marshal(item.Payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal trash payload: %w", err)
+	}
+
+	query := `
+		INSERT INTO trash (id, entity_type, entity_id, label, deleted_at, payload, request_id)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`
+	_, err = r.getQuerier().ExecContext(ctx, query,
+		item.ID,
+		item.EntityType,
+		item.EntityID,
+		item.Label,
+		item.DeletedAt.Format("2006-01-02 15:04:05"),
+		string(payload),
+		nullableString(item.RequestID),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert trash item: %w", err)
+	}
+	return nil
+}
+
+// GetTrashItems retrieves trash items, newest first, optionally filtered by entity type.
+func (r *TrashRepository) GetTrashItems(entityType string) ([]model.TrashItem, error) {
+	query := `
+		SELECT id, entity_type, entity_id, label, deleted_at, payload, request_id
+		FROM trash
+	`
+	var args []any
+	if entityType != "" {
+		query += ` WHERE entity_type = ?`
+		args = append(args, entityType)
+	}
+	query += ` ORDER BY deleted_at DESC, id DESC`
+
+	rows, err := r.getQuerier().Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query trash table: %w", err)
+	}
+	defer rows.Close()
+
+	items := []model.TrashItem{}
+	for rows.Next() {
+		item, err := scanTrashItem(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating trash items: %w", err)
+	}
+	return items, nil
+}
+
+// GetTrashItem retrieves a single trash item including its payload.
+// Returns ErrTrashItemNotFound if no item has the given ID.
+func (r *TrashRepository) GetTrashItem(id string) (model.TrashItem, error) {
+	query := `
+		SELECT id, entity_type, entity_id, label, deleted_at, payload, request_id
+		FROM trash
+		WHERE id = ?
+	`
+	item, err := scanTrashItem(r.getQuerier().QueryRow(query, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.TrashItem{}, apperrors.ErrTrashItemNotFound
+	}
+	return item, err
+}
+
+// DeleteTrashItem permanently removes a trash item.
+// Returns ErrTrashItemNotFound if no item has the given ID.
+func (r *TrashRepository) DeleteTrashItem(ctx context.Context, id string) error {
+	result, err := r.getQuerier().ExecContext(ctx, `DELETE FROM trash WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete trash item: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return apperrors.ErrTrashItemNotFound
+	}
+	return nil
+}
+
+// PurgeTrashBefore permanently removes every trash item deleted before cutoff.
+// Returns the number of items removed.
+func (r *TrashRepository) PurgeTrashBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	trashLog.DebugContext(ctx, "purging trash", "cutoff", cutoff.Format(time.RFC3339))
+	result, err := r.getQuerier().ExecContext(ctx,
+		`DELETE FROM trash WHERE deleted_at < ?`,
+		cutoff.UTC().Format("2006-01-02 15:04:05"),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge trash: %w", err)
+	}
+
+	purged, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return purged, nil
+}
+
+// trashColumn is a column of a snapshotted table.
+type trashColumn struct {
+	name   string
+	isTime bool
+}
+
+// tableColumns returns the columns of table in declaration order.
+func (r *TrashRepository) tableColumns(ctx context.Context, table string) ([]trashColumn, error) {
+	rows, err := r.getQuerier().QueryContext(ctx, `SELECT name, type FROM pragma_table_info(?)`, table)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read columns of %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	var columns []trashColumn
+	for rows.Next() {
+		var name, declType string
+		if err := rows.Scan(&name, &declType); err != nil {
+			return nil, fmt.Errorf("failed to scan column of %s: %w", table, err)
+		}
+		declType = strings.ToUpper(declType)
+		columns = append(columns, trashColumn{
+			name:   name,
+			isTime: strings.Contains(declType, "DATE") || strings.Contains(declType, "TIME"),
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating columns of %s: %w", table, err)
+	}
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("table %s does not exist", table)
+	}
+	return columns, nil
+}
+
+// snapshotValue converts a scanned driver value to its text representation (nil for NULL).
+func snapshotValue(v any) *string {
+	var s string
+	switch val := v.(type) {
+	case nil:
+		return nil
+	case int64:
+		s = strconv.FormatInt(val, 10)
+	case float64:
+		s = strconv.FormatFloat(val, 'g', -1, 64)
+	case bool:
+		s = "0"
+		if val {
+			s = "1"
+		}
+	case []byte:
+		s = string(val)
+	case string:
+		s = val
+	case time.Time:
+		s = val.UTC().Format("2006-01-02 15:04:05")
+	default:
+		s = fmt.Sprint(val)
+	}
+	return &s
+}
+
+// scanTrashItem scans a trash row, decoding its payload and computing per-table row counts.
+func scanTrashItem(row interface{ Scan(...any) error }) (model.TrashItem, error) {
+	var item model.TrashItem
+	var deletedAtStr, payloadStr string
+	var requestID sql.NullString
+
+	if err := row.Scan(
+		&item.ID,
+		&item.EntityType,
+		&item.EntityID,
+		&item.Label,
+		&deletedAtStr,
+		&payloadStr,
+		&requestID,
+	); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.TrashItem{}, err
+		}
+		return model.TrashItem{}, fmt.Errorf("failed to scan trash item: %w", err)
+	}
+
+	var err error
+	item.DeletedAt, err = ParseTime(deletedAtStr)
+	if err != nil {
+		return model.TrashItem{}, fmt.Errorf("failed to parse deleted_at: %w", err)
+	}
+	item.RequestID = requestID.String
+
+	if err := json.Unmarshal([]byte(payloadStr), &item.Payload); err != nil {
+		return model.TrashItem{}, fmt.Errorf("failed to decode trash payload: %w", err)
+	}
+	item.Contents = make(map[string]int, len(item.Payload))
+	for _, t := range item.Payload {
+		item.Contents[t.Table] += len(t.Rows)
+	}
+
+	return item, nil
+}
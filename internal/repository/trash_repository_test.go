@@ -0,0 +1,224 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/apperrors"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/model"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/repository"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/testutil"
+)
+
+//nolint:gocyclo // Test function with multiple subtests and assertions.
+func TestTrashRepository_SnapshotAndRestoreRows(t *testing.T) {
+	t.Run("restored rows are identical to the snapshot", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		repo := repository.NewTrashRepository(db)
+		ctx := context.Background()
+
+		portfolio := testutil.NewPortfolio().Build(t, db)
+		fund := testutil.NewFund().Build(t, db)
+		pf := testutil.NewPortfolioFund(portfolio.ID, fund.ID).Build(t, db)
+		txn := testutil.NewTransaction(pf.ID).
+			WithDate(time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)).
+			WithShares(12.345678901234567).
+			WithCostPerShare(1.0/3).
+			Build(t, db)
+
+		var wantDate string
+		var wantShares, wantCost float64
+		if err := db.QueryRow(`SELECT CAST(date AS TEXT), shares, cost_per_share FROM "transaction" WHERE id = ?`, txn.ID).
+			Scan(&wantDate, &wantShares, &wantCost); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		snapshot, err := repo.SnapshotRows(ctx, "transaction", "id = ?", txn.ID)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if snapshot.Table != "transaction" || len(snapshot.Rows) != 1 {
+			t.Fatalf("expected 1 transaction row, got %+v", snapshot)
+		}
+
+		if _, err := db.Exec(`DELETE FROM "transaction" WHERE id = ?`, txn.ID); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := repo.RestoreRows(ctx, snapshot); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		var gotDate, gotType string
+		var gotShares, gotCost float64
+		if err := db.QueryRow(`SELECT CAST(date AS TEXT), typeof(shares), shares, cost_per_share FROM "transaction" WHERE id = ?`, txn.ID).
+			Scan(&gotDate, &gotType, &gotShares, &gotCost); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if gotDate != wantDate {
+			t.Errorf("expected stored date %q, got %q", wantDate, gotDate)
+		}
+		if gotType != "real" {
+			t.Errorf("expected shares stored as real, got %s", gotType)
+		}
+		if gotShares != wantShares || gotCost != wantCost {
+			t.Errorf("expected %v/%v, got %v/%v", wantShares, wantCost, gotShares, gotCost)
+		}
+	})
+
+	t.Run("NULL columns stay NULL", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		repo := repository.NewTrashRepository(db)
+		ctx := context.Background()
+
+		fund := testutil.NewFund().WithSymbol("").Build(t, db)
+		if _, err := db.Exec(`UPDATE fund SET symbol = NULL WHERE id = ?`, fund.ID); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		snapshot, err := repo.SnapshotRows(ctx, "fund", "id = ?", fund.ID)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if snapshot.Rows[0]["symbol"] != nil {
+			t.Fatalf("expected nil symbol in snapshot, got %q", *snapshot.Rows[0]["symbol"])
+		}
+
+		if _, err := db.Exec(`DELETE FROM fund WHERE id = ?`, fund.ID); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := repo.RestoreRows(ctx, snapshot); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		var isNull bool
+		if err := db.QueryRow(`SELECT symbol IS NULL FROM fund WHERE id = ?`, fund.ID).Scan(&isNull); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !isNull {
+			t.Error("expected restored symbol to be NULL")
+		}
+	})
+
+	t.Run("restoring over existing rows is a conflict", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		repo := repository.NewTrashRepository(db)
+		ctx := context.Background()
+
+		portfolio := testutil.NewPortfolio().Build(t, db)
+		snapshot, err := repo.SnapshotRows(ctx, "portfolio", "id = ?", portfolio.ID)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		err = repo.RestoreRows(ctx, snapshot)
+		if !errors.Is(err, apperrors.ErrTrashRestoreConflict) {
+			t.Errorf("expected ErrTrashRestoreConflict, got %v", err)
+		}
+	})
+
+	t.Run("unknown table", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		repo := repository.NewTrashRepository(db)
+
+		if _, err := repo.SnapshotRows(context.Background(), "nope", "id = ?", "x"); err == nil {
+			t.Error("expected error for unknown table")
+		}
+	})
+}
+
+func newTrashItem(entityType model.TrashEntityType, deletedAt time.Time) *model.TrashItem {
+	entityID := testutil.MakeID()
+	return &model.TrashItem{
+		ID:         testutil.MakeID(),
+		EntityType: string(entityType),
+		EntityID:   entityID,
+		Label:      "Test",
+		DeletedAt:  deletedAt,
+		Payload: []model.TrashTable{
+			{Table: string(entityType), Rows: []map[string]*string{{"id": &entityID}}},
+		},
+	}
+}
+
+//nolint:gocyclo // Test function with multiple subtests and assertions.
+func TestTrashRepository_TrashItems(t *testing.T) {
+	t.Run("insert, list, get and delete", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		repo := repository.NewTrashRepository(db)
+		ctx := context.Background()
+		now := time.Now().UTC().Truncate(time.Second)
+
+		older := newTrashItem(model.TrashEntityPortfolio, now.Add(-time.Hour))
+		newer := newTrashItem(model.TrashEntityFund, now)
+		for _, item := range []*model.TrashItem{older, newer} {
+			if err := repo.InsertTrashItem(ctx, item); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+
+		items, err := repo.GetTrashItems("")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(items) != 2 || items[0].ID != newer.ID {
+			t.Fatalf("expected 2 items newest first, got %+v", items)
+		}
+		if items[0].Contents["fund"] != 1 {
+			t.Errorf("expected contents to count 1 fund row, got %v", items[0].Contents)
+		}
+
+		items, err = repo.GetTrashItems("portfolio")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(items) != 1 || items[0].ID != older.ID {
+			t.Errorf("expected only the portfolio item, got %+v", items)
+		}
+
+		got, err := repo.GetTrashItem(older.ID)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !got.DeletedAt.Equal(older.DeletedAt) || len(got.Payload) != 1 {
+			t.Errorf("unexpected item: %+v", got)
+		}
+
+		if err := repo.DeleteTrashItem(ctx, older.ID); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if _, err := repo.GetTrashItem(older.ID); !errors.Is(err, apperrors.ErrTrashItemNotFound) {
+			t.Errorf("expected ErrTrashItemNotFound, got %v", err)
+		}
+		if err := repo.DeleteTrashItem(ctx, older.ID); !errors.Is(err, apperrors.ErrTrashItemNotFound) {
+			t.Errorf("expected ErrTrashItemNotFound on second delete, got %v", err)
+		}
+	})
+
+	t.Run("purge removes only items deleted before the cutoff", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		repo := repository.NewTrashRepository(db)
+		ctx := context.Background()
+		now := time.Now().UTC()
+
+		expired := newTrashItem(model.TrashEntityTransaction, now.Add(-40*24*time.Hour))
+		recent := newTrashItem(model.TrashEntityTransaction, now.Add(-time.Hour))
+		for _, item := range []*model.TrashItem{expired, recent} {
+			if err := repo.InsertTrashItem(ctx, item); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+
+		purged, err := repo.PurgeTrashBefore(ctx, now.Add(-30*24*time.Hour))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if purged != 1 {
+			t.Errorf("expected 1 purged item, got %d", purged)
+		}
+		if _, err := repo.GetTrashItem(recent.ID); err != nil {
+			t.Errorf("expected recent item to remain, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,474 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/apperrors"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/logging"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/model"
+)
+
+var userLog = logging.NewLogger("security")
+
+// UserRepository provides data access methods for user accounts, login sessions
+// and the portfolio ownership and share tables that decide portfolio access.
+type UserRepository struct {
+	db *sql.DB
+	tx *sql.Tx
+}
+
+// NewUserRepository creates a new UserRepository with the provided database connection.
+func NewUserRepository(db *sql.DB) *UserRepository {
+	return &UserRepository{db: db}
+}
+
+// WithTx returns a new UserRepository scoped to the provided transaction.
+func (r *UserRepository) WithTx(tx *sql.Tx) *UserRepository {
+	return &UserRepository{
+		db: r.db,
+		tx: tx,
+	}
+}
+
+// getQuerier returns the active transaction if one is set, otherwise the database connection.
+func (r *UserRepository) getQuerier() Querier {
+	if r.tx != nil {
+		return traced(r.tx)
+	}
+	return traced(r.db)
+}
+
+// CountUsers returns the number of user accounts.
+func (r *UserRepository) CountUsers() (int, error) {
+	var count int
+	if err := r.getQuerier().QueryRow(`SELECT COUNT(*) FROM user_account`).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count users: %w", err)
+	}
+	return count, nil
+}
+
+// GetUsers retrieves all user accounts ordered by username.
+func (r *UserRepository) GetUsers() ([]model.User, error) {
+	userLog.Debug("getting users")
+
+	rows, err := r.getQuerier().Query(`
+		SELECT id, username, password_hash, is_admin, created_at, last_login_at
+		FROM user_account
+		ORDER BY username ASC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query user_account table: %w", err)
+	}
+	defer rows.Close()
+
+	users := []model.User{}
+	for rows.Next() {
+		u, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, u)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating user_account table: %w", err)
+	}
+
+	return users, nil
+}
+
+// GetUserByID retrieves a single user account. Returns ErrUserNotFound if it does not exist.
+func (r *UserRepository) GetUserByID(userID string) (model.User, error) {
+	return r.getUser(`WHERE id = ?`, userID)
+}
+
+// GetUserByUsername retrieves a single user account by its (lower-case) username.
+// Returns ErrUserNotFound if it does not exist.
+func (r *UserRepository) GetUserByUsername(username string) (model.User, error) {
+	return r.getUser(`WHERE username = ?`, username)
+}
+
+func (r *UserRepository) getUser(where string, arg string) (model.User, error) {
+	//nolint:gosec // G202: where is a constant supplied by the caller, the value is parameterized
+	row := r.getQuerier().QueryRow(`
+		SELECT id, username, password_hash, is_admin, created_at, last_login_at
+		FROM user_account
+		`+where, arg)
+
+	u, err := scanUser(row)
+	if err == sql.ErrNoRows {
+		return model.User{}, apperrors.ErrUserNotFound
+	}
+	if err != nil {
+		return model.User{}, err
+	}
+	return u, nil
+}
+
+// scanner is satisfied by both *sql.Row and *sql.Rows.
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanUser(s scanner) (model.User, error) {
+	var u model.User
+	var createdAtStr string
+	var lastLoginStr sql.NullString
+
+	if err := s.Scan(&u.ID, &u.Username, &u.PasswordHash, &u.IsAdmin, &createdAtStr, &lastLoginStr); err != nil {
+		if err == sql.ErrNoRows {
+			return model.User{}, err
+		}
+		return model.User{}, fmt.Errorf("failed to scan user: %w", err)
+	}
+
+	var err error
+	u.CreatedAt, err = ParseTime(createdAtStr)
+	if err != nil {
+		return model.User{}, fmt.Errorf("failed to parse created_at: %w", err)
+	}
+	if lastLoginStr.Valid {
+		t, err := ParseTime(lastLoginStr.String)
+		if err != nil {
+			return model.User{}, fmt.Errorf("failed to parse last_login_at: %w", err)
+		}
+		u.LastLoginAt = &t
+	}
+
+	return u, nil
+}
+
+// InsertUser inserts a new user account. Returns ErrUsernameTaken if the username is in use.
+func (r *UserRepository) InsertUser(ctx context.Context, u *model.User) error {
+	userLog.DebugContext(ctx, "inserting user", "user_id", u.ID, "username", u.Username)
+
+	_, err := r.getQuerier().ExecContext(ctx, `
+		INSERT INTO user_account (id, username, password_hash, is_admin, created_at)
+		VALUES (?, ?, ?, ?, ?)
+	`, u.ID, u.Username, u.PasswordHash, u.IsAdmin, u.CreatedAt.UTC().Format("2006-01-02 15:04:05"))
+	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE constraint failed") {
+			return apperrors.ErrUsernameTaken
+		}
+		return fmt.Errorf("failed to insert user: %w", err)
+	}
+	return nil
+}
+
+// UpdatePasswordHash replaces a user's password hash.
+func (r *UserRepository) UpdatePasswordHash(ctx context.Context, userID, passwordHash string) error {
+	result, err := r.getQuerier().ExecContext(ctx,
+		`UPDATE user_account SET password_hash = ? WHERE id = ?`, passwordHash, userID)
+	if err != nil {
+		return fmt.Errorf("failed to update password: %w", err)
+	}
+	return requireAffected(result, apperrors.ErrUserNotFound)
+}
+
+// UpdateLastLogin records the time of a user's most recent successful login.
+func (r *UserRepository) UpdateLastLogin(ctx context.Context, userID string, at time.Time) error {
+	_, err := r.getQuerier().ExecContext(ctx,
+		`UPDATE user_account SET last_login_at = ? WHERE id = ?`, at.UTC().Format("2006-01-02 15:04:05"), userID)
+	if err != nil {
+		return fmt.Errorf("failed to update last login: %w", err)
+	}
+	return nil
+}
+
+// DeleteUser removes a user account. Sessions and shares are removed by cascade.
+func (r *UserRepository) DeleteUser(ctx context.Context, userID string) error {
+	userLog.DebugContext(ctx, "deleting user", "user_id", userID)
+
+	result, err := r.getQuerier().ExecContext(ctx, `DELETE FROM user_account WHERE id = ?`, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete user: %w", err)
+	}
+	return requireAffected(result, apperrors.ErrUserNotFound)
+}
+
+// InsertSession stores a new login session.
+func (r *UserRepository) InsertSession(ctx context.Context, s *model.Session) error {
+	_, err := r.getQuerier().ExecContext(ctx, `
+		INSERT INTO user_session (id, user_id, token_hash, created_at, expires_at)
+		VALUES (?, ?, ?, ?, ?)
+	`, s.ID, s.UserID, s.TokenHash,
+		s.CreatedAt.UTC().Format("2006-01-02 15:04:05"),
+		s.ExpiresAt.UTC().Format("2006-01-02 15:04:05"))
+	if err != nil {
+		return fmt.Errorf("failed to insert session: %w", err)
+	}
+	return nil
+}
+
+// GetSessionByTokenHash retrieves the unexpired session with the given token hash.
+// Returns ErrSessionInvalid if no such session exists or it has expired.
+func (r *UserRepository) GetSessionByTokenHash(tokenHash string, now time.Time) (model.Session, error) {
+	var s model.Session
+	var createdAtStr, expiresAtStr string
+
+	err := r.getQuerier().QueryRow(`
+		SELECT id, user_id, token_hash, created_at, expires_at
+		FROM user_session
+		WHERE token_hash = ? AND expires_at > ?
+	`, tokenHash, now.UTC().Format("2006-01-02 15:04:05")).Scan(
+		&s.ID, &s.UserID, &s.TokenHash, &createdAtStr, &expiresAtStr,
+	)
+	if err == sql.ErrNoRows {
+		return model.Session{}, apperrors.ErrSessionInvalid
+	}
+	if err != nil {
+		return model.Session{}, fmt.Errorf("failed to query session: %w", err)
+	}
+
+	if s.CreatedAt, err = ParseTime(createdAtStr); err != nil {
+		return model.Session{}, fmt.Errorf("failed to parse created_at: %w", err)
+	}
+	if s.ExpiresAt, err = ParseTime(expiresAtStr); err != nil {
+		return model.Session{}, fmt.Errorf("failed to parse expires_at: %w", err)
+	}
+
+	return s, nil
+}
+
+// DeleteSession removes the session with the given token hash. Deleting an unknown session is not an error.
+func (r *UserRepository) DeleteSession(ctx context.Context, tokenHash string) error {
+	if _, err := r.getQuerier().ExecContext(ctx, `DELETE FROM user_session WHERE token_hash = ?`, tokenHash); err != nil {
+		return fmt.Errorf("failed to delete session: %w", err)
+	}
+	return nil
+}
+
+// DeleteUserSessions removes every session belonging to a user, except the one with keepTokenHash
+// (pass an empty string to remove them all).
+func (r *UserRepository) DeleteUserSessions(ctx context.Context, userID, keepTokenHash string) error {
+	if _, err := r.getQuerier().ExecContext(ctx,
+		`DELETE FROM user_session WHERE user_id = ? AND token_hash != ?`, userID, keepTokenHash); err != nil {
+		return fmt.Errorf("failed to delete user sessions: %w", err)
+	}
+	return nil
+}
+
+// DeleteExpiredSessions removes all sessions that expired before now and returns how many were removed.
+func (r *UserRepository) DeleteExpiredSessions(ctx context.Context, now time.Time) (int64, error) {
+	result, err := r.getQuerier().ExecContext(ctx,
+		`DELETE FROM user_session WHERE expires_at <= ?`, now.UTC().Format("2006-01-02 15:04:05"))
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired sessions: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// AssignUnownedPortfolios makes userID the owner of every portfolio without an owner
+// and returns how many portfolios were claimed.
+func (r *UserRepository) AssignUnownedPortfolios(ctx context.Context, userID string) (int64, error) {
+	result, err := r.getQuerier().ExecContext(ctx,
+		`UPDATE portfolio SET owner_id = ? WHERE owner_id IS NULL`, userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to assign unowned portfolios: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// TransferPortfolios moves ownership of every portfolio owned by fromUserID to toUserID.
+// Any share toUserID held on those portfolios becomes redundant and is removed.
+func (r *UserRepository) TransferPortfolios(ctx context.Context, fromUserID, toUserID string) error {
+	if _, err := r.getQuerier().ExecContext(ctx, `
+		DELETE FROM portfolio_share
+		WHERE user_id = ? AND portfolio_id IN (SELECT id FROM portfolio WHERE owner_id = ?)
+	`, toUserID, fromUserID); err != nil {
+		return fmt.Errorf("failed to remove redundant shares: %w", err)
+	}
+	if _, err := r.getQuerier().ExecContext(ctx,
+		`UPDATE portfolio SET owner_id = ? WHERE owner_id = ?`, toUserID, fromUserID); err != nil {
+		return fmt.Errorf("failed to transfer portfolios: %w", err)
+	}
+	return nil
+}
+
+// GetPortfolioAccess returns the access level userID has to each portfolio it owns or
+// has been shared, keyed by portfolio ID.
+func (r *UserRepository) GetPortfolioAccess(userID string) (map[string]model.PortfolioAccess, error) {
+	rows, err := r.getQuerier().Query(`
+		SELECT id, 'owner' FROM portfolio WHERE owner_id = ?
+		UNION ALL
+		SELECT portfolio_id, access FROM portfolio_share WHERE user_id = ?
+	`, userID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query portfolio access: %w", err)
+	}
+	defer rows.Close()
+
+	access := make(map[string]model.PortfolioAccess)
+	for rows.Next() {
+		var portfolioID, level string
+		if err := rows.Scan(&portfolioID, &level); err != nil {
+			return nil, fmt.Errorf("failed to scan portfolio access: %w", err)
+		}
+		// Ownership wins over any share left behind on the same portfolio.
+		if current, ok := access[portfolioID]; !ok || !current.Allows(model.PortfolioAccess(level)) {
+			access[portfolioID] = model.PortfolioAccess(level)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating portfolio access: %w", err)
+	}
+
+	return access, nil
+}
+
+// GetPortfolioFundOwners maps every portfolio_fund ID in the given portfolios to its portfolio ID.
+func (r *UserRepository) GetPortfolioFundOwners(portfolioIDs []string) (map[string]string, error) {
+	owners := make(map[string]string)
+	if len(portfolioIDs) == 0 {
+		return owners, nil
+	}
+
+	args := make([]any, len(portfolioIDs))
+	for i, id := range portfolioIDs {
+		args[i] = id
+	}
+
+	//nolint:gosec // G202: only placeholders are concatenated
+	rows, err := r.getQuerier().Query(`
+		SELECT id, portfolio_id FROM portfolio_fund
+		WHERE portfolio_id IN (`+placeholders(len(portfolioIDs))+`)
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query portfolio_fund table: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var pfID, portfolioID string
+		if err := rows.Scan(&pfID, &portfolioID); err != nil {
+			return nil, fmt.Errorf("failed to scan portfolio_fund table results: %w", err)
+		}
+		owners[pfID] = portfolioID
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating portfolio_fund table: %w", err)
+	}
+
+	return owners, nil
+}
+
+// GetPortfolioIDForTransaction returns the portfolio a transaction belongs to.
+// Returns ErrTransactionNotFound if the transaction does not exist.
+func (r *UserRepository) GetPortfolioIDForTransaction(transactionID string) (string, error) {
+	return r.portfolioIDFor(`
+		SELECT pf.portfolio_id FROM "transaction" t
+		INNER JOIN portfolio_fund pf ON pf.id = t.portfolio_fund_id
+		WHERE t.id = ?
+	`, transactionID, apperrors.ErrTransactionNotFound)
+}
+
+// GetPortfolioIDForInvestmentPlan returns the portfolio an investment plan belongs to.
+// Returns ErrInvestmentPlanNotFound if the plan does not exist.
+func (r *UserRepository) GetPortfolioIDForInvestmentPlan(planID string) (string, error) {
+	return r.portfolioIDFor(`
+		SELECT pf.portfolio_id FROM investment_plan p
+		INNER JOIN portfolio_fund pf ON pf.id = p.portfolio_fund_id
+		WHERE p.id = ?
+	`, planID, apperrors.ErrInvestmentPlanNotFound)
+}
+
+// GetPortfolioIDForDividend returns the portfolio a dividend belongs to.
+// Returns ErrDividendNotFound if the dividend does not exist.
+func (r *UserRepository) GetPortfolioIDForDividend(dividendID string) (string, error) {
+	return r.portfolioIDFor(`
+		SELECT pf.portfolio_id FROM dividend d
+		INNER JOIN portfolio_fund pf ON pf.id = d.portfolio_fund_id
+		WHERE d.id = ?
+	`, dividendID, apperrors.ErrDividendNotFound)
+}
+
+// GetPortfolioIDForPortfolioFund returns the portfolio a portfolio_fund row belongs to.
+// Returns ErrPortfolioFundNotFound if the row does not exist.
+func (r *UserRepository) GetPortfolioIDForPortfolioFund(portfolioFundID string) (string, error) {
+	return r.portfolioIDFor(`SELECT portfolio_id FROM portfolio_fund WHERE id = ?`,
+		portfolioFundID, apperrors.ErrPortfolioFundNotFound)
+}
+
+func (r *UserRepository) portfolioIDFor(query, id string, notFound error) (string, error) {
+	var portfolioID string
+	err := r.getQuerier().QueryRow(query, id).Scan(&portfolioID)
+	if err == sql.ErrNoRows {
+		return "", notFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve portfolio: %w", err)
+	}
+	return portfolioID, nil
+}
+
+// GetPortfolioShares retrieves the users a portfolio is shared with, ordered by username.
+func (r *UserRepository) GetPortfolioShares(portfolioID string) ([]model.PortfolioShare, error) {
+	rows, err := r.getQuerier().Query(`
+		SELECT s.portfolio_id, s.user_id, u.username, s.access, s.created_at
+		FROM portfolio_share s
+		INNER JOIN user_account u ON u.id = s.user_id
+		WHERE s.portfolio_id = ?
+		ORDER BY u.username ASC
+	`, portfolioID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query portfolio_share table: %w", err)
+	}
+	defer rows.Close()
+
+	shares := []model.PortfolioShare{}
+	for rows.Next() {
+		var s model.PortfolioShare
+		var createdAtStr string
+		if err := rows.Scan(&s.PortfolioID, &s.UserID, &s.Username, &s.Access, &createdAtStr); err != nil {
+			return nil, fmt.Errorf("failed to scan portfolio_share table results: %w", err)
+		}
+		if s.CreatedAt, err = ParseTime(createdAtStr); err != nil {
+			return nil, fmt.Errorf("failed to parse created_at: %w", err)
+		}
+		shares = append(shares, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating portfolio_share table: %w", err)
+	}
+
+	return shares, nil
+}
+
+// UpsertPortfolioShare grants or changes a user's access to a portfolio.
+func (r *UserRepository) UpsertPortfolioShare(ctx context.Context, s *model.PortfolioShare) error {
+	_, err := r.getQuerier().ExecContext(ctx, `
+		INSERT INTO portfolio_share (portfolio_id, user_id, access, created_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT (portfolio_id, user_id) DO UPDATE SET access = excluded.access
+	`, s.PortfolioID, s.UserID, s.Access, s.CreatedAt.UTC().Format("2006-01-02 15:04:05"))
+	if err != nil {
+		return fmt.Errorf("failed to upsert portfolio share: %w", err)
+	}
+	return nil
+}
+
+// DeletePortfolioShare revokes a user's access to a portfolio.
+// Returns ErrPortfolioShareNotFound if the portfolio was not shared with the user.
+func (r *UserRepository) DeletePortfolioShare(ctx context.Context, portfolioID, userID string) error {
+	result, err := r.getQuerier().ExecContext(ctx,
+		`DELETE FROM portfolio_share WHERE portfolio_id = ? AND user_id = ?`, portfolioID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete portfolio share: %w", err)
+	}
+	return requireAffected(result, apperrors.ErrPortfolioShareNotFound)
+}
+
+// requireAffected returns notFound when a statement changed no rows.
+func requireAffected(result sql.Result, notFound error) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return notFound
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/apperrors"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/model"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/repository"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/testutil"
+)
+
+// insertTestUser inserts a user with the given username and returns it.
+func insertTestUser(t *testing.T, repo *repository.UserRepository, username string) model.User {
+	t.Helper()
+	u := model.User{
+		ID:           testutil.MakeID(),
+		Username:     username,
+		PasswordHash: "hash",
+		CreatedAt:    time.Now().UTC(),
+	}
+	if err := repo.InsertUser(context.Background(), &u); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return u
+}
+
+func TestUserRepository_Users(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	repo := repository.NewUserRepository(db)
+
+	alice := insertTestUser(t, repo, "alice")
+
+	t.Run("duplicate username", func(t *testing.T) {
+		u := model.User{ID: testutil.MakeID(), Username: "alice", PasswordHash: "hash", CreatedAt: time.Now()}
+		if err := repo.InsertUser(context.Background(), &u); !errors.Is(err, apperrors.ErrUsernameTaken) {
+			t.Errorf("expected ErrUsernameTaken, got %v", err)
+		}
+	})
+
+	t.Run("lookup by username", func(t *testing.T) {
+		got, err := repo.GetUserByUsername("alice")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got.ID != alice.ID || got.PasswordHash != "hash" {
+			t.Errorf("unexpected user: %+v", got)
+		}
+		if _, err := repo.GetUserByUsername("bob"); !errors.Is(err, apperrors.ErrUserNotFound) {
+			t.Errorf("expected ErrUserNotFound, got %v", err)
+		}
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		if err := repo.DeleteUser(context.Background(), alice.ID); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := repo.DeleteUser(context.Background(), alice.ID); !errors.Is(err, apperrors.ErrUserNotFound) {
+			t.Errorf("expected ErrUserNotFound, got %v", err)
+		}
+	})
+}
+
+func TestUserRepository_Sessions(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	repo := repository.NewUserRepository(db)
+	ctx := context.Background()
+	user := insertTestUser(t, repo, "alice")
+	now := time.Now().UTC()
+
+	for _, s := range []model.Session{
+		{ID: testutil.MakeID(), UserID: user.ID, TokenHash: "live", CreatedAt: now, ExpiresAt: now.Add(time.Hour)},
+		{ID: testutil.MakeID(), UserID: user.ID, TokenHash: "expired", CreatedAt: now, ExpiresAt: now.Add(-time.Hour)},
+	} {
+		if err := repo.InsertSession(ctx, &s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if _, err := repo.GetSessionByTokenHash("live", now); err != nil {
+		t.Errorf("expected live session, got %v", err)
+	}
+	if _, err := repo.GetSessionByTokenHash("expired", now); !errors.Is(err, apperrors.ErrSessionInvalid) {
+		t.Errorf("expected ErrSessionInvalid for expired session, got %v", err)
+	}
+
+	purged, err := repo.DeleteExpiredSessions(ctx, now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if purged != 1 {
+		t.Errorf("expected 1 purged session, got %d", purged)
+	}
+}
+
+func TestUserRepository_PortfolioAccess(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	repo := repository.NewUserRepository(db)
+	ctx := context.Background()
+
+	alice := insertTestUser(t, repo, "alice")
+	bob := insertTestUser(t, repo, "bob")
+	owned := testutil.NewPortfolio().Build(t, db)
+	shared := testutil.NewPortfolio().Build(t, db)
+	testutil.NewPortfolio().Build(t, db)
+	fund := testutil.NewFund().Build(t, db)
+	pf := testutil.NewPortfolioFund(shared.ID, fund.ID).Build(t, db)
+
+	if _, err := repo.AssignUnownedPortfolios(ctx, bob.ID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := db.Exec(`UPDATE portfolio SET owner_id = ? WHERE id = ?`, alice.ID, owned.ID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	share := &model.PortfolioShare{PortfolioID: shared.ID, UserID: alice.ID, Access: model.PortfolioAccessRead, CreatedAt: time.Now()}
+	if err := repo.UpsertPortfolioShare(ctx, share); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	access, err := repo.GetPortfolioAccess(alice.ID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(access) != 2 || access[owned.ID] != model.PortfolioAccessOwner || access[shared.ID] != model.PortfolioAccessRead {
+		t.Errorf("unexpected access map: %+v", access)
+	}
+
+	owners, err := repo.GetPortfolioFundOwners([]string{shared.ID})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if owners[pf.ID] != shared.ID {
+		t.Errorf("expected portfolio fund %s to map to %s, got %+v", pf.ID, shared.ID, owners)
+	}
+
+	// Transferring bob's portfolios to alice removes her now-redundant share.
+	if err := repo.TransferPortfolios(ctx, bob.ID, alice.ID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	shares, err := repo.GetPortfolioShares(shared.ID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(shares) != 0 {
+		t.Errorf("expected redundant share to be removed, got %+v", shares)
+	}
+	access, err = repo.GetPortfolioAccess(alice.ID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(access) != 3 || access[shared.ID] != model.PortfolioAccessOwner {
+		t.Errorf("expected ownership of all portfolios, got %+v", access)
+	}
+}
@@ -0,0 +1,334 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/apperrors"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/model"
+)
+
+// webhookColumns is the column list shared by every webhook SELECT.
+const webhookColumns = `id, url, event_types, secret, enabled, created_at, updated_at`
+
+// webhookDeliveryColumns is the column list shared by every webhook delivery SELECT.
+const webhookDeliveryColumns = `id, webhook_id, event_type, event_key, payload, status, attempts,
+	response_status, last_error, created_at, next_attempt_at, delivered_at`
+
+// WebhookRepository provides data access methods for webhook subscriptions and their deliveries.
+type WebhookRepository struct {
+	db *sql.DB
+	tx *sql.Tx
+}
+
+// NewWebhookRepository creates a new WebhookRepository with the provided database connection.
+func NewWebhookRepository(db *sql.DB) *WebhookRepository {
+	return &WebhookRepository{db: db}
+}
+
+// WithTx returns a new WebhookRepository scoped to the provided transaction.
+func (r *WebhookRepository) WithTx(tx *sql.Tx) *WebhookRepository {
+	return &WebhookRepository{
+		db: r.db,
+		tx: tx,
+	}
+}
+
+// getQuerier returns the active transaction if one is set, otherwise the database connection.
+func (r *WebhookRepository) getQuerier() Querier {
+	if r.tx != nil {
+		return traced(r.tx)
+	}
+	return traced(r.db)
+}
+
+// GetWebhooks retrieves all webhooks, oldest first. Secrets are included.
+func (r *WebhookRepository) GetWebhooks() ([]model.Webhook, error) {
+	rows, err := r.getQuerier().Query(`SELECT ` + webhookColumns + ` FROM webhook ORDER BY created_at, id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query webhooks: %w", err)
+	}
+	defer rows.Close()
+
+	webhooks := []model.Webhook{}
+	for rows.Next() {
+		w, err := scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, w)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating webhooks: %w", err)
+	}
+	return webhooks, nil
+}
+
+// GetWebhook retrieves a webhook by ID, including its secret.
+// Returns ErrWebhookNotFound if it does not exist.
+func (r *WebhookRepository) GetWebhook(webhookID string) (model.Webhook, error) {
+	row := r.getQuerier().QueryRow(`SELECT `+webhookColumns+` FROM webhook WHERE id = ?`, webhookID)
+
+	w, err := scanWebhook(row)
+	if err == sql.ErrNoRows {
+		return model.Webhook{}, apperrors.ErrWebhookNotFound
+	}
+	if err != nil {
+		return model.Webhook{}, err
+	}
+	return w, nil
+}
+
+func scanWebhook(s scanner) (model.Webhook, error) {
+	var w model.Webhook
+	var eventTypes, createdAtStr, updatedAtStr string
+
+	if err := s.Scan(&w.ID, &w.URL, &eventTypes, &w.Secret, &w.Enabled, &createdAtStr, &updatedAtStr); err != nil {
+		if err == sql.ErrNoRows {
+			return model.Webhook{}, err
+		}
+		return model.Webhook{}, fmt.Errorf("failed to scan webhook: %w", err)
+	}
+
+	w.EventTypes = []model.WebhookEvent{}
+	for _, event := range strings.Fields(eventTypes) {
+		w.EventTypes = append(w.EventTypes, model.WebhookEvent(event))
+	}
+
+	var err error
+	if w.CreatedAt, err = ParseTime(createdAtStr); err != nil {
+		return model.Webhook{}, fmt.Errorf("failed to parse created_at: %w", err)
+	}
+	if w.UpdatedAt, err = ParseTime(updatedAtStr); err != nil {
+		return model.Webhook{}, fmt.Errorf("failed to parse updated_at: %w", err)
+	}
+	return w, nil
+}
+
+func joinWebhookEvents(events []model.WebhookEvent) string {
+	names := make([]string, len(events))
+	for i, e := range events {
+		names[i] = string(e)
+	}
+	return strings.Join(names, " ")
+}
+
+// InsertWebhook stores a new webhook.
+func (r *WebhookRepository) InsertWebhook(ctx context.Context, w *model.Webhook) error {
+	_, err := r.getQuerier().ExecContext(ctx, `
+		INSERT INTO webhook (id, url, event_types, secret, enabled, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, w.ID, w.URL, joinWebhookEvents(w.EventTypes), w.Secret, w.Enabled,
+		w.CreatedAt.UTC().Format("2006-01-02 15:04:05"), w.UpdatedAt.UTC().Format("2006-01-02 15:04:05"))
+	if err != nil {
+		return fmt.Errorf("failed to insert webhook: %w", err)
+	}
+	return nil
+}
+
+// UpdateWebhook replaces the URL, event types, secret and enabled flag of a webhook.
+// Returns ErrWebhookNotFound if it does not exist.
+func (r *WebhookRepository) UpdateWebhook(ctx context.Context, w *model.Webhook) error {
+	result, err := r.getQuerier().ExecContext(ctx, `
+		UPDATE webhook SET url = ?, event_types = ?, secret = ?, enabled = ?, updated_at = ?
+		WHERE id = ?
+	`, w.URL, joinWebhookEvents(w.EventTypes), w.Secret, w.Enabled, w.UpdatedAt.UTC().Format("2006-01-02 15:04:05"), w.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update webhook: %w", err)
+	}
+	return requireAffected(result, apperrors.ErrWebhookNotFound)
+}
+
+// DeleteWebhook removes a webhook and its deliveries. Returns ErrWebhookNotFound if it does not exist.
+func (r *WebhookRepository) DeleteWebhook(ctx context.Context, webhookID string) error {
+	result, err := r.getQuerier().ExecContext(ctx, `DELETE FROM webhook WHERE id = ?`, webhookID)
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+	return requireAffected(result, apperrors.ErrWebhookNotFound)
+}
+
+// InsertDelivery queues a delivery. A delivery whose event key was already queued for the
+// same webhook is not inserted; the returned boolean reports whether it was.
+func (r *WebhookRepository) InsertDelivery(ctx context.Context, d *model.WebhookDelivery) (bool, error) {
+	var eventKey, nextAttempt any
+	if d.EventKey != "" {
+		eventKey = d.EventKey
+	}
+	if d.NextAttemptAt != nil {
+		nextAttempt = d.NextAttemptAt.UTC().Format("2006-01-02 15:04:05")
+	}
+
+	result, err := r.getQuerier().ExecContext(ctx, `
+		INSERT INTO webhook_delivery (id, webhook_id, event_type, event_key, payload, status, attempts, created_at, next_attempt_at)
+		VALUES (?, ?, ?, ?, ?, ?, 0, ?, ?)
+		ON CONFLICT(webhook_id, event_key) DO NOTHING
+	`, d.ID, d.WebhookID, d.EventType, eventKey, string(d.Payload), d.Status,
+		d.CreatedAt.UTC().Format("2006-01-02 15:04:05"), nextAttempt)
+	if err != nil {
+		return false, fmt.Errorf("failed to insert webhook delivery: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return n > 0, nil
+}
+
+// GetDeliveries retrieves the most recent deliveries of a webhook, newest first.
+func (r *WebhookRepository) GetDeliveries(webhookID string, limit int) ([]model.WebhookDelivery, error) {
+	rows, err := r.getQuerier().Query(`
+		SELECT `+webhookDeliveryColumns+`
+		FROM webhook_delivery
+		WHERE webhook_id = ?
+		ORDER BY created_at DESC, id DESC
+		LIMIT ?
+	`, webhookID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	deliveries := []model.WebhookDelivery{}
+	for rows.Next() {
+		d, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating webhook deliveries: %w", err)
+	}
+	return deliveries, nil
+}
+
+// GetDelivery retrieves a delivery by ID. Returns ErrWebhookDeliveryNotFound if it does not exist.
+func (r *WebhookRepository) GetDelivery(deliveryID string) (model.WebhookDelivery, error) {
+	row := r.getQuerier().QueryRow(`SELECT `+webhookDeliveryColumns+` FROM webhook_delivery WHERE id = ?`, deliveryID)
+
+	d, err := scanWebhookDelivery(row)
+	if err == sql.ErrNoRows {
+		return model.WebhookDelivery{}, apperrors.ErrWebhookDeliveryNotFound
+	}
+	if err != nil {
+		return model.WebhookDelivery{}, err
+	}
+	return d, nil
+}
+
+// GetDueDelivery returns the pending delivery that has been due the longest, if any.
+func (r *WebhookRepository) GetDueDelivery(at time.Time) (delivery model.WebhookDelivery, ok bool, err error) {
+	row := r.getQuerier().QueryRow(`
+		SELECT `+webhookDeliveryColumns+`
+		FROM webhook_delivery
+		WHERE status = ? AND next_attempt_at <= ?
+		ORDER BY next_attempt_at, created_at
+		LIMIT 1
+	`, model.WebhookDeliveryPending, at.UTC().Format("2006-01-02 15:04:05"))
+
+	delivery, err = scanWebhookDelivery(row)
+	if err == sql.ErrNoRows {
+		return model.WebhookDelivery{}, false, nil
+	}
+	if err != nil {
+		return model.WebhookDelivery{}, false, err
+	}
+	return delivery, true, nil
+}
+
+// RecordDeliveryAttempt stores the outcome of a delivery attempt. nextAttempt is the time
+// of the next retry for a delivery that stays pending and nil otherwise; deliveredAt is
+// set for a successful delivery.
+func (r *WebhookRepository) RecordDeliveryAttempt(
+	ctx context.Context,
+	deliveryID string,
+	status model.WebhookDeliveryStatus,
+	responseStatus int,
+	errMsg string,
+	nextAttempt, deliveredAt *time.Time,
+) error {
+	var respStatus, lastError, next, delivered any
+	if responseStatus != 0 {
+		respStatus = responseStatus
+	}
+	if errMsg != "" {
+		lastError = errMsg
+	}
+	if nextAttempt != nil {
+		next = nextAttempt.UTC().Format("2006-01-02 15:04:05")
+	}
+	if deliveredAt != nil {
+		delivered = deliveredAt.UTC().Format("2006-01-02 15:04:05")
+	}
+
+	_, err := r.getQuerier().ExecContext(ctx, `
+		UPDATE webhook_delivery
+		SET status = ?, attempts = attempts + 1, response_status = ?, last_error = ?, next_attempt_at = ?, delivered_at = ?
+		WHERE id = ?
+	`, status, respStatus, lastError, next, delivered, deliveryID)
+	if err != nil {
+		return fmt.Errorf("failed to record webhook delivery attempt: %w", err)
+	}
+	return nil
+}
+
+// DeleteFinishedDeliveries removes succeeded and failed deliveries created before the
+// given time. Returns the number of deliveries removed.
+func (r *WebhookRepository) DeleteFinishedDeliveries(ctx context.Context, before time.Time) (int64, error) {
+	result, err := r.getQuerier().ExecContext(ctx, `
+		DELETE FROM webhook_delivery WHERE status != ? AND created_at < ?
+	`, model.WebhookDeliveryPending, before.UTC().Format("2006-01-02 15:04:05"))
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete webhook deliveries: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+func scanWebhookDelivery(s scanner) (model.WebhookDelivery, error) {
+	var d model.WebhookDelivery
+	var payload, createdAtStr string
+	var eventKey, lastError, nextAttemptStr, deliveredAtStr sql.NullString
+	var responseStatus sql.NullInt64
+
+	if err := s.Scan(&d.ID, &d.WebhookID, &d.EventType, &eventKey, &payload, &d.Status, &d.Attempts,
+		&responseStatus, &lastError, &createdAtStr, &nextAttemptStr, &deliveredAtStr); err != nil {
+		if err == sql.ErrNoRows {
+			return model.WebhookDelivery{}, err
+		}
+		return model.WebhookDelivery{}, fmt.Errorf("failed to scan webhook delivery: %w", err)
+	}
+	d.EventKey = eventKey.String
+	d.Payload = []byte(payload)
+	d.LastError = lastError.String
+	if responseStatus.Valid {
+		status := int(responseStatus.Int64)
+		d.ResponseStatus = &status
+	}
+
+	var err error
+	if d.CreatedAt, err = ParseTime(createdAtStr); err != nil {
+		return model.WebhookDelivery{}, fmt.Errorf("failed to parse created_at: %w", err)
+	}
+	for _, col := range []struct {
+		name string
+		src  sql.NullString
+		dst  **time.Time
+	}{
+		{"next_attempt_at", nextAttemptStr, &d.NextAttemptAt},
+		{"delivered_at", deliveredAtStr, &d.DeliveredAt},
+	} {
+		if !col.src.Valid {
+			continue
+		}
+		parsed, err := ParseTime(col.src.String)
+		if err != nil {
+			return model.WebhookDelivery{}, fmt.Errorf("failed to parse %s: %w", col.name, err)
+		}
+		*col.dst = &parsed
+	}
+	return d, nil
+}
@@ -0,0 +1,180 @@
+package repository_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/apperrors"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/model"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/repository"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/testutil"
+)
+
+func insertTestWebhook(t *testing.T, repo *repository.WebhookRepository) model.Webhook {
+	t.Helper()
+	now := time.Now().UTC().Truncate(time.Second)
+	w := model.Webhook{
+		ID:         testutil.MakeID(),
+		URL:        "https://example.com/hook",
+		EventTypes: []model.WebhookEvent{model.WebhookEventIbkrInboxItems, model.WebhookEventPriceUpdateFailed},
+		Secret:     "0123456789abcdef",
+		Enabled:    true,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	if err := repo.InsertWebhook(context.Background(), &w); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return w
+}
+
+func TestWebhookRepository_Webhooks(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	repo := repository.NewWebhookRepository(db)
+	ctx := context.Background()
+
+	w := insertTestWebhook(t, repo)
+
+	got, err := repo.GetWebhook(w.ID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.URL != w.URL || got.Secret != w.Secret || !got.Enabled || len(got.EventTypes) != 2 || !got.Subscribes(model.WebhookEventPriceUpdateFailed) {
+		t.Errorf("expected webhook to round-trip, got %+v", got)
+	}
+
+	got.Enabled = false
+	got.EventTypes = []model.WebhookEvent{model.WebhookEventPortfolioValueDrop}
+	if err := repo.UpdateWebhook(ctx, &got); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	webhooks, err := repo.GetWebhooks()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(webhooks) != 1 || webhooks[0].Enabled || !webhooks[0].Subscribes(model.WebhookEventPortfolioValueDrop) {
+		t.Errorf("expected the updated webhook, got %+v", webhooks)
+	}
+
+	if err := repo.DeleteWebhook(ctx, w.ID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := repo.GetWebhook(w.ID); !errors.Is(err, apperrors.ErrWebhookNotFound) {
+		t.Errorf("expected ErrWebhookNotFound, got %v", err)
+	}
+	if err := repo.DeleteWebhook(ctx, w.ID); !errors.Is(err, apperrors.ErrWebhookNotFound) {
+		t.Errorf("expected ErrWebhookNotFound, got %v", err)
+	}
+}
+
+//nolint:gocyclo // Test function with multiple subtests and assertions.
+func TestWebhookRepository_Deliveries(t *testing.T) {
+	newDelivery := func(webhookID, key string, due *time.Time) model.WebhookDelivery {
+		return model.WebhookDelivery{
+			ID:            testutil.MakeID(),
+			WebhookID:     webhookID,
+			EventType:     model.WebhookEventIbkrInboxItems,
+			EventKey:      key,
+			Payload:       json.RawMessage(`{"count":1}`),
+			Status:        model.WebhookDeliveryPending,
+			CreatedAt:     time.Now().UTC(),
+			NextAttemptAt: due,
+		}
+	}
+
+	t.Run("event key is queued once per webhook", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		repo := repository.NewWebhookRepository(db)
+		w := insertTestWebhook(t, repo)
+
+		for i, want := range []bool{true, false} {
+			d := newDelivery(w.ID, "evt:1", nil)
+			inserted, err := repo.InsertDelivery(context.Background(), &d)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if inserted != want {
+				t.Errorf("insert %d: expected inserted=%v, got %v", i, want, inserted)
+			}
+		}
+		// Deliveries without a key are never deduplicated.
+		for range 2 {
+			d := newDelivery(w.ID, "", nil)
+			if inserted, err := repo.InsertDelivery(context.Background(), &d); err != nil || !inserted {
+				t.Fatalf("expected keyless delivery to be inserted, got %v, %v", inserted, err)
+			}
+		}
+		testutil.AssertRowCount(t, db, "webhook_delivery", 3)
+	})
+
+	t.Run("due deliveries and attempts", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		repo := repository.NewWebhookRepository(db)
+		ctx := context.Background()
+		w := insertTestWebhook(t, repo)
+		now := time.Now().UTC()
+		later := now.Add(time.Hour)
+
+		unscheduled := newDelivery(w.ID, "", nil)
+		future := newDelivery(w.ID, "", &later)
+		for _, d := range []*model.WebhookDelivery{&unscheduled, &future} {
+			if _, err := repo.InsertDelivery(ctx, d); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+		if _, ok, err := repo.GetDueDelivery(now); err != nil || ok {
+			t.Fatalf("expected no due delivery, got ok=%v err=%v", ok, err)
+		}
+
+		due, ok, err := repo.GetDueDelivery(later)
+		if err != nil || !ok || due.ID != future.ID {
+			t.Fatalf("expected the future delivery to be due, got %+v ok=%v err=%v", due, ok, err)
+		}
+
+		if err := repo.RecordDeliveryAttempt(ctx, due.ID, model.WebhookDeliveryPending, 503, "unexpected response status 503", &later, nil); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := repo.RecordDeliveryAttempt(ctx, due.ID, model.WebhookDeliverySucceeded, 200, "", nil, &now); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		got, err := repo.GetDelivery(due.ID)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got.Status != model.WebhookDeliverySucceeded || got.Attempts != 2 || got.ResponseStatus == nil || *got.ResponseStatus != 200 ||
+			got.LastError != "" || got.NextAttemptAt != nil || got.DeliveredAt == nil {
+			t.Errorf("expected a delivery that succeeded on the second attempt, got %+v", got)
+		}
+
+		deliveries, err := repo.GetDeliveries(w.ID, 1)
+		if err != nil || len(deliveries) != 1 {
+			t.Fatalf("expected 1 delivery with limit 1, got %d, %v", len(deliveries), err)
+		}
+
+		n, err := repo.DeleteFinishedDeliveries(ctx, later)
+		if err != nil || n != 1 {
+			t.Errorf("expected the succeeded delivery to be purged, got %d, %v", n, err)
+		}
+		if _, err := repo.GetDelivery(due.ID); !errors.Is(err, apperrors.ErrWebhookDeliveryNotFound) {
+			t.Errorf("expected ErrWebhookDeliveryNotFound, got %v", err)
+		}
+	})
+
+	t.Run("deleting the webhook removes its deliveries", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		repo := repository.NewWebhookRepository(db)
+		w := insertTestWebhook(t, repo)
+		d := newDelivery(w.ID, "", nil)
+		if _, err := repo.InsertDelivery(context.Background(), &d); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if err := repo.DeleteWebhook(context.Background(), w.ID); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		testutil.AssertRowCount(t, db, "webhook_delivery", 0)
+	})
+}
@@ -0,0 +1,222 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/apperrors"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/model"
+)
+
+// WithholdingTaxRepository provides data access methods for default withholding tax rates
+// and the withholding tax imported from IBKR.
+type WithholdingTaxRepository struct {
+	db *sql.DB
+	tx *sql.Tx
+}
+
+// NewWithholdingTaxRepository creates a new WithholdingTaxRepository with the provided database connection.
+func NewWithholdingTaxRepository(db *sql.DB) *WithholdingTaxRepository {
+	return &WithholdingTaxRepository{db: db}
+}
+
+// WithTx returns a new WithholdingTaxRepository scoped to the provided transaction.
+func (r *WithholdingTaxRepository) WithTx(tx *sql.Tx) *WithholdingTaxRepository {
+	return &WithholdingTaxRepository{
+		db: r.db,
+		tx: tx,
+	}
+}
+
+// getQuerier returns the active transaction if one is set, otherwise the database connection.
+func (r *WithholdingTaxRepository) getQuerier() Querier {
+	if r.tx != nil {
+		return traced(r.tx)
+	}
+	return traced(r.db)
+}
+
+// GetWithholdingTaxRates retrieves every default withholding tax rate, ordered by country.
+func (r *WithholdingTaxRepository) GetWithholdingTaxRates() ([]model.WithholdingTaxRate, error) {
+	rows, err := r.getQuerier().Query(`SELECT country, rate_percent, updated_at FROM withholding_tax_rate ORDER BY country`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query withholding tax rates: %w", err)
+	}
+	defer rows.Close()
+
+	rates := []model.WithholdingTaxRate{}
+	for rows.Next() {
+		var rate model.WithholdingTaxRate
+		var updatedAtStr string
+		if err := rows.Scan(&rate.Country, &rate.RatePercent, &updatedAtStr); err != nil {
+			return nil, fmt.Errorf("failed to scan withholding tax rate: %w", err)
+		}
+		if rate.UpdatedAt, err = ParseTime(updatedAtStr); err != nil {
+			return nil, fmt.Errorf("failed to parse updated_at: %w", err)
+		}
+		rates = append(rates, rate)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating withholding tax rates: %w", err)
+	}
+	return rates, nil
+}
+
+// GetFundWithholdingTaxRate returns the default withholding tax rate in percent for a fund's
+// domicile, the country code of its ISIN. Returns 0 when the fund has no ISIN or its country
+// has no default rate.
+func (r *WithholdingTaxRepository) GetFundWithholdingTaxRate(fundID string) (float64, error) {
+	var rate float64
+	err := r.getQuerier().QueryRow(`
+		SELECT r.rate_percent
+		FROM fund f
+		JOIN withholding_tax_rate r ON r.country = UPPER(SUBSTR(f.isin, 1, 2))
+		WHERE f.id = ?
+	`, fundID).Scan(&rate)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to query fund withholding tax rate: %w", err)
+	}
+	return rate, nil
+}
+
+// UpsertWithholdingTaxRate creates or replaces the default withholding tax rate of a country.
+func (r *WithholdingTaxRepository) UpsertWithholdingTaxRate(ctx context.Context, rate model.WithholdingTaxRate) error {
+	_, err := r.getQuerier().ExecContext(ctx, `
+		INSERT INTO withholding_tax_rate (country, rate_percent, updated_at)
+		VALUES (?, ?, ?)
+		ON CONFLICT(country) DO UPDATE SET rate_percent = excluded.rate_percent, updated_at = excluded.updated_at
+	`, rate.Country, rate.RatePercent, rate.UpdatedAt.UTC().Format("2006-01-02 15:04:05"))
+	if err != nil {
+		return fmt.Errorf("failed to upsert withholding tax rate: %w", err)
+	}
+	return nil
+}
+
+// DeleteWithholdingTaxRate removes the default withholding tax rate of a country.
+// Returns ErrWithholdingTaxRateNotFound if the country has no rate.
+func (r *WithholdingTaxRepository) DeleteWithholdingTaxRate(ctx context.Context, country string) error {
+	result, err := r.getQuerier().ExecContext(ctx, `DELETE FROM withholding_tax_rate WHERE country = ?`, country)
+	if err != nil {
+		return fmt.Errorf("failed to delete withholding tax rate: %w", err)
+	}
+	return requireAffected(result, apperrors.ErrWithholdingTaxRateNotFound)
+}
+
+// GetWithholdingTaxDividends retrieves the dividends of the portfolios in scope with an
+// ex-dividend date in year, with their portfolio and fund, ordered by ex-dividend date.
+func (r *WithholdingTaxRepository) GetWithholdingTaxDividends(year int, scope model.PortfolioScope) ([]model.WithholdingTaxDividend, error) {
+	condition, args := scopeCondition(scope, "pf.portfolio_id")
+	//#nosec G202 -- Safe: placeholders are generated programmatically, not from user input
+	rows, err := r.getQuerier().Query(`
+		SELECT d.id, pf.portfolio_id, f.id, f.name, COALESCE(f.isin, ''), f.currency, d.ex_dividend_date,
+			d.total_amount, d.withholding_tax
+		FROM dividend d
+		JOIN portfolio_fund pf ON d.portfolio_fund_id = pf.id
+		JOIN fund f ON pf.fund_id = f.id
+		WHERE strftime('%Y', d.ex_dividend_date) = ? AND `+condition+`
+		ORDER BY d.ex_dividend_date, d.id
+	`, append([]any{strconv.Itoa(year)}, args...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query withholding tax dividends: %w", err)
+	}
+	defer rows.Close()
+
+	dividends := []model.WithholdingTaxDividend{}
+	for rows.Next() {
+		var d model.WithholdingTaxDividend
+		var exDateStr string
+		if err := rows.Scan(&d.DividendID, &d.PortfolioID, &d.FundID, &d.FundName, &d.ISIN, &d.Currency, &exDateStr,
+			&d.GrossAmount, &d.WithholdingTax); err != nil {
+			return nil, fmt.Errorf("failed to scan withholding tax dividend: %w", err)
+		}
+		if d.ExDividendDate, err = ParseTime(exDateStr); err != nil {
+			return nil, fmt.Errorf("failed to parse ex_dividend_date: %w", err)
+		}
+		dividends = append(dividends, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating withholding tax dividends: %w", err)
+	}
+	return dividends, nil
+}
+
+// AddIBKRWithholdingTax stores IBKR withholding tax cash transactions, skipping those already
+// imported. Returns the number of rows added.
+func (r *WithholdingTaxRepository) AddIBKRWithholdingTax(ctx context.Context, items []model.IBKRWithholdingTax) (int, error) {
+	added := 0
+	for _, w := range items {
+		result, err := r.getQuerier().ExecContext(ctx, `
+			INSERT OR IGNORE INTO ibkr_withholding_tax (id, ibkr_transaction_id, isin, symbol, currency, amount,
+				payment_date, ex_dividend_date, description, applied_at, imported_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, NULL, ?)
+		`, w.ID, w.IBKRTransactionID, nullableString(w.ISIN), nullableString(w.Symbol), w.Currency, w.Amount,
+			w.PaymentDate.Format("2006-01-02"), formatNullableDate(w.ExDividendDate), nullableString(w.Description),
+			w.ImportedAt.UTC().Format("2006-01-02 15:04:05"))
+		if err != nil {
+			return added, fmt.Errorf("failed to insert ibkr withholding tax: %w", err)
+		}
+		n, err := result.RowsAffected()
+		if err != nil {
+			return added, fmt.Errorf("failed to get rows affected: %w", err)
+		}
+		added += int(n)
+	}
+	return added, nil
+}
+
+// GetUnappliedIBKRWithholdingTax retrieves the IBKR withholding tax not yet applied to a
+// dividend, oldest payment first.
+func (r *WithholdingTaxRepository) GetUnappliedIBKRWithholdingTax() ([]model.IBKRWithholdingTax, error) {
+	rows, err := r.getQuerier().Query(`
+		SELECT id, ibkr_transaction_id, COALESCE(isin, ''), COALESCE(symbol, ''), currency, amount, payment_date,
+			ex_dividend_date, COALESCE(description, ''), imported_at
+		FROM ibkr_withholding_tax
+		WHERE applied_at IS NULL
+		ORDER BY payment_date, ibkr_transaction_id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query ibkr withholding tax: %w", err)
+	}
+	defer rows.Close()
+
+	items := []model.IBKRWithholdingTax{}
+	for rows.Next() {
+		var w model.IBKRWithholdingTax
+		var paymentDateStr, importedAtStr string
+		var exDateStr sql.NullString
+		if err := rows.Scan(&w.ID, &w.IBKRTransactionID, &w.ISIN, &w.Symbol, &w.Currency, &w.Amount, &paymentDateStr,
+			&exDateStr, &w.Description, &importedAtStr); err != nil {
+			return nil, fmt.Errorf("failed to scan ibkr withholding tax: %w", err)
+		}
+		if w.PaymentDate, err = ParseTime(paymentDateStr); err != nil {
+			return nil, fmt.Errorf("failed to parse payment_date: %w", err)
+		}
+		if w.ExDividendDate, err = parseNullableDate(exDateStr); err != nil {
+			return nil, fmt.Errorf("failed to parse ex_dividend_date: %w", err)
+		}
+		if w.ImportedAt, err = ParseTime(importedAtStr); err != nil {
+			return nil, fmt.Errorf("failed to parse imported_at: %w", err)
+		}
+		items = append(items, w)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating ibkr withholding tax: %w", err)
+	}
+	return items, nil
+}
+
+// MarkIBKRWithholdingTaxApplied records that an IBKR withholding tax row was applied to its dividends.
+func (r *WithholdingTaxRepository) MarkIBKRWithholdingTaxApplied(ctx context.Context, id string, appliedAt time.Time) error {
+	_, err := r.getQuerier().ExecContext(ctx, `UPDATE ibkr_withholding_tax SET applied_at = ? WHERE id = ?`,
+		appliedAt.UTC().Format("2006-01-02 15:04:05"), id)
+	if err != nil {
+		return fmt.Errorf("failed to mark ibkr withholding tax applied: %w", err)
+	}
+	return nil
+}
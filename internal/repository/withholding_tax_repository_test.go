@@ -0,0 +1,146 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/apperrors"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/model"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/repository"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/testutil"
+)
+
+func TestWithholdingTaxRepository_Rates(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	repo := repository.NewWithholdingTaxRepository(db)
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Second)
+
+	for _, r := range []model.WithholdingTaxRate{
+		{Country: "US", RatePercent: 30, UpdatedAt: now},
+		{Country: "IE", RatePercent: 0, UpdatedAt: now},
+		{Country: "US", RatePercent: 15, UpdatedAt: now},
+	} {
+		if err := repo.UpsertWithholdingTaxRate(ctx, r); err != nil {
+			t.Fatalf("UpsertWithholdingTaxRate(%s) failed: %v", r.Country, err)
+		}
+	}
+
+	rates, err := repo.GetWithholdingTaxRates()
+	if err != nil {
+		t.Fatalf("GetWithholdingTaxRates() failed: %v", err)
+	}
+	if len(rates) != 2 || rates[0].Country != "IE" || rates[1].Country != "US" || rates[1].RatePercent != 15 {
+		t.Fatalf("GetWithholdingTaxRates() = %+v, want IE 0 and US 15", rates)
+	}
+
+	usFund := testutil.NewFund().WithISIN("us0378331005").Build(t, db)
+	nlFund := testutil.NewFund().WithISIN("NL0010273215").Build(t, db)
+	for fundID, want := range map[string]float64{usFund.ID: 15, nlFund.ID: 0} {
+		rate, err := repo.GetFundWithholdingTaxRate(fundID)
+		if err != nil {
+			t.Fatalf("GetFundWithholdingTaxRate() failed: %v", err)
+		}
+		if rate != want {
+			t.Errorf("GetFundWithholdingTaxRate(%s) = %v, want %v", fundID, rate, want)
+		}
+	}
+
+	if err := repo.DeleteWithholdingTaxRate(ctx, "US"); err != nil {
+		t.Fatalf("DeleteWithholdingTaxRate() failed: %v", err)
+	}
+	if err := repo.DeleteWithholdingTaxRate(ctx, "US"); !errors.Is(err, apperrors.ErrWithholdingTaxRateNotFound) {
+		t.Errorf("DeleteWithholdingTaxRate() twice error = %v, want ErrWithholdingTaxRateNotFound", err)
+	}
+}
+
+func TestWithholdingTaxRepository_GetWithholdingTaxDividends(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	repo := repository.NewWithholdingTaxRepository(db)
+
+	portfolio := testutil.NewPortfolio().Build(t, db)
+	fund := testutil.NewFund().WithName("US Equity").WithISIN("US0378331005").Build(t, db)
+	pf := testutil.NewPortfolioFund(portfolio.ID, fund.ID).Build(t, db)
+
+	inYear := testutil.NewDividend(fund.ID, pf.ID).
+		WithExDividendDate(time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)).
+		WithWithholdingTax(7.5, model.WithholdingSourceDefault).
+		Build(t, db)
+	testutil.NewDividend(fund.ID, pf.ID).WithExDividendDate(time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC)).Build(t, db)
+	testutil.NewDividend(fund.ID, pf.ID).WithExDividendDate(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)).Build(t, db)
+
+	dividends, err := repo.GetWithholdingTaxDividends(2025, model.AllPortfolios())
+	if err != nil {
+		t.Fatalf("GetWithholdingTaxDividends() failed: %v", err)
+	}
+	if len(dividends) != 1 {
+		t.Fatalf("GetWithholdingTaxDividends() returned %d dividends, want 1", len(dividends))
+	}
+	d := dividends[0]
+	if d.DividendID != inYear.ID || d.PortfolioID != portfolio.ID || d.ISIN != "US0378331005" || d.FundName != "US Equity" {
+		t.Errorf("unexpected dividend %+v", d)
+	}
+	if d.GrossAmount != 50 || d.WithholdingTax != 7.5 {
+		t.Errorf("amounts = %v/%v, want 50/7.5", d.GrossAmount, d.WithholdingTax)
+	}
+}
+
+func TestWithholdingTaxRepository_IBKRWithholdingTax(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	repo := repository.NewWithholdingTaxRepository(db)
+	ctx := context.Background()
+
+	exDate := time.Date(2025, 2, 7, 0, 0, 0, 0, time.UTC)
+	items := []model.IBKRWithholdingTax{
+		{
+			ID: testutil.MakeID(), IBKRTransactionID: "1001", ISIN: "US0378331005", Symbol: "AAPL", Currency: "USD",
+			Amount: 3.75, PaymentDate: time.Date(2025, 2, 13, 0, 0, 0, 0, time.UTC), ExDividendDate: &exDate,
+			ImportedAt: time.Now().UTC(),
+		},
+		{
+			ID: testutil.MakeID(), IBKRTransactionID: "1002", Symbol: "MSFT", Currency: "USD",
+			Amount: 2, PaymentDate: time.Date(2025, 3, 13, 0, 0, 0, 0, time.UTC), ImportedAt: time.Now().UTC(),
+		},
+	}
+
+	added, err := repo.AddIBKRWithholdingTax(ctx, items)
+	if err != nil {
+		t.Fatalf("AddIBKRWithholdingTax() failed: %v", err)
+	}
+	if added != 2 {
+		t.Errorf("added = %d, want 2", added)
+	}
+
+	items[0].ID = testutil.MakeID()
+	added, err = repo.AddIBKRWithholdingTax(ctx, items[:1])
+	if err != nil {
+		t.Fatalf("AddIBKRWithholdingTax() again failed: %v", err)
+	}
+	if added != 0 {
+		t.Errorf("re-importing added %d rows, want 0", added)
+	}
+
+	pending, err := repo.GetUnappliedIBKRWithholdingTax()
+	if err != nil {
+		t.Fatalf("GetUnappliedIBKRWithholdingTax() failed: %v", err)
+	}
+	if len(pending) != 2 || pending[0].IBKRTransactionID != "1001" || pending[1].IBKRTransactionID != "1002" {
+		t.Fatalf("GetUnappliedIBKRWithholdingTax() = %+v", pending)
+	}
+	if pending[0].ExDividendDate == nil || !pending[0].ExDividendDate.Equal(exDate) || pending[1].ExDividendDate != nil {
+		t.Errorf("ex-dividend dates = %v, %v", pending[0].ExDividendDate, pending[1].ExDividendDate)
+	}
+
+	if err := repo.MarkIBKRWithholdingTaxApplied(ctx, pending[0].ID, time.Now().UTC()); err != nil {
+		t.Fatalf("MarkIBKRWithholdingTaxApplied() failed: %v", err)
+	}
+	pending, err = repo.GetUnappliedIBKRWithholdingTax()
+	if err != nil {
+		t.Fatalf("GetUnappliedIBKRWithholdingTax() failed: %v", err)
+	}
+	if len(pending) != 1 || pending[0].IBKRTransactionID != "1002" {
+		t.Errorf("after applying, unapplied = %+v, want only 1002", pending)
+	}
+}
@@ -0,0 +1,652 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/api/request"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/apperrors"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/logging"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/model"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/repository"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/tracing"
+)
+
+var alertLog = logging.NewLogger("system")
+
+// alertPriceLookbackDays is how far back fund rules look for the latest price, and for
+// the reference price of a weekly move across weekends and holidays.
+const alertPriceLookbackDays = 30
+
+// AlertEvaluator re-evaluates alert rules after the data they watch has changed.
+// FundService and MaterializedService depend on this interface rather than on *AlertService.
+type AlertEvaluator interface {
+	// EvaluateFundAlerts evaluates the rules that watch fund prices.
+	EvaluateFundAlerts(ctx context.Context) (model.AlertEvaluation, error)
+	// EvaluatePortfolioAlerts evaluates the rules that watch portfolioID.
+	EvaluatePortfolioAlerts(ctx context.Context, portfolioID string) (model.AlertEvaluation, error)
+}
+
+// Notifier delivers short notifications to people rather than systems.
+type Notifier interface {
+	// Notify sends subject and text to the configured recipients. Having no recipients
+	// is not an error.
+	Notify(ctx context.Context, subject, text string) error
+}
+
+// AlertService manages alert rules and raises, resolves and acknowledges the alerts
+// they trigger. Rules are evaluated after fund prices are updated and after a portfolio's
+// materialized history is regenerated; triggered alerts are logged, published as
+// alert.triggered events and sent to the Notifier.
+type AlertService struct {
+	db               *sql.DB
+	alertRepo        *repository.AlertRepository
+	auditRepo        *repository.AuditRepository
+	portfolioRepo    *repository.PortfolioRepository
+	fundRepo         *repository.FundRepository
+	pfRepo           *repository.PortfolioFundRepository
+	materializedRepo *repository.MaterializedRepository
+	events           EventPublisher
+	notifier         Notifier
+
+	// mu serializes evaluations so concurrent triggers cannot raise the same alert twice.
+	mu sync.Mutex
+}
+
+// NewAlertService creates a new AlertService with the provided dependencies.
+func NewAlertService(
+	db *sql.DB,
+	alertRepo *repository.AlertRepository,
+	auditRepo *repository.AuditRepository,
+	portfolioRepo *repository.PortfolioRepository,
+	fundRepo *repository.FundRepository,
+	pfRepo *repository.PortfolioFundRepository,
+	materializedRepo *repository.MaterializedRepository,
+) *AlertService {
+	return &AlertService{
+		db:               db,
+		alertRepo:        alertRepo,
+		auditRepo:        auditRepo,
+		portfolioRepo:    portfolioRepo,
+		fundRepo:         fundRepo,
+		pfRepo:           pfRepo,
+		materializedRepo: materializedRepo,
+	}
+}
+
+// SetEventPublisher injects the EventPublisher that receives alert.triggered and alert.resolved events.
+func (s *AlertService) SetEventPublisher(p EventPublisher) {
+	s.events = p
+}
+
+// SetNotifier injects the Notifier that receives triggered alerts.
+func (s *AlertService) SetNotifier(n Notifier) {
+	s.notifier = n
+}
+
+// GetAlertRules retrieves all alert rules, oldest first.
+func (s *AlertService) GetAlertRules() ([]model.AlertRule, error) {
+	rules, err := s.alertRepo.GetAlertRules()
+	if err != nil {
+		return nil, fmt.Errorf("get alert rules: %w", err)
+	}
+	return rules, nil
+}
+
+// GetAlertRule retrieves an alert rule. Returns ErrAlertRuleNotFound if it does not exist.
+func (s *AlertService) GetAlertRule(ruleID string) (model.AlertRule, error) {
+	return s.alertRepo.GetAlertRule(ruleID)
+}
+
+// CreateAlertRule stores a new alert rule. It is first evaluated with the next price
+// update or regeneration. Returns ErrPortfolioNotFound or ErrFundNotFound if the rule
+// refers to a portfolio or fund that does not exist.
+func (s *AlertService) CreateAlertRule(ctx context.Context, req request.CreateAlertRuleRequest) (model.AlertRule, error) {
+	ctx, span := tracing.Start(ctx, "AlertService.CreateAlertRule")
+	defer span.End()
+
+	now := time.Now().UTC().Truncate(time.Second)
+	rule := buildAlertRule(req)
+	rule.ID = uuid.New().String()
+	rule.Enabled = req.Enabled == nil || *req.Enabled
+	rule.CreatedAt = now
+	rule.UpdatedAt = now
+	if err := s.checkAlertRuleTargets(rule); err != nil {
+		return model.AlertRule{}, err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return model.AlertRule{}, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }() //nolint:errcheck // Rollback is a no-op after Commit; error is intentionally ignored.
+
+	if err := s.alertRepo.WithTx(tx).InsertAlertRule(ctx, &rule); err != nil {
+		return model.AlertRule{}, fmt.Errorf("insert alert rule: %w", err)
+	}
+	if err := recordAudit(ctx, s.auditRepo.WithTx(tx), model.AuditEntityAlertRule, rule.ID, model.AuditActionCreate, nil, rule); err != nil {
+		return model.AlertRule{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return model.AlertRule{}, fmt.Errorf("commit transaction: %w", err)
+	}
+
+	alertLog.InfoContext(ctx, "alert rule created", "rule_id", rule.ID, "type", rule.Type)
+	return rule, nil
+}
+
+// UpdateAlertRule replaces an alert rule. An open alert of the rule is resolved, since it
+// was raised under the old condition; the next evaluation raises it again if the new
+// condition holds.
+// Returns ErrAlertRuleNotFound if the rule does not exist, or ErrPortfolioNotFound or
+// ErrFundNotFound if it refers to a portfolio or fund that does not exist.
+func (s *AlertService) UpdateAlertRule(ctx context.Context, ruleID string, req request.UpdateAlertRuleRequest) (model.AlertRule, error) {
+	ctx, span := tracing.Start(ctx, "AlertService.UpdateAlertRule")
+	defer span.End()
+
+	after := buildAlertRule(request.CreateAlertRuleRequest(req))
+	if err := s.checkAlertRuleTargets(after); err != nil {
+		return model.AlertRule{}, err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return model.AlertRule{}, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }() //nolint:errcheck // Rollback is a no-op after Commit; error is intentionally ignored.
+
+	before, err := s.alertRepo.WithTx(tx).GetAlertRule(ruleID)
+	if err != nil {
+		return model.AlertRule{}, err
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	after.ID = before.ID
+	after.Enabled = before.Enabled
+	if req.Enabled != nil {
+		after.Enabled = *req.Enabled
+	}
+	after.CreatedAt = before.CreatedAt
+	after.UpdatedAt = now
+
+	if err := s.alertRepo.WithTx(tx).UpdateAlertRule(ctx, &after); err != nil {
+		return model.AlertRule{}, fmt.Errorf("update alert rule: %w", err)
+	}
+	open, err := s.alertRepo.WithTx(tx).GetOpenAlerts([]string{ruleID})
+	if err != nil {
+		return model.AlertRule{}, err
+	}
+	if alert, ok := open[ruleID]; ok {
+		if err := s.alertRepo.WithTx(tx).UpdateAlertStatus(ctx, alert.ID, model.AlertStatusResolved, now); err != nil {
+			return model.AlertRule{}, fmt.Errorf("resolve alert: %w", err)
+		}
+	}
+	if err := recordAudit(ctx, s.auditRepo.WithTx(tx), model.AuditEntityAlertRule, ruleID, model.AuditActionUpdate, before, after); err != nil {
+		return model.AlertRule{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return model.AlertRule{}, fmt.Errorf("commit transaction: %w", err)
+	}
+	return after, nil
+}
+
+// DeleteAlertRule removes an alert rule and its alerts. Returns ErrAlertRuleNotFound if it does not exist.
+func (s *AlertService) DeleteAlertRule(ctx context.Context, ruleID string) error {
+	ctx, span := tracing.Start(ctx, "AlertService.DeleteAlertRule")
+	defer span.End()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }() //nolint:errcheck // Rollback is a no-op after Commit; error is intentionally ignored.
+
+	before, err := s.alertRepo.WithTx(tx).GetAlertRule(ruleID)
+	if err != nil {
+		return err
+	}
+	if err := s.alertRepo.WithTx(tx).DeleteAlertRule(ctx, ruleID); err != nil {
+		return err
+	}
+	if err := recordAudit(ctx, s.auditRepo.WithTx(tx), model.AuditEntityAlertRule, ruleID, model.AuditActionDelete, before, nil); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+
+	alertLog.InfoContext(ctx, "alert rule deleted", "rule_id", ruleID)
+	return nil
+}
+
+// buildAlertRule copies the fields of req that its rule type uses into a new rule.
+func buildAlertRule(req request.CreateAlertRuleRequest) model.AlertRule {
+	rule := model.AlertRule{
+		Name: req.Name,
+		Type: model.AlertRuleType(req.Type),
+	}
+	if req.Threshold != nil {
+		rule.Threshold = *req.Threshold
+	}
+	switch rule.Type {
+	case model.AlertRuleFundPrice:
+		rule.FundID = req.FundID
+		rule.Direction = model.AlertDirection(req.Direction)
+	case model.AlertRuleFundMove:
+		rule.FundID = req.FundID
+		rule.Period = model.AlertPeriod(req.Period)
+	case model.AlertRulePortfolioValue:
+		rule.PortfolioID = req.PortfolioID
+	case model.AlertRulePositionLoss:
+		rule.PortfolioID = req.PortfolioID
+		rule.FundID = req.FundID
+	}
+	return rule
+}
+
+// checkAlertRuleTargets verifies that the portfolio and fund a rule refers to exist.
+func (s *AlertService) checkAlertRuleTargets(rule model.AlertRule) error {
+	if rule.PortfolioID != "" {
+		if _, err := s.portfolioRepo.GetPortfolioOnID(rule.PortfolioID); err != nil {
+			return err
+		}
+	}
+	if rule.FundID != "" {
+		if _, err := s.fundRepo.GetFund(rule.FundID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetAlerts retrieves the most recently triggered alerts in one of statuses, newest
+// first. An empty statuses returns alerts in every status.
+func (s *AlertService) GetAlerts(statuses []model.AlertStatus, limit int) ([]model.Alert, error) {
+	alerts, err := s.alertRepo.GetAlerts(statuses, limit)
+	if err != nil {
+		return nil, fmt.Errorf("get alerts: %w", err)
+	}
+	return alerts, nil
+}
+
+// AcknowledgeAlert marks an active alert as seen. It stays open until its condition no
+// longer holds, but is not raised again meanwhile.
+// Returns ErrAlertNotFound if the alert does not exist, or ErrAlertNotActive if it is
+// already acknowledged or resolved.
+func (s *AlertService) AcknowledgeAlert(ctx context.Context, alertID string) (model.Alert, error) {
+	ctx, span := tracing.Start(ctx, "AlertService.AcknowledgeAlert")
+	defer span.End()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return model.Alert{}, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }() //nolint:errcheck // Rollback is a no-op after Commit; error is intentionally ignored.
+
+	before, err := s.alertRepo.WithTx(tx).GetAlert(alertID)
+	if err != nil {
+		return model.Alert{}, err
+	}
+	if before.Status != model.AlertStatusActive {
+		return model.Alert{}, apperrors.ErrAlertNotActive
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	if err := s.alertRepo.WithTx(tx).UpdateAlertStatus(ctx, alertID, model.AlertStatusAcknowledged, now); err != nil {
+		return model.Alert{}, fmt.Errorf("acknowledge alert: %w", err)
+	}
+	alert := before
+	alert.Status = model.AlertStatusAcknowledged
+	alert.AcknowledgedAt = &now
+	if err := recordAudit(ctx, s.auditRepo.WithTx(tx), model.AuditEntityAlert, alertID, model.AuditActionAcknowledge, before, alert); err != nil {
+		return model.Alert{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return model.Alert{}, fmt.Errorf("commit transaction: %w", err)
+	}
+
+	alertLog.InfoContext(ctx, "alert acknowledged", "alert_id", alertID, "rule_id", alert.RuleID)
+	return alert, nil
+}
+
+// EvaluateAlerts evaluates every enabled rule, for fund prices and all portfolios.
+func (s *AlertService) EvaluateAlerts(ctx context.Context) (model.AlertEvaluation, error) {
+	funds, err := s.EvaluateFundAlerts(ctx)
+	if err != nil {
+		return funds, err
+	}
+	portfolios, err := s.EvaluatePortfolioAlerts(ctx, "")
+	if err != nil {
+		return portfolios, err
+	}
+	return model.AlertEvaluation{
+		Evaluated: funds.Evaluated + portfolios.Evaluated,
+		Triggered: funds.Triggered + portfolios.Triggered,
+		Resolved:  funds.Resolved + portfolios.Resolved,
+		Failed:    funds.Failed + portfolios.Failed,
+	}, nil
+}
+
+// alertObservation is the outcome of checking one rule against the current data.
+type alertObservation struct {
+	value     float64
+	triggered bool
+	message   string // Describes the triggered condition
+}
+
+// EvaluateFundAlerts evaluates the enabled fund_price and fund_move rules against the
+// latest stored prices. Rules of funds without a price in the last 30 days are skipped.
+func (s *AlertService) EvaluateFundAlerts(ctx context.Context) (result model.AlertEvaluation, err error) {
+	ctx, span := tracing.Start(ctx, "AlertService.EvaluateFundAlerts")
+	defer func() { tracing.End(span, err) }()
+
+	rules, err := s.alertRepo.GetEnabledFundAlertRules()
+	if err != nil {
+		return result, fmt.Errorf("get fund alert rules: %w", err)
+	}
+	if len(rules) == 0 {
+		return result, nil
+	}
+
+	seen := make(map[string]bool)
+	fundIDs := []string{}
+	for _, rule := range rules {
+		if !seen[rule.FundID] {
+			seen[rule.FundID] = true
+			fundIDs = append(fundIDs, rule.FundID)
+		}
+	}
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	prices, err := s.fundRepo.GetFundPrice(fundIDs, today.AddDate(0, 0, -alertPriceLookbackDays), today, false)
+	if err != nil {
+		return result, fmt.Errorf("get fund prices: %w", err)
+	}
+
+	names := make(map[string]string)
+	return s.apply(ctx, rules, func(rule model.AlertRule) (alertObservation, bool, error) {
+		history := prices[rule.FundID]
+		if len(history) == 0 {
+			return alertObservation{}, false, nil
+		}
+		name, ok := names[rule.FundID]
+		if !ok {
+			fund, err := s.fundRepo.GetFund(rule.FundID)
+			if err != nil {
+				return alertObservation{}, false, err
+			}
+			name = fund.Name
+			names[rule.FundID] = name
+		}
+		if rule.Type == model.AlertRuleFundMove {
+			return observeFundMove(rule, name, history)
+		}
+		return observeFundPrice(rule, name, history[0]), true, nil
+	})
+}
+
+// observeFundPrice checks a fund_price rule against the latest price.
+func observeFundPrice(rule model.AlertRule, fundName string, latest model.FundPrice) alertObservation {
+	triggered := latest.Price > rule.Threshold
+	if rule.Direction == model.AlertDirectionBelow {
+		triggered = latest.Price < rule.Threshold
+	}
+	return alertObservation{
+		value:     latest.Price,
+		triggered: triggered,
+		message:   fmt.Sprintf("%s price %.2f is %s %.2f", fundName, latest.Price, rule.Direction, rule.Threshold),
+	}
+}
+
+// observeFundMove checks a fund_move rule by comparing the latest price with the last
+// price at least one period older. history is sorted newest first.
+func observeFundMove(rule model.AlertRule, fundName string, history []model.FundPrice) (alertObservation, bool, error) {
+	latest := history[0]
+	cutoff := latest.Date.AddDate(0, 0, -rule.Period.Days())
+	for _, ref := range history[1:] {
+		if ref.Date.After(cutoff) {
+			continue
+		}
+		if ref.Price <= 0 {
+			return alertObservation{}, false, nil
+		}
+		change := (latest.Price - ref.Price) / ref.Price * 100
+		return alertObservation{
+			value:     change,
+			triggered: math.Abs(change) >= rule.Threshold,
+			message: fmt.Sprintf("%s moved %+.2f%% over the last %s, from %.2f to %.2f",
+				fundName, change, rule.Period, ref.Price, latest.Price),
+		}, true, nil
+	}
+	return alertObservation{}, false, nil
+}
+
+// EvaluatePortfolioAlerts evaluates the enabled portfolio_value and position_loss rules of
+// a portfolio against its latest materialized history. An empty portfolioID evaluates the
+// rules of every portfolio. Portfolios without materialized history are skipped.
+func (s *AlertService) EvaluatePortfolioAlerts(ctx context.Context, portfolioID string) (result model.AlertEvaluation, err error) {
+	ctx, span := tracing.Start(ctx, "AlertService.EvaluatePortfolioAlerts")
+	defer func() { tracing.End(span, err) }()
+
+	rules, err := s.alertRepo.GetEnabledPortfolioAlertRules(portfolioID)
+	if err != nil {
+		return result, fmt.Errorf("get portfolio alert rules: %w", err)
+	}
+	if len(rules) == 0 {
+		return result, nil
+	}
+	return s.apply(ctx, rules, s.observePortfolio)
+}
+
+// observePortfolio checks a portfolio_value or position_loss rule.
+func (s *AlertService) observePortfolio(rule model.AlertRule) (alertObservation, bool, error) {
+	portfolio, err := s.portfolioRepo.GetPortfolioOnID(rule.PortfolioID)
+	if err != nil {
+		return alertObservation{}, false, err
+	}
+
+	if rule.Type == model.AlertRulePortfolioValue {
+		var latest *model.PortfolioHistoryMaterialized
+		err := s.materializedRepo.GetPortfolioSummaryLatest([]string{rule.PortfolioID}, func(record model.PortfolioHistoryMaterialized) error {
+			latest = &record
+			return nil
+		})
+		if err != nil || latest == nil {
+			return alertObservation{}, false, err
+		}
+		return alertObservation{
+			value:     latest.Value,
+			triggered: latest.Value < rule.Threshold,
+			message:   fmt.Sprintf("%s value %.2f is below the floor of %.2f", portfolio.Name, latest.Value, rule.Threshold),
+		}, true, nil
+	}
+
+	fund, err := s.fundRepo.GetFund(rule.FundID)
+	if err != nil {
+		return alertObservation{}, false, err
+	}
+	pf, err := s.pfRepo.GetPortfolioFundByPortfolioAndFund(rule.PortfolioID, rule.FundID)
+	if errors.Is(err, apperrors.ErrPortfolioFundNotFound) {
+		return alertObservation{}, false, nil
+	}
+	if err != nil {
+		return alertObservation{}, false, err
+	}
+	date, _, ok, err := s.materializedRepo.GetLatestMaterializedDate([]string{rule.PortfolioID})
+	if err != nil || !ok {
+		return alertObservation{}, false, err
+	}
+	entries, err := s.materializedRepo.GetMaterializedEntriesForDate([]string{pf.ID}, date)
+	if err != nil {
+		return alertObservation{}, false, err
+	}
+
+	// A position that is no longer held has no unrealized loss.
+	var loss float64
+	if entry, ok := entries[pf.ID]; ok && entry.Shares > 0 && entry.Cost > 0 {
+		loss = -entry.UnrealizedGain / entry.Cost * 100
+	}
+	return alertObservation{
+		value:     loss,
+		triggered: loss >= rule.Threshold,
+		message: fmt.Sprintf("%s in %s has an unrealized loss of %.2f%% (threshold %.2f%%)",
+			fund.Name, portfolio.Name, loss, rule.Threshold),
+	}, true, nil
+}
+
+// apply observes each rule and moves its alert through the alert states: a rule whose
+// condition holds and has no open alert raises one; an open alert whose condition no
+// longer holds is resolved. Rules without enough data keep their state; rules that fail
+// are logged and counted, and do not stop the others from being evaluated.
+// Webhooks and notifications for the state changes are delivered after the evaluation
+// lock is released, so a slow endpoint does not hold up acknowledgements or other runs.
+func (s *AlertService) apply(
+	ctx context.Context,
+	rules []model.AlertRule,
+	observe func(rule model.AlertRule) (alertObservation, bool, error),
+) (model.AlertEvaluation, error) {
+	s.mu.Lock()
+	result, changed, err := s.applyLocked(ctx, rules, observe)
+	s.mu.Unlock()
+
+	for _, alert := range changed {
+		s.deliver(ctx, alert)
+	}
+	return result, err
+}
+
+// applyLocked performs apply's state changes and returns the alerts that were triggered
+// or resolved. s.mu must be held.
+func (s *AlertService) applyLocked(
+	ctx context.Context,
+	rules []model.AlertRule,
+	observe func(rule model.AlertRule) (alertObservation, bool, error),
+) (model.AlertEvaluation, []model.Alert, error) {
+	var result model.AlertEvaluation
+	ruleIDs := make([]string, len(rules))
+	for i, rule := range rules {
+		ruleIDs[i] = rule.ID
+	}
+	open, err := s.alertRepo.GetOpenAlerts(ruleIDs)
+	if err != nil {
+		return result, nil, err
+	}
+
+	var changed []model.Alert
+	for _, rule := range rules {
+		alert, outcome, err := s.applyRule(ctx, rule, open, observe)
+		if err != nil {
+			alertLog.ErrorContext(ctx, "failed to evaluate alert rule", "rule_id", rule.ID, "rule", rule.Name, "error", err)
+			result.Failed++
+			continue
+		}
+		switch outcome {
+		case ruleSkipped:
+			continue
+		case ruleTriggered:
+			result.Triggered++
+			changed = append(changed, alert)
+		case ruleResolved:
+			result.Resolved++
+			changed = append(changed, alert)
+		}
+		result.Evaluated++
+	}
+	return result, changed, nil
+}
+
+// ruleOutcome is what evaluating one rule did to its alert.
+type ruleOutcome int
+
+const (
+	ruleSkipped   ruleOutcome = iota // Not enough data to evaluate
+	ruleUnchanged                    // Evaluated; the alert state still matches the condition
+	ruleTriggered                    // A new alert was raised
+	ruleResolved                     // The open alert was resolved
+)
+
+// applyRule observes one rule and triggers or resolves its alert. It returns the alert
+// whose state changed, if any.
+func (s *AlertService) applyRule(
+	ctx context.Context,
+	rule model.AlertRule,
+	open map[string]model.Alert,
+	observe func(rule model.AlertRule) (alertObservation, bool, error),
+) (model.Alert, ruleOutcome, error) {
+	obs, ok, err := observe(rule)
+	if err != nil {
+		return model.Alert{}, ruleSkipped, err
+	}
+	if !ok {
+		return model.Alert{}, ruleSkipped, nil
+	}
+
+	current, isOpen := open[rule.ID]
+	switch {
+	case obs.triggered && !isOpen:
+		alert, err := s.trigger(ctx, rule, obs)
+		return alert, ruleTriggered, err
+	case !obs.triggered && isOpen:
+		alert, err := s.resolve(ctx, current)
+		return alert, ruleResolved, err
+	}
+	return model.Alert{}, ruleUnchanged, nil
+}
+
+// trigger stores a new active alert for rule.
+func (s *AlertService) trigger(ctx context.Context, rule model.AlertRule, obs alertObservation) (model.Alert, error) {
+	alert := model.Alert{
+		ID:          uuid.New().String(),
+		RuleID:      rule.ID,
+		RuleName:    rule.Name,
+		RuleType:    rule.Type,
+		Status:      model.AlertStatusActive,
+		Message:     obs.message,
+		Value:       obs.value,
+		TriggeredAt: time.Now().UTC().Truncate(time.Second),
+	}
+	if err := s.alertRepo.InsertAlert(ctx, &alert); err != nil {
+		return model.Alert{}, fmt.Errorf("insert alert: %w", err)
+	}
+
+	alertLog.WarnContext(ctx, "alert triggered", "alert_id", alert.ID, "rule_id", rule.ID, "rule", rule.Name, "message", alert.Message)
+	return alert, nil
+}
+
+// resolve marks an open alert as resolved.
+func (s *AlertService) resolve(ctx context.Context, alert model.Alert) (model.Alert, error) {
+	now := time.Now().UTC().Truncate(time.Second)
+	if err := s.alertRepo.UpdateAlertStatus(ctx, alert.ID, model.AlertStatusResolved, now); err != nil {
+		return model.Alert{}, fmt.Errorf("resolve alert: %w", err)
+	}
+	alert.Status = model.AlertStatusResolved
+	alert.ResolvedAt = &now
+
+	alertLog.InfoContext(ctx, "alert resolved", "alert_id", alert.ID, "rule_id", alert.RuleID, "rule", alert.RuleName)
+	return alert, nil
+}
+
+// deliver publishes a triggered or resolved alert to webhooks; triggered alerts are also
+// sent to the notifier.
+func (s *AlertService) deliver(ctx context.Context, alert model.Alert) {
+	if alert.Status == model.AlertStatusResolved {
+		publishEvent(ctx, s.events, model.WebhookEventAlertResolved, "", alert)
+		return
+	}
+
+	publishEvent(ctx, s.events, model.WebhookEventAlertTriggered, "", alert)
+	if s.notifier != nil {
+		text := fmt.Sprintf("%s\n\nRule: %s\nTriggered at: %s UTC\n", alert.Message, alert.RuleName, alert.TriggeredAt.Format("2006-01-02 15:04"))
+		if err := s.notifier.Notify(ctx, "Alert: "+alert.RuleName, text); err != nil {
+			alertLog.WarnContext(ctx, "failed to send alert notification", "alert_id", alert.ID, "error", err)
+		}
+	}
+}
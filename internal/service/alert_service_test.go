@@ -0,0 +1,356 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/api/request"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/apperrors"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/model"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/service"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/testutil"
+)
+
+// recordingNotifier is a service.Notifier that keeps every notification.
+type recordingNotifier struct {
+	mu       sync.Mutex
+	subjects []string
+}
+
+func (n *recordingNotifier) Notify(_ context.Context, subject, _ string) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.subjects = append(n.subjects, subject)
+	return nil
+}
+
+func createAlertRule(t *testing.T, svc *service.AlertService, req request.CreateAlertRuleRequest) model.AlertRule {
+	t.Helper()
+	rule, err := svc.CreateAlertRule(context.Background(), req)
+	if err != nil {
+		t.Fatalf("CreateAlertRule: %v", err)
+	}
+	return rule
+}
+
+func threshold(f float64) *float64 { return &f }
+
+func TestAlertService_Rules(t *testing.T) {
+	t.Run("create, update and delete are audited", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		svc := testutil.NewTestAlertService(t, db)
+		fund := testutil.NewFund().Build(t, db)
+		ctx := context.Background()
+
+		rule := createAlertRule(t, svc, request.CreateAlertRuleRequest{
+			Name: "Cheap", Type: "fund_price", FundID: fund.ID, Direction: "below", Period: "week", Threshold: threshold(50),
+		})
+		if !rule.Enabled || rule.Period != "" || rule.Direction != model.AlertDirectionBelow {
+			t.Errorf("expected an enabled rule without unused fields, got %+v", rule)
+		}
+
+		disabled := false
+		updated, err := svc.UpdateAlertRule(ctx, rule.ID, request.UpdateAlertRuleRequest{
+			Name: "Weekly move", Type: "fund_move", FundID: fund.ID, Period: "week", Threshold: threshold(5), Enabled: &disabled,
+		})
+		if err != nil {
+			t.Fatalf("UpdateAlertRule: %v", err)
+		}
+		if updated.Enabled || updated.Type != model.AlertRuleFundMove || updated.Direction != "" || !updated.CreatedAt.Equal(rule.CreatedAt) {
+			t.Errorf("unexpected updated rule %+v", updated)
+		}
+
+		if err := svc.DeleteAlertRule(ctx, rule.ID); err != nil {
+			t.Fatalf("DeleteAlertRule: %v", err)
+		}
+		if _, err := svc.GetAlertRule(rule.ID); !errors.Is(err, apperrors.ErrAlertRuleNotFound) {
+			t.Errorf("expected ErrAlertRuleNotFound, got %v", err)
+		}
+		if n := countRows(t, db, "audit_event", "entity_type = ? AND entity_id = ?", model.AuditEntityAlertRule, rule.ID); n != 3 {
+			t.Errorf("expected 3 audit entries, got %d", n)
+		}
+	})
+
+	t.Run("unknown fund", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		svc := testutil.NewTestAlertService(t, db)
+
+		_, err := svc.CreateAlertRule(context.Background(), request.CreateAlertRuleRequest{
+			Name: "x", Type: "fund_price", FundID: testutil.MakeID(), Direction: "above", Threshold: threshold(1),
+		})
+		if !errors.Is(err, apperrors.ErrFundNotFound) {
+			t.Errorf("expected ErrFundNotFound, got %v", err)
+		}
+	})
+}
+
+//nolint:gocyclo // Test function walking alerts through their states.
+func TestAlertService_EvaluateFundAlerts(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	svc := testutil.NewTestAlertService(t, db)
+	publisher := &recordingPublisher{}
+	notifier := &recordingNotifier{}
+	svc.SetEventPublisher(publisher)
+	svc.SetNotifier(notifier)
+	ctx := context.Background()
+
+	// 100 until a week ago, 90 today: -10% over the week.
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	fund := testutil.NewFund().WithName("World ETF").Build(t, db)
+	for days := 20; days >= 7; days-- {
+		testutil.NewFundPrice(fund.ID).WithDate(today.AddDate(0, 0, -days)).WithPrice(100).Build(t, db)
+	}
+	testutil.NewFundPrice(fund.ID).WithDate(today).WithPrice(90).Build(t, db)
+
+	below := createAlertRule(t, svc, request.CreateAlertRuleRequest{Name: "Below 95", Type: "fund_price", FundID: fund.ID, Direction: "below", Threshold: threshold(95)})
+	createAlertRule(t, svc, request.CreateAlertRuleRequest{Name: "Above 200", Type: "fund_price", FundID: fund.ID, Direction: "above", Threshold: threshold(200)})
+	createAlertRule(t, svc, request.CreateAlertRuleRequest{Name: "Weekly move", Type: "fund_move", FundID: fund.ID, Period: "week", Threshold: threshold(5)})
+	createAlertRule(t, svc, request.CreateAlertRuleRequest{Name: "Daily move", Type: "fund_move", FundID: fund.ID, Period: "day", Threshold: threshold(5)})
+
+	result, err := svc.EvaluateFundAlerts(ctx)
+	if err != nil {
+		t.Fatalf("EvaluateFundAlerts: %v", err)
+	}
+	// The daily move compares with the last price a day or more before today: 100 a week ago.
+	if result.Evaluated != 4 || result.Triggered != 3 || result.Resolved != 0 {
+		t.Errorf("unexpected result %+v", result)
+	}
+
+	active, err := svc.GetAlerts([]model.AlertStatus{model.AlertStatusActive}, 50)
+	if err != nil {
+		t.Fatalf("GetAlerts: %v", err)
+	}
+	if len(active) != 3 {
+		t.Fatalf("expected 3 active alerts, got %+v", active)
+	}
+	for _, alert := range active {
+		if alert.RuleName == "Weekly move" && (math.Abs(alert.Value+10) > 1e-9 || !strings.Contains(alert.Message, "World ETF moved -10.00%")) {
+			t.Errorf("unexpected move alert %+v", alert)
+		}
+	}
+	if _, ok := publisher.find(model.WebhookEventAlertTriggered); !ok {
+		t.Error("expected an alert.triggered event")
+	}
+	if len(notifier.subjects) != 3 || !strings.HasPrefix(notifier.subjects[0], "Alert: ") {
+		t.Errorf("expected 3 notifications, got %v", notifier.subjects)
+	}
+
+	t.Run("open alerts are not raised again", func(t *testing.T) {
+		result, err := svc.EvaluateFundAlerts(ctx)
+		if err != nil || result.Triggered != 0 {
+			t.Errorf("expected no new alerts, got %+v (%v)", result, err)
+		}
+	})
+
+	t.Run("acknowledge", func(t *testing.T) {
+		alert, err := svc.AcknowledgeAlert(ctx, active[0].ID)
+		if err != nil || alert.Status != model.AlertStatusAcknowledged || alert.AcknowledgedAt == nil {
+			t.Fatalf("expected an acknowledged alert, got %+v (%v)", alert, err)
+		}
+		if _, err := svc.AcknowledgeAlert(ctx, active[0].ID); !errors.Is(err, apperrors.ErrAlertNotActive) {
+			t.Errorf("expected ErrAlertNotActive, got %v", err)
+		}
+		if _, err := svc.AcknowledgeAlert(ctx, testutil.MakeID()); !errors.Is(err, apperrors.ErrAlertNotFound) {
+			t.Errorf("expected ErrAlertNotFound, got %v", err)
+		}
+	})
+
+	t.Run("alerts resolve when the condition no longer holds", func(t *testing.T) {
+		if _, err := db.Exec(`UPDATE fund_price SET price = 100 WHERE fund_id = ? AND date = ?`, fund.ID, today.Format("2006-01-02")); err != nil {
+			t.Fatalf("update price: %v", err)
+		}
+		result, err := svc.EvaluateFundAlerts(ctx)
+		if err != nil || result.Resolved != 3 {
+			t.Fatalf("expected 3 resolved alerts, got %+v (%v)", result, err)
+		}
+		open, err := svc.GetAlerts([]model.AlertStatus{model.AlertStatusActive, model.AlertStatusAcknowledged}, 50)
+		if err != nil || len(open) != 0 {
+			t.Errorf("expected no open alerts, got %+v (%v)", open, err)
+		}
+		if _, ok := publisher.find(model.WebhookEventAlertResolved); !ok {
+			t.Error("expected an alert.resolved event")
+		}
+	})
+
+	t.Run("updating a rule resolves its open alert", func(t *testing.T) {
+		if _, err := db.Exec(`UPDATE fund_price SET price = 90 WHERE fund_id = ? AND date = ?`, fund.ID, today.Format("2006-01-02")); err != nil {
+			t.Fatalf("update price: %v", err)
+		}
+		if _, err := svc.EvaluateFundAlerts(ctx); err != nil {
+			t.Fatalf("EvaluateFundAlerts: %v", err)
+		}
+		if _, err := svc.UpdateAlertRule(ctx, below.ID, request.UpdateAlertRuleRequest{Name: "Below 80", Type: "fund_price", FundID: fund.ID, Direction: "below", Threshold: threshold(80)}); err != nil {
+			t.Fatalf("UpdateAlertRule: %v", err)
+		}
+		if n := countRows(t, db, "alert", "rule_id = ? AND status = ?", below.ID, model.AlertStatusActive); n != 0 {
+			t.Errorf("expected the open alert to be resolved, got %d active", n)
+		}
+	})
+}
+
+func TestAlertService_EvaluatePortfolioAlerts(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	svc := testutil.NewTestAlertService(t, db)
+	materialized := testutil.NewTestMaterializedService(t, db)
+	ctx := context.Background()
+
+	// 10 shares bought at 10.00, worth 12.00 today.
+	portfolio := buildDigestPortfolio(t, db)
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	fund := testutil.NewFund().Build(t, db)
+	held, err := db.Query(`SELECT fund_id FROM portfolio_fund WHERE portfolio_id = ?`, portfolio.ID)
+	if err != nil {
+		t.Fatalf("query portfolio fund: %v", err)
+	}
+	var heldFundID string
+	for held.Next() {
+		if err := held.Scan(&heldFundID); err != nil {
+			t.Fatalf("scan: %v", err)
+		}
+	}
+	_ = held.Close() //nolint:errcheck // Test query.
+
+	floor := createAlertRule(t, svc, request.CreateAlertRuleRequest{Name: "Floor", Type: "portfolio_value", PortfolioID: portfolio.ID, Threshold: threshold(150)})
+	createAlertRule(t, svc, request.CreateAlertRuleRequest{Name: "Loss", Type: "position_loss", PortfolioID: portfolio.ID, FundID: heldFundID, Threshold: threshold(10)})
+	createAlertRule(t, svc, request.CreateAlertRuleRequest{Name: "Not held", Type: "position_loss", PortfolioID: portfolio.ID, FundID: fund.ID, Threshold: threshold(10)})
+
+	t.Run("skipped without materialized history", func(t *testing.T) {
+		result, err := svc.EvaluatePortfolioAlerts(ctx, portfolio.ID)
+		if err != nil || result.Evaluated != 0 {
+			t.Errorf("expected nothing evaluated, got %+v (%v)", result, err)
+		}
+	})
+
+	if err := materialized.RegenerateMaterializedTable(ctx, today.AddDate(0, 0, -30), []string{portfolio.ID}, "", ""); err != nil {
+		t.Fatalf("RegenerateMaterializedTable: %v", err)
+	}
+
+	t.Run("value below the floor", func(t *testing.T) {
+		result, err := svc.EvaluatePortfolioAlerts(ctx, portfolio.ID)
+		if err != nil {
+			t.Fatalf("EvaluatePortfolioAlerts: %v", err)
+		}
+		if result.Evaluated != 2 || result.Triggered != 1 {
+			t.Errorf("unexpected result %+v", result)
+		}
+		if n := countRows(t, db, "alert", "rule_id = ? AND status = ?", floor.ID, model.AlertStatusActive); n != 1 {
+			t.Errorf("expected an active floor alert, got %d", n)
+		}
+	})
+
+	t.Run("position loss", func(t *testing.T) {
+		// 10 shares at 8.00 against a cost of 100 is a 20% loss.
+		if _, err := db.Exec(`UPDATE fund_price SET price = 8 WHERE fund_id = ? AND date = ?`, heldFundID, today.Format("2006-01-02")); err != nil {
+			t.Fatalf("update price: %v", err)
+		}
+		if err := materialized.RegenerateMaterializedTable(ctx, today, []string{portfolio.ID}, "", ""); err != nil {
+			t.Fatalf("RegenerateMaterializedTable: %v", err)
+		}
+		result, err := svc.EvaluatePortfolioAlerts(ctx, portfolio.ID)
+		if err != nil || result.Triggered != 1 {
+			t.Fatalf("expected the loss alert, got %+v (%v)", result, err)
+		}
+		alerts, err := svc.GetAlerts([]model.AlertStatus{model.AlertStatusActive}, 50)
+		if err != nil || len(alerts) != 2 {
+			t.Fatalf("expected 2 active alerts, got %+v (%v)", alerts, err)
+		}
+		for _, alert := range alerts {
+			if alert.RuleType == model.AlertRulePositionLoss && math.Abs(alert.Value-20) > 1e-9 {
+				t.Errorf("expected a 20%% loss, got %+v", alert)
+			}
+		}
+	})
+}
+
+func TestAlertService_EvaluationFailures(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	svc := testutil.NewTestAlertService(t, db)
+	materialized := testutil.NewTestMaterializedService(t, db)
+	ctx := context.Background()
+
+	portfolio := buildDigestPortfolio(t, db)
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	if err := materialized.RegenerateMaterializedTable(ctx, today.AddDate(0, 0, -30), []string{portfolio.ID}, "", ""); err != nil {
+		t.Fatalf("RegenerateMaterializedTable: %v", err)
+	}
+
+	// A rule whose portfolio is gone fails to evaluate. Foreign keys are a per-connection
+	// setting, so the rule is inserted on a connection with them turned off.
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		t.Fatalf("conn: %v", err)
+	}
+	now := time.Now().UTC().Format("2006-01-02 15:04:05")
+	for _, stmt := range []string{`PRAGMA foreign_keys = OFF`,
+		`INSERT INTO alert_rule (id, name, type, portfolio_id, threshold, enabled, created_at, updated_at)
+			VALUES ('` + testutil.MakeID() + `', 'Orphan', 'portfolio_value', '` + testutil.MakeID() + `', 1000, 1, '` + now + `', '` + now + `')`,
+		`PRAGMA foreign_keys = ON`} {
+		if _, err := conn.ExecContext(ctx, stmt); err != nil {
+			t.Fatalf("exec %q: %v", stmt, err)
+		}
+	}
+	_ = conn.Close() //nolint:errcheck // Test connection.
+	floor := createAlertRule(t, svc, request.CreateAlertRuleRequest{Name: "Floor", Type: "portfolio_value", PortfolioID: portfolio.ID, Threshold: threshold(150)})
+
+	result, err := svc.EvaluatePortfolioAlerts(ctx, "")
+	if err != nil {
+		t.Fatalf("expected the failing rule not to fail the run, got %v", err)
+	}
+	if result.Failed != 1 || result.Evaluated != 1 || result.Triggered != 1 {
+		t.Errorf("unexpected result %+v", result)
+	}
+	if n := countRows(t, db, "alert", "rule_id = ? AND status = ?", floor.ID, model.AlertStatusActive); n != 1 {
+		t.Errorf("expected the floor alert despite the failing rule, got %d", n)
+	}
+}
+
+// acknowledgingNotifier acknowledges the triggered alert while it is being notified,
+// which blocks if notifications are sent with the evaluation lock held.
+type acknowledgingNotifier struct {
+	svc *service.AlertService
+	err error
+}
+
+func (n *acknowledgingNotifier) Notify(ctx context.Context, _, _ string) error {
+	alerts, err := n.svc.GetAlerts([]model.AlertStatus{model.AlertStatusActive}, 1)
+	if err != nil || len(alerts) != 1 {
+		n.err = fmt.Errorf("expected the triggered alert, got %+v (%w)", alerts, err)
+		return nil
+	}
+	done := make(chan error, 1)
+	go func() {
+		_, err := n.svc.AcknowledgeAlert(ctx, alerts[0].ID)
+		done <- err
+	}()
+	select {
+	case n.err = <-done:
+	case <-time.After(2 * time.Second):
+		n.err = errors.New("acknowledging blocked while the notification was sent")
+	}
+	return nil
+}
+
+func TestAlertService_NotifiesOutsideTheLock(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	svc := testutil.NewTestAlertService(t, db)
+	notifier := &acknowledgingNotifier{svc: svc}
+	svc.SetNotifier(notifier)
+
+	fund := testutil.NewFund().Build(t, db)
+	testutil.NewFundPrice(fund.ID).WithDate(time.Now().UTC().Truncate(24*time.Hour)).WithPrice(10).Build(t, db)
+	createAlertRule(t, svc, request.CreateAlertRuleRequest{Name: "Below 20", Type: "fund_price", FundID: fund.ID, Direction: "below", Threshold: threshold(20)})
+
+	result, err := svc.EvaluateFundAlerts(context.Background())
+	if err != nil || result.Triggered != 1 {
+		t.Fatalf("expected one triggered alert, got %+v (%v)", result, err)
+	}
+	if notifier.err != nil {
+		t.Error(notifier.err)
+	}
+}
@@ -0,0 +1,385 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/api/request"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/apperrors"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/logging"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/model"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/repository"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/tracing"
+)
+
+var allocationLog = logging.NewLogger("portfolio")
+
+// minTradeAmount is the smallest trade worth suggesting; smaller gaps are rounding noise.
+const minTradeAmount = 0.01
+
+// AllocationService manages the target allocation of portfolios and calculates the
+// trades that bring a portfolio back to its targets.
+type AllocationService struct {
+	db             *sql.DB
+	allocationRepo *repository.AllocationRepository
+	auditRepo      *repository.AuditRepository
+	portfolioRepo  *repository.PortfolioRepository
+	pfRepo         *repository.PortfolioFundRepository
+	fundService    *FundService
+}
+
+// NewAllocationService creates a new AllocationService with the provided dependencies.
+func NewAllocationService(
+	db *sql.DB,
+	allocationRepo *repository.AllocationRepository,
+	auditRepo *repository.AuditRepository,
+	portfolioRepo *repository.PortfolioRepository,
+	pfRepo *repository.PortfolioFundRepository,
+	fundService *FundService,
+) *AllocationService {
+	return &AllocationService{
+		db:             db,
+		allocationRepo: allocationRepo,
+		auditRepo:      auditRepo,
+		portfolioRepo:  portfolioRepo,
+		pfRepo:         pfRepo,
+		fundService:    fundService,
+	}
+}
+
+// GetAllocationTargets retrieves the allocation targets of a portfolio.
+// Returns ErrPortfolioNotFound if the portfolio does not exist.
+func (s *AllocationService) GetAllocationTargets(portfolioID string) ([]model.AllocationTarget, error) {
+	if _, err := s.portfolioRepo.GetPortfolioOnID(portfolioID); err != nil {
+		return nil, fmt.Errorf("get portfolio: %w", err)
+	}
+	targets, err := s.allocationRepo.GetAllocationTargets(portfolioID)
+	if err != nil {
+		return nil, fmt.Errorf("get allocation targets: %w", err)
+	}
+	return targets, nil
+}
+
+// SetAllocationTargets replaces the allocation targets of a portfolio and returns the
+// stored targets. Returns ErrPortfolioNotFound if the portfolio does not exist and
+// ErrFundNotInPortfolio if a fund target refers to a fund the portfolio does not hold.
+func (s *AllocationService) SetAllocationTargets(ctx context.Context, portfolioID string, req request.SetAllocationTargetsRequest) ([]model.AllocationTarget, error) {
+	ctx, span := tracing.Start(ctx, "AllocationService.SetAllocationTargets")
+	defer span.End()
+
+	before, err := s.GetAllocationTargets(portfolioID)
+	if err != nil {
+		return nil, err
+	}
+	portfolioFunds, err := s.pfRepo.GetPortfolioFunds(portfolioID)
+	if err != nil {
+		return nil, fmt.Errorf("get portfolio funds: %w", err)
+	}
+	held := make(map[string]bool, len(portfolioFunds))
+	for _, pf := range portfolioFunds {
+		held[pf.FundID] = true
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	targets := make([]model.AllocationTarget, 0, len(req.Targets))
+	for _, t := range req.Targets {
+		if t.FundID != "" && !held[t.FundID] {
+			return nil, fmt.Errorf("fund %s: %w", t.FundID, apperrors.ErrFundNotInPortfolio)
+		}
+		targets = append(targets, model.AllocationTarget{
+			ID:               uuid.New().String(),
+			PortfolioID:      portfolioID,
+			FundID:           t.FundID,
+			AssetClass:       t.AssetClass,
+			TargetPercent:    t.TargetPercent,
+			TolerancePercent: t.TolerancePercent,
+			CreatedAt:        now,
+			UpdatedAt:        now,
+		})
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }() //nolint:errcheck // Rollback is a no-op after Commit; error is intentionally ignored.
+
+	if err := s.allocationRepo.WithTx(tx).ReplaceAllocationTargets(ctx, portfolioID, targets); err != nil {
+		return nil, fmt.Errorf("replace allocation targets: %w", err)
+	}
+	if err := recordAudit(ctx, s.auditRepo.WithTx(tx), model.AuditEntityAllocation, portfolioID, model.AuditActionUpdate, before, targets); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit transaction: %w", err)
+	}
+
+	allocationLog.InfoContext(ctx, "allocation targets updated", "portfolio_id", portfolioID, "targets", len(targets))
+	stored, err := s.allocationRepo.GetAllocationTargets(portfolioID)
+	if err != nil {
+		return nil, fmt.Errorf("get allocation targets: %w", err)
+	}
+	return stored, nil
+}
+
+// rebalanceHolding is one fund of the portfolio valued at its latest price.
+type rebalanceHolding struct {
+	fund      model.PortfolioFundResponse
+	price     float64
+	priceDate string
+	value     float64
+}
+
+// rebalanceGroup collects the holdings that share an allocation target.
+type rebalanceGroup struct {
+	allocation model.RebalanceAllocation
+	holdings   []*rebalanceHolding
+	amount     float64 // Money to move into (positive) or out of (negative) the group
+}
+
+// GetRebalancePlan compares the current weights of a portfolio with its allocation targets
+// and suggests the buys and sells that close the gap, valued at the latest fund prices.
+//
+// In full mode the contribution is added to the portfolio value and every allocation is
+// brought to its target; trades are only suggested when an allocation drifted outside its
+// tolerance or there is a contribution to invest. In new_money mode nothing is sold: the
+// contribution goes to the underweight allocations first, in proportion to how far they
+// are below target, and whatever remains is spread by target weight.
+//
+// Asset class trades are spread over the funds of the class in proportion to their value,
+// or evenly when the class holds nothing yet. Money meant for an allocation without a
+// priced fund is reported as Unallocated.
+//
+// Returns ErrPortfolioNotFound if the portfolio does not exist and ErrNoAllocationTargets
+// if it has no targets.
+func (s *AllocationService) GetRebalancePlan(ctx context.Context, portfolioID string, mode model.RebalanceMode, contribution float64) (plan model.RebalancePlan, err error) {
+	ctx, span := tracing.Start(ctx, "AllocationService.GetRebalancePlan")
+	defer func() { tracing.End(span, err) }()
+
+	targets, err := s.GetAllocationTargets(portfolioID)
+	if err != nil {
+		return model.RebalancePlan{}, err
+	}
+	if len(targets) == 0 {
+		return model.RebalancePlan{}, apperrors.ErrNoAllocationTargets
+	}
+
+	holdings, err := s.loadRebalanceHoldings(ctx, portfolioID)
+	if err != nil {
+		return model.RebalancePlan{}, err
+	}
+
+	basis := model.AllocationBasisFund
+	if targets[0].AssetClass != "" {
+		basis = model.AllocationBasisAssetClass
+	}
+	groups := groupRebalanceHoldings(basis, targets, holdings)
+
+	plan = model.RebalancePlan{
+		PortfolioID:  portfolioID,
+		Basis:        basis,
+		Mode:         mode,
+		Contribution: round(contribution),
+		Allocations:  make([]model.RebalanceAllocation, 0, len(groups)),
+		Trades:       []model.RebalanceTrade{},
+	}
+	var total float64
+	for _, h := range holdings {
+		total += h.value
+	}
+	targetTotal := total + contribution
+
+	for _, g := range groups {
+		if total > 0 {
+			g.allocation.CurrentPercent = g.allocation.CurrentValue / total * 100
+		}
+		g.allocation.Drift = g.allocation.CurrentPercent - g.allocation.TargetPercent
+		g.allocation.WithinTolerance = math.Abs(g.allocation.Drift) <= g.allocation.TolerancePercent+1e-9
+		if !g.allocation.WithinTolerance {
+			plan.NeedsRebalancing = true
+		}
+	}
+
+	if mode == model.RebalanceModeNewMoney {
+		allocateNewMoney(groups, targetTotal, contribution)
+	} else if plan.NeedsRebalancing || contribution > 0 {
+		for _, g := range groups {
+			g.amount = targetTotal*g.allocation.TargetPercent/100 - g.allocation.CurrentValue
+		}
+	}
+
+	var sells, buys []model.RebalanceTrade
+	for _, g := range groups {
+		trades, unallocated := g.trades()
+		plan.Unallocated += unallocated
+		for _, trade := range trades {
+			if trade.Action == model.TradeActionSell {
+				sells = append(sells, trade)
+			} else {
+				buys = append(buys, trade)
+			}
+		}
+
+		a := g.allocation
+		a.CurrentValue = round(a.CurrentValue)
+		a.CurrentPercent = round(a.CurrentPercent)
+		a.Drift = round(a.Drift)
+		plan.Allocations = append(plan.Allocations, a)
+	}
+	plan.Trades = append(append(plan.Trades, sells...), buys...)
+	plan.CurrentValue = round(total)
+	plan.TargetValue = round(targetTotal)
+	plan.Unallocated = round(plan.Unallocated)
+	return plan, nil
+}
+
+// loadRebalanceHoldings returns the funds of a portfolio with their current share count,
+// valued at the latest known price of each fund.
+func (s *AllocationService) loadRebalanceHoldings(ctx context.Context, portfolioID string) ([]*rebalanceHolding, error) {
+	portfolioFunds, err := s.fundService.GetPortfolioFunds(portfolioID)
+	if err != nil {
+		return nil, fmt.Errorf("get portfolio funds: %w", err)
+	}
+	if len(portfolioFunds) == 0 {
+		return nil, nil
+	}
+
+	fundIDs := make([]string, 0, len(portfolioFunds))
+	for _, pf := range portfolioFunds {
+		fundIDs = append(fundIDs, pf.FundID)
+	}
+	prices, err := s.fundService.LoadLatestFundPrices(fundIDs)
+	if err != nil {
+		return nil, fmt.Errorf("load latest fund prices: %w", err)
+	}
+
+	holdings := make([]*rebalanceHolding, 0, len(portfolioFunds))
+	for _, pf := range portfolioFunds {
+		h := &rebalanceHolding{fund: pf}
+		if latest, ok := prices[pf.FundID]; ok {
+			h.price = latest.Price
+			h.priceDate = latest.Date.Format("2006-01-02")
+			h.value = pf.TotalShares * h.price
+		} else {
+			allocationLog.WarnContext(ctx, "fund has no price, excluded from rebalancing", "portfolio_id", portfolioID, "fund_id", pf.FundID)
+		}
+		holdings = append(holdings, h)
+	}
+	return holdings, nil
+}
+
+// groupRebalanceHoldings returns one group per target, in target order, followed by a
+// group with a 0% target for every held fund or asset class without a target.
+func groupRebalanceHoldings(basis model.AllocationBasis, targets []model.AllocationTarget, holdings []*rebalanceHolding) []*rebalanceGroup {
+	key := func(h *rebalanceHolding) string {
+		if basis == model.AllocationBasisAssetClass {
+			return h.fund.InvestmentType
+		}
+		return h.fund.FundID
+	}
+
+	groups := make([]*rebalanceGroup, 0, len(targets))
+	byKey := make(map[string]*rebalanceGroup, len(targets))
+	for _, t := range targets {
+		g := &rebalanceGroup{allocation: model.RebalanceAllocation{
+			FundID:           t.FundID,
+			FundName:         t.FundName,
+			AssetClass:       t.AssetClass,
+			TargetPercent:    t.TargetPercent,
+			TolerancePercent: t.TolerancePercent,
+		}}
+		groups = append(groups, g)
+		byKey[t.FundID+t.AssetClass] = g
+	}
+
+	for _, h := range holdings {
+		g, ok := byKey[key(h)]
+		if !ok {
+			g = &rebalanceGroup{}
+			if basis == model.AllocationBasisAssetClass {
+				g.allocation.AssetClass = h.fund.InvestmentType
+			} else {
+				g.allocation.FundID = h.fund.FundID
+				g.allocation.FundName = h.fund.FundName
+			}
+			groups = append(groups, g)
+			byKey[key(h)] = g
+		}
+		g.holdings = append(g.holdings, h)
+		g.allocation.CurrentValue += h.value
+	}
+	return groups
+}
+
+// allocateNewMoney spreads contribution over the groups without selling. Groups below
+// their target value receive money in proportion to their shortfall; once every shortfall
+// is covered the rest is spread by target weight.
+func allocateNewMoney(groups []*rebalanceGroup, targetTotal, contribution float64) {
+	shortfalls := make([]float64, len(groups))
+	var totalShortfall float64
+	for i, g := range groups {
+		shortfalls[i] = math.Max(0, targetTotal*g.allocation.TargetPercent/100-g.allocation.CurrentValue)
+		totalShortfall += shortfalls[i]
+	}
+
+	for i, g := range groups {
+		if totalShortfall > contribution {
+			g.amount = contribution * shortfalls[i] / totalShortfall
+		} else {
+			g.amount = shortfalls[i] + (contribution-totalShortfall)*g.allocation.TargetPercent/100
+		}
+	}
+}
+
+// trades spreads the group's amount over its priced holdings. Sells are spread by value;
+// buys by value, or evenly when the group holds nothing yet. It also returns the part of a
+// buy that no holding can receive.
+func (g *rebalanceGroup) trades() ([]model.RebalanceTrade, float64) {
+	if math.Abs(g.amount) < minTradeAmount {
+		return nil, 0
+	}
+
+	priced := make([]*rebalanceHolding, 0, len(g.holdings))
+	var pricedValue float64
+	for _, h := range g.holdings {
+		if h.price > 0 {
+			priced = append(priced, h)
+			pricedValue += h.value
+		}
+	}
+	if len(priced) == 0 || (g.amount < 0 && pricedValue == 0) {
+		return nil, math.Max(0, g.amount)
+	}
+
+	trades := make([]model.RebalanceTrade, 0, len(priced))
+	for _, h := range priced {
+		share := 1 / float64(len(priced))
+		if pricedValue > 0 {
+			share = h.value / pricedValue
+		}
+		amount := g.amount * share
+		if math.Abs(amount) < minTradeAmount {
+			continue
+		}
+
+		action := model.TradeActionBuy
+		if amount < 0 {
+			action = model.TradeActionSell
+		}
+		trades = append(trades, model.RebalanceTrade{
+			PortfolioFundID: h.fund.ID,
+			FundID:          h.fund.FundID,
+			FundName:        h.fund.FundName,
+			Action:          action,
+			Price:           h.price,
+			PriceDate:       h.priceDate,
+			Shares:          round(math.Abs(amount) / h.price),
+			Amount:          round(math.Abs(amount)),
+		})
+	}
+	return trades, 0
+}
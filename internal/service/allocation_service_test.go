@@ -0,0 +1,211 @@
+package service_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/api/request"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/apperrors"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/model"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/testutil"
+)
+
+// buildRebalancePortfolio creates a portfolio holding 10 shares of a fund priced at 15
+// and 10 shares of a stock priced at 5, so the fund is 75% of its value of 200.
+func buildRebalancePortfolio(t *testing.T, db *sql.DB) (portfolio model.Portfolio, fund, stock model.Fund) {
+	t.Helper()
+	portfolio = testutil.NewPortfolio().Build(t, db)
+	fund = testutil.NewFund().WithName("World ETF").WithInvestmentType("FUND").Build(t, db)
+	stock = testutil.NewFund().WithName("Chip Maker").WithInvestmentType("STOCK").Build(t, db)
+
+	bought := time.Now().UTC().AddDate(0, 0, -30).Truncate(24 * time.Hour)
+	for _, f := range []struct {
+		fund  model.Fund
+		price float64
+	}{{fund, 15}, {stock, 5}} {
+		pf := testutil.NewPortfolioFund(portfolio.ID, f.fund.ID).Build(t, db)
+		testutil.NewTransaction(pf.ID).WithDate(bought).WithShares(10).WithCostPerShare(10).Build(t, db)
+		testutil.NewFundPrice(f.fund.ID).WithDate(bought).WithPrice(10).Build(t, db)
+		testutil.NewFundPrice(f.fund.ID).WithDate(time.Now().UTC().Truncate(24*time.Hour)).WithPrice(f.price).Build(t, db)
+	}
+	return portfolio, fund, stock
+}
+
+func findTrade(plan model.RebalancePlan, fundID string) (model.RebalanceTrade, bool) {
+	for _, trade := range plan.Trades {
+		if trade.FundID == fundID {
+			return trade, true
+		}
+	}
+	return model.RebalanceTrade{}, false
+}
+
+func TestAllocationService_SetAllocationTargets(t *testing.T) {
+	t.Run("replaces the targets and records an audit event", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		svc := testutil.NewTestAllocationService(t, db)
+		portfolio, fund, stock := buildRebalancePortfolio(t, db)
+		ctx := context.Background()
+
+		if _, err := svc.SetAllocationTargets(ctx, portfolio.ID, request.SetAllocationTargetsRequest{Targets: []request.AllocationTargetRequest{
+			{FundID: fund.ID, TargetPercent: 100},
+		}}); err != nil {
+			t.Fatalf("SetAllocationTargets: %v", err)
+		}
+		targets, err := svc.SetAllocationTargets(ctx, portfolio.ID, request.SetAllocationTargetsRequest{Targets: []request.AllocationTargetRequest{
+			{FundID: fund.ID, TargetPercent: 60, TolerancePercent: 5},
+			{FundID: stock.ID, TargetPercent: 40, TolerancePercent: 5},
+		}})
+		if err != nil {
+			t.Fatalf("SetAllocationTargets: %v", err)
+		}
+
+		if len(targets) != 2 || targets[0].FundID != fund.ID || targets[0].FundName != "World ETF" || targets[1].TargetPercent != 40 {
+			t.Errorf("expected the two new targets, got %+v", targets)
+		}
+		if n := countRows(t, db, "audit_event", "entity_type = ? AND entity_id = ?", model.AuditEntityAllocation, portfolio.ID); n != 2 {
+			t.Errorf("expected 2 audit events, got %d", n)
+		}
+	})
+
+	t.Run("rejects a fund outside the portfolio", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		svc := testutil.NewTestAllocationService(t, db)
+		portfolio, _, _ := buildRebalancePortfolio(t, db)
+		other := testutil.NewFund().Build(t, db)
+
+		_, err := svc.SetAllocationTargets(context.Background(), portfolio.ID, request.SetAllocationTargetsRequest{Targets: []request.AllocationTargetRequest{
+			{FundID: other.ID, TargetPercent: 100},
+		}})
+		if !errors.Is(err, apperrors.ErrFundNotInPortfolio) {
+			t.Errorf("expected ErrFundNotInPortfolio, got %v", err)
+		}
+	})
+
+	t.Run("unknown portfolio", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		svc := testutil.NewTestAllocationService(t, db)
+
+		_, err := svc.SetAllocationTargets(context.Background(), testutil.MakeID(), request.SetAllocationTargetsRequest{})
+		if !errors.Is(err, apperrors.ErrPortfolioNotFound) {
+			t.Errorf("expected ErrPortfolioNotFound, got %v", err)
+		}
+	})
+}
+
+func TestAllocationService_GetRebalancePlan(t *testing.T) {
+	setup := func(t *testing.T, targets ...request.AllocationTargetRequest) (func(model.RebalanceMode, float64) model.RebalancePlan, model.Fund, model.Fund) {
+		t.Helper()
+		db := testutil.SetupTestDB(t)
+		svc := testutil.NewTestAllocationService(t, db)
+		portfolio, fund, stock := buildRebalancePortfolio(t, db)
+		for i := range targets {
+			switch targets[i].FundID {
+			case "fund":
+				targets[i].FundID = fund.ID
+			case "stock":
+				targets[i].FundID = stock.ID
+			}
+		}
+		if _, err := svc.SetAllocationTargets(context.Background(), portfolio.ID, request.SetAllocationTargetsRequest{Targets: targets}); err != nil {
+			t.Fatalf("SetAllocationTargets: %v", err)
+		}
+		plan := func(mode model.RebalanceMode, contribution float64) model.RebalancePlan {
+			t.Helper()
+			p, err := svc.GetRebalancePlan(context.Background(), portfolio.ID, mode, contribution)
+			if err != nil {
+				t.Fatalf("GetRebalancePlan: %v", err)
+			}
+			return p
+		}
+		return plan, fund, stock
+	}
+
+	t.Run("full mode sells the overweight fund and buys the underweight one", func(t *testing.T) {
+		plan, fund, stock := setup(t,
+			request.AllocationTargetRequest{FundID: "fund", TargetPercent: 50, TolerancePercent: 5},
+			request.AllocationTargetRequest{FundID: "stock", TargetPercent: 50, TolerancePercent: 5},
+		)
+		p := plan(model.RebalanceModeFull, 0)
+
+		if p.CurrentValue != 200 || !p.NeedsRebalancing || p.Basis != model.AllocationBasisFund {
+			t.Fatalf("expected a 200 portfolio needing rebalancing, got %+v", p)
+		}
+		if a := p.Allocations[1]; a.FundID != fund.ID || a.CurrentPercent != 75 || a.Drift != 25 || a.WithinTolerance {
+			t.Errorf("expected the fund 25 points over target, got %+v", a)
+		}
+		sell, ok := findTrade(p, fund.ID)
+		if !ok || sell.Action != model.TradeActionSell || sell.Amount != 50 || math.Abs(sell.Shares-50.0/15) > 1e-5 {
+			t.Errorf("expected to sell 50 of the fund, got %+v", sell)
+		}
+		buy, ok := findTrade(p, stock.ID)
+		if !ok || buy.Action != model.TradeActionBuy || buy.Amount != 50 || buy.Shares != 10 {
+			t.Errorf("expected to buy 10 shares of the stock, got %+v", buy)
+		}
+		if p.Trades[0].Action != model.TradeActionSell {
+			t.Errorf("expected sells before buys, got %+v", p.Trades)
+		}
+	})
+
+	t.Run("new money mode only buys", func(t *testing.T) {
+		plan, _, stock := setup(t,
+			request.AllocationTargetRequest{FundID: "fund", TargetPercent: 50, TolerancePercent: 5},
+			request.AllocationTargetRequest{FundID: "stock", TargetPercent: 50, TolerancePercent: 5},
+		)
+		p := plan(model.RebalanceModeNewMoney, 100)
+
+		if len(p.Trades) != 1 {
+			t.Fatalf("expected a single buy, got %+v", p.Trades)
+		}
+		if trade := p.Trades[0]; trade.FundID != stock.ID || trade.Action != model.TradeActionBuy || trade.Amount != 100 || trade.Shares != 20 {
+			t.Errorf("expected to buy 20 shares of the stock, got %+v", trade)
+		}
+		if p.TargetValue != 300 || p.Unallocated != 0 {
+			t.Errorf("expected the full contribution invested, got %+v", p)
+		}
+	})
+
+	t.Run("new money beyond the shortfall is spread by target", func(t *testing.T) {
+		plan, fund, stock := setup(t,
+			request.AllocationTargetRequest{FundID: "fund", TargetPercent: 50},
+			request.AllocationTargetRequest{FundID: "stock", TargetPercent: 50},
+		)
+		p := plan(model.RebalanceModeNewMoney, 300)
+
+		fundBuy, _ := findTrade(p, fund.ID)
+		stockBuy, _ := findTrade(p, stock.ID)
+		if fundBuy.Amount != 100 || stockBuy.Amount != 200 {
+			t.Errorf("expected buys of 100 and 200, got %+v and %+v", fundBuy, stockBuy)
+		}
+	})
+
+	t.Run("asset classes within tolerance need no trades", func(t *testing.T) {
+		plan, _, _ := setup(t,
+			request.AllocationTargetRequest{AssetClass: "FUND", TargetPercent: 80, TolerancePercent: 10},
+			request.AllocationTargetRequest{AssetClass: "STOCK", TargetPercent: 20, TolerancePercent: 10},
+		)
+		p := plan(model.RebalanceModeFull, 0)
+
+		if p.Basis != model.AllocationBasisAssetClass || p.NeedsRebalancing || len(p.Trades) != 0 {
+			t.Errorf("expected a balanced asset class plan without trades, got %+v", p)
+		}
+		if a := p.Allocations[0]; a.AssetClass != "FUND" || a.CurrentValue != 150 || a.Drift != -5 || !a.WithinTolerance {
+			t.Errorf("expected FUND 5 points under target, got %+v", a)
+		}
+	})
+
+	t.Run("no targets", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		svc := testutil.NewTestAllocationService(t, db)
+		portfolio, _, _ := buildRebalancePortfolio(t, db)
+
+		_, err := svc.GetRebalancePlan(context.Background(), portfolio.ID, model.RebalanceModeFull, 0)
+		if !errors.Is(err, apperrors.ErrNoAllocationTargets) {
+			t.Errorf("expected ErrNoAllocationTargets, got %v", err)
+		}
+	})
+}
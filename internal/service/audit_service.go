@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/logging"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/model"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/repository"
+)
+
+var auditLog = logging.NewLogger("security")
+
+// AuditService exposes the audit trail for querying.
+// Audit events themselves are written by the mutating service methods via recordAudit,
+// inside the same database transaction as the change they describe.
+type AuditService struct {
+	auditRepo *repository.AuditRepository
+}
+
+// NewAuditService creates a new AuditService with the provided repository dependency.
+func NewAuditService(auditRepo *repository.AuditRepository) *AuditService {
+	return &AuditService{
+		auditRepo: auditRepo,
+	}
+}
+
+// GetAuditEvents retrieves audit events matching the given filters with cursor-based pagination.
+func (s *AuditService) GetAuditEvents(filters *model.AuditFilters) (*model.AuditEventResponse, error) {
+	auditLog.Debug("retrieving audit events", "entity_id", filters.EntityID, "request_id", filters.RequestID)
+	result, err := s.auditRepo.GetAuditEvents(filters)
+	if err != nil {
+		return nil, fmt.Errorf("get audit events: %w", err)
+	}
+	return result, nil
+}
+
+// recordAudit writes an audit event through a transaction-scoped AuditRepository.
+// before and after are JSON-encoded snapshots of the record; pass nil for the side that
+// does not exist (before on create, after on delete). Request ID, client IP and user agent
+// are taken from the request context populated by the logging middleware.
+//
+// The caller must return the error so the surrounding transaction is rolled back:
+// a mutation without its audit event must never be committed.
+func recordAudit(
+	ctx context.Context,
+	repo *repository.AuditRepository,
+	entityType model.AuditEntityType,
+	entityID string,
+	action model.AuditAction,
+	before, after any,
+) error {
+	event := &model.AuditEvent{
+		ID:         uuid.New().String(),
+		Timestamp:  time.Now().UTC(),
+		EntityType: string(entityType),
+		EntityID:   entityID,
+		Action:     string(action),
+		UserID:     logging.UserIDFromContext(ctx),
+		RequestID:  logging.RequestIDFromContext(ctx),
+		IPAddress:  logging.IPFromContext(ctx),
+		UserAgent:  logging.UserAgentFromContext(ctx),
+	}
+
+	var err error
+	if before != nil {
+		if event.Before, err = json.Marshal(before); err != nil {
+			return fmt.Errorf("marshal audit before state: %w", err)
+		}
+	}
+	if after != nil {
+		if event.After, err = json.Marshal(after); err != nil {
+			return fmt.Errorf("marshal audit after state: %w", err)
+		}
+	}
+
+	if err := repo.InsertAuditEvent(ctx, event); err != nil {
+		return fmt.Errorf("record audit event: %w", err)
+	}
+	return nil
+}
+
+// recordAuditUpsert records an upsert as a "create" when before is nil and as an "update" otherwise.
+func recordAuditUpsert[T any](
+	ctx context.Context,
+	repo *repository.AuditRepository,
+	entityType model.AuditEntityType,
+	entityID string,
+	before *T,
+	after T,
+) error {
+	if before == nil {
+		return recordAudit(ctx, repo, entityType, entityID, model.AuditActionCreate, nil, after)
+	}
+	return recordAudit(ctx, repo, entityType, entityID, model.AuditActionUpdate, before, after)
+}
@@ -0,0 +1,227 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/api/request"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/logging"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/model"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/testutil"
+)
+
+//nolint:gocyclo // Test function with multiple subtests and assertions.
+func TestAuditService_RecordsMutations(t *testing.T) {
+	t.Run("create, update and delete portfolio are audited with request metadata", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		pfSvc := testutil.NewTestPortfolioService(t, db)
+		auditSvc := testutil.NewTestAuditService(t, db)
+		ctx := logging.WithRequestInfo(context.Background(), "req-audit", "192.0.2.1", "audit-test")
+
+		p, err := pfSvc.CreatePortfolio(ctx, request.CreatePortfolioRequest{Name: "Before"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		newName := "After"
+		if _, err := pfSvc.UpdatePortfolio(ctx, p.ID, request.UpdatePortfolioRequest{Name: &newName}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := pfSvc.DeletePortfolio(ctx, p.ID); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		result, err := auditSvc.GetAuditEvents(&model.AuditFilters{EntityID: p.ID, SortDir: "asc", PerPage: 50})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.Count != 3 {
+			t.Fatalf("expected 3 events, got %d", result.Count)
+		}
+
+		wantActions := []string{"create", "update", "delete"}
+		for i, e := range result.Events {
+			if e.Action != wantActions[i] {
+				t.Errorf("event %d: expected action %s, got %s", i, wantActions[i], e.Action)
+			}
+			if e.EntityType != string(model.AuditEntityPortfolio) {
+				t.Errorf("event %d: expected entity type portfolio, got %s", i, e.EntityType)
+			}
+			if e.RequestID != "req-audit" || e.IPAddress != "192.0.2.1" || e.UserAgent != "audit-test" {
+				t.Errorf("event %d: unexpected request metadata %+v", i, e)
+			}
+		}
+
+		create, update, del := result.Events[0], result.Events[1], result.Events[2]
+		if len(create.Before) != 0 || len(create.After) == 0 {
+			t.Error("expected create to carry only an after snapshot")
+		}
+		if len(del.Before) == 0 || len(del.After) != 0 {
+			t.Error("expected delete to carry only a before snapshot")
+		}
+
+		var before, after model.Portfolio
+		if err := json.Unmarshal(update.Before, &before); err != nil {
+			t.Fatalf("unmarshal before: %v", err)
+		}
+		if err := json.Unmarshal(update.After, &after); err != nil {
+			t.Fatalf("unmarshal after: %v", err)
+		}
+		if before.Name != "Before" || after.Name != "After" {
+			t.Errorf("expected name Before -> After, got %s -> %s", before.Name, after.Name)
+		}
+	})
+
+	t.Run("fund price imports record one event with the replaced prices", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		devSvc := testutil.NewTestDeveloperService(t, db)
+		auditSvc := testutil.NewTestAuditService(t, db)
+		fund := testutil.NewFund().Build(t, db)
+		testutil.NewFundPrice(fund.ID).WithDate(time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC)).WithPrice(10).Build(t, db)
+		testutil.NewFundPrice(fund.ID).WithDate(time.Date(2025, 1, 12, 0, 0, 0, 0, time.UTC)).WithPrice(10.5).Build(t, db)
+
+		csv := []byte("date,price\n2025-01-10,11.00\n2025-01-11,11.50\n")
+		if _, err := devSvc.ImportFundPrices(context.Background(), fund.ID, csv); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		result, err := auditSvc.GetAuditEvents(&model.AuditFilters{EntityID: fund.ID, SortDir: "asc", PerPage: 50})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.Count != 1 || result.Events[0].Action != string(model.AuditActionImport) ||
+			result.Events[0].EntityType != string(model.AuditEntityFundPrice) {
+			t.Fatalf("expected one fund_price import event, got %+v", result.Events)
+		}
+		var before, after []model.FundPrice
+		if err := json.Unmarshal(result.Events[0].Before, &before); err != nil {
+			t.Fatalf("unmarshal before: %v", err)
+		}
+		if err := json.Unmarshal(result.Events[0].After, &after); err != nil {
+			t.Fatalf("unmarshal after: %v", err)
+		}
+		if len(before) != 1 || before[0].Price != 10 || len(after) != 2 {
+			t.Errorf("expected the Jan 10 price replaced and 2 imported, got %+v -> %+v", before, after)
+		}
+	})
+
+	t.Run("prices fetched from yahoo are audited", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		fundSvc := testutil.NewTestFundServiceWithMockYahoo(t, db, testutil.NewMockYahooClient())
+		auditSvc := testutil.NewTestAuditService(t, db)
+		portfolio := testutil.NewPortfolio().Build(t, db)
+		fund := testutil.NewFund().WithSymbol("TEST").Build(t, db)
+		pf := testutil.NewPortfolioFund(portfolio.ID, fund.ID).Build(t, db)
+		testutil.NewTransaction(pf.ID).WithDate(time.Now().UTC().AddDate(0, 0, -10)).Build(t, db)
+
+		if _, added, err := fundSvc.UpdateCurrentFundPrice(context.Background(), fund.ID); err != nil || !added {
+			t.Fatalf("expected the current price to be added, got %v (%v)", added, err)
+		}
+		count, err := fundSvc.UpdateHistoricalFundPrice(context.Background(), fund.ID)
+		if err != nil || count == 0 {
+			t.Fatalf("expected prices to be added, got %d (%v)", count, err)
+		}
+
+		result, err := auditSvc.GetAuditEvents(&model.AuditFilters{EntityID: fund.ID, SortDir: "asc", PerPage: 50})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.Count != 2 || result.Events[0].Action != string(model.AuditActionCreate) ||
+			result.Events[1].Action != string(model.AuditActionImport) || len(result.Events[1].Before) != 0 {
+			t.Fatalf("expected a create and an import event, got %+v", result.Events)
+		}
+		var after []model.FundPrice
+		if err := json.Unmarshal(result.Events[1].After, &after); err != nil {
+			t.Fatalf("unmarshal after: %v", err)
+		}
+		if len(after) != count {
+			t.Errorf("expected %d prices in the snapshot, got %d", count, len(after))
+		}
+	})
+
+	t.Run("password change is audited without the hash", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		authSvc := testutil.NewTestAuthService(t, db)
+		auditSvc := testutil.NewTestAuditService(t, db)
+		admin := setupAdmin(t, authSvc)
+
+		err := authSvc.ChangePassword(context.Background(), admin.User.ID, admin.Token,
+			request.ChangePasswordRequest{CurrentPassword: "correct horse", NewPassword: "battery staple"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		result, err := auditSvc.GetAuditEvents(&model.AuditFilters{
+			EntityID: admin.User.ID, Actions: []string{string(model.AuditActionChangePassword)}, SortDir: "asc", PerPage: 50,
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.Count != 1 || result.Events[0].EntityType != string(model.AuditEntityUser) {
+			t.Fatalf("expected one change_password event on the user, got %+v", result.Events)
+		}
+		if strings.Contains(string(result.Events[0].After), "correct horse") || strings.Contains(string(result.Events[0].After), "$") {
+			t.Errorf("expected no password material in the snapshot, got %s", result.Events[0].After)
+		}
+	})
+
+	t.Run("acknowledging an alert is audited", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		alertSvc := testutil.NewTestAlertService(t, db)
+		auditSvc := testutil.NewTestAuditService(t, db)
+		fund := testutil.NewFund().Build(t, db)
+		testutil.NewFundPrice(fund.ID).WithDate(time.Now().UTC().Truncate(24*time.Hour)).WithPrice(10).Build(t, db)
+		createAlertRule(t, alertSvc, request.CreateAlertRuleRequest{Name: "Below 20", Type: "fund_price", FundID: fund.ID, Direction: "below", Threshold: threshold(20)})
+		if _, err := alertSvc.EvaluateFundAlerts(context.Background()); err != nil {
+			t.Fatalf("EvaluateFundAlerts: %v", err)
+		}
+		active, err := alertSvc.GetAlerts([]model.AlertStatus{model.AlertStatusActive}, 1)
+		if err != nil || len(active) != 1 {
+			t.Fatalf("expected an active alert, got %+v (%v)", active, err)
+		}
+
+		if _, err := alertSvc.AcknowledgeAlert(context.Background(), active[0].ID); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		result, err := auditSvc.GetAuditEvents(&model.AuditFilters{EntityID: active[0].ID, SortDir: "asc", PerPage: 50})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.Count != 1 || result.Events[0].Action != string(model.AuditActionAcknowledge) ||
+			result.Events[0].EntityType != string(model.AuditEntityAlert) {
+			t.Fatalf("expected one acknowledge event on the alert, got %+v", result.Events)
+		}
+		var before, after model.Alert
+		if err := json.Unmarshal(result.Events[0].Before, &before); err != nil {
+			t.Fatalf("unmarshal before: %v", err)
+		}
+		if err := json.Unmarshal(result.Events[0].After, &after); err != nil {
+			t.Fatalf("unmarshal after: %v", err)
+		}
+		if before.Status != model.AlertStatusActive || after.Status != model.AlertStatusAcknowledged {
+			t.Errorf("expected active -> acknowledged, got %s -> %s", before.Status, after.Status)
+		}
+	})
+
+	t.Run("failed mutation leaves no audit event", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		pfSvc := testutil.NewTestPortfolioService(t, db)
+		auditSvc := testutil.NewTestAuditService(t, db)
+
+		newName := "Nope"
+		if _, err := pfSvc.UpdatePortfolio(context.Background(), testutil.MakeID(), request.UpdatePortfolioRequest{Name: &newName}); err == nil {
+			t.Fatal("expected error for unknown portfolio")
+		}
+
+		result, err := auditSvc.GetAuditEvents(&model.AuditFilters{SortDir: "desc", PerPage: 50})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.Count != 0 {
+			t.Errorf("expected no audit events, got %d", result.Count)
+		}
+	})
+}
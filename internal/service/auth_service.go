@@ -0,0 +1,631 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/api/request"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/apperrors"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/auth"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/logging"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/model"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/repository"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/tracing"
+)
+
+var authLog = logging.NewLogger("security")
+
+// DefaultSessionTTL is how long a login session stays valid when no TTL is configured.
+const DefaultSessionTTL = 7 * 24 * time.Hour
+
+// apiTokenTouchInterval limits how often an API token's last-used timestamp is written.
+const apiTokenTouchInterval = time.Minute
+
+// AuthService handles user accounts, login sessions, personal API tokens and portfolio sharing.
+//
+// Until the first account is created through Setup the API runs in the original
+// single-user mode without authentication. Setup creates an administrator who takes
+// ownership of every existing portfolio; from then on every request must carry a
+// session token.
+type AuthService struct {
+	db         *sql.DB
+	userRepo   *repository.UserRepository
+	tokenRepo  *repository.APITokenRepository
+	auditRepo  *repository.AuditRepository
+	sessionTTL time.Duration
+
+	// usersExist caches that at least one account exists. Once set it never
+	// flips back, as the last administrator cannot be deleted.
+	usersExist atomic.Bool
+	setupMu    sync.Mutex
+}
+
+// NewAuthService creates a new AuthService with the provided repository dependencies.
+// A non-positive sessionTTL falls back to DefaultSessionTTL.
+func NewAuthService(
+	db *sql.DB,
+	userRepo *repository.UserRepository,
+	tokenRepo *repository.APITokenRepository,
+	auditRepo *repository.AuditRepository,
+	sessionTTL time.Duration,
+) *AuthService {
+	if sessionTTL <= 0 {
+		sessionTTL = DefaultSessionTTL
+	}
+	return &AuthService{
+		db:         db,
+		userRepo:   userRepo,
+		tokenRepo:  tokenRepo,
+		auditRepo:  auditRepo,
+		sessionTTL: sessionTTL,
+	}
+}
+
+// AuthEnabled reports whether at least one account exists, i.e. whether requests must authenticate.
+func (s *AuthService) AuthEnabled() (bool, error) {
+	if s.usersExist.Load() {
+		return true, nil
+	}
+	count, err := s.userRepo.CountUsers()
+	if err != nil {
+		return false, fmt.Errorf("count users: %w", err)
+	}
+	if count > 0 {
+		s.usersExist.Store(true)
+	}
+	return count > 0, nil
+}
+
+// Setup creates the first account as an administrator, assigns it every existing
+// portfolio and logs it in. Returns ErrSetupAlreadyCompleted once any account exists.
+func (s *AuthService) Setup(ctx context.Context, req request.SetupRequest) (*model.LoginResponse, error) {
+	ctx, span := tracing.Start(ctx, "AuthService.Setup")
+	defer span.End()
+	s.setupMu.Lock()
+	defer s.setupMu.Unlock()
+
+	enabled, err := s.AuthEnabled()
+	if err != nil {
+		return nil, err
+	}
+	if enabled {
+		return nil, apperrors.ErrSetupAlreadyCompleted
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }() //nolint:errcheck // Rollback is a no-op after Commit; error is intentionally ignored.
+
+	user, err := s.insertUser(ctx, tx, req.Username, req.Password, true)
+	if err != nil {
+		return nil, err
+	}
+
+	claimed, err := s.userRepo.WithTx(tx).AssignUnownedPortfolios(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit transaction: %w", err)
+	}
+	s.usersExist.Store(true)
+
+	authLog.InfoContext(ctx, "initial administrator created", "user_id", user.ID, "username", user.Username, "claimed_portfolios", claimed)
+	return s.startSession(ctx, user)
+}
+
+// Login verifies a username and password and starts a new session.
+// Returns ErrInvalidCredentials for an unknown user or a wrong password alike.
+func (s *AuthService) Login(ctx context.Context, req request.LoginRequest) (*model.LoginResponse, error) {
+	ctx, span := tracing.Start(ctx, "AuthService.Login")
+	defer span.End()
+	username := normalizeUsername(req.Username)
+
+	user, err := s.userRepo.GetUserByUsername(username)
+	if errors.Is(err, apperrors.ErrUserNotFound) {
+		authLog.WarnContext(ctx, "login failed", "username", username, "reason", "unknown user")
+		return nil, apperrors.ErrInvalidCredentials
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get user: %w", err)
+	}
+
+	if !auth.CheckPassword(user.PasswordHash, req.Password) {
+		authLog.WarnContext(ctx, "login failed", "username", username, "reason", "wrong password")
+		return nil, apperrors.ErrInvalidCredentials
+	}
+
+	now := time.Now().UTC()
+	if err := s.userRepo.UpdateLastLogin(ctx, user.ID, now); err != nil {
+		return nil, err
+	}
+	user.LastLoginAt = &now
+
+	authLog.InfoContext(ctx, "user logged in", "user_id", user.ID, "username", user.Username)
+	return s.startSession(ctx, user)
+}
+
+// startSession issues a new bearer token for user and stores its hash.
+func (s *AuthService) startSession(ctx context.Context, user model.User) (*model.LoginResponse, error) {
+	token, err := auth.NewToken()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	session := &model.Session{
+		ID:        uuid.New().String(),
+		UserID:    user.ID,
+		TokenHash: auth.HashToken(token),
+		CreatedAt: now,
+		ExpiresAt: now.Add(s.sessionTTL),
+	}
+	if err := s.userRepo.InsertSession(ctx, session); err != nil {
+		return nil, err
+	}
+
+	return &model.LoginResponse{
+		Token:     token,
+		ExpiresAt: session.ExpiresAt,
+		User:      user,
+	}, nil
+}
+
+// Logout ends the session identified by token.
+func (s *AuthService) Logout(ctx context.Context, token string) error {
+	ctx, span := tracing.Start(ctx, "AuthService.Logout")
+	defer span.End()
+	if err := s.userRepo.DeleteSession(ctx, auth.HashToken(token)); err != nil {
+		return err
+	}
+	authLog.InfoContext(ctx, "user logged out")
+	return nil
+}
+
+// Authenticate resolves a session or API token to the Principal it belongs to, loading the
+// portfolios the user may access. Principals of API tokens are limited to the token's scopes.
+// Returns ErrSessionInvalid for unknown, expired or revoked tokens.
+func (s *AuthService) Authenticate(ctx context.Context, token string) (*auth.Principal, error) {
+	ctx, span := tracing.Start(ctx, "AuthService.Authenticate")
+	defer span.End()
+	if auth.IsAPIToken(token) {
+		return s.authenticateAPIToken(ctx, token)
+	}
+
+	session, err := s.userRepo.GetSessionByTokenHash(auth.HashToken(token), time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return s.loadPrincipal(session.UserID)
+}
+
+// authenticateAPIToken resolves a personal API token and records its use.
+func (s *AuthService) authenticateAPIToken(ctx context.Context, token string) (*auth.Principal, error) {
+	now := time.Now()
+	apiToken, err := s.tokenRepo.GetActiveAPITokenByHash(auth.HashToken(token), now)
+	if err != nil {
+		return nil, err
+	}
+
+	principal, err := s.loadPrincipal(apiToken.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.tokenRepo.TouchAPIToken(ctx, apiToken.ID, now, apiTokenTouchInterval); err != nil {
+		// Failing to record the last use must not fail the request.
+		authLog.WarnContext(ctx, "failed to record API token use", "error", err, "token_id", apiToken.ID)
+	}
+
+	return principal.WithAPIToken(apiToken.ID, apiToken.Scopes), nil
+}
+
+// loadPrincipal builds the Principal for userID. A user deleted since the token was
+// issued yields ErrSessionInvalid.
+func (s *AuthService) loadPrincipal(userID string) (*auth.Principal, error) {
+	user, err := s.userRepo.GetUserByID(userID)
+	if errors.Is(err, apperrors.ErrUserNotFound) {
+		return nil, apperrors.ErrSessionInvalid
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get user: %w", err)
+	}
+
+	if user.IsAdmin {
+		return auth.NewPrincipal(user, nil, nil), nil
+	}
+
+	access, err := s.userRepo.GetPortfolioAccess(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	portfolioIDs := make([]string, 0, len(access))
+	for id := range access {
+		portfolioIDs = append(portfolioIDs, id)
+	}
+	portfolioFunds, err := s.userRepo.GetPortfolioFundOwners(portfolioIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	return auth.NewPrincipal(user, access, portfolioFunds), nil
+}
+
+// ChangePassword changes the password of userID after verifying the current one.
+// Every other session of the user is ended; the session identified by keepToken stays valid.
+// The change is audited as a "change_password" event on the user.
+func (s *AuthService) ChangePassword(ctx context.Context, userID, keepToken string, req request.ChangePasswordRequest) error {
+	ctx, span := tracing.Start(ctx, "AuthService.ChangePassword")
+	defer span.End()
+	user, err := s.userRepo.GetUserByID(userID)
+	if err != nil {
+		return fmt.Errorf("get user: %w", err)
+	}
+	if !auth.CheckPassword(user.PasswordHash, req.CurrentPassword) {
+		return apperrors.ErrInvalidCredentials
+	}
+
+	hash, err := auth.HashPassword(req.NewPassword)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }() //nolint:errcheck // Rollback is a no-op after Commit; error is intentionally ignored.
+
+	if err := s.userRepo.WithTx(tx).UpdatePasswordHash(ctx, userID, hash); err != nil {
+		return err
+	}
+	if err := s.userRepo.WithTx(tx).DeleteUserSessions(ctx, userID, auth.HashToken(keepToken)); err != nil {
+		return err
+	}
+
+	// The snapshots never include the password hash; the action records that it changed.
+	if err := recordAudit(ctx, s.auditRepo.WithTx(tx), model.AuditEntityUser, userID, model.AuditActionChangePassword, user, user); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+
+	authLog.InfoContext(ctx, "password changed", "user_id", userID)
+	return nil
+}
+
+// GetUsers retrieves all user accounts.
+func (s *AuthService) GetUsers() ([]model.User, error) {
+	return s.userRepo.GetUsers()
+}
+
+// CreateUser creates a new account. Returns ErrUsernameTaken if the username is in use.
+func (s *AuthService) CreateUser(ctx context.Context, req request.CreateUserRequest) (*model.User, error) {
+	ctx, span := tracing.Start(ctx, "AuthService.CreateUser")
+	defer span.End()
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }() //nolint:errcheck // Rollback is a no-op after Commit; error is intentionally ignored.
+
+	user, err := s.insertUser(ctx, tx, req.Username, req.Password, req.IsAdmin)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit transaction: %w", err)
+	}
+	s.usersExist.Store(true)
+
+	authLog.InfoContext(ctx, "user created", "user_id", user.ID, "username", user.Username, "is_admin", user.IsAdmin)
+	return &user, nil
+}
+
+// insertUser hashes the password and inserts a new account inside tx, recording an audit event.
+func (s *AuthService) insertUser(ctx context.Context, tx *sql.Tx, username, password string, isAdmin bool) (model.User, error) {
+	hash, err := auth.HashPassword(password)
+	if err != nil {
+		return model.User{}, err
+	}
+
+	user := model.User{
+		ID:           uuid.New().String(),
+		Username:     normalizeUsername(username),
+		IsAdmin:      isAdmin,
+		CreatedAt:    time.Now().UTC(),
+		PasswordHash: hash,
+	}
+	if err := s.userRepo.WithTx(tx).InsertUser(ctx, &user); err != nil {
+		return model.User{}, err
+	}
+
+	if err := recordAudit(ctx, s.auditRepo.WithTx(tx), model.AuditEntityUser, user.ID, model.AuditActionCreate, nil, user); err != nil {
+		return model.User{}, err
+	}
+	return user, nil
+}
+
+// DeleteUser deletes an account on behalf of the administrator actingUserID.
+// Portfolios owned by the deleted user are transferred to the acting administrator;
+// the user's sessions and shares are removed. Administrators cannot delete themselves.
+func (s *AuthService) DeleteUser(ctx context.Context, actingUserID, userID string) error {
+	ctx, span := tracing.Start(ctx, "AuthService.DeleteUser")
+	defer span.End()
+	if actingUserID == userID {
+		return apperrors.ErrCannotDeleteSelf
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }() //nolint:errcheck // Rollback is a no-op after Commit; error is intentionally ignored.
+
+	user, err := s.userRepo.WithTx(tx).GetUserByID(userID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.userRepo.WithTx(tx).TransferPortfolios(ctx, userID, actingUserID); err != nil {
+		return err
+	}
+	if err := s.userRepo.WithTx(tx).DeleteUser(ctx, userID); err != nil {
+		return err
+	}
+
+	if err := recordAudit(ctx, s.auditRepo.WithTx(tx), model.AuditEntityUser, userID, model.AuditActionDelete, user, nil); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+
+	authLog.InfoContext(ctx, "user deleted", "user_id", userID, "username", user.Username, "portfolios_transferred_to", actingUserID)
+	return nil
+}
+
+// GetPortfolioShares retrieves the users a portfolio is shared with.
+func (s *AuthService) GetPortfolioShares(portfolioID string) ([]model.PortfolioShare, error) {
+	return s.userRepo.GetPortfolioShares(portfolioID)
+}
+
+// SharePortfolio grants a user read or write access to a portfolio, replacing any earlier grant.
+// The portfolio's owner cannot be given a share. Returns ErrUserNotFound for an unknown user.
+func (s *AuthService) SharePortfolio(
+	ctx context.Context,
+	portfolio model.Portfolio,
+	req request.SharePortfolioRequest,
+) (*model.PortfolioShare, error) {
+	ctx, span := tracing.Start(ctx, "AuthService.SharePortfolio")
+	defer span.End()
+	if portfolio.OwnerID == req.UserID {
+		return nil, apperrors.ErrInvalidShareTarget
+	}
+
+	user, err := s.userRepo.GetUserByID(req.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	share := &model.PortfolioShare{
+		PortfolioID: portfolio.ID,
+		UserID:      user.ID,
+		Username:    user.Username,
+		Access:      model.PortfolioAccess(req.Access),
+		CreatedAt:   time.Now().UTC(),
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }() //nolint:errcheck // Rollback is a no-op after Commit; error is intentionally ignored.
+
+	if err := s.userRepo.WithTx(tx).UpsertPortfolioShare(ctx, share); err != nil {
+		return nil, err
+	}
+
+	if err := recordAudit(ctx, s.auditRepo.WithTx(tx), model.AuditEntityPortfolioShare, portfolio.ID, model.AuditActionUpdate, nil, share); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit transaction: %w", err)
+	}
+
+	authLog.InfoContext(ctx, "portfolio shared", "portfolio_id", portfolio.ID, "shared_with", user.ID, "access", share.Access)
+	return share, nil
+}
+
+// UnsharePortfolio revokes a user's access to a portfolio.
+// Returns ErrPortfolioShareNotFound if the portfolio was not shared with the user.
+func (s *AuthService) UnsharePortfolio(ctx context.Context, portfolioID, userID string) error {
+	ctx, span := tracing.Start(ctx, "AuthService.UnsharePortfolio")
+	defer span.End()
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }() //nolint:errcheck // Rollback is a no-op after Commit; error is intentionally ignored.
+
+	if err := s.userRepo.WithTx(tx).DeletePortfolioShare(ctx, portfolioID, userID); err != nil {
+		return err
+	}
+
+	before := map[string]string{"userId": userID}
+	if err := recordAudit(ctx, s.auditRepo.WithTx(tx), model.AuditEntityPortfolioShare, portfolioID, model.AuditActionDelete, before, nil); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+
+	authLog.InfoContext(ctx, "portfolio unshared", "portfolio_id", portfolioID, "user_id", userID)
+	return nil
+}
+
+// PortfolioIDForTransaction returns the portfolio a transaction belongs to.
+func (s *AuthService) PortfolioIDForTransaction(transactionID string) (string, error) {
+	return s.userRepo.GetPortfolioIDForTransaction(transactionID)
+}
+
+// PortfolioIDForInvestmentPlan returns the portfolio an investment plan belongs to.
+func (s *AuthService) PortfolioIDForInvestmentPlan(planID string) (string, error) {
+	return s.userRepo.GetPortfolioIDForInvestmentPlan(planID)
+}
+
+// PortfolioIDForDividend returns the portfolio a dividend belongs to.
+func (s *AuthService) PortfolioIDForDividend(dividendID string) (string, error) {
+	return s.userRepo.GetPortfolioIDForDividend(dividendID)
+}
+
+// PortfolioIDForPortfolioFund returns the portfolio a portfolio_fund row belongs to.
+func (s *AuthService) PortfolioIDForPortfolioFund(portfolioFundID string) (string, error) {
+	return s.userRepo.GetPortfolioIDForPortfolioFund(portfolioFundID)
+}
+
+// PurgeExpiredSessions removes all expired sessions. It is run daily by the scheduler.
+func (s *AuthService) PurgeExpiredSessions(ctx context.Context) (int64, error) {
+	ctx, span := tracing.Start(ctx, "AuthService.PurgeExpiredSessions")
+	defer span.End()
+	purged, err := s.userRepo.DeleteExpiredSessions(ctx, time.Now())
+	if err != nil {
+		return 0, err
+	}
+	if purged > 0 {
+		authLog.InfoContext(ctx, "expired sessions purged", "count", purged)
+	}
+	return purged, nil
+}
+
+// normalizeUsername returns the canonical (trimmed, lower-case) form of a username.
+func normalizeUsername(username string) string {
+	return strings.ToLower(strings.TrimSpace(username))
+}
+
+// GetAPITokens retrieves the personal API tokens of a user, including revoked and expired ones.
+func (s *AuthService) GetAPITokens(userID string) ([]model.APIToken, error) {
+	return s.tokenRepo.GetAPITokens(userID)
+}
+
+// CreateAPIToken creates a personal API token for user with the requested scopes.
+// Administrator-only scopes return ErrScopeNotAllowed for other users.
+// The plaintext token is only part of the response and is never stored.
+func (s *AuthService) CreateAPIToken(
+	ctx context.Context,
+	user model.User,
+	req request.CreateAPITokenRequest,
+) (*model.CreateAPITokenResponse, error) {
+	ctx, span := tracing.Start(ctx, "AuthService.CreateAPIToken")
+	defer span.End()
+	scopes := make([]model.APIScope, 0, len(req.Scopes))
+	seen := make(map[model.APIScope]bool, len(req.Scopes))
+	for _, raw := range req.Scopes {
+		scope := model.APIScope(raw)
+		if model.ValidAPIScopes[scope] && !user.IsAdmin {
+			return nil, fmt.Errorf("%w: %s", apperrors.ErrScopeNotAllowed, scope)
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			scopes = append(scopes, scope)
+		}
+	}
+
+	token, err := auth.NewAPIToken()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	apiToken := model.APIToken{
+		ID:        uuid.New().String(),
+		UserID:    user.ID,
+		Name:      strings.TrimSpace(req.Name),
+		Scopes:    scopes,
+		CreatedAt: now,
+		TokenHash: auth.HashToken(token),
+	}
+	if req.ExpiresInDays != nil {
+		expiresAt := now.AddDate(0, 0, *req.ExpiresInDays)
+		apiToken.ExpiresAt = &expiresAt
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }() //nolint:errcheck // Rollback is a no-op after Commit; error is intentionally ignored.
+
+	if err := s.tokenRepo.WithTx(tx).InsertAPIToken(ctx, &apiToken); err != nil {
+		return nil, err
+	}
+
+	if err := recordAudit(ctx, s.auditRepo.WithTx(tx), model.AuditEntityAPIToken, apiToken.ID, model.AuditActionCreate, nil, apiToken); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit transaction: %w", err)
+	}
+
+	authLog.InfoContext(ctx, "API token created", "token_id", apiToken.ID, "user_id", user.ID, "scopes", scopes)
+	return &model.CreateAPITokenResponse{Token: token, APIToken: apiToken}, nil
+}
+
+// RevokeAPIToken revokes a personal API token on behalf of user. Users can only revoke
+// their own tokens; administrators can revoke any token. Returns ErrAPITokenNotFound for
+// tokens the user cannot see and ErrAPITokenRevoked if the token was already revoked.
+func (s *AuthService) RevokeAPIToken(ctx context.Context, user model.User, tokenID string) error {
+	ctx, span := tracing.Start(ctx, "AuthService.RevokeAPIToken")
+	defer span.End()
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }() //nolint:errcheck // Rollback is a no-op after Commit; error is intentionally ignored.
+
+	before, err := s.tokenRepo.WithTx(tx).GetAPIToken(tokenID)
+	if err != nil {
+		return err
+	}
+	if before.UserID != user.ID && !user.IsAdmin {
+		return apperrors.ErrAPITokenNotFound
+	}
+	if before.RevokedAt != nil {
+		return apperrors.ErrAPITokenRevoked
+	}
+
+	now := time.Now().UTC()
+	if err := s.tokenRepo.WithTx(tx).RevokeAPIToken(ctx, tokenID, now); err != nil {
+		return err
+	}
+	after := before
+	after.RevokedAt = &now
+
+	if err := recordAudit(ctx, s.auditRepo.WithTx(tx), model.AuditEntityAPIToken, tokenID, model.AuditActionUpdate, before, after); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+
+	authLog.InfoContext(ctx, "API token revoked", "token_id", tokenID, "owner_id", before.UserID)
+	return nil
+}
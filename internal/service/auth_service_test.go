@@ -0,0 +1,399 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/api/request"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/apperrors"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/auth"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/model"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/service"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/testutil"
+)
+
+// setupAdmin completes initial setup and returns the administrator's login response.
+func setupAdmin(t *testing.T, svc *service.AuthService) *model.LoginResponse {
+	t.Helper()
+	resp, err := svc.Setup(context.Background(), request.SetupRequest{Username: "Admin", Password: "correct horse"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return resp
+}
+
+func TestAuthService_Setup(t *testing.T) {
+	t.Run("creates admin and claims existing portfolios", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		svc := testutil.NewTestAuthService(t, db)
+		portfolio := testutil.NewPortfolio().Build(t, db)
+
+		enabled, err := svc.AuthEnabled()
+		if err != nil || enabled {
+			t.Fatalf("expected auth disabled before setup, got %v, %v", enabled, err)
+		}
+
+		resp := setupAdmin(t, svc)
+		if resp.Token == "" || !resp.User.IsAdmin || resp.User.Username != "admin" {
+			t.Errorf("unexpected login response: %+v", resp)
+		}
+
+		if n := countRows(t, db, "portfolio", "id = ? AND owner_id = ?", portfolio.ID, resp.User.ID); n != 1 {
+			t.Errorf("expected portfolio to be owned by the administrator")
+		}
+
+		enabled, err = svc.AuthEnabled()
+		if err != nil || !enabled {
+			t.Errorf("expected auth enabled after setup, got %v, %v", enabled, err)
+		}
+	})
+
+	t.Run("second setup is rejected", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		svc := testutil.NewTestAuthService(t, db)
+		setupAdmin(t, svc)
+
+		_, err := svc.Setup(context.Background(), request.SetupRequest{Username: "other", Password: "correct horse"})
+		if !errors.Is(err, apperrors.ErrSetupAlreadyCompleted) {
+			t.Errorf("expected ErrSetupAlreadyCompleted, got %v", err)
+		}
+	})
+}
+
+func TestAuthService_LoginAndAuthenticate(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	ctx := context.Background()
+	svc := testutil.NewTestAuthService(t, db)
+	setupAdmin(t, svc)
+
+	t.Run("wrong password is rejected", func(t *testing.T) {
+		_, err := svc.Login(ctx, request.LoginRequest{Username: "admin", Password: "wrong password"})
+		if !errors.Is(err, apperrors.ErrInvalidCredentials) {
+			t.Errorf("expected ErrInvalidCredentials, got %v", err)
+		}
+	})
+
+	t.Run("unknown user is rejected", func(t *testing.T) {
+		_, err := svc.Login(ctx, request.LoginRequest{Username: "nobody", Password: "correct horse"})
+		if !errors.Is(err, apperrors.ErrInvalidCredentials) {
+			t.Errorf("expected ErrInvalidCredentials, got %v", err)
+		}
+	})
+
+	t.Run("token authenticates until logout", func(t *testing.T) {
+		resp, err := svc.Login(ctx, request.LoginRequest{Username: " ADMIN ", Password: "correct horse"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if resp.User.LastLoginAt == nil {
+			t.Error("expected last login to be set")
+		}
+
+		principal, err := svc.Authenticate(ctx, resp.Token)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if principal.User.ID != resp.User.ID {
+			t.Errorf("expected principal for %s, got %s", resp.User.ID, principal.User.ID)
+		}
+
+		if err := svc.Logout(ctx, resp.Token); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if _, err := svc.Authenticate(ctx, resp.Token); !errors.Is(err, apperrors.ErrSessionInvalid) {
+			t.Errorf("expected ErrSessionInvalid after logout, got %v", err)
+		}
+	})
+
+	t.Run("unknown token is rejected", func(t *testing.T) {
+		if _, err := svc.Authenticate(ctx, "not-a-token"); !errors.Is(err, apperrors.ErrSessionInvalid) {
+			t.Errorf("expected ErrSessionInvalid, got %v", err)
+		}
+	})
+}
+
+func TestAuthService_PortfolioAccess(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	svc := testutil.NewTestAuthService(t, db)
+	ctx := context.Background()
+
+	admin := setupAdmin(t, svc)
+	owned := testutil.NewPortfolio().Build(t, db) // created after setup, so unowned
+	other := testutil.NewPortfolio().Build(t, db)
+	fund := testutil.NewFund().Build(t, db)
+	pf := testutil.NewPortfolioFund(owned.ID, fund.ID).Build(t, db)
+
+	user, err := svc.CreateUser(ctx, request.CreateUserRequest{Username: "alice", Password: "alice-password"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := db.Exec(`UPDATE portfolio SET owner_id = ? WHERE id = ?`, user.ID, owned.ID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := db.Exec(`UPDATE portfolio SET owner_id = ? WHERE id = ?`, admin.User.ID, other.ID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	login := func() *auth.Principal {
+		t.Helper()
+		resp, err := svc.Login(ctx, request.LoginRequest{Username: "alice", Password: "alice-password"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		p, err := svc.Authenticate(ctx, resp.Token)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return p
+	}
+
+	t.Run("owner sees only own portfolios", func(t *testing.T) {
+		p := login()
+		if !p.CanAccessPortfolio(owned.ID, model.PortfolioAccessOwner) {
+			t.Error("expected owner access to own portfolio")
+		}
+		if !p.CanAccessPortfolioFund(pf.ID, model.PortfolioAccessWrite) {
+			t.Error("expected write access to own portfolio fund")
+		}
+		if p.CanAccessPortfolio(other.ID, model.PortfolioAccessRead) {
+			t.Error("expected no access to another user's portfolio")
+		}
+	})
+
+	t.Run("sharing grants and revokes access", func(t *testing.T) {
+		portfolio := model.Portfolio{ID: other.ID, OwnerID: admin.User.ID}
+		share, err := svc.SharePortfolio(ctx, portfolio, request.SharePortfolioRequest{UserID: user.ID, Access: "read"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if share.Username != "alice" || share.Access != model.PortfolioAccessRead {
+			t.Errorf("unexpected share: %+v", share)
+		}
+
+		p := login()
+		if !p.CanAccessPortfolio(other.ID, model.PortfolioAccessRead) {
+			t.Error("expected read access to shared portfolio")
+		}
+		if p.CanAccessPortfolio(other.ID, model.PortfolioAccessWrite) {
+			t.Error("expected no write access to read-only share")
+		}
+
+		if err := svc.UnsharePortfolio(ctx, other.ID, user.ID); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if p := login(); p.CanAccessPortfolio(other.ID, model.PortfolioAccessRead) {
+			t.Error("expected no access after unsharing")
+		}
+
+		err = svc.UnsharePortfolio(ctx, other.ID, user.ID)
+		if !errors.Is(err, apperrors.ErrPortfolioShareNotFound) {
+			t.Errorf("expected ErrPortfolioShareNotFound, got %v", err)
+		}
+	})
+
+	t.Run("owner cannot be given a share", func(t *testing.T) {
+		portfolio := model.Portfolio{ID: owned.ID, OwnerID: user.ID}
+		_, err := svc.SharePortfolio(ctx, portfolio, request.SharePortfolioRequest{UserID: user.ID, Access: "write"})
+		if !errors.Is(err, apperrors.ErrInvalidShareTarget) {
+			t.Errorf("expected ErrInvalidShareTarget, got %v", err)
+		}
+	})
+
+	t.Run("resolvers map entities to portfolios", func(t *testing.T) {
+		tx := testutil.NewTransaction(pf.ID).Build(t, db)
+		got, err := svc.PortfolioIDForTransaction(tx.ID)
+		if err != nil || got != owned.ID {
+			t.Errorf("expected %s, got %s (%v)", owned.ID, got, err)
+		}
+		if _, err := svc.PortfolioIDForTransaction(testutil.MakeID()); !errors.Is(err, apperrors.ErrTransactionNotFound) {
+			t.Errorf("expected ErrTransactionNotFound, got %v", err)
+		}
+		got, err = svc.PortfolioIDForPortfolioFund(pf.ID)
+		if err != nil || got != owned.ID {
+			t.Errorf("expected %s, got %s (%v)", owned.ID, got, err)
+		}
+	})
+}
+
+func TestAuthService_ChangePassword(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	svc := testutil.NewTestAuthService(t, db)
+	ctx := context.Background()
+
+	first := setupAdmin(t, svc)
+	second, err := svc.Login(ctx, request.LoginRequest{Username: "admin", Password: "correct horse"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	err = svc.ChangePassword(ctx, first.User.ID, first.Token, request.ChangePasswordRequest{CurrentPassword: "wrong", NewPassword: "battery staple"})
+	if !errors.Is(err, apperrors.ErrInvalidCredentials) {
+		t.Fatalf("expected ErrInvalidCredentials, got %v", err)
+	}
+
+	err = svc.ChangePassword(ctx, first.User.ID, first.Token, request.ChangePasswordRequest{CurrentPassword: "correct horse", NewPassword: "battery staple"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := svc.Authenticate(ctx, first.Token); err != nil {
+		t.Errorf("expected current session to survive, got %v", err)
+	}
+	if _, err := svc.Authenticate(ctx, second.Token); !errors.Is(err, apperrors.ErrSessionInvalid) {
+		t.Errorf("expected other session to be ended, got %v", err)
+	}
+	if _, err := svc.Login(ctx, request.LoginRequest{Username: "admin", Password: "battery staple"}); err != nil {
+		t.Errorf("expected login with new password, got %v", err)
+	}
+}
+
+func TestAuthService_DeleteUser(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	svc := testutil.NewTestAuthService(t, db)
+	ctx := context.Background()
+
+	admin := setupAdmin(t, svc)
+	user, err := svc.CreateUser(ctx, request.CreateUserRequest{Username: "bob", Password: "bob-password"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	portfolio := testutil.NewPortfolio().Build(t, db)
+	if _, err := db.Exec(`UPDATE portfolio SET owner_id = ? WHERE id = ?`, user.ID, portfolio.ID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	t.Run("duplicate username is rejected", func(t *testing.T) {
+		_, err := svc.CreateUser(ctx, request.CreateUserRequest{Username: "BOB", Password: "bob-password"})
+		if !errors.Is(err, apperrors.ErrUsernameTaken) {
+			t.Errorf("expected ErrUsernameTaken, got %v", err)
+		}
+	})
+
+	t.Run("cannot delete self", func(t *testing.T) {
+		if err := svc.DeleteUser(ctx, admin.User.ID, admin.User.ID); !errors.Is(err, apperrors.ErrCannotDeleteSelf) {
+			t.Errorf("expected ErrCannotDeleteSelf, got %v", err)
+		}
+	})
+
+	t.Run("transfers portfolios to acting admin", func(t *testing.T) {
+		if err := svc.DeleteUser(ctx, admin.User.ID, user.ID); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if n := countRows(t, db, "portfolio", "id = ? AND owner_id = ?", portfolio.ID, admin.User.ID); n != 1 {
+			t.Error("expected portfolio to be transferred to the administrator")
+		}
+		if err := svc.DeleteUser(ctx, admin.User.ID, user.ID); !errors.Is(err, apperrors.ErrUserNotFound) {
+			t.Errorf("expected ErrUserNotFound, got %v", err)
+		}
+	})
+
+	t.Run("audit events record the acting user", func(t *testing.T) {
+		principal := auth.NewPrincipal(admin.User, nil, nil)
+		created, err := svc.CreateUser(auth.WithPrincipal(ctx, principal), request.CreateUserRequest{Username: "carol", Password: "carol-password"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if n := countRows(t, db, "audit_event", "entity_id = ? AND user_id = ?", created.ID, admin.User.ID); n != 1 {
+			t.Errorf("expected audit event attributed to the administrator, got %d", n)
+		}
+	})
+}
+
+func TestAuthService_PurgeExpiredSessions(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	svc := testutil.NewTestAuthService(t, db)
+	ctx := context.Background()
+	resp := setupAdmin(t, svc)
+
+	if _, err := db.Exec(`UPDATE user_session SET expires_at = '2000-01-01 00:00:00'`); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	purged, err := svc.PurgeExpiredSessions(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if purged != 1 {
+		t.Errorf("expected 1 purged session, got %d", purged)
+	}
+	if _, err := svc.Authenticate(ctx, resp.Token); !errors.Is(err, apperrors.ErrSessionInvalid) {
+		t.Errorf("expected ErrSessionInvalid, got %v", err)
+	}
+}
+
+func TestAuthService_APITokens(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	svc := testutil.NewTestAuthService(t, db)
+	ctx := context.Background()
+
+	admin := setupAdmin(t, svc)
+	user, err := svc.CreateUser(ctx, request.CreateUserRequest{Username: "alice", Password: "alice-password"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	t.Run("token authenticates with its scopes", func(t *testing.T) {
+		resp, err := svc.CreateAPIToken(ctx, *user, request.CreateAPITokenRequest{
+			Name:   "dashboard",
+			Scopes: []string{"write:transaction", "read:portfolio", "read:portfolio"},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(resp.APIToken.Scopes) != 2 {
+			t.Errorf("expected duplicate scopes to be removed, got %v", resp.APIToken.Scopes)
+		}
+
+		p, err := svc.Authenticate(ctx, resp.Token)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if p.User.ID != user.ID || p.APITokenID != resp.APIToken.ID {
+			t.Errorf("unexpected principal: %+v", p)
+		}
+		if !p.HasScope(model.ScopeReadTransaction) || p.HasScope(model.ScopeWritePortfolio) {
+			t.Error("expected principal limited to the token's scopes")
+		}
+
+		tokens, err := svc.GetAPITokens(user.ID)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(tokens) != 1 || tokens[0].LastUsedAt == nil {
+			t.Errorf("expected one token with a last use, got %+v", tokens)
+		}
+	})
+
+	t.Run("non-admins cannot request admin scopes", func(t *testing.T) {
+		_, err := svc.CreateAPIToken(ctx, *user, request.CreateAPITokenRequest{Name: "x", Scopes: []string{"admin:ibkr"}})
+		if !errors.Is(err, apperrors.ErrScopeNotAllowed) {
+			t.Errorf("expected ErrScopeNotAllowed, got %v", err)
+		}
+		if _, err := svc.CreateAPIToken(ctx, admin.User, request.CreateAPITokenRequest{Name: "x", Scopes: []string{"admin:ibkr"}}); err != nil {
+			t.Errorf("expected admin to get admin scope, got %v", err)
+		}
+	})
+
+	t.Run("revoked token no longer authenticates", func(t *testing.T) {
+		resp, err := svc.CreateAPIToken(ctx, *user, request.CreateAPITokenRequest{Name: "script", Scopes: []string{"read:fund"}})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		other := model.User{ID: testutil.MakeID()}
+		if err := svc.RevokeAPIToken(ctx, other, resp.APIToken.ID); !errors.Is(err, apperrors.ErrAPITokenNotFound) {
+			t.Errorf("expected other users to get ErrAPITokenNotFound, got %v", err)
+		}
+
+		if err := svc.RevokeAPIToken(ctx, *user, resp.APIToken.ID); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if _, err := svc.Authenticate(ctx, resp.Token); !errors.Is(err, apperrors.ErrSessionInvalid) {
+			t.Errorf("expected ErrSessionInvalid, got %v", err)
+		}
+		if err := svc.RevokeAPIToken(ctx, admin.User, resp.APIToken.ID); !errors.Is(err, apperrors.ErrAPITokenRevoked) {
+			t.Errorf("expected ErrAPITokenRevoked, got %v", err)
+		}
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"database/sql"
 	"encoding/csv"
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
@@ -16,6 +17,7 @@ import (
 	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/logging"
 	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/model"
 	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/repository"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/tracing"
 )
 
 var devLog = logging.NewLogger("developer")
@@ -27,6 +29,7 @@ type DeveloperService struct {
 	fundRepo                *repository.FundRepository
 	transactionRepo         *repository.TransactionRepository
 	pfRepo                  *repository.PortfolioFundRepository
+	auditRepo               *repository.AuditRepository
 	materializedInvalidator MaterializedInvalidator
 	logHandler              *logging.LogHandler
 }
@@ -45,6 +48,7 @@ func NewDeveloperService(
 		fundRepo:        fundRepo,
 		transactionRepo: transactionRepo,
 		pfRepo:          pfRepo,
+		auditRepo:       repository.NewAuditRepository(db),
 	}
 }
 
@@ -94,6 +98,8 @@ func (s *DeveloperService) GetLoggingConfig() (model.LoggingSetting, error) {
 // Upserts LOGGING_ENABLED and LOGGING_LEVEL settings within a single transaction.
 // Only updates fields that are present in the request.
 func (s *DeveloperService) SetLoggingConfig(ctx context.Context, req request.SetLoggingConfig) (model.LoggingSetting, error) {
+	ctx, span := tracing.Start(ctx, "DeveloperService.SetLoggingConfig")
+	defer span.End()
 	devLog.DebugContext(ctx, "setting logging config", "level", req.Level)
 
 	tx, err := s.db.BeginTx(ctx, nil)
@@ -101,6 +107,12 @@ func (s *DeveloperService) SetLoggingConfig(ctx context.Context, req request.Set
 		return model.LoggingSetting{}, fmt.Errorf("begin transaction: %w", err)
 	}
 	defer func() { _ = tx.Rollback() }() //nolint:errcheck // Rollback is a no-op after Commit; error is intentionally ignored.
+
+	before, err := s.developerRepo.WithTx(tx).GetLoggingConfig()
+	if err != nil {
+		return model.LoggingSetting{}, fmt.Errorf("get logging config: %w", err)
+	}
+
 	updateTime := time.Now().UTC()
 	if req.Enabled != nil {
 		settingEnabled := model.SystemSetting{
@@ -128,6 +140,15 @@ func (s *DeveloperService) SetLoggingConfig(ctx context.Context, req request.Set
 
 	}
 
+	after, err := s.developerRepo.WithTx(tx).GetLoggingConfig()
+	if err != nil {
+		return model.LoggingSetting{}, fmt.Errorf("get logging config: %w", err)
+	}
+
+	if err := recordAudit(ctx, s.auditRepo.WithTx(tx), model.AuditEntitySystemSetting, "logging", model.AuditActionUpdate, before, after); err != nil {
+		return model.LoggingSetting{}, err
+	}
+
 	if err = tx.Commit(); err != nil {
 		return model.LoggingSetting{}, fmt.Errorf("commit transaction: %w", err)
 	}
@@ -164,6 +185,8 @@ func (s *DeveloperService) UpdateExchangeRate(
 	ctx context.Context,
 	req request.SetExchangeRateRequest,
 ) (model.ExchangeRate, error) {
+	ctx, span := tracing.Start(ctx, "DeveloperService.UpdateExchangeRate")
+	defer span.End()
 	devLog.DebugContext(ctx, "updating exchange rate", "from", req.FromCurrency, "to", req.ToCurrency, "date", req.Date)
 
 	var exRate model.ExchangeRate
@@ -189,10 +212,21 @@ func (s *DeveloperService) UpdateExchangeRate(
 	}
 	defer func() { _ = tx.Rollback() }() //nolint:errcheck // Rollback is a no-op after Commit; error is intentionally ignored.
 
+	before, err := s.developerRepo.WithTx(tx).GetExchangeRate(exRate.FromCurrency, exRate.ToCurrency, exRate.Date)
+	if err != nil && !errors.Is(err, apperrors.ErrExchangeRateNotFound) {
+		return model.ExchangeRate{}, fmt.Errorf("get exchange rate: %w", err)
+	}
+
 	if err := s.developerRepo.WithTx(tx).UpdateExchangeRate(ctx, exRate); err != nil {
 		return model.ExchangeRate{}, fmt.Errorf("failed to update exchange rate: %w", err)
 	}
 
+	// Exchange rates are audited per currency pair; the date is part of the snapshot.
+	pair := exRate.FromCurrency + "/" + exRate.ToCurrency
+	if err := recordAuditUpsert(ctx, s.auditRepo.WithTx(tx), model.AuditEntityExchangeRate, pair, before, exRate); err != nil {
+		return model.ExchangeRate{}, err
+	}
+
 	if err = tx.Commit(); err != nil {
 		return model.ExchangeRate{}, fmt.Errorf("commit transaction: %w", err)
 	}
@@ -227,6 +261,8 @@ func (s *DeveloperService) UpdateFundPrice(
 	ctx context.Context,
 	req request.SetFundPriceRequest,
 ) (model.FundPrice, error) {
+	ctx, span := tracing.Start(ctx, "DeveloperService.UpdateFundPrice")
+	defer span.End()
 	devLog.DebugContext(ctx, "updating fund price", "fundID", req.FundID, "date", req.Date)
 
 	var fp model.FundPrice
@@ -251,21 +287,30 @@ func (s *DeveloperService) UpdateFundPrice(
 
 	fp.ID = uuid.New().String()
 
+	existing, err := s.fundRepo.WithTx(tx).GetFundPrice([]string{fp.FundID}, fp.Date, fp.Date, true)
+	if err != nil {
+		return model.FundPrice{}, fmt.Errorf("get fund price: %w", err)
+	}
+	var before *model.FundPrice
+	if prices := existing[fp.FundID]; len(prices) > 0 {
+		before = &prices[0]
+	}
+
 	if err := s.fundRepo.WithTx(tx).UpdateFundPrice(ctx, fp); err != nil {
 		return model.FundPrice{}, fmt.Errorf("failed to update fund price: %w", err)
 	}
 
+	// Manual price overrides are audited per fund; the date is part of the snapshot.
+	if err := recordAuditUpsert(ctx, s.auditRepo.WithTx(tx), model.AuditEntityFundPrice, fp.FundID, before, fp); err != nil {
+		return model.FundPrice{}, err
+	}
+
 	if err = tx.Commit(); err != nil {
 		return model.FundPrice{}, fmt.Errorf("commit transaction: %w", err)
 	}
 
 	if s.materializedInvalidator != nil {
-		//nolint:gosec // G118: Background context is intentional — goroutine outlives the HTTP request.
-		go func() {
-			if err := s.materializedInvalidator.RegenerateMaterializedTable(context.Background(), fp.Date, nil, fp.FundID, ""); err != nil {
-				devLog.Warn("failed to regenerate materialized table after manual price update", "error", err)
-			}
-		}()
+		s.materializedInvalidator.ScheduleRegeneration(ctx, fp.Date, nil, fp.FundID, "")
 	}
 
 	devLog.InfoContext(ctx, "fund price updated", "fundID", fp.FundID, "date", fp.Date.Format("2006-01-02"))
@@ -275,6 +320,8 @@ func (s *DeveloperService) UpdateFundPrice(
 // DeleteLogs clears all log entries and records a single "logs cleared" audit entry.
 // Both the deletion and the new audit log are performed within a single transaction.
 func (s *DeveloperService) DeleteLogs(ctx context.Context, ipAddress *string, userAgent string) error {
+	ctx, span := tracing.Start(ctx, "DeveloperService.DeleteLogs")
+	defer span.End()
 	devLog.DebugContext(ctx, "deleting logs")
 
 	tx, err := s.db.BeginTx(ctx, nil)
@@ -311,13 +358,16 @@ func (s *DeveloperService) DeleteLogs(ctx context.Context, ipAddress *string, us
 	return nil
 }
 
-// ImportFundPrices parses a CSV file and upserts fund prices for the given fund.
+// ImportFundPrices parses a CSV file and upserts fund prices for the given fund, recording
+// one "import" audit event with the prices it replaced and the imported prices.
 // Validates that the fund exists, the file is valid CSV with required headers,
 // and each row has a parseable date and positive price.
 // Triggers materialized view regeneration from the earliest imported date (Issue #35, Edge Case 9).
 //
 //nolint:gocyclo // CSV parsing + validation + batch insert + materialized invalidation
 func (s *DeveloperService) ImportFundPrices(ctx context.Context, fundID string, content []byte) (int, error) {
+	ctx, span := tracing.Start(ctx, "DeveloperService.ImportFundPrices")
+	defer span.End()
 	devLog.DebugContext(ctx, "importing fund prices from CSV", "fundID", fundID)
 	if _, err := s.fundRepo.GetFund(fundID); err != nil {
 		return 0, apperrors.ErrFundNotFound
@@ -342,8 +392,8 @@ func (s *DeveloperService) ImportFundPrices(ctx context.Context, fundID string,
 	}
 	defer func() { _ = tx.Rollback() }() //nolint:errcheck
 
-	count := 0
-	var earliestDate time.Time
+	prices := make([]model.FundPrice, 0, len(rows))
+	var earliestDate, latestDate time.Time
 	for i, row := range rows {
 		rowNum := i + 2 // 1-indexed, row 1 is headers
 
@@ -360,34 +410,55 @@ func (s *DeveloperService) ImportFundPrices(ctx context.Context, fundID string,
 		if earliestDate.IsZero() || date.Before(earliestDate) {
 			earliestDate = date
 		}
+		if date.After(latestDate) {
+			latestDate = date
+		}
 
-		fp := model.FundPrice{
+		prices = append(prices, model.FundPrice{
 			ID:     uuid.NewString(),
 			FundID: fundID,
 			Date:   date,
 			Price:  price,
-		}
-		if err := s.fundRepo.WithTx(tx).UpdateFundPrice(ctx, fp); err != nil {
-			return 0, fmt.Errorf("row %d: failed to upsert fund price: %w", rowNum, err)
-		}
-		count++
+		})
 	}
 
-	if count == 0 {
+	if len(prices) == 0 {
 		return 0, fmt.Errorf("no data rows found in CSV")
 	}
 
+	// The audit event holds the prices the import replaced, not the whole range.
+	existing, err := s.fundRepo.WithTx(tx).GetFundPrice([]string{fundID}, earliestDate, latestDate, true)
+	if err != nil {
+		return 0, fmt.Errorf("get fund prices: %w", err)
+	}
+	imported := make(map[string]bool, len(prices))
+	for i, fp := range prices {
+		imported[fp.Date.Format("2006-01-02")] = true
+		if err := s.fundRepo.WithTx(tx).UpdateFundPrice(ctx, fp); err != nil {
+			return 0, fmt.Errorf("row %d: failed to upsert fund price: %w", i+2, err)
+		}
+	}
+	var replaced []model.FundPrice
+	for _, fp := range existing[fundID] {
+		if imported[fp.Date.Format("2006-01-02")] {
+			replaced = append(replaced, fp)
+		}
+	}
+	var before any
+	if len(replaced) > 0 {
+		before = replaced
+	}
+	if err := recordAudit(ctx, s.auditRepo.WithTx(tx), model.AuditEntityFundPrice, fundID, model.AuditActionImport, before, prices); err != nil {
+		return 0, err
+	}
+	count := len(prices)
+
 	if err := tx.Commit(); err != nil {
 		return 0, fmt.Errorf("commit transaction: %w", err)
 	}
 
 	if s.materializedInvalidator != nil && earliestDate != (time.Time{}) {
-		//nolint:gosec // G118: Background context is intentional — goroutine outlives the HTTP request.
-		go func() {
-			if err := s.materializedInvalidator.RegenerateMaterializedTable(context.Background(), earliestDate, nil, fundID, ""); err != nil {
-				devLog.Warn("failed to regenerate materialized table after price import", "error", err)
-			}
-		}()
+		s.materializedInvalidator.ScheduleRegeneration(ctx, earliestDate, nil, fundID, "")
 	}
 
 	devLog.InfoContext(ctx, "fund prices imported", "fundID", fundID, "count", count)
@@ -443,6 +514,8 @@ func validateTransactionRow(row []string, colIdx map[string]int, rowNum int) (tr
 //
 //nolint:gocyclo // CSV parsing + validation + batch insert + materialized invalidation
 func (s *DeveloperService) ImportTransactions(ctx context.Context, portfolioFundID string, content []byte) (int, error) {
+	ctx, span := tracing.Start(ctx, "DeveloperService.ImportTransactions")
+	defer span.End()
 	devLog.DebugContext(ctx, "importing transactions from CSV", "portfolioFundID", portfolioFundID)
 	if _, err := s.pfRepo.GetPortfolioFund(portfolioFundID); err != nil {
 		return 0, apperrors.ErrPortfolioFundNotFound
@@ -493,6 +566,9 @@ func (s *DeveloperService) ImportTransactions(ctx context.Context, portfolioFund
 		if err := s.transactionRepo.WithTx(tx).InsertTransaction(ctx, t); err != nil {
 			return 0, fmt.Errorf("row %d: failed to insert transaction: %w", rowNum, err)
 		}
+		if err := recordAudit(ctx, s.auditRepo.WithTx(tx), model.AuditEntityTransaction, t.ID, model.AuditActionCreate, nil, t); err != nil {
+			return 0, err
+		}
 		count++
 	}
 
@@ -505,12 +581,7 @@ func (s *DeveloperService) ImportTransactions(ctx context.Context, portfolioFund
 	}
 
 	if s.materializedInvalidator != nil && !earliestDate.IsZero() {
-		//nolint:gosec // G118: Background context is intentional — goroutine outlives the HTTP request.
-		go func() {
-			if err := s.materializedInvalidator.RegenerateMaterializedTable(context.Background(), earliestDate, nil, "", portfolioFundID); err != nil {
-				devLog.Warn("failed to regenerate materialized table after transaction import", "error", err)
-			}
-		}()
+		s.materializedInvalidator.ScheduleRegeneration(ctx, earliestDate, nil, "", portfolioFundID)
 	}
 
 	devLog.InfoContext(ctx, "transactions imported", "portfolioFundID", portfolioFundID, "count", count)
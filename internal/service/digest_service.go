@@ -0,0 +1,348 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/api/request"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/apperrors"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/email"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/logging"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/model"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/repository"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/tracing"
+)
+
+var digestLog = logging.NewLogger("system")
+
+// digestSettingKey is the system_setting key of the email digest settings.
+const digestSettingKey = "EMAIL_DIGEST"
+
+// digestTopMovers is the number of funds listed as top movers per portfolio.
+const digestTopMovers = 3
+
+// DigestService builds the periodic portfolio digest and emails it to the configured
+// recipients. The digest content comes from the same materialized history as the
+// portfolio endpoints; the schedule is the email_digest scheduled task.
+type DigestService struct {
+	db                  *sql.DB
+	developerRepo       *repository.DeveloperRepository
+	auditRepo           *repository.AuditRepository
+	materializedService *MaterializedService
+	ibkrService         *IbkrService
+	sender              email.Sender // Nil when no SMTP server is configured
+}
+
+// NewDigestService creates a new DigestService with the provided dependencies.
+// sender is nil when no SMTP server is configured; sending then fails with
+// ErrEmailNotConfigured while settings and previews keep working.
+func NewDigestService(
+	db *sql.DB,
+	developerRepo *repository.DeveloperRepository,
+	auditRepo *repository.AuditRepository,
+	materializedService *MaterializedService,
+	ibkrService *IbkrService,
+	sender email.Sender,
+) *DigestService {
+	return &DigestService{
+		db:                  db,
+		developerRepo:       developerRepo,
+		auditRepo:           auditRepo,
+		materializedService: materializedService,
+		ibkrService:         ibkrService,
+		sender:              sender,
+	}
+}
+
+// GetSettings returns the stored digest settings, or the defaults (disabled, weekly, no
+// recipients) when none are stored.
+func (s *DigestService) GetSettings() (model.DigestSettings, error) {
+	return s.getSettings(s.developerRepo)
+}
+
+// UpdateSettings changes the enabled flag, period and/or recipients of the digest.
+// Only fields present in the request are changed.
+func (s *DigestService) UpdateSettings(ctx context.Context, req request.UpdateDigestSettingsRequest) (model.DigestSettings, error) {
+	ctx, span := tracing.Start(ctx, "DigestService.UpdateSettings")
+	defer span.End()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return model.DigestSettings{}, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }() //nolint:errcheck // Rollback is a no-op after Commit; error is intentionally ignored.
+
+	before, err := s.getSettings(s.developerRepo.WithTx(tx))
+	if err != nil {
+		return model.DigestSettings{}, err
+	}
+
+	after := before
+	if req.Enabled != nil {
+		after.Enabled = *req.Enabled
+	}
+	if req.Period != nil {
+		after.Period = model.DigestPeriod(*req.Period)
+	}
+	if req.Recipients != nil {
+		after.Recipients = req.Recipients
+	}
+
+	value, err := json.Marshal(after)
+	if err != nil {
+		return model.DigestSettings{}, fmt.Errorf("encode digest settings: %w", err)
+	}
+	now := time.Now().UTC()
+	setting := model.SystemSetting{
+		ID:        uuid.New().String(),
+		Key:       digestSettingKey,
+		Value:     string(value),
+		UpdatedAt: &now,
+	}
+	if err := s.developerRepo.WithTx(tx).SetSystemSetting(ctx, setting); err != nil {
+		return model.DigestSettings{}, fmt.Errorf("update %s: %w", setting.Key, err)
+	}
+
+	if err := recordAudit(ctx, s.auditRepo.WithTx(tx), model.AuditEntitySystemSetting, "email_digest", model.AuditActionUpdate, before, after); err != nil {
+		return model.DigestSettings{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return model.DigestSettings{}, fmt.Errorf("commit transaction: %w", err)
+	}
+
+	digestLog.InfoContext(ctx, "email digest settings updated", "enabled", after.Enabled,
+		"period", after.Period, "recipients", len(after.Recipients))
+	return after, nil
+}
+
+// BuildDigest assembles the digest of every active portfolio over period, ending today.
+// An empty period uses the period in the digest settings.
+func (s *DigestService) BuildDigest(ctx context.Context, period model.DigestPeriod) (model.Digest, error) {
+	ctx, span := tracing.Start(ctx, "DigestService.BuildDigest")
+	defer span.End()
+
+	if period == "" {
+		settings, err := s.GetSettings()
+		if err != nil {
+			return model.Digest{}, err
+		}
+		period = settings.Period
+	}
+
+	now := time.Now().UTC()
+	endDate := now.Truncate(24 * time.Hour)
+	startDate := period.Start(endDate)
+
+	summaries, err := s.materializedService.GetPortfolioSummaryWithFallback(ctx, "")
+	if err != nil {
+		return model.Digest{}, fmt.Errorf("get portfolio summaries: %w", err)
+	}
+	history, err := s.materializedService.GetPortfolioHistoryWithFallback(ctx, startDate, endDate, "", model.HistoryIntervalDay)
+	if err != nil {
+		return model.Digest{}, fmt.Errorf("get portfolio history: %w", err)
+	}
+
+	// The first point of each portfolio in the period is its state at the start.
+	starts := make(map[string]model.PortfolioSummary)
+	for _, day := range history {
+		for _, p := range day.Portfolios {
+			if _, ok := starts[p.ID]; !ok {
+				starts[p.ID] = p
+			}
+		}
+	}
+
+	digest := model.Digest{
+		Period:      period,
+		StartDate:   startDate,
+		EndDate:     endDate,
+		Portfolios:  make([]model.DigestPortfolio, 0, len(summaries)),
+		GeneratedAt: now,
+	}
+	for _, summary := range summaries {
+		if summary.IsArchived {
+			continue
+		}
+		start := starts[summary.ID]
+		portfolio := model.DigestPortfolio{
+			ID:         summary.ID,
+			Name:       summary.Name,
+			Value:      summary.TotalValue,
+			StartValue: start.TotalValue,
+			Change:     summary.TotalGainLoss - start.TotalGainLoss,
+			Dividends:  summary.TotalDividends - start.TotalDividends,
+		}
+		if start.TotalValue > 0 {
+			portfolio.ChangePercent = portfolio.Change / start.TotalValue * 100
+		}
+		portfolio.TopMovers, err = s.topMovers(ctx, summary.ID, startDate, endDate)
+		if err != nil {
+			return model.Digest{}, err
+		}
+
+		digest.Portfolios = append(digest.Portfolios, portfolio)
+		digest.TotalValue += portfolio.Value
+		digest.TotalChange += portfolio.Change
+		digest.TotalDividends += portfolio.Dividends
+	}
+
+	inbox, err := s.ibkrService.GetInboxCount()
+	if err != nil {
+		return model.Digest{}, err
+	}
+	digest.PendingInboxCount = inbox.Count
+
+	return digest, nil
+}
+
+// topMovers returns the funds of a portfolio with the largest absolute change in total
+// gain/loss between the first and last day of fund history in the period.
+func (s *DigestService) topMovers(ctx context.Context, portfolioID string, startDate, endDate time.Time) ([]model.DigestMover, error) {
+	history, err := s.materializedService.GetFundHistoryWithFallback(ctx, portfolioID, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("get fund history of %s: %w", portfolioID, err)
+	}
+	if len(history) == 0 {
+		return []model.DigestMover{}, nil
+	}
+
+	starts := make(map[string]model.FundHistoryEntry)
+	for _, day := range history {
+		for _, fund := range day.Funds {
+			if _, ok := starts[fund.PortfolioFundID]; !ok {
+				starts[fund.PortfolioFundID] = fund
+			}
+		}
+	}
+
+	movers := []model.DigestMover{}
+	for _, fund := range history[len(history)-1].Funds {
+		start := starts[fund.PortfolioFundID]
+		change := fund.TotalGainLoss - start.TotalGainLoss
+		if math.Abs(change) < 0.005 {
+			continue
+		}
+		mover := model.DigestMover{FundName: fund.FundName, Change: change}
+		if start.Value > 0 {
+			mover.ChangePercent = change / start.Value * 100
+		}
+		movers = append(movers, mover)
+	}
+	slices.SortFunc(movers, func(a, b model.DigestMover) int {
+		return cmp.Compare(math.Abs(b.Change), math.Abs(a.Change))
+	})
+	if len(movers) > digestTopMovers {
+		movers = movers[:digestTopMovers]
+	}
+	return movers, nil
+}
+
+// RenderDigest builds the digest over period and renders it as an email to the configured
+// recipients, without sending it.
+func (s *DigestService) RenderDigest(ctx context.Context, period model.DigestPeriod) (email.Message, error) {
+	settings, err := s.GetSettings()
+	if err != nil {
+		return email.Message{}, err
+	}
+	if period == "" {
+		period = settings.Period
+	}
+	digest, err := s.BuildDigest(ctx, period)
+	if err != nil {
+		return email.Message{}, err
+	}
+
+	text, html, err := email.Render("digest", digest)
+	if err != nil {
+		return email.Message{}, err
+	}
+	title := "Weekly"
+	if digest.Period == model.DigestPeriodMonth {
+		title = "Monthly"
+	}
+	return email.Message{
+		To:      settings.Recipients,
+		Subject: fmt.Sprintf("%s portfolio digest %s", title, digest.EndDate.Format("2006-01-02")),
+		Text:    text,
+		HTML:    html,
+	}, nil
+}
+
+// SendDigest builds the digest with the configured period and emails it to the configured
+// recipients. Scheduled runs are skipped while the digest is disabled, reported by a false
+// sent result.
+// Returns ErrEmailNotConfigured when no SMTP server is configured, or ErrDigestNoRecipients
+// when there is nobody to send to.
+func (s *DigestService) SendDigest(ctx context.Context, scheduled bool) (sent bool, err error) {
+	ctx, span := tracing.Start(ctx, "DigestService.SendDigest")
+	defer func() { tracing.End(span, err) }()
+
+	settings, err := s.GetSettings()
+	if err != nil {
+		return false, err
+	}
+	if scheduled && !settings.Enabled {
+		return false, nil
+	}
+	if s.sender == nil {
+		return false, apperrors.ErrEmailNotConfigured
+	}
+	if len(settings.Recipients) == 0 {
+		return false, apperrors.ErrDigestNoRecipients
+	}
+
+	msg, err := s.RenderDigest(ctx, settings.Period)
+	if err != nil {
+		return false, err
+	}
+	if err := s.sender.Send(ctx, msg); err != nil {
+		return false, fmt.Errorf("send digest: %w", err)
+	}
+	digestLog.InfoContext(ctx, "email digest sent", "period", settings.Period, "recipients", len(msg.To))
+	return true, nil
+}
+
+// Notify emails subject and text to the digest recipients, whether or not the digest itself
+// is enabled. Nothing is sent when no SMTP server is configured or there are no recipients.
+func (s *DigestService) Notify(ctx context.Context, subject, text string) error {
+	if s.sender == nil {
+		return nil
+	}
+	settings, err := s.GetSettings()
+	if err != nil {
+		return err
+	}
+	if len(settings.Recipients) == 0 {
+		return nil
+	}
+	if err := s.sender.Send(ctx, email.Message{To: settings.Recipients, Subject: subject, Text: text}); err != nil {
+		return fmt.Errorf("send notification: %w", err)
+	}
+	return nil
+}
+
+// getSettings returns the stored digest settings, filling anything not stored from the defaults.
+func (s *DigestService) getSettings(repo *repository.DeveloperRepository) (model.DigestSettings, error) {
+	settings := model.DigestSettings{
+		Period:     model.DigestPeriodWeek,
+		Recipients: []string{},
+	}
+	value, found, err := repo.GetSystemSetting(digestSettingKey)
+	if err != nil {
+		return settings, fmt.Errorf("get digest settings: %w", err)
+	}
+	if found {
+		if err := json.Unmarshal([]byte(value), &settings); err != nil {
+			return settings, fmt.Errorf("decode digest settings: %w", err)
+		}
+	}
+	return settings, nil
+}
@@ -0,0 +1,215 @@
+package service_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/api/request"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/apperrors"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/email"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/model"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/service"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/testutil"
+)
+
+// recordingSender is an email.Sender that keeps every message instead of sending it.
+type recordingSender struct {
+	mu       sync.Mutex
+	messages []email.Message
+	err      error
+}
+
+func (s *recordingSender) Send(_ context.Context, msg email.Message) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return s.err
+	}
+	s.messages = append(s.messages, msg)
+	return nil
+}
+
+// buildDigestPortfolio creates a portfolio holding 10 shares of one fund, bought 30 days ago
+// at 10.00 and priced at 10.00 until a week ago and 12.00 today, with one pending IBKR
+// inbox transaction.
+func buildDigestPortfolio(t *testing.T, db *sql.DB) model.Portfolio {
+	t.Helper()
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+
+	portfolio := testutil.NewPortfolio().WithName("Family").Build(t, db)
+	fund := testutil.NewFund().WithName("World ETF").Build(t, db)
+	pf := testutil.NewPortfolioFund(portfolio.ID, fund.ID).Build(t, db)
+	testutil.NewTransaction(pf.ID).WithDate(today.AddDate(0, 0, -30)).WithShares(10).WithCostPerShare(10).Build(t, db)
+	for days := 30; days >= 7; days-- {
+		testutil.NewFundPrice(fund.ID).WithDate(today.AddDate(0, 0, -days)).WithPrice(10).Build(t, db)
+	}
+	testutil.NewFundPrice(fund.ID).WithDate(today).WithPrice(12).Build(t, db)
+	testutil.NewIBKRTransaction().Build(t, db)
+	return portfolio
+}
+
+func TestDigestService_Settings(t *testing.T) {
+	t.Run("defaults to a disabled weekly digest", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		svc := testutil.NewTestDigestService(t, db, nil)
+
+		settings, err := svc.GetSettings()
+		if err != nil {
+			t.Fatalf("GetSettings: %v", err)
+		}
+		if settings.Enabled || settings.Period != model.DigestPeriodWeek || len(settings.Recipients) != 0 {
+			t.Errorf("unexpected defaults %+v", settings)
+		}
+	})
+
+	t.Run("stores and audits updates", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		svc := testutil.NewTestDigestService(t, db, nil)
+
+		enabled := true
+		month := "month"
+		if _, err := svc.UpdateSettings(context.Background(), request.UpdateDigestSettingsRequest{
+			Enabled:    &enabled,
+			Period:     &month,
+			Recipients: []string{"alice@example.com"},
+		}); err != nil {
+			t.Fatalf("UpdateSettings: %v", err)
+		}
+		// Omitted fields keep their value.
+		settings, err := svc.UpdateSettings(context.Background(), request.UpdateDigestSettingsRequest{Recipients: []string{"bob@example.com"}})
+		if err != nil {
+			t.Fatalf("UpdateSettings: %v", err)
+		}
+		if !settings.Enabled || settings.Period != model.DigestPeriodMonth || len(settings.Recipients) != 1 || settings.Recipients[0] != "bob@example.com" {
+			t.Errorf("unexpected settings %+v", settings)
+		}
+
+		stored, err := svc.GetSettings()
+		if err != nil || stored.Period != model.DigestPeriodMonth {
+			t.Errorf("expected the stored settings, got %+v (%v)", stored, err)
+		}
+		if n := countRows(t, db, "audit_event", "entity_type = ? AND entity_id = ?", model.AuditEntitySystemSetting, "email_digest"); n != 2 {
+			t.Errorf("expected 2 audit entries, got %d", n)
+		}
+	})
+}
+
+func TestDigestService_BuildDigest(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	svc := testutil.NewTestDigestService(t, db, nil)
+	portfolio := buildDigestPortfolio(t, db)
+	testutil.NewPortfolio().WithName("Old").Archived().Build(t, db)
+
+	digest, err := svc.BuildDigest(context.Background(), "")
+	if err != nil {
+		t.Fatalf("BuildDigest: %v", err)
+	}
+
+	if digest.Period != model.DigestPeriodWeek || digest.EndDate.Sub(digest.StartDate) != 7*24*time.Hour {
+		t.Errorf("expected a weekly period, got %s %v - %v", digest.Period, digest.StartDate, digest.EndDate)
+	}
+	if digest.PendingInboxCount != 1 {
+		t.Errorf("expected 1 pending inbox transaction, got %d", digest.PendingInboxCount)
+	}
+	if len(digest.Portfolios) != 1 {
+		t.Fatalf("expected only the active portfolio, got %+v", digest.Portfolios)
+	}
+
+	got := digest.Portfolios[0]
+	if got.ID != portfolio.ID || got.Value != 120 || got.StartValue != 100 {
+		t.Errorf("expected value 100 -> 120, got %+v", got)
+	}
+	if math.Abs(got.Change-20) > 1e-9 || math.Abs(got.ChangePercent-20) > 1e-9 {
+		t.Errorf("expected a change of 20 (20%%), got %v (%v%%)", got.Change, got.ChangePercent)
+	}
+	if len(got.TopMovers) != 1 || got.TopMovers[0].FundName != "World ETF" || math.Abs(got.TopMovers[0].Change-20) > 1e-9 {
+		t.Errorf("expected World ETF as top mover, got %+v", got.TopMovers)
+	}
+	if digest.TotalValue != 120 || math.Abs(digest.TotalChange-20) > 1e-9 {
+		t.Errorf("unexpected totals %v / %v", digest.TotalValue, digest.TotalChange)
+	}
+}
+
+func TestDigestService_SendDigest(t *testing.T) {
+	enable := func(t *testing.T, svc *service.DigestService, enabled bool, recipients ...string) {
+		t.Helper()
+		if _, err := svc.UpdateSettings(context.Background(), request.UpdateDigestSettingsRequest{Enabled: &enabled, Recipients: recipients}); err != nil {
+			t.Fatalf("UpdateSettings: %v", err)
+		}
+	}
+
+	t.Run("sends the rendered digest to the recipients", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		sender := &recordingSender{}
+		svc := testutil.NewTestDigestService(t, db, sender)
+		buildDigestPortfolio(t, db)
+		enable(t, svc, true, "alice@example.com", "bob@example.com")
+
+		sent, err := svc.SendDigest(context.Background(), true)
+		if err != nil || !sent {
+			t.Fatalf("SendDigest: sent=%v err=%v", sent, err)
+		}
+		if len(sender.messages) != 1 {
+			t.Fatalf("expected one message, got %d", len(sender.messages))
+		}
+		msg := sender.messages[0]
+		if len(msg.To) != 2 || !strings.HasPrefix(msg.Subject, "Weekly portfolio digest") {
+			t.Errorf("unexpected message %+v", msg)
+		}
+		if !strings.Contains(msg.Text, "Family") || !strings.Contains(msg.Text, "World ETF: +20.00") || !strings.Contains(msg.HTML, "IBKR transaction is waiting") {
+			t.Errorf("unexpected body:\n%s", msg.Text)
+		}
+	})
+
+	t.Run("scheduled run is skipped while disabled", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		sender := &recordingSender{}
+		svc := testutil.NewTestDigestService(t, db, sender)
+		enable(t, svc, false, "alice@example.com")
+
+		sent, err := svc.SendDigest(context.Background(), true)
+		if err != nil || sent || len(sender.messages) != 0 {
+			t.Errorf("expected a skipped run, got sent=%v err=%v", sent, err)
+		}
+
+		// Sending on request ignores the enabled flag.
+		if sent, err := svc.SendDigest(context.Background(), false); err != nil || !sent {
+			t.Errorf("expected a manual send, got sent=%v err=%v", sent, err)
+		}
+	})
+
+	t.Run("no recipients", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		svc := testutil.NewTestDigestService(t, db, &recordingSender{})
+
+		if _, err := svc.SendDigest(context.Background(), false); !errors.Is(err, apperrors.ErrDigestNoRecipients) {
+			t.Errorf("expected ErrDigestNoRecipients, got %v", err)
+		}
+	})
+
+	t.Run("email not configured", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		svc := testutil.NewTestDigestService(t, db, nil)
+		enable(t, svc, true, "alice@example.com")
+
+		if _, err := svc.SendDigest(context.Background(), true); !errors.Is(err, apperrors.ErrEmailNotConfigured) {
+			t.Errorf("expected ErrEmailNotConfigured, got %v", err)
+		}
+	})
+
+	t.Run("send failure is returned", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		svc := testutil.NewTestDigestService(t, db, &recordingSender{err: errors.New("550 rejected")})
+		enable(t, svc, true, "alice@example.com")
+
+		if _, err := svc.SendDigest(context.Background(), true); err == nil || !strings.Contains(err.Error(), "550 rejected") {
+			t.Errorf("expected the send error, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,172 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/model"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/repository"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/tracing"
+)
+
+// DividendIncomeService reports the dividend income of portfolios and forecasts it.
+type DividendIncomeService struct {
+	dividendRepo    *repository.DividendRepository
+	transactionRepo *repository.TransactionRepository
+	fundService     *FundService
+}
+
+// NewDividendIncomeService creates a new DividendIncomeService with the provided dependencies.
+func NewDividendIncomeService(
+	dividendRepo *repository.DividendRepository,
+	transactionRepo *repository.TransactionRepository,
+	fundService *FundService,
+) *DividendIncomeService {
+	return &DividendIncomeService{
+		dividendRepo:    dividendRepo,
+		transactionRepo: transactionRepo,
+		fundService:     fundService,
+	}
+}
+
+// incomeTotals accumulates received income per month and per year.
+type incomeTotals struct {
+	monthly map[string]float64
+	yearly  map[string]float64
+}
+
+func newIncomeTotals() incomeTotals {
+	return incomeTotals{monthly: make(map[string]float64), yearly: make(map[string]float64)}
+}
+
+func (t incomeTotals) add(date time.Time, amount float64) {
+	t.monthly[date.Format("2006-01")] += amount
+	t.yearly[date.Format("2006")] += amount
+}
+
+// GetDividendIncome reports the dividend income of a portfolio and of each of its funds
+// as of today.
+//
+// Received income is grouped per month and year by ex-dividend date. Yield on cost and
+// current yield divide the income of the last 12 months by the cost basis and value of
+// the current position. The forecast assumes every fund pays again what it paid in the
+// last 12 months, one year later and at the same dividend per share, on the shares held
+// today (GetSharesOnDate); a fund that paid nothing in the last year is forecast to pay
+// nothing. Returns ErrPortfolioNotFound if the portfolio does not exist.
+func (s *DividendIncomeService) GetDividendIncome(ctx context.Context, portfolioID string) (income model.DividendIncome, err error) {
+	_, span := tracing.Start(ctx, "DividendIncomeService.GetDividendIncome")
+	defer func() { tracing.End(span, err) }()
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	yearAgo := today.AddDate(-1, 0, 0)
+
+	holdings, err := s.fundService.GetPortfolioFunds(portfolioID)
+	if err != nil {
+		return model.DividendIncome{}, err
+	}
+	pfIDs := make([]string, len(holdings))
+	for i, h := range holdings {
+		pfIDs[i] = h.ID
+	}
+	dividends, err := s.dividendRepo.GetDividendPerPF(pfIDs, time.Time{}, today)
+	if err != nil {
+		return model.DividendIncome{}, fmt.Errorf("get dividends: %w", err)
+	}
+
+	income = model.DividendIncome{
+		PortfolioID: portfolioID,
+		AsOf:        today.Format("2006-01-02"),
+		Funds:       make([]model.FundDividendIncome, 0, len(holdings)),
+	}
+	portfolioTotals := newIncomeTotals()
+	forecastMonthly := make(map[string]float64)
+
+	for _, h := range holdings {
+		shares, err := s.transactionRepo.GetSharesOnDate(h.ID, today)
+		if err != nil {
+			return model.DividendIncome{}, fmt.Errorf("get shares: %w", err)
+		}
+		fund := model.FundDividendIncome{
+			PortfolioFundID: h.ID,
+			FundID:          h.FundID,
+			FundName:        h.FundName,
+			Shares:          round(shares),
+			CostBasis:       h.TotalCost,
+			CurrentValue:    h.CurrentValue,
+			Payments:        []model.ForecastPayment{},
+		}
+		fundTotals := newIncomeTotals()
+
+		for _, d := range dividends[h.ID] {
+			fundTotals.add(d.ExDividendDate, d.TotalAmount)
+			portfolioTotals.add(d.ExDividendDate, d.TotalAmount)
+			fund.TotalReceived += d.TotalAmount
+			if !d.ExDividendDate.After(yearAgo) {
+				continue
+			}
+
+			fund.Trailing12Months += d.TotalAmount
+			fund.PaymentsPerYear++
+			if shares <= 0 {
+				continue
+			}
+			payment := model.ForecastPayment{
+				ExDividendDate:   d.ExDividendDate.AddDate(1, 0, 0),
+				DividendPerShare: d.DividendPerShare,
+				Shares:           round(shares),
+				Amount:           round(d.DividendPerShare * shares),
+			}
+			fund.Payments = append(fund.Payments, payment)
+			fund.Forecast12Months += payment.Amount
+			forecastMonthly[payment.ExDividendDate.Format("2006-01")] += payment.Amount
+		}
+
+		fund.TotalReceived = round(fund.TotalReceived)
+		fund.Trailing12Months = round(fund.Trailing12Months)
+		fund.Forecast12Months = round(fund.Forecast12Months)
+		fund.YieldOnCost = yieldPercent(fund.Trailing12Months, fund.CostBasis)
+		fund.CurrentYield = yieldPercent(fund.Trailing12Months, fund.CurrentValue)
+		fund.Monthly = incomePeriods(fundTotals.monthly)
+		fund.Yearly = incomePeriods(fundTotals.yearly)
+
+		income.TotalReceived += fund.TotalReceived
+		income.Trailing12Months += fund.Trailing12Months
+		income.Forecast12Months += fund.Forecast12Months
+		income.CostBasis += fund.CostBasis
+		income.CurrentValue += fund.CurrentValue
+		income.Funds = append(income.Funds, fund)
+	}
+
+	income.TotalReceived = round(income.TotalReceived)
+	income.Trailing12Months = round(income.Trailing12Months)
+	income.Forecast12Months = round(income.Forecast12Months)
+	income.CostBasis = round(income.CostBasis)
+	income.CurrentValue = round(income.CurrentValue)
+	income.YieldOnCost = yieldPercent(income.Trailing12Months, income.CostBasis)
+	income.CurrentYield = yieldPercent(income.Trailing12Months, income.CurrentValue)
+	income.Monthly = incomePeriods(portfolioTotals.monthly)
+	income.Yearly = incomePeriods(portfolioTotals.yearly)
+	income.Forecast = incomePeriods(forecastMonthly)
+	return income, nil
+}
+
+// yieldPercent returns income as a percentage of base, or 0 when there is no base.
+func yieldPercent(income, base float64) float64 {
+	if base <= 0 {
+		return 0
+	}
+	return round(income / base * 100)
+}
+
+// incomePeriods returns the amounts per period in chronological order.
+func incomePeriods(amounts map[string]float64) []model.IncomePeriod {
+	periods := make([]model.IncomePeriod, 0, len(amounts))
+	for period, amount := range amounts {
+		periods = append(periods, model.IncomePeriod{Period: period, Amount: round(amount)})
+	}
+	slices.SortFunc(periods, func(a, b model.IncomePeriod) int { return strings.Compare(a.Period, b.Period) })
+	return periods
+}
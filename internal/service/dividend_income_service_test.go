@@ -0,0 +1,83 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/apperrors"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/testutil"
+)
+
+func TestDividendIncomeService_GetDividendIncome(t *testing.T) {
+	t.Run("reports received income, yields and the forecast", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		svc := testutil.NewTestDividendIncomeService(t, db)
+		today := time.Now().UTC().Truncate(24 * time.Hour)
+
+		portfolio := testutil.NewPortfolio().Build(t, db)
+		fund := testutil.NewFund().WithName("Dividend ETF").Build(t, db)
+		idle := testutil.NewFund().WithName("Growth ETF").Build(t, db)
+		pf := testutil.NewPortfolioFund(portfolio.ID, fund.ID).Build(t, db)
+		testutil.NewPortfolioFund(portfolio.ID, idle.ID).Build(t, db)
+
+		testutil.NewTransaction(pf.ID).WithDate(today.AddDate(-2, 0, 0)).WithShares(100).WithCostPerShare(10).Build(t, db)
+		testutil.NewTransaction(pf.ID).WithDate(today.AddDate(0, 0, -1)).WithShares(100).WithCostPerShare(10).Build(t, db)
+		testutil.NewFundPrice(fund.ID).WithDate(today).WithPrice(20).Build(t, db)
+
+		// Quarterly payments of 0.50 on 100 shares; the oldest is outside the trailing year.
+		for _, months := range []int{-13, -10, -7, -4, -1} {
+			testutil.NewDividend(fund.ID, pf.ID).WithExDividendDate(today.AddDate(0, months, 0)).Build(t, db)
+		}
+
+		income, err := svc.GetDividendIncome(context.Background(), portfolio.ID)
+		if err != nil {
+			t.Fatalf("GetDividendIncome: %v", err)
+		}
+
+		if income.TotalReceived != 250 || income.Trailing12Months != 200 {
+			t.Errorf("expected 250 received and 200 in the last year, got %+v", income)
+		}
+		if income.CostBasis != 2000 || income.YieldOnCost != 10 || income.CurrentValue != 4000 || income.CurrentYield != 5 {
+			t.Errorf("expected a 10%% yield on cost and 5%% current yield, got %+v", income)
+		}
+		// The next four payments fall on today's 200 shares.
+		if income.Forecast12Months != 400 || len(income.Forecast) != 4 {
+			t.Errorf("expected a forecast of 400 over four months, got %v %+v", income.Forecast12Months, income.Forecast)
+		}
+
+		var monthly float64
+		for _, p := range income.Monthly {
+			monthly += p.Amount
+		}
+		if len(income.Monthly) != 5 || monthly != 250 || len(income.Yearly) == 0 {
+			t.Errorf("expected five months adding up to 250, got %+v", income.Monthly)
+		}
+
+		if len(income.Funds) != 2 {
+			t.Fatalf("expected both funds, got %+v", income.Funds)
+		}
+		for _, f := range income.Funds {
+			switch f.FundID {
+			case fund.ID:
+				if f.Shares != 200 || f.PaymentsPerYear != 4 || len(f.Payments) != 4 ||
+					!f.Payments[0].ExDividendDate.Equal(today.AddDate(0, -10, 0).AddDate(1, 0, 0)) || f.Payments[0].Amount != 100 {
+					t.Errorf("unexpected fund income: %+v", f)
+				}
+			case idle.ID:
+				if f.TotalReceived != 0 || f.Forecast12Months != 0 || len(f.Payments) != 0 || len(f.Monthly) != 0 {
+					t.Errorf("expected no income for the fund without dividends, got %+v", f)
+				}
+			}
+		}
+	})
+
+	t.Run("unknown portfolio", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		svc := testutil.NewTestDividendIncomeService(t, db)
+		if _, err := svc.GetDividendIncome(context.Background(), testutil.MakeID()); !errors.Is(err, apperrors.ErrPortfolioNotFound) {
+			t.Errorf("expected ErrPortfolioNotFound, got %v", err)
+		}
+	})
+}
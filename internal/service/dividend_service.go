@@ -8,9 +8,11 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/api/request"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/auth"
 	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/logging"
 	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/model"
 	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/repository"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/tracing"
 )
 
 var divLog = logging.NewLogger("dividend")
@@ -21,6 +23,9 @@ type DividendService struct {
 	dividendRepo            *repository.DividendRepository
 	pfRepo                  *repository.PortfolioFundRepository
 	transactionRepo         *repository.TransactionRepository
+	auditRepo               *repository.AuditRepository
+	trashRepo               *repository.TrashRepository
+	withholdingRepo         *repository.WithholdingTaxRepository
 	materializedInvalidator MaterializedInvalidator
 }
 
@@ -42,6 +47,9 @@ func NewDividendService(
 		dividendRepo:    dividendRepo,
 		pfRepo:          pfRepo,
 		transactionRepo: transactionRepo,
+		auditRepo:       repository.NewAuditRepository(db),
+		trashRepo:       repository.NewTrashRepository(db),
+		withholdingRepo: repository.NewWithholdingTaxRepository(db),
 	}
 }
 
@@ -51,11 +59,11 @@ func (s *DividendService) SetMaterializedInvalidator(m MaterializedInvalidator)
 	s.materializedInvalidator = m
 }
 
-// GetAllDividend retrieves all dividend records from the database.
+// GetAllDividend retrieves the dividend records of every portfolio the caller in ctx may read.
 // Returns raw dividend data without fund enrichment.
-func (s *DividendService) GetAllDividend() ([]model.Dividend, error) {
+func (s *DividendService) GetAllDividend(ctx context.Context) ([]model.Dividend, error) {
 	divLog.Debug("retrieving all dividends")
-	result, err := s.dividendRepo.GetAllDividend()
+	result, err := s.dividendRepo.GetAllDividend(auth.Scope(ctx))
 	if err != nil {
 		return nil, fmt.Errorf("get all dividends: %w", err)
 	}
@@ -80,10 +88,12 @@ func (s *DividendService) GetDividend(DividendID string) (model.Dividend, error)
 //   - portfolioID: Filter by portfolio ID (mutually exclusive with fundID)
 //   - fundID:      Filter by fund ID (mutually exclusive with portfolioID; checked if portfolioID is empty)
 //
+// Only dividends of portfolios the caller in ctx may read are returned.
+//
 // Returns a slice of DividendFund containing all historical dividend payments for the given filter.
-func (s *DividendService) GetDividendFund(portfolioID, fundID string) ([]model.DividendFund, error) {
+func (s *DividendService) GetDividendFund(ctx context.Context, portfolioID, fundID string) ([]model.DividendFund, error) {
 	divLog.Debug("retrieving dividend fund", "portfolioID", portfolioID, "fundID", fundID)
-	dividendFund, err := s.dividendRepo.GetDividendPerPortfolioFund(portfolioID, fundID)
+	dividendFund, err := s.dividendRepo.GetDividendPerPortfolioFund(portfolioID, fundID, auth.Scope(ctx))
 	if err != nil {
 		return nil, fmt.Errorf("get dividend fund: %w", err)
 	}
@@ -171,6 +181,8 @@ func (s *DividendService) processDividendAmountForDate(dividend []model.Dividend
 //
 // Returns the created dividend with its generated ID, or an error if creation fails.
 func (s *DividendService) CreateDividend(ctx context.Context, req request.CreateDividendRequest) (*model.DividendFund, error) {
+	ctx, span := tracing.Start(ctx, "DividendService.CreateDividend")
+	defer span.End()
 	divLog.DebugContext(ctx, "creating dividend", "portfolioFundID", req.PortfolioFundID)
 	portfolioFund, err := s.pfRepo.GetPortfolioFundListing(req.PortfolioFundID)
 	if err != nil {
@@ -208,6 +220,13 @@ func (s *DividendService) CreateDividend(ctx context.Context, req request.Create
 		CreatedAt:        time.Now().UTC(),
 	}
 
+	if req.WithholdingTax != nil {
+		dividend.WithholdingTax = *req.WithholdingTax
+		dividend.WithholdingSource = model.WithholdingSourceManual
+	} else if err := s.applyDefaultWithholding(dividend); err != nil {
+		return nil, err
+	}
+
 	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
 		return nil, fmt.Errorf("begin transaction: %w", err)
@@ -228,17 +247,16 @@ func (s *DividendService) CreateDividend(ctx context.Context, req request.Create
 		return nil, fmt.Errorf("failed to create dividend: %w", err)
 	}
 
+	if err := recordAudit(ctx, s.auditRepo.WithTx(tx), model.AuditEntityDividend, dividend.ID, model.AuditActionCreate, nil, dividend); err != nil {
+		return nil, err
+	}
+
 	if err := tx.Commit(); err != nil {
 		return nil, fmt.Errorf("commit transaction: %w", err)
 	}
 
 	if s.materializedInvalidator != nil {
-		//nolint:gosec // G118: Background context is intentional — goroutine outlives the HTTP request.
-		go func() {
-			if err := s.materializedInvalidator.RegenerateMaterializedTable(context.Background(), dividend.ExDividendDate, nil, "", req.PortfolioFundID); err != nil {
-				divLog.Warn("failed to regenerate materialized table", "error", err)
-			}
-		}()
+		s.materializedInvalidator.ScheduleRegeneration(ctx, dividend.ExDividendDate, nil, "", req.PortfolioFundID)
 	}
 
 	divLog.InfoContext(ctx, "dividend created", "dividendID", dividend.ID, "portfolioFundID", req.PortfolioFundID, "totalAmount", dividend.TotalAmount)
@@ -248,7 +266,8 @@ func (s *DividendService) CreateDividend(ctx context.Context, req request.Create
 // UpdateDividend updates an existing dividend with the provided changes.
 // Only fields present in the request (non-nil) are updated; all others retain their current values.
 // SharesOwned and TotalAmount are always recalculated from transactions as of the (possibly updated)
-// ex-dividend date.
+// ex-dividend date. A withholding tax derived from the default rate is recalculated as well;
+// manually entered or IBKR-imported withholding tax is kept unless the request sets it.
 //
 // ReinvestmentStatus is re-evaluated after the update using the same rules as CreateDividend:
 //
@@ -275,12 +294,15 @@ func (s *DividendService) UpdateDividend(
 	id string,
 	req request.UpdateDividendRequest,
 ) (*model.DividendFund, error) {
+	ctx, span := tracing.Start(ctx, "DividendService.UpdateDividend")
+	defer span.End()
 	divLog.DebugContext(ctx, "updating dividend", "dividendID", id)
 	dividend, err := s.dividendRepo.GetDividend(id)
 	if err != nil {
 		return nil, fmt.Errorf("get dividend: %w", err)
 	}
 
+	before := dividend
 	oldExDividendDate := dividend.ExDividendDate
 
 	if req.PortfolioFundID != nil {
@@ -305,6 +327,15 @@ func (s *DividendService) UpdateDividend(
 	dividend.TotalAmount = shares * dividend.DividendPerShare
 	dividend.CreatedAt = time.Now().UTC() // Intentional: tracks latest modification, not original creation.
 
+	if req.WithholdingTax != nil {
+		dividend.WithholdingTax = *req.WithholdingTax
+		dividend.WithholdingSource = model.WithholdingSourceManual
+	} else if dividend.WithholdingSource == model.WithholdingSourceDefault {
+		if err := s.applyDefaultWithholding(&dividend); err != nil {
+			return nil, err
+		}
+	}
+
 	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
 		return nil, fmt.Errorf("begin transaction: %w", err)
@@ -325,6 +356,10 @@ func (s *DividendService) UpdateDividend(
 		return nil, fmt.Errorf("failed to update dividend: %w", err)
 	}
 
+	if err := recordAudit(ctx, s.auditRepo.WithTx(tx), model.AuditEntityDividend, id, model.AuditActionUpdate, before, dividend); err != nil {
+		return nil, err
+	}
+
 	if err := tx.Commit(); err != nil {
 		return nil, fmt.Errorf("commit transaction: %w", err)
 	}
@@ -334,12 +369,7 @@ func (s *DividendService) UpdateDividend(
 		if oldExDividendDate.Before(regenDate) {
 			regenDate = oldExDividendDate
 		}
-		//nolint:gosec // G118: Background context is intentional — goroutine outlives the HTTP request.
-		go func() {
-			if err := s.materializedInvalidator.RegenerateMaterializedTable(context.Background(), regenDate, nil, "", dividend.PortfolioFundID); err != nil {
-				divLog.Warn("failed to regenerate materialized table", "error", err)
-			}
-		}()
+		s.materializedInvalidator.ScheduleRegeneration(ctx, regenDate, nil, "", dividend.PortfolioFundID)
 	}
 
 	divLog.InfoContext(ctx, "dividend updated", "dividendID", id)
@@ -478,6 +508,18 @@ func (s *DividendService) updateReinvestmentTransaction(ctx context.Context, tx
 	return nil
 }
 
+// applyDefaultWithholding sets the dividend's withholding tax to its gross amount times the
+// default rate of the fund's domicile.
+func (s *DividendService) applyDefaultWithholding(dividend *model.Dividend) error {
+	rate, err := s.withholdingRepo.GetFundWithholdingTaxRate(dividend.FundID)
+	if err != nil {
+		return fmt.Errorf("get withholding tax rate: %w", err)
+	}
+	dividend.WithholdingTax = round(dividend.TotalAmount * rate / 100)
+	dividend.WithholdingSource = model.WithholdingSourceDefault
+	return nil
+}
+
 // dividendToFund maps a Dividend and its associated PortfolioFundListing into a DividendFund response.
 func dividendToFund(d model.Dividend, pf model.PortfolioFundListing) *model.DividendFund {
 	var buyOrderDate *time.Time
@@ -486,7 +528,7 @@ func dividendToFund(d model.Dividend, pf model.PortfolioFundListing) *model.Divi
 		buyOrderDate = &t
 	}
 
-	return &model.DividendFund{
+	dividendFund := &model.DividendFund{
 		ID:                        d.ID,
 		FundID:                    d.FundID,
 		FundName:                  pf.FundName,
@@ -496,11 +538,15 @@ func dividendToFund(d model.Dividend, pf model.PortfolioFundListing) *model.Divi
 		SharesOwned:               d.SharesOwned,
 		DividendPerShare:          d.DividendPerShare,
 		TotalAmount:               d.TotalAmount,
+		WithholdingTax:            d.WithholdingTax,
+		WithholdingSource:         d.WithholdingSource,
 		ReinvestmentStatus:        d.ReinvestmentStatus,
 		BuyOrderDate:              buyOrderDate,
 		ReinvestmentTransactionID: d.ReinvestmentTransactionID,
 		DividendType:              pf.DividendType,
 	}
+	dividendFund.SetNetAmount()
+	return dividendFund
 }
 
 // applyUpdateFields applies optional field updates from an UpdateDividendRequest onto a Dividend.
@@ -536,6 +582,8 @@ func applyUpdateFields(dividend *model.Dividend, req request.UpdateDividendReque
 // Returns ErrDividendNotFound if the dividend does not exist.
 // Returns an error if any database operation fails; both deletions are rolled back on error.
 func (s *DividendService) DeleteDividend(ctx context.Context, id string) error {
+	ctx, span := tracing.Start(ctx, "DividendService.DeleteDividend")
+	defer span.End()
 	divLog.DebugContext(ctx, "deleting dividend", "dividendID", id)
 
 	dividend, err := s.dividendRepo.GetDividend(id)
@@ -549,6 +597,12 @@ func (s *DividendService) DeleteDividend(ctx context.Context, id string) error {
 	}
 	defer func() { _ = tx.Rollback() }() //nolint:errcheck // Rollback is a no-op after Commit; error is intentionally ignored.
 
+	label := fmt.Sprintf("dividend %g on %s", dividend.TotalAmount, dividend.ExDividendDate.Format("2006-01-02"))
+	specs := dividendTrashSpecs(id, dividend.ReinvestmentTransactionID)
+	if err := moveToTrash(ctx, s.trashRepo.WithTx(tx), model.TrashEntityDividend, id, label, specs); err != nil {
+		return err
+	}
+
 	if err := s.dividendRepo.WithTx(tx).DeleteDividend(ctx, id); err != nil {
 		return fmt.Errorf("failed to delete dividend: %w", err)
 	}
@@ -559,6 +613,10 @@ func (s *DividendService) DeleteDividend(ctx context.Context, id string) error {
 		}
 	}
 
+	if err := recordAudit(ctx, s.auditRepo.WithTx(tx), model.AuditEntityDividend, id, model.AuditActionDelete, dividend, nil); err != nil {
+		return err
+	}
+
 	if err := tx.Commit(); err != nil {
 		return fmt.Errorf("commit transaction: %w", err)
 	}
@@ -566,12 +624,7 @@ func (s *DividendService) DeleteDividend(ctx context.Context, id string) error {
 	divLog.InfoContext(ctx, "dividend deleted", "dividendID", id)
 
 	if s.materializedInvalidator != nil {
-		//nolint:gosec // G118: Background context is intentional — goroutine outlives the HTTP request.
-		go func() {
-			if err := s.materializedInvalidator.RegenerateMaterializedTable(context.Background(), dividend.ExDividendDate, nil, "", dividend.PortfolioFundID); err != nil {
-				divLog.Warn("failed to regenerate materialized table", "error", err)
-			}
-		}()
+		s.materializedInvalidator.ScheduleRegeneration(ctx, dividend.ExDividendDate, nil, "", dividend.PortfolioFundID)
 	}
 
 	return nil
@@ -8,6 +8,9 @@ import (
 
 	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/api/request"
 	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/apperrors"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/model"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/repository"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/service"
 	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/testutil"
 )
 
@@ -20,7 +23,7 @@ func TestDividendService_GetAllDividend(t *testing.T) {
 		db := testutil.SetupTestDB(t)
 		svc := testutil.NewTestDividendService(t, db)
 
-		dividends, err := svc.GetAllDividend()
+		dividends, err := svc.GetAllDividend(context.Background())
 		if err != nil {
 			t.Fatalf("GetAllDividend() error: %v", err)
 		}
@@ -46,7 +49,7 @@ func TestDividendService_GetAllDividend(t *testing.T) {
 			WithRecordDate(time.Date(2025, 2, 17, 0, 0, 0, 0, time.UTC)).
 			Build(t, db)
 
-		dividends, err := svc.GetAllDividend()
+		dividends, err := svc.GetAllDividend(context.Background())
 		if err != nil {
 			t.Fatalf("GetAllDividend() error: %v", err)
 		}
@@ -119,7 +122,7 @@ func TestDividendService_GetDividendFund(t *testing.T) {
 			WithRecordDate(time.Date(2025, 1, 17, 0, 0, 0, 0, time.UTC)).
 			Build(t, db)
 
-		results, err := svc.GetDividendFund(portfolio.ID, "")
+		results, err := svc.GetDividendFund(context.Background(), portfolio.ID, "")
 		if err != nil {
 			t.Fatalf("GetDividendFund() error: %v", err)
 		}
@@ -141,7 +144,7 @@ func TestDividendService_GetDividendFund(t *testing.T) {
 			WithRecordDate(time.Date(2025, 1, 17, 0, 0, 0, 0, time.UTC)).
 			Build(t, db)
 
-		results, err := svc.GetDividendFund("", fund.ID)
+		results, err := svc.GetDividendFund(context.Background(), "", fund.ID)
 		if err != nil {
 			t.Fatalf("GetDividendFund() error: %v", err)
 		}
@@ -159,7 +162,7 @@ func TestDividendService_GetDividendFund(t *testing.T) {
 		fund := testutil.NewFund().Build(t, db)
 		testutil.NewPortfolioFund(portfolio.ID, fund.ID).Build(t, db)
 
-		results, err := svc.GetDividendFund(portfolio.ID, "")
+		results, err := svc.GetDividendFund(context.Background(), portfolio.ID, "")
 		if err != nil {
 			t.Fatalf("GetDividendFund() error: %v", err)
 		}
@@ -391,6 +394,98 @@ func TestDividendService_CreateDividend(t *testing.T) {
 	})
 }
 
+// =============================================================================
+// DividendService withholding tax
+// =============================================================================
+
+func TestDividendService_WithholdingTax(t *testing.T) {
+	setup := func(t *testing.T) (*service.DividendService, model.PortfolioFund) {
+		t.Helper()
+		db := testutil.SetupTestDB(t)
+		svc := testutil.NewTestDividendService(t, db)
+
+		if err := repository.NewWithholdingTaxRepository(db).UpsertWithholdingTaxRate(context.Background(),
+			model.WithholdingTaxRate{Country: "US", RatePercent: 15, UpdatedAt: time.Now().UTC()}); err != nil {
+			t.Fatalf("failed to set withholding tax rate: %v", err)
+		}
+
+		portfolio := testutil.NewPortfolio().Build(t, db)
+		fund := testutil.NewFund().WithISIN("US0378331005").WithDividendType("CASH").Build(t, db)
+		pf := testutil.NewPortfolioFund(portfolio.ID, fund.ID).Build(t, db)
+		testutil.NewTransaction(pf.ID).
+			WithDate(time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC)).
+			WithShares(100).WithCostPerShare(10.0).
+			Build(t, db)
+		return svc, pf
+	}
+
+	t.Run("applies the domicile's default rate", func(t *testing.T) {
+		svc, pf := setup(t)
+
+		div, err := svc.CreateDividend(context.Background(), request.CreateDividendRequest{
+			PortfolioFundID:  pf.ID,
+			RecordDate:       "2025-01-20",
+			ExDividendDate:   "2025-01-18",
+			DividendPerShare: 0.50,
+		})
+		if err != nil {
+			t.Fatalf("CreateDividend() error: %v", err)
+		}
+		if div.WithholdingTax != 7.5 || div.WithholdingSource != model.WithholdingSourceDefault {
+			t.Errorf("expected default withholding 7.5, got %v (%s)", div.WithholdingTax, div.WithholdingSource)
+		}
+		if div.NetAmount != 42.5 || div.WithholdingRate != 15 {
+			t.Errorf("expected net 42.5 at 15%%, got %v at %v%%", div.NetAmount, div.WithholdingRate)
+		}
+	})
+
+	t.Run("keeps a manual amount and recalculates a default one", func(t *testing.T) {
+		svc, pf := setup(t)
+		ctx := context.Background()
+
+		manual := 12.0
+		div, err := svc.CreateDividend(ctx, request.CreateDividendRequest{
+			PortfolioFundID:  pf.ID,
+			RecordDate:       "2025-01-20",
+			ExDividendDate:   "2025-01-18",
+			DividendPerShare: 0.50,
+			WithholdingTax:   &manual,
+		})
+		if err != nil {
+			t.Fatalf("CreateDividend() error: %v", err)
+		}
+		if div.WithholdingTax != 12 || div.WithholdingSource != model.WithholdingSourceManual {
+			t.Errorf("expected manual withholding 12, got %v (%s)", div.WithholdingTax, div.WithholdingSource)
+		}
+
+		newDPS := 1.0
+		updated, err := svc.UpdateDividend(ctx, div.ID, request.UpdateDividendRequest{DividendPerShare: &newDPS})
+		if err != nil {
+			t.Fatalf("UpdateDividend() error: %v", err)
+		}
+		if updated.WithholdingTax != 12 {
+			t.Errorf("expected manual withholding to be kept, got %v", updated.WithholdingTax)
+		}
+
+		other, err := svc.CreateDividend(ctx, request.CreateDividendRequest{
+			PortfolioFundID:  pf.ID,
+			RecordDate:       "2025-04-20",
+			ExDividendDate:   "2025-04-18",
+			DividendPerShare: 0.50,
+		})
+		if err != nil {
+			t.Fatalf("CreateDividend() error: %v", err)
+		}
+		updated, err = svc.UpdateDividend(ctx, other.ID, request.UpdateDividendRequest{DividendPerShare: &newDPS})
+		if err != nil {
+			t.Fatalf("UpdateDividend() error: %v", err)
+		}
+		if updated.WithholdingTax != 15 || updated.WithholdingSource != model.WithholdingSourceDefault {
+			t.Errorf("expected default withholding recalculated to 15, got %v (%s)", updated.WithholdingTax, updated.WithholdingSource)
+		}
+	})
+}
+
 // =============================================================================
 // DividendService.UpdateDividend
 // =============================================================================
@@ -671,7 +766,7 @@ func TestDividendService_LoadDividendPerPF_ViaCreateAndGet(t *testing.T) {
 		}
 
 		// Verify via GetAllDividend
-		all, err := svc.GetAllDividend()
+		all, err := svc.GetAllDividend(context.Background())
 		if err != nil {
 			t.Fatalf("GetAllDividend() error: %v", err)
 		}
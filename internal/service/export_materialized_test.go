@@ -0,0 +1,18 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/model"
+)
+
+// ExportCalculateFundHistoryOnFly exposes calculateFundHistoryOnFly for testing.
+func (s *MaterializedService) ExportCalculateFundHistoryOnFly(portfolioID string, startDate, endDate time.Time) ([]model.FundHistoryResponse, error) {
+	return s.calculateFundHistoryOnFly(context.Background(), portfolioID, startDate, endDate)
+}
+
+// ExportCheckValueDrop exposes checkValueDrop for testing.
+func (s *MaterializedService) ExportCheckValueDrop(ctx context.Context, portfolioID string) {
+	s.checkValueDrop(ctx, portfolioID)
+}
@@ -0,0 +1,14 @@
+package service
+
+// ExportScheduledEntries returns the names of the tasks with an entry on the running scheduler.
+func (s *SchedulerService) ExportScheduledEntries() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	names := make([]string, 0, len(s.entries))
+	for _, task := range s.tasks {
+		if _, ok := s.entries[task.name]; ok {
+			names = append(names, task.name)
+		}
+	}
+	return names
+}
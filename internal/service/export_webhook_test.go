@@ -0,0 +1,8 @@
+package service
+
+import "context"
+
+// ExportDrainDeliveries exposes drainDeliveries for testing.
+func (s *WebhookService) ExportDrainDeliveries(ctx context.Context) {
+	s.drainDeliveries(ctx)
+}
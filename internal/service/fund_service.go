@@ -10,9 +10,11 @@ import (
 	"github.com/google/uuid"
 	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/api/request"
 	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/apperrors"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/auth"
 	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/logging"
 	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/model"
 	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/repository"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/tracing"
 	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/yahoo"
 )
 
@@ -29,7 +31,11 @@ type FundService struct {
 	dataLoaderService       *DataLoaderService
 	portfolioRepo           *repository.PortfolioRepository
 	yahooClient             yahoo.Client
+	auditRepo               *repository.AuditRepository
+	trashRepo               *repository.TrashRepository
 	materializedInvalidator MaterializedInvalidator
+	events                  EventPublisher
+	alerts                  AlertEvaluator
 }
 
 // FundServiceOption is a functional option for configuring a FundService.
@@ -81,7 +87,11 @@ func FundWithYahooClient(c yahoo.Client) FundServiceOption {
 // Only the options relevant to the calling context need to be provided; unset fields remain
 // nil and will panic if the corresponding method is called — a clear wiring error.
 func NewFundService(db *sql.DB, opts ...FundServiceOption) *FundService {
-	s := &FundService{db: db}
+	s := &FundService{
+		db:        db,
+		auditRepo: repository.NewAuditRepository(db),
+		trashRepo: repository.NewTrashRepository(db),
+	}
 	for _, opt := range opts {
 		opt(s)
 	}
@@ -94,6 +104,17 @@ func (s *FundService) SetMaterializedInvalidator(m MaterializedInvalidator) {
 	s.materializedInvalidator = m
 }
 
+// SetEventPublisher injects the EventPublisher that receives failed price update events.
+func (s *FundService) SetEventPublisher(p EventPublisher) {
+	s.events = p
+}
+
+// SetAlertEvaluator injects the AlertEvaluator that re-evaluates fund alert rules after
+// UpdateAllFundHistory stored new prices.
+func (s *FundService) SetAlertEvaluator(e AlertEvaluator) {
+	s.alerts = e
+}
+
 // GetFund retrieves fund from the database.
 // Returns fund metadata including latest prices.
 func (s *FundService) GetFund(fundID string) (model.Fund, error) {
@@ -160,11 +181,11 @@ func (s *FundService) GetSymbol(symbol string) (*model.Symbol, error) {
 }
 
 // GetAllPortfolioFundListings retrieves all portfolio-fund relationships with basic metadata.
-// Returns a listing of funds across all portfolios (non-archived) with portfolio and fund names.
-// Used for the GET /api/portfolio/funds endpoint.
-func (s *FundService) GetAllPortfolioFundListings() ([]model.PortfolioFundListing, error) {
+// Returns a listing of funds across the non-archived portfolios the caller in ctx may read,
+// with portfolio and fund names. Used for the GET /api/portfolio/funds endpoint.
+func (s *FundService) GetAllPortfolioFundListings(ctx context.Context) ([]model.PortfolioFundListing, error) {
 	fundLog.Debug("retrieving all portfolio fund listings")
-	listings, err := s.pfRepo.GetAllPortfolioFundListings()
+	listings, err := s.pfRepo.GetAllPortfolioFundListings(auth.Scope(ctx))
 	if err != nil {
 		return nil, fmt.Errorf("get portfolio fund listings: %w", err)
 	}
@@ -249,6 +270,17 @@ func (s *FundService) LoadFundPrices(fundIDs []string, startDate, endDate time.T
 	return prices, nil
 }
 
+// LoadLatestFundPrices retrieves the most recent price of each of the given funds.
+// Funds without any price are absent from the returned map.
+func (s *FundService) LoadLatestFundPrices(fundIDs []string) (map[string]model.FundPrice, error) {
+	fundLog.Debug("loading latest fund prices", "fundCount", len(fundIDs))
+	prices, err := s.fundRepo.GetLatestFundPrices(fundIDs)
+	if err != nil {
+		return nil, fmt.Errorf("get latest fund prices: %w", err)
+	}
+	return prices, nil
+}
+
 // CheckUsage checks if a fund is currently in use by any portfolios.
 // A fund is considered "in use" if it has portfolio_fund relationships with transactions.
 // This check is critical for data integrity - funds with usage history should not be deleted
@@ -259,20 +291,29 @@ func (s *FundService) LoadFundPrices(fundIDs []string, startDate, endDate time.T
 //   - Portfolios: list of portfolios using the fund with transaction counts
 //   - Empty Portfolios slice means the fund can be safely deleted
 //
+// InUsage covers every portfolio, but only portfolios the caller in ctx may read are named.
+//
 // Use case: Call before deletion to prevent losing historical data.
-func (s *FundService) CheckUsage(fundID string) (model.FundUsage, error) {
+func (s *FundService) CheckUsage(ctx context.Context, fundID string) (model.FundUsage, error) {
 	fundLog.Debug("checking fund usage", "fundID", fundID)
-	checkUsage, err := s.pfRepo.CheckUsage(fundID)
+	checkUsage, err := s.pfRepo.CheckUsage(fundID, model.AllPortfolios())
 	if err != nil {
 		return model.FundUsage{}, fmt.Errorf("check fund usage: %w", err)
 	}
 	var fundUsage model.FundUsage
 	if len(checkUsage) == 0 {
 		fundUsage.InUsage = false
-	} else {
-		fundUsage.InUsage = true
-		fundUsage.Portfolios = checkUsage
+		return fundUsage, nil
+	}
+
+	fundUsage.InUsage = true
+	if scope := auth.Scope(ctx); !scope.All {
+		checkUsage, err = s.pfRepo.CheckUsage(fundID, scope)
+		if err != nil {
+			return model.FundUsage{}, fmt.Errorf("check fund usage: %w", err)
+		}
 	}
+	fundUsage.Portfolios = checkUsage
 
 	return fundUsage, nil
 }
@@ -281,6 +322,8 @@ func (s *FundService) CheckUsage(fundID string) (model.FundUsage, error) {
 // Validates that both the portfolio and fund exist before creating the relationship.
 // This allows a fund to be tracked within a specific portfolio.
 func (s *FundService) CreatePortfolioFund(ctx context.Context, req request.CreatePortfolioFundRequest) error {
+	ctx, span := tracing.Start(ctx, "FundService.CreatePortfolioFund")
+	defer span.End()
 	fundLog.DebugContext(ctx, "creating portfolio fund", "portfolioID", req.PortfolioID, "fundID", req.FundID)
 
 	tx, err := s.db.BeginTx(ctx, nil)
@@ -305,6 +348,15 @@ func (s *FundService) CreatePortfolioFund(ctx context.Context, req request.Creat
 		return fmt.Errorf("failed to create portfolio_fund: %w", err)
 	}
 
+	pf, err := s.pfRepo.WithTx(tx).GetPortfolioFundByPortfolioAndFund(req.PortfolioID, req.FundID)
+	if err != nil {
+		return fmt.Errorf("get created portfolio_fund: %w", err)
+	}
+
+	if err := recordAudit(ctx, s.auditRepo.WithTx(tx), model.AuditEntityPortfolioFund, pf.ID, model.AuditActionCreate, nil, pf); err != nil {
+		return err
+	}
+
 	if err = tx.Commit(); err != nil {
 		return fmt.Errorf("commit transaction: %w", err)
 	}
@@ -316,6 +368,8 @@ func (s *FundService) CreatePortfolioFund(ctx context.Context, req request.Creat
 // Validates that the portfolio-fund relationship exists before deletion.
 // This does not delete the fund itself, only removes it from the portfolio.
 func (s *FundService) DeletePortfolioFund(ctx context.Context, pfID string) error {
+	ctx, span := tracing.Start(ctx, "FundService.DeletePortfolioFund")
+	defer span.End()
 	fundLog.DebugContext(ctx, "deleting portfolio fund", "portfolioFundID", pfID)
 
 	tx, err := s.db.BeginTx(ctx, nil)
@@ -324,7 +378,7 @@ func (s *FundService) DeletePortfolioFund(ctx context.Context, pfID string) erro
 	}
 	defer func() { _ = tx.Rollback() }() //nolint:errcheck // Rollback is a no-op after Commit; error is intentionally ignored.
 
-	_, err = s.pfRepo.WithTx(tx).GetPortfolioFund(pfID)
+	pf, err := s.pfRepo.WithTx(tx).GetPortfolioFund(pfID)
 	if err != nil {
 		return fmt.Errorf("get portfolio fund: %w", err)
 	}
@@ -334,10 +388,20 @@ func (s *FundService) DeletePortfolioFund(ctx context.Context, pfID string) erro
 		return fmt.Errorf("delete portfolio fund: %w", err)
 	}
 
+	if err := recordAudit(ctx, s.auditRepo.WithTx(tx), model.AuditEntityPortfolioFund, pfID, model.AuditActionDelete, pf, nil); err != nil {
+		return err
+	}
+
 	if err = tx.Commit(); err != nil {
 		return fmt.Errorf("commit transaction: %w", err)
 	}
 
+	// The fund's materialized rows cascade away with it; rebuild the portfolio
+	// snapshots so they no longer include it.
+	if s.materializedInvalidator != nil {
+		s.materializedInvalidator.ScheduleRegeneration(ctx, time.Time{}, []string{pf.PortfolioID}, "", "")
+	}
+
 	fundLog.InfoContext(ctx, "portfolio fund deleted", "portfolio_fund_id", pfID)
 	return nil
 }
@@ -355,6 +419,8 @@ func (s *FundService) DeletePortfolioFund(ctx context.Context, pfID string) erro
 //
 // Returns the created fund with its generated ID, or an error if creation fails.
 func (s *FundService) CreateFund(ctx context.Context, req request.CreateFundRequest) (*model.Fund, error) {
+	ctx, span := tracing.Start(ctx, "FundService.CreateFund")
+	defer span.End()
 	fundLog.DebugContext(ctx, "creating fund", "name", req.Name, "symbol", req.Symbol)
 	fund := &model.Fund{
 		ID:             uuid.New().String(),
@@ -367,10 +433,24 @@ func (s *FundService) CreateFund(ctx context.Context, req request.CreateFundRequ
 		DividendType:   req.DividendType,
 	}
 
-	if err := s.fundRepo.InsertFund(ctx, fund); err != nil {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }() //nolint:errcheck // Rollback is a no-op after Commit; error is intentionally ignored.
+
+	if err := s.fundRepo.WithTx(tx).InsertFund(ctx, fund); err != nil {
 		return nil, fmt.Errorf("failed to create fund: %w", err)
 	}
 
+	if err := recordAudit(ctx, s.auditRepo.WithTx(tx), model.AuditEntityFund, fund.ID, model.AuditActionCreate, nil, fund); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit transaction: %w", err)
+	}
+
 	fundLog.InfoContext(ctx, "fund created", "fundID", fund.ID, "symbol", fund.Symbol)
 	return fund, nil
 }
@@ -390,6 +470,8 @@ func (s *FundService) UpdateFund(
 	id string,
 	req request.UpdateFundRequest,
 ) (*model.Fund, error) {
+	ctx, span := tracing.Start(ctx, "FundService.UpdateFund")
+	defer span.End()
 	fundLog.DebugContext(ctx, "updating fund", "fundID", id)
 
 	tx, err := s.db.BeginTx(ctx, nil)
@@ -402,6 +484,7 @@ func (s *FundService) UpdateFund(
 	if err != nil {
 		return nil, fmt.Errorf("get fund: %w", err)
 	}
+	before := fund
 	if req.Name != nil {
 		fund.Name = *req.Name
 	}
@@ -428,6 +511,10 @@ func (s *FundService) UpdateFund(
 		return nil, fmt.Errorf("failed to update fund: %w", err)
 	}
 
+	if err := recordAudit(ctx, s.auditRepo.WithTx(tx), model.AuditEntityFund, id, model.AuditActionUpdate, before, fund); err != nil {
+		return nil, err
+	}
+
 	if err = tx.Commit(); err != nil {
 		return nil, fmt.Errorf("commit transaction: %w", err)
 	}
@@ -450,6 +537,8 @@ func (s *FundService) UpdateFund(
 //   - apperrors.ErrFundInUse if the fund is being used by portfolios
 //   - error if deletion fails
 func (s *FundService) DeleteFund(ctx context.Context, id string) error {
+	ctx, span := tracing.Start(ctx, "FundService.DeleteFund")
+	defer span.End()
 	fundLog.DebugContext(ctx, "deleting fund", "fundID", id)
 
 	tx, err := s.db.BeginTx(ctx, nil)
@@ -458,12 +547,12 @@ func (s *FundService) DeleteFund(ctx context.Context, id string) error {
 	}
 	defer func() { _ = tx.Rollback() }() //nolint:errcheck // Rollback is a no-op after Commit; error is intentionally ignored.
 
-	_, err = s.fundRepo.WithTx(tx).GetFund(id)
+	fund, err := s.fundRepo.WithTx(tx).GetFund(id)
 	if err != nil {
 		return fmt.Errorf("get fund: %w", err)
 	}
 
-	usage, err := s.pfRepo.WithTx(tx).CheckUsage(id)
+	usage, err := s.pfRepo.WithTx(tx).CheckUsage(id, model.AllPortfolios())
 	if err != nil {
 		return fmt.Errorf("failed to check fund usage: %w", err)
 	}
@@ -472,11 +561,23 @@ func (s *FundService) DeleteFund(ctx context.Context, id string) error {
 		return apperrors.ErrFundInUse
 	}
 
+	if err := moveToTrash(ctx, s.trashRepo.WithTx(tx), model.TrashEntityFund, id, fund.Name, fundTrashSpecs(id)); err != nil {
+		return err
+	}
+
+	if err := s.fundRepo.WithTx(tx).DeleteFundPrices(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete fund prices: %w", err)
+	}
+
 	err = s.fundRepo.WithTx(tx).DeleteFund(ctx, id)
 	if err != nil {
 		return fmt.Errorf("failed to delete fund: %w", err)
 	}
 
+	if err := recordAudit(ctx, s.auditRepo.WithTx(tx), model.AuditEntityFund, id, model.AuditActionDelete, fund, nil); err != nil {
+		return err
+	}
+
 	if err = tx.Commit(); err != nil {
 		return fmt.Errorf("commit transaction: %w", err)
 	}
@@ -514,6 +615,8 @@ func (s *FundService) DeleteFund(ctx context.Context, id string) error {
 //
 // Note: This method triggers materialized view regeneration after a successful price insert (Issue #35).
 func (s *FundService) UpdateCurrentFundPrice(ctx context.Context, fundID string) (model.FundPrice, bool, error) {
+	ctx, span := tracing.Start(ctx, "FundService.UpdateCurrentFundPrice")
+	defer span.End()
 	fundLog.DebugContext(ctx, "updating current fund price", "fundID", fundID)
 	fund, err := s.GetFund(fundID)
 	if err != nil {
@@ -560,17 +663,24 @@ func (s *FundService) UpdateCurrentFundPrice(ctx context.Context, fundID string)
 		Price:  indicator.PriceClose,
 	}
 
-	if err = s.fundRepo.InsertFundPrice(ctx, fundPrice); err != nil {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return model.FundPrice{}, false, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }() //nolint:errcheck // Rollback is a no-op after Commit; error is intentionally ignored.
+
+	if err = s.fundRepo.WithTx(tx).InsertFundPrice(ctx, fundPrice); err != nil {
 		return model.FundPrice{}, false, fmt.Errorf("insert fund price: %w", err)
 	}
+	if err := recordAudit(ctx, s.auditRepo.WithTx(tx), model.AuditEntityFundPrice, fund.ID, model.AuditActionCreate, nil, fundPrice); err != nil {
+		return model.FundPrice{}, false, err
+	}
+	if err := tx.Commit(); err != nil {
+		return model.FundPrice{}, false, fmt.Errorf("commit transaction: %w", err)
+	}
 
 	if s.materializedInvalidator != nil {
-		//nolint:gosec // G118: Background context is intentional — goroutine outlives the HTTP request.
-		go func() {
-			if err := s.materializedInvalidator.RegenerateMaterializedTable(context.Background(), fundPrice.Date, nil, fundPrice.FundID, ""); err != nil {
-				fundLog.Warn("failed to regenerate materialized table after price update", "error", err)
-			}
-		}()
+		s.materializedInvalidator.ScheduleRegeneration(ctx, fundPrice.Date, nil, fundPrice.FundID, "")
 	}
 
 	fundLog.InfoContext(ctx, "fund price updated", "fundID", fundID, "date", fundPrice.Date.Format("2006-01-02"), "price", fundPrice.Price)
@@ -689,6 +799,8 @@ func (s *FundService) filterMissingPrices(indicators []yahoo.Indicators, missing
 //
 //nolint:gocyclo,funlen // Multi-step pipeline: validate, load, diff, fetch, filter, insert, invalidate
 func (s *FundService) UpdateHistoricalFundPrice(ctx context.Context, fundID string) (int, error) {
+	ctx, span := tracing.Start(ctx, "FundService.UpdateHistoricalFundPrice")
+	defer span.End()
 	fundLog.DebugContext(ctx, "updating historical fund prices", "fundID", fundID)
 	fund, err := s.GetFund(fundID)
 	if err != nil {
@@ -753,6 +865,9 @@ func (s *FundService) UpdateHistoricalFundPrice(ctx context.Context, fundID stri
 	if err != nil {
 		return 0, fmt.Errorf("insert fund prices: %w", err)
 	}
+	if err := recordAudit(ctx, s.auditRepo.WithTx(tx), model.AuditEntityFundPrice, fundID, model.AuditActionImport, nil, missingFundPrices); err != nil {
+		return 0, err
+	}
 
 	if err := tx.Commit(); err != nil {
 		return 0, fmt.Errorf("commit transaction: %w", err)
@@ -762,12 +877,7 @@ func (s *FundService) UpdateHistoricalFundPrice(ctx context.Context, fundID stri
 		return a.Date.Compare(b.Date)
 	})
 	if s.materializedInvalidator != nil {
-		//nolint:gosec // G118: Background context is intentional — goroutine outlives the HTTP request.
-		go func() {
-			if err := s.materializedInvalidator.RegenerateMaterializedTable(context.Background(), oldestPrice.Date, nil, oldestPrice.FundID, ""); err != nil {
-				fundLog.Warn("failed to regenerate materialized table after historical price update", "error", err)
-			}
-		}()
+		s.materializedInvalidator.ScheduleRegeneration(ctx, oldestPrice.Date, nil, oldestPrice.FundID, "")
 	}
 
 	fundLog.InfoContext(ctx, "historical fund prices updated", "fundID", fundID, "pricesAdded", len(missingFundPrices))
@@ -792,6 +902,8 @@ func (s *FundService) UpdateHistoricalFundPrice(ctx context.Context, fundID stri
 // The response always includes detailed information about which funds succeeded or failed,
 // regardless of whether an error is returned.
 func (s *FundService) UpdateAllFundHistory(ctx context.Context) (model.AllFundUpdateResponse, error) {
+	ctx, span := tracing.Start(ctx, "FundService.UpdateAllFundHistory")
+	defer span.End()
 	fundLog.DebugContext(ctx, "updating all fund history")
 	funds, err := s.GetAllFunds()
 	if err != nil {
@@ -805,8 +917,9 @@ func (s *FundService) UpdateAllFundHistory(ctx context.Context) (model.AllFundUp
 	var errors []model.UpdatedFundError
 	var fundResults []model.UpdatedFund
 
-	for _, f := range funds {
+	for i, f := range funds {
 		result, err := s.UpdateHistoricalFundPrice(ctx, f.ID)
+		reportJobProgress(ctx, i+1, len(funds))
 		if err != nil {
 			fundPriceError := model.UpdatedFundError{
 				FundID: f.ID,
@@ -833,11 +946,25 @@ func (s *FundService) UpdateAllFundHistory(ctx context.Context) (model.AllFundUp
 	fundUpdate.TotalErrors = len(errors)
 	fundUpdate.TotalUpdated = len(fundResults)
 
+	if len(errors) > 0 {
+		publishEvent(ctx, s.events, model.WebhookEventPriceUpdateFailed, "", map[string]any{
+			"totalUpdated": fundUpdate.TotalUpdated,
+			"totalErrors":  fundUpdate.TotalErrors,
+			"errors":       errors,
+		})
+	}
+
 	if len(fundResults) == 0 && len(errors) > 0 {
 		fundUpdate.Success = false
 		return fundUpdate, fmt.Errorf("failed to update any funds: %d errors occurred", len(errors))
 	}
 
+	if s.alerts != nil {
+		if _, err := s.alerts.EvaluateFundAlerts(ctx); err != nil {
+			fundLog.WarnContext(ctx, "failed to evaluate fund alerts", "error", err)
+		}
+	}
+
 	fundUpdate.Success = true
 	if len(errors) > 0 {
 		fundLog.Warn("fund history update completed with errors", "updated", len(fundResults), "errors", len(errors))
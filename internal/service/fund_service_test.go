@@ -151,7 +151,7 @@ func TestFundService_GetAllPortfolioFundListings(t *testing.T) {
 		db := testutil.SetupTestDB(t)
 		svc := testutil.NewTestFundService(t, db)
 
-		listings, err := svc.GetAllPortfolioFundListings()
+		listings, err := svc.GetAllPortfolioFundListings(context.Background())
 		if err != nil {
 			t.Fatalf("GetAllPortfolioFundListings() error: %v", err)
 		}
@@ -168,7 +168,7 @@ func TestFundService_GetAllPortfolioFundListings(t *testing.T) {
 		fund := testutil.NewFund().Build(t, db)
 		testutil.NewPortfolioFund(portfolio.ID, fund.ID).Build(t, db)
 
-		listings, err := svc.GetAllPortfolioFundListings()
+		listings, err := svc.GetAllPortfolioFundListings(context.Background())
 		if err != nil {
 			t.Fatalf("GetAllPortfolioFundListings() error: %v", err)
 		}
@@ -193,7 +193,7 @@ func TestFundService_CheckUsage(t *testing.T) {
 
 		// CheckUsage returns portfolio rows via GROUP BY even with 0 transactions,
 		// so the fund is considered "in use" when it has a portfolio_fund link.
-		usage, err := svc.CheckUsage(fund.ID)
+		usage, err := svc.CheckUsage(context.Background(), fund.ID)
 		if err != nil {
 			t.Fatalf("CheckUsage() error: %v", err)
 		}
@@ -211,7 +211,7 @@ func TestFundService_CheckUsage(t *testing.T) {
 		pf := testutil.NewPortfolioFund(portfolio.ID, fund.ID).Build(t, db)
 		testutil.NewTransaction(pf.ID).Build(t, db)
 
-		usage, err := svc.CheckUsage(fund.ID)
+		usage, err := svc.CheckUsage(context.Background(), fund.ID)
 		if err != nil {
 			t.Fatalf("CheckUsage() error: %v", err)
 		}
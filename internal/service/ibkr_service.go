@@ -2,12 +2,16 @@ package service
 
 import (
 	"context"
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
 	"encoding/json"
 	"encoding/xml"
 	"errors"
 	"fmt"
 	"math"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
@@ -19,6 +23,7 @@ import (
 	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/logging"
 	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/model"
 	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/repository"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/tracing"
 )
 
 var ibkrLog = logging.NewLogger("ibkr")
@@ -34,8 +39,11 @@ type IbkrService struct {
 	pfRepo                  *repository.PortfolioFundRepository
 	transactionRepo         *repository.TransactionRepository
 	dividendRepo            *repository.DividendRepository
-	encryptionKey           *fernet.Key
+	withholdingRepo         *repository.WithholdingTaxRepository
+	encryptionKeys          []*fernet.Key
+	auditRepo               *repository.AuditRepository
 	materializedInvalidator MaterializedInvalidator
+	events                  EventPublisher
 }
 
 // IbkrServiceOption is a functional option for configuring an IbkrService.
@@ -81,16 +89,32 @@ func IbkrWithDividendRepo(r *repository.DividendRepository) IbkrServiceOption {
 	return func(s *IbkrService) { s.dividendRepo = r }
 }
 
-// IbkrWithEncryptionKey injects the pre-decoded Fernet encryption key.
+// IbkrWithWithholdingTaxRepo injects the WithholdingTaxRepository dependency.
+func IbkrWithWithholdingTaxRepo(r *repository.WithholdingTaxRepository) IbkrServiceOption {
+	return func(s *IbkrService) { s.withholdingRepo = r }
+}
+
+// IbkrWithEncryptionKey injects a single pre-decoded Fernet encryption key.
 func IbkrWithEncryptionKey(key *fernet.Key) IbkrServiceOption {
-	return func(s *IbkrService) { s.encryptionKey = key }
+	return func(s *IbkrService) {
+		s.encryptionKeys = nil
+		if key != nil {
+			s.encryptionKeys = []*fernet.Key{key}
+		}
+	}
+}
+
+// IbkrWithEncryptionKeys injects the pre-decoded Fernet encryption keys, newest first.
+// Tokens are encrypted with the newest key and decrypted with any of them.
+func IbkrWithEncryptionKeys(keys []*fernet.Key) IbkrServiceOption {
+	return func(s *IbkrService) { s.encryptionKeys = keys }
 }
 
 // NewIbkrService creates a new IbkrService. Pass IbkrWith* options to inject dependencies.
 // Only the options relevant to the calling context need to be provided; unset fields remain
 // nil and will panic if the corresponding method is called — a clear wiring error.
 func NewIbkrService(db *sql.DB, opts ...IbkrServiceOption) *IbkrService {
-	s := &IbkrService{db: db}
+	s := &IbkrService{db: db, auditRepo: repository.NewAuditRepository(db)}
 	for _, opt := range opts {
 		opt(s)
 	}
@@ -103,6 +127,11 @@ func (s *IbkrService) SetMaterializedInvalidator(m MaterializedInvalidator) {
 	s.materializedInvalidator = m
 }
 
+// SetEventPublisher injects the EventPublisher that receives import and token events.
+func (s *IbkrService) SetEventPublisher(p EventPublisher) {
+	s.events = p
+}
+
 // GetIbkrConfig retrieves the IBKR integration configuration.
 // Adds a token expiration warning if the token expires within 30 days.
 func (s *IbkrService) GetIbkrConfig() (*model.IbkrConfig, error) {
@@ -131,12 +160,14 @@ func (s *IbkrService) GetIbkrConfig() (*model.IbkrConfig, error) {
 }
 
 // GetActivePortfolios retrieves all active portfolios that can be used for IBKR import allocation.
-// Returns portfolios that are not archived and not excluded from tracking.
+// Returns portfolios that are not archived and not excluded from tracking. The IBKR
+// endpoints are limited to administrators, who may allocate to every portfolio.
 func (s *IbkrService) GetActivePortfolios() ([]model.Portfolio, error) {
 	ibkrLog.Debug("retrieving active portfolios for ibkr")
 	portfolios, err := s.portfolioRepo.GetPortfolios(model.PortfolioFilter{
 		IncludeArchived: false,
 		IncludeExcluded: false,
+		Scope:           model.AllPortfolios(),
 	})
 	if err != nil {
 		return nil, fmt.Errorf("get active portfolios: %w", err)
@@ -158,10 +189,14 @@ func (s *IbkrService) GetPendingDividends(symbol, isin string) ([]model.PendingD
 
 // GetInbox retrieves IBKR imported transactions from the inbox.
 // Returns transactions filtered by status (defaults to "pending") and optionally by transaction type.
-// Used to display imported IBKR transactions that need to be allocated to portfolios.
+// This is the IBKR-filtered view of the shared inbox; see InboxService for all sources.
 func (s *IbkrService) GetInbox(status, transactionType string) ([]model.IBKRTransaction, error) {
 	ibkrLog.Debug("retrieving inbox", "status", status, "transactionType", transactionType)
-	inbox, err := s.ibkrRepo.GetInbox(status, transactionType)
+	inbox, err := s.ibkrRepo.GetInbox(model.InboxFilter{
+		Source:          string(model.InboxSourceIBKR),
+		Status:          status,
+		TransactionType: transactionType,
+	})
 	if err != nil {
 		return nil, fmt.Errorf("get inbox: %w", err)
 	}
@@ -172,7 +207,7 @@ func (s *IbkrService) GetInbox(status, transactionType string) ([]model.IBKRTran
 // Returns only the count without fetching full transaction records for efficiency.
 func (s *IbkrService) GetInboxCount() (model.IBKRInboxCount, error) {
 	ibkrLog.Debug("retrieving inbox count")
-	count, err := s.ibkrRepo.GetIbkrInboxCount()
+	count, err := s.ibkrRepo.GetIbkrInboxCount(string(model.InboxSourceIBKR))
 	if err != nil {
 		return model.IBKRInboxCount{}, fmt.Errorf("get inbox count: %w", err)
 	}
@@ -314,6 +349,8 @@ func (s *IbkrService) GetEligiblePortfolios(transactionID string) (model.IBKREli
 //
 //nolint:gocyclo // Primary Flex Report Import orchestrator. Mostly filled with error handling.
 func (s *IbkrService) ImportFlexReport(ctx context.Context) (int, int, error) {
+	ctx, span := tracing.Start(ctx, "IbkrService.ImportFlexReport")
+	defer span.End()
 	ibkrLog.DebugContext(ctx, "starting flex report import")
 
 	config, err := s.GetIbkrConfig()
@@ -368,6 +405,56 @@ func (s *IbkrService) ImportFlexReport(ctx context.Context) (int, int, error) {
 		}
 	}
 
+	imported, skipped, err := s.importFlexStatement(ctx, req)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if err := s.ibkrRepo.UpdateLastImportDate(ctx, config.FlexQueryID, now); err != nil {
+		return 0, 0, fmt.Errorf("ImportFlexReport: failed to update last_import_date: %w", err)
+	}
+
+	s.publishImportEvents(ctx, config, imported, skipped, now)
+	return imported, skipped, nil
+}
+
+// publishImportEvents reports a finished Flex report import, any new inbox items, and a
+// token that is about to expire. The token warning is published at most once a day.
+func (s *IbkrService) publishImportEvents(ctx context.Context, config *model.IbkrConfig, imported, skipped int, now time.Time) {
+	publishEvent(ctx, s.events, model.WebhookEventIbkrImportCompleted, "", map[string]int{
+		"imported": imported,
+		"skipped":  skipped,
+	})
+	if imported > 0 {
+		publishEvent(ctx, s.events, model.WebhookEventIbkrInboxItems, "", map[string]int{"count": imported})
+	}
+	if config.TokenWarning != "" {
+		publishEvent(ctx, s.events, model.WebhookEventIbkrTokenExpiring, now.Format("2006-01-02"), map[string]any{
+			"expiresAt": config.TokenExpiresAt,
+			"warning":   config.TokenWarning,
+		})
+	}
+}
+
+// ImportFlexFile processes a Flex statement exported from IBKR as XML, without calling the
+// IBKR API or touching the import cache and last import date. Transactions already in the
+// inbox are skipped, exactly as in ImportFlexReport.
+// Returns the number of imported and skipped transactions.
+func (s *IbkrService) ImportFlexFile(ctx context.Context, content []byte) (int, int, error) {
+	ctx, span := tracing.Start(ctx, "IbkrService.ImportFlexFile")
+	defer span.End()
+	ibkrLog.DebugContext(ctx, "starting flex file import", "bytes", len(content))
+
+	var req ibkr.FlexQueryResponse
+	if err := xml.Unmarshal(content, &req); err != nil {
+		return 0, 0, fmt.Errorf("unmarshal flex report: %w", err)
+	}
+	return s.importFlexStatement(ctx, req)
+}
+
+// importFlexStatement parses a Flex statement and adds the transactions not yet in the inbox,
+// along with the statement's exchange rates.
+func (s *IbkrService) importFlexStatement(ctx context.Context, req ibkr.FlexQueryResponse) (int, int, error) {
 	report, rates, err := s.parseIBKRFlexReport(req)
 	if err != nil {
 		return 0, 0, fmt.Errorf("parse flex report: %w", err)
@@ -393,21 +480,21 @@ func (s *IbkrService) ImportFlexReport(ctx context.Context) (int, int, error) {
 		}
 	}
 
-	if err := s.ibkrRepo.UpdateLastImportDate(ctx, config.FlexQueryID, now); err != nil {
-		return 0, 0, fmt.Errorf("ImportFlexReport: failed to update last_import_date: %w", err)
+	if err := s.importWithholdingTax(ctx, req); err != nil {
+		return 0, 0, fmt.Errorf("import withholding tax: %w", err)
 	}
 
 	ibkrLog.InfoContext(ctx, "flex report import completed", "imported", len(missingTransactions), "skipped", len(report)-len(missingTransactions), "exchangeRates", len(rates))
 	return len(missingTransactions), len(report) - len(missingTransactions), nil
 }
 
-// decryptToken decrypts a fernet-encrypted IBKR flex token using the injected encryption key.
-// Returns an error if the key is nil or decryption fails.
+// decryptToken decrypts a fernet-encrypted IBKR flex token with any of the injected keys.
+// Returns an error if no key is set or decryption fails.
 func (s *IbkrService) decryptToken(token string) (string, error) {
-	if s.encryptionKey == nil {
+	if len(s.encryptionKeys) == 0 {
 		return "", fmt.Errorf("IBKR_ENCRYPTION_KEY not set")
 	}
-	decryptedToken := fernet.VerifyAndDecrypt([]byte(token), 0, []*fernet.Key{s.encryptionKey})
+	decryptedToken := fernet.VerifyAndDecrypt([]byte(token), 0, s.encryptionKeys)
 	if decryptedToken == nil {
 		return "", fmt.Errorf("decryption failed")
 	}
@@ -415,13 +502,13 @@ func (s *IbkrService) decryptToken(token string) (string, error) {
 	return strings.TrimSpace(string(decryptedToken)), nil
 }
 
-// encryptToken encrypts a plaintext IBKR flex token using the injected Fernet key.
-// Returns an error if the key is nil or encryption fails.
+// encryptToken encrypts a plaintext IBKR flex token with the newest injected Fernet key.
+// Returns an error if no key is set or encryption fails.
 func (s *IbkrService) encryptToken(token string) (string, error) {
-	if s.encryptionKey == nil {
+	if len(s.encryptionKeys) == 0 {
 		return "", fmt.Errorf("IBKR_ENCRYPTION_KEY not set")
 	}
-	encryptedToken, err := fernet.EncryptAndSign([]byte(token), s.encryptionKey)
+	encryptedToken, err := fernet.EncryptAndSign([]byte(token), s.encryptionKeys[0])
 	if err != nil {
 		return "", fmt.Errorf("encrypt token: %w", err)
 	}
@@ -432,6 +519,91 @@ func (s *IbkrService) encryptToken(token string) (string, error) {
 	return strings.TrimSpace(string(encryptedToken)), nil
 }
 
+// encryptionKeyID returns a short fingerprint that identifies a key without revealing it.
+func encryptionKeyID(key *fernet.Key) string {
+	sum := sha256.Sum256(key[:])
+	return hex.EncodeToString(sum[:6])
+}
+
+// ibkrKeyAuditState is the audit snapshot of a key rotation: which key the flex token is
+// encrypted with.
+type ibkrKeyAuditState struct {
+	KeyID string `json:"keyId"`
+}
+
+// RotateEncryptionKey re-encrypts the stored flex token with the newest configured key, so
+// older keys can be dropped from the configuration afterwards. The token is decrypted with
+// whichever configured key still reads it. The rotation is recorded in the audit log with
+// the fingerprints of the old and new key; nothing is changed or audited when no token is
+// stored.
+func (s *IbkrService) RotateEncryptionKey(ctx context.Context) (model.IbkrKeyRotation, error) {
+	ctx, span := tracing.Start(ctx, "IbkrService.RotateEncryptionKey")
+	defer span.End()
+	ibkrLog.DebugContext(ctx, "rotating encryption key", "configuredKeys", len(s.encryptionKeys))
+
+	if len(s.encryptionKeys) == 0 {
+		return model.IbkrKeyRotation{}, fmt.Errorf("IBKR_ENCRYPTION_KEY not set")
+	}
+	result := model.IbkrKeyRotation{
+		KeyID:          encryptionKeyID(s.encryptionKeys[0]),
+		ConfiguredKeys: len(s.encryptionKeys),
+		RotatedAt:      time.Now().UTC(),
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return model.IbkrKeyRotation{}, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }() //nolint:errcheck // Rollback is a no-op after Commit; error is intentionally ignored.
+
+	config, err := s.ibkrRepo.WithTx(tx).GetIbkrConfig()
+	if err != nil && !errors.Is(err, apperrors.ErrIbkrConfigNotFound) {
+		return model.IbkrKeyRotation{}, fmt.Errorf("get ibkr config: %w", err)
+	}
+	if err != nil || config.FlexToken == "" {
+		ibkrLog.InfoContext(ctx, "encryption key rotation skipped, no flex token stored")
+		return result, nil
+	}
+
+	for _, key := range s.encryptionKeys {
+		if fernet.VerifyAndDecrypt([]byte(config.FlexToken), 0, []*fernet.Key{key}) != nil {
+			result.PreviousKeyID = encryptionKeyID(key)
+			break
+		}
+	}
+	if result.PreviousKeyID == "" {
+		return model.IbkrKeyRotation{}, fmt.Errorf("decrypt token: no configured key decrypts the stored flex token")
+	}
+
+	token, err := s.decryptToken(config.FlexToken)
+	if err != nil {
+		return model.IbkrKeyRotation{}, fmt.Errorf("decrypt token: %w", err)
+	}
+	config.FlexToken, err = s.encryptToken(token)
+	if err != nil {
+		return model.IbkrKeyRotation{}, err
+	}
+	config.UpdatedAt = result.RotatedAt
+
+	if err := s.ibkrRepo.WithTx(tx).UpdateIbkrConfig(ctx, false, config); err != nil {
+		return model.IbkrKeyRotation{}, fmt.Errorf("failed to update IBKR config: %w", err)
+	}
+
+	before := ibkrKeyAuditState{KeyID: result.PreviousKeyID}
+	after := ibkrKeyAuditState{KeyID: result.KeyID}
+	if err := recordAudit(ctx, s.auditRepo.WithTx(tx), model.AuditEntityIbkrConfig, config.ID, model.AuditActionRotateKey, before, after); err != nil {
+		return model.IbkrKeyRotation{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return model.IbkrKeyRotation{}, fmt.Errorf("commit transaction: %w", err)
+	}
+
+	result.Reencrypted = true
+	ibkrLog.InfoContext(ctx, "encryption key rotated", "previousKeyId", result.PreviousKeyID, "keyId", result.KeyID)
+	return result, nil
+}
+
 // writeImportCache persists a Flex report cache entry to the database within a transaction.
 // The caller is responsible for populating all fields on importCache before calling.
 func (s *IbkrService) writeImportCache(ctx context.Context, importCache model.IbkrImportCache) error {
@@ -478,6 +650,8 @@ func (s *IbkrService) addExchangeRates(ctx context.Context, rates []model.Exchan
 // AddIbkrTransactions persists a slice of IBKR transactions to the database within a single transaction.
 // Returns an error if the transaction cannot be started or if any insert fails.
 func (s *IbkrService) AddIbkrTransactions(ctx context.Context, transactions []model.IBKRTransaction) error {
+	ctx, span := tracing.Start(ctx, "IbkrService.AddIbkrTransactions")
+	defer span.End()
 	ibkrLog.DebugContext(ctx, "adding ibkr transactions", "count", len(transactions))
 	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
@@ -489,6 +663,12 @@ func (s *IbkrService) AddIbkrTransactions(ctx context.Context, transactions []mo
 		return fmt.Errorf("add ibkr transactions: %w", err)
 	}
 
+	for _, t := range transactions {
+		if err := recordAudit(ctx, s.auditRepo.WithTx(tx), model.AuditEntityIbkrTransaction, t.ID, model.AuditActionCreate, nil, t); err != nil {
+			return err
+		}
+	}
+
 	if err := tx.Commit(); err != nil {
 		return fmt.Errorf("commit transaction: %w", err)
 	}
@@ -526,6 +706,7 @@ func (s *IbkrService) parseIBKRFlexReport(report ibkr.FlexQueryResponse) ([]mode
 
 		t := model.IBKRTransaction{
 			ID:                uuid.New().String(),
+			Source:            string(model.InboxSourceIBKR),
 			IBKRTransactionID: fmt.Sprintf("%d_%d", v.TransactionID, v.IbOrderID),
 			TransactionDate:   transactionDate,
 			Symbol:            v.Symbol,
@@ -566,6 +747,169 @@ func (s *IbkrService) parseIBKRFlexReport(report ibkr.FlexQueryResponse) ([]mode
 	return ibkrTransactions, ibkrExchangeRate, nil
 }
 
+// ibkrWithholdingTaxType is the CashTransaction type of tax withheld on dividends.
+const ibkrWithholdingTaxType = "Withholding Tax"
+
+// ibkrWithholdingMatchDays is how many days before a withholding tax payment the matching
+// dividend's ex-dividend date may be, when IBKR does not report the ex-dividend date.
+const ibkrWithholdingMatchDays = 60
+
+// parseIBKRWithholdingTax extracts the "Withholding Tax" cash transactions of a Flex report.
+// IBKR reports withheld tax as a negative amount; it is stored as positive.
+func parseIBKRWithholdingTax(report ibkr.FlexQueryResponse) ([]model.IBKRWithholdingTax, error) {
+	items := []model.IBKRWithholdingTax{}
+	for _, v := range report.FlexStatements.FlexStatement.CashTransactions.CashTransaction {
+		if v.Type != ibkrWithholdingTaxType {
+			continue
+		}
+
+		dateStr, _, _ := strings.Cut(v.DateTime, ";")
+		if dateStr == "" {
+			dateStr = v.ReportDate
+		}
+		paymentDate, err := time.Parse("20060102", dateStr)
+		if err != nil {
+			return nil, fmt.Errorf("parse date for withholding tax %d: %w", v.TransactionID, err)
+		}
+
+		var exDate *time.Time
+		if v.ExDate != "" {
+			d, err := time.Parse("20060102", v.ExDate)
+			if err != nil {
+				return nil, fmt.Errorf("parse ex-date for withholding tax %d: %w", v.TransactionID, err)
+			}
+			exDate = &d
+		}
+
+		items = append(items, model.IBKRWithholdingTax{
+			ID:                uuid.New().String(),
+			IBKRTransactionID: strconv.FormatInt(v.TransactionID, 10),
+			ISIN:              v.Isin,
+			Symbol:            v.Symbol,
+			Currency:          v.Currency,
+			Amount:            -v.Amount,
+			PaymentDate:       paymentDate,
+			ExDividendDate:    exDate,
+			Description:       v.Description,
+			ImportedAt:        report.ImportedAt,
+		})
+	}
+	return items, nil
+}
+
+// importWithholdingTax stores the statement's withholding tax cash transactions and applies
+// every unapplied one to its dividends. Withholding tax whose dividend has not been recorded
+// yet stays unapplied and is retried on the next import.
+func (s *IbkrService) importWithholdingTax(ctx context.Context, report ibkr.FlexQueryResponse) error {
+	items, err := parseIBKRWithholdingTax(report)
+	if err != nil {
+		return err
+	}
+
+	added, err := s.withholdingRepo.AddIBKRWithholdingTax(ctx, items)
+	if err != nil {
+		return err
+	}
+
+	pending, err := s.withholdingRepo.GetUnappliedIBKRWithholdingTax()
+	if err != nil {
+		return err
+	}
+
+	applied := 0
+	for _, item := range pending {
+		ok, err := s.applyIBKRWithholdingTax(ctx, item)
+		if err != nil {
+			return fmt.Errorf("apply withholding tax %s: %w", item.IBKRTransactionID, err)
+		}
+		if ok {
+			applied++
+		}
+	}
+
+	if added > 0 || applied > 0 {
+		ibkrLog.InfoContext(ctx, "withholding tax imported", "added", added, "applied", applied, "unapplied", len(pending)-applied)
+	}
+	return nil
+}
+
+// applyIBKRWithholdingTax applies one IBKR withholding tax amount to the dividends it was
+// withheld from: the fund's dividends on the reported ex-dividend date, or else those with the
+// latest ex-dividend date in the ibkrWithholdingMatchDays before payment. The amount is split
+// over the matching dividends by gross amount. It replaces default and manual withholding tax
+// and adds to earlier IBKR amounts, so corrections and refunds net out.
+// Returns false when no dividend matches yet.
+func (s *IbkrService) applyIBKRWithholdingTax(ctx context.Context, item model.IBKRWithholdingTax) (bool, error) {
+	fund, err := s.findFundByISINOrSymbol(item.ISIN, item.Symbol)
+	if errors.Is(err, apperrors.ErrIBKRFundNotMatched) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if fund.Currency != item.Currency {
+		ibkrLog.WarnContext(ctx, "withholding tax currency differs from fund currency", "transactionID", item.IBKRTransactionID, "currency", item.Currency, "fundCurrency", fund.Currency)
+		return false, nil
+	}
+
+	start, end := item.PaymentDate.AddDate(0, 0, -ibkrWithholdingMatchDays), item.PaymentDate
+	if item.ExDividendDate != nil {
+		start, end = *item.ExDividendDate, *item.ExDividendDate
+	}
+	dividends, err := s.dividendRepo.GetFundDividends(fund.ID, start, end)
+	if err != nil {
+		return false, fmt.Errorf("get fund dividends: %w", err)
+	}
+	if len(dividends) == 0 {
+		return false, nil
+	}
+	latest := dividends[len(dividends)-1].ExDividendDate.Format("2006-01-02")
+	dividends = slices.DeleteFunc(dividends, func(d model.Dividend) bool {
+		return d.ExDividendDate.Format("2006-01-02") != latest
+	})
+
+	gross := 0.0
+	for _, d := range dividends {
+		gross += d.TotalAmount
+	}
+
+	dbTx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = dbTx.Rollback() }() //nolint:errcheck
+
+	for _, d := range dividends {
+		share := item.Amount / float64(len(dividends))
+		if gross > 0 {
+			share = item.Amount * d.TotalAmount / gross
+		}
+
+		before := d
+		if d.WithholdingSource != model.WithholdingSourceIBKR {
+			d.WithholdingTax = 0
+		}
+		d.WithholdingTax = round(d.WithholdingTax + share)
+		d.WithholdingSource = model.WithholdingSourceIBKR
+
+		if err := s.dividendRepo.WithTx(dbTx).UpdateDividend(ctx, &d); err != nil {
+			return false, fmt.Errorf("failed to update dividend %s: %w", d.ID, err)
+		}
+		if err := recordAudit(ctx, s.auditRepo.WithTx(dbTx), model.AuditEntityDividend, d.ID, model.AuditActionUpdate, before, d); err != nil {
+			return false, err
+		}
+	}
+
+	if err := s.withholdingRepo.WithTx(dbTx).MarkIBKRWithholdingTaxApplied(ctx, item.ID, time.Now().UTC()); err != nil {
+		return false, err
+	}
+
+	if err := dbTx.Commit(); err != nil {
+		return false, fmt.Errorf("commit transaction: %w", err)
+	}
+	return true, nil
+}
+
 // UpdateIbkrConfig applies a partial update to the IBKR configuration.
 // Only non-nil fields in the request are applied; omitted fields retain their current values.
 // FlexToken is an exception: passing an empty string also means "no change" — only a non-empty
@@ -582,6 +926,8 @@ func (s *IbkrService) UpdateIbkrConfig(
 	ctx context.Context,
 	req request.UpdateIbkrConfigRequest,
 ) (*model.IbkrConfig, error) {
+	ctx, span := tracing.Start(ctx, "IbkrService.UpdateIbkrConfig")
+	defer span.End()
 	ibkrLog.DebugContext(ctx, "updating ibkr config")
 
 	var config *model.IbkrConfig
@@ -599,6 +945,16 @@ func (s *IbkrService) UpdateIbkrConfig(
 		config.ID = uuid.New().String()
 		config.CreatedAt = time.Now().UTC()
 	}
+
+	// The flex token is excluded from the audit snapshots by its json:"-" tag.
+	var before *model.IbkrConfig
+	auditAction := model.AuditActionCreate
+	if err == nil {
+		snapshot := *config
+		before = &snapshot
+		auditAction = model.AuditActionUpdate
+	}
+
 	var overwriteConfig bool
 
 	if (req.FlexQueryID != nil && *req.FlexQueryID != config.FlexQueryID) && (req.Enabled != nil && *req.Enabled) {
@@ -622,6 +978,10 @@ func (s *IbkrService) UpdateIbkrConfig(
 			return nil, fmt.Errorf("failed to update IBKR config: %w", err)
 		}
 
+		if err := recordAudit(ctx, s.auditRepo.WithTx(tx), model.AuditEntityIbkrConfig, config.ID, auditAction, before, config); err != nil {
+			return nil, err
+		}
+
 		if err := tx.Commit(); err != nil {
 			return nil, fmt.Errorf("commit transaction: %w", err)
 		}
@@ -670,6 +1030,10 @@ func (s *IbkrService) UpdateIbkrConfig(
 		return nil, fmt.Errorf("failed to update IBKR config: %w", err)
 	}
 
+	if err := recordAudit(ctx, s.auditRepo.WithTx(tx), model.AuditEntityIbkrConfig, config.ID, auditAction, before, config); err != nil {
+		return nil, err
+	}
+
 	if err := tx.Commit(); err != nil {
 		return nil, fmt.Errorf("commit transaction: %w", err)
 	}
@@ -683,12 +1047,33 @@ func (s *IbkrService) UpdateIbkrConfig(
 // DeleteIbkrConfig removes the IBKR configuration from the database.
 // Returns ErrIbkrConfigNotFound (propagated from the repository) if no config exists.
 func (s *IbkrService) DeleteIbkrConfig(ctx context.Context) error {
+	ctx, span := tracing.Start(ctx, "IbkrService.DeleteIbkrConfig")
+	defer span.End()
 	ibkrLog.DebugContext(ctx, "deleting ibkr config")
-	err := s.ibkrRepo.DeleteIbkrConfig(ctx)
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }() //nolint:errcheck // Rollback is a no-op after Commit; error is intentionally ignored.
+
+	config, err := s.ibkrRepo.WithTx(tx).GetIbkrConfig()
 	if err != nil {
 		return fmt.Errorf("delete ibkr config: %w", err)
 	}
 
+	if err := s.ibkrRepo.WithTx(tx).DeleteIbkrConfig(ctx); err != nil {
+		return fmt.Errorf("delete ibkr config: %w", err)
+	}
+
+	if err := recordAudit(ctx, s.auditRepo.WithTx(tx), model.AuditEntityIbkrConfig, config.ID, model.AuditActionDelete, config, nil); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+
 	ibkrLog.InfoContext(ctx, "ibkr config deleted")
 	return nil
 }
@@ -698,6 +1083,8 @@ func (s *IbkrService) DeleteIbkrConfig(ctx context.Context) error {
 // than from the encrypted config — this is intentional for a pre-save credential check.
 // Returns true if IBKR accepts the credentials, or an error if the call fails.
 func (s *IbkrService) TestIbkrConnection(ctx context.Context, req request.TestIbkrConnectionRequest) (bool, error) {
+	ctx, span := tracing.Start(ctx, "IbkrService.TestIbkrConnection")
+	defer span.End()
 	ibkrLog.DebugContext(ctx, "testing ibkr connection", "flexQueryID", req.FlexQueryID)
 	ok, err := s.ibkrClient.TestIbkrConnection(ctx, req.FlexToken, req.FlexQueryID)
 	if err != nil {
@@ -731,20 +1118,36 @@ func (s *IbkrService) GetIbkrTransactionDetail(transactionID string) (model.IBKR
 // DeleteIbkrTransaction removes a pending IBKR transaction.
 // Returns ErrIBKRTransactionAlreadyProcessed if the transaction is not pending.
 func (s *IbkrService) DeleteIbkrTransaction(ctx context.Context, transactionID string) error {
+	ctx, span := tracing.Start(ctx, "IbkrService.DeleteIbkrTransaction")
+	defer span.End()
 	ibkrLog.DebugContext(ctx, "deleting ibkr transaction", "transactionID", transactionID)
-	tx, err := s.ibkrRepo.GetIbkrTransaction(transactionID)
+	dbTx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = dbTx.Rollback() }() //nolint:errcheck
+
+	ibkrTx, err := s.ibkrRepo.WithTx(dbTx).GetIbkrTransaction(transactionID)
 	if err != nil {
 		return fmt.Errorf("get ibkr transaction: %w", err)
 	}
 
-	if tx.Status != "pending" {
+	if ibkrTx.Status != "pending" {
 		return apperrors.ErrIBKRTransactionAlreadyProcessed
 	}
 
-	if err := s.ibkrRepo.DeleteIbkrTransaction(ctx, transactionID); err != nil {
+	if err := s.ibkrRepo.WithTx(dbTx).DeleteIbkrTransaction(ctx, transactionID); err != nil {
 		return fmt.Errorf("delete ibkr transaction: %w", err)
 	}
 
+	if err := recordAudit(ctx, s.auditRepo.WithTx(dbTx), model.AuditEntityIbkrTransaction, transactionID, model.AuditActionDelete, ibkrTx, nil); err != nil {
+		return err
+	}
+
+	if err := dbTx.Commit(); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+
 	ibkrLog.InfoContext(ctx, "ibkr transaction deleted", "transaction_id", transactionID)
 	return nil
 }
@@ -752,6 +1155,8 @@ func (s *IbkrService) DeleteIbkrTransaction(ctx context.Context, transactionID s
 // IgnoreIbkrTransaction marks a pending IBKR transaction as ignored.
 // Returns ErrIBKRTransactionAlreadyProcessed if the transaction is not pending.
 func (s *IbkrService) IgnoreIbkrTransaction(ctx context.Context, transactionID string) error {
+	ctx, span := tracing.Start(ctx, "IbkrService.IgnoreIbkrTransaction")
+	defer span.End()
 	ibkrLog.DebugContext(ctx, "ignoring ibkr transaction", "transactionID", transactionID)
 	dbTx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
@@ -772,6 +1177,12 @@ func (s *IbkrService) IgnoreIbkrTransaction(ctx context.Context, transactionID s
 		return fmt.Errorf("update ibkr transaction status: %w", err)
 	}
 
+	after := ibkrTx
+	after.Status = "ignored"
+	if err := recordAudit(ctx, s.auditRepo.WithTx(dbTx), model.AuditEntityIbkrTransaction, transactionID, model.AuditActionIgnore, ibkrTx, after); err != nil {
+		return err
+	}
+
 	if err := dbTx.Commit(); err != nil {
 		return fmt.Errorf("commit transaction: %w", err)
 	}
@@ -807,6 +1218,8 @@ func (s *IbkrService) findFundByISINOrSymbol(isin, symbol string) (model.Fund, e
 // Creates Transaction and IBKRTransactionAllocation records for each portfolio, including
 // separate fee transactions when fees > 0.
 func (s *IbkrService) AllocateIbkrTransaction(ctx context.Context, transactionID string, allocations []request.AllocationEntry) error {
+	ctx, span := tracing.Start(ctx, "IbkrService.AllocateIbkrTransaction")
+	defer span.End()
 	ibkrLog.DebugContext(ctx, "allocating ibkr transaction", "transactionID", transactionID, "allocations", len(allocations))
 	dbTx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
@@ -814,7 +1227,7 @@ func (s *IbkrService) AllocateIbkrTransaction(ctx context.Context, transactionID
 	}
 	defer func() { _ = dbTx.Rollback() }() //nolint:errcheck
 
-	ibkrTx, err := s.ibkrRepo.WithTx(dbTx).GetIbkrTransaction(transactionID)
+	before, err := s.ibkrAuditSnapshot(dbTx, transactionID)
 	if err != nil {
 		return fmt.Errorf("get ibkr transaction: %w", err)
 	}
@@ -823,12 +1236,16 @@ func (s *IbkrService) AllocateIbkrTransaction(ctx context.Context, transactionID
 		return fmt.Errorf("allocate transaction: %w", err)
 	}
 
+	if err := s.recordIbkrAudit(ctx, dbTx, transactionID, model.AuditActionAllocate, before); err != nil {
+		return err
+	}
+
 	if err := dbTx.Commit(); err != nil {
 		return fmt.Errorf("commit transaction: %w", err)
 	}
 
 	ibkrLog.InfoContext(ctx, "ibkr transaction allocated", "transactionID", transactionID)
-	s.triggerRegenFromAllocations(transactionID, ibkrTx.TransactionDate)
+	s.triggerRegenFromAllocations(ctx, transactionID, before.TransactionDate)
 
 	return nil
 }
@@ -836,6 +1253,8 @@ func (s *IbkrService) AllocateIbkrTransaction(ctx context.Context, transactionID
 // BulkAllocateIbkrTransactions allocates multiple IBKR transactions using the same allocation split.
 // Each transaction is allocated in its own DB transaction so partial success is possible.
 func (s *IbkrService) BulkAllocateIbkrTransactions(ctx context.Context, req request.BulkAllocateRequest) model.BulkAllocateResponse {
+	ctx, span := tracing.Start(ctx, "IbkrService.BulkAllocateIbkrTransactions")
+	defer span.End()
 	ibkrLog.DebugContext(ctx, "bulk allocating ibkr transactions", "count", len(req.TransactionIDs))
 	resp := model.BulkAllocateResponse{Errors: []string{}}
 
@@ -899,6 +1318,8 @@ func (s *IbkrService) unallocateIbkrTransactionTx(ctx context.Context, dbTx *sql
 // Deletes all linked Transaction and IBKRTransactionAllocation records, then resets
 // the status to "pending".
 func (s *IbkrService) UnallocateIbkrTransaction(ctx context.Context, transactionID string) error {
+	ctx, span := tracing.Start(ctx, "IbkrService.UnallocateIbkrTransaction")
+	defer span.End()
 	ibkrLog.DebugContext(ctx, "unallocating ibkr transaction", "transactionID", transactionID)
 	ibkrTx, err := s.ibkrRepo.GetIbkrTransaction(transactionID)
 	if err != nil {
@@ -913,10 +1334,19 @@ func (s *IbkrService) UnallocateIbkrTransaction(ctx context.Context, transaction
 	}
 	defer func() { _ = dbTx.Rollback() }() //nolint:errcheck
 
+	before, err := s.ibkrAuditSnapshot(dbTx, transactionID)
+	if err != nil {
+		return fmt.Errorf("get ibkr transaction: %w", err)
+	}
+
 	if err := s.unallocateIbkrTransactionTx(ctx, dbTx, transactionID); err != nil {
 		return fmt.Errorf("unallocate transaction: %w", err)
 	}
 
+	if err := s.recordIbkrAudit(ctx, dbTx, transactionID, model.AuditActionUnallocate, before); err != nil {
+		return err
+	}
+
 	if err := dbTx.Commit(); err != nil {
 		return fmt.Errorf("commit transaction: %w", err)
 	}
@@ -924,12 +1354,7 @@ func (s *IbkrService) UnallocateIbkrTransaction(ctx context.Context, transaction
 	ibkrLog.InfoContext(ctx, "ibkr transaction unallocated", "transaction_id", transactionID)
 
 	if s.materializedInvalidator != nil && len(portfolioIDs) > 0 {
-		//nolint:gosec // G118: Background context is intentional — goroutine outlives the HTTP request.
-		go func() {
-			if err := s.materializedInvalidator.RegenerateMaterializedTable(context.Background(), ibkrTx.TransactionDate, portfolioIDs, "", ""); err != nil {
-				ibkrLog.Warn("failed to regenerate materialized table after unallocation", "error", err)
-			}
-		}()
+		s.materializedInvalidator.ScheduleRegeneration(ctx, ibkrTx.TransactionDate, portfolioIDs, "", "")
 	}
 
 	return nil
@@ -938,6 +1363,8 @@ func (s *IbkrService) UnallocateIbkrTransaction(ctx context.Context, transaction
 // ModifyAllocations atomically unallocates and reallocates an IBKR transaction with new allocation percentages.
 // Both operations run in a single DB transaction for atomicity.
 func (s *IbkrService) ModifyAllocations(ctx context.Context, transactionID string, allocations []request.AllocationEntry) error {
+	ctx, span := tracing.Start(ctx, "IbkrService.ModifyAllocations")
+	defer span.End()
 	ibkrLog.DebugContext(ctx, "modifying ibkr allocations", "transactionID", transactionID, "allocations", len(allocations))
 	ibkrTx, err := s.ibkrRepo.GetIbkrTransaction(transactionID)
 	if err != nil {
@@ -952,6 +1379,11 @@ func (s *IbkrService) ModifyAllocations(ctx context.Context, transactionID strin
 	}
 	defer func() { _ = dbTx.Rollback() }() //nolint:errcheck
 
+	before, err := s.ibkrAuditSnapshot(dbTx, transactionID)
+	if err != nil {
+		return fmt.Errorf("get ibkr transaction: %w", err)
+	}
+
 	if err := s.unallocateIbkrTransactionTx(ctx, dbTx, transactionID); err != nil {
 		return fmt.Errorf("unallocate transaction: %w", err)
 	}
@@ -961,6 +1393,10 @@ func (s *IbkrService) ModifyAllocations(ctx context.Context, transactionID strin
 		return fmt.Errorf("allocate transaction: %w", err)
 	}
 
+	if err := s.recordIbkrAudit(ctx, dbTx, transactionID, model.AuditActionUpdate, before); err != nil {
+		return err
+	}
+
 	if err := dbTx.Commit(); err != nil {
 		return fmt.Errorf("commit transaction: %w", err)
 	}
@@ -978,12 +1414,7 @@ func (s *IbkrService) ModifyAllocations(ctx context.Context, transactionID strin
 		}
 	}
 	if s.materializedInvalidator != nil && len(newPortfolioIDs) > 0 {
-		//nolint:gosec // G118: Background context is intentional — goroutine outlives the HTTP request.
-		go func() {
-			if err := s.materializedInvalidator.RegenerateMaterializedTable(context.Background(), ibkrTx.TransactionDate, newPortfolioIDs, "", ""); err != nil {
-				ibkrLog.Warn("failed to regenerate materialized table after allocation modification", "error", err)
-			}
-		}()
+		s.materializedInvalidator.ScheduleRegeneration(ctx, ibkrTx.TransactionDate, newPortfolioIDs, "", "")
 	}
 
 	return nil
@@ -1119,6 +1550,8 @@ func (s *IbkrService) allocateIbkrTransactionTx(ctx context.Context, dbTx *sql.T
 //
 //nolint:gocyclo // Dividend matching with per-dividend portfolio lookup and validation.
 func (s *IbkrService) MatchDividend(ctx context.Context, transactionID string, dividendIDs []string) error {
+	ctx, span := tracing.Start(ctx, "IbkrService.MatchDividend")
+	defer span.End()
 	ibkrLog.DebugContext(ctx, "matching dividends to ibkr transaction", "transactionID", transactionID, "dividendIDs", len(dividendIDs))
 	dbTx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
@@ -1171,6 +1604,7 @@ func (s *IbkrService) MatchDividend(ctx context.Context, transactionID string, d
 			return fmt.Errorf("no allocation found for portfolio %s (dividend %s)", pf.PortfolioID, dividendID)
 		}
 
+		before := dividend
 		dividend.ReinvestmentTransactionID = allocTxID
 		dividend.BuyOrderDate = ibkrTx.TransactionDate
 		dividend.ReinvestmentStatus = "COMPLETED"
@@ -1178,6 +1612,10 @@ func (s *IbkrService) MatchDividend(ctx context.Context, transactionID string, d
 		if err := s.dividendRepo.WithTx(dbTx).UpdateDividend(ctx, &dividend); err != nil {
 			return fmt.Errorf("failed to update dividend %s: %w", dividendID, err)
 		}
+
+		if err := recordAudit(ctx, s.auditRepo.WithTx(dbTx), model.AuditEntityDividend, dividendID, model.AuditActionMatchDividend, before, dividend); err != nil {
+			return err
+		}
 	}
 
 	if err := dbTx.Commit(); err != nil {
@@ -1185,11 +1623,40 @@ func (s *IbkrService) MatchDividend(ctx context.Context, transactionID string, d
 	}
 
 	ibkrLog.InfoContext(ctx, "dividends matched to ibkr transaction", "transactionID", transactionID, "dividendCount", len(dividendIDs))
-	s.triggerRegenFromAllocations(transactionID, ibkrTx.TransactionDate)
+	s.triggerRegenFromAllocations(ctx, transactionID, ibkrTx.TransactionDate)
 
 	return nil
 }
 
+// ibkrAuditState is the audit snapshot of an inbox transaction: the row itself plus its allocations.
+type ibkrAuditState struct {
+	model.IBKRTransaction
+	Allocations []model.IBKRTransactionAllocation `json:"allocations,omitempty"`
+}
+
+// ibkrAuditSnapshot reads an inbox transaction and its allocations within dbTx for the audit trail.
+func (s *IbkrService) ibkrAuditSnapshot(dbTx *sql.Tx, transactionID string) (ibkrAuditState, error) {
+	ibkrTx, err := s.ibkrRepo.WithTx(dbTx).GetIbkrTransaction(transactionID)
+	if err != nil {
+		return ibkrAuditState{}, err
+	}
+	allocations, err := s.ibkrRepo.WithTx(dbTx).GetIbkrTransactionAllocations(transactionID)
+	if err != nil {
+		return ibkrAuditState{}, fmt.Errorf("get ibkr transaction allocations: %w", err)
+	}
+	return ibkrAuditState{IBKRTransaction: ibkrTx, Allocations: allocations}, nil
+}
+
+// recordIbkrAudit snapshots the inbox transaction after an allocation change and records
+// it against the provided before state.
+func (s *IbkrService) recordIbkrAudit(ctx context.Context, dbTx *sql.Tx, transactionID string, action model.AuditAction, before ibkrAuditState) error {
+	after, err := s.ibkrAuditSnapshot(dbTx, transactionID)
+	if err != nil {
+		return fmt.Errorf("snapshot ibkr transaction: %w", err)
+	}
+	return recordAudit(ctx, s.auditRepo.WithTx(dbTx), model.AuditEntityIbkrTransaction, transactionID, action, before, after)
+}
+
 // collectPortfolioIDsFromAllocations returns the unique portfolio IDs linked to
 // an IBKR transaction via its allocations. Used to know which portfolios are
 // affected before an unallocation deletes the records.
@@ -1212,7 +1679,7 @@ func (s *IbkrService) collectPortfolioIDsFromAllocations(ibkrTransactionID strin
 
 // triggerRegenFromAllocations looks up the portfolio IDs from an IBKR transaction's
 // allocations and triggers a single materialized view regeneration covering all of them.
-func (s *IbkrService) triggerRegenFromAllocations(ibkrTransactionID string, txDate time.Time) {
+func (s *IbkrService) triggerRegenFromAllocations(ctx context.Context, ibkrTransactionID string, txDate time.Time) {
 	if s.materializedInvalidator == nil {
 		return
 	}
@@ -1220,10 +1687,5 @@ func (s *IbkrService) triggerRegenFromAllocations(ibkrTransactionID string, txDa
 	if len(portfolioIDs) == 0 {
 		return
 	}
-	//nolint:gosec // G118: Background context is intentional — goroutine outlives the HTTP request.
-	go func() {
-		if err := s.materializedInvalidator.RegenerateMaterializedTable(context.Background(), txDate, portfolioIDs, "", ""); err != nil {
-			ibkrLog.Warn("failed to regenerate materialized table", "error", err)
-		}
-	}()
+	s.materializedInvalidator.ScheduleRegeneration(ctx, txDate, portfolioIDs, "", "")
 }
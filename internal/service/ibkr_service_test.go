@@ -154,6 +154,33 @@ func TestIbkrService_EncryptDecryptToken(t *testing.T) {
 			t.Fatal("expected error when decrypting with wrong key")
 		}
 	})
+
+	t.Run("decrypts with an older key and encrypts with the newest", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		oldKey := generateFernetKey(t)
+		newKey := generateFernetKey(t)
+
+		encrypted, err := fernet.EncryptAndSign([]byte("my-token"), oldKey)
+		if err != nil {
+			t.Fatalf("encrypt failed: %v", err)
+		}
+
+		svc := testutil.NewTestIbkrServiceWithMockIBKR(t, db, &mockIBKRClient{},
+			service.IbkrWithEncryptionKeys([]*fernet.Key{newKey, oldKey}))
+
+		decrypted, err := svc.ExportDecryptToken(string(encrypted))
+		if err != nil || decrypted != "my-token" {
+			t.Errorf("expected the older key to decrypt, got %q, %v", decrypted, err)
+		}
+
+		reencrypted, err := svc.ExportEncryptToken("my-token")
+		if err != nil {
+			t.Fatalf("encrypt failed: %v", err)
+		}
+		if got := fernet.VerifyAndDecrypt([]byte(reencrypted), 0, []*fernet.Key{newKey}); string(got) != "my-token" {
+			t.Error("expected new tokens to be encrypted with the newest key")
+		}
+	})
 }
 
 // --- GetIbkrConfig Tests ---
@@ -463,6 +490,25 @@ func TestIbkrService_GetInbox(t *testing.T) {
 			t.Errorf("expected 1 pending transaction, got %d", len(inbox))
 		}
 	})
+
+	t.Run("excludes transactions staged by other importers", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		svc := testutil.NewTestIbkrService(t, db)
+
+		testutil.NewIBKRTransaction().WithStatus("pending").Build(t, db)
+		testutil.NewIBKRTransaction().WithStatus("pending").WithSource("csv").Build(t, db)
+
+		inbox, err := svc.GetInbox("pending", "")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(inbox) != 1 {
+			t.Fatalf("expected 1 ibkr transaction, got %d", len(inbox))
+		}
+		if inbox[0].Source != "ibkr" {
+			t.Errorf("expected source ibkr, got %q", inbox[0].Source)
+		}
+	})
 }
 
 func TestIbkrService_GetInboxCount(t *testing.T) {
@@ -1891,6 +1937,227 @@ func TestIbkrService_ImportFlexReport(t *testing.T) {
 	})
 }
 
+func TestIbkrService_ImportFlexFile(t *testing.T) {
+	flexXML := []byte(`<FlexQueryResponse queryName="test" type="AF"><FlexStatements count="1"><FlexStatement accountId="U1">` +
+		`<Trades><Trade currencyPrimary="USD" symbol="AAPL" isin="US0378331005" quantity="10" tradePrice="150" ibCommission="-1" ` +
+		`netCash="-1500" ibOrderID="100" transactionID="200" tradeDate="20240115" buySell="BUY" reportDate="20240115"/></Trades>` +
+		`</FlexStatement></FlexStatements></FlexQueryResponse>`)
+
+	t.Run("imports a statement without config or api call", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		svc := testutil.NewTestIbkrServiceWithMockIBKR(t, db, &mockIBKRClient{})
+
+		imported, skipped, err := svc.ImportFlexFile(context.Background(), flexXML)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if imported != 1 || skipped != 0 {
+			t.Errorf("expected 1 imported and 0 skipped, got %d and %d", imported, skipped)
+		}
+		testutil.AssertRowCount(t, db, "ibkr_transaction", 1)
+		testutil.AssertRowCount(t, db, "ibkr_import_cache", 0)
+	})
+
+	t.Run("skips transactions already in the inbox", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		svc := testutil.NewTestIbkrServiceWithMockIBKR(t, db, &mockIBKRClient{})
+
+		if _, _, err := svc.ImportFlexFile(context.Background(), flexXML); err != nil {
+			t.Fatalf("first import: %v", err)
+		}
+		imported, skipped, err := svc.ImportFlexFile(context.Background(), flexXML)
+		if err != nil {
+			t.Fatalf("second import: %v", err)
+		}
+		if imported != 0 || skipped != 1 {
+			t.Errorf("expected 0 imported and 1 skipped, got %d and %d", imported, skipped)
+		}
+		testutil.AssertRowCount(t, db, "ibkr_transaction", 1)
+	})
+
+	t.Run("rejects invalid xml", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		svc := testutil.NewTestIbkrServiceWithMockIBKR(t, db, &mockIBKRClient{})
+
+		if _, _, err := svc.ImportFlexFile(context.Background(), []byte("not xml")); err == nil {
+			t.Fatal("expected error for invalid xml")
+		}
+	})
+}
+
+func TestIbkrService_ImportWithholdingTax(t *testing.T) {
+	flexXML := []byte(`<FlexQueryResponse queryName="test" type="AF"><FlexStatements count="1"><FlexStatement accountId="U1">` +
+		`<CashTransactions>` +
+		`<CashTransaction currency="USD" symbol="AAPL" isin="US0378331005" dateTime="20250213;202000" amount="-30" ` +
+		`type="Withholding Tax" transactionID="301" reportDate="20250213" exDate="20250207" description="AAPL US TAX"/>` +
+		`<CashTransaction currency="USD" symbol="AAPL" isin="US0378331005" dateTime="20250213;202000" amount="200" ` +
+		`type="Dividends" transactionID="302" reportDate="20250213"/>` +
+		`<CashTransaction currency="USD" symbol="MSFT" isin="US5949181045" dateTime="20250313" amount="-5" ` +
+		`type="Withholding Tax" transactionID="303" reportDate="20250313"/>` +
+		`</CashTransactions></FlexStatement></FlexStatements></FlexQueryResponse>`)
+
+	db := testutil.SetupTestDB(t)
+	svc := testutil.NewTestIbkrServiceWithMockIBKR(t, db, &mockIBKRClient{})
+	dividendRepo := repository.NewDividendRepository(db)
+
+	fund := testutil.NewFund().WithISIN("US0378331005").WithSymbol("AAPL").Build(t, db)
+	pf1 := testutil.NewPortfolioFund(testutil.NewPortfolio().Build(t, db).ID, fund.ID).Build(t, db)
+	pf2 := testutil.NewPortfolioFund(testutil.NewPortfolio().Build(t, db).ID, fund.ID).Build(t, db)
+	exDate := time.Date(2025, 2, 7, 0, 0, 0, 0, time.UTC)
+	div1 := testutil.NewDividend(fund.ID, pf1.ID).WithExDividendDate(exDate).
+		WithWithholdingTax(7.5, model.WithholdingSourceDefault).Build(t, db)
+	div2 := testutil.NewDividend(fund.ID, pf2.ID).WithExDividendDate(exDate).
+		WithSharesOwned(300).WithDividendPerShare(0.5).Build(t, db)
+
+	assertWithholding := func(t *testing.T, dividendID string, want float64) {
+		t.Helper()
+		d, err := dividendRepo.GetDividend(dividendID)
+		if err != nil {
+			t.Fatalf("failed to get dividend: %v", err)
+		}
+		if d.WithholdingTax != want || d.WithholdingSource != model.WithholdingSourceIBKR {
+			t.Errorf("dividend %s withholding = %v (%s), want %v from IBKR", dividendID, d.WithholdingTax, d.WithholdingSource, want)
+		}
+	}
+
+	if _, _, err := svc.ImportFlexFile(context.Background(), flexXML); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// 30 withheld on 50 + 150 gross is split 7.5 / 22.5 and replaces the default estimate.
+	assertWithholding(t, div1.ID, 7.5)
+	assertWithholding(t, div2.ID, 22.5)
+	testutil.AssertRowCount(t, db, "ibkr_withholding_tax", 2)
+	if n := countRows(t, db, "ibkr_withholding_tax", "applied_at IS NULL"); n != 1 {
+		t.Errorf("expected the unmatched MSFT withholding to stay unapplied, got %d unapplied", n)
+	}
+
+	// Importing the same statement again neither duplicates nor re-applies the tax.
+	if _, _, err := svc.ImportFlexFile(context.Background(), flexXML); err != nil {
+		t.Fatalf("second import: %v", err)
+	}
+	assertWithholding(t, div1.ID, 7.5)
+	assertWithholding(t, div2.ID, 22.5)
+	testutil.AssertRowCount(t, db, "ibkr_withholding_tax", 2)
+}
+
+func TestIbkrService_RotateEncryptionKey(t *testing.T) {
+	t.Run("re-encrypts the stored token with the newest key", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		oldKey := generateFernetKey(t)
+		newKey := generateFernetKey(t)
+
+		plainToken := "test-ibkr-token" //nolint:gosec // G101: Test credential, not a real secret
+		encToken, err := fernet.EncryptAndSign([]byte(plainToken), oldKey)
+		if err != nil {
+			t.Fatalf("failed to encrypt token: %v", err)
+		}
+		configID := testutil.MakeID()
+		insertIbkrConfig(t, db, configID, string(encToken), "54321", true)
+
+		svc := testutil.NewTestIbkrServiceWithMockIBKR(t, db, &mockIBKRClient{},
+			service.IbkrWithEncryptionKeys([]*fernet.Key{newKey, oldKey}))
+
+		rotation, err := svc.RotateEncryptionKey(context.Background())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !rotation.Reencrypted {
+			t.Error("expected the token to be re-encrypted")
+		}
+		if rotation.ConfiguredKeys != 2 {
+			t.Errorf("expected 2 configured keys, got %d", rotation.ConfiguredKeys)
+		}
+		if rotation.KeyID == "" || rotation.PreviousKeyID == "" || rotation.KeyID == rotation.PreviousKeyID {
+			t.Errorf("expected distinct key IDs, got %q and %q", rotation.PreviousKeyID, rotation.KeyID)
+		}
+
+		var stored string
+		if err := db.QueryRow(`SELECT flex_token FROM ibkr_config`).Scan(&stored); err != nil {
+			t.Fatalf("read flex token: %v", err)
+		}
+		if got := fernet.VerifyAndDecrypt([]byte(stored), 0, []*fernet.Key{newKey}); string(got) != plainToken {
+			t.Errorf("expected token to decrypt with the new key, got %q", got)
+		}
+		if got := fernet.VerifyAndDecrypt([]byte(stored), 0, []*fernet.Key{oldKey}); got != nil {
+			t.Error("expected token to no longer decrypt with the old key")
+		}
+
+		if n := countRows(t, db, "audit_event", "entity_id = ? AND action = ?", configID, "rotate_key"); n != 1 {
+			t.Errorf("expected 1 rotate_key audit event, got %d", n)
+		}
+	})
+
+	t.Run("rotating twice keeps the newest key", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		key := generateFernetKey(t)
+		encToken, err := fernet.EncryptAndSign([]byte("token"), key)
+		if err != nil {
+			t.Fatalf("failed to encrypt token: %v", err)
+		}
+		insertIbkrConfig(t, db, testutil.MakeID(), string(encToken), "54321", true)
+
+		svc := testutil.NewTestIbkrServiceWithMockIBKR(t, db, &mockIBKRClient{},
+			service.IbkrWithEncryptionKey(key))
+
+		rotation, err := svc.RotateEncryptionKey(context.Background())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if rotation.PreviousKeyID != rotation.KeyID {
+			t.Errorf("expected the token to stay on key %s, got %s", rotation.KeyID, rotation.PreviousKeyID)
+		}
+	})
+
+	t.Run("no stored token", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		svc := testutil.NewTestIbkrServiceWithMockIBKR(t, db, &mockIBKRClient{},
+			service.IbkrWithEncryptionKey(generateFernetKey(t)))
+
+		rotation, err := svc.RotateEncryptionKey(context.Background())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if rotation.Reencrypted {
+			t.Error("expected nothing to re-encrypt")
+		}
+		testutil.AssertRowCount(t, db, "audit_event", 0)
+	})
+
+	t.Run("fails without encryption keys", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		svc := testutil.NewTestIbkrServiceWithMockIBKR(t, db, &mockIBKRClient{})
+
+		if _, err := svc.RotateEncryptionKey(context.Background()); err == nil {
+			t.Fatal("expected error without encryption keys")
+		}
+	})
+
+	t.Run("fails when no configured key decrypts the token", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		encToken, err := fernet.EncryptAndSign([]byte("token"), generateFernetKey(t))
+		if err != nil {
+			t.Fatalf("failed to encrypt token: %v", err)
+		}
+		insertIbkrConfig(t, db, testutil.MakeID(), string(encToken), "54321", true)
+
+		svc := testutil.NewTestIbkrServiceWithMockIBKR(t, db, &mockIBKRClient{},
+			service.IbkrWithEncryptionKeys([]*fernet.Key{generateFernetKey(t), generateFernetKey(t)}))
+
+		if _, err := svc.RotateEncryptionKey(context.Background()); err == nil {
+			t.Fatal("expected error when the token cannot be decrypted")
+		}
+
+		var stored string
+		if err := db.QueryRow(`SELECT flex_token FROM ibkr_config`).Scan(&stored); err != nil {
+			t.Fatalf("read flex token: %v", err)
+		}
+		if stored != string(encToken) {
+			t.Error("expected the stored token to be unchanged")
+		}
+	})
+}
+
 // --- GetIbkrTransactionDetail Tests ---
 
 func TestIbkrService_GetIbkrTransactionDetail(t *testing.T) {
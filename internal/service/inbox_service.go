@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/api/request"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/logging"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/model"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/repository"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/tracing"
+)
+
+var inboxLog = logging.NewLogger("ibkr")
+
+// InboxService handles the source-agnostic transaction inbox.
+// Importers stage transactions under their own source; staged rows share the allocation,
+// ignore and dividend-matching workflow implemented by IbkrService, which operates on
+// inbox rows regardless of where they came from.
+type InboxService struct {
+	db        *sql.DB
+	ibkrRepo  *repository.IbkrRepository
+	auditRepo *repository.AuditRepository
+}
+
+// NewInboxService creates a new InboxService with the provided repository dependency.
+func NewInboxService(
+	db *sql.DB,
+	ibkrRepo *repository.IbkrRepository,
+) *InboxService {
+	return &InboxService{
+		db:        db,
+		ibkrRepo:  ibkrRepo,
+		auditRepo: repository.NewAuditRepository(db),
+	}
+}
+
+// GetInbox retrieves staged transactions across all importers.
+// Filters by status (defaults to "pending"), and optionally by source and transaction type.
+func (s *InboxService) GetInbox(filter model.InboxFilter) ([]model.IBKRTransaction, error) {
+	inboxLog.Debug("retrieving inbox", "inbox_source", filter.Source, "transactionType", filter.TransactionType)
+	inbox, err := s.ibkrRepo.GetInbox(filter)
+	if err != nil {
+		return nil, fmt.Errorf("get inbox: %w", err)
+	}
+	return inbox, nil
+}
+
+// GetInboxCount retrieves the number of pending inbox transactions.
+// An empty source counts pending transactions from every importer.
+func (s *InboxService) GetInboxCount(source string) (model.IBKRInboxCount, error) {
+	inboxLog.Debug("retrieving inbox count", "inbox_source", source)
+	count, err := s.ibkrRepo.GetIbkrInboxCount(source)
+	if err != nil {
+		return model.IBKRInboxCount{}, fmt.Errorf("get inbox count: %w", err)
+	}
+	return count, nil
+}
+
+// StageTransactions adds a batch of transactions to the inbox as "pending" under the request's source.
+// Each entry's external reference is namespaced by source ("<source>:<externalId>") so different
+// importers cannot collide; entries whose reference already exists are skipped rather than failing
+// the batch, which makes re-submitting the same file or paste idempotent.
+// All new entries are inserted in a single database transaction.
+func (s *InboxService) StageTransactions(ctx context.Context, req request.StageInboxTransactionsRequest) (model.InboxStageResult, error) {
+	ctx, span := tracing.Start(ctx, "InboxService.StageTransactions")
+	defer span.End()
+	inboxLog.DebugContext(ctx, "staging inbox transactions", "inbox_source", req.Source, "count", len(req.Transactions))
+	result := model.InboxStageResult{Source: req.Source}
+
+	now := time.Now().UTC()
+	seen := make(map[string]bool, len(req.Transactions))
+	staged := make([]model.IBKRTransaction, 0, len(req.Transactions))
+
+	for _, e := range req.Transactions {
+		date, err := time.Parse("2006-01-02", e.Date)
+		if err != nil {
+			return model.InboxStageResult{}, fmt.Errorf("parse date %q: %w", e.Date, err)
+		}
+
+		t := model.IBKRTransaction{
+			ID:                uuid.New().String(),
+			Source:            req.Source,
+			IBKRTransactionID: inboxExternalID(req.Source, e),
+			TransactionDate:   date,
+			Symbol:            strings.TrimSpace(e.Symbol),
+			ISIN:              strings.TrimSpace(e.ISIN),
+			Description:       e.Description,
+			TransactionType:   e.Type,
+			Quantity:          e.Quantity,
+			Price:             e.Price,
+			TotalAmount:       e.TotalAmount,
+			Currency:          strings.ToUpper(e.Currency),
+			Fees:              e.Fees,
+			Status:            string(model.InboxStatusPending),
+			ImportedAt:        now,
+			Notes:             e.Notes,
+			ReportDate:        date,
+		}
+
+		if seen[t.IBKRTransactionID] || s.ibkrRepo.CompareIbkrTransaction(t) {
+			result.Skipped++
+			continue
+		}
+		seen[t.IBKRTransactionID] = true
+		staged = append(staged, t)
+	}
+
+	if len(staged) > 0 {
+		tx, err := s.db.BeginTx(ctx, nil)
+		if err != nil {
+			return model.InboxStageResult{}, fmt.Errorf("begin transaction: %w", err)
+		}
+		defer func() { _ = tx.Rollback() }() //nolint:errcheck
+
+		if err := s.ibkrRepo.WithTx(tx).AddIbkrTransactions(ctx, staged); err != nil {
+			return model.InboxStageResult{}, fmt.Errorf("add inbox transactions: %w", err)
+		}
+
+		for _, t := range staged {
+			if err := recordAudit(ctx, s.auditRepo.WithTx(tx), model.AuditEntityIbkrTransaction, t.ID, model.AuditActionCreate, nil, t); err != nil {
+				return model.InboxStageResult{}, err
+			}
+		}
+
+		if err := tx.Commit(); err != nil {
+			return model.InboxStageResult{}, fmt.Errorf("commit transaction: %w", err)
+		}
+	}
+
+	result.Staged = len(staged)
+	inboxLog.InfoContext(ctx, "inbox transactions staged", "inbox_source", req.Source, "staged", result.Staged, "skipped", result.Skipped)
+	return result, nil
+}
+
+// inboxExternalID returns the namespaced external reference for a staged entry.
+// When the importer supplies no ExternalID, a content hash is used so that an identical
+// entry submitted twice is still recognised as a duplicate.
+func inboxExternalID(source string, e request.InboxTransactionEntry) string {
+	ref := strings.TrimSpace(e.ExternalID)
+	if ref == "" {
+		sum := sha256.Sum256(fmt.Appendf(nil, "%s|%s|%s|%s|%g|%g|%g|%s|%g",
+			e.Date, strings.TrimSpace(e.ISIN), strings.TrimSpace(e.Symbol), e.Type,
+			e.Quantity, e.Price, e.TotalAmount, strings.ToUpper(e.Currency), e.Fees))
+		ref = hex.EncodeToString(sum[:])[:32]
+	}
+	return source + ":" + ref
+}
@@ -0,0 +1,179 @@
+package service_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/api/request"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/model"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/testutil"
+)
+
+func TestInboxService_GetInbox(t *testing.T) {
+	t.Run("returns pending transactions from every source", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		svc := testutil.NewTestInboxService(t, db)
+
+		testutil.NewIBKRTransaction().WithStatus("pending").Build(t, db)
+		testutil.NewIBKRTransaction().WithStatus("pending").WithSource("csv").Build(t, db)
+		testutil.NewIBKRTransaction().WithStatus("processed").WithSource("csv").Build(t, db)
+
+		inbox, err := svc.GetInbox(model.InboxFilter{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(inbox) != 2 {
+			t.Errorf("expected 2 pending transactions, got %d", len(inbox))
+		}
+	})
+
+	t.Run("filters by source", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		svc := testutil.NewTestInboxService(t, db)
+
+		testutil.NewIBKRTransaction().WithStatus("pending").Build(t, db)
+		testutil.NewIBKRTransaction().WithStatus("pending").WithSource("csv").Build(t, db)
+
+		inbox, err := svc.GetInbox(model.InboxFilter{Source: "csv"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(inbox) != 1 || inbox[0].Source != "csv" {
+			t.Errorf("expected 1 csv transaction, got %+v", inbox)
+		}
+	})
+}
+
+func TestInboxService_GetInboxCount(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	svc := testutil.NewTestInboxService(t, db)
+
+	testutil.NewIBKRTransaction().WithStatus("pending").Build(t, db)
+	testutil.NewIBKRTransaction().WithStatus("pending").WithSource("manual").Build(t, db)
+
+	t.Run("counts all sources", func(t *testing.T) {
+		count, err := svc.GetInboxCount("")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if count.Count != 2 {
+			t.Errorf("expected count=2, got %d", count.Count)
+		}
+	})
+
+	t.Run("counts a single source", func(t *testing.T) {
+		count, err := svc.GetInboxCount("manual")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if count.Count != 1 {
+			t.Errorf("expected count=1, got %d", count.Count)
+		}
+	})
+}
+
+func TestInboxService_StageTransactions(t *testing.T) {
+	entry := func(externalID string) request.InboxTransactionEntry {
+		return request.InboxTransactionEntry{
+			ExternalID:  externalID,
+			Date:        "2025-03-14",
+			Symbol:      "VWRL",
+			ISIN:        "IE00B3RBWM25",
+			Type:        "buy",
+			Quantity:    10,
+			Price:       100,
+			TotalAmount: 1000,
+			Currency:    "eur",
+		}
+	}
+
+	t.Run("stages transactions as pending under the source", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		svc := testutil.NewTestInboxService(t, db)
+
+		result, err := svc.StageTransactions(context.Background(), request.StageInboxTransactionsRequest{
+			Source:       "csv",
+			Transactions: []request.InboxTransactionEntry{entry("row-1"), entry("row-2")},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.Staged != 2 || result.Skipped != 0 {
+			t.Errorf("expected 2 staged, 0 skipped, got %+v", result)
+		}
+
+		inbox, err := svc.GetInbox(model.InboxFilter{Source: "csv"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(inbox) != 2 {
+			t.Fatalf("expected 2 staged rows, got %d", len(inbox))
+		}
+		for _, tx := range inbox {
+			if tx.Status != "pending" {
+				t.Errorf("expected status pending, got %q", tx.Status)
+			}
+			if !strings.HasPrefix(tx.IBKRTransactionID, "csv:") {
+				t.Errorf("expected namespaced external id, got %q", tx.IBKRTransactionID)
+			}
+			if tx.Currency != "EUR" {
+				t.Errorf("expected upper-cased currency, got %q", tx.Currency)
+			}
+		}
+	})
+
+	t.Run("skips entries already staged", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		svc := testutil.NewTestInboxService(t, db)
+		req := request.StageInboxTransactionsRequest{
+			Source:       "csv",
+			Transactions: []request.InboxTransactionEntry{entry("row-1")},
+		}
+
+		if _, err := svc.StageTransactions(context.Background(), req); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		result, err := svc.StageTransactions(context.Background(), req)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.Staged != 0 || result.Skipped != 1 {
+			t.Errorf("expected 0 staged, 1 skipped, got %+v", result)
+		}
+	})
+
+	t.Run("deduplicates entries without an external id by content", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		svc := testutil.NewTestInboxService(t, db)
+
+		result, err := svc.StageTransactions(context.Background(), request.StageInboxTransactionsRequest{
+			Source:       "manual",
+			Transactions: []request.InboxTransactionEntry{entry(""), entry("")},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.Staged != 1 || result.Skipped != 1 {
+			t.Errorf("expected 1 staged, 1 skipped, got %+v", result)
+		}
+	})
+
+	t.Run("same external id under another source is not a duplicate", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		svc := testutil.NewTestInboxService(t, db)
+
+		for _, source := range []string{"csv", "manual"} {
+			result, err := svc.StageTransactions(context.Background(), request.StageInboxTransactionsRequest{
+				Source:       source,
+				Transactions: []request.InboxTransactionEntry{entry("row-1")},
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.Staged != 1 {
+				t.Errorf("expected 1 staged for %s, got %+v", source, result)
+			}
+		}
+	})
+}
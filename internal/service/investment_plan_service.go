@@ -0,0 +1,401 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/api/request"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/auth"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/logging"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/model"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/repository"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/tracing"
+)
+
+var planLog = logging.NewLogger("transaction")
+
+// InvestmentPlanService manages recurring investment plans and generates the buy
+// transactions of their due runs.
+type InvestmentPlanService struct {
+	db                      *sql.DB
+	planRepo                *repository.InvestmentPlanRepository
+	auditRepo               *repository.AuditRepository
+	pfRepo                  *repository.PortfolioFundRepository
+	fundRepo                *repository.FundRepository
+	transactionRepo         *repository.TransactionRepository
+	materializedInvalidator MaterializedInvalidator
+
+	// mu serializes runs so the scheduled job and the startup catch-up cannot buy the same run twice.
+	mu sync.Mutex
+}
+
+// NewInvestmentPlanService creates a new InvestmentPlanService with the provided dependencies.
+func NewInvestmentPlanService(
+	db *sql.DB,
+	planRepo *repository.InvestmentPlanRepository,
+	auditRepo *repository.AuditRepository,
+	pfRepo *repository.PortfolioFundRepository,
+	fundRepo *repository.FundRepository,
+	transactionRepo *repository.TransactionRepository,
+) *InvestmentPlanService {
+	return &InvestmentPlanService{
+		db:              db,
+		planRepo:        planRepo,
+		auditRepo:       auditRepo,
+		pfRepo:          pfRepo,
+		fundRepo:        fundRepo,
+		transactionRepo: transactionRepo,
+	}
+}
+
+// SetMaterializedInvalidator injects the MaterializedInvalidator after construction.
+// Generated transactions schedule regeneration of the affected portfolio history.
+func (s *InvestmentPlanService) SetMaterializedInvalidator(m MaterializedInvalidator) {
+	s.materializedInvalidator = m
+}
+
+// GetInvestmentPlans retrieves the investment plans of a portfolio, or of every portfolio
+// the caller in ctx may read when portfolioID is empty.
+func (s *InvestmentPlanService) GetInvestmentPlans(ctx context.Context, portfolioID string) ([]model.InvestmentPlan, error) {
+	plans, err := s.planRepo.GetInvestmentPlans(portfolioID, auth.Scope(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("get investment plans: %w", err)
+	}
+	for i := range plans {
+		withNextRunDate(&plans[i])
+	}
+	return plans, nil
+}
+
+// GetInvestmentPlan retrieves an investment plan by ID.
+// Returns ErrInvestmentPlanNotFound if it does not exist.
+func (s *InvestmentPlanService) GetInvestmentPlan(planID string) (model.InvestmentPlan, error) {
+	plan, err := s.planRepo.GetInvestmentPlan(planID)
+	if err != nil {
+		return model.InvestmentPlan{}, err
+	}
+	withNextRunDate(&plan)
+	return plan, nil
+}
+
+// CreateInvestmentPlan stores a new investment plan. A start date in the past makes the
+// next run buy every run since then. Returns ErrPortfolioFundNotFound if the portfolio
+// fund does not exist.
+func (s *InvestmentPlanService) CreateInvestmentPlan(ctx context.Context, req request.CreateInvestmentPlanRequest) (model.InvestmentPlan, error) {
+	ctx, span := tracing.Start(ctx, "InvestmentPlanService.CreateInvestmentPlan")
+	defer span.End()
+
+	if _, err := s.pfRepo.GetPortfolioFund(req.PortfolioFundID); err != nil {
+		return model.InvestmentPlan{}, fmt.Errorf("get portfolio fund: %w", err)
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	plan := model.InvestmentPlan{
+		ID:              uuid.New().String(),
+		PortfolioFundID: req.PortfolioFundID,
+		Enabled:         req.Enabled == nil || *req.Enabled,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	if err := applyInvestmentPlanSchedule(&plan, req.Amount, req.Frequency, req.DayOfMonth, req.StartDate, req.EndDate); err != nil {
+		return model.InvestmentPlan{}, err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return model.InvestmentPlan{}, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }() //nolint:errcheck // Rollback is a no-op after Commit; error is intentionally ignored.
+
+	if err := s.planRepo.WithTx(tx).InsertInvestmentPlan(ctx, &plan); err != nil {
+		return model.InvestmentPlan{}, fmt.Errorf("insert investment plan: %w", err)
+	}
+	if err := recordAudit(ctx, s.auditRepo.WithTx(tx), model.AuditEntityInvestmentPlan, plan.ID, model.AuditActionCreate, nil, plan); err != nil {
+		return model.InvestmentPlan{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return model.InvestmentPlan{}, fmt.Errorf("commit transaction: %w", err)
+	}
+
+	planLog.InfoContext(ctx, "investment plan created", "plan_id", plan.ID, "portfolio_fund_id", plan.PortfolioFundID)
+	return s.GetInvestmentPlan(plan.ID)
+}
+
+// UpdateInvestmentPlan replaces the amount, schedule and enabled flag of an investment plan.
+// Runs that were already generated are kept. Re-enabling a disabled plan skips the runs
+// that fell in the paused period instead of buying them all at once.
+// Returns ErrInvestmentPlanNotFound if the plan does not exist.
+func (s *InvestmentPlanService) UpdateInvestmentPlan(ctx context.Context, planID string, req request.UpdateInvestmentPlanRequest) (model.InvestmentPlan, error) {
+	ctx, span := tracing.Start(ctx, "InvestmentPlanService.UpdateInvestmentPlan")
+	defer span.End()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	before, err := s.planRepo.GetInvestmentPlan(planID)
+	if err != nil {
+		return model.InvestmentPlan{}, err
+	}
+	plan := before
+	if req.Enabled != nil {
+		plan.Enabled = *req.Enabled
+	}
+	plan.UpdatedAt = time.Now().UTC().Truncate(time.Second)
+	if err := applyInvestmentPlanSchedule(&plan, req.Amount, req.Frequency, req.DayOfMonth, req.StartDate, req.EndDate); err != nil {
+		return model.InvestmentPlan{}, err
+	}
+
+	var skipUntil *time.Time
+	if plan.Enabled && !before.Enabled {
+		today := time.Now().UTC().Truncate(24 * time.Hour)
+		if due := dueRunDates(plan, today.AddDate(0, 0, -1)); len(due) > 0 {
+			skipUntil = &due[len(due)-1]
+		}
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return model.InvestmentPlan{}, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }() //nolint:errcheck // Rollback is a no-op after Commit; error is intentionally ignored.
+
+	if err := s.planRepo.WithTx(tx).UpdateInvestmentPlan(ctx, &plan); err != nil {
+		return model.InvestmentPlan{}, err
+	}
+	if skipUntil != nil {
+		plan.LastRunDate = skipUntil
+		if err := s.planRepo.WithTx(tx).UpdateInvestmentPlanLastRun(ctx, planID, *skipUntil); err != nil {
+			return model.InvestmentPlan{}, err
+		}
+	}
+	if err := recordAudit(ctx, s.auditRepo.WithTx(tx), model.AuditEntityInvestmentPlan, planID, model.AuditActionUpdate, before, plan); err != nil {
+		return model.InvestmentPlan{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return model.InvestmentPlan{}, fmt.Errorf("commit transaction: %w", err)
+	}
+
+	planLog.InfoContext(ctx, "investment plan updated", "plan_id", planID)
+	return s.GetInvestmentPlan(planID)
+}
+
+// DeleteInvestmentPlan removes an investment plan. Its generated transactions are kept
+// and become regular transactions. Returns ErrInvestmentPlanNotFound if it does not exist.
+func (s *InvestmentPlanService) DeleteInvestmentPlan(ctx context.Context, planID string) error {
+	ctx, span := tracing.Start(ctx, "InvestmentPlanService.DeleteInvestmentPlan")
+	defer span.End()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }() //nolint:errcheck // Rollback is a no-op after Commit; error is intentionally ignored.
+
+	before, err := s.planRepo.WithTx(tx).GetInvestmentPlan(planID)
+	if err != nil {
+		return err
+	}
+	if err := s.planRepo.WithTx(tx).DeleteInvestmentPlan(ctx, planID); err != nil {
+		return err
+	}
+	if err := recordAudit(ctx, s.auditRepo.WithTx(tx), model.AuditEntityInvestmentPlan, planID, model.AuditActionDelete, before, nil); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+
+	planLog.InfoContext(ctx, "investment plan deleted", "plan_id", planID)
+	return nil
+}
+
+// RunInvestmentPlans generates the buy transactions of every enabled plan for the runs
+// that are due up to today and not generated yet, so runs missed while the server was
+// down are caught up. Each run buys at the fund price of its scheduled date or the next
+// available one; a plan whose due run has no price yet waits for the next run.
+//
+// The runs of one plan are stored in a single transaction together with the plan's last
+// run date. A failing plan does not stop the others; their errors are joined.
+func (s *InvestmentPlanService) RunInvestmentPlans(ctx context.Context) (result model.InvestmentPlanRun, err error) {
+	ctx, span := tracing.Start(ctx, "InvestmentPlanService.RunInvestmentPlans")
+	defer func() { tracing.End(span, err) }()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	plans, err := s.planRepo.GetEnabledInvestmentPlans()
+	if err != nil {
+		return result, fmt.Errorf("get investment plans: %w", err)
+	}
+	result.Plans = len(plans)
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	var errs []error
+	for _, plan := range plans {
+		generated, waiting, err := s.runInvestmentPlan(ctx, plan, today)
+		if err != nil {
+			planLog.ErrorContext(ctx, "investment plan run failed", "plan_id", plan.ID, "error", err)
+			result.Failed++
+			errs = append(errs, fmt.Errorf("plan %s: %w", plan.ID, err))
+			continue
+		}
+		result.Transactions += generated
+		if waiting {
+			result.Waiting++
+		}
+	}
+
+	planLog.InfoContext(ctx, "investment plans run", "plans", result.Plans, "transactions", result.Transactions,
+		"waiting", result.Waiting, "failed", result.Failed)
+	return result, errors.Join(errs...)
+}
+
+// runInvestmentPlan generates the due runs of one plan. It returns the number of
+// transactions created and whether a due run is still waiting for a fund price.
+func (s *InvestmentPlanService) runInvestmentPlan(ctx context.Context, plan model.InvestmentPlan, today time.Time) (int, bool, error) {
+	due := dueRunDates(plan, today)
+	if len(due) == 0 {
+		return 0, false, nil
+	}
+
+	prices, err := s.fundRepo.GetFundPrice([]string{plan.FundID}, due[0], today, true)
+	if err != nil {
+		return 0, false, fmt.Errorf("get fund prices: %w", err)
+	}
+	fundPrices := prices[plan.FundID]
+
+	now := time.Now().UTC()
+	var transactions []model.Transaction
+	var lastRun time.Time
+	waiting := false
+	for _, date := range due {
+		price, ok := priceOnOrAfter(fundPrices, date)
+		if !ok {
+			waiting = true
+			break
+		}
+		transactions = append(transactions, model.Transaction{
+			ID:               uuid.New().String(),
+			PortfolioFundID:  plan.PortfolioFundID,
+			Date:             price.Date.UTC(),
+			Type:             string(model.TransactionTypeBuy),
+			Shares:           round(plan.Amount / price.Price),
+			CostPerShare:     price.Price,
+			InvestmentPlanID: plan.ID,
+			CreatedAt:        now,
+		})
+		lastRun = date
+	}
+	if len(transactions) == 0 {
+		return 0, waiting, nil
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, false, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }() //nolint:errcheck // Rollback is a no-op after Commit; error is intentionally ignored.
+
+	for i := range transactions {
+		if err := s.transactionRepo.WithTx(tx).InsertTransaction(ctx, &transactions[i]); err != nil {
+			return 0, false, fmt.Errorf("insert transaction: %w", err)
+		}
+		if err := recordAudit(ctx, s.auditRepo.WithTx(tx), model.AuditEntityTransaction, transactions[i].ID, model.AuditActionCreate, nil, transactions[i]); err != nil {
+			return 0, false, err
+		}
+	}
+	if err := s.planRepo.WithTx(tx).UpdateInvestmentPlanLastRun(ctx, plan.ID, lastRun); err != nil {
+		return 0, false, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, false, fmt.Errorf("commit transaction: %w", err)
+	}
+
+	if s.materializedInvalidator != nil {
+		s.materializedInvalidator.ScheduleRegeneration(ctx, transactions[0].Date, nil, "", plan.PortfolioFundID)
+	}
+	planLog.InfoContext(ctx, "investment plan transactions generated", "plan_id", plan.ID, "count", len(transactions))
+	return len(transactions), waiting, nil
+}
+
+// applyInvestmentPlanSchedule copies a validated amount and schedule onto plan.
+func applyInvestmentPlanSchedule(plan *model.InvestmentPlan, amount float64, frequency string, dayOfMonth int, startDate, endDate string) error {
+	start, err := time.Parse("2006-01-02", startDate)
+	if err != nil {
+		return fmt.Errorf("parse start date: %w", err)
+	}
+	plan.Amount = amount
+	plan.Frequency = model.InvestmentPlanFrequency(frequency)
+	plan.DayOfMonth = dayOfMonth
+	plan.StartDate = start.UTC()
+	plan.EndDate = nil
+	if endDate != "" {
+		end, err := time.Parse("2006-01-02", endDate)
+		if err != nil {
+			return fmt.Errorf("parse end date: %w", err)
+		}
+		end = end.UTC()
+		plan.EndDate = &end
+	}
+	return nil
+}
+
+// planRunDate returns the scheduled date of the n-th run period of a plan, counted from
+// the month of its start date. Days past the end of a shorter month fall on its last day.
+func planRunDate(plan model.InvestmentPlan, n int) time.Time {
+	first := time.Date(plan.StartDate.Year(), plan.StartDate.Month()+time.Month(n*plan.Frequency.Months()), 1, 0, 0, 0, 0, time.UTC)
+	lastDay := first.AddDate(0, 1, -1).Day()
+	return first.AddDate(0, 0, min(plan.DayOfMonth, lastDay)-1)
+}
+
+// dueRunDates returns the scheduled dates of a plan after its last run, up to and
+// including until, in date order.
+func dueRunDates(plan model.InvestmentPlan, until time.Time) []time.Time {
+	var dates []time.Time
+	for n := 0; ; n++ {
+		date := planRunDate(plan, n)
+		if date.After(until) || (plan.EndDate != nil && date.After(*plan.EndDate)) {
+			return dates
+		}
+		if date.Before(plan.StartDate) || (plan.LastRunDate != nil && !date.After(*plan.LastRunDate)) {
+			continue
+		}
+		dates = append(dates, date)
+	}
+}
+
+// withNextRunDate sets the first scheduled date after the plan's last run, or leaves it
+// nil when the plan has ended.
+func withNextRunDate(plan *model.InvestmentPlan) {
+	plan.NextRunDate = nil
+	for n := 0; ; n++ {
+		date := planRunDate(*plan, n)
+		if plan.EndDate != nil && date.After(*plan.EndDate) {
+			return
+		}
+		if date.Before(plan.StartDate) || (plan.LastRunDate != nil && !date.After(*plan.LastRunDate)) {
+			continue
+		}
+		plan.NextRunDate = &date
+		return
+	}
+}
+
+// priceOnOrAfter returns the first price dated on or after date from prices sorted by
+// ascending date.
+func priceOnOrAfter(prices []model.FundPrice, date time.Time) (model.FundPrice, bool) {
+	for _, p := range prices {
+		if !p.Date.Before(date) {
+			return p, true
+		}
+	}
+	return model.FundPrice{}, false
+}
@@ -0,0 +1,236 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/api/request"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/apperrors"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/model"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/repository"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/testutil"
+)
+
+func planDay(s string) time.Time {
+	d, _ := time.Parse("2006-01-02", s) //nolint:errcheck // Test literals are valid dates
+	return d
+}
+
+func TestInvestmentPlanService_RunInvestmentPlans(t *testing.T) {
+	t.Run("catches up every due run at that date's or the next price", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		svc := testutil.NewTestInvestmentPlanService(t, db)
+		ctx := context.Background()
+
+		portfolio := testutil.NewPortfolio().Build(t, db)
+		fund := testutil.NewFund().Build(t, db)
+		pf := testutil.NewPortfolioFund(portfolio.ID, fund.ID).Build(t, db)
+		for date, price := range map[string]float64{"2024-01-31": 10, "2024-03-01": 20, "2024-04-02": 25, "2024-04-30": 20} {
+			testutil.NewFundPrice(fund.ID).WithDate(planDay(date)).WithPrice(price).Build(t, db)
+		}
+
+		plan, err := svc.CreateInvestmentPlan(ctx, request.CreateInvestmentPlanRequest{
+			PortfolioFundID: pf.ID, Amount: 100, Frequency: "monthly", DayOfMonth: 31,
+			StartDate: "2024-01-01", EndDate: "2024-04-30",
+		})
+		if err != nil {
+			t.Fatalf("CreateInvestmentPlan: %v", err)
+		}
+		if plan.NextRunDate == nil || !plan.NextRunDate.Equal(planDay("2024-01-31")) {
+			t.Errorf("expected the first run on 2024-01-31, got %v", plan.NextRunDate)
+		}
+
+		result, err := svc.RunInvestmentPlans(ctx)
+		if err != nil {
+			t.Fatalf("RunInvestmentPlans: %v", err)
+		}
+		if result.Plans != 1 || result.Transactions != 4 || result.Waiting != 0 || result.Failed != 0 {
+			t.Errorf("unexpected run result: %+v", result)
+		}
+
+		transactions, err := testutil.NewTestTransactionService(t, db).GetTransactionsperPortfolio(context.Background(), portfolio.ID)
+		if err != nil {
+			t.Fatalf("GetTransactionsperPortfolio: %v", err)
+		}
+		want := map[string]float64{"2024-01-31": 10, "2024-03-01": 5, "2024-04-02": 4, "2024-04-30": 5}
+		if len(transactions) != len(want) {
+			t.Fatalf("expected %d transactions, got %+v", len(want), transactions)
+		}
+		for _, tx := range transactions {
+			shares, ok := want[tx.Date.Format("2006-01-02")]
+			if !ok || math.Abs(tx.Shares-shares) > 1e-9 || tx.Type != "buy" || !tx.PlanGenerated || tx.InvestmentPlanID != plan.ID {
+				t.Errorf("unexpected generated transaction: %+v", tx)
+			}
+		}
+
+		got, err := svc.GetInvestmentPlan(plan.ID)
+		if err != nil || got.LastRunDate == nil || !got.LastRunDate.Equal(planDay("2024-04-30")) || got.NextRunDate != nil {
+			t.Errorf("expected the plan to have ended after its last run, got %+v (%v)", got, err)
+		}
+
+		result, err = svc.RunInvestmentPlans(ctx)
+		if err != nil || result.Transactions != 0 {
+			t.Errorf("expected a second run to generate nothing, got %+v (%v)", result, err)
+		}
+	})
+
+	t.Run("waits for a price before buying a due run", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		svc := testutil.NewTestInvestmentPlanService(t, db)
+		ctx := context.Background()
+
+		portfolio := testutil.NewPortfolio().Build(t, db)
+		fund := testutil.NewFund().Build(t, db)
+		pf := testutil.NewPortfolioFund(portfolio.ID, fund.ID).Build(t, db)
+		testutil.NewFundPrice(fund.ID).WithDate(planDay("2024-01-15")).WithPrice(50).Build(t, db)
+
+		plan, err := svc.CreateInvestmentPlan(ctx, request.CreateInvestmentPlanRequest{
+			PortfolioFundID: pf.ID, Amount: 100, Frequency: "monthly", DayOfMonth: 15,
+			StartDate: "2024-01-01", EndDate: "2024-03-31",
+		})
+		if err != nil {
+			t.Fatalf("CreateInvestmentPlan: %v", err)
+		}
+
+		result, err := svc.RunInvestmentPlans(ctx)
+		if err != nil || result.Transactions != 1 || result.Waiting != 1 {
+			t.Errorf("expected one run bought and one waiting, got %+v (%v)", result, err)
+		}
+
+		testutil.NewFundPrice(fund.ID).WithDate(planDay("2024-02-20")).WithPrice(40).Build(t, db)
+		result, err = svc.RunInvestmentPlans(ctx)
+		if err != nil || result.Transactions != 1 || result.Waiting != 1 {
+			t.Errorf("expected the February run bought on the next price, got %+v (%v)", result, err)
+		}
+
+		got, err := svc.GetInvestmentPlan(plan.ID)
+		if err != nil || got.LastRunDate == nil || !got.LastRunDate.Equal(planDay("2024-02-15")) ||
+			got.NextRunDate == nil || !got.NextRunDate.Equal(planDay("2024-03-15")) {
+			t.Errorf("expected the March run to be next, got %+v (%v)", got, err)
+		}
+	})
+
+	t.Run("quarterly plans start in the month of the start date", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		svc := testutil.NewTestInvestmentPlanService(t, db)
+		ctx := context.Background()
+
+		portfolio := testutil.NewPortfolio().Build(t, db)
+		fund := testutil.NewFund().Build(t, db)
+		pf := testutil.NewPortfolioFund(portfolio.ID, fund.ID).Build(t, db)
+		for _, date := range []string{"2024-02-10", "2024-05-10", "2024-08-12", "2024-11-11"} {
+			testutil.NewFundPrice(fund.ID).WithDate(planDay(date)).WithPrice(10).Build(t, db)
+		}
+
+		// The February run falls before the start date and is skipped.
+		if _, err := svc.CreateInvestmentPlan(ctx, request.CreateInvestmentPlanRequest{
+			PortfolioFundID: pf.ID, Amount: 100, Frequency: "quarterly", DayOfMonth: 10,
+			StartDate: "2024-02-20", EndDate: "2024-12-31",
+		}); err != nil {
+			t.Fatalf("CreateInvestmentPlan: %v", err)
+		}
+
+		result, err := svc.RunInvestmentPlans(ctx)
+		if err != nil || result.Transactions != 3 {
+			t.Errorf("expected the May, August and November runs, got %+v (%v)", result, err)
+		}
+	})
+}
+
+func TestInvestmentPlanService_UpdateInvestmentPlan(t *testing.T) {
+	t.Run("re-enabling skips the runs of the paused period", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		svc := testutil.NewTestInvestmentPlanService(t, db)
+		ctx := context.Background()
+
+		portfolio := testutil.NewPortfolio().Build(t, db)
+		fund := testutil.NewFund().Build(t, db)
+		pf := testutil.NewPortfolioFund(portfolio.ID, fund.ID).Build(t, db)
+		today := time.Now().UTC().Truncate(24 * time.Hour)
+		testutil.NewFundPrice(fund.ID).WithDate(today.AddDate(0, -3, 0)).WithPrice(10).Build(t, db)
+		testutil.NewFundPrice(fund.ID).WithDate(today).WithPrice(10).Build(t, db)
+
+		dayOfMonth := today.Day()%28 + 1 // Never today, so no run is due today
+		disabled := false
+		plan, err := svc.CreateInvestmentPlan(ctx, request.CreateInvestmentPlanRequest{
+			PortfolioFundID: pf.ID, Amount: 100, Frequency: "monthly", DayOfMonth: dayOfMonth,
+			StartDate: today.AddDate(0, -3, 0).Format("2006-01-02"), Enabled: &disabled,
+		})
+		if err != nil {
+			t.Fatalf("CreateInvestmentPlan: %v", err)
+		}
+		if result, err := svc.RunInvestmentPlans(ctx); err != nil || result.Plans != 0 {
+			t.Errorf("expected disabled plans to be skipped, got %+v (%v)", result, err)
+		}
+
+		enabled := true
+		updated, err := svc.UpdateInvestmentPlan(ctx, plan.ID, request.UpdateInvestmentPlanRequest{
+			Amount: 200, Frequency: "monthly", DayOfMonth: dayOfMonth, StartDate: today.AddDate(0, -3, 0).Format("2006-01-02"), Enabled: &enabled,
+		})
+		if err != nil {
+			t.Fatalf("UpdateInvestmentPlan: %v", err)
+		}
+		if !updated.Enabled || updated.Amount != 200 || updated.LastRunDate == nil || !updated.NextRunDate.After(today) {
+			t.Errorf("expected the paused runs to be skipped, got %+v", updated)
+		}
+
+		result, err := svc.RunInvestmentPlans(ctx)
+		if err != nil || result.Plans != 1 || result.Transactions != 0 {
+			t.Errorf("expected no catch-up after re-enabling, got %+v (%v)", result, err)
+		}
+		if n := countRows(t, db, "audit_event", "entity_type = ? AND entity_id = ?", model.AuditEntityInvestmentPlan, plan.ID); n != 2 {
+			t.Errorf("expected create and update audit events, got %d", n)
+		}
+	})
+
+	t.Run("missing plan", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		svc := testutil.NewTestInvestmentPlanService(t, db)
+		_, err := svc.UpdateInvestmentPlan(context.Background(), testutil.MakeID(), request.UpdateInvestmentPlanRequest{
+			Amount: 100, Frequency: "monthly", DayOfMonth: 1, StartDate: "2024-01-01",
+		})
+		if !errors.Is(err, apperrors.ErrInvestmentPlanNotFound) {
+			t.Errorf("expected ErrInvestmentPlanNotFound, got %v", err)
+		}
+	})
+}
+
+func TestInvestmentPlanService_CreateAndDelete(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	svc := testutil.NewTestInvestmentPlanService(t, db)
+	ctx := context.Background()
+
+	if _, err := svc.CreateInvestmentPlan(ctx, request.CreateInvestmentPlanRequest{
+		PortfolioFundID: testutil.MakeID(), Amount: 100, Frequency: "monthly", DayOfMonth: 1, StartDate: "2024-01-01",
+	}); !errors.Is(err, apperrors.ErrPortfolioFundNotFound) {
+		t.Errorf("expected ErrPortfolioFundNotFound, got %v", err)
+	}
+
+	portfolio := testutil.NewPortfolio().Build(t, db)
+	fund := testutil.NewFund().Build(t, db)
+	pf := testutil.NewPortfolioFund(portfolio.ID, fund.ID).Build(t, db)
+	testutil.NewFundPrice(fund.ID).WithDate(planDay("2024-01-02")).WithPrice(10).Build(t, db)
+	plan, err := svc.CreateInvestmentPlan(ctx, request.CreateInvestmentPlanRequest{
+		PortfolioFundID: pf.ID, Amount: 100, Frequency: "yearly", DayOfMonth: 1, StartDate: "2024-01-01", EndDate: "2024-12-31",
+	})
+	if err != nil {
+		t.Fatalf("CreateInvestmentPlan: %v", err)
+	}
+	if _, err := svc.RunInvestmentPlans(ctx); err != nil {
+		t.Fatalf("RunInvestmentPlans: %v", err)
+	}
+
+	if err := svc.DeleteInvestmentPlan(ctx, plan.ID); err != nil {
+		t.Fatalf("DeleteInvestmentPlan: %v", err)
+	}
+	transactions, err := repository.NewTransactionRepository(db).GetTransactionsPerPortfolio(portfolio.ID, model.AllPortfolios())
+	if err != nil || len(transactions) != 1 || transactions[0].PlanGenerated {
+		t.Errorf("expected the generated transaction to remain as a regular one, got %+v (%v)", transactions, err)
+	}
+	if err := svc.DeleteInvestmentPlan(ctx, plan.ID); !errors.Is(err, apperrors.ErrInvestmentPlanNotFound) {
+		t.Errorf("expected ErrInvestmentPlanNotFound, got %v", err)
+	}
+}
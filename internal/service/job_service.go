@@ -0,0 +1,399 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/apperrors"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/logging"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/model"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/repository"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+var jobLog = logging.NewLogger("system")
+
+const (
+	// submittedJobTimeout bounds jobs started with Submit. Run inherits the caller's deadline.
+	submittedJobTimeout = 30 * time.Minute
+	// jobRetention is how long finished jobs are kept before PurgeFinishedJobs removes them.
+	jobRetention = 30 * 24 * time.Hour
+	// interruptedJobError is recorded on jobs that were unfinished when the process stopped.
+	interruptedJobError = "interrupted by server restart"
+)
+
+// JobFunc performs the work of a job. params holds the job's JSON-encoded parameters
+// (nil if it has none). A non-nil result is stored JSON-encoded on the job.
+// Progress can be reported with reportJobProgress on ctx.
+type JobFunc func(ctx context.Context, params json.RawMessage) (any, error)
+
+// JobService runs background work as tracked jobs. Every run is recorded in the job table
+// with its parameters, status, progress, result and error, so callers can follow work that
+// outlives the request that started it, and failed jobs can be retried with the same input.
+//
+// Jobs run in-process; a restart marks unfinished jobs as failed (see FailInterruptedJobs).
+type JobService struct {
+	jobRepo *repository.JobRepository
+
+	mu       sync.RWMutex
+	handlers map[model.JobType]JobFunc
+
+	// wg tracks jobs started with Submit so shutdown can wait for them.
+	wg sync.WaitGroup
+}
+
+// NewJobService creates a new JobService with the provided repository dependency.
+// Job types must be registered with Register before they can be started.
+func NewJobService(jobRepo *repository.JobRepository) *JobService {
+	return &JobService{
+		jobRepo:  jobRepo,
+		handlers: make(map[model.JobType]JobFunc),
+	}
+}
+
+// Register sets the function that performs jobs of the given type.
+func (s *JobService) Register(jobType model.JobType, fn JobFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers[jobType] = fn
+}
+
+func (s *JobService) handler(jobType model.JobType) (JobFunc, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	fn, ok := s.handlers[jobType]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", apperrors.ErrUnknownJobType, jobType)
+	}
+	return fn, nil
+}
+
+// GetJobs retrieves jobs matching the filters, newest first.
+func (s *JobService) GetJobs(filters *model.JobFilters) ([]model.Job, error) {
+	jobs, err := s.jobRepo.GetJobs(filters)
+	if err != nil {
+		return nil, fmt.Errorf("get jobs: %w", err)
+	}
+	return jobs, nil
+}
+
+// GetJob retrieves a job by ID. Returns ErrJobNotFound if it does not exist.
+func (s *JobService) GetJob(jobID string) (model.Job, error) {
+	job, err := s.jobRepo.GetJob(jobID)
+	if err != nil {
+		return model.Job{}, fmt.Errorf("get job: %w", err)
+	}
+	return job, nil
+}
+
+// Submit records a pending job and runs it in the background. It returns as soon as the
+// job is recorded. The job keeps the trace, user and request ID of ctx but not its
+// cancellation, and is bounded by submittedJobTimeout.
+func (s *JobService) Submit(ctx context.Context, jobType model.JobType, params any) (model.Job, error) {
+	return s.submit(ctx, jobType, params, "")
+}
+
+func (s *JobService) submit(ctx context.Context, jobType model.JobType, params any, retryOf string) (model.Job, error) {
+	fn, err := s.handler(jobType)
+	if err != nil {
+		return model.Job{}, err
+	}
+	job, err := s.newJob(ctx, jobType, params, retryOf, model.JobStatusPending)
+	if err != nil {
+		return model.Job{}, err
+	}
+
+	background := logging.WithRequestInfo(tracing.Detach(ctx),
+		logging.RequestIDFromContext(ctx), logging.IPFromContext(ctx), logging.UserAgentFromContext(ctx))
+	background = logging.WithUserID(background, logging.UserIDFromContext(ctx))
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		runCtx, cancel := context.WithTimeout(background, submittedJobTimeout)
+		defer cancel()
+		if err := s.jobRepo.StartJob(runCtx, job.ID, time.Now().UTC()); err != nil {
+			jobLog.Error("failed to mark job as running", "job_id", job.ID, "error", err)
+		}
+		_ = s.execute(runCtx, job, fn) //nolint:errcheck // The outcome is recorded on the job.
+	}()
+
+	jobLog.InfoContext(ctx, "job submitted", "job_id", job.ID, "type", job.Type)
+	return job, nil
+}
+
+// Run records a job and performs it synchronously, returning the finished job and the
+// error of the job function. Used by scheduled tasks and background loops that already
+// run on their own goroutine.
+func (s *JobService) Run(ctx context.Context, jobType model.JobType, params any) (model.Job, error) {
+	fn, err := s.handler(jobType)
+	if err != nil {
+		return model.Job{}, err
+	}
+	job, err := s.newJob(ctx, jobType, params, "", model.JobStatusRunning)
+	if err != nil {
+		return model.Job{}, err
+	}
+
+	runErr := s.execute(ctx, job, fn)
+
+	finished, err := s.jobRepo.GetJob(job.ID)
+	if err != nil {
+		jobLog.Warn("failed to reload finished job", "job_id", job.ID, "error", err)
+		finished = job
+	}
+	return finished, runErr
+}
+
+// Retry starts a new job with the type and parameters of a failed job.
+// Returns ErrJobNotFound if the job does not exist and ErrJobNotRetryable if it has not failed.
+func (s *JobService) Retry(ctx context.Context, jobID string) (model.Job, error) {
+	original, err := s.jobRepo.GetJob(jobID)
+	if err != nil {
+		return model.Job{}, fmt.Errorf("get job: %w", err)
+	}
+	if original.Status != string(model.JobStatusFailed) {
+		return model.Job{}, apperrors.ErrJobNotRetryable
+	}
+
+	job, err := s.submit(ctx, model.JobType(original.Type), original.Params, original.ID)
+	if err != nil {
+		return model.Job{}, fmt.Errorf("retry job: %w", err)
+	}
+	return job, nil
+}
+
+// FailInterruptedJobs marks jobs left pending or running by a previous process as failed,
+// so they show up as retryable. Call once at startup, before any job is started.
+func (s *JobService) FailInterruptedJobs(ctx context.Context) (int64, error) {
+	n, err := s.jobRepo.FailUnfinishedJobs(ctx, interruptedJobError, time.Now().UTC())
+	if err != nil {
+		return 0, fmt.Errorf("fail interrupted jobs: %w", err)
+	}
+	if n > 0 {
+		jobLog.Warn("marked interrupted jobs as failed", "count", n)
+	}
+	return n, nil
+}
+
+// PurgeFinishedJobs removes succeeded and failed jobs older than jobRetention.
+func (s *JobService) PurgeFinishedJobs(ctx context.Context) (int64, error) {
+	n, err := s.jobRepo.DeleteFinishedJobs(ctx, time.Now().UTC().Add(-jobRetention))
+	if err != nil {
+		return 0, fmt.Errorf("purge finished jobs: %w", err)
+	}
+	jobLog.Info("purged finished jobs", "count", n)
+	return n, nil
+}
+
+// Wait blocks until all jobs started with Submit have finished or ctx is done.
+func (s *JobService) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// newJob records a job in the given initial status.
+func (s *JobService) newJob(ctx context.Context, jobType model.JobType, params any, retryOf string, status model.JobStatus) (model.Job, error) {
+	job := model.Job{
+		ID:        uuid.New().String(),
+		Type:      string(jobType),
+		Status:    string(status),
+		RetryOf:   retryOf,
+		UserID:    logging.UserIDFromContext(ctx),
+		RequestID: logging.RequestIDFromContext(ctx),
+		CreatedAt: time.Now().UTC(),
+	}
+	if status == model.JobStatusRunning {
+		job.StartedAt = &job.CreatedAt
+	}
+
+	switch p := params.(type) {
+	case nil:
+	case json.RawMessage:
+		job.Params = p
+	default:
+		encoded, err := json.Marshal(p)
+		if err != nil {
+			return model.Job{}, fmt.Errorf("marshal job params: %w", err)
+		}
+		job.Params = encoded
+	}
+
+	if err := s.jobRepo.InsertJob(ctx, &job); err != nil {
+		return model.Job{}, fmt.Errorf("record job: %w", err)
+	}
+	return job, nil
+}
+
+// execute runs fn for a recorded job and stores its outcome. Panics are recovered and
+// recorded as failures.
+func (s *JobService) execute(ctx context.Context, job model.Job, fn JobFunc) (err error) {
+	ctx, span := tracing.Start(ctx, "job."+job.Type, attribute.String("job.id", job.ID))
+	start := time.Now()
+
+	ctx = context.WithValue(ctx, jobProgressKey{}, &jobProgress{repo: s.jobRepo, jobID: job.ID})
+
+	var result any
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+		tracing.End(span, err)
+		s.finish(job, result, err, time.Since(start))
+	}()
+
+	result, err = fn(ctx, job.Params)
+	return err
+}
+
+// finish records the outcome of a job. It uses a fresh context so that a job cancelled by
+// its deadline can still be marked as failed.
+func (s *JobService) finish(job model.Job, result any, runErr error, elapsed time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	status := model.JobStatusSucceeded
+	var errMsg string
+	if runErr != nil {
+		status = model.JobStatusFailed
+		errMsg = runErr.Error()
+	}
+
+	var encoded json.RawMessage
+	if result != nil {
+		var err error
+		if encoded, err = json.Marshal(result); err != nil {
+			jobLog.Warn("failed to marshal job result", "job_id", job.ID, "error", err)
+		}
+	}
+
+	if err := s.jobRepo.FinishJob(ctx, job.ID, status, encoded, errMsg, time.Now().UTC()); err != nil {
+		jobLog.Error("failed to record job outcome", "job_id", job.ID, "error", err)
+	}
+
+	if runErr != nil {
+		jobLog.Warn("job failed", "job_id", job.ID, "type", job.Type, "duration", elapsed.Round(time.Millisecond), "error", runErr)
+		return
+	}
+	jobLog.Info("job succeeded", "job_id", job.ID, "type", job.Type, "duration", elapsed.Round(time.Millisecond))
+}
+
+// jobProgressKey is the context key under which a running job's progress reporter is stored.
+type jobProgressKey struct{}
+
+// jobProgress writes a job's progress percentage, skipping writes that would not change it.
+type jobProgress struct {
+	repo  *repository.JobRepository
+	jobID string
+
+	mu   sync.Mutex
+	last int
+}
+
+// reportJobProgress records that done of total units of work are complete for the job
+// running on ctx. It is a no-op outside a job, so service methods can call it unconditionally.
+func reportJobProgress(ctx context.Context, done, total int) {
+	p, ok := ctx.Value(jobProgressKey{}).(*jobProgress)
+	if !ok || total <= 0 {
+		return
+	}
+	percent := min(100, done*100/total)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if percent <= p.last {
+		return
+	}
+	if err := p.repo.UpdateJobProgress(ctx, p.jobID, percent); err != nil {
+		jobLog.Warn("failed to update job progress", "job_id", p.jobID, "error", err)
+		return
+	}
+	p.last = percent
+}
+
+// RegisterJobHandlers registers the built-in job types on jobs.
+func RegisterJobHandlers(
+	jobs *JobService,
+	fundService *FundService,
+	ibkrService *IbkrService,
+	trashService *TrashService,
+	authService *AuthService,
+	materializedService *MaterializedService,
+	digestService *DigestService,
+	planService *InvestmentPlanService,
+) {
+	jobs.Register(model.JobTypeFundPriceUpdate, func(ctx context.Context, _ json.RawMessage) (any, error) {
+		return fundService.UpdateAllFundHistory(ctx)
+	})
+	jobs.Register(model.JobTypeIbkrImport, func(ctx context.Context, raw json.RawMessage) (any, error) {
+		var params model.IbkrImportParams
+		if len(raw) > 0 {
+			if err := json.Unmarshal(raw, &params); err != nil {
+				return nil, fmt.Errorf("decode params: %w", err)
+			}
+		}
+		if params.Scheduled {
+			config, err := ibkrService.GetIbkrConfig()
+			if err != nil {
+				return nil, err
+			}
+			if !config.Configured || !config.Enabled || !config.AutoImportEnabled {
+				return map[string]string{"skipped": "automatic import is disabled"}, nil
+			}
+		}
+		imported, skipped, err := ibkrService.ImportFlexReport(ctx)
+		return map[string]int{"imported": imported, "skipped": skipped}, err
+	})
+	jobs.Register(model.JobTypeTrashPurge, func(ctx context.Context, _ json.RawMessage) (any, error) {
+		return trashService.PurgeExpiredTrash(ctx)
+	})
+	jobs.Register(model.JobTypeSessionPurge, func(ctx context.Context, _ json.RawMessage) (any, error) {
+		purged, err := authService.PurgeExpiredSessions(ctx)
+		return map[string]int64{"purged": purged}, err
+	})
+	jobs.Register(model.JobTypeJobPurge, func(ctx context.Context, _ json.RawMessage) (any, error) {
+		purged, err := jobs.PurgeFinishedJobs(ctx)
+		return map[string]int64{"purged": purged}, err
+	})
+	jobs.Register(model.JobTypeEmailDigest, func(ctx context.Context, raw json.RawMessage) (any, error) {
+		var params model.EmailDigestParams
+		if len(raw) > 0 {
+			if err := json.Unmarshal(raw, &params); err != nil {
+				return nil, fmt.Errorf("decode params: %w", err)
+			}
+		}
+		sent, err := digestService.SendDigest(ctx, params.Scheduled)
+		if err == nil && !sent {
+			return map[string]string{"skipped": "email digest is disabled"}, nil
+		}
+		return map[string]bool{"sent": sent}, err
+	})
+	jobs.Register(model.JobTypeInvestmentPlans, func(ctx context.Context, _ json.RawMessage) (any, error) {
+		return planService.RunInvestmentPlans(ctx)
+	})
+	jobs.Register(model.JobTypeMaterializedRegen, func(ctx context.Context, raw json.RawMessage) (any, error) {
+		var params model.MaterializedRegenParams
+		if err := json.Unmarshal(raw, &params); err != nil {
+			return nil, fmt.Errorf("decode params: %w", err)
+		}
+		startDate, err := time.Parse("2006-01-02", params.StartDate)
+		if err != nil {
+			return nil, fmt.Errorf("parse start date: %w", err)
+		}
+		return nil, materializedService.RegenerateMaterializedTable(ctx, startDate, params.PortfolioIDs, params.FundID, params.PortfolioFundID)
+	})
+}
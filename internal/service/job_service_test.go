@@ -0,0 +1,207 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/apperrors"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/model"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/testutil"
+)
+
+// waitForJobs waits for every submitted job to finish.
+func waitForJobs(t *testing.T, wait func(context.Context) error) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := wait(ctx); err != nil {
+		t.Fatalf("jobs did not finish: %v", err)
+	}
+}
+
+//nolint:gocyclo // Test function with multiple subtests and assertions.
+func TestJobService_Run(t *testing.T) {
+	t.Run("records parameters and result of a successful job", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		svc := testutil.NewTestJobService(t, db)
+
+		var gotParams model.MaterializedRegenParams
+		svc.Register(model.JobTypeMaterializedRegen, func(_ context.Context, params json.RawMessage) (any, error) {
+			if err := json.Unmarshal(params, &gotParams); err != nil {
+				return nil, err
+			}
+			return map[string]int{"rows": 3}, nil
+		})
+
+		job, err := svc.Run(context.Background(), model.JobTypeMaterializedRegen,
+			model.MaterializedRegenParams{StartDate: "2024-01-01", FundID: "f1"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if gotParams.StartDate != "2024-01-01" || gotParams.FundID != "f1" {
+			t.Errorf("job function received unexpected params: %+v", gotParams)
+		}
+		if job.Status != string(model.JobStatusSucceeded) || job.Progress != 100 {
+			t.Errorf("expected succeeded job at 100%%, got %s at %d%%", job.Status, job.Progress)
+		}
+		if string(job.Result) != `{"rows":3}` {
+			t.Errorf("expected result to be recorded, got %s", job.Result)
+		}
+		if job.StartedAt == nil || job.FinishedAt == nil {
+			t.Errorf("expected start and finish times, got %v %v", job.StartedAt, job.FinishedAt)
+		}
+	})
+
+	t.Run("records the error of a failed job", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		svc := testutil.NewTestJobService(t, db)
+		svc.Register(model.JobTypeTrashPurge, func(context.Context, json.RawMessage) (any, error) {
+			return nil, errors.New("disk on fire")
+		})
+
+		job, err := svc.Run(context.Background(), model.JobTypeTrashPurge, nil)
+		if err == nil || err.Error() != "disk on fire" {
+			t.Fatalf("expected the job error to be returned, got %v", err)
+		}
+		if job.Status != string(model.JobStatusFailed) || job.Error != "disk on fire" {
+			t.Errorf("expected failed job with error, got %+v", job)
+		}
+	})
+
+	t.Run("records a panic as a failure", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		svc := testutil.NewTestJobService(t, db)
+		svc.Register(model.JobTypeTrashPurge, func(context.Context, json.RawMessage) (any, error) {
+			panic("unexpected")
+		})
+
+		job, err := svc.Run(context.Background(), model.JobTypeTrashPurge, nil)
+		if err == nil {
+			t.Fatal("expected an error")
+		}
+		if job.Status != string(model.JobStatusFailed) {
+			t.Errorf("expected failed job, got %s", job.Status)
+		}
+	})
+
+	t.Run("unregistered type", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		svc := testutil.NewTestJobService(t, db)
+
+		if _, err := svc.Run(context.Background(), model.JobTypeIbkrImport, nil); !errors.Is(err, apperrors.ErrUnknownJobType) {
+			t.Errorf("expected ErrUnknownJobType, got %v", err)
+		}
+	})
+}
+
+//nolint:gocyclo // Test function with multiple subtests and assertions.
+func TestJobService_SubmitAndRetry(t *testing.T) {
+	t.Run("submitted job runs in the background", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		svc := testutil.NewTestJobService(t, db)
+		release := make(chan struct{})
+		svc.Register(model.JobTypeFundPriceUpdate, func(context.Context, json.RawMessage) (any, error) {
+			<-release
+			return nil, nil
+		})
+
+		job, err := svc.Submit(context.Background(), model.JobTypeFundPriceUpdate, nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if job.Status != string(model.JobStatusPending) {
+			t.Errorf("expected pending job, got %s", job.Status)
+		}
+
+		close(release)
+		waitForJobs(t, svc.Wait)
+
+		got, err := svc.GetJob(job.ID)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got.Status != string(model.JobStatusSucceeded) {
+			t.Errorf("expected succeeded job, got %s", got.Status)
+		}
+	})
+
+	t.Run("failed job is retried with the same parameters", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		svc := testutil.NewTestJobService(t, db)
+		attempts := 0
+		var params []string
+		svc.Register(model.JobTypeMaterializedRegen, func(_ context.Context, p json.RawMessage) (any, error) {
+			attempts++
+			params = append(params, string(p))
+			if attempts == 1 {
+				return nil, errors.New("database is locked")
+			}
+			return nil, nil
+		})
+
+		failed, _ := svc.Run(context.Background(), model.JobTypeMaterializedRegen, model.MaterializedRegenParams{StartDate: "2024-02-01"})
+
+		retry, err := svc.Retry(context.Background(), failed.ID)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		waitForJobs(t, svc.Wait)
+
+		got, _ := svc.GetJob(retry.ID)
+		if got.Status != string(model.JobStatusSucceeded) || got.RetryOf != failed.ID {
+			t.Errorf("expected succeeded retry of %s, got %+v", failed.ID, got)
+		}
+		if len(params) != 2 || params[0] != params[1] {
+			t.Errorf("expected both attempts to get the same params, got %v", params)
+		}
+	})
+
+	t.Run("only failed jobs can be retried", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		svc := testutil.NewTestJobService(t, db)
+		svc.Register(model.JobTypeTrashPurge, func(context.Context, json.RawMessage) (any, error) { return nil, nil })
+
+		job, _ := svc.Run(context.Background(), model.JobTypeTrashPurge, nil)
+		if _, err := svc.Retry(context.Background(), job.ID); !errors.Is(err, apperrors.ErrJobNotRetryable) {
+			t.Errorf("expected ErrJobNotRetryable, got %v", err)
+		}
+		if _, err := svc.Retry(context.Background(), testutil.MakeID()); !errors.Is(err, apperrors.ErrJobNotFound) {
+			t.Errorf("expected ErrJobNotFound, got %v", err)
+		}
+	})
+
+	t.Run("interrupted jobs are marked failed", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		svc := testutil.NewTestJobService(t, db)
+		started := make(chan struct{})
+		release := make(chan struct{})
+		svc.Register(model.JobTypeFundPriceUpdate, func(context.Context, json.RawMessage) (any, error) {
+			close(started)
+			<-release
+			return nil, nil
+		})
+
+		job, _ := svc.Submit(context.Background(), model.JobTypeFundPriceUpdate, nil)
+		<-started
+
+		// A new process starting up sees the still-running job.
+		restarted := testutil.NewTestJobService(t, db)
+		n, err := restarted.FailInterruptedJobs(context.Background())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if n != 1 {
+			t.Errorf("expected 1 interrupted job, got %d", n)
+		}
+		got, _ := restarted.GetJob(job.ID)
+		if got.Status != string(model.JobStatusFailed) || got.Error == "" {
+			t.Errorf("expected failed job with reason, got %+v", got)
+		}
+
+		close(release)
+		waitForJobs(t, svc.Wait)
+	})
+}
@@ -176,6 +176,48 @@ func (s *MaterializedService) calculateDailyPortfolioHistory(
 	return history, nil
 }
 
+// rollupPortfolioHistory downsamples daily history to one point per portfolio per week or
+// month: the portfolio's last day within the period, matching the rows kept in
+// portfolio_history_rollup. Daily history is returned unchanged. Dates left without any
+// portfolio are dropped.
+func rollupPortfolioHistory(history []model.PortfolioHistory, interval model.HistoryInterval) []model.PortfolioHistory {
+	if interval != model.HistoryIntervalWeek && interval != model.HistoryIntervalMonth {
+		return history
+	}
+
+	type periodKey struct {
+		portfolioID string
+		start       time.Time
+	}
+	seen := make(map[periodKey]bool)
+	kept := make([]model.PortfolioHistory, 0, len(history))
+
+	// Walk backwards so the first entry seen for a period is its last day.
+	for i := len(history) - 1; i >= 0; i-- {
+		date, err := time.Parse("2006-01-02", history[i].Date)
+		if err != nil {
+			continue
+		}
+		start := interval.PeriodStart(date)
+
+		var summaries []model.PortfolioSummary
+		for _, summary := range history[i].Portfolios {
+			key := periodKey{summary.ID, start}
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			summaries = append(summaries, summary)
+		}
+		if len(summaries) > 0 {
+			kept = append(kept, model.PortfolioHistory{Date: history[i].Date, Portfolios: summaries})
+		}
+	}
+
+	slices.Reverse(kept)
+	return kept
+}
+
 // calculatePortfolioSummaryForDate computes metrics for all portfolios on a specific date.
 // This method iterates through each portfolio and calculates its summary metrics, skipping
 // portfolios that have no transactions or whose first transaction is after the given date.
@@ -398,11 +440,7 @@ func (s *MaterializedService) calculateFundsForDate(
 			return nil, fmt.Errorf("calculate fund entry: %w", err)
 		}
 
-		// Skip entries with no meaningful data — zero shares AND no financial activity.
-		// This excludes dates before the first buy, but preserves sold-fund rows
-		// that carry cumulative realized_gain, dividends, sale_proceeds, or original_cost.
-		if entry.Shares == 0 && entry.RealizedGain == 0 && entry.Dividends == 0 &&
-			entry.SaleProceeds == 0 && entry.OriginalCost == 0 && entry.Fees == 0 {
+		if !hasFundActivity(entry) {
 			continue
 		}
 
@@ -412,13 +450,195 @@ func (s *MaterializedService) calculateFundsForDate(
 	return funds, nil
 }
 
+// hasFundActivity reports whether an entry carries meaningful data. Entries with zero
+// shares AND no financial activity are skipped: this excludes dates before the first buy,
+// but preserves sold-fund rows that carry cumulative realized_gain, dividends,
+// sale_proceeds, or original_cost.
+func hasFundActivity(entry model.FundHistoryEntry) bool {
+	return entry.Shares != 0 || entry.RealizedGain != 0 || entry.Dividends != 0 ||
+		entry.SaleProceeds != 0 || entry.OriginalCost != 0 || entry.Fees != 0
+}
+
+// fundState is the cumulative position of one portfolio fund at the end of a day,
+// unrounded. It is what a FundHistoryEntry is derived from, and what
+// calculateFundHistoryFromSeed carries from one day to the next.
+type fundState struct {
+	shares       float64
+	cost         float64
+	fees         float64
+	price        float64
+	dividends    float64
+	realizedGain float64
+	saleProceeds float64
+	originalCost float64
+}
+
+// fundStateFromEntry restores a fundState from a materialized row. Stored values are
+// rounded to RoundingPrecision, which is well below the precision of the reported figures.
+func fundStateFromEntry(e model.FundHistoryEntry) fundState {
+	return fundState{
+		shares:       e.Shares,
+		cost:         e.Cost,
+		fees:         e.Fees,
+		price:        e.Price,
+		dividends:    e.Dividends,
+		realizedGain: e.RealizedGain,
+		saleProceeds: e.SaleProceeds,
+		originalCost: e.OriginalCost,
+	}
+}
+
+// applyTransaction applies one transaction to the running shares, cost and fees using
+// the same rules as FundService.calculateFundMetrics.
+func (st *fundState) applyTransaction(transaction model.Transaction) error {
+	switch transaction.Type {
+	case "buy":
+		st.shares += transaction.Shares
+		st.cost += transaction.Shares * transaction.CostPerShare
+	case "dividend":
+		// Reinvested shares are counted on the dividend's ex-date, not here.
+	case "sell":
+		st.shares -= transaction.Shares
+		if st.shares > 0.0 {
+			st.cost = (st.cost / (st.shares + transaction.Shares)) * st.shares
+		} else {
+			st.cost = 0.0
+		}
+	case "fee":
+		st.cost += transaction.CostPerShare
+		st.fees += transaction.CostPerShare
+	default:
+		return fmt.Errorf("unsupported transaction type: %s", transaction.Type)
+	}
+	return nil
+}
+
+// entry builds the rounded FundHistoryEntry for this state. Date is left unset.
+func (st fundState) entry(pf model.PortfolioFundResponse) model.FundHistoryEntry {
+	var value float64
+	if st.price > 0 {
+		value = st.shares * st.price
+	}
+	unrealizedGain := value - st.cost
+
+	return model.FundHistoryEntry{
+		PortfolioFundID: pf.ID,
+		FundID:          pf.FundID,
+		FundName:        pf.FundName,
+		Shares:          round(st.shares),
+		Price:           round(st.price),
+		Value:           round(value),
+		Cost:            round(st.cost),
+		RealizedGain:    round(st.realizedGain),
+		UnrealizedGain:  round(unrealizedGain),
+		TotalGainLoss:   round(unrealizedGain + st.realizedGain),
+		Dividends:       round(st.dividends),
+		Fees:            round(st.fees),
+		SaleProceeds:    round(st.saleProceeds),
+		OriginalCost:    round(st.originalCost),
+	}
+}
+
+// calculateFundHistoryFromSeed computes the daily entries of a single portfolio fund from
+// startDate to endDate by rolling its state forward one day at a time, starting from seed
+// (the fund's materialized row on the day before startDate). Each day only applies that
+// day's transactions, dividends, realized gains and price, so the cost is proportional to
+// the number of days and events in the range rather than to the whole history.
+//
+// A day is calculated in full with calculateFundState instead when there is no state to
+// roll forward (seed is nil on the first day) and on the ex-date of a reinvested dividend:
+// calculateFundMetrics adds all reinvested shares before replaying sells, so a new
+// reinvestment changes how earlier sells scaled the cost basis.
+//
+// Returns the entries with Date set, skipping days without fund activity.
+func (s *MaterializedService) calculateFundHistoryFromSeed(
+	pf model.PortfolioFundResponse,
+	data *PortfolioData,
+	realizedGains []model.RealizedGainLoss,
+	seed *model.FundHistoryEntry,
+	startDate, endDate time.Time,
+) ([]model.FundHistoryEntry, error) {
+	const layout = "2006-01-02"
+
+	// Index the events in the range by day, preserving their order within a day.
+	txByDate := make(map[string][]model.Transaction)
+	for _, tx := range data.TransactionsByPF[pf.ID] {
+		if !tx.Date.Before(startDate) {
+			txByDate[tx.Date.Format(layout)] = append(txByDate[tx.Date.Format(layout)], tx)
+		}
+	}
+	dividendByDate := make(map[string]float64)
+	reinvestedOn := make(map[string]bool)
+	for _, div := range data.DividendsByPF[pf.ID] {
+		if div.ExDividendDate.Before(startDate) {
+			continue
+		}
+		dividendByDate[div.ExDividendDate.Format(layout)] += div.TotalAmount
+		if div.ReinvestmentTransactionID != "" {
+			reinvestedOn[div.ExDividendDate.Format(layout)] = true
+		}
+	}
+	realizedByDate := make(map[string][]model.RealizedGainLoss)
+	for _, r := range realizedGains {
+		if !r.TransactionDate.Before(startDate) {
+			realizedByDate[r.TransactionDate.Format(layout)] = append(realizedByDate[r.TransactionDate.Format(layout)], r)
+		}
+	}
+	priceByDate := make(map[string]float64)
+	for _, p := range data.FundPricesByFund[pf.FundID] {
+		if !p.Date.Before(startDate) {
+			priceByDate[p.Date.Format(layout)] = p.Price
+		}
+	}
+
+	var state fundState
+	haveState := seed != nil
+	if haveState {
+		state = fundStateFromEntry(*seed)
+	}
+
+	var entries []model.FundHistoryEntry
+	for date := startDate; !date.After(endDate); date = date.AddDate(0, 0, 1) {
+		key := date.Format(layout)
+
+		if !haveState || reinvestedOn[key] {
+			var err error
+			state, err = s.calculateFundState(pf, date, data, realizedGains)
+			if err != nil {
+				return nil, fmt.Errorf("calculate fund state for date %s: %w", key, err)
+			}
+			haveState = true
+		} else {
+			for _, tx := range txByDate[key] {
+				if err := state.applyTransaction(tx); err != nil {
+					return nil, fmt.Errorf("apply transaction on %s: %w", key, err)
+				}
+			}
+			state.dividends += dividendByDate[key]
+			for _, r := range realizedByDate[key] {
+				state.realizedGain += r.RealizedGainLoss
+				state.saleProceeds += r.SaleProceeds
+				state.originalCost += r.CostBasis
+			}
+			if price, ok := priceByDate[key]; ok {
+				state.price = price
+			}
+		}
+
+		entry := state.entry(pf)
+		if !hasFundActivity(entry) {
+			continue
+		}
+		entry.Date = date
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
 // calculateFundEntry computes complete metrics for a single fund on a specific date.
-// This method performs the full calculation pipeline for one fund:
-//   - Processes dividend shares (accounting for reinvestments)
-//   - Calculates fund metrics (shares, price, value, cost, gains)
-//   - Aggregates dividend amounts
-//   - Aggregates realized gains/losses
-//   - Rounds all monetary values to two decimals
+// This method performs the full calculation pipeline for one fund via calculateFundState
+// and rounds all values to RoundingPrecision.
 //
 // Parameters:
 //   - pf: The portfolio fund to calculate metrics for
@@ -428,13 +648,31 @@ func (s *MaterializedService) calculateFundsForDate(
 //
 // Returns a FundHistoryEntry with all fields populated: PortfolioFundID, FundID, FundName,
 // Shares, Price, Value, Cost, RealizedGain, UnrealizedGain, TotalGainLoss, Dividends, Fees.
-// All monetary values are rounded to two decimal places.
 func (s *MaterializedService) calculateFundEntry(
 	pf model.PortfolioFundResponse,
 	date time.Time,
 	data *PortfolioData,
 	realizedGains []model.RealizedGainLoss,
 ) (model.FundHistoryEntry, error) {
+	state, err := s.calculateFundState(pf, date, data, realizedGains)
+	if err != nil {
+		return model.FundHistoryEntry{}, err
+	}
+	return state.entry(pf), nil
+}
+
+// calculateFundState computes the unrounded state of a single fund on a specific date
+// from its full history:
+//   - Processes dividend shares (accounting for reinvestments)
+//   - Calculates fund metrics (shares, price, cost, fees)
+//   - Aggregates dividend amounts
+//   - Aggregates realized gains/losses
+func (s *MaterializedService) calculateFundState(
+	pf model.PortfolioFundResponse,
+	date time.Time,
+	data *PortfolioData,
+	realizedGains []model.RealizedGainLoss,
+) (fundState, error) {
 
 	// Calculate dividend shares
 	dividendSharesMap, err := s.dividendService.processDividendSharesForDate(
@@ -443,7 +681,7 @@ func (s *MaterializedService) calculateFundEntry(
 		date,
 	)
 	if err != nil {
-		return model.FundHistoryEntry{}, fmt.Errorf("process dividend shares: %w", err)
+		return fundState{}, fmt.Errorf("process dividend shares: %w", err)
 	}
 
 	// Calculate fund metrics
@@ -457,7 +695,7 @@ func (s *MaterializedService) calculateFundEntry(
 		false,
 	)
 	if err != nil {
-		return model.FundHistoryEntry{}, fmt.Errorf("calculate fund metrics: %w", err)
+		return fundState{}, fmt.Errorf("calculate fund metrics: %w", err)
 	}
 
 	// Calculate dividend amount
@@ -466,7 +704,7 @@ func (s *MaterializedService) calculateFundEntry(
 		date,
 	)
 	if err != nil {
-		return model.FundHistoryEntry{}, fmt.Errorf("process dividends: %w", err)
+		return fundState{}, fmt.Errorf("process dividends: %w", err)
 	}
 
 	// Calculate realized gains
@@ -475,24 +713,17 @@ func (s *MaterializedService) calculateFundEntry(
 		date,
 	)
 	if err != nil {
-		return model.FundHistoryEntry{}, fmt.Errorf("process realized gain loss: %w", err)
+		return fundState{}, fmt.Errorf("process realized gain loss: %w", err)
 	}
 
-	// Build entry with rounding
-	return model.FundHistoryEntry{
-		PortfolioFundID: pf.ID,
-		FundID:          pf.FundID,
-		FundName:        pf.FundName,
-		Shares:          round(fundMetrics.Shares),
-		Price:           round(fundMetrics.LatestPrice),
-		Value:           round(fundMetrics.Value),
-		Cost:            round(fundMetrics.Cost),
-		RealizedGain:    round(realizedGain),
-		UnrealizedGain:  round(fundMetrics.UnrealizedGain),
-		TotalGainLoss:   round(fundMetrics.UnrealizedGain + realizedGain),
-		Dividends:       round(dividendAmount),
-		Fees:            round(fundMetrics.Fees),
-		SaleProceeds:    round(saleProceeds),
-		OriginalCost:    round(costBasis),
+	return fundState{
+		shares:       fundMetrics.Shares,
+		cost:         fundMetrics.Cost,
+		fees:         fundMetrics.Fees,
+		price:        fundMetrics.LatestPrice,
+		dividends:    dividendAmount,
+		realizedGain: realizedGain,
+		saleProceeds: saleProceeds,
+		originalCost: costBasis,
 	}, nil
 }
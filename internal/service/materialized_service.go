@@ -3,16 +3,23 @@ package service
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"math"
+	"slices"
 	"sort"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/apperrors"
 	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/logging"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/metrics"
 	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/model"
 	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/repository"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 var matLog = logging.NewLogger("system")
@@ -22,6 +29,9 @@ var matLog = logging.NewLogger("system")
 // rather than on *MaterializedService directly, breaking the cyclic dependency.
 type MaterializedInvalidator interface {
 	RegenerateMaterializedTable(ctx context.Context, startDate time.Time, portfolioIDs []string, fundID, portfolioFundID string) error
+	// ScheduleRegeneration regenerates in the background, outliving ctx. Failures are
+	// recorded on the regeneration job rather than returned.
+	ScheduleRegeneration(ctx context.Context, startDate time.Time, portfolioIDs []string, fundID, portfolioFundID string)
 }
 
 // TransactionMetrics aggregates calculated metrics from processing transactions for a specific date.
@@ -48,14 +58,15 @@ type MaterializedService struct {
 	dataLoaderService       *DataLoaderService
 	portfolioService        *PortfolioService
 	pfRepo                  *repository.PortfolioFundRepository
-
-	// regenMu protects regenInFlight from concurrent access.
-	regenMu sync.Mutex
-	// regenInFlight tracks which portfolio IDs currently have a background
-	// regeneration job running and the startDate they're regenerating from.
-	// If a new request arrives with an earlier startDate, the existing entry
-	// is updated so the next job picks up the earlier date.
-	regenInFlight map[string]time.Time
+	jobService              *JobService
+	events                  EventPublisher
+	// valueDropPercent is the daily loss, as a percentage of the previous day's value,
+	// at which a portfolio.value_drop event is published.
+	valueDropPercent float64
+	alerts           AlertEvaluator
+
+	// regenWake signals RunRegenWorker that a regeneration was queued.
+	regenWake chan struct{}
 	// regenWriteMu serializes all materialized regen writes. Acquired inside
 	// RegenerateMaterializedTable so both write-path hooks and read-path
 	// fallback are serialized. SQLite only supports one writer at a time.
@@ -71,6 +82,12 @@ func MaterializedWithMaterializedRepository(r *repository.MaterializedRepository
 	return func(s *MaterializedService) { s.materializedRepo = r }
 }
 
+// MaterializedWithJobService injects the JobService used to track background regenerations.
+// Without it, regenerations still run but are not recorded as jobs.
+func MaterializedWithJobService(j *JobService) MaterializedServiceOption {
+	return func(s *MaterializedService) { s.jobService = j }
+}
+
 // MaterializedWithPortfolioRepository injects the PortfolioRepository dependency.
 func MaterializedWithPortfolioRepository(r *repository.PortfolioRepository) MaterializedServiceOption {
 	return func(s *MaterializedService) { s.portfolioRepo = r }
@@ -115,25 +132,38 @@ func MaterializedWithPortfolioFundRepository(r *repository.PortfolioFundReposito
 // inject dependencies. Only the options relevant to the calling context need to be provided;
 // unset fields remain nil and will panic if the corresponding method is called.
 func NewMaterializedService(db *sql.DB, opts ...MaterializedServiceOption) *MaterializedService {
-	s := &MaterializedService{db: db}
+	s := &MaterializedService{db: db, regenWake: make(chan struct{}, 1)}
 	for _, opt := range opts {
 		opt(s)
 	}
 	return s
 }
 
+// SetEventPublisher injects the EventPublisher that receives portfolio.value_drop events,
+// published when a regenerated portfolio lost at least valueDropPercent in a day.
+func (s *MaterializedService) SetEventPublisher(p EventPublisher, valueDropPercent float64) {
+	s.events = p
+	s.valueDropPercent = valueDropPercent
+}
+
+// SetAlertEvaluator injects the AlertEvaluator that re-evaluates a portfolio's alert rules
+// after its materialized history was regenerated.
+func (s *MaterializedService) SetAlertEvaluator(e AlertEvaluator) {
+	s.alerts = e
+}
+
 // =============================================================================
 // PORTFOLIO HISTORY METHODS
 // =============================================================================
 
-// GetPortfolioHistoryMaterialized retrieves daily portfolio valuations from the materialized view table.
+// GetPortfolioHistoryMaterialized retrieves portfolio valuations from the portfolio snapshot tables.
 //
 // This method provides significantly faster performance compared to GetPortfolioHistory() by querying
 // pre-calculated daily snapshots instead of recomputing values from raw transactions, dividends, and prices.
 //
 // The method performs the following:
 //  1. Resolves portfolio(s) from the ID parameter using GetPortfoliosForRequest
-//  2. Queries the materialized view (delegates to materializedRepo.GetMaterializedHistory)
+//  2. Queries the daily snapshots or the weekly/monthly rollups (delegates to materializedRepo.GetMaterializedHistory)
 //  3. Groups results by date using a callback pattern
 //  4. Transforms grouped records into PortfolioSummary structs with portfolio metadata
 //
@@ -146,13 +176,14 @@ func NewMaterializedService(db *sql.DB, opts ...MaterializedServiceOption) *Mate
 //   - requestedStartDate: First date to include in returned results
 //   - requestedEndDate: Last date to include in returned results
 //   - portfolioID: Optional portfolio ID. If empty, returns all active portfolios.
+//   - interval: Daily points, or one point per week or month
 //
 // Returns:
 // A slice of PortfolioHistory structs, one per date, each containing portfolio summaries for that date.
-func (s *MaterializedService) GetPortfolioHistoryMaterialized(requestedStartDate, requestedEndDate time.Time, portfolioID string) ([]model.PortfolioHistory, error) {
-	matLog.Debug("retrieving portfolio history from materialized view", "portfolioID", portfolioID, "startDate", requestedStartDate.Format("2006-01-02"), "endDate", requestedEndDate.Format("2006-01-02"))
+func (s *MaterializedService) GetPortfolioHistoryMaterialized(ctx context.Context, requestedStartDate, requestedEndDate time.Time, portfolioID string, interval model.HistoryInterval) ([]model.PortfolioHistory, error) {
+	matLog.Debug("retrieving portfolio history from materialized view", "portfolioID", portfolioID, "interval", interval, "startDate", requestedStartDate.Format("2006-01-02"), "endDate", requestedEndDate.Format("2006-01-02"))
 
-	portfolios, err := s.portfolioService.GetPortfoliosForRequest(portfolioID)
+	portfolios, err := s.portfolioService.GetPortfoliosForRequest(ctx, portfolioID)
 	if err != nil {
 		return nil, fmt.Errorf("get portfolios: %w", err)
 	}
@@ -169,6 +200,7 @@ func (s *MaterializedService) GetPortfolioHistoryMaterialized(requestedStartDate
 
 	err = s.materializedRepo.GetMaterializedHistory(
 		portfolioIDs,
+		interval,
 		requestedStartDate,
 		requestedEndDate,
 		func(record model.PortfolioHistoryMaterialized) error {
@@ -246,10 +278,10 @@ func (s *MaterializedService) GetPortfolioHistoryMaterialized(requestedStartDate
 //
 // Returns a slice of PortfolioHistory, one entry per date, each containing portfolio
 // summaries with metrics like TotalValue, TotalCost, TotalGainLoss, etc.
-func (s *MaterializedService) GetPortfolioHistory(requestedStartDate, requestedEndDate time.Time, portfolioID string) ([]model.PortfolioHistory, error) {
+func (s *MaterializedService) GetPortfolioHistory(ctx context.Context, requestedStartDate, requestedEndDate time.Time, portfolioID string) ([]model.PortfolioHistory, error) {
 	matLog.Debug("calculating portfolio history on-demand", "portfolioID", portfolioID, "startDate", requestedStartDate.Format("2006-01-02"), "endDate", requestedEndDate.Format("2006-01-02"))
 
-	portfolios, err := s.portfolioService.GetPortfoliosForRequest(portfolioID)
+	portfolios, err := s.portfolioService.GetPortfoliosForRequest(ctx, portfolioID)
 	if err != nil {
 		return nil, fmt.Errorf("get portfolios: %w", err)
 	}
@@ -300,18 +332,25 @@ func (s *MaterializedService) GetPortfolioHistory(requestedStartDate, requestedE
 //   - Reliable on-demand calculation as fallback when data is stale or missing (~50ms)
 //
 // Parameters:
+//   - ctx: Request context; background regeneration joins its trace
 //   - startDate: First date to include in results
 //   - endDate: Last date to include in results (typically today)
 //   - portfolioID: Optional portfolio ID. Empty string returns all active portfolios.
+//   - interval: Daily points, or one point per week or month (the last day of each period)
 //
 // Returns complete portfolio history from startDate to endDate, using the fastest available method.
+// On-demand results are downsampled to the interval so both paths return the same points.
 func (s *MaterializedService) GetPortfolioHistoryWithFallback(
+	ctx context.Context,
 	startDate, endDate time.Time,
 	portfolioID string,
+	interval model.HistoryInterval,
 ) ([]model.PortfolioHistory, error) {
-	matLog.Debug("getting portfolio history with fallback", "start_date", startDate.Format("2006-01-02"), "end_date", endDate.Format("2006-01-02"), "portfolio_id", portfolioID)
+	ctx, span := tracing.Start(ctx, "MaterializedService.GetPortfolioHistoryWithFallback")
+	defer span.End()
+	matLog.Debug("getting portfolio history with fallback", "start_date", startDate.Format("2006-01-02"), "end_date", endDate.Format("2006-01-02"), "portfolio_id", portfolioID, "interval", interval)
 
-	portfolios, err := s.portfolioService.GetPortfoliosForRequest(portfolioID)
+	portfolios, err := s.portfolioService.GetPortfoliosForRequest(ctx, portfolioID)
 	if err != nil {
 		return nil, fmt.Errorf("get portfolios: %w", err)
 	}
@@ -322,26 +361,34 @@ func (s *MaterializedService) GetPortfolioHistoryWithFallback(
 
 	matLog.Debug("portfolio history: resolved portfolios for range", "count", len(portfolios), "portfolioIDs", portfolioIDs, "startDate", startDate.Format("2006-01-02"), "endDate", endDate.Format("2006-01-02"))
 
-	stale := s.checkStaleData(portfolioIDs, endDate)
+	stale := s.checkStaleDataTraced(ctx, portfolioIDs, endDate)
 
 	if !stale {
-		materialized, mErr := s.GetPortfolioHistoryMaterialized(startDate, endDate, portfolioID)
+		_, matSpan := tracing.Start(ctx, "MaterializedService.GetPortfolioHistoryMaterialized")
+		materialized, mErr := s.GetPortfolioHistoryMaterialized(ctx, startDate, endDate, portfolioID, interval)
+		tracing.End(matSpan, mErr)
 		if mErr == nil && len(materialized) > 0 {
 			matLog.Debug("portfolio history: serving from materialized view", "dates", len(materialized), "summary", summarisePortfolioResult(materialized))
+			span.SetAttributes(attribute.String("materialized.source", "materialized"))
 			return materialized, nil
 		}
 		matLog.Debug("portfolio history: materialized view returned 0 entries, falling back", "error", mErr)
 	}
 
 	matLog.Debug("portfolio history: cache stale or empty, falling back to on-demand calculation")
-	result, err := s.GetPortfolioHistory(startDate, endDate, portfolioID)
+	span.SetAttributes(attribute.String("materialized.source", "on_demand"))
+	_, calcSpan := tracing.Start(ctx, "MaterializedService.GetPortfolioHistory")
+	result, err := s.GetPortfolioHistory(ctx, startDate, endDate, portfolioID)
+	tracing.End(calcSpan, err)
 	if err != nil {
 		return nil, fmt.Errorf("calculate portfolio history on-demand: %w", err)
 	}
 
 	matLog.Debug("portfolio history: on-demand calculation completed", "dates", len(result), "summary", summarisePortfolioResult(result))
 
-	s.triggerBackgroundRegeneration(portfolioIDs, startDate)
+	result = rollupPortfolioHistory(result, interval)
+
+	s.triggerBackgroundRegeneration(ctx, portfolioIDs, startDate)
 
 	return result, nil
 }
@@ -352,12 +399,15 @@ func (s *MaterializedService) GetPortfolioHistoryWithFallback(
 // computing the full history and returning the last entry.
 //
 // Parameters:
+//   - ctx: Request context; background regeneration joins its trace
 //   - portfolioID: Optional portfolio ID. If empty, returns all active portfolios.
 //
 // Returns portfolio summaries for the most recent available date.
-func (s *MaterializedService) GetPortfolioSummaryWithFallback(portfolioID string) ([]model.PortfolioSummary, error) {
+func (s *MaterializedService) GetPortfolioSummaryWithFallback(ctx context.Context, portfolioID string) ([]model.PortfolioSummary, error) {
+	ctx, span := tracing.Start(ctx, "MaterializedService.GetPortfolioSummaryWithFallback")
+	defer span.End()
 	matLog.Debug("retrieving portfolio summary with fallback", "portfolioID", portfolioID)
-	portfolios, err := s.portfolioService.GetPortfoliosForRequest(portfolioID)
+	portfolios, err := s.portfolioService.GetPortfoliosForRequest(ctx, portfolioID)
 	if err != nil {
 		return nil, fmt.Errorf("get portfolios: %w", err)
 	}
@@ -371,7 +421,7 @@ func (s *MaterializedService) GetPortfolioSummaryWithFallback(portfolioID string
 	}
 
 	endDate := time.Now().UTC()
-	stale := s.checkStaleData(portfolioIDs, endDate)
+	stale := s.checkStaleDataTraced(ctx, portfolioIDs, endDate)
 
 	if !stale {
 		var summaries []model.PortfolioSummary
@@ -397,6 +447,7 @@ func (s *MaterializedService) GetPortfolioSummaryWithFallback(portfolioID string
 		)
 		if err == nil && len(summaries) > 0 {
 			matLog.Debug("portfolio summary: serving from materialized view", "portfolios", len(summaries))
+			span.SetAttributes(attribute.String("materialized.source", "materialized"))
 			return summaries, nil
 		}
 		matLog.Debug("portfolio summary: materialized latest returned 0 entries, falling back", "error", err)
@@ -406,7 +457,10 @@ func (s *MaterializedService) GetPortfolioSummaryWithFallback(portfolioID string
 	// Pass zero-time as startDate — LoadForPortfolios unconditionally loads
 	// from the oldest transaction date regardless of the requested start.
 	matLog.Debug("portfolio summary: cache stale or empty, falling back to on-demand calculation")
-	history, err := s.GetPortfolioHistory(time.Time{}, endDate, portfolioID)
+	span.SetAttributes(attribute.String("materialized.source", "on_demand"))
+	_, calcSpan := tracing.Start(ctx, "MaterializedService.GetPortfolioHistory")
+	history, err := s.GetPortfolioHistory(ctx, time.Time{}, endDate, portfolioID)
+	tracing.End(calcSpan, err)
 	if err != nil {
 		return nil, fmt.Errorf("calculate portfolio history on-demand: %w", err)
 	}
@@ -415,7 +469,7 @@ func (s *MaterializedService) GetPortfolioSummaryWithFallback(portfolioID string
 	// not an arbitrary epoch, per the architecture doc's startDate clamping rule.
 	if len(history) > 0 {
 		if regenStart, parseErr := time.Parse("2006-01-02", history[0].Date); parseErr == nil {
-			s.triggerBackgroundRegeneration(portfolioIDs, regenStart)
+			s.triggerBackgroundRegeneration(ctx, portfolioIDs, regenStart)
 		}
 	}
 
@@ -526,10 +580,10 @@ func (s *MaterializedService) formatFundHistoryFromMaterialized(fundHistoryByDat
 //   - endDate: Last date to include in results
 //
 // Returns a slice of FundHistoryResponse, one per date, with per-fund metrics for that date.
-func (s *MaterializedService) calculateFundHistoryOnFly(portfolioID string, startDate, endDate time.Time) ([]model.FundHistoryResponse, error) {
+func (s *MaterializedService) calculateFundHistoryOnFly(ctx context.Context, portfolioID string, startDate, endDate time.Time) ([]model.FundHistoryResponse, error) {
 	matLog.Debug("calculating fund history on the fly", "portfolioID", portfolioID, "startDate", startDate.Format("2006-01-02"), "endDate", endDate.Format("2006-01-02"))
 
-	portfolio, err := s.portfolioService.GetPortfoliosForRequest(portfolioID)
+	portfolio, err := s.portfolioService.GetPortfoliosForRequest(ctx, portfolioID)
 	if err != nil {
 		return nil, fmt.Errorf("get portfolios: %w", err)
 	}
@@ -573,34 +627,44 @@ func (s *MaterializedService) calculateFundHistoryOnFly(portfolioID string, star
 //   - Reliable on-demand calculation as fallback when data is stale or missing (~50ms)
 //
 // Parameters:
+//   - ctx: Request context; background regeneration joins its trace
 //   - portfolioID: The portfolio ID to retrieve fund history for
 //   - startDate: First date to include in results
 //   - endDate: Last date to include in results (typically today)
 //
 // Returns complete fund-level history from startDate to endDate, using the fastest available method.
 func (s *MaterializedService) GetFundHistoryWithFallback(
+	ctx context.Context,
 	portfolioID string,
 	startDate, endDate time.Time,
 ) ([]model.FundHistoryResponse, error) {
+	ctx, span := tracing.Start(ctx, "MaterializedService.GetFundHistoryWithFallback")
+	defer span.End()
 	matLog.Debug("retrieving fund history with fallback", "portfolioID", portfolioID, "startDate", startDate.Format("2006-01-02"), "endDate", endDate.Format("2006-01-02"))
 
-	stale := s.checkStaleData([]string{portfolioID}, endDate)
+	stale := s.checkStaleDataTraced(ctx, []string{portfolioID}, endDate)
 
 	if !stale {
+		_, matSpan := tracing.Start(ctx, "MaterializedService.GetFundHistoryMaterialized")
 		materialized, mErr := s.GetFundHistoryMaterialized(portfolioID, startDate, endDate)
+		tracing.End(matSpan, mErr)
 		if mErr == nil && len(materialized) > 0 {
 			matLog.Debug("fund history: serving from materialized view", "entries", len(materialized), "portfolioID", portfolioID)
+			span.SetAttributes(attribute.String("materialized.source", "materialized"))
 			return materialized, nil
 		}
 	}
 
 	matLog.Debug("fund history: cache stale or empty, falling back to on-demand calculation", "portfolioID", portfolioID)
-	result, err := s.calculateFundHistoryOnFly(portfolioID, startDate, endDate)
+	span.SetAttributes(attribute.String("materialized.source", "on_demand"))
+	_, calcSpan := tracing.Start(ctx, "MaterializedService.calculateFundHistoryOnFly")
+	result, err := s.calculateFundHistoryOnFly(ctx, portfolioID, startDate, endDate)
+	tracing.End(calcSpan, err)
 	if err != nil {
 		return nil, fmt.Errorf("calculate fund history on-demand: %w", err)
 	}
 
-	s.triggerBackgroundRegeneration([]string{portfolioID}, startDate)
+	s.triggerBackgroundRegeneration(ctx, []string{portfolioID}, startDate)
 
 	return result, nil
 }
@@ -622,14 +686,20 @@ func (s *MaterializedService) GetFundHistoryWithFallback(
 //
 // Returns true if the cache is stale and should be regenerated.
 func (s *MaterializedService) checkStaleData(portfolioIDs []string, endDate time.Time) bool {
+	return s.staleReason(portfolioIDs, endDate) != model.StaleReasonNone
+}
+
+// staleReason performs the checks of checkStaleData and returns the first one that
+// fails, or StaleReasonNone if the cache is fresh.
+func (s *MaterializedService) staleReason(portfolioIDs []string, endDate time.Time) model.StaleReason {
 	matDate, matCalc, ok, err := s.materializedRepo.GetLatestMaterializedDate(portfolioIDs)
 	if err != nil {
 		matLog.Debug("stale check: error getting materialized date, treating as stale", "portfolioIDs", portfolioIDs, "error", err)
-		return true // Assume stale on error
+		return model.StaleReasonError // Assume stale on error
 	}
 	if !ok {
 		matLog.Debug("stale check: no materialized data found", "portfolioIDs", portfolioIDs)
-		return true // No materialized data at all
+		return model.StaleReasonNoData // No materialized data at all
 	}
 
 	matLog.Debug("stale check: materialized coverage", "portfolioIDs", portfolioIDs, "coverageEnd", matDate.Format("2006-01-02"), "calculatedAt", matCalc.Format(time.RFC3339), "endDate", endDate.Format("2006-01-02"))
@@ -637,13 +707,13 @@ func (s *MaterializedService) checkStaleData(portfolioIDs []string, endDate time
 	// Check date coverage (truncate to date-only since matDate is midnight UTC)
 	if matDate.Before(endDate.Truncate(24 * time.Hour)) {
 		matLog.Debug("stale check: stale, coverage insufficient", "portfolioIDs", portfolioIDs, "coverageEnd", matDate.Format("2006-01-02"), "needed", endDate.Format("2006-01-02"))
-		return true
+		return model.StaleReasonCoverage
 	}
 
 	latestTxn, latestPrice, latestDiv, err := s.materializedRepo.GetLatestSourceDates(portfolioIDs)
 	if err != nil {
 		matLog.Debug("stale check: error getting source dates, treating as stale", "portfolioIDs", portfolioIDs, "error", err)
-		return true
+		return model.StaleReasonError
 	}
 
 	matLog.Debug("stale check: source dates", "portfolioIDs", portfolioIDs, "latestTxn", latestTxn.Format(time.RFC3339), "latestPrice", latestPrice.Format("2006-01-02"), "latestDiv", latestDiv.Format(time.RFC3339))
@@ -651,113 +721,489 @@ func (s *MaterializedService) checkStaleData(portfolioIDs []string, endDate time
 	// Transaction created_at is a datetime - compare directly against calculated_at
 	if !latestTxn.IsZero() && latestTxn.After(matCalc) {
 		matLog.Debug("stale check: stale, latest txn after calculated_at", "portfolioIDs", portfolioIDs, "latestTxn", latestTxn.Format(time.RFC3339), "calculatedAt", matCalc.Format(time.RFC3339))
-		return true
+		return model.StaleReasonTransactions
 	}
 
 	// Price date is a date - if latest price date > materialized date coverage, it's stale
 	if !latestPrice.IsZero() && latestPrice.After(matDate) {
 		matLog.Debug("stale check: stale, latest price after materialized coverage", "portfolioIDs", portfolioIDs, "latestPrice", latestPrice.Format("2006-01-02"), "coverageEnd", matDate.Format("2006-01-02"))
-		return true
+		return model.StaleReasonPrices
 	}
 
 	// Dividend created_at is a datetime - compare against calculated_at
 	if !latestDiv.IsZero() && latestDiv.After(matCalc) {
 		matLog.Debug("stale check: stale, latest dividend after calculated_at", "portfolioIDs", portfolioIDs, "latestDiv", latestDiv.Format(time.RFC3339), "calculatedAt", matCalc.Format(time.RFC3339))
-		return true
+		return model.StaleReasonDividends
 	}
 
 	matLog.Debug("stale check: fresh", "portfolioIDs", portfolioIDs)
-	return false
-}
-
-// triggerBackgroundRegeneration starts a background goroutine to regenerate the
-// materialized table for the given portfolios. Uses regenInFlight to prevent
-// duplicate jobs for the same portfolio.
-//
-// If a regeneration job is already running for a portfolio and the new request has
-// an earlier startDate, the tracked date is updated. The running job will finish,
-// then a follow-up job will be launched covering the earlier date range.
-// If the new request has a later or equal startDate, it is dropped (already covered).
-func (s *MaterializedService) triggerBackgroundRegeneration(portfolioIDs []string, startDate time.Time) {
-	s.regenMu.Lock()
-	if s.regenInFlight == nil {
-		s.regenInFlight = make(map[string]time.Time)
-	}
-
-	var toStart []string
-	for _, pid := range portfolioIDs {
-		existing, running := s.regenInFlight[pid]
-		if running {
-			if startDate.Before(existing) {
-				matLog.Debug("regen: superseding with earlier date", "portfolioID", pid, "newDate", startDate.Format("2006-01-02"), "oldDate", existing.Format("2006-01-02"))
-				s.regenInFlight[pid] = startDate
-			} else {
-				matLog.Debug("regen: skipped, already in flight", "portfolioID", pid, "from", existing.Format("2006-01-02"))
-			}
-			continue
-		}
-		s.regenInFlight[pid] = startDate
-		toStart = append(toStart, pid)
+	return model.StaleReasonNone
+}
+
+// checkStaleDataTraced wraps checkStaleData in a span.
+func (s *MaterializedService) checkStaleDataTraced(ctx context.Context, portfolioIDs []string, endDate time.Time) bool {
+	_, span := tracing.Start(ctx, "MaterializedService.checkStaleData")
+	defer span.End()
+	stale := s.checkStaleData(portfolioIDs, endDate)
+	span.SetAttributes(attribute.Bool("materialized.stale", stale))
+	return stale
+}
+
+// regenPollInterval is how often the regeneration worker looks for due queue entries
+// when it has not been woken by a new request.
+const regenPollInterval = 30 * time.Second
+
+// regenRetryBase and regenRetryMax bound the exponential backoff between failed
+// regeneration attempts for a portfolio.
+const (
+	regenRetryBase = 30 * time.Second
+	regenRetryMax  = time.Hour
+)
+
+// triggerBackgroundRegeneration queues regeneration of every fund in the given portfolios
+// from startDate onward and wakes the regeneration worker.
+func (s *MaterializedService) triggerBackgroundRegeneration(ctx context.Context, portfolioIDs []string, startDate time.Time) {
+	targets := make([]model.RegenTarget, len(portfolioIDs))
+	for i, pid := range portfolioIDs {
+		targets[i] = model.RegenTarget{PortfolioID: pid}
+	}
+	s.enqueueRegeneration(ctx, targets, startDate)
+}
+
+// enqueueRegeneration queues regeneration of the given targets from startDate onward and
+// wakes the regeneration worker.
+//
+// The queue is persistent and keeps one entry per target with the earliest dirty date,
+// so repeated requests collapse into a single regeneration and nothing is lost if the
+// process stops before the worker gets to it.
+func (s *MaterializedService) enqueueRegeneration(ctx context.Context, targets []model.RegenTarget, startDate time.Time) {
+	if len(targets) == 0 {
+		return
+	}
+	if err := s.materializedRepo.EnqueueRegen(ctx, targets, startDate, time.Now().UTC()); err != nil {
+		matLog.WarnContext(ctx, "regen: failed to enqueue", "targets", len(targets), "error", err)
+		return
 	}
-	s.regenMu.Unlock()
+	matLog.DebugContext(ctx, "regen: queued", "targets", len(targets), "from", startDate.Format("2006-01-02"))
 
-	for _, pid := range toStart {
-		matLog.Debug("regen: queued", "portfolioID", pid, "from", startDate.Format("2006-01-02"))
-		go s.runRegenLoop(pid)
+	select {
+	case s.regenWake <- struct{}{}:
+	default: // A wake-up is already pending.
 	}
 }
 
-// maxRegenRetries is the maximum number of consecutive failures before runRegenLoop
-// gives up. This prevents infinite retry loops on persistent errors.
-const maxRegenRetries = 3
+// ScheduleRegeneration implements MaterializedInvalidator by queueing the affected
+// portfolio funds for the regeneration worker.
+func (s *MaterializedService) ScheduleRegeneration(ctx context.Context, startDate time.Time, portfolioIDs []string, fundID, portfolioFundID string) {
+	targets, err := s.resolveRegenTargets(portfolioIDs, fundID, portfolioFundID)
+	if err != nil {
+		matLog.WarnContext(ctx, "regen: failed to resolve targets", "fundID", fundID, "portfolioFundID", portfolioFundID, "error", err)
+		return
+	}
+	s.enqueueRegeneration(ctx, targets, startDate)
+}
+
+// GetRegenQueue returns the pending regenerations, i.e. the portfolios and portfolio funds
+// whose materialized history is dirty and from which date.
+func (s *MaterializedService) GetRegenQueue() ([]model.RegenQueueEntry, error) {
+	entries, err := s.materializedRepo.GetRegenQueue()
+	if err != nil {
+		return nil, fmt.Errorf("get regeneration queue: %w", err)
+	}
+	return entries, nil
+}
+
+// RegenQueueLength returns the number of regenerations queued or running.
+func (s *MaterializedService) RegenQueueLength() int {
+	n, err := s.materializedRepo.CountRegenQueue()
+	if err != nil {
+		matLog.Warn("regen: failed to count queue", "error", err)
+		return 0
+	}
+	return n
+}
+
+// RunRegenWorker drains the regeneration queue until ctx is cancelled: once at startup,
+// whenever a regeneration is queued, and every regenPollInterval for retries that have
+// become due. Run it on its own goroutine, once per process.
+func (s *MaterializedService) RunRegenWorker(ctx context.Context) {
+	matLog.Info("regen: worker started")
+	ticker := time.NewTicker(regenPollInterval)
+	defer ticker.Stop()
 
-// runRegenLoop runs regeneration for a portfolio, then checks if a follow-up job
-// with an earlier startDate was requested while it was running. Repeats until no
-// earlier date is pending. Aborts after maxRegenRetries consecutive failures.
-func (s *MaterializedService) runRegenLoop(portfolioID string) {
-	failures := 0
 	for {
-		s.regenMu.Lock()
-		startDate, ok := s.regenInFlight[portfolioID]
+		s.drainRegenQueue(ctx)
+		select {
+		case <-ctx.Done():
+			matLog.Info("regen: worker stopped")
+			return
+		case <-s.regenWake:
+		case <-ticker.C:
+		}
+	}
+}
+
+// ProcessRegenQueue regenerates every due queue entry once and returns, for processes
+// that do not run RunRegenWorker. Failed entries stay queued with their backoff.
+func (s *MaterializedService) ProcessRegenQueue(ctx context.Context) {
+	s.drainRegenQueue(ctx)
+}
+
+// drainRegenQueue regenerates due queue entries one at a time until none are due.
+// A target re-queued while it was regenerating stays due and is processed again
+// from its new earliest date. Failures are rescheduled with exponential backoff.
+func (s *MaterializedService) drainRegenQueue(ctx context.Context) {
+	for ctx.Err() == nil {
+		entry, ok, err := s.materializedRepo.GetDueRegen(time.Now().UTC())
+		if err != nil {
+			matLog.Warn("regen: failed to read queue", "error", err)
+			return
+		}
 		if !ok {
-			s.regenMu.Unlock()
 			return
 		}
-		s.regenMu.Unlock()
 
-		matLog.Debug("regen: starting", "portfolioID", portfolioID, "from", startDate.Format("2006-01-02"))
+		matLog.Debug("regen: starting", "portfolioID", entry.PortfolioID, "portfolioFundID", entry.PortfolioFundID, "from", entry.StartDate.Format("2006-01-02"), "attempt", entry.Attempts+1)
 		start := time.Now()
-		err := s.RegenerateMaterializedTable(
-			context.Background(), startDate, []string{portfolioID}, "", "",
+		regenCtx, span := tracing.Start(ctx, "MaterializedService.drainRegenQueue",
+			attribute.String("portfolio.id", entry.PortfolioID),
+			attribute.String("portfolio_fund.id", entry.PortfolioFundID),
+			attribute.String("materialized.start_date", entry.StartDate.Format("2006-01-02")),
 		)
-		if err != nil {
-			matLog.Warn("regen: failed", "portfolioID", portfolioID, "duration", time.Since(start).Round(time.Millisecond), "error", err)
-			failures++
+		if entry.PortfolioFundID != "" {
+			err = s.regenerate(regenCtx, entry.StartDate, nil, "", entry.PortfolioFundID)
 		} else {
-			matLog.Info("regen: completed", "portfolioID", portfolioID, "duration", time.Since(start).Round(time.Millisecond))
-			failures = 0
+			err = s.regenerate(regenCtx, entry.StartDate, []string{entry.PortfolioID}, "", "")
 		}
+		tracing.End(span, err)
 
-		if failures >= maxRegenRetries {
-			matLog.Warn("regen: aborting after consecutive failures", "portfolioID", portfolioID, "failures", failures)
-			s.regenMu.Lock()
-			delete(s.regenInFlight, portfolioID)
-			s.regenMu.Unlock()
-			return
+		if err != nil {
+			if ctx.Err() != nil {
+				return // Shutting down; the entry stays queued for the next start.
+			}
+			backoff := min(regenRetryBase<<min(entry.Attempts, 16), regenRetryMax)
+			matLog.Warn("regen: failed", "portfolioID", entry.PortfolioID, "portfolioFundID", entry.PortfolioFundID, "duration", time.Since(start).Round(time.Millisecond), "retryIn", backoff, "error", err)
+			metrics.MaterializedRegenDuration.WithLabelValues(metrics.OutcomeFailure).Observe(time.Since(start).Seconds())
+			if err := s.materializedRepo.FailRegen(ctx, entry.Target(), err.Error(), time.Now().UTC().Add(backoff)); err != nil {
+				matLog.Warn("regen: failed to reschedule", "portfolioID", entry.PortfolioID, "error", err)
+				return
+			}
+			continue
 		}
 
-		s.regenMu.Lock()
-		current := s.regenInFlight[portfolioID]
-		if !current.Before(startDate) {
-			// No earlier date was requested while we were running — done
-			delete(s.regenInFlight, portfolioID)
-			s.regenMu.Unlock()
+		matLog.Info("regen: completed", "portfolioID", entry.PortfolioID, "portfolioFundID", entry.PortfolioFundID, "duration", time.Since(start).Round(time.Millisecond))
+		metrics.MaterializedRegenDuration.WithLabelValues(metrics.OutcomeSuccess).Observe(time.Since(start).Seconds())
+		done, err := s.materializedRepo.CompleteRegen(ctx, entry.Target(), entry.Version)
+		if err != nil {
+			matLog.Warn("regen: failed to dequeue", "portfolioID", entry.PortfolioID, "error", err)
 			return
 		}
-		// An earlier date was requested — loop again with the new date
-		matLog.Debug("regen: follow-up needed", "portfolioID", portfolioID, "newFrom", current.Format("2006-01-02"), "previousFrom", startDate.Format("2006-01-02"))
-		s.regenMu.Unlock()
+		if !done {
+			matLog.Debug("regen: follow-up needed", "portfolioID", entry.PortfolioID)
+		}
+		s.checkValueDrop(ctx, entry.PortfolioID)
+		if s.alerts != nil {
+			if _, err := s.alerts.EvaluatePortfolioAlerts(ctx, entry.PortfolioID); err != nil {
+				matLog.Warn("regen: failed to evaluate alerts", "portfolioID", entry.PortfolioID, "error", err)
+			}
+		}
+	}
+}
+
+// checkValueDrop publishes a portfolio.value_drop event when the latest daily snapshot of a
+// portfolio, within the last week, lost at least valueDropPercent of the previous snapshot's
+// value. The change is measured on total gain/loss so deposits and withdrawals do not count.
+// Each snapshot date is reported at most once.
+func (s *MaterializedService) checkValueDrop(ctx context.Context, portfolioID string) {
+	if s.events == nil || s.valueDropPercent <= 0 {
+		return
+	}
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	var prev, last *model.PortfolioHistoryMaterialized
+	err := s.materializedRepo.GetMaterializedHistory([]string{portfolioID}, model.HistoryIntervalDay, today.AddDate(0, 0, -7), today,
+		func(record model.PortfolioHistoryMaterialized) error {
+			prev, last = last, &record
+			return nil
+		})
+	if err != nil {
+		matLog.Warn("regen: failed to check value drop", "portfolioID", portfolioID, "error", err)
+		return
+	}
+	if prev == nil || prev.Value <= 0 {
+		return
+	}
+
+	change := last.TotalGainLoss - prev.TotalGainLoss
+	percent := change / prev.Value * 100
+	if percent > -s.valueDropPercent {
+		return
+	}
+
+	date := last.Date.Format("2006-01-02")
+	publishEvent(ctx, s.events, model.WebhookEventPortfolioValueDrop, portfolioID+":"+date, map[string]any{
+		"portfolioId":   portfolioID,
+		"date":          date,
+		"previousDate":  prev.Date.Format("2006-01-02"),
+		"value":         last.Value,
+		"previousValue": prev.Value,
+		"change":        change,
+		"changePercent": percent,
+	})
+}
+
+// regenerate runs RegenerateMaterializedTable synchronously, recorded as a job when a
+// JobService is configured.
+func (s *MaterializedService) regenerate(ctx context.Context, startDate time.Time, portfolioIDs []string, fundID, portfolioFundID string) error {
+	if s.jobService == nil {
+		return s.RegenerateMaterializedTable(ctx, startDate, portfolioIDs, fundID, portfolioFundID)
+	}
+	_, err := s.jobService.Run(ctx, model.JobTypeMaterializedRegen, model.MaterializedRegenParams{
+		StartDate:       startDate.Format("2006-01-02"),
+		PortfolioIDs:    portfolioIDs,
+		FundID:          fundID,
+		PortfolioFundID: portfolioFundID,
+	})
+	return err
+}
+
+// =============================================================================
+// ADMIN: COVERAGE, REBUILD & VERIFICATION
+// =============================================================================
+
+// verifyTolerance is the largest difference between a materialized and a recalculated
+// value that VerifyMaterialized does not report. Values are rounded to six decimals, so
+// anything above rounding noise is drift.
+const verifyTolerance = 1e-6
+
+// adminPortfolios resolves the portfolios an admin operation covers: the given portfolio,
+// or every portfolio including archived and excluded ones.
+func (s *MaterializedService) adminPortfolios(ctx context.Context, portfolioID string) ([]model.Portfolio, error) {
+	if portfolioID != "" {
+		return s.portfolioService.GetPortfoliosForRequest(ctx, portfolioID)
+	}
+	return s.portfolioService.GetAllPortfolios(ctx)
+}
+
+// GetMaterializedCoverage reports the materialized history of one portfolio, or of every
+// portfolio if portfolioID is empty: the dates it covers, when it was last calculated, and
+// whether and why checkStaleData considers it stale as of today.
+func (s *MaterializedService) GetMaterializedCoverage(ctx context.Context, portfolioID string) ([]model.MaterializedCoverage, error) {
+	_, span := tracing.Start(ctx, "MaterializedService.GetMaterializedCoverage")
+	defer span.End()
+	matLog.DebugContext(ctx, "getting materialized coverage", "portfolioID", portfolioID)
+
+	portfolios, err := s.adminPortfolios(ctx, portfolioID)
+	if err != nil {
+		return nil, fmt.Errorf("get portfolios: %w", err)
+	}
+	portfolioIDs := make([]string, len(portfolios))
+	for i, p := range portfolios {
+		portfolioIDs[i] = p.ID
+	}
+
+	coverage, err := s.materializedRepo.GetMaterializedCoverage(portfolioIDs)
+	if err != nil {
+		return nil, fmt.Errorf("get materialized coverage: %w", err)
+	}
+
+	today := time.Now().UTC()
+	result := make([]model.MaterializedCoverage, len(portfolios))
+	for i, p := range portfolios {
+		c := coverage[p.ID]
+		c.PortfolioID = p.ID
+		c.PortfolioName = p.Name
+		c.StaleReason = s.staleReason([]string{p.ID}, today)
+		c.Stale = c.StaleReason != model.StaleReasonNone
+		result[i] = c
+	}
+	return result, nil
+}
+
+// RebuildMaterialized regenerates the materialized history of one portfolio, or of every
+// portfolio if portfolioID is empty, and returns the resulting coverage. A zero startDate
+// rebuilds the whole history from scratch; otherwise rows from startDate onward are
+// recalculated, seeded from the rows of the day before.
+func (s *MaterializedService) RebuildMaterialized(ctx context.Context, portfolioID string, startDate time.Time) ([]model.MaterializedCoverage, error) {
+	ctx, span := tracing.Start(ctx, "MaterializedService.RebuildMaterialized")
+	defer span.End()
+	matLog.InfoContext(ctx, "rebuilding materialized history", "portfolioID", portfolioID, "startDate", startDate.Format("2006-01-02"))
+
+	portfolios, err := s.adminPortfolios(ctx, portfolioID)
+	if err != nil {
+		return nil, fmt.Errorf("get portfolios: %w", err)
+	}
+	if len(portfolios) > 0 {
+		portfolioIDs := make([]string, len(portfolios))
+		for i, p := range portfolios {
+			portfolioIDs[i] = p.ID
+		}
+		if err := s.RegenerateMaterializedTable(ctx, startDate, portfolioIDs, "", ""); err != nil {
+			return nil, fmt.Errorf("regenerate materialized table: %w", err)
+		}
+	}
+
+	return s.GetMaterializedCoverage(ctx, portfolioID)
+}
+
+// SubmitRebuild is RebuildMaterialized as a background materialized_regen job. It returns
+// as soon as the job is recorded; the job's status reports the outcome.
+func (s *MaterializedService) SubmitRebuild(ctx context.Context, portfolioID string, startDate time.Time) (model.Job, error) {
+	ctx, span := tracing.Start(ctx, "MaterializedService.SubmitRebuild")
+	defer span.End()
+
+	if s.jobService == nil {
+		return model.Job{}, fmt.Errorf("submit rebuild: no job service configured")
+	}
+
+	portfolios, err := s.adminPortfolios(ctx, portfolioID)
+	if err != nil {
+		return model.Job{}, fmt.Errorf("get portfolios: %w", err)
+	}
+	portfolioIDs := make([]string, len(portfolios))
+	for i, p := range portfolios {
+		portfolioIDs[i] = p.ID
+	}
+
+	job, err := s.jobService.Submit(ctx, model.JobTypeMaterializedRegen, model.MaterializedRegenParams{
+		StartDate:    startDate.Format("2006-01-02"),
+		PortfolioIDs: portfolioIDs,
+	})
+	if err != nil {
+		return model.Job{}, fmt.Errorf("submit rebuild job: %w", err)
+	}
+	return job, nil
+}
+
+// VerifyMaterialized compares the materialized fund rows of one portfolio, or of every
+// portfolio if portfolioID is empty, against calculateFundHistoryOnFly over the same date
+// range and reports every row that is missing, unexpected or differs in any field.
+func (s *MaterializedService) VerifyMaterialized(ctx context.Context, portfolioID string, startDate, endDate time.Time) ([]model.MaterializedVerification, error) {
+	_, span := tracing.Start(ctx, "MaterializedService.VerifyMaterialized")
+	defer span.End()
+	matLog.DebugContext(ctx, "verifying materialized history", "portfolioID", portfolioID, "startDate", startDate.Format("2006-01-02"), "endDate", endDate.Format("2006-01-02"))
+
+	portfolios, err := s.adminPortfolios(ctx, portfolioID)
+	if err != nil {
+		return nil, fmt.Errorf("get portfolios: %w", err)
+	}
+
+	result := make([]model.MaterializedVerification, 0, len(portfolios))
+	for _, p := range portfolios {
+		verification, err := s.verifyPortfolio(ctx, p, startDate, endDate)
+		if err != nil {
+			return nil, fmt.Errorf("verify portfolio %s: %w", p.ID, err)
+		}
+		if verification.DriftCount > 0 {
+			matLog.WarnContext(ctx, "materialized drift detected", "portfolioID", p.ID, "drift", verification.DriftCount, "rowsChecked", verification.RowsChecked)
+		}
+		result = append(result, verification)
+	}
+	return result, nil
+}
+
+// verifyPortfolio compares one portfolio's materialized fund rows with a fresh calculation.
+func (s *MaterializedService) verifyPortfolio(ctx context.Context, portfolio model.Portfolio, startDate, endDate time.Time) (model.MaterializedVerification, error) {
+	verification := model.MaterializedVerification{
+		PortfolioID:   portfolio.ID,
+		PortfolioName: portfolio.Name,
+		StartDate:     startDate,
+		EndDate:       endDate,
+		Drift:         []model.MaterializedDrift{},
+	}
+
+	type rowKey struct {
+		pfID string
+		date string
+	}
+	materialized := make(map[rowKey]model.FundHistoryEntry)
+	err := s.materializedRepo.GetFundHistoryMaterialized(portfolio.ID, startDate, endDate, func(entry model.FundHistoryEntry) error {
+		materialized[rowKey{entry.PortfolioFundID, entry.Date.Format("2006-01-02")}] = entry
+		return nil
+	})
+	if err != nil {
+		return verification, fmt.Errorf("get fund history materialized: %w", err)
+	}
+
+	expected, err := s.calculateFundHistoryOnFly(ctx, portfolio.ID, startDate, endDate)
+	if err != nil {
+		return verification, fmt.Errorf("calculate fund history: %w", err)
+	}
+
+	report := func(d model.MaterializedDrift) {
+		verification.DriftCount++
+		if len(verification.Drift) < model.MaxReportedDrift {
+			verification.Drift = append(verification.Drift, d)
+		}
+	}
+
+	// Calculated entries carry their date on the enclosing day only.
+	for _, day := range expected {
+		for _, want := range day.Funds {
+			key := rowKey{want.PortfolioFundID, day.Date.Format("2006-01-02")}
+			got, ok := materialized[key]
+			if !ok {
+				report(model.MaterializedDrift{Kind: model.DriftMissing, PortfolioFundID: want.PortfolioFundID, FundName: want.FundName, Date: day.Date})
+				continue
+			}
+			delete(materialized, key)
+			verification.RowsChecked++
+
+			for _, field := range compareFundHistoryEntries(got, want) {
+				field.PortfolioFundID = want.PortfolioFundID
+				field.FundName = want.FundName
+				field.Date = day.Date
+				report(field)
+			}
+		}
 	}
+
+	// Whatever is left was materialized but not produced by the calculation.
+	extra := make([]model.FundHistoryEntry, 0, len(materialized))
+	for _, entry := range materialized {
+		extra = append(extra, entry)
+	}
+	sort.Slice(extra, func(i, j int) bool {
+		if !extra[i].Date.Equal(extra[j].Date) {
+			return extra[i].Date.Before(extra[j].Date)
+		}
+		return extra[i].PortfolioFundID < extra[j].PortfolioFundID
+	})
+	for _, entry := range extra {
+		verification.RowsChecked++
+		report(model.MaterializedDrift{Kind: model.DriftUnexpected, PortfolioFundID: entry.PortfolioFundID, FundName: entry.FundName, Date: entry.Date})
+	}
+
+	return verification, nil
+}
+
+// compareFundHistoryEntries returns a mismatch for every field of got that differs from
+// want by more than verifyTolerance. Row identity fields are left for the caller to fill in.
+func compareFundHistoryEntries(got, want model.FundHistoryEntry) []model.MaterializedDrift {
+	fields := []struct {
+		name      string
+		got, want float64
+	}{
+		{"shares", got.Shares, want.Shares},
+		{"price", got.Price, want.Price},
+		{"value", got.Value, want.Value},
+		{"cost", got.Cost, want.Cost},
+		{"realizedGain", got.RealizedGain, want.RealizedGain},
+		{"unrealizedGain", got.UnrealizedGain, want.UnrealizedGain},
+		{"totalGainLoss", got.TotalGainLoss, want.TotalGainLoss},
+		{"dividends", got.Dividends, want.Dividends},
+		{"fees", got.Fees, want.Fees},
+		{"saleProceeds", got.SaleProceeds, want.SaleProceeds},
+		{"originalCost", got.OriginalCost, want.OriginalCost},
+	}
+
+	var drift []model.MaterializedDrift
+	for _, f := range fields {
+		if math.Abs(f.got-f.want) > verifyTolerance {
+			drift = append(drift, model.MaterializedDrift{Kind: model.DriftMismatch, Field: f.name, Materialized: f.got, Expected: f.want})
+		}
+	}
+	return drift
 }
 
 // =============================================================================
@@ -805,77 +1251,72 @@ func (s *MaterializedService) processTransactionsForDate(transactionsMap map[str
 }
 
 // RegenerateMaterializedTable recalculates and replaces materialized view entries from startDate
-// forward. Portfolio scope is determined by the first non-empty/non-nil parameter:
-//   - portfolioIDs: used directly
-//   - fundID: resolved to all portfolios holding that fund
-//   - portfolioFundID: resolved to the owning portfolio
+// forward. Scope is determined by the first non-empty/non-nil parameter:
+//   - portfolioIDs: every fund in those portfolios
+//   - fundID: every portfolio fund holding that fund
+//   - portfolioFundID: that portfolio fund only
+//
+// Only the portfolio funds in scope are rewritten; rows of other funds in the same
+// portfolio are left untouched. Each portfolio fund is rolled forward from its
+// materialized row on the day before startDate (see calculateFundHistoryFromSeed), so the
+// work scales with the length of the changed range rather than the whole history. The
+// portfolio-level snapshots and rollups of the affected portfolios are rebuilt from the
+// fund rows in the same transaction.
 //
 // All calls are serialized via regenWriteMu because SQLite supports only one concurrent writer;
 // without this, both write-path hooks and read-path fallback goroutines would cause SQLITE_BUSY
 // errors.
-//
-//nolint:gocyclo // Core regen: resolve portfolios, calculate, collect pfIDs, invalidate+insert in tx
 func (s *MaterializedService) RegenerateMaterializedTable(ctx context.Context, startDate time.Time, portfolioIDs []string, fundID, portfolioFundID string) error {
+	ctx, span := tracing.Start(ctx, "MaterializedService.RegenerateMaterializedTable")
+	defer span.End()
 	matLog.DebugContext(ctx, "regenerating materialized table", "startDate", startDate.Format("2006-01-02"), "portfolioIDs", portfolioIDs, "fundID", fundID, "portfolioFundID", portfolioFundID)
 	s.regenWriteMu.Lock()
 	defer s.regenWriteMu.Unlock()
 
-	if len(portfolioIDs) == 0 {
-		if fundID != "" {
-			pfs, err := s.pfRepo.GetPortfolioFundsbyFundID(fundID)
-			if err != nil {
-				return fmt.Errorf("get portfolio funds by fund ID: %w", err)
-			}
-			seen := make(map[string]bool)
-			for _, v := range pfs {
-				if !seen[v.PortfolioID] {
-					portfolioIDs = append(portfolioIDs, v.PortfolioID)
-					seen[v.PortfolioID] = true
-				}
-			}
-		} else if portfolioFundID != "" {
-			pf, err := s.pfRepo.GetPortfolioFund(portfolioFundID)
-			if err != nil {
-				return fmt.Errorf("get portfolio fund: %w", err)
-			}
-			portfolioIDs = append(portfolioIDs, pf.PortfolioID)
-		} else {
-			return fmt.Errorf("RegenerateMaterializedTable: at least one of portfolioIDs, fundID, or portfolioFundID must be provided")
+	targets, err := s.resolveRegenTargets(portfolioIDs, fundID, portfolioFundID)
+	if err != nil {
+		return err
+	}
+
+	// Group targets by portfolio so each portfolio's data is loaded once. A portfolio
+	// listed in wholePortfolio has every fund in scope.
+	var order []string
+	pfIDsByPortfolio := make(map[string][]string)
+	wholePortfolio := make(map[string]bool)
+	for _, t := range targets {
+		if _, seen := pfIDsByPortfolio[t.PortfolioID]; !seen && !wholePortfolio[t.PortfolioID] {
+			order = append(order, t.PortfolioID)
+		}
+		if t.PortfolioFundID == "" {
+			wholePortfolio[t.PortfolioID] = true
+			continue
 		}
+		pfIDsByPortfolio[t.PortfolioID] = append(pfIDsByPortfolio[t.PortfolioID], t.PortfolioFundID)
 	}
 
 	// Calculate new entries before starting the transaction (read-heavy, no writes)
+	startDate = time.Date(startDate.Year(), startDate.Month(), startDate.Day(), 0, 0, 0, 0, time.UTC)
 	endDate := time.Now().UTC()
-	var allEntries []model.FundHistoryResponse
+	var fundHistoryEntries []model.FundHistoryEntry
+	var pfIDs []string
 
-	for _, pid := range portfolioIDs {
-		entries, err := s.calculateFundHistoryOnFly(pid, startDate, endDate)
+	for _, pid := range order {
+		var scope []string
+		if !wholePortfolio[pid] {
+			scope = pfIDsByPortfolio[pid]
+		}
+		entries, regenerated, err := s.calculateRegenEntries(ctx, pid, scope, startDate, endDate)
 		if err != nil {
 			return fmt.Errorf("calculate fund history: %w", err)
 		}
-		allEntries = append(allEntries, entries...)
+		fundHistoryEntries = append(fundHistoryEntries, entries...)
+		pfIDs = append(pfIDs, regenerated...)
 	}
 
-	fundHistoryEntries := make([]model.FundHistoryEntry, 0, len(allEntries))
-	for _, v := range allEntries {
-		// Propagate the response-level date to each fund entry, since
-		// calculateFundEntry doesn't set FundHistoryEntry.Date itself.
-		for i := range v.Funds {
-			v.Funds[i].Date = v.Date
-		}
-		fundHistoryEntries = append(fundHistoryEntries, v.Funds...)
-	}
-
-	pfIDSet := make(map[string]bool)
 	for i := range fundHistoryEntries {
 		if fundHistoryEntries[i].ID == "" {
 			fundHistoryEntries[i].ID = uuid.New().String()
 		}
-		pfIDSet[fundHistoryEntries[i].PortfolioFundID] = true
-	}
-	pfIDs := make([]string, 0, len(pfIDSet))
-	for id := range pfIDSet {
-		pfIDs = append(pfIDs, id)
 	}
 
 	tx, err := s.db.BeginTx(ctx, nil)
@@ -892,6 +1333,10 @@ func (s *MaterializedService) RegenerateMaterializedTable(ctx context.Context, s
 		return fmt.Errorf("insert materialized entries: %w", err)
 	}
 
+	if err := s.materializedRepo.WithTx(tx).RefreshPortfolioSnapshots(ctx, order, startDate); err != nil {
+		return fmt.Errorf("refresh portfolio snapshots: %w", err)
+	}
+
 	if err := tx.Commit(); err != nil {
 		return fmt.Errorf("commit transaction: %w", err)
 	}
@@ -899,6 +1344,107 @@ func (s *MaterializedService) RegenerateMaterializedTable(ctx context.Context, s
 	return nil
 }
 
+// calculateRegenEntries calculates the materialized rows of one portfolio from startDate to
+// endDate for the portfolio funds in pfIDs, or for every fund in the portfolio if pfIDs is
+// empty. It returns the rows along with the IDs of the portfolio funds they replace.
+//
+// Each fund is seeded from its materialized row on the day before startDate. Rows before
+// startDate are unaffected by the change being regenerated, so the seed is trusted as is.
+// A fund without a seed row (nothing materialized yet, or no position that day) starts
+// from a full calculation instead.
+func (s *MaterializedService) calculateRegenEntries(ctx context.Context, portfolioID string, pfIDs []string, startDate, endDate time.Time) ([]model.FundHistoryEntry, []string, error) {
+	portfolio, err := s.portfolioService.GetPortfoliosForRequest(ctx, portfolioID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("get portfolios: %w", err)
+	}
+
+	data, err := s.dataLoaderService.LoadForPortfolios(portfolio, startDate, endDate)
+	if err != nil {
+		return nil, nil, fmt.Errorf("load portfolio data: %w", err)
+	}
+
+	funds := data.PortfolioFunds
+	if len(pfIDs) > 0 {
+		funds = slices.DeleteFunc(slices.Clone(funds), func(pf model.PortfolioFundResponse) bool {
+			return !slices.Contains(pfIDs, pf.ID)
+		})
+	}
+	if len(funds) == 0 {
+		return nil, nil, nil
+	}
+	regenerated := make([]string, len(funds))
+	for i, pf := range funds {
+		regenerated[i] = pf.ID
+	}
+
+	// Skip the empty calendar days before the portfolio's first transaction. Rows
+	// before a clamped start may themselves be outdated (the earliest transaction
+	// was moved or deleted), so they are not used as seeds.
+	seeds := map[string]model.FundHistoryEntry{}
+	if !data.OldestTransactionDate.IsZero() && startDate.Before(data.OldestTransactionDate) {
+		startDate = data.OldestTransactionDate
+		startDate = time.Date(startDate.Year(), startDate.Month(), startDate.Day(), 0, 0, 0, 0, time.UTC)
+	} else {
+		seeds, err = s.materializedRepo.GetMaterializedEntriesForDate(regenerated, startDate.AddDate(0, 0, -1))
+		if err != nil {
+			return nil, nil, fmt.Errorf("get materialized seed: %w", err)
+		}
+	}
+
+	realizedGainsByPF := data.MapRealizedGainsByPF(portfolioID)
+
+	var entries []model.FundHistoryEntry
+	for _, pf := range funds {
+		var seed *model.FundHistoryEntry
+		if e, ok := seeds[pf.ID]; ok {
+			seed = &e
+		}
+		history, err := s.calculateFundHistoryFromSeed(pf, data, realizedGainsByPF[pf.ID], seed, startDate, endDate)
+		if err != nil {
+			return nil, nil, fmt.Errorf("calculate history for portfolio fund %s: %w", pf.ID, err)
+		}
+		entries = append(entries, history...)
+	}
+
+	return entries, regenerated, nil
+}
+
+// resolveRegenTargets returns what a regeneration request covers: every fund in
+// portfolioIDs if given, otherwise the portfolio funds holding fundID, otherwise
+// portfolioFundID. A portfolio fund that no longer exists resolves to nothing, since
+// its materialized rows were deleted along with it.
+func (s *MaterializedService) resolveRegenTargets(portfolioIDs []string, fundID, portfolioFundID string) ([]model.RegenTarget, error) {
+	if len(portfolioIDs) > 0 {
+		targets := make([]model.RegenTarget, len(portfolioIDs))
+		for i, pid := range portfolioIDs {
+			targets[i] = model.RegenTarget{PortfolioID: pid}
+		}
+		return targets, nil
+	}
+	if fundID != "" {
+		pfs, err := s.pfRepo.GetPortfolioFundsbyFundID(fundID)
+		if err != nil {
+			return nil, fmt.Errorf("get portfolio funds by fund ID: %w", err)
+		}
+		targets := make([]model.RegenTarget, len(pfs))
+		for i, pf := range pfs {
+			targets[i] = model.RegenTarget{PortfolioID: pf.PortfolioID, PortfolioFundID: pf.ID}
+		}
+		return targets, nil
+	}
+	if portfolioFundID != "" {
+		pf, err := s.pfRepo.GetPortfolioFund(portfolioFundID)
+		if errors.Is(err, apperrors.ErrPortfolioFundNotFound) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("get portfolio fund: %w", err)
+		}
+		return []model.RegenTarget{{PortfolioID: pf.PortfolioID, PortfolioFundID: pf.ID}}, nil
+	}
+	return nil, fmt.Errorf("RegenerateMaterializedTable: at least one of portfolioIDs, fundID, or portfolioFundID must be provided")
+}
+
 // summarisePortfolioResult builds a compact log string showing each portfolio's name
 // and how many date entries it appears in, e.g. "3 portfolios: Savings (366), ISA (366), SIPP (200)".
 func summarisePortfolioResult(history []model.PortfolioHistory) string {
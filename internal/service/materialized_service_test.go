@@ -2,14 +2,87 @@ package service_test
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"slices"
 	"testing"
 	"time"
 
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/apperrors"
 	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/model"
 	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/repository"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/service"
 	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/testutil"
 )
 
+// =============================================================================
+// REGENERATION QUEUE
+// =============================================================================
+
+// waitForEmptyRegenQueue polls until the regeneration queue is drained.
+func waitForEmptyRegenQueue(t *testing.T, svc interface{ RegenQueueLength() int }) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for svc.RegenQueueLength() > 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("regeneration queue not drained, %d entries left", svc.RegenQueueLength())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// TestMaterializedService_RegenQueue tests the persistent regeneration queue.
+//
+// WHY: Regeneration requests must survive a restart. A worker started on a database
+// with entries left by a previous process has to drain them without any new request.
+func TestMaterializedService_RegenQueue(t *testing.T) {
+	t.Run("worker drains entries left from before a restart", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		svc := testutil.NewTestMaterializedService(t, db)
+
+		portfolio := testutil.NewPortfolio().Build(t, db)
+		fund := testutil.NewFund().Build(t, db)
+		pf := testutil.NewPortfolioFund(portfolio.ID, fund.ID).Build(t, db)
+		txDate := time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)
+		testutil.NewTransaction(pf.ID).WithDate(txDate).WithShares(100).WithCostPerShare(10.0).Build(t, db)
+		testutil.NewFundPrice(fund.ID).WithDate(txDate).WithPrice(10.0).Build(t, db)
+
+		if err := repository.NewMaterializedRepository(db).EnqueueRegen(context.Background(), []model.RegenTarget{{PortfolioID: portfolio.ID}}, txDate, time.Now()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		testutil.StartRegenWorker(t, svc)
+		waitForEmptyRegenQueue(t, svc)
+
+		if testutil.CountRows(t, db, "fund_history_materialized") == 0 {
+			t.Error("expected materialized rows after the worker drained the queue")
+		}
+	})
+
+	t.Run("schedule queues the affected portfolio fund from the earliest date", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		svc := testutil.NewTestMaterializedService(t, db)
+
+		portfolio := testutil.NewPortfolio().Build(t, db)
+		fund := testutil.NewFund().Build(t, db)
+		pf := testutil.NewPortfolioFund(portfolio.ID, fund.ID).Build(t, db)
+
+		svc.ScheduleRegeneration(context.Background(), time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC), nil, "", pf.ID)
+		svc.ScheduleRegeneration(context.Background(), time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), nil, fund.ID, "")
+
+		queue, err := svc.GetRegenQueue()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(queue) != 1 || queue[0].PortfolioID != portfolio.ID || queue[0].PortfolioFundID != pf.ID {
+			t.Fatalf("expected one entry for the portfolio fund, got %+v", queue)
+		}
+		if got := queue[0].StartDate.Format("2006-01-02"); got != "2025-01-01" {
+			t.Errorf("expected dirty from 2025-01-01, got %s", got)
+		}
+	})
+}
+
 // =============================================================================
 // REGENERATE MATERIALIZED TABLE
 // =============================================================================
@@ -182,6 +255,129 @@ func TestMaterializedService_RegenerateMaterializedTable(t *testing.T) {
 	})
 }
 
+// TestMaterializedService_IncrementalRegeneration tests per-portfolio-fund regeneration.
+//
+// WHY: A backdated change to one fund must only rewrite that fund's rows from the
+// change date, rolled forward from the row before it, and still produce exactly what
+// a full recalculation would — including sells, fees, realized gains and reinvested
+// dividends after the change.
+func TestMaterializedService_IncrementalRegeneration(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	svc := testutil.NewTestMaterializedService(t, db)
+	ctx := context.Background()
+	day := func(m time.Month, d int) time.Time { return time.Date(2025, m, d, 0, 0, 0, 0, time.UTC) }
+
+	portfolio := testutil.NewPortfolio().Build(t, db)
+	fund1 := testutil.NewFund().Build(t, db)
+	fund2 := testutil.NewFund().Build(t, db)
+	pf1 := testutil.NewPortfolioFund(portfolio.ID, fund1.ID).Build(t, db)
+	pf2 := testutil.NewPortfolioFund(portfolio.ID, fund2.ID).Build(t, db)
+
+	testutil.NewTransaction(pf1.ID).WithDate(day(1, 2)).WithShares(100).WithCostPerShare(10.0).Build(t, db)
+	sell := testutil.NewTransaction(pf1.ID).WithDate(day(2, 10)).WithType("sell").WithShares(30).WithCostPerShare(12.0).Build(t, db)
+	testutil.NewRealizedGainLoss(portfolio.ID, fund1.ID, sell.ID).WithDate(day(2, 10)).
+		WithShares(30).WithCostBasis(300).WithSaleProceeds(360).Build(t, db)
+	testutil.NewTransaction(pf1.ID).WithDate(day(3, 1)).WithType("fee").WithShares(0).WithCostPerShare(5.0).Build(t, db)
+	reinvest := testutil.NewTransaction(pf1.ID).WithDate(day(4, 2)).WithType("dividend").WithShares(2).WithCostPerShare(12.5).Build(t, db)
+	testutil.NewDividend(fund1.ID, pf1.ID).WithExDividendDate(day(3, 28)).WithRecordDate(day(3, 27)).
+		WithSharesOwned(70).WithDividendPerShare(0.5).WithReinvestmentTransaction(reinvest.ID).Build(t, db)
+	testutil.NewTransaction(pf2.ID).WithDate(day(1, 2)).WithShares(50).WithCostPerShare(20.0).Build(t, db)
+	for i, d := range []time.Time{day(1, 2), day(1, 25), day(2, 14), day(3, 10), day(4, 1), day(5, 5)} {
+		testutil.NewFundPrice(fund1.ID).WithDate(d).WithPrice(10.0+float64(i)*0.75).Build(t, db)
+		testutil.NewFundPrice(fund2.ID).WithDate(d).WithPrice(20.0-float64(i)*0.5).Build(t, db)
+	}
+
+	if err := svc.RegenerateMaterializedTable(ctx, day(1, 2), []string{portfolio.ID}, "", ""); err != nil {
+		t.Fatalf("initial regeneration: %v", err)
+	}
+
+	rowIDs := func(pfID string) map[string]string {
+		t.Helper()
+		rows, err := db.Query(`SELECT date, id FROM fund_history_materialized WHERE portfolio_fund_id = ?`, pfID)
+		if err != nil {
+			t.Fatalf("query rows: %v", err)
+		}
+		defer rows.Close()
+		ids := make(map[string]string)
+		for rows.Next() {
+			var date, id string
+			if err := rows.Scan(&date, &id); err != nil {
+				t.Fatalf("scan row: %v", err)
+			}
+			ids[date] = id
+		}
+		return ids
+	}
+	pf1Before := rowIDs(pf1.ID)
+	pf2Before := rowIDs(pf2.ID)
+
+	// Backdated buy in the first fund.
+	testutil.NewTransaction(pf1.ID).WithDate(day(1, 20)).WithShares(40).WithCostPerShare(11.0).Build(t, db)
+	if err := svc.RegenerateMaterializedTable(ctx, day(1, 20), nil, "", pf1.ID); err != nil {
+		t.Fatalf("incremental regeneration: %v", err)
+	}
+
+	t.Run("other funds are not rewritten", func(t *testing.T) {
+		pf2After := rowIDs(pf2.ID)
+		if len(pf2After) != len(pf2Before) {
+			t.Fatalf("expected %d rows for the untouched fund, got %d", len(pf2Before), len(pf2After))
+		}
+		for date, id := range pf2Before {
+			if pf2After[date] != id {
+				t.Fatalf("row for %s was rewritten", date)
+			}
+		}
+	})
+
+	t.Run("rows before the change date are kept", func(t *testing.T) {
+		pf1After := rowIDs(pf1.ID)
+		for date, id := range pf1Before {
+			if date < "2025-01-20" && pf1After[date] != id {
+				t.Errorf("row for %s was rewritten", date)
+			}
+			if date >= "2025-01-20" && pf1After[date] == id {
+				t.Errorf("row for %s was not regenerated", date)
+			}
+		}
+	})
+
+	t.Run("matches a full recalculation", func(t *testing.T) {
+		expected, err := svc.ExportCalculateFundHistoryOnFly(portfolio.ID, day(1, 2), time.Now().UTC())
+		if err != nil {
+			t.Fatalf("calculate on the fly: %v", err)
+		}
+		actual := make(map[string]model.FundHistoryEntry)
+		err = repository.NewMaterializedRepository(db).GetFundHistoryMaterialized(portfolio.ID, day(1, 1), time.Now().UTC(),
+			func(e model.FundHistoryEntry) error {
+				actual[e.Date.Format("2006-01-02")+"/"+e.PortfolioFundID] = e
+				return nil
+			})
+		if err != nil {
+			t.Fatalf("get materialized history: %v", err)
+		}
+
+		compared := 0
+		for _, h := range expected {
+			for _, want := range h.Funds {
+				key := h.Date.Format("2006-01-02") + "/" + want.PortfolioFundID
+				got, ok := actual[key]
+				if !ok {
+					t.Fatalf("missing materialized row %s", key)
+				}
+				if got.Shares != want.Shares || got.Cost != want.Cost || got.Value != want.Value ||
+					got.RealizedGain != want.RealizedGain || got.Dividends != want.Dividends ||
+					got.Fees != want.Fees || got.SaleProceeds != want.SaleProceeds || got.OriginalCost != want.OriginalCost {
+					t.Fatalf("row %s differs from full recalculation:\n got  %+v\n want %+v", key, got, want)
+				}
+				compared++
+			}
+		}
+		if compared != len(actual) {
+			t.Errorf("expected %d materialized rows, got %d", compared, len(actual))
+		}
+	})
+}
+
 // =============================================================================
 // PORTFOLIO HISTORY WITH FALLBACK
 // =============================================================================
@@ -209,7 +405,7 @@ func TestMaterializedService_GetPortfolioHistoryWithFallback(t *testing.T) {
 		}
 
 		// No materialized data exists — should fall back
-		result, err := svc.GetPortfolioHistoryWithFallback(txDate, endDate, portfolio.ID)
+		result, err := svc.GetPortfolioHistoryWithFallback(context.Background(), txDate, endDate, portfolio.ID, model.HistoryIntervalDay)
 		if err != nil {
 			t.Fatalf("GetPortfolioHistoryWithFallback() error: %v", err)
 		}
@@ -243,7 +439,7 @@ func TestMaterializedService_GetPortfolioHistoryWithFallback(t *testing.T) {
 		}
 
 		// Query with endDate within materialized range
-		result, err := svc.GetPortfolioHistoryWithFallback(txDate, endDate, portfolio.ID)
+		result, err := svc.GetPortfolioHistoryWithFallback(context.Background(), txDate, endDate, portfolio.ID, model.HistoryIntervalDay)
 		if err != nil {
 			t.Fatalf("GetPortfolioHistoryWithFallback() error: %v", err)
 		}
@@ -253,6 +449,67 @@ func TestMaterializedService_GetPortfolioHistoryWithFallback(t *testing.T) {
 		}
 	})
 
+	t.Run("weekly and monthly history match between on-demand and materialized", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		svc := testutil.NewTestMaterializedService(t, db)
+
+		portfolio := testutil.NewPortfolio().Build(t, db)
+		fund := testutil.NewFund().Build(t, db)
+		pf := testutil.NewPortfolioFund(portfolio.ID, fund.ID).Build(t, db)
+
+		// Wednesday 2025-01-15 through Monday 2025-02-10.
+		txDate := time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)
+		endDate := time.Date(2025, 2, 10, 0, 0, 0, 0, time.UTC)
+
+		testutil.NewTransaction(pf.ID).WithDate(txDate).WithShares(100).WithCostPerShare(10.0).Build(t, db)
+		for i, d := 0, txDate; !d.After(endDate); i, d = i+1, d.AddDate(0, 0, 1) {
+			testutil.NewFundPrice(fund.ID).WithDate(d).WithPrice(10.0+float64(i)*0.25).Build(t, db)
+		}
+
+		want := map[model.HistoryInterval][]string{
+			model.HistoryIntervalWeek:  {"2025-01-19", "2025-01-26", "2025-02-02", "2025-02-09", "2025-02-10"},
+			model.HistoryIntervalMonth: {"2025-01-31", "2025-02-10"},
+		}
+
+		// On-demand first: nothing is materialized yet.
+		onDemand := make(map[model.HistoryInterval][]model.PortfolioHistory)
+		for interval := range want {
+			result, err := svc.GetPortfolioHistoryWithFallback(context.Background(), txDate, endDate, portfolio.ID, interval)
+			if err != nil {
+				t.Fatalf("GetPortfolioHistoryWithFallback(%s) error: %v", interval, err)
+			}
+			onDemand[interval] = result
+		}
+
+		if err := svc.RegenerateMaterializedTable(context.Background(), time.Time{}, []string{portfolio.ID}, "", ""); err != nil {
+			t.Fatalf("RegenerateMaterializedTable() error: %v", err)
+		}
+
+		for interval, dates := range want {
+			materialized, err := svc.GetPortfolioHistoryMaterialized(context.Background(), txDate, endDate, portfolio.ID, interval)
+			if err != nil {
+				t.Fatalf("GetPortfolioHistoryMaterialized(%s) error: %v", interval, err)
+			}
+			for source, result := range map[string][]model.PortfolioHistory{"on-demand": onDemand[interval], "materialized": materialized} {
+				got := make([]string, len(result))
+				for i, h := range result {
+					got[i] = h.Date
+				}
+				if !slices.Equal(got, dates) {
+					t.Errorf("%s %s dates = %v, want %v", source, interval, got, dates)
+				}
+			}
+			if len(materialized) != len(onDemand[interval]) {
+				continue
+			}
+			for i := range materialized {
+				if m, o := materialized[i].Portfolios[0].TotalValue, onDemand[interval][i].Portfolios[0].TotalValue; m != o {
+					t.Errorf("%s %s value: materialized %f, on-demand %f", interval, materialized[i].Date, m, o)
+				}
+			}
+		}
+	})
+
 	t.Run("returns data for all active portfolios when no portfolioID specified", func(t *testing.T) {
 		db := testutil.SetupTestDB(t)
 		svc := testutil.NewTestMaterializedService(t, db)
@@ -278,7 +535,7 @@ func TestMaterializedService_GetPortfolioHistoryWithFallback(t *testing.T) {
 		}
 
 		// Empty portfolioID = all active portfolios
-		result, err := svc.GetPortfolioHistoryWithFallback(txDate, endDate, "")
+		result, err := svc.GetPortfolioHistoryWithFallback(context.Background(), txDate, endDate, "", model.HistoryIntervalDay)
 		if err != nil {
 			t.Fatalf("GetPortfolioHistoryWithFallback() error: %v", err)
 		}
@@ -324,7 +581,7 @@ func TestMaterializedService_GetFundHistoryWithFallback(t *testing.T) {
 			testutil.NewFundPrice(fund.ID).WithDate(d).WithPrice(10.0).Build(t, db)
 		}
 
-		result, err := svc.GetFundHistoryWithFallback(portfolio.ID, txDate, endDate)
+		result, err := svc.GetFundHistoryWithFallback(context.Background(), portfolio.ID, txDate, endDate)
 		if err != nil {
 			t.Fatalf("GetFundHistoryWithFallback() error: %v", err)
 		}
@@ -364,7 +621,7 @@ func TestMaterializedService_GetFundHistoryWithFallback(t *testing.T) {
 			t.Fatalf("RegenerateMaterializedTable() error: %v", err)
 		}
 
-		result, err := svc.GetFundHistoryWithFallback(portfolio.ID, txDate, endDate)
+		result, err := svc.GetFundHistoryWithFallback(context.Background(), portfolio.ID, txDate, endDate)
 		if err != nil {
 			t.Fatalf("GetFundHistoryWithFallback() error: %v", err)
 		}
@@ -395,7 +652,7 @@ func TestMaterializedService_GetFundHistoryWithFallback(t *testing.T) {
 			WithPrice(12.0). // Price > cost = unrealized gain
 			Build(t, db)
 
-		result, err := svc.GetFundHistoryWithFallback(portfolio.ID, txDate, txDate)
+		result, err := svc.GetFundHistoryWithFallback(context.Background(), portfolio.ID, txDate, txDate)
 		if err != nil {
 			t.Fatalf("GetFundHistoryWithFallback() error: %v", err)
 		}
@@ -465,7 +722,7 @@ func TestMaterializedService_StaleDetection(t *testing.T) {
 		}
 
 		// Get initial results
-		resultBefore, err := svc.GetPortfolioHistoryWithFallback(txDate, endDate, portfolio.ID)
+		resultBefore, err := svc.GetPortfolioHistoryWithFallback(context.Background(), txDate, endDate, portfolio.ID, model.HistoryIntervalDay)
 		if err != nil {
 			t.Fatalf("first call error: %v", err)
 		}
@@ -478,7 +735,7 @@ func TestMaterializedService_StaleDetection(t *testing.T) {
 			Build(t, db)
 
 		// Should detect staleness and fall back to on-demand
-		resultAfter, err := svc.GetPortfolioHistoryWithFallback(txDate, endDate, portfolio.ID)
+		resultAfter, err := svc.GetPortfolioHistoryWithFallback(context.Background(), txDate, endDate, portfolio.ID, model.HistoryIntervalDay)
 		if err != nil {
 			t.Fatalf("second call error: %v", err)
 		}
@@ -535,7 +792,7 @@ func TestMaterializedService_StaleDetection(t *testing.T) {
 		}
 
 		// Should detect that latest price date (Jan 20) > materialized max date (Jan 18)
-		result, err := svc.GetPortfolioHistoryWithFallback(txDate, endDate, portfolio.ID)
+		result, err := svc.GetPortfolioHistoryWithFallback(context.Background(), txDate, endDate, portfolio.ID, model.HistoryIntervalDay)
 		if err != nil {
 			t.Fatalf("GetPortfolioHistoryWithFallback() error: %v", err)
 		}
@@ -560,6 +817,7 @@ func TestMaterializedService_StaleDetection(t *testing.T) {
 		//     present for every date including today.
 		db := testutil.SetupTestDB(t)
 		svc := testutil.NewTestMaterializedService(t, db)
+		testutil.StartRegenWorker(t, svc)
 
 		fundA := testutil.NewFund().Build(t, db)
 		fundB := testutil.NewFund().Build(t, db)
@@ -596,7 +854,7 @@ func TestMaterializedService_StaleDetection(t *testing.T) {
 		}
 
 		// ── 1. Fallback returns data for both portfolios on today ─────────────────
-		result, err := svc.GetPortfolioHistoryWithFallback(twoDaysAgo, today, "")
+		result, err := svc.GetPortfolioHistoryWithFallback(context.Background(), twoDaysAgo, today, "", model.HistoryIntervalDay)
 		if err != nil {
 			t.Fatalf("GetPortfolioHistoryWithFallback: %v", err)
 		}
@@ -646,7 +904,7 @@ func TestMaterializedService_StaleDetection(t *testing.T) {
 		}
 
 		// ── 3. Second call uses the repaired materialized view ───────────────────
-		result2, err := svc.GetPortfolioHistoryWithFallback(twoDaysAgo, today, "")
+		result2, err := svc.GetPortfolioHistoryWithFallback(context.Background(), twoDaysAgo, today, "", model.HistoryIntervalDay)
 		if err != nil {
 			t.Fatalf("second GetPortfolioHistoryWithFallback: %v", err)
 		}
@@ -690,7 +948,7 @@ func TestMaterializedService_StaleDetection(t *testing.T) {
 			Build(t, db)
 
 		// Should detect that dividend created_at > materialized calculated_at
-		result, err := svc.GetPortfolioHistoryWithFallback(txDate, endDate, portfolio.ID)
+		result, err := svc.GetPortfolioHistoryWithFallback(context.Background(), txDate, endDate, portfolio.ID, model.HistoryIntervalDay)
 		if err != nil {
 			t.Fatalf("GetPortfolioHistoryWithFallback() error: %v", err)
 		}
@@ -719,7 +977,7 @@ func TestMaterializedService_GetPortfolioHistory(t *testing.T) {
 		startDate := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
 		endDate := time.Date(2025, 1, 5, 0, 0, 0, 0, time.UTC)
 
-		result, err := svc.GetPortfolioHistory(startDate, endDate, portfolio.ID)
+		result, err := svc.GetPortfolioHistory(context.Background(), startDate, endDate, portfolio.ID)
 		if err != nil {
 			t.Fatalf("GetPortfolioHistory() error: %v", err)
 		}
@@ -757,7 +1015,7 @@ func TestMaterializedService_GetPortfolioHistory(t *testing.T) {
 		}
 
 		endDate := txDate.AddDate(0, 0, 2)
-		result, err := svc.GetPortfolioHistory(txDate, endDate, portfolio.ID)
+		result, err := svc.GetPortfolioHistory(context.Background(), txDate, endDate, portfolio.ID)
 		if err != nil {
 			t.Fatalf("GetPortfolioHistory() error: %v", err)
 		}
@@ -807,7 +1065,7 @@ func TestMaterializedService_GetPortfolioSummaryWithFallback(t *testing.T) {
 			WithPrice(12.0).
 			Build(t, db)
 
-		summaries, err := svc.GetPortfolioSummaryWithFallback(portfolio.ID)
+		summaries, err := svc.GetPortfolioSummaryWithFallback(context.Background(), portfolio.ID)
 		if err != nil {
 			t.Fatalf("GetPortfolioSummaryWithFallback() error: %v", err)
 		}
@@ -833,7 +1091,7 @@ func TestMaterializedService_GetPortfolioSummaryWithFallback(t *testing.T) {
 
 		testutil.NewPortfolio().Build(t, db)
 
-		summaries, err := svc.GetPortfolioSummaryWithFallback("")
+		summaries, err := svc.GetPortfolioSummaryWithFallback(context.Background(), "")
 		if err != nil {
 			t.Fatalf("GetPortfolioSummaryWithFallback() error: %v", err)
 		}
@@ -868,7 +1126,7 @@ func TestMaterializedService_GetPortfolioSummaryWithFallback(t *testing.T) {
 			t.Fatalf("RegenerateMaterializedTable() error: %v", err)
 		}
 
-		summaries, err := svc.GetPortfolioSummaryWithFallback(portfolio.ID)
+		summaries, err := svc.GetPortfolioSummaryWithFallback(context.Background(), portfolio.ID)
 		if err != nil {
 			t.Fatalf("GetPortfolioSummaryWithFallback() error: %v", err)
 		}
@@ -881,3 +1139,124 @@ func TestMaterializedService_GetPortfolioSummaryWithFallback(t *testing.T) {
 		}
 	})
 }
+
+// TestMaterializedService_Admin tests the admin coverage, rebuild and verification methods.
+//
+// WHY: These are the tools for diagnosing wrong numbers. Coverage must report the same
+// staleness the read path acts on, and verification must catch rows that no longer match
+// a fresh calculation, which a rebuild must then repair.
+func TestMaterializedService_Admin(t *testing.T) {
+	setup := func(t *testing.T) (*sql.DB, *service.MaterializedService, model.Portfolio, model.PortfolioFund, time.Time) {
+		t.Helper()
+		db := testutil.SetupTestDB(t)
+		svc := testutil.NewTestMaterializedService(t, db)
+
+		portfolio := testutil.NewPortfolio().WithName("Audited").Build(t, db)
+		fund := testutil.NewFund().Build(t, db)
+		pf := testutil.NewPortfolioFund(portfolio.ID, fund.ID).Build(t, db)
+
+		txDate := time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)
+		testutil.NewTransaction(pf.ID).WithDate(txDate).WithShares(100).WithCostPerShare(10.0).Build(t, db)
+		for i := range 10 {
+			testutil.NewFundPrice(fund.ID).WithDate(txDate.AddDate(0, 0, i)).WithPrice(10.0+float64(i)*0.5).Build(t, db)
+		}
+		return db, svc, portfolio, pf, txDate
+	}
+
+	t.Run("coverage reports missing data and then the materialized range", func(t *testing.T) {
+		_, svc, portfolio, _, txDate := setup(t)
+		ctx := context.Background()
+
+		coverage, err := svc.GetMaterializedCoverage(ctx, portfolio.ID)
+		if err != nil {
+			t.Fatalf("GetMaterializedCoverage() error: %v", err)
+		}
+		if len(coverage) != 1 || !coverage[0].Stale || coverage[0].StaleReason != model.StaleReasonNoData || coverage[0].FirstDate != nil {
+			t.Fatalf("expected stale coverage without data, got %+v", coverage)
+		}
+
+		coverage, err = svc.RebuildMaterialized(ctx, portfolio.ID, time.Time{})
+		if err != nil {
+			t.Fatalf("RebuildMaterialized() error: %v", err)
+		}
+		if len(coverage) != 1 {
+			t.Fatalf("expected 1 portfolio, got %d", len(coverage))
+		}
+		c := coverage[0]
+		if c.Stale || c.StaleReason != model.StaleReasonNone {
+			t.Errorf("expected fresh coverage after rebuild, got reason %q", c.StaleReason)
+		}
+		if c.FirstDate == nil || !c.FirstDate.Equal(txDate) {
+			t.Errorf("expected first date %s, got %v", txDate.Format("2006-01-02"), c.FirstDate)
+		}
+		if c.PortfolioName != "Audited" || c.Funds != 1 || c.Rows == 0 || c.CalculatedAt == nil {
+			t.Errorf("unexpected coverage: %+v", c)
+		}
+	})
+
+	t.Run("verify reports drift and a rebuild repairs it", func(t *testing.T) {
+		db, svc, portfolio, pf, txDate := setup(t)
+		ctx := context.Background()
+		endDate := txDate.AddDate(0, 0, 9)
+
+		if _, err := svc.RebuildMaterialized(ctx, portfolio.ID, time.Time{}); err != nil {
+			t.Fatalf("RebuildMaterialized() error: %v", err)
+		}
+
+		result, err := svc.VerifyMaterialized(ctx, portfolio.ID, txDate, endDate)
+		if err != nil {
+			t.Fatalf("VerifyMaterialized() error: %v", err)
+		}
+		if len(result) != 1 || result[0].DriftCount != 0 || result[0].RowsChecked != 10 {
+			t.Fatalf("expected 10 clean rows, got %+v", result)
+		}
+
+		// Corrupt one day's value and drop another day's row.
+		if _, err := db.Exec(`UPDATE fund_history_materialized SET value = value + 1 WHERE portfolio_fund_id = ? AND date = ?`,
+			pf.ID, txDate.AddDate(0, 0, 3).Format("2006-01-02")); err != nil {
+			t.Fatalf("corrupt value: %v", err)
+		}
+		if _, err := db.Exec(`DELETE FROM fund_history_materialized WHERE portfolio_fund_id = ? AND date = ?`,
+			pf.ID, txDate.AddDate(0, 0, 5).Format("2006-01-02")); err != nil {
+			t.Fatalf("delete row: %v", err)
+		}
+
+		result, err = svc.VerifyMaterialized(ctx, portfolio.ID, txDate, endDate)
+		if err != nil {
+			t.Fatalf("VerifyMaterialized() error: %v", err)
+		}
+		if result[0].DriftCount != 2 {
+			t.Fatalf("expected 2 drift entries, got %+v", result[0].Drift)
+		}
+		kinds := map[model.MaterializedDriftKind]model.MaterializedDrift{}
+		for _, d := range result[0].Drift {
+			kinds[d.Kind] = d
+		}
+		if d, ok := kinds[model.DriftMismatch]; !ok || d.Field != "value" || d.Materialized-d.Expected != 1 {
+			t.Errorf("expected a value mismatch of 1, got %+v", result[0].Drift)
+		}
+		if d, ok := kinds[model.DriftMissing]; !ok || !d.Date.Equal(txDate.AddDate(0, 0, 5)) {
+			t.Errorf("expected a missing row on day 5, got %+v", result[0].Drift)
+		}
+
+		if _, err := svc.RebuildMaterialized(ctx, portfolio.ID, time.Time{}); err != nil {
+			t.Fatalf("RebuildMaterialized() error: %v", err)
+		}
+		result, err = svc.VerifyMaterialized(ctx, portfolio.ID, txDate, endDate)
+		if err != nil {
+			t.Fatalf("VerifyMaterialized() error: %v", err)
+		}
+		if result[0].DriftCount != 0 {
+			t.Errorf("expected no drift after rebuild, got %+v", result[0].Drift)
+		}
+	})
+
+	t.Run("unknown portfolio returns ErrPortfolioNotFound", func(t *testing.T) {
+		_, svc, _, _, _ := setup(t)
+
+		_, err := svc.GetMaterializedCoverage(context.Background(), testutil.MakeID())
+		if !errors.Is(err, apperrors.ErrPortfolioNotFound) {
+			t.Errorf("expected ErrPortfolioNotFound, got %v", err)
+		}
+	})
+}
@@ -130,7 +130,7 @@ func (data *PortfolioData) MapRealizedGainsByPF(portfolioID string) map[string][
 //
 // Usage:
 //
-//	portfolios, _ := portfolioService.GetPortfoliosForRequest("some-id")
+//	portfolios, _ := portfolioService.GetPortfoliosForRequest(ctx, "some-id")
 //	data, err := dataLoaderService.LoadForPortfolios(portfolios, startDate, endDate)
 //	if err != nil {
 //	    return err
@@ -7,9 +7,11 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/api/request"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/auth"
 	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/logging"
 	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/model"
 	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/repository"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/tracing"
 )
 
 var pfLog = logging.NewLogger("portfolio")
@@ -21,6 +23,8 @@ type PortfolioService struct {
 	db            *sql.DB
 	portfolioRepo *repository.PortfolioRepository
 	pfRepo        *repository.PortfolioFundRepository
+	auditRepo     *repository.AuditRepository
+	trashRepo     *repository.TrashRepository
 }
 
 // NewPortfolioService creates a new PortfolioService with the provided repository dependencies.
@@ -33,16 +37,19 @@ func NewPortfolioService(
 		db:            db,
 		portfolioRepo: portfolioRepo,
 		pfRepo:        pfRepo,
+		auditRepo:     repository.NewAuditRepository(db),
+		trashRepo:     repository.NewTrashRepository(db),
 	}
 }
 
-// GetAllPortfolios retrieves all portfolios from the database with no filters applied.
+// GetAllPortfolios retrieves every portfolio the caller in ctx may read.
 // This includes both archived and excluded portfolios.
-func (s *PortfolioService) GetAllPortfolios() ([]model.Portfolio, error) {
+func (s *PortfolioService) GetAllPortfolios(ctx context.Context) ([]model.Portfolio, error) {
 	pfLog.Debug("retrieving all portfolios")
 	result, err := s.portfolioRepo.GetPortfolios(model.PortfolioFilter{
 		IncludeArchived: true,
 		IncludeExcluded: true,
+		Scope:           auth.Scope(ctx),
 	})
 	if err != nil {
 		return nil, fmt.Errorf("get all portfolios: %w", err)
@@ -62,13 +69,14 @@ func (s *PortfolioService) GetPortfolio(portfolioID string) (model.Portfolio, er
 	return result, nil
 }
 
-// LoadActivePortfolios retrieves only active, non-excluded portfolios.
-// Archived and excluded portfolios are filtered out.
-func (s *PortfolioService) LoadActivePortfolios() ([]model.Portfolio, error) {
+// LoadActivePortfolios retrieves only the active, non-excluded portfolios the caller in ctx
+// may read. Archived and excluded portfolios are filtered out.
+func (s *PortfolioService) LoadActivePortfolios(ctx context.Context) ([]model.Portfolio, error) {
 	pfLog.Debug("loading active portfolios")
 	result, err := s.portfolioRepo.GetPortfolios(model.PortfolioFilter{
 		IncludeArchived: false,
 		IncludeExcluded: false,
+		Scope:           auth.Scope(ctx),
 	})
 	if err != nil {
 		return nil, fmt.Errorf("load active portfolios: %w", err)
@@ -102,18 +110,19 @@ func (s *PortfolioService) LoadAllPortfolioFunds(portfolios []model.Portfolio) (
 // across multiple service methods.
 //
 // Parameters:
+//   - ctx: Request context; "all portfolios" is limited to those the caller may read
 //   - portfolioID: Optional portfolio ID. Empty string means "all portfolios"
 //
 // Returns:
 //   - If portfolioID is provided: a slice containing just that portfolio
-//   - If portfolioID is empty: all active, non-excluded portfolios
+//   - If portfolioID is empty: all active, non-excluded portfolios the caller may read
 //   - Error if the specific portfolio ID is not found or database query fails
 //
 // Usage in other services:
 //
-//	portfolios, err := portfolioService.GetPortfoliosForRequest(portfolioID)
+//	portfolios, err := portfolioService.GetPortfoliosForRequest(ctx, portfolioID)
 //	// portfolios is always a slice, simplifying downstream code
-func (s *PortfolioService) GetPortfoliosForRequest(portfolioID string) ([]model.Portfolio, error) {
+func (s *PortfolioService) GetPortfoliosForRequest(ctx context.Context, portfolioID string) ([]model.Portfolio, error) {
 	pfLog.Debug("resolving portfolios for request", "portfolioID", portfolioID)
 	if portfolioID != "" {
 		portfolio, err := s.portfolioRepo.GetPortfolioOnID(portfolioID)
@@ -128,6 +137,7 @@ func (s *PortfolioService) GetPortfoliosForRequest(portfolioID string) ([]model.
 	result, err := s.portfolioRepo.GetPortfolios(model.PortfolioFilter{
 		IncludeArchived: false,
 		IncludeExcluded: false,
+		Scope:           auth.Scope(ctx),
 	})
 	if err != nil {
 		return nil, fmt.Errorf("get active portfolios: %w", err)
@@ -136,7 +146,10 @@ func (s *PortfolioService) GetPortfoliosForRequest(portfolioID string) ([]model.
 }
 
 // CreatePortfolio creates a new portfolio record and returns the created portfolio.
+// The portfolio is owned by the authenticated caller, if any.
 func (s *PortfolioService) CreatePortfolio(ctx context.Context, req request.CreatePortfolioRequest) (*model.Portfolio, error) {
+	ctx, span := tracing.Start(ctx, "PortfolioService.CreatePortfolio")
+	defer span.End()
 	pfLog.DebugContext(ctx, "creating portfolio", "name", req.Name)
 	portfolio := &model.Portfolio{
 		ID:                  uuid.New().String(),
@@ -145,11 +158,28 @@ func (s *PortfolioService) CreatePortfolio(ctx context.Context, req request.Crea
 		IsArchived:          false,
 		ExcludeFromOverview: req.ExcludeFromOverview,
 	}
+	if p := auth.PrincipalFromContext(ctx); p != nil {
+		portfolio.OwnerID = p.User.ID
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }() //nolint:errcheck // Rollback is a no-op after Commit; error is intentionally ignored.
 
-	if err := s.portfolioRepo.InsertPortfolio(ctx, portfolio); err != nil {
+	if err := s.portfolioRepo.WithTx(tx).InsertPortfolio(ctx, portfolio); err != nil {
 		return nil, fmt.Errorf("failed to create portfolio: %w", err)
 	}
 
+	if err := recordAudit(ctx, s.auditRepo.WithTx(tx), model.AuditEntityPortfolio, portfolio.ID, model.AuditActionCreate, nil, portfolio); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit transaction: %w", err)
+	}
+
 	pfLog.InfoContext(ctx, "portfolio created", "portfolioID", portfolio.ID, "name", portfolio.Name)
 	return portfolio, nil
 }
@@ -160,6 +190,8 @@ func (s *PortfolioService) UpdatePortfolio(
 	id string,
 	req request.UpdatePortfolioRequest,
 ) (*model.Portfolio, error) {
+	ctx, span := tracing.Start(ctx, "PortfolioService.UpdatePortfolio")
+	defer span.End()
 	pfLog.DebugContext(ctx, "updating portfolio", "portfolioID", id)
 
 	tx, err := s.db.BeginTx(ctx, nil)
@@ -172,6 +204,7 @@ func (s *PortfolioService) UpdatePortfolio(
 	if err != nil {
 		return nil, fmt.Errorf("get portfolio: %w", err)
 	}
+	before := portfolio
 
 	if req.Name != nil {
 		portfolio.Name = *req.Name
@@ -190,6 +223,10 @@ func (s *PortfolioService) UpdatePortfolio(
 		return nil, fmt.Errorf("failed to update portfolio: %w", err)
 	}
 
+	if err := recordAudit(ctx, s.auditRepo.WithTx(tx), model.AuditEntityPortfolio, id, model.AuditActionUpdate, before, portfolio); err != nil {
+		return nil, err
+	}
+
 	if err := tx.Commit(); err != nil {
 		return nil, fmt.Errorf("commit transaction: %w", err)
 	}
@@ -200,6 +237,8 @@ func (s *PortfolioService) UpdatePortfolio(
 
 // DeletePortfolio deletes a portfolio and all associated portfolio-fund relationships.
 func (s *PortfolioService) DeletePortfolio(ctx context.Context, id string) error {
+	ctx, span := tracing.Start(ctx, "PortfolioService.DeletePortfolio")
+	defer span.End()
 	pfLog.DebugContext(ctx, "deleting portfolio", "portfolioID", id)
 
 	tx, err := s.db.BeginTx(ctx, nil)
@@ -208,16 +247,24 @@ func (s *PortfolioService) DeletePortfolio(ctx context.Context, id string) error
 	}
 	defer func() { _ = tx.Rollback() }() //nolint:errcheck // Rollback is a no-op after Commit; error is intentionally ignored.
 
-	_, err = s.portfolioRepo.WithTx(tx).GetPortfolioOnID(id)
+	portfolio, err := s.portfolioRepo.WithTx(tx).GetPortfolioOnID(id)
 	if err != nil {
 		return fmt.Errorf("get portfolio: %w", err)
 	}
 
+	if err := moveToTrash(ctx, s.trashRepo.WithTx(tx), model.TrashEntityPortfolio, id, portfolio.Name, portfolioTrashSpecs(id)); err != nil {
+		return err
+	}
+
 	err = s.portfolioRepo.WithTx(tx).DeletePortfolio(ctx, id)
 	if err != nil {
 		return fmt.Errorf("delete portfolio: %w", err)
 	}
 
+	if err := recordAudit(ctx, s.auditRepo.WithTx(tx), model.AuditEntityPortfolio, id, model.AuditActionDelete, portfolio, nil); err != nil {
+		return err
+	}
+
 	if err = tx.Commit(); err != nil {
 		return fmt.Errorf("commit transaction: %w", err)
 	}
@@ -1,6 +1,7 @@
 package service_test
 
 import (
+	"context"
 	"testing"
 
 	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/model"
@@ -19,7 +20,7 @@ func TestPortfolioService_GetAllPortfolios(t *testing.T) {
 		svc := testutil.NewTestPortfolioService(t, db)
 
 		// Execute
-		portfolios, err := svc.GetAllPortfolios()
+		portfolios, err := svc.GetAllPortfolios(context.Background())
 
 		// Assert
 		if err != nil {
@@ -41,7 +42,7 @@ func TestPortfolioService_GetAllPortfolios(t *testing.T) {
 		p2 := testutil.CreateArchivedPortfolio(t, db, "Archived Portfolio")
 
 		// Execute
-		portfolios, err := svc.GetAllPortfolios()
+		portfolios, err := svc.GetAllPortfolios(context.Background())
 
 		// Assert
 		if err != nil {
@@ -81,7 +82,7 @@ func TestPortfolioService_GetAllPortfolios(t *testing.T) {
 		testutil.CreatePortfolios(t, db, 5)
 
 		// Execute
-		portfolios, err := svc.GetAllPortfolios()
+		portfolios, err := svc.GetAllPortfolios(context.Background())
 
 		// Assert
 		if err != nil {
@@ -108,7 +109,7 @@ func TestPortfolioService_GetAllPortfolios_DatabaseErrors(t *testing.T) {
 		db.Close()
 
 		// Execute
-		portfolios, err := svc.GetAllPortfolios()
+		portfolios, err := svc.GetAllPortfolios(context.Background())
 
 		// Assert
 		if err == nil {
@@ -134,7 +135,7 @@ func TestPortfolioService_LoadActivePortfolios(t *testing.T) {
 		testutil.NewPortfolio().WithName("Archived").Archived().Build(t, db)
 		testutil.NewPortfolio().WithName("Excluded").ExcludedFromOverview().Build(t, db)
 
-		portfolios, err := svc.LoadActivePortfolios()
+		portfolios, err := svc.LoadActivePortfolios(context.Background())
 		if err != nil {
 			t.Fatalf("LoadActivePortfolios() error: %v", err)
 		}
@@ -154,7 +155,7 @@ func TestPortfolioService_LoadActivePortfolios(t *testing.T) {
 		testutil.NewPortfolio().WithName("Archived 1").Archived().Build(t, db)
 		testutil.NewPortfolio().WithName("Archived 2").Archived().Build(t, db)
 
-		portfolios, err := svc.LoadActivePortfolios()
+		portfolios, err := svc.LoadActivePortfolios(context.Background())
 		if err != nil {
 			t.Fatalf("LoadActivePortfolios() error: %v", err)
 		}
@@ -167,7 +168,7 @@ func TestPortfolioService_LoadActivePortfolios(t *testing.T) {
 		db := testutil.SetupTestDB(t)
 		svc := testutil.NewTestPortfolioService(t, db)
 
-		portfolios, err := svc.LoadActivePortfolios()
+		portfolios, err := svc.LoadActivePortfolios(context.Background())
 		if err != nil {
 			t.Fatalf("LoadActivePortfolios() error: %v", err)
 		}
@@ -184,7 +185,7 @@ func TestPortfolioService_LoadActivePortfolios(t *testing.T) {
 		testutil.NewPortfolio().WithName("Active 2").Build(t, db)
 		testutil.NewPortfolio().WithName("Active 3").Build(t, db)
 
-		portfolios, err := svc.LoadActivePortfolios()
+		portfolios, err := svc.LoadActivePortfolios(context.Background())
 		if err != nil {
 			t.Fatalf("LoadActivePortfolios() error: %v", err)
 		}
@@ -312,7 +313,7 @@ func TestPortfolioService_GetAllPortfolios_TableDriven(t *testing.T) {
 			}
 
 			// Execute
-			portfolios, err := svc.GetAllPortfolios()
+			portfolios, err := svc.GetAllPortfolios(context.Background())
 
 			// Assert
 			if err != nil {
@@ -0,0 +1,357 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/api/request"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/apperrors"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/config"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/logging"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/metrics"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/model"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/repository"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/tracing"
+	"github.com/robfig/cron/v3"
+)
+
+var schedulerLog = logging.NewLogger("system")
+
+// scheduleSettingPrefix prefixes the system_setting key of each task's schedule.
+const scheduleSettingPrefix = "SCHEDULE_"
+
+// scheduledTask describes a built-in task the scheduler can run.
+type scheduledTask struct {
+	name            string
+	jobType         model.JobType
+	params          any // Parameters of scheduled runs; "run now" starts the job without them
+	timeout         time.Duration
+	defaultSchedule string
+}
+
+// SchedulerService runs the built-in background tasks on cron schedules. Each task's
+// schedule, time zone and enabled flag start from the config file defaults and can be
+// overridden through system settings; changes apply to the running scheduler immediately.
+// Every run goes through the JobService, so run history is the job table.
+type SchedulerService struct {
+	db            *sql.DB
+	developerRepo *repository.DeveloperRepository
+	jobRepo       *repository.JobRepository
+	auditRepo     *repository.AuditRepository
+	jobService    *JobService
+	tasks         []scheduledTask
+
+	mu      sync.Mutex
+	cron    *cron.Cron
+	entries map[string]cron.EntryID
+}
+
+// NewSchedulerService creates a new SchedulerService with the provided dependencies.
+// defaults supplies the schedule of tasks that have no stored setting.
+// Tasks do not run until Start is called.
+func NewSchedulerService(
+	db *sql.DB,
+	developerRepo *repository.DeveloperRepository,
+	jobRepo *repository.JobRepository,
+	auditRepo *repository.AuditRepository,
+	jobService *JobService,
+	defaults config.SchedulerConfig,
+) *SchedulerService {
+	return &SchedulerService{
+		db:            db,
+		developerRepo: developerRepo,
+		jobRepo:       jobRepo,
+		auditRepo:     auditRepo,
+		jobService:    jobService,
+		tasks: []scheduledTask{
+			// Default 00:55 UTC every weekday.
+			{model.ScheduledTaskFundPriceUpdate, model.JobTypeFundPriceUpdate, nil, 15 * time.Minute, defaults.FundPriceUpdate},
+			// Default between 05:30 and 07:30 UTC Tue-Sat, fetching the previous business
+			// day's close-of-business report. Skipped while automatic import is disabled.
+			{model.ScheduledTaskIbkrImport, model.JobTypeIbkrImport, model.IbkrImportParams{Scheduled: true}, 15 * time.Minute, defaults.IbkrImport},
+			{model.ScheduledTaskTrashPurge, model.JobTypeTrashPurge, nil, 5 * time.Minute, defaults.TrashPurge},
+			{model.ScheduledTaskSessionPurge, model.JobTypeSessionPurge, nil, 5 * time.Minute, defaults.SessionPurge},
+			{model.ScheduledTaskJobPurge, model.JobTypeJobPurge, nil, 5 * time.Minute, defaults.JobPurge},
+			// Default 07:00 UTC on Mondays. Skipped while the digest is disabled.
+			{model.ScheduledTaskEmailDigest, model.JobTypeEmailDigest, model.EmailDigestParams{Scheduled: true}, 5 * time.Minute, defaults.EmailDigest},
+			// Default 01:30 UTC daily. Also submitted once at startup to catch up on missed runs.
+			{model.ScheduledTaskInvestmentPlans, model.JobTypeInvestmentPlans, nil, 15 * time.Minute, defaults.InvestmentPlans},
+		},
+		entries: make(map[string]cron.EntryID),
+	}
+}
+
+// Start schedules every enabled task and starts the cron scheduler.
+func (s *SchedulerService) Start() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cron != nil {
+		return fmt.Errorf("scheduler already started")
+	}
+
+	c := cron.New(
+		cron.WithLocation(time.UTC),
+		cron.WithChain(
+			cron.SkipIfStillRunning(cron.DefaultLogger),
+			cron.Recover(cron.DefaultLogger),
+		),
+	)
+	for _, task := range s.tasks {
+		setting, err := s.getSetting(s.developerRepo, task)
+		if err != nil {
+			return err
+		}
+		if !setting.Enabled {
+			schedulerLog.Info("scheduled task disabled", "task", task.name)
+			continue
+		}
+		id, err := c.AddFunc(cronSpec(setting), s.runScheduled(task))
+		if err != nil {
+			return fmt.Errorf("schedule %s: %w", task.name, err)
+		}
+		s.entries[task.name] = id
+	}
+	c.Start()
+	s.cron = c
+	return nil
+}
+
+// Stop stops scheduling new runs. The returned context is done once running tasks finish.
+func (s *SchedulerService) Stop() context.Context {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cron == nil {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		return ctx
+	}
+	return s.cron.Stop()
+}
+
+// GetTasks returns every scheduled task with its settings, next run and last run.
+func (s *SchedulerService) GetTasks() ([]model.ScheduledTask, error) {
+	tasks := make([]model.ScheduledTask, 0, len(s.tasks))
+	for _, task := range s.tasks {
+		t, err := s.describe(task)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, t)
+	}
+	return tasks, nil
+}
+
+// GetTask returns a single scheduled task. Returns ErrScheduledTaskNotFound if no task has
+// the given name.
+func (s *SchedulerService) GetTask(name string) (model.ScheduledTask, error) {
+	task, err := s.task(name)
+	if err != nil {
+		return model.ScheduledTask{}, err
+	}
+	return s.describe(task)
+}
+
+// UpdateTask changes the schedule, time zone and/or enabled flag of a task and applies
+// the change to the running scheduler. Only fields present in the request are changed.
+// Returns ErrScheduledTaskNotFound if no task has the given name.
+func (s *SchedulerService) UpdateTask(ctx context.Context, name string, req request.UpdateScheduledTaskRequest) (model.ScheduledTask, error) {
+	ctx, span := tracing.Start(ctx, "SchedulerService.UpdateTask")
+	defer span.End()
+
+	task, err := s.task(name)
+	if err != nil {
+		return model.ScheduledTask{}, err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return model.ScheduledTask{}, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }() //nolint:errcheck // Rollback is a no-op after Commit; error is intentionally ignored.
+
+	before, err := s.getSetting(s.developerRepo.WithTx(tx), task)
+	if err != nil {
+		return model.ScheduledTask{}, err
+	}
+
+	after := before
+	if req.Schedule != nil {
+		after.Schedule = strings.TrimSpace(*req.Schedule)
+	}
+	if req.Timezone != nil {
+		after.Timezone = *req.Timezone
+	}
+	if req.Enabled != nil {
+		after.Enabled = *req.Enabled
+	}
+	if _, err := cron.ParseStandard(cronSpec(after)); err != nil {
+		return model.ScheduledTask{}, fmt.Errorf("parse schedule: %w", err)
+	}
+
+	value, err := json.Marshal(after)
+	if err != nil {
+		return model.ScheduledTask{}, fmt.Errorf("encode schedule: %w", err)
+	}
+	now := time.Now().UTC()
+	setting := model.SystemSetting{
+		ID:        uuid.New().String(),
+		Key:       settingKey(task.name),
+		Value:     string(value),
+		UpdatedAt: &now,
+	}
+	if err := s.developerRepo.WithTx(tx).SetSystemSetting(ctx, setting); err != nil {
+		return model.ScheduledTask{}, fmt.Errorf("update %s: %w", setting.Key, err)
+	}
+
+	if err := recordAudit(ctx, s.auditRepo.WithTx(tx), model.AuditEntitySystemSetting, "schedule_"+task.name, model.AuditActionUpdate, before, after); err != nil {
+		return model.ScheduledTask{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return model.ScheduledTask{}, fmt.Errorf("commit transaction: %w", err)
+	}
+
+	if err := s.reschedule(task, after); err != nil {
+		return model.ScheduledTask{}, err
+	}
+
+	schedulerLog.InfoContext(ctx, "scheduled task updated", "task", task.name,
+		"schedule", after.Schedule, "timezone", after.Timezone, "enabled", after.Enabled)
+	return s.describe(task)
+}
+
+// RunTask starts a task immediately as a background job, whether or not it is enabled.
+// Returns ErrScheduledTaskNotFound if no task has the given name.
+func (s *SchedulerService) RunTask(ctx context.Context, name string) (model.Job, error) {
+	task, err := s.task(name)
+	if err != nil {
+		return model.Job{}, err
+	}
+	job, err := s.jobService.Submit(ctx, task.jobType, nil)
+	if err != nil {
+		return model.Job{}, fmt.Errorf("run %s: %w", task.name, err)
+	}
+	return job, nil
+}
+
+func (s *SchedulerService) task(name string) (scheduledTask, error) {
+	for _, task := range s.tasks {
+		if task.name == name {
+			return task, nil
+		}
+	}
+	return scheduledTask{}, apperrors.ErrScheduledTaskNotFound
+}
+
+// getSetting returns the stored setting of a task, filling anything not stored from the defaults.
+func (s *SchedulerService) getSetting(repo *repository.DeveloperRepository, task scheduledTask) (model.ScheduledTaskSetting, error) {
+	setting := model.ScheduledTaskSetting{
+		Schedule: task.defaultSchedule,
+		Timezone: "UTC",
+		Enabled:  true,
+	}
+	value, found, err := repo.GetSystemSetting(settingKey(task.name))
+	if err != nil {
+		return setting, fmt.Errorf("get schedule of %s: %w", task.name, err)
+	}
+	if found {
+		if err := json.Unmarshal([]byte(value), &setting); err != nil {
+			return setting, fmt.Errorf("decode schedule of %s: %w", task.name, err)
+		}
+	}
+	return setting, nil
+}
+
+// reschedule replaces the cron entry of a task. It is a no-op before Start.
+func (s *SchedulerService) reschedule(task scheduledTask, setting model.ScheduledTaskSetting) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cron == nil {
+		return nil
+	}
+
+	if id, ok := s.entries[task.name]; ok {
+		s.cron.Remove(id)
+		delete(s.entries, task.name)
+	}
+	if !setting.Enabled {
+		return nil
+	}
+	id, err := s.cron.AddFunc(cronSpec(setting), s.runScheduled(task))
+	if err != nil {
+		return fmt.Errorf("schedule %s: %w", task.name, err)
+	}
+	s.entries[task.name] = id
+	return nil
+}
+
+// describe combines a task's setting with its next run and most recent job.
+func (s *SchedulerService) describe(task scheduledTask) (model.ScheduledTask, error) {
+	setting, err := s.getSetting(s.developerRepo, task)
+	if err != nil {
+		return model.ScheduledTask{}, err
+	}
+	t := model.ScheduledTask{
+		Name:                 task.name,
+		JobType:              task.jobType,
+		ScheduledTaskSetting: setting,
+	}
+
+	if setting.Enabled {
+		schedule, err := cron.ParseStandard(cronSpec(setting))
+		if err != nil {
+			return model.ScheduledTask{}, fmt.Errorf("parse schedule of %s: %w", task.name, err)
+		}
+		next := schedule.Next(time.Now()).UTC()
+		t.NextRunAt = &next
+	}
+
+	jobs, err := s.jobRepo.GetJobs(&model.JobFilters{Types: []string{string(task.jobType)}, Limit: 1})
+	if err != nil {
+		return model.ScheduledTask{}, fmt.Errorf("get last run of %s: %w", task.name, err)
+	}
+	if len(jobs) > 0 {
+		last := jobs[0]
+		t.LastJobID = last.ID
+		t.LastRunAt = last.StartedAt
+		if t.LastRunAt == nil {
+			t.LastRunAt = &last.CreatedAt
+		}
+		t.LastStatus = last.Status
+		t.LastResult = last.Result
+		t.LastError = last.Error
+	}
+	return t, nil
+}
+
+// runScheduled returns the cron function that runs a task as a tracked job.
+func (s *SchedulerService) runScheduled(task scheduledTask) func() {
+	return func() {
+		schedulerLog.Info("starting scheduled task", "task", task.name)
+		done := metrics.TrackCronJob(task.name)
+		ctx, cancel := context.WithTimeout(context.Background(), task.timeout)
+		defer cancel()
+		ctx, span := tracing.Start(ctx, "cron."+task.name)
+		_, err := s.jobService.Run(ctx, task.jobType, task.params)
+		if err != nil {
+			schedulerLog.Error("scheduled task failed", "task", task.name, "error", err)
+		}
+		tracing.End(span, err)
+		done(err)
+	}
+}
+
+// cronSpec returns the cron spec of a setting, evaluated in its time zone.
+func cronSpec(setting model.ScheduledTaskSetting) string {
+	return "CRON_TZ=" + setting.Timezone + " " + setting.Schedule
+}
+
+func settingKey(name string) string {
+	return scheduleSettingPrefix + strings.ToUpper(name)
+}
@@ -0,0 +1,188 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/api/request"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/apperrors"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/model"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/testutil"
+)
+
+func TestSchedulerService_GetTasks(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	jobs := testutil.NewTestJobService(t, db)
+	jobs.Register(model.JobTypeTrashPurge, func(context.Context, json.RawMessage) (any, error) {
+		return map[string]int{"purged": 2}, nil
+	})
+	svc := testutil.NewTestSchedulerService(t, db, jobs)
+
+	if _, err := jobs.Run(context.Background(), model.JobTypeTrashPurge, nil); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	tasks, err := svc.GetTasks()
+	if err != nil {
+		t.Fatalf("GetTasks: %v", err)
+	}
+	if len(tasks) != 7 {
+		t.Fatalf("expected 7 tasks, got %d", len(tasks))
+	}
+
+	for _, task := range tasks {
+		if !task.Enabled || task.Timezone != "UTC" || task.Schedule == "" {
+			t.Errorf("expected %s to default to an enabled UTC schedule, got %+v", task.Name, task.ScheduledTaskSetting)
+		}
+		if task.NextRunAt == nil || !task.NextRunAt.After(time.Now()) {
+			t.Errorf("expected %s to have a future next run, got %v", task.Name, task.NextRunAt)
+		}
+		switch task.Name {
+		case model.ScheduledTaskTrashPurge:
+			if task.LastStatus != string(model.JobStatusSucceeded) || string(task.LastResult) != `{"purged":2}` || task.LastRunAt == nil {
+				t.Errorf("expected the last trash purge run, got %+v", task)
+			}
+		default:
+			if task.LastJobID != "" {
+				t.Errorf("expected %s to have no last run, got job %s", task.Name, task.LastJobID)
+			}
+		}
+	}
+}
+
+//nolint:gocyclo // Test function with multiple subtests and assertions.
+func TestSchedulerService_UpdateTask(t *testing.T) {
+	str := func(s string) *string { return &s }
+
+	t.Run("stores the setting, audits it and reschedules the running task", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		svc := testutil.NewTestSchedulerService(t, db, testutil.NewTestJobService(t, db))
+		if err := svc.Start(); err != nil {
+			t.Fatalf("Start: %v", err)
+		}
+		t.Cleanup(func() { <-svc.Stop().Done() })
+
+		disabled := false
+		task, err := svc.UpdateTask(context.Background(), model.ScheduledTaskIbkrImport, request.UpdateScheduledTaskRequest{Enabled: &disabled})
+		if err != nil {
+			t.Fatalf("UpdateTask: %v", err)
+		}
+		if task.Enabled || task.NextRunAt != nil {
+			t.Errorf("expected a disabled task without next run, got %+v", task)
+		}
+		if slices.Contains(svc.ExportScheduledEntries(), model.ScheduledTaskIbkrImport) {
+			t.Error("expected the disabled task to be removed from the scheduler")
+		}
+
+		enabled := true
+		task, err = svc.UpdateTask(context.Background(), model.ScheduledTaskIbkrImport, request.UpdateScheduledTaskRequest{
+			Schedule: str("0 6 * * *"),
+			Timezone: str("Europe/Amsterdam"),
+			Enabled:  &enabled,
+		})
+		if err != nil {
+			t.Fatalf("UpdateTask: %v", err)
+		}
+		if task.Schedule != "0 6 * * *" || task.Timezone != "Europe/Amsterdam" || !task.Enabled {
+			t.Errorf("expected updated setting, got %+v", task.ScheduledTaskSetting)
+		}
+		amsterdam, _ := time.LoadLocation("Europe/Amsterdam")
+		if task.NextRunAt == nil || task.NextRunAt.In(amsterdam).Hour() != 6 {
+			t.Errorf("expected next run at 06:00 Amsterdam time, got %v", task.NextRunAt)
+		}
+		if !slices.Contains(svc.ExportScheduledEntries(), model.ScheduledTaskIbkrImport) {
+			t.Error("expected the re-enabled task to be scheduled")
+		}
+
+		if n := countRows(t, db, "system_setting", "key = ?", "SCHEDULE_IBKR_IMPORT"); n != 1 {
+			t.Errorf("expected one stored setting, got %d", n)
+		}
+		if n := countRows(t, db, "audit_event", "entity_type = ? AND entity_id = ?", model.AuditEntitySystemSetting, "schedule_ibkr_import"); n != 2 {
+			t.Errorf("expected 2 audit entries, got %d", n)
+		}
+	})
+
+	t.Run("stored settings are applied on start", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		jobs := testutil.NewTestJobService(t, db)
+		disabled := false
+		if _, err := testutil.NewTestSchedulerService(t, db, jobs).UpdateTask(context.Background(),
+			model.ScheduledTaskJobPurge, request.UpdateScheduledTaskRequest{Enabled: &disabled}); err != nil {
+			t.Fatalf("UpdateTask: %v", err)
+		}
+
+		svc := testutil.NewTestSchedulerService(t, db, jobs)
+		if err := svc.Start(); err != nil {
+			t.Fatalf("Start: %v", err)
+		}
+		t.Cleanup(func() { <-svc.Stop().Done() })
+
+		entries := svc.ExportScheduledEntries()
+		if len(entries) != 6 || slices.Contains(entries, model.ScheduledTaskJobPurge) {
+			t.Errorf("expected every task but job_purge to be scheduled, got %v", entries)
+		}
+	})
+
+	t.Run("unknown task", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		svc := testutil.NewTestSchedulerService(t, db, testutil.NewTestJobService(t, db))
+
+		_, err := svc.UpdateTask(context.Background(), "nope", request.UpdateScheduledTaskRequest{})
+		if !errors.Is(err, apperrors.ErrScheduledTaskNotFound) {
+			t.Errorf("expected ErrScheduledTaskNotFound, got %v", err)
+		}
+	})
+
+	t.Run("invalid schedule is not stored", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		svc := testutil.NewTestSchedulerService(t, db, testutil.NewTestJobService(t, db))
+
+		_, err := svc.UpdateTask(context.Background(), model.ScheduledTaskTrashPurge, request.UpdateScheduledTaskRequest{Schedule: str("not a schedule")})
+		if err == nil {
+			t.Fatal("expected an error")
+		}
+		testutil.AssertRowCount(t, db, "system_setting", 0)
+	})
+}
+
+func TestSchedulerService_RunTask(t *testing.T) {
+	t.Run("starts the task as a job even when disabled", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		jobs := testutil.NewTestJobService(t, db)
+		jobs.Register(model.JobTypeSessionPurge, func(context.Context, json.RawMessage) (any, error) {
+			return map[string]int64{"purged": 1}, nil
+		})
+		svc := testutil.NewTestSchedulerService(t, db, jobs)
+		disabled := false
+		if _, err := svc.UpdateTask(context.Background(), model.ScheduledTaskSessionPurge, request.UpdateScheduledTaskRequest{Enabled: &disabled}); err != nil {
+			t.Fatalf("UpdateTask: %v", err)
+		}
+
+		job, err := svc.RunTask(context.Background(), model.ScheduledTaskSessionPurge)
+		if err != nil {
+			t.Fatalf("RunTask: %v", err)
+		}
+		waitForJobs(t, jobs.Wait)
+
+		task, err := svc.GetTask(model.ScheduledTaskSessionPurge)
+		if err != nil {
+			t.Fatalf("GetTask: %v", err)
+		}
+		if task.LastJobID != job.ID || task.LastStatus != string(model.JobStatusSucceeded) {
+			t.Errorf("expected the started job as last run, got %+v", task)
+		}
+	})
+
+	t.Run("unknown task", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		svc := testutil.NewTestSchedulerService(t, db, testutil.NewTestJobService(t, db))
+
+		if _, err := svc.RunTask(context.Background(), "nope"); !errors.Is(err, apperrors.ErrScheduledTaskNotFound) {
+			t.Errorf("expected ErrScheduledTaskNotFound, got %v", err)
+		}
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"fmt"
 
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/config"
 	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/database"
 	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/logging"
 	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/model"
@@ -14,14 +15,36 @@ var sysLog = logging.NewLogger("system")
 
 // SystemService handles system-related operations
 type SystemService struct {
-	db *sql.DB
+	db  *sql.DB
+	cfg *config.Config
+}
+
+// SystemServiceOption is a functional option for configuring a SystemService.
+type SystemServiceOption func(*SystemService)
+
+// SystemWithConfig injects the loaded application configuration.
+func SystemWithConfig(cfg *config.Config) SystemServiceOption {
+	return func(s *SystemService) { s.cfg = cfg }
 }
 
 // NewSystemService creates a new SystemService
-func NewSystemService(db *sql.DB) *SystemService {
-	return &SystemService{
+func NewSystemService(db *sql.DB, opts ...SystemServiceOption) *SystemService {
+	s := &SystemService{
 		db: db,
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// EffectiveConfig returns the configuration the application is running with, after the
+// config file and environment variables were applied, with secrets redacted.
+func (s *SystemService) EffectiveConfig() (config.RedactedConfig, error) {
+	if s.cfg == nil {
+		return config.RedactedConfig{}, fmt.Errorf("configuration not loaded")
+	}
+	return s.cfg.Redacted(), nil
 }
 
 // CheckHealth checks the health of the system
@@ -0,0 +1,214 @@
+package service
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/model"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/pdf"
+)
+
+// WriteBox3CSV writes a Box 3 report as CSV: one row per fund, a "Total" row after each
+// portfolio's funds and a final "Total" row for the report. Total rows only carry EUR amounts.
+func WriteBox3CSV(w io.Writer, report model.Box3Report) error {
+	cw := csv.NewWriter(w)
+	records := [][]string{{
+		"portfolio", "fund", "isin", "currency", "shares", "price", "value", "exchange_rate",
+		"value_eur", "dividends", "dividends_eur", "withholding_tax", "withholding_tax_eur",
+	}}
+	for _, p := range report.Portfolios {
+		for _, f := range p.Funds {
+			records = append(records, []string{
+				p.PortfolioName, f.FundName, f.ISIN, f.Currency, number(f.Shares), number(f.Price), money(f.Value),
+				number(f.ExchangeRate), money(f.ValueEUR), money(f.Dividends), money(f.DividendsEUR),
+				money(f.WithholdingTax), money(f.WithholdingTaxEUR),
+			})
+		}
+		records = append(records, box3TotalRecord(p.PortfolioName, "Total", p.Value, p.Dividends, p.WithholdingTax))
+	}
+	records = append(records, box3TotalRecord("Total", "", report.TotalValue, report.TotalDividends, report.TotalWithholdingTax))
+
+	if err := cw.WriteAll(records); err != nil {
+		return fmt.Errorf("write box 3 csv: %w", err)
+	}
+	return nil
+}
+
+// box3TotalRecord returns a CSV total row with only the EUR amounts.
+func box3TotalRecord(portfolio, fund string, value, dividends, withholding float64) []string {
+	return []string{portfolio, fund, "", "", "", "", "", "", money(value), "", money(dividends), "", money(withholding)}
+}
+
+// WriteBox3PDF writes a Box 3 report as a PDF document with the totals, a table per portfolio
+// and the exchange rates used.
+func WriteBox3PDF(w io.Writer, report model.Box3Report) error {
+	doc := pdf.New(fmt.Sprintf("Box 3 report %d", report.Year))
+	doc.Text(fmt.Sprintf("Reference date (peildatum): %s. Amounts in %s.", report.ReferenceDate, report.Currency))
+	doc.Text("Total value: " + money(report.TotalValue))
+	doc.Text("Dividends: " + money(report.TotalDividends))
+	doc.Text("Withholding tax: " + money(report.TotalWithholdingTax))
+	if len(report.MissingExchangeRates) > 0 {
+		doc.Text("No exchange rate for " + strings.Join(report.MissingExchangeRates, ", ") + "; these amounts count as zero.")
+	}
+
+	columns := []pdf.Column{
+		{Header: "Fund", Width: 0.28},
+		{Header: "ISIN", Width: 0.15},
+		{Header: "Value", Width: 0.13, Align: pdf.AlignRight},
+		{Header: "Rate", Width: 0.08, Align: pdf.AlignRight},
+		{Header: "Value EUR", Width: 0.13, Align: pdf.AlignRight},
+		{Header: "Dividends EUR", Width: 0.12, Align: pdf.AlignRight},
+		{Header: "Withheld EUR", Width: 0.11, Align: pdf.AlignRight},
+	}
+	for _, p := range report.Portfolios {
+		doc.Heading(p.PortfolioName)
+		rows := make([][]string, 0, len(p.Funds)+1)
+		for _, f := range p.Funds {
+			rows = append(rows, []string{
+				f.FundName, f.ISIN, f.Currency + " " + money(f.Value), number(f.ExchangeRate),
+				money(f.ValueEUR), money(f.DividendsEUR), money(f.WithholdingTaxEUR),
+			})
+		}
+		rows = append(rows, []string{"Total", "", "", "", money(p.Value), money(p.Dividends), money(p.WithholdingTax)})
+		doc.Table(columns, rows)
+	}
+
+	if len(report.ExchangeRates) > 0 {
+		doc.Heading("Exchange rates to " + report.Currency)
+		rows := make([][]string, 0, len(report.ExchangeRates))
+		for _, r := range report.ExchangeRates {
+			rows = append(rows, []string{r.Currency, number(r.Rate), r.Date})
+		}
+		doc.Table([]pdf.Column{
+			{Header: "Currency", Width: 0.15},
+			{Header: "Rate", Width: 0.15, Align: pdf.AlignRight},
+			{Header: "Date", Width: 0.2},
+		}, rows)
+	}
+
+	if _, err := doc.WriteTo(w); err != nil {
+		return fmt.Errorf("write box 3 pdf: %w", err)
+	}
+	return nil
+}
+
+// WriteCapitalGainsCSV writes a capital gains report as CSV: one row per sale, a "Total" row
+// after each fund's sales and each portfolio's funds, and a final "Total" row for the report.
+// Portfolio and report totals only carry base currency amounts.
+func WriteCapitalGainsCSV(w io.Writer, report model.CapitalGainsReport) error {
+	cw := csv.NewWriter(w)
+	records := [][]string{{
+		"portfolio", "fund", "isin", "currency", "sale_date", "shares_sold", "acquisitions", "holding_period",
+		"proceeds", "cost_basis", "gain", "proceeds_base", "cost_basis_base", "gain_base",
+	}}
+	for _, p := range report.Portfolios {
+		for _, f := range p.Funds {
+			for _, sale := range f.Sales {
+				records = append(records, []string{
+					p.PortfolioName, f.FundName, f.ISIN, f.Currency, sale.SaleDate, number(sale.SharesSold),
+					acquisitionsText(sale.Acquisitions), sale.HoldingPeriod, money(sale.Proceeds), money(sale.CostBasis),
+					money(sale.Gain), money(sale.ProceedsBase), money(sale.CostBasisBase), money(sale.GainBase),
+				})
+			}
+			records = append(records, []string{
+				p.PortfolioName, f.FundName, f.ISIN, f.Currency, "Total", number(f.SharesSold), "", "",
+				money(f.Proceeds), money(f.CostBasis), money(f.Gain), money(f.ProceedsBase), money(f.CostBasisBase), money(f.GainBase),
+			})
+		}
+		records = append(records, capitalGainsTotalRecord(p.PortfolioName, "Total", p.ProceedsBase, p.CostBasisBase, p.GainBase))
+	}
+	records = append(records, capitalGainsTotalRecord("Total", "", report.ProceedsBase, report.CostBasisBase, report.GainBase))
+
+	if err := cw.WriteAll(records); err != nil {
+		return fmt.Errorf("write capital gains csv: %w", err)
+	}
+	return nil
+}
+
+// capitalGainsTotalRecord returns a CSV total row with only the base currency amounts.
+func capitalGainsTotalRecord(portfolio, fund string, proceeds, costBasis, gain float64) []string {
+	return []string{portfolio, fund, "", "", "", "", "", "", "", "", "", money(proceeds), money(costBasis), money(gain)}
+}
+
+// acquisitionsText lists the acquisitions of a sale as "date (shares)", separated by
+// semicolons. Shares without a matching purchase are listed as "unknown".
+func acquisitionsText(acquisitions []model.CapitalGainsAcquisition) string {
+	parts := make([]string, len(acquisitions))
+	for i, a := range acquisitions {
+		date := a.AcquisitionDate
+		if date == "" {
+			date = "unknown"
+		}
+		parts[i] = fmt.Sprintf("%s (%s)", date, number(a.Shares))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// WriteCapitalGainsPDF writes a capital gains report as a PDF document with the totals and a
+// table of sales per portfolio, each fund followed by its total.
+func WriteCapitalGainsPDF(w io.Writer, report model.CapitalGainsReport) error {
+	doc := pdf.New(fmt.Sprintf("Capital gains report %d", report.Year))
+	doc.Text(fmt.Sprintf("Sales in %d. Base currency amounts in %s.", report.Year, report.BaseCurrency))
+	doc.Text("Proceeds: " + money(report.ProceedsBase))
+	doc.Text("Cost basis: " + money(report.CostBasisBase))
+	doc.Text(fmt.Sprintf("Gain: %s (short term %s, long term %s)",
+		money(report.GainBase), money(report.ShortTermGainBase), money(report.LongTermGainBase)))
+	if len(report.MissingExchangeRates) > 0 {
+		doc.Text("No exchange rate for " + strings.Join(report.MissingExchangeRates, ", ") + "; these sales count as zero.")
+	}
+
+	columns := []pdf.Column{
+		{Header: "Sold", Width: 0.1},
+		{Header: "Fund", Width: 0.2},
+		{Header: "Shares", Width: 0.08, Align: pdf.AlignRight},
+		{Header: "Acquired", Width: 0.13},
+		{Header: "Term", Width: 0.07},
+		{Header: "Proceeds", Width: 0.11, Align: pdf.AlignRight},
+		{Header: "Cost basis", Width: 0.11, Align: pdf.AlignRight},
+		{Header: "Gain", Width: 0.1, Align: pdf.AlignRight},
+		{Header: "Gain " + report.BaseCurrency, Width: 0.1, Align: pdf.AlignRight},
+	}
+	for _, p := range report.Portfolios {
+		doc.Heading(p.PortfolioName)
+		var rows [][]string
+		for _, f := range p.Funds {
+			for _, sale := range f.Sales {
+				acquired := ""
+				if len(sale.Acquisitions) > 0 {
+					acquired = sale.Acquisitions[0].AcquisitionDate
+					if len(sale.Acquisitions) > 1 {
+						acquired += fmt.Sprintf(" +%d", len(sale.Acquisitions)-1)
+					}
+				}
+				rows = append(rows, []string{
+					sale.SaleDate, f.FundName, number(sale.SharesSold), acquired, sale.HoldingPeriod,
+					money(sale.Proceeds), money(sale.CostBasis), money(sale.Gain), money(sale.GainBase),
+				})
+			}
+			rows = append(rows, []string{
+				"Total", f.FundName, number(f.SharesSold), "", "", f.Currency + " " + money(f.Proceeds),
+				money(f.CostBasis), money(f.Gain), money(f.GainBase),
+			})
+		}
+		rows = append(rows, []string{"Total", "", "", "", "", "", "", "", money(p.GainBase)})
+		doc.Table(columns, rows)
+	}
+
+	if _, err := doc.WriteTo(w); err != nil {
+		return fmt.Errorf("write capital gains pdf: %w", err)
+	}
+	return nil
+}
+
+// money formats an amount with two decimals.
+func money(v float64) string {
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
+
+// number formats a quantity or rate without trailing zeros.
+func number(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
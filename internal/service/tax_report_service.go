@@ -0,0 +1,491 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/apperrors"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/auth"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/logging"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/model"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/repository"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/tracing"
+)
+
+var reportLog = logging.NewLogger("portfolio")
+
+// TaxReportService builds the yearly tax reports from the materialized history, dividends
+// and exchange rates. Reports are computed on request; nothing is persisted.
+type TaxReportService struct {
+	taxReportRepo   *repository.TaxReportRepository
+	portfolioRepo   *repository.PortfolioRepository
+	transactionRepo *repository.TransactionRepository
+	withholdingRepo *repository.WithholdingTaxRepository
+	developerRepo   *repository.DeveloperRepository
+}
+
+// NewTaxReportService creates a new TaxReportService with the provided repository dependencies.
+func NewTaxReportService(
+	taxReportRepo *repository.TaxReportRepository,
+	portfolioRepo *repository.PortfolioRepository,
+	transactionRepo *repository.TransactionRepository,
+	withholdingRepo *repository.WithholdingTaxRepository,
+	developerRepo *repository.DeveloperRepository,
+) *TaxReportService {
+	return &TaxReportService{
+		taxReportRepo:   taxReportRepo,
+		portfolioRepo:   portfolioRepo,
+		transactionRepo: transactionRepo,
+		withholdingRepo: withholdingRepo,
+		developerRepo:   developerRepo,
+	}
+}
+
+// GetBox3Report builds the Dutch Box 3 report of year for the portfolios the caller in ctx can
+// read, archived and overview-excluded ones included. Holdings are valued at their latest
+// materialized value on or before 1 January of year; dividends and withholding tax are those
+// with an ex-dividend date in year. Portfolios without holdings or dividends are left out.
+func (s *TaxReportService) GetBox3Report(ctx context.Context, year int) (report model.Box3Report, err error) {
+	ctx, span := tracing.Start(ctx, "TaxReportService.GetBox3Report")
+	defer func() { tracing.End(span, err) }()
+
+	refDate := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	scope := auth.Scope(ctx)
+	portfolios, err := s.portfolioRepo.GetPortfolios(model.PortfolioFilter{IncludeArchived: true, IncludeExcluded: true, Scope: scope})
+	if err != nil {
+		return model.Box3Report{}, err
+	}
+
+	holdings, err := s.taxReportRepo.GetHoldingsOnDate(refDate, scope)
+	if err != nil {
+		return model.Box3Report{}, err
+	}
+	dividends, err := s.withholdingRepo.GetWithholdingTaxDividends(year, scope)
+	if err != nil {
+		return model.Box3Report{}, err
+	}
+
+	rates := newEURRates(s.developerRepo)
+	funds := make(map[string]map[string]*model.Box3Fund, len(portfolios))
+	for _, p := range portfolios {
+		funds[p.ID] = make(map[string]*model.Box3Fund)
+	}
+	fund := func(portfolioID, fundID, fundName, isin, currency string) *model.Box3Fund {
+		f, ok := funds[portfolioID][fundID]
+		if !ok {
+			f = &model.Box3Fund{FundID: fundID, FundName: fundName, ISIN: isin, Currency: currency}
+			funds[portfolioID][fundID] = f
+		}
+		return f
+	}
+
+	for _, h := range holdings {
+		if funds[h.PortfolioID] == nil {
+			continue
+		}
+		rate, err := rates.rate(h.Currency, refDate)
+		if err != nil {
+			return model.Box3Report{}, err
+		}
+		f := fund(h.PortfolioID, h.FundID, h.FundName, h.ISIN, h.Currency)
+		f.Shares, f.Price, f.Value = h.Shares, h.Price, h.Value
+		f.ExchangeRate = rate
+		f.ValueEUR = h.Value * rate
+	}
+	for _, d := range dividends {
+		if funds[d.PortfolioID] == nil {
+			continue
+		}
+		rate, err := rates.rate(d.Currency, d.ExDividendDate)
+		if err != nil {
+			return model.Box3Report{}, err
+		}
+		f := fund(d.PortfolioID, d.FundID, d.FundName, d.ISIN, d.Currency)
+		f.Dividends += d.GrossAmount
+		f.DividendsEUR += d.GrossAmount * rate
+		f.WithholdingTax += d.WithholdingTax
+		f.WithholdingTaxEUR += d.WithholdingTax * rate
+	}
+
+	report = model.Box3Report{
+		Year:                 year,
+		ReferenceDate:        refDate.Format("2006-01-02"),
+		Currency:             model.TaxReportCurrency,
+		Portfolios:           []model.Box3Portfolio{},
+		ExchangeRates:        rates.used(refDate),
+		MissingExchangeRates: rates.missingCurrencies(),
+	}
+	for _, p := range portfolios {
+		if len(funds[p.ID]) == 0 {
+			continue
+		}
+		bp := model.Box3Portfolio{PortfolioID: p.ID, PortfolioName: p.Name, Funds: make([]model.Box3Fund, 0, len(funds[p.ID]))}
+		for _, f := range funds[p.ID] {
+			bp.Value += f.ValueEUR
+			bp.Dividends += f.DividendsEUR
+			bp.WithholdingTax += f.WithholdingTaxEUR
+
+			f.Value, f.ValueEUR = round(f.Value), round(f.ValueEUR)
+			f.Dividends, f.DividendsEUR = round(f.Dividends), round(f.DividendsEUR)
+			f.WithholdingTax, f.WithholdingTaxEUR = round(f.WithholdingTax), round(f.WithholdingTaxEUR)
+			bp.Funds = append(bp.Funds, *f)
+		}
+		slices.SortFunc(bp.Funds, func(a, b model.Box3Fund) int { return strings.Compare(a.FundName, b.FundName) })
+
+		report.TotalValue += bp.Value
+		report.TotalDividends += bp.Dividends
+		report.TotalWithholdingTax += bp.WithholdingTax
+		bp.Value, bp.Dividends, bp.WithholdingTax = round(bp.Value), round(bp.Dividends), round(bp.WithholdingTax)
+		report.Portfolios = append(report.Portfolios, bp)
+	}
+	slices.SortFunc(report.Portfolios, func(a, b model.Box3Portfolio) int { return strings.Compare(a.PortfolioName, b.PortfolioName) })
+	report.TotalValue = round(report.TotalValue)
+	report.TotalDividends = round(report.TotalDividends)
+	report.TotalWithholdingTax = round(report.TotalWithholdingTax)
+
+	if len(report.MissingExchangeRates) > 0 {
+		reportLog.WarnContext(ctx, "box 3 report has currencies without an exchange rate", "year", year, "currencies", report.MissingExchangeRates)
+	}
+	return report, nil
+}
+
+// GetCapitalGainsReport builds the capital gains report of year for the portfolios the caller in
+// ctx can read, archived and overview-excluded ones included, from the realized_gain_loss records
+// of the sales dated in year. Proceeds are converted to the base currency at the rate of the sale
+// date and each acquisition's share of the cost basis at the rate of its acquisition date, so the
+// base currency gain includes the currency result.
+func (s *TaxReportService) GetCapitalGainsReport(ctx context.Context, year int) (report model.CapitalGainsReport, err error) {
+	ctx, span := tracing.Start(ctx, "TaxReportService.GetCapitalGainsReport")
+	defer func() { tracing.End(span, err) }()
+
+	scope := auth.Scope(ctx)
+	portfolios, err := s.portfolioRepo.GetPortfolios(model.PortfolioFilter{IncludeArchived: true, IncludeExcluded: true, Scope: scope})
+	if err != nil {
+		return model.CapitalGainsReport{}, err
+	}
+
+	gains, err := s.taxReportRepo.GetRealizedGains(year, scope)
+	if err != nil {
+		return model.CapitalGainsReport{}, err
+	}
+
+	acquisitions, err := s.fifoAcquisitions(gains)
+	if err != nil {
+		return model.CapitalGainsReport{}, err
+	}
+
+	rates := newEURRates(s.developerRepo)
+	funds := make(map[string]map[string]*model.CapitalGainsFund, len(portfolios))
+	for _, g := range gains {
+		if funds[g.PortfolioID] == nil {
+			funds[g.PortfolioID] = make(map[string]*model.CapitalGainsFund)
+		}
+		f, ok := funds[g.PortfolioID][g.FundID]
+		if !ok {
+			f = &model.CapitalGainsFund{FundID: g.FundID, FundName: g.FundName, ISIN: g.ISIN, Currency: g.Currency, Sales: []model.CapitalGainsSale{}}
+			funds[g.PortfolioID][g.FundID] = f
+		}
+
+		sale, shortBase, longBase, err := s.capitalGainsSale(g, acquisitions[g.TransactionID], rates)
+		if err != nil {
+			return model.CapitalGainsReport{}, err
+		}
+		for _, a := range sale.Acquisitions {
+			gain := g.Gain * a.Shares / g.SharesSold
+			if a.HoldingPeriod == model.HoldingPeriodLong {
+				f.LongTermGain += gain
+			} else {
+				f.ShortTermGain += gain
+			}
+		}
+		f.SharesSold += g.SharesSold
+		f.Proceeds += g.Proceeds
+		f.CostBasis += g.CostBasis
+		f.Gain += g.Gain
+		f.ProceedsBase += sale.ProceedsBase
+		f.CostBasisBase += sale.CostBasisBase
+		f.ShortTermGainBase += shortBase
+		f.LongTermGainBase += longBase
+		f.Sales = append(f.Sales, sale)
+	}
+
+	report = model.CapitalGainsReport{
+		Year:                 year,
+		BaseCurrency:         model.TaxReportCurrency,
+		Portfolios:           []model.CapitalGainsPortfolio{},
+		MissingExchangeRates: rates.missingCurrencies(),
+	}
+	for _, p := range portfolios {
+		if len(funds[p.ID]) == 0 {
+			continue
+		}
+		cp := model.CapitalGainsPortfolio{PortfolioID: p.ID, PortfolioName: p.Name, Funds: make([]model.CapitalGainsFund, 0, len(funds[p.ID]))}
+		for _, f := range funds[p.ID] {
+			f.GainBase = f.ProceedsBase - f.CostBasisBase
+			cp.ProceedsBase += f.ProceedsBase
+			cp.CostBasisBase += f.CostBasisBase
+			cp.ShortTermGainBase += f.ShortTermGainBase
+			cp.LongTermGainBase += f.LongTermGainBase
+
+			f.SharesSold, f.Proceeds, f.CostBasis, f.Gain = round(f.SharesSold), round(f.Proceeds), round(f.CostBasis), round(f.Gain)
+			f.ShortTermGain, f.LongTermGain = round(f.ShortTermGain), round(f.LongTermGain)
+			f.ProceedsBase, f.CostBasisBase, f.GainBase = round(f.ProceedsBase), round(f.CostBasisBase), round(f.GainBase)
+			f.ShortTermGainBase, f.LongTermGainBase = round(f.ShortTermGainBase), round(f.LongTermGainBase)
+			cp.Funds = append(cp.Funds, *f)
+		}
+		slices.SortFunc(cp.Funds, func(a, b model.CapitalGainsFund) int { return strings.Compare(a.FundName, b.FundName) })
+
+		report.ProceedsBase += cp.ProceedsBase
+		report.CostBasisBase += cp.CostBasisBase
+		report.ShortTermGainBase += cp.ShortTermGainBase
+		report.LongTermGainBase += cp.LongTermGainBase
+		cp.GainBase = round(cp.ProceedsBase - cp.CostBasisBase)
+		cp.ProceedsBase, cp.CostBasisBase = round(cp.ProceedsBase), round(cp.CostBasisBase)
+		cp.ShortTermGainBase, cp.LongTermGainBase = round(cp.ShortTermGainBase), round(cp.LongTermGainBase)
+		report.Portfolios = append(report.Portfolios, cp)
+	}
+	slices.SortFunc(report.Portfolios, func(a, b model.CapitalGainsPortfolio) int { return strings.Compare(a.PortfolioName, b.PortfolioName) })
+	report.GainBase = round(report.ProceedsBase - report.CostBasisBase)
+	report.ProceedsBase, report.CostBasisBase = round(report.ProceedsBase), round(report.CostBasisBase)
+	report.ShortTermGainBase, report.LongTermGainBase = round(report.ShortTermGainBase), round(report.LongTermGainBase)
+
+	if len(report.MissingExchangeRates) > 0 {
+		reportLog.WarnContext(ctx, "capital gains report has currencies without an exchange rate", "year", year, "currencies", report.MissingExchangeRates)
+	}
+	return report, nil
+}
+
+// capitalGainsSale converts a realized gain into a sale of the report, with its base currency
+// amounts and the base currency gain split into short and long term. When a rate is missing
+// the sale's base currency amounts are zero.
+func (s *TaxReportService) capitalGainsSale(
+	g model.TaxRealizedGain,
+	acquisitions []model.CapitalGainsAcquisition,
+	rates *eurRates,
+) (sale model.CapitalGainsSale, shortBase, longBase float64, err error) {
+	if len(acquisitions) == 0 {
+		acquisitions = []model.CapitalGainsAcquisition{{Shares: round(g.SharesSold), HoldingPeriod: model.HoldingPeriodShort}}
+	}
+	sale = model.CapitalGainsSale{
+		TransactionID: g.TransactionID,
+		SaleDate:      g.SaleDate.Format("2006-01-02"),
+		SharesSold:    round(g.SharesSold),
+		Proceeds:      round(g.Proceeds),
+		CostBasis:     round(g.CostBasis),
+		Gain:          round(g.Gain),
+		Acquisitions:  acquisitions,
+	}
+
+	saleRate, err := rates.rate(g.Currency, g.SaleDate)
+	if err != nil {
+		return model.CapitalGainsSale{}, 0, 0, err
+	}
+	complete := saleRate != 0
+	var costBase float64
+	periods := make(map[string]bool, 2)
+	for _, a := range acquisitions {
+		share := 0.0
+		if g.SharesSold != 0 {
+			share = a.Shares / g.SharesSold
+		}
+		acquisitionRate := saleRate
+		if a.AcquisitionDate != "" {
+			date, err := time.Parse("2006-01-02", a.AcquisitionDate)
+			if err != nil {
+				return model.CapitalGainsSale{}, 0, 0, fmt.Errorf("parse acquisition date: %w", err)
+			}
+			if acquisitionRate, err = rates.rate(g.Currency, date); err != nil {
+				return model.CapitalGainsSale{}, 0, 0, err
+			}
+		}
+		complete = complete && acquisitionRate != 0
+
+		lotCostBase := g.CostBasis * share * acquisitionRate
+		lotGainBase := g.Proceeds*share*saleRate - lotCostBase
+		costBase += lotCostBase
+		if a.HoldingPeriod == model.HoldingPeriodLong {
+			longBase += lotGainBase
+		} else {
+			shortBase += lotGainBase
+		}
+		periods[a.HoldingPeriod] = true
+	}
+
+	switch {
+	case len(periods) > 1:
+		sale.HoldingPeriod = model.HoldingPeriodMixed
+	case periods[model.HoldingPeriodLong]:
+		sale.HoldingPeriod = model.HoldingPeriodLong
+	default:
+		sale.HoldingPeriod = model.HoldingPeriodShort
+	}
+	if !complete {
+		return sale, 0, 0, nil
+	}
+	sale.ProceedsBase = round(g.Proceeds * saleRate)
+	sale.CostBasisBase = round(costBase)
+	sale.GainBase = round(g.Proceeds*saleRate - costBase)
+	return sale, shortBase, longBase, nil
+}
+
+// fifoAcquisitions assigns the shares of each sale in gains to the earlier purchases and
+// dividend reinvestments of its portfolio fund, first-in, first-out, replaying the fund's
+// transactions in date order. The result is keyed by the sale's transaction ID.
+func (s *TaxReportService) fifoAcquisitions(gains []model.TaxRealizedGain) (map[string][]model.CapitalGainsAcquisition, error) {
+	type lot struct {
+		date   time.Time
+		shares float64
+	}
+	const epsilon = 1e-9
+
+	sales := make(map[string]bool, len(gains))
+	var pfIDs []string
+	for _, g := range gains {
+		sales[g.TransactionID] = true
+		if !slices.Contains(pfIDs, g.PortfolioFundID) {
+			pfIDs = append(pfIDs, g.PortfolioFundID)
+		}
+	}
+
+	result := make(map[string][]model.CapitalGainsAcquisition, len(gains))
+	for _, pfID := range pfIDs {
+		transactions, err := s.transactionRepo.GetTransactionsByPortfolioFundID(pfID)
+		if err != nil {
+			return nil, fmt.Errorf("get transactions of portfolio fund %s: %w", pfID, err)
+		}
+		// Shares bought on the day of a sale may be part of it.
+		slices.SortStableFunc(transactions, func(a, b model.Transaction) int {
+			if c := a.Date.Compare(b.Date); c != 0 {
+				return c
+			}
+			return boolCompare(a.Type == "sell", b.Type == "sell")
+		})
+
+		var lots []lot
+		for _, t := range transactions {
+			switch t.Type {
+			case "buy", "dividend":
+				lots = append(lots, lot{date: t.Date, shares: t.Shares})
+			case "sell":
+				var acquired []model.CapitalGainsAcquisition
+				remaining := t.Shares
+				for remaining > epsilon && len(lots) > 0 {
+					taken := min(lots[0].shares, remaining)
+					acquired = append(acquired, acquisition(lots[0].date, t.Date, taken))
+					lots[0].shares -= taken
+					remaining -= taken
+					if lots[0].shares <= epsilon {
+						lots = lots[1:]
+					}
+				}
+				if remaining > epsilon {
+					acquired = append(acquired, model.CapitalGainsAcquisition{Shares: round(remaining), HoldingPeriod: model.HoldingPeriodShort})
+				}
+				if sales[t.ID] {
+					result[t.ID] = acquired
+				}
+			}
+		}
+	}
+	return result, nil
+}
+
+// acquisition returns the part of a sale on saleDate acquired on acquired. Shares held for
+// more than one year are long term.
+func acquisition(acquired, saleDate time.Time, shares float64) model.CapitalGainsAcquisition {
+	period := model.HoldingPeriodShort
+	if saleDate.After(acquired.AddDate(1, 0, 0)) {
+		period = model.HoldingPeriodLong
+	}
+	return model.CapitalGainsAcquisition{
+		AcquisitionDate: acquired.Format("2006-01-02"),
+		Shares:          round(shares),
+		HoldingDays:     int(saleDate.Sub(acquired).Hours() / 24),
+		HoldingPeriod:   period,
+	}
+}
+
+// boolCompare orders false before true.
+func boolCompare(a, b bool) int {
+	switch {
+	case a == b:
+		return 0
+	case a:
+		return 1
+	default:
+		return -1
+	}
+}
+
+// eurRates looks up and caches the rates converting currencies to EUR. A rate is the latest
+// one on or before the date, stored either as currency→EUR or as EUR→currency (inverted).
+type eurRates struct {
+	developerRepo *repository.DeveloperRepository
+	rates         map[string]*model.ExchangeRate // Keyed by currency and date; nil when missing
+}
+
+// newEURRates creates an empty rate cache.
+func newEURRates(developerRepo *repository.DeveloperRepository) *eurRates {
+	return &eurRates{developerRepo: developerRepo, rates: make(map[string]*model.ExchangeRate)}
+}
+
+// rate returns the rate converting currency to EUR on date, or 0 if there is none.
+func (e *eurRates) rate(currency string, date time.Time) (float64, error) {
+	if currency == model.TaxReportCurrency {
+		return 1, nil
+	}
+	key := currency + "|" + date.Format("2006-01-02")
+	if r, ok := e.rates[key]; ok {
+		if r == nil {
+			return 0, nil
+		}
+		return r.Rate, nil
+	}
+
+	r, err := e.developerRepo.GetLatestExchangeRate(currency, model.TaxReportCurrency, date)
+	if errors.Is(err, apperrors.ErrExchangeRateNotFound) {
+		r, err = e.developerRepo.GetLatestExchangeRate(model.TaxReportCurrency, currency, date)
+		if err == nil && r.Rate != 0 {
+			r = &model.ExchangeRate{FromCurrency: currency, ToCurrency: model.TaxReportCurrency, Rate: 1 / r.Rate, Date: r.Date}
+		}
+	}
+	if errors.Is(err, apperrors.ErrExchangeRateNotFound) || (err == nil && r.Rate == 0) {
+		e.rates[key] = nil
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("get %s exchange rate: %w", currency, err)
+	}
+	e.rates[key] = r
+	return r.Rate, nil
+}
+
+// used returns the rates looked up for date, ordered by currency.
+func (e *eurRates) used(date time.Time) []model.Box3ExchangeRate {
+	suffix := "|" + date.Format("2006-01-02")
+	used := []model.Box3ExchangeRate{}
+	for key, r := range e.rates {
+		if r != nil && strings.HasSuffix(key, suffix) {
+			used = append(used, model.Box3ExchangeRate{Currency: r.FromCurrency, Rate: round(r.Rate), Date: r.Date.Format("2006-01-02")})
+		}
+	}
+	slices.SortFunc(used, func(a, b model.Box3ExchangeRate) int { return strings.Compare(a.Currency, b.Currency) })
+	return used
+}
+
+// missingCurrencies returns the currencies that lacked a rate for any lookup, sorted.
+func (e *eurRates) missingCurrencies() []string {
+	missing := []string{}
+	for key, r := range e.rates {
+		if currency := key[:strings.Index(key, "|")]; r == nil && !slices.Contains(missing, currency) {
+			missing = append(missing, currency)
+		}
+	}
+	slices.Sort(missing)
+	return missing
+}
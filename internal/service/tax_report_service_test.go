@@ -0,0 +1,269 @@
+package service_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/auth"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/model"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/repository"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/service"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/testutil"
+)
+
+// insertHolding stores a materialized row for a position worth value on date.
+func insertHolding(t *testing.T, repo *repository.MaterializedRepository, pf model.PortfolioFund, date time.Time, shares, value float64) {
+	t.Helper()
+	err := repo.InsertMaterializedEntries(context.Background(), []model.FundHistoryEntry{{
+		ID: testutil.MakeID(), PortfolioFundID: pf.ID, FundID: pf.FundID, Date: date,
+		Shares: shares, Price: value / shares, Value: value,
+	}})
+	if err != nil {
+		t.Fatalf("InsertMaterializedEntries: %v", err)
+	}
+}
+
+func TestTaxReportService_GetBox3Report(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	svc := testutil.NewTestTaxReportService(t, db)
+	materialized := repository.NewMaterializedRepository(db)
+	ctx := context.Background()
+	jan1 := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	alpha := testutil.NewPortfolio().WithName("Alpha").Archived().Build(t, db)
+	beta := testutil.NewPortfolio().WithName("Beta").Build(t, db)
+	testutil.NewPortfolio().WithName("Empty").Build(t, db)
+
+	usd := testutil.NewFund().WithName("US Equity").WithCurrency("USD").Build(t, db)
+	eur := testutil.NewFund().WithName("World ETF").WithCurrency("EUR").Build(t, db)
+	jpy := testutil.NewFund().WithName("Japan").WithCurrency("JPY").Build(t, db)
+	gbp := testutil.NewFund().WithName("UK").WithCurrency("GBP").Build(t, db)
+
+	usdPF := testutil.NewPortfolioFund(alpha.ID, usd.ID).Build(t, db)
+	insertHolding(t, materialized, usdPF, jan1, 10, 1000)
+	insertHolding(t, materialized, testutil.NewPortfolioFund(alpha.ID, eur.ID).Build(t, db), jan1, 5, 500)
+	insertHolding(t, materialized, testutil.NewPortfolioFund(beta.ID, jpy.ID).Build(t, db), jan1, 100, 16000)
+	insertHolding(t, materialized, testutil.NewPortfolioFund(beta.ID, gbp.ID).Build(t, db), jan1, 2, 200)
+
+	// 50 USD gross with 7.5 withheld, converted at the rate of the day before its ex-dividend date.
+	testutil.NewDividend(usd.ID, usdPF.ID).
+		WithExDividendDate(time.Date(2025, 6, 10, 0, 0, 0, 0, time.UTC)).
+		WithWithholdingTax(7.5, model.WithholdingSourceIBKR).
+		Build(t, db)
+
+	testutil.NewExchangeRate("USD", "EUR", "2024-12-31", 0.9).Build(t, db)
+	testutil.NewExchangeRate("USD", "EUR", "2025-06-09", 0.92).Build(t, db)
+	testutil.NewExchangeRate("EUR", "JPY", "2024-12-30", 160).Build(t, db)
+
+	t.Run("values every readable portfolio in EUR", func(t *testing.T) {
+		report, err := svc.GetBox3Report(ctx, 2025)
+		if err != nil {
+			t.Fatalf("GetBox3Report() error: %v", err)
+		}
+		if report.ReferenceDate != "2025-01-01" || report.Currency != "EUR" {
+			t.Errorf("unexpected reference date or currency: %s %s", report.ReferenceDate, report.Currency)
+		}
+		if report.TotalValue != 1500 || report.TotalDividends != 46 || report.TotalWithholdingTax != 6.9 {
+			t.Errorf("totals = %v/%v/%v, want 1500/46/6.9", report.TotalValue, report.TotalDividends, report.TotalWithholdingTax)
+		}
+		if len(report.Portfolios) != 2 || report.Portfolios[0].PortfolioName != "Alpha" || report.Portfolios[1].PortfolioName != "Beta" {
+			t.Fatalf("expected Alpha and Beta, got %+v", report.Portfolios)
+		}
+
+		a := report.Portfolios[0]
+		if a.Value != 1400 || len(a.Funds) != 2 {
+			t.Fatalf("unexpected Alpha %+v", a)
+		}
+		if f := a.Funds[0]; f.FundName != "US Equity" || f.Value != 1000 || f.ExchangeRate != 0.9 || f.ValueEUR != 900 ||
+			f.Dividends != 50 || f.DividendsEUR != 46 || f.WithholdingTax != 7.5 {
+			t.Errorf("unexpected US Equity %+v", f)
+		}
+
+		if b := report.Portfolios[1]; b.Value != 100 {
+			t.Errorf("expected the JPY holding at the inverted rate and GBP at zero, got %+v", b)
+		}
+		if len(report.MissingExchangeRates) != 1 || report.MissingExchangeRates[0] != "GBP" {
+			t.Errorf("MissingExchangeRates = %v, want [GBP]", report.MissingExchangeRates)
+		}
+		if len(report.ExchangeRates) != 2 || report.ExchangeRates[0].Currency != "JPY" || report.ExchangeRates[0].Rate != 0.00625 ||
+			report.ExchangeRates[1] != (model.Box3ExchangeRate{Currency: "USD", Rate: 0.9, Date: "2024-12-31"}) {
+			t.Errorf("unexpected exchange rates %+v", report.ExchangeRates)
+		}
+	})
+
+	t.Run("only includes portfolios the caller can read", func(t *testing.T) {
+		user := model.User{ID: testutil.MakeID()}
+		restricted := auth.WithPrincipal(ctx, auth.NewPrincipal(user, map[string]model.PortfolioAccess{beta.ID: model.PortfolioAccessRead}, nil))
+		report, err := svc.GetBox3Report(restricted, 2025)
+		if err != nil {
+			t.Fatalf("GetBox3Report() error: %v", err)
+		}
+		if len(report.Portfolios) != 1 || report.Portfolios[0].PortfolioID != beta.ID || report.TotalDividends != 0 {
+			t.Errorf("expected only Beta, got %+v", report.Portfolios)
+		}
+	})
+
+	t.Run("exports", func(t *testing.T) {
+		report, err := svc.GetBox3Report(ctx, 2025)
+		if err != nil {
+			t.Fatalf("GetBox3Report() error: %v", err)
+		}
+
+		var csv bytes.Buffer
+		if err := service.WriteBox3CSV(&csv, report); err != nil {
+			t.Fatalf("WriteBox3CSV() error: %v", err)
+		}
+		lines := strings.Split(strings.TrimSpace(csv.String()), "\n")
+		if len(lines) != 8 || !strings.HasPrefix(lines[0], "portfolio,fund,isin") {
+			t.Fatalf("expected a header, 4 funds, 2 portfolio totals and a total, got:\n%s", csv.String())
+		}
+		if lines[7] != "Total,,,,,,,,1500.00,,46.00,,6.90" {
+			t.Errorf("total row = %q", lines[7])
+		}
+
+		var pdf bytes.Buffer
+		if err := service.WriteBox3PDF(&pdf, report); err != nil {
+			t.Fatalf("WriteBox3PDF() error: %v", err)
+		}
+		if out := pdf.String(); !strings.HasPrefix(out, "%PDF-") || !strings.Contains(out, "(Box 3 report 2025)") {
+			t.Errorf("unexpected PDF output")
+		}
+	})
+}
+
+func TestTaxReportService_GetCapitalGainsReport(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	svc := testutil.NewTestTaxReportService(t, db)
+	ctx := context.Background()
+	day := func(year int, month time.Month, d int) time.Time {
+		return time.Date(year, month, d, 0, 0, 0, 0, time.UTC)
+	}
+
+	alpha := testutil.NewPortfolio().WithName("Alpha").Build(t, db)
+	beta := testutil.NewPortfolio().WithName("Beta").Archived().Build(t, db)
+	usd := testutil.NewFund().WithName("US Equity").WithCurrency("USD").Build(t, db)
+	eur := testutil.NewFund().WithName("World ETF").WithCurrency("EUR").Build(t, db)
+	gbp := testutil.NewFund().WithName("UK").WithCurrency("GBP").Build(t, db)
+
+	// sell records a sale with its realized gain.
+	sell := func(portfolioID, fundID, pfID string, date time.Time, shares, costBasis, proceeds float64) {
+		tx := testutil.NewTransaction(pfID).WithType("sell").WithDate(date).WithShares(shares).WithCostPerShare(proceeds/shares).Build(t, db)
+		testutil.NewRealizedGainLoss(portfolioID, fundID, tx.ID).
+			WithShares(shares).WithCostBasis(costBasis).WithSaleProceeds(proceeds).WithDate(date).
+			Build(t, db)
+	}
+
+	// 15 of 20 USD shares at an average cost of 110: 10 held for almost two years, 5 for five months.
+	usdPF := testutil.NewPortfolioFund(alpha.ID, usd.ID).Build(t, db)
+	testutil.NewTransaction(usdPF.ID).WithDate(day(2023, 3, 1)).WithShares(10).WithCostPerShare(100).Build(t, db)
+	testutil.NewTransaction(usdPF.ID).WithDate(day(2024, 9, 1)).WithShares(10).WithCostPerShare(120).Build(t, db)
+	sell(alpha.ID, usd.ID, usdPF.ID, day(2025, 2, 1), 15, 1650, 2250)
+	testutil.NewExchangeRate("USD", "EUR", "2023-03-01", 0.95).Build(t, db)
+	testutil.NewExchangeRate("USD", "EUR", "2024-09-01", 0.9).Build(t, db)
+	testutil.NewExchangeRate("USD", "EUR", "2025-01-31", 0.92).Build(t, db)
+
+	eurPF := testutil.NewPortfolioFund(beta.ID, eur.ID).Build(t, db)
+	testutil.NewTransaction(eurPF.ID).WithDate(day(2025, 1, 10)).WithShares(5).WithCostPerShare(10).Build(t, db)
+	sell(beta.ID, eur.ID, eurPF.ID, day(2025, 3, 1), 5, 50, 60)
+
+	// Without a GBP rate the sale counts as zero in EUR.
+	gbpPF := testutil.NewPortfolioFund(beta.ID, gbp.ID).Build(t, db)
+	testutil.NewTransaction(gbpPF.ID).WithDate(day(2025, 1, 5)).WithShares(1).WithCostPerShare(10).Build(t, db)
+	sell(beta.ID, gbp.ID, gbpPF.ID, day(2025, 2, 1), 1, 10, 20)
+
+	t.Run("lists sales with acquisitions and base currency gains", func(t *testing.T) {
+		report, err := svc.GetCapitalGainsReport(ctx, 2025)
+		if err != nil {
+			t.Fatalf("GetCapitalGainsReport() error: %v", err)
+		}
+		if report.BaseCurrency != "EUR" || report.ProceedsBase != 2130 || report.CostBasisBase != 1590 || report.GainBase != 540 ||
+			report.ShortTermGainBase != 205 || report.LongTermGainBase != 335 {
+			t.Errorf("unexpected totals %+v", report)
+		}
+		if len(report.MissingExchangeRates) != 1 || report.MissingExchangeRates[0] != "GBP" {
+			t.Errorf("MissingExchangeRates = %v, want [GBP]", report.MissingExchangeRates)
+		}
+		if len(report.Portfolios) != 2 || report.Portfolios[0].PortfolioName != "Alpha" || report.Portfolios[1].PortfolioName != "Beta" {
+			t.Fatalf("expected Alpha and Beta, got %+v", report.Portfolios)
+		}
+
+		f := report.Portfolios[0].Funds[0]
+		if f.FundName != "US Equity" || f.Gain != 600 || f.LongTermGain != 400 || f.ShortTermGain != 200 ||
+			f.ProceedsBase != 2070 || f.CostBasisBase != 1540 || f.GainBase != 530 {
+			t.Errorf("unexpected US Equity %+v", f)
+		}
+		sale := f.Sales[0]
+		if sale.SaleDate != "2025-02-01" || sale.HoldingPeriod != model.HoldingPeriodMixed || len(sale.Acquisitions) != 2 {
+			t.Fatalf("unexpected sale %+v", sale)
+		}
+		if a := sale.Acquisitions[0]; a.AcquisitionDate != "2023-03-01" || a.Shares != 10 || a.HoldingPeriod != model.HoldingPeriodLong || a.HoldingDays != 703 {
+			t.Errorf("unexpected first acquisition %+v", a)
+		}
+		if a := sale.Acquisitions[1]; a.AcquisitionDate != "2024-09-01" || a.Shares != 5 || a.HoldingPeriod != model.HoldingPeriodShort {
+			t.Errorf("unexpected second acquisition %+v", a)
+		}
+
+		b := report.Portfolios[1]
+		if b.GainBase != 10 || b.ShortTermGainBase != 10 || len(b.Funds) != 2 || b.Funds[0].FundName != "UK" {
+			t.Fatalf("unexpected Beta %+v", b)
+		}
+		if uk := b.Funds[0]; uk.Gain != 10 || uk.GainBase != 0 || uk.Sales[0].HoldingPeriod != model.HoldingPeriodShort {
+			t.Errorf("expected the GBP sale without base amounts, got %+v", uk)
+		}
+	})
+
+	t.Run("only includes portfolios the caller can read", func(t *testing.T) {
+		user := model.User{ID: testutil.MakeID()}
+		restricted := auth.WithPrincipal(ctx, auth.NewPrincipal(user, map[string]model.PortfolioAccess{alpha.ID: model.PortfolioAccessRead}, nil))
+		report, err := svc.GetCapitalGainsReport(restricted, 2025)
+		if err != nil {
+			t.Fatalf("GetCapitalGainsReport() error: %v", err)
+		}
+		if len(report.Portfolios) != 1 || report.Portfolios[0].PortfolioID != alpha.ID || len(report.MissingExchangeRates) != 0 {
+			t.Errorf("expected only Alpha, got %+v", report)
+		}
+	})
+
+	t.Run("returns an empty report for a year without sales", func(t *testing.T) {
+		report, err := svc.GetCapitalGainsReport(ctx, 2024)
+		if err != nil {
+			t.Fatalf("GetCapitalGainsReport() error: %v", err)
+		}
+		if len(report.Portfolios) != 0 || report.GainBase != 0 {
+			t.Errorf("expected no sales, got %+v", report)
+		}
+	})
+
+	t.Run("exports", func(t *testing.T) {
+		report, err := svc.GetCapitalGainsReport(ctx, 2025)
+		if err != nil {
+			t.Fatalf("GetCapitalGainsReport() error: %v", err)
+		}
+
+		var csv bytes.Buffer
+		if err := service.WriteCapitalGainsCSV(&csv, report); err != nil {
+			t.Fatalf("WriteCapitalGainsCSV() error: %v", err)
+		}
+		lines := strings.Split(strings.TrimSpace(csv.String()), "\n")
+		if len(lines) != 10 || !strings.HasPrefix(lines[0], "portfolio,fund,isin") {
+			t.Fatalf("expected a header, 3 sales, 3 fund totals, 2 portfolio totals and a total, got:\n%s", csv.String())
+		}
+		if !strings.Contains(lines[1], "2023-03-01 (10); 2024-09-01 (5),mixed") {
+			t.Errorf("sale row = %q", lines[1])
+		}
+		if lines[9] != "Total,,,,,,,,,,,2130.00,1590.00,540.00" {
+			t.Errorf("total row = %q", lines[9])
+		}
+
+		var pdf bytes.Buffer
+		if err := service.WriteCapitalGainsPDF(&pdf, report); err != nil {
+			t.Fatalf("WriteCapitalGainsPDF() error: %v", err)
+		}
+		if out := pdf.String(); !strings.HasPrefix(out, "%PDF-") || !strings.Contains(out, "(Capital gains report 2025)") {
+			t.Errorf("unexpected PDF output")
+		}
+	})
+}
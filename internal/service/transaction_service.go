@@ -9,9 +9,11 @@ import (
 	"github.com/google/uuid"
 	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/api/request"
 	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/apperrors"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/auth"
 	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/logging"
 	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/model"
 	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/repository"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/tracing"
 )
 
 var txLog = logging.NewLogger("transaction")
@@ -25,6 +27,8 @@ type TransactionService struct {
 	pfRepo                  *repository.PortfolioFundRepository
 	realizedGainLossRepo    *repository.RealizedGainLossRepository
 	ibkrRepo                *repository.IbkrRepository
+	auditRepo               *repository.AuditRepository
+	trashRepo               *repository.TrashRepository
 	materializedInvalidator MaterializedInvalidator
 }
 
@@ -42,6 +46,8 @@ func NewTransactionService(
 		pfRepo:               pfRepo,
 		realizedGainLossRepo: realizedGainLossRepo,
 		ibkrRepo:             ibkrRepo,
+		auditRepo:            repository.NewAuditRepository(db),
+		trashRepo:            repository.NewTrashRepository(db),
 	}
 }
 
@@ -68,10 +74,11 @@ func (s *TransactionService) loadTransactions(pfIDs []string, startDate, endDate
 }
 
 // GetTransactionsperPortfolio retrieves all transactions for a specific portfolio or all transactions if portfolioID is empty.
+// Only transactions of portfolios the caller in ctx may read are returned.
 // Returns enriched transaction data including fund names and IBKR linkage status.
-func (s *TransactionService) GetTransactionsperPortfolio(portfolioID string) ([]model.TransactionResponse, error) {
+func (s *TransactionService) GetTransactionsperPortfolio(ctx context.Context, portfolioID string) ([]model.TransactionResponse, error) {
 	txLog.Debug("retrieving transactions per portfolio", "portfolioID", portfolioID)
-	result, err := s.transactionRepo.GetTransactionsPerPortfolio(portfolioID)
+	result, err := s.transactionRepo.GetTransactionsPerPortfolio(portfolioID, auth.Scope(ctx))
 	if err != nil {
 		return nil, fmt.Errorf("get transactions per portfolio: %w", err)
 	}
@@ -97,6 +104,8 @@ func (s *TransactionService) GetTransaction(transactionID string) (model.Transac
 // Returns ErrInsufficientShares if selling more shares than currently held.
 // Returns an error if date parsing fails or database insertion fails.
 func (s *TransactionService) CreateTransaction(ctx context.Context, req request.CreateTransactionRequest) (*model.Transaction, error) {
+	ctx, span := tracing.Start(ctx, "TransactionService.CreateTransaction")
+	defer span.End()
 	txLog.DebugContext(ctx, "creating transaction", "portfolioFundID", req.PortfolioFundID, "type", req.Type)
 
 	tx, err := s.db.BeginTx(ctx, nil)
@@ -135,17 +144,16 @@ func (s *TransactionService) CreateTransaction(ctx context.Context, req request.
 		}
 	}
 
+	if err := recordAudit(ctx, s.auditRepo.WithTx(tx), model.AuditEntityTransaction, transaction.ID, model.AuditActionCreate, nil, transaction); err != nil {
+		return nil, err
+	}
+
 	if err := tx.Commit(); err != nil {
 		return nil, fmt.Errorf("commit transaction: %w", err)
 	}
 
 	if s.materializedInvalidator != nil {
-		//nolint:gosec // G118: Background context is intentional — goroutine outlives the HTTP request.
-		go func() {
-			if err := s.materializedInvalidator.RegenerateMaterializedTable(context.Background(), transaction.Date, nil, "", req.PortfolioFundID); err != nil {
-				txLog.Warn("failed to regenerate materialized table after transaction creation", "error", err)
-			}
-		}()
+		s.materializedInvalidator.ScheduleRegeneration(ctx, transaction.Date, nil, "", req.PortfolioFundID)
 	}
 
 	txLog.InfoContext(ctx, "transaction created", "transactionID", transaction.ID, "type", transaction.Type, "shares", transaction.Shares)
@@ -174,6 +182,8 @@ func (s *TransactionService) UpdateTransaction(
 	id string,
 	req request.UpdateTransactionRequest,
 ) (*model.Transaction, error) {
+	ctx, span := tracing.Start(ctx, "TransactionService.UpdateTransaction")
+	defer span.End()
 	txLog.DebugContext(ctx, "updating transaction", "transactionID", id)
 
 	tx, err := s.db.BeginTx(ctx, nil)
@@ -187,6 +197,7 @@ func (s *TransactionService) UpdateTransaction(
 		return nil, fmt.Errorf("get transaction: %w", err)
 	}
 
+	before := transaction
 	oldType := transaction.Type
 	oldDate := transaction.Date
 	oldPortfolioFundID := transaction.PortfolioFundID
@@ -212,6 +223,10 @@ func (s *TransactionService) UpdateTransaction(
 		return nil, fmt.Errorf("failed to update transaction: %w", err)
 	}
 
+	if err := recordAudit(ctx, s.auditRepo.WithTx(tx), model.AuditEntityTransaction, id, model.AuditActionUpdate, before, transaction); err != nil {
+		return nil, err
+	}
+
 	if err := tx.Commit(); err != nil {
 		return nil, fmt.Errorf("commit transaction: %w", err)
 	}
@@ -221,19 +236,9 @@ func (s *TransactionService) UpdateTransaction(
 		if oldDate.Before(regenDate) {
 			regenDate = oldDate
 		}
-		//nolint:gosec // G118: Background context is intentional — goroutine outlives the HTTP request.
-		go func() {
-			if err := s.materializedInvalidator.RegenerateMaterializedTable(context.Background(), regenDate, nil, "", transaction.PortfolioFundID); err != nil {
-				txLog.Warn("failed to regenerate materialized table after transaction update", "error", err)
-			}
-		}()
+		s.materializedInvalidator.ScheduleRegeneration(ctx, regenDate, nil, "", transaction.PortfolioFundID)
 		if oldPortfolioFundID != transaction.PortfolioFundID {
-			//nolint:gosec // G118: Background context is intentional — goroutine outlives the HTTP request.
-			go func() {
-				if err := s.materializedInvalidator.RegenerateMaterializedTable(context.Background(), regenDate, nil, "", oldPortfolioFundID); err != nil {
-					txLog.Warn("failed to regenerate materialized table for old portfolio-fund", "error", err)
-				}
-			}()
+			s.materializedInvalidator.ScheduleRegeneration(ctx, regenDate, nil, "", oldPortfolioFundID)
 		}
 	}
 
@@ -252,6 +257,8 @@ func (s *TransactionService) UpdateTransaction(
 // Returns ErrTransactionNotFound if the transaction does not exist.
 // Returns an error if the database deletion fails.
 func (s *TransactionService) DeleteTransaction(ctx context.Context, id string) error {
+	ctx, span := tracing.Start(ctx, "TransactionService.DeleteTransaction")
+	defer span.End()
 	txLog.DebugContext(ctx, "deleting transaction", "transactionID", id)
 
 	tx, err := s.db.BeginTx(ctx, nil)
@@ -265,6 +272,11 @@ func (s *TransactionService) DeleteTransaction(ctx context.Context, id string) e
 		return fmt.Errorf("get transaction: %w", err)
 	}
 
+	label := fmt.Sprintf("%s %g @ %g on %s", transaction.Type, transaction.Shares, transaction.CostPerShare, transaction.Date.Format("2006-01-02"))
+	if err := moveToTrash(ctx, s.trashRepo.WithTx(tx), model.TrashEntityTransaction, id, label, transactionTrashSpecs(id)); err != nil {
+		return err
+	}
+
 	if transaction.Type == "sell" {
 		if err := s.realizedGainLossRepo.WithTx(tx).DeleteRealizedGainLossByTransactionID(ctx, id); err != nil {
 			return fmt.Errorf("failed to delete realized gain/loss: %w", err)
@@ -294,17 +306,16 @@ func (s *TransactionService) DeleteTransaction(ctx context.Context, id string) e
 		return fmt.Errorf("failed to delete transaction: %w", err)
 	}
 
+	if err := recordAudit(ctx, s.auditRepo.WithTx(tx), model.AuditEntityTransaction, id, model.AuditActionDelete, transaction, nil); err != nil {
+		return err
+	}
+
 	if err := tx.Commit(); err != nil {
 		return fmt.Errorf("commit transaction: %w", err)
 	}
 
 	if s.materializedInvalidator != nil {
-		//nolint:gosec // G118: Background context is intentional — goroutine outlives the HTTP request.
-		go func() {
-			if err := s.materializedInvalidator.RegenerateMaterializedTable(context.Background(), transaction.Date, nil, "", transaction.PortfolioFundID); err != nil {
-				txLog.Warn("failed to regenerate materialized table after transaction deletion", "error", err)
-			}
-		}()
+		s.materializedInvalidator.ScheduleRegeneration(ctx, transaction.Date, nil, "", transaction.PortfolioFundID)
 	}
 
 	txLog.InfoContext(ctx, "transaction deleted", "transactionID", id)
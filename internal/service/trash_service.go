@@ -0,0 +1,370 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/logging"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/model"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/repository"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/tracing"
+)
+
+var trashLog = logging.NewLogger("database")
+
+// DefaultTrashRetention is how long deleted entities stay restorable when no retention is configured.
+const DefaultTrashRetention = 30 * 24 * time.Hour
+
+// TrashService handles the trash of soft-deleted portfolios, funds, transactions and dividends.
+// Deleting one of these entities snapshots its row and every row the deletion cascades to
+// (see the *TrashSpecs functions) into the trash before the rows are removed. Restoring
+// re-inserts the snapshot and regenerates materialized history from the earliest affected date.
+type TrashService struct {
+	db                      *sql.DB
+	trashRepo               *repository.TrashRepository
+	ibkrRepo                *repository.IbkrRepository
+	auditRepo               *repository.AuditRepository
+	retention               time.Duration
+	materializedInvalidator MaterializedInvalidator
+}
+
+// NewTrashService creates a new TrashService with the provided repository dependencies.
+// retention is how long items stay in the trash before PurgeExpiredTrash removes them;
+// a non-positive value falls back to DefaultTrashRetention.
+func NewTrashService(
+	db *sql.DB,
+	trashRepo *repository.TrashRepository,
+	ibkrRepo *repository.IbkrRepository,
+	retention time.Duration,
+) *TrashService {
+	if retention <= 0 {
+		retention = DefaultTrashRetention
+	}
+	return &TrashService{
+		db:        db,
+		trashRepo: trashRepo,
+		ibkrRepo:  ibkrRepo,
+		auditRepo: repository.NewAuditRepository(db),
+		retention: retention,
+	}
+}
+
+// SetMaterializedInvalidator injects the MaterializedInvalidator after construction.
+// This breaks the circular initialization order between TrashService and MaterializedService.
+func (s *TrashService) SetMaterializedInvalidator(m MaterializedInvalidator) {
+	s.materializedInvalidator = m
+}
+
+// GetTrash retrieves the items in the trash, newest first, optionally filtered by entity type.
+func (s *TrashService) GetTrash(entityType string) ([]model.TrashItem, error) {
+	trashLog.Debug("retrieving trash", "entity_type", entityType)
+	items, err := s.trashRepo.GetTrashItems(entityType)
+	if err != nil {
+		return nil, fmt.Errorf("get trash items: %w", err)
+	}
+	for i := range items {
+		items[i].ExpiresAt = items[i].DeletedAt.Add(s.retention)
+	}
+	return items, nil
+}
+
+// RestoreTrashItem brings a deleted entity and its cascaded children back and removes it from the trash.
+// IBKR inbox rows whose allocation is restored are marked processed again. Materialized history
+// is regenerated in the background from the earliest transaction or dividend date in the snapshot.
+//
+// Returns ErrTrashItemNotFound if the item does not exist, and ErrTrashRestoreConflict if the rows
+// collide with current data or depend on rows deleted since (e.g. restoring a transaction whose
+// portfolio is itself in the trash).
+func (s *TrashService) RestoreTrashItem(ctx context.Context, id string) (model.TrashItem, error) {
+	ctx, span := tracing.Start(ctx, "TrashService.RestoreTrashItem")
+	defer span.End()
+	trashLog.DebugContext(ctx, "restoring trash item", "trash_id", id)
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return model.TrashItem{}, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }() //nolint:errcheck // Rollback is a no-op after Commit; error is intentionally ignored.
+
+	item, err := s.trashRepo.WithTx(tx).GetTrashItem(id)
+	if err != nil {
+		return model.TrashItem{}, fmt.Errorf("get trash item: %w", err)
+	}
+
+	for _, t := range item.Payload {
+		if err := s.trashRepo.WithTx(tx).RestoreRows(ctx, t); err != nil {
+			return model.TrashItem{}, fmt.Errorf("restore %s: %w", item.EntityType, err)
+		}
+	}
+
+	if err := s.reprocessIbkrAllocations(ctx, tx, item.Payload); err != nil {
+		return model.TrashItem{}, err
+	}
+
+	if err := s.trashRepo.WithTx(tx).DeleteTrashItem(ctx, id); err != nil {
+		return model.TrashItem{}, fmt.Errorf("delete trash item: %w", err)
+	}
+
+	if err := recordAudit(ctx, s.auditRepo.WithTx(tx), model.AuditEntityType(item.EntityType), item.EntityID, model.AuditActionRestore, nil, trashEntityRow(item)); err != nil {
+		return model.TrashItem{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return model.TrashItem{}, fmt.Errorf("commit transaction: %w", err)
+	}
+
+	s.regenerateAfterRestore(ctx, item)
+
+	trashLog.InfoContext(ctx, "trash item restored", "trash_id", id, "entity_type", item.EntityType, "entity_id", item.EntityID)
+	item.ExpiresAt = item.DeletedAt.Add(s.retention)
+	return item, nil
+}
+
+// PurgeTrashItem permanently removes a single item from the trash.
+// Returns ErrTrashItemNotFound if the item does not exist.
+func (s *TrashService) PurgeTrashItem(ctx context.Context, id string) error {
+	ctx, span := tracing.Start(ctx, "TrashService.PurgeTrashItem")
+	defer span.End()
+	trashLog.DebugContext(ctx, "purging trash item", "trash_id", id)
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }() //nolint:errcheck // Rollback is a no-op after Commit; error is intentionally ignored.
+
+	item, err := s.trashRepo.WithTx(tx).GetTrashItem(id)
+	if err != nil {
+		return fmt.Errorf("get trash item: %w", err)
+	}
+
+	if err := s.trashRepo.WithTx(tx).DeleteTrashItem(ctx, id); err != nil {
+		return fmt.Errorf("delete trash item: %w", err)
+	}
+
+	if err := recordAudit(ctx, s.auditRepo.WithTx(tx), model.AuditEntityType(item.EntityType), item.EntityID, model.AuditActionPurge, trashEntityRow(item), nil); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+
+	trashLog.InfoContext(ctx, "trash item purged", "trash_id", id, "entity_type", item.EntityType, "entity_id", item.EntityID)
+	return nil
+}
+
+// PurgeExpiredTrash permanently removes every trash item older than the retention period.
+// Intended to be run by the scheduler.
+func (s *TrashService) PurgeExpiredTrash(ctx context.Context) (model.TrashPurgeResult, error) {
+	ctx, span := tracing.Start(ctx, "TrashService.PurgeExpiredTrash")
+	defer span.End()
+	cutoff := time.Now().UTC().Add(-s.retention)
+	purged, err := s.trashRepo.PurgeTrashBefore(ctx, cutoff)
+	if err != nil {
+		return model.TrashPurgeResult{}, fmt.Errorf("purge expired trash: %w", err)
+	}
+
+	trashLog.InfoContext(ctx, "expired trash purged", "purged", purged, "retention_days", int(s.retention.Hours()/24))
+	return model.TrashPurgeResult{Purged: purged}, nil
+}
+
+// reprocessIbkrAllocations marks inbox transactions processed again when a restore brings back
+// their allocation. Deleting the only transaction allocated from an inbox row reverts that row
+// to pending (see TransactionService.DeleteTransaction); restoring undoes that.
+func (s *TrashService) reprocessIbkrAllocations(ctx context.Context, tx *sql.Tx, payload []model.TrashTable) error {
+	now := time.Now().UTC()
+	seen := make(map[string]bool)
+	for _, t := range payload {
+		if t.Table != "ibkr_transaction_allocation" {
+			continue
+		}
+		for _, row := range t.Rows {
+			ibkrID := row["ibkr_transaction_id"]
+			if ibkrID == nil || seen[*ibkrID] {
+				continue
+			}
+			seen[*ibkrID] = true
+
+			ibkrTx, err := s.ibkrRepo.WithTx(tx).GetIbkrTransaction(*ibkrID)
+			if err != nil {
+				return fmt.Errorf("get ibkr transaction: %w", err)
+			}
+			if ibkrTx.Status != string(model.InboxStatusPending) {
+				continue
+			}
+			if err := s.ibkrRepo.WithTx(tx).UpdateIbkrTransactionStatus(ctx, *ibkrID, string(model.InboxStatusProcessed), &now); err != nil {
+				return fmt.Errorf("reprocess ibkr transaction: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// regenerateAfterRestore regenerates materialized history for the restored entity in the background,
+// starting at the earliest transaction or dividend date in the snapshot. Funds carry no history of
+// their own (a fund in use cannot be deleted), so nothing is regenerated for them.
+func (s *TrashService) regenerateAfterRestore(ctx context.Context, item model.TrashItem) {
+	if s.materializedInvalidator == nil {
+		return
+	}
+
+	var from time.Time
+	var pfID string
+	for _, t := range item.Payload {
+		dateColumn := ""
+		switch t.Table {
+		case "transaction":
+			dateColumn = "date"
+		case "dividend":
+			dateColumn = "ex_dividend_date"
+		default:
+			continue
+		}
+		for _, row := range t.Rows {
+			v := row[dateColumn]
+			if v == nil {
+				continue
+			}
+			d, err := repository.ParseTime(*v)
+			if err != nil {
+				continue
+			}
+			if from.IsZero() || d.Before(from) {
+				from = d
+				if id := row["portfolio_fund_id"]; id != nil {
+					pfID = *id
+				}
+			}
+		}
+	}
+	if from.IsZero() {
+		return
+	}
+
+	var portfolioIDs []string
+	if item.EntityType == string(model.TrashEntityPortfolio) {
+		portfolioIDs = []string{item.EntityID}
+		pfID = ""
+	}
+
+	s.materializedInvalidator.ScheduleRegeneration(ctx, from, portfolioIDs, "", pfID)
+}
+
+// trashEntityRow returns the snapshot of the trashed entity's own row, used as the audit state.
+// Trash entity types are named after their tables.
+func trashEntityRow(item model.TrashItem) map[string]*string {
+	for _, t := range item.Payload {
+		if t.Table != item.EntityType {
+			continue
+		}
+		for _, row := range t.Rows {
+			if id := row["id"]; id != nil && *id == item.EntityID {
+				return row
+			}
+		}
+	}
+	return nil
+}
+
+// trashSpec selects the rows of one table that a deletion removes.
+type trashSpec struct {
+	table string
+	where string
+	args  []any
+}
+
+// moveToTrash snapshots the rows selected by specs into a new trash item.
+// It must run inside the deleting transaction, before any row is deleted, so the snapshot
+// includes the rows that ON DELETE CASCADE is about to remove. Specs are listed in
+// foreign-key insertion order, which is the order a restore re-inserts them in.
+// Tables without matching rows are left out of the payload.
+func moveToTrash(
+	ctx context.Context,
+	repo *repository.TrashRepository,
+	entityType model.TrashEntityType,
+	entityID, label string,
+	specs []trashSpec,
+) error {
+	item := &model.TrashItem{
+		ID:         uuid.New().String(),
+		EntityType: string(entityType),
+		EntityID:   entityID,
+		Label:      label,
+		DeletedAt:  time.Now().UTC(),
+		RequestID:  logging.RequestIDFromContext(ctx),
+	}
+
+	for _, spec := range specs {
+		snapshot, err := repo.SnapshotRows(ctx, spec.table, spec.where, spec.args...)
+		if err != nil {
+			return fmt.Errorf("snapshot %s for trash: %w", spec.table, err)
+		}
+		if len(snapshot.Rows) > 0 {
+			item.Payload = append(item.Payload, snapshot)
+		}
+	}
+
+	if err := repo.InsertTrashItem(ctx, item); err != nil {
+		return fmt.Errorf("move %s to trash: %w", entityType, err)
+	}
+	return nil
+}
+
+// portfolioTrashSpecs selects a portfolio and everything that cascades from it:
+// its shares, its portfolio funds with their investment plans, transactions and dividends, realized gains,
+// IBKR allocations, allocation targets and alert rules with their alerts.
+func portfolioTrashSpecs(portfolioID string) []trashSpec {
+	inPortfolio := `portfolio_fund_id IN (SELECT id FROM portfolio_fund WHERE portfolio_id = ?)`
+	return []trashSpec{
+		{table: "portfolio", where: "id = ?", args: []any{portfolioID}},
+		{table: "portfolio_share", where: "portfolio_id = ?", args: []any{portfolioID}},
+		{table: "portfolio_fund", where: "portfolio_id = ?", args: []any{portfolioID}},
+		{table: "investment_plan", where: inPortfolio, args: []any{portfolioID}},
+		{table: "transaction", where: inPortfolio, args: []any{portfolioID}},
+		{table: "dividend", where: inPortfolio, args: []any{portfolioID}},
+		{table: "realized_gain_loss", where: "portfolio_id = ?", args: []any{portfolioID}},
+		{table: "ibkr_transaction_allocation", where: "portfolio_id = ?", args: []any{portfolioID}},
+		{table: "allocation_target", where: "portfolio_id = ?", args: []any{portfolioID}},
+		{table: "alert_rule", where: "portfolio_id = ?", args: []any{portfolioID}},
+		{table: "alert", where: "rule_id IN (SELECT id FROM alert_rule WHERE portfolio_id = ?)", args: []any{portfolioID}},
+	}
+}
+
+// fundTrashSpecs selects a fund with its price history, the allocation targets that name it
+// and its alert rules with their alerts. Funds in use cannot be deleted, so there are no
+// other portfolio rows to capture.
+func fundTrashSpecs(fundID string) []trashSpec {
+	return []trashSpec{
+		{table: "fund", where: "id = ?", args: []any{fundID}},
+		{table: "fund_price", where: "fund_id = ?", args: []any{fundID}},
+		{table: "allocation_target", where: "fund_id = ?", args: []any{fundID}},
+		{table: "alert_rule", where: "fund_id = ?", args: []any{fundID}},
+		{table: "alert", where: "rule_id IN (SELECT id FROM alert_rule WHERE fund_id = ?)", args: []any{fundID}},
+	}
+}
+
+// transactionTrashSpecs selects a transaction with its realized gain and IBKR allocation.
+func transactionTrashSpecs(transactionID string) []trashSpec {
+	return []trashSpec{
+		{table: "transaction", where: "id = ?", args: []any{transactionID}},
+		{table: "realized_gain_loss", where: "transaction_id = ?", args: []any{transactionID}},
+		{table: "ibkr_transaction_allocation", where: "transaction_id = ?", args: []any{transactionID}},
+	}
+}
+
+// dividendTrashSpecs selects a dividend and, when reinvested, its reinvestment transaction.
+// The transaction is listed first because the dividend references it.
+func dividendTrashSpecs(dividendID, reinvestmentTransactionID string) []trashSpec {
+	var specs []trashSpec
+	if reinvestmentTransactionID != "" {
+		specs = append(specs,
+			trashSpec{table: "transaction", where: "id = ?", args: []any{reinvestmentTransactionID}},
+			trashSpec{table: "ibkr_transaction_allocation", where: "transaction_id = ?", args: []any{reinvestmentTransactionID}},
+		)
+	}
+	return append(specs, trashSpec{table: "dividend", where: "id = ?", args: []any{dividendID}})
+}
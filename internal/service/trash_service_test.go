@@ -0,0 +1,455 @@
+package service_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/api/request"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/apperrors"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/model"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/repository"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/service"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/testutil"
+)
+
+// countRows returns the number of rows in table matching where.
+func countRows(t *testing.T, db *sql.DB, table, where string, args ...any) int {
+	t.Helper()
+	var n int
+	//nolint:gosec // G202: test-only query built from constants
+	if err := db.QueryRow(`SELECT COUNT(*) FROM "`+table+`" WHERE `+where, args...).Scan(&n); err != nil {
+		t.Fatalf("count %s: %v", table, err)
+	}
+	return n
+}
+
+// onlyTrashItem returns the single item in the trash, failing the test otherwise.
+func onlyTrashItem(t *testing.T, svc *service.TrashService) model.TrashItem {
+	t.Helper()
+	items, err := svc.GetTrash("")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(items) != 1 {
+		t.Fatalf("expected 1 trash item, got %d", len(items))
+	}
+	return items[0]
+}
+
+// insertAlert stores an active alert for rule.
+func insertAlert(t *testing.T, db *sql.DB, ruleID string) {
+	t.Helper()
+	alert := &model.Alert{ID: testutil.MakeID(), RuleID: ruleID, Status: model.AlertStatusActive, Message: "triggered", Value: 100, TriggeredAt: time.Now()}
+	if err := repository.NewAlertRepository(db).InsertAlert(context.Background(), alert); err != nil {
+		t.Fatalf("insert alert: %v", err)
+	}
+}
+
+//nolint:gocyclo // Test function with multiple subtests and assertions.
+func TestTrashService_PortfolioRoundTrip(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	ctx := context.Background()
+	pfSvc := testutil.NewTestPortfolioService(t, db)
+	trashSvc := testutil.NewTestTrashService(t, db)
+
+	portfolio := testutil.NewPortfolio().WithName("Pension").Build(t, db)
+	fund := testutil.NewFund().Build(t, db)
+	pf := testutil.NewPortfolioFund(portfolio.ID, fund.ID).Build(t, db)
+	testutil.NewTransaction(pf.ID).WithDate(time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)).Build(t, db)
+	sell := testutil.NewTransaction(pf.ID).WithType("sell").WithShares(10).Build(t, db)
+	testutil.NewRealizedGainLoss(portfolio.ID, fund.ID, sell.ID).Build(t, db)
+	testutil.NewDividend(fund.ID, pf.ID).Build(t, db)
+
+	if err := pfSvc.DeletePortfolio(ctx, portfolio.ID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if n := countRows(t, db, "portfolio_fund", "portfolio_id = ?", portfolio.ID); n != 0 {
+		t.Fatalf("expected cascade to remove portfolio funds, %d left", n)
+	}
+
+	item := onlyTrashItem(t, trashSvc)
+	if item.EntityType != "portfolio" || item.EntityID != portfolio.ID || item.Label != "Pension" {
+		t.Errorf("unexpected trash item: %+v", item)
+	}
+	wantContents := map[string]int{"portfolio": 1, "portfolio_fund": 1, "transaction": 2, "dividend": 1, "realized_gain_loss": 1}
+	for table, want := range wantContents {
+		if item.Contents[table] != want {
+			t.Errorf("expected %d %s rows in trash, got %d", want, table, item.Contents[table])
+		}
+	}
+	if !item.ExpiresAt.Equal(item.DeletedAt.Add(30 * 24 * time.Hour)) {
+		t.Errorf("expected expiry 30 days after deletion, got %v -> %v", item.DeletedAt, item.ExpiresAt)
+	}
+
+	if _, err := trashSvc.RestoreTrashItem(ctx, item.ID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if n := countRows(t, db, "portfolio", "id = ? AND name = ?", portfolio.ID, "Pension"); n != 1 {
+		t.Error("expected portfolio to be restored")
+	}
+	if n := countRows(t, db, "transaction", "portfolio_fund_id = ?", pf.ID); n != 2 {
+		t.Errorf("expected 2 restored transactions, got %d", n)
+	}
+	if n := countRows(t, db, "dividend", "portfolio_fund_id = ?", pf.ID); n != 1 {
+		t.Errorf("expected 1 restored dividend, got %d", n)
+	}
+	if n := countRows(t, db, "realized_gain_loss", "transaction_id = ?", sell.ID); n != 1 {
+		t.Errorf("expected 1 restored realized gain, got %d", n)
+	}
+	if n := countRows(t, db, "trash", "1 = 1"); n != 0 {
+		t.Errorf("expected trash to be empty after restore, got %d", n)
+	}
+	if n := countRows(t, db, "audit_event", "entity_id = ? AND action = ?", portfolio.ID, "restore"); n != 1 {
+		t.Errorf("expected a restore audit event, got %d", n)
+	}
+}
+
+//nolint:gocyclo // Test function with multiple subtests and assertions.
+func TestTrashService_Restore(t *testing.T) {
+	t.Run("transaction allocated from the inbox is reprocessed", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		ctx := context.Background()
+		txSvc := testutil.NewTestTransactionService(t, db)
+		trashSvc := testutil.NewTestTrashService(t, db)
+
+		portfolio := testutil.NewPortfolio().Build(t, db)
+		fund := testutil.NewFund().Build(t, db)
+		pf := testutil.NewPortfolioFund(portfolio.ID, fund.ID).Build(t, db)
+		txn := testutil.NewTransaction(pf.ID).Build(t, db)
+		ibkrTx := testutil.NewIBKRTransaction().WithStatus("processed").Build(t, db)
+		if err := repository.NewIbkrRepository(db).InsertIbkrTransactionAllocation(ctx, model.IBKRTransactionAllocation{
+			ID:                   testutil.MakeID(),
+			IBKRTransactionID:    ibkrTx.ID,
+			PortfolioID:          portfolio.ID,
+			AllocationPercentage: 100,
+			AllocatedAmount:      1000,
+			AllocatedShares:      100,
+			TransactionID:        txn.ID,
+			Type:                 "trade",
+			CreatedAt:            time.Now().UTC(),
+		}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if err := txSvc.DeleteTransaction(ctx, txn.ID); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if n := countRows(t, db, "ibkr_transaction", "id = ? AND status = 'pending'", ibkrTx.ID); n != 1 {
+			t.Fatal("expected inbox transaction to revert to pending on delete")
+		}
+
+		item := onlyTrashItem(t, trashSvc)
+		if _, err := trashSvc.RestoreTrashItem(ctx, item.ID); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if n := countRows(t, db, "ibkr_transaction_allocation", "transaction_id = ?", txn.ID); n != 1 {
+			t.Error("expected allocation to be restored")
+		}
+		if n := countRows(t, db, "ibkr_transaction", "id = ? AND status = 'processed'", ibkrTx.ID); n != 1 {
+			t.Error("expected inbox transaction to be processed again")
+		}
+	})
+
+	t.Run("dividend is restored with its reinvestment transaction", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		ctx := context.Background()
+		divSvc := testutil.NewTestDividendService(t, db)
+		trashSvc := testutil.NewTestTrashService(t, db)
+
+		portfolio := testutil.NewPortfolio().Build(t, db)
+		fund := testutil.NewFund().Build(t, db)
+		pf := testutil.NewPortfolioFund(portfolio.ID, fund.ID).Build(t, db)
+		reinvest := testutil.NewTransaction(pf.ID).WithType("dividend").WithShares(2).Build(t, db)
+		dividend := testutil.NewDividend(fund.ID, pf.ID).WithReinvestmentTransaction(reinvest.ID).Build(t, db)
+
+		if err := divSvc.DeleteDividend(ctx, dividend.ID); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if n := countRows(t, db, "transaction", "id = ?", reinvest.ID); n != 0 {
+			t.Fatal("expected reinvestment transaction to be deleted with the dividend")
+		}
+
+		item := onlyTrashItem(t, trashSvc)
+		if item.EntityType != "dividend" || item.EntityID != dividend.ID {
+			t.Errorf("unexpected trash item: %+v", item)
+		}
+		if _, err := trashSvc.RestoreTrashItem(ctx, item.ID); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if n := countRows(t, db, "dividend", "id = ? AND reinvestment_transaction_id = ?", dividend.ID, reinvest.ID); n != 1 {
+			t.Error("expected dividend to be restored with its reinvestment link")
+		}
+		if n := countRows(t, db, "transaction", "id = ?", reinvest.ID); n != 1 {
+			t.Error("expected reinvestment transaction to be restored")
+		}
+	})
+
+	t.Run("fund is restored with its price history", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		ctx := context.Background()
+		fundSvc := testutil.NewTestFundService(t, db)
+		trashSvc := testutil.NewTestTrashService(t, db)
+
+		fund := testutil.NewFund().Build(t, db)
+		testutil.NewFundPrice(fund.ID).WithDate(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)).Build(t, db)
+		testutil.NewFundPrice(fund.ID).WithDate(time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)).Build(t, db)
+
+		if err := fundSvc.DeleteFund(ctx, fund.ID); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if n := countRows(t, db, "fund_price", "fund_id = ?", fund.ID); n != 0 {
+			t.Fatalf("expected fund prices to be deleted, %d left", n)
+		}
+
+		item := onlyTrashItem(t, trashSvc)
+		if _, err := trashSvc.RestoreTrashItem(ctx, item.ID); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if n := countRows(t, db, "fund_price", "fund_id = ?", fund.ID); n != 2 {
+			t.Errorf("expected 2 restored fund prices, got %d", n)
+		}
+	})
+
+	t.Run("portfolio is restored with its alert rules and alerts", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		ctx := context.Background()
+		pfSvc := testutil.NewTestPortfolioService(t, db)
+		alertSvc := testutil.NewTestAlertService(t, db)
+		trashSvc := testutil.NewTestTrashService(t, db)
+
+		portfolio := testutil.NewPortfolio().Build(t, db)
+		rule := createAlertRule(t, alertSvc, request.CreateAlertRuleRequest{Name: "Floor", Type: "portfolio_value", PortfolioID: portfolio.ID, Threshold: threshold(150)})
+		insertAlert(t, db, rule.ID)
+
+		if err := pfSvc.DeletePortfolio(ctx, portfolio.ID); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if n := countRows(t, db, "alert_rule", "portfolio_id = ?", portfolio.ID); n != 0 {
+			t.Fatalf("expected cascade to remove alert rules, %d left", n)
+		}
+
+		item := onlyTrashItem(t, trashSvc)
+		if item.Contents["alert_rule"] != 1 || item.Contents["alert"] != 1 {
+			t.Errorf("expected the alert rule and its alert in the trash, got %+v", item.Contents)
+		}
+		if _, err := trashSvc.RestoreTrashItem(ctx, item.ID); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if n := countRows(t, db, "alert_rule", "id = ? AND portfolio_id = ?", rule.ID, portfolio.ID); n != 1 {
+			t.Error("expected alert rule to be restored")
+		}
+		if n := countRows(t, db, "alert", "rule_id = ?", rule.ID); n != 1 {
+			t.Errorf("expected 1 restored alert, got %d", n)
+		}
+	})
+
+	t.Run("fund is restored with its alert rules and alerts", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		ctx := context.Background()
+		fundSvc := testutil.NewTestFundService(t, db)
+		alertSvc := testutil.NewTestAlertService(t, db)
+		trashSvc := testutil.NewTestTrashService(t, db)
+
+		fund := testutil.NewFund().Build(t, db)
+		testutil.NewFundPrice(fund.ID).WithDate(time.Now().UTC().Truncate(24*time.Hour)).WithPrice(10).Build(t, db)
+		rule := createAlertRule(t, alertSvc, request.CreateAlertRuleRequest{Name: "Below 20", Type: "fund_price", FundID: fund.ID, Direction: "below", Threshold: threshold(20)})
+		if _, err := alertSvc.EvaluateFundAlerts(ctx); err != nil {
+			t.Fatalf("EvaluateFundAlerts: %v", err)
+		}
+
+		if err := fundSvc.DeleteFund(ctx, fund.ID); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if n := countRows(t, db, "alert", "rule_id = ?", rule.ID); n != 0 {
+			t.Fatalf("expected cascade to remove alerts, %d left", n)
+		}
+
+		item := onlyTrashItem(t, trashSvc)
+		if _, err := trashSvc.RestoreTrashItem(ctx, item.ID); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if n := countRows(t, db, "alert_rule", "id = ? AND fund_id = ?", rule.ID, fund.ID); n != 1 {
+			t.Error("expected alert rule to be restored")
+		}
+		if n := countRows(t, db, "alert", "rule_id = ? AND status = ?", rule.ID, "active"); n != 1 {
+			t.Errorf("expected the active alert to be restored, got %d", n)
+		}
+	})
+
+	t.Run("portfolio is restored with its allocation targets", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		ctx := context.Background()
+		pfSvc := testutil.NewTestPortfolioService(t, db)
+		allocationSvc := testutil.NewTestAllocationService(t, db)
+		trashSvc := testutil.NewTestTrashService(t, db)
+
+		portfolio := testutil.NewPortfolio().Build(t, db)
+		if _, err := allocationSvc.SetAllocationTargets(ctx, portfolio.ID, request.SetAllocationTargetsRequest{Targets: []request.AllocationTargetRequest{
+			{AssetClass: "FUND", TargetPercent: 70, TolerancePercent: 5},
+			{AssetClass: "STOCK", TargetPercent: 30, TolerancePercent: 5},
+		}}); err != nil {
+			t.Fatalf("SetAllocationTargets: %v", err)
+		}
+
+		if err := pfSvc.DeletePortfolio(ctx, portfolio.ID); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if n := countRows(t, db, "allocation_target", "portfolio_id = ?", portfolio.ID); n != 0 {
+			t.Fatalf("expected cascade to remove allocation targets, %d left", n)
+		}
+
+		item := onlyTrashItem(t, trashSvc)
+		if _, err := trashSvc.RestoreTrashItem(ctx, item.ID); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		targets, err := allocationSvc.GetAllocationTargets(portfolio.ID)
+		if err != nil {
+			t.Fatalf("GetAllocationTargets: %v", err)
+		}
+		if len(targets) != 2 || targets[0].TargetPercent+targets[1].TargetPercent != 100 {
+			t.Errorf("expected both allocation targets to be restored, got %+v", targets)
+		}
+	})
+
+	t.Run("fund is restored with the allocation targets that name it", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		ctx := context.Background()
+		fundSvc := testutil.NewTestFundService(t, db)
+		allocationSvc := testutil.NewTestAllocationService(t, db)
+		trashSvc := testutil.NewTestTrashService(t, db)
+
+		portfolio := testutil.NewPortfolio().Build(t, db)
+		fund := testutil.NewFund().Build(t, db)
+		pf := testutil.NewPortfolioFund(portfolio.ID, fund.ID).Build(t, db)
+		if _, err := allocationSvc.SetAllocationTargets(ctx, portfolio.ID, request.SetAllocationTargetsRequest{Targets: []request.AllocationTargetRequest{
+			{FundID: fund.ID, TargetPercent: 100},
+		}}); err != nil {
+			t.Fatalf("SetAllocationTargets: %v", err)
+		}
+		// The target outlives the fund's removal from the portfolio, so the fund can be deleted.
+		if err := fundSvc.DeletePortfolioFund(ctx, pf.ID); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if err := fundSvc.DeleteFund(ctx, fund.ID); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if n := countRows(t, db, "allocation_target", "fund_id = ?", fund.ID); n != 0 {
+			t.Fatalf("expected cascade to remove allocation targets, %d left", n)
+		}
+
+		items, err := trashSvc.GetTrash("fund")
+		if err != nil || len(items) != 1 {
+			t.Fatalf("expected 1 trashed fund, got %d (%v)", len(items), err)
+		}
+		if _, err := trashSvc.RestoreTrashItem(ctx, items[0].ID); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if n := countRows(t, db, "allocation_target", "portfolio_id = ? AND fund_id = ?", portfolio.ID, fund.ID); n != 1 {
+			t.Errorf("expected the allocation target to be restored, got %d", n)
+		}
+	})
+
+	t.Run("conflict when the parent is gone leaves the item in the trash", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		ctx := context.Background()
+		txSvc := testutil.NewTestTransactionService(t, db)
+		pfSvc := testutil.NewTestPortfolioService(t, db)
+		trashSvc := testutil.NewTestTrashService(t, db)
+
+		portfolio := testutil.NewPortfolio().Build(t, db)
+		fund := testutil.NewFund().Build(t, db)
+		pf := testutil.NewPortfolioFund(portfolio.ID, fund.ID).Build(t, db)
+		txn := testutil.NewTransaction(pf.ID).Build(t, db)
+
+		if err := txSvc.DeleteTransaction(ctx, txn.ID); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		txItems, err := trashSvc.GetTrash("transaction")
+		if err != nil || len(txItems) != 1 {
+			t.Fatalf("expected 1 trashed transaction, got %d (%v)", len(txItems), err)
+		}
+		if err := pfSvc.DeletePortfolio(ctx, portfolio.ID); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		_, err = trashSvc.RestoreTrashItem(ctx, txItems[0].ID)
+		if !errors.Is(err, apperrors.ErrTrashRestoreConflict) {
+			t.Fatalf("expected ErrTrashRestoreConflict, got %v", err)
+		}
+		if n := countRows(t, db, "trash", "id = ?", txItems[0].ID); n != 1 {
+			t.Error("expected trash item to remain after a failed restore")
+		}
+	})
+
+	t.Run("unknown item", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		trashSvc := testutil.NewTestTrashService(t, db)
+
+		_, err := trashSvc.RestoreTrashItem(context.Background(), testutil.MakeID())
+		if !errors.Is(err, apperrors.ErrTrashItemNotFound) {
+			t.Errorf("expected ErrTrashItemNotFound, got %v", err)
+		}
+	})
+}
+
+func TestTrashService_Purge(t *testing.T) {
+	t.Run("purge single item", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		ctx := context.Background()
+		pfSvc := testutil.NewTestPortfolioService(t, db)
+		trashSvc := testutil.NewTestTrashService(t, db)
+
+		portfolio := testutil.NewPortfolio().Build(t, db)
+		if err := pfSvc.DeletePortfolio(ctx, portfolio.ID); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		item := onlyTrashItem(t, trashSvc)
+
+		if err := trashSvc.PurgeTrashItem(ctx, item.ID); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := trashSvc.PurgeTrashItem(ctx, item.ID); !errors.Is(err, apperrors.ErrTrashItemNotFound) {
+			t.Errorf("expected ErrTrashItemNotFound, got %v", err)
+		}
+		if n := countRows(t, db, "audit_event", "entity_id = ? AND action = ?", portfolio.ID, "purge"); n != 1 {
+			t.Errorf("expected a purge audit event, got %d", n)
+		}
+	})
+
+	t.Run("expired items are purged after the retention period", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		ctx := context.Background()
+		pfSvc := testutil.NewTestPortfolioService(t, db)
+		trashSvc := testutil.NewTestTrashService(t, db)
+
+		expired := testutil.NewPortfolio().Build(t, db)
+		recent := testutil.NewPortfolio().Build(t, db)
+		for _, id := range []string{expired.ID, recent.ID} {
+			if err := pfSvc.DeletePortfolio(ctx, id); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+		old := time.Now().UTC().AddDate(0, 0, -31).Format("2006-01-02 15:04:05")
+		if _, err := db.Exec(`UPDATE trash SET deleted_at = ? WHERE entity_id = ?`, old, expired.ID); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		result, err := trashSvc.PurgeExpiredTrash(ctx)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.Purged != 1 {
+			t.Errorf("expected 1 purged item, got %d", result.Purged)
+		}
+		if item := onlyTrashItem(t, trashSvc); item.EntityID != recent.ID {
+			t.Errorf("expected recent portfolio to remain, got %s", item.EntityID)
+		}
+	})
+}
@@ -0,0 +1,466 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/api/request"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/auth"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/config"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/logging"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/model"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/repository"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+var webhookLog = logging.NewLogger("system")
+
+const (
+	// webhookPollInterval is how often the delivery worker looks for retries that have
+	// become due. New deliveries wake it immediately.
+	webhookPollInterval = 15 * time.Second
+	// webhookDeliveryRetention is how long finished deliveries stay in the delivery log.
+	webhookDeliveryRetention = 30 * 24 * time.Hour
+	// webhookPurgeInterval is how often the delivery worker purges the delivery log.
+	webhookPurgeInterval = time.Hour
+	// webhookSignatureHeader carries "sha256=" and the hex HMAC-SHA256 of the body.
+	webhookSignatureHeader = "X-IPM-Signature"
+)
+
+// EventPublisher receives domain events from the services that produce them.
+// Services depend on this interface rather than on *WebhookService directly.
+type EventPublisher interface {
+	// Publish delivers event with data in the background. When key is not empty the
+	// event is delivered at most once per subscriber for that key. Failures are logged,
+	// never returned.
+	Publish(ctx context.Context, event model.WebhookEvent, key string, data any)
+}
+
+// publishEvent publishes through p if a publisher is configured.
+func publishEvent(ctx context.Context, p EventPublisher, event model.WebhookEvent, key string, data any) {
+	if p != nil {
+		p.Publish(ctx, event, key, data)
+	}
+}
+
+// WebhookService manages webhook subscriptions and delivers domain events to them.
+// Events are queued in the webhook_delivery table and POSTed by RunDeliveryWorker,
+// signed with the webhook's secret and retried with exponential backoff.
+type WebhookService struct {
+	db          *sql.DB
+	webhookRepo *repository.WebhookRepository
+	auditRepo   *repository.AuditRepository
+	cfg         config.WebhooksConfig
+	client      *http.Client
+
+	// wake signals RunDeliveryWorker that a delivery was queued.
+	wake chan struct{}
+}
+
+// NewWebhookService creates a new WebhookService with the provided dependencies.
+// Deliveries are only sent while RunDeliveryWorker runs.
+func NewWebhookService(
+	db *sql.DB,
+	webhookRepo *repository.WebhookRepository,
+	auditRepo *repository.AuditRepository,
+	cfg config.WebhooksConfig,
+) *WebhookService {
+	return &WebhookService{
+		db:          db,
+		webhookRepo: webhookRepo,
+		auditRepo:   auditRepo,
+		cfg:         cfg,
+		client:      &http.Client{Timeout: time.Duration(cfg.Timeout)},
+		wake:        make(chan struct{}, 1),
+	}
+}
+
+// GetWebhooks retrieves all webhooks without their secrets.
+func (s *WebhookService) GetWebhooks() ([]model.Webhook, error) {
+	webhooks, err := s.webhookRepo.GetWebhooks()
+	if err != nil {
+		return nil, fmt.Errorf("get webhooks: %w", err)
+	}
+	for i := range webhooks {
+		webhooks[i].Secret = ""
+	}
+	return webhooks, nil
+}
+
+// GetWebhook retrieves a webhook without its secret. Returns ErrWebhookNotFound if it does not exist.
+func (s *WebhookService) GetWebhook(webhookID string) (model.Webhook, error) {
+	w, err := s.webhookRepo.GetWebhook(webhookID)
+	if err != nil {
+		return model.Webhook{}, err
+	}
+	w.Secret = ""
+	return w, nil
+}
+
+// CreateWebhook subscribes a URL to the requested events. A secret is generated when
+// none is given. The returned webhook is the only place the secret is shown.
+func (s *WebhookService) CreateWebhook(ctx context.Context, req request.CreateWebhookRequest) (model.Webhook, error) {
+	ctx, span := tracing.Start(ctx, "WebhookService.CreateWebhook")
+	defer span.End()
+
+	secret := req.Secret
+	if secret == "" {
+		var err error
+		if secret, err = auth.NewToken(); err != nil {
+			return model.Webhook{}, err
+		}
+	}
+	now := time.Now().UTC()
+	w := model.Webhook{
+		ID:         uuid.New().String(),
+		URL:        req.URL,
+		EventTypes: toWebhookEvents(req.EventTypes),
+		Secret:     secret,
+		Enabled:    req.Enabled == nil || *req.Enabled,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return model.Webhook{}, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }() //nolint:errcheck // Rollback is a no-op after Commit; error is intentionally ignored.
+
+	if err := s.webhookRepo.WithTx(tx).InsertWebhook(ctx, &w); err != nil {
+		return model.Webhook{}, fmt.Errorf("insert webhook: %w", err)
+	}
+	if err := recordAudit(ctx, s.auditRepo.WithTx(tx), model.AuditEntityWebhook, w.ID, model.AuditActionCreate, nil, withoutSecret(w)); err != nil {
+		return model.Webhook{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return model.Webhook{}, fmt.Errorf("commit transaction: %w", err)
+	}
+
+	webhookLog.InfoContext(ctx, "webhook created", "webhook_id", w.ID, "events", req.EventTypes)
+	return w, nil
+}
+
+// UpdateWebhook changes the fields present in the request. An empty secret is replaced by
+// a newly generated one; the secret is only returned when it changed.
+// Returns ErrWebhookNotFound if the webhook does not exist.
+func (s *WebhookService) UpdateWebhook(ctx context.Context, webhookID string, req request.UpdateWebhookRequest) (model.Webhook, error) {
+	ctx, span := tracing.Start(ctx, "WebhookService.UpdateWebhook")
+	defer span.End()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return model.Webhook{}, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }() //nolint:errcheck // Rollback is a no-op after Commit; error is intentionally ignored.
+
+	before, err := s.webhookRepo.WithTx(tx).GetWebhook(webhookID)
+	if err != nil {
+		return model.Webhook{}, err
+	}
+
+	after := before
+	if req.URL != nil {
+		after.URL = *req.URL
+	}
+	if req.EventTypes != nil {
+		after.EventTypes = toWebhookEvents(req.EventTypes)
+	}
+	if req.Enabled != nil {
+		after.Enabled = *req.Enabled
+	}
+	if req.Secret != nil {
+		after.Secret = *req.Secret
+		if after.Secret == "" {
+			if after.Secret, err = auth.NewToken(); err != nil {
+				return model.Webhook{}, err
+			}
+		}
+	}
+	after.UpdatedAt = time.Now().UTC()
+
+	if err := s.webhookRepo.WithTx(tx).UpdateWebhook(ctx, &after); err != nil {
+		return model.Webhook{}, fmt.Errorf("update webhook: %w", err)
+	}
+	if err := recordAudit(ctx, s.auditRepo.WithTx(tx), model.AuditEntityWebhook, webhookID, model.AuditActionUpdate, withoutSecret(before), withoutSecret(after)); err != nil {
+		return model.Webhook{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return model.Webhook{}, fmt.Errorf("commit transaction: %w", err)
+	}
+
+	if req.Secret == nil {
+		after.Secret = ""
+	}
+	return after, nil
+}
+
+// DeleteWebhook removes a webhook and its delivery log. Returns ErrWebhookNotFound if it does not exist.
+func (s *WebhookService) DeleteWebhook(ctx context.Context, webhookID string) error {
+	ctx, span := tracing.Start(ctx, "WebhookService.DeleteWebhook")
+	defer span.End()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }() //nolint:errcheck // Rollback is a no-op after Commit; error is intentionally ignored.
+
+	before, err := s.webhookRepo.WithTx(tx).GetWebhook(webhookID)
+	if err != nil {
+		return err
+	}
+	if err := s.webhookRepo.WithTx(tx).DeleteWebhook(ctx, webhookID); err != nil {
+		return err
+	}
+	if err := recordAudit(ctx, s.auditRepo.WithTx(tx), model.AuditEntityWebhook, webhookID, model.AuditActionDelete, withoutSecret(before), nil); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+
+	webhookLog.InfoContext(ctx, "webhook deleted", "webhook_id", webhookID)
+	return nil
+}
+
+// GetDeliveries retrieves the most recent deliveries of a webhook, newest first.
+// Returns ErrWebhookNotFound if the webhook does not exist.
+func (s *WebhookService) GetDeliveries(webhookID string, limit int) ([]model.WebhookDelivery, error) {
+	if _, err := s.webhookRepo.GetWebhook(webhookID); err != nil {
+		return nil, err
+	}
+	deliveries, err := s.webhookRepo.GetDeliveries(webhookID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("get deliveries: %w", err)
+	}
+	return deliveries, nil
+}
+
+// TestWebhook sends a webhook.test event to a webhook right away, whether or not it is
+// enabled, and returns the delivery with the outcome of the first attempt. A failed test
+// is retried like any other delivery. Returns ErrWebhookNotFound if the webhook does not exist.
+func (s *WebhookService) TestWebhook(ctx context.Context, webhookID string) (model.WebhookDelivery, error) {
+	ctx, span := tracing.Start(ctx, "WebhookService.TestWebhook")
+	defer span.End()
+
+	w, err := s.webhookRepo.GetWebhook(webhookID)
+	if err != nil {
+		return model.WebhookDelivery{}, err
+	}
+	// Queued without a due time so the worker does not pick it up while it is sent here.
+	d, _, err := s.queue(ctx, w.ID, model.WebhookEventTest, "", map[string]string{
+		"message": "Test event from Investment Portfolio Manager",
+	}, nil)
+	if err != nil {
+		return model.WebhookDelivery{}, err
+	}
+	s.deliver(ctx, w, d)
+	return s.webhookRepo.GetDelivery(d.ID)
+}
+
+// Publish implements EventPublisher by queueing a delivery for every enabled webhook
+// subscribed to event.
+func (s *WebhookService) Publish(ctx context.Context, event model.WebhookEvent, key string, data any) {
+	webhooks, err := s.webhookRepo.GetWebhooks()
+	if err != nil {
+		webhookLog.WarnContext(ctx, "failed to load webhooks", "event", event, "error", err)
+		return
+	}
+
+	now := time.Now().UTC()
+	queued := 0
+	for _, w := range webhooks {
+		if !w.Enabled || !w.Subscribes(event) {
+			continue
+		}
+		_, inserted, err := s.queue(ctx, w.ID, event, key, data, &now)
+		if err != nil {
+			webhookLog.WarnContext(ctx, "failed to queue webhook delivery", "webhook_id", w.ID, "event", event, "error", err)
+			continue
+		}
+		if inserted {
+			queued++
+		}
+	}
+	if queued == 0 {
+		return
+	}
+
+	webhookLog.DebugContext(ctx, "webhook event queued", "event", event, "deliveries", queued)
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// queue stores a delivery of event to one webhook, due at dueAt (nil for not scheduled).
+// The returned boolean is false when the event key was already delivered to the webhook.
+func (s *WebhookService) queue(ctx context.Context, webhookID string, event model.WebhookEvent, key string, data any, dueAt *time.Time) (model.WebhookDelivery, bool, error) {
+	d := model.WebhookDelivery{
+		ID:            uuid.New().String(),
+		WebhookID:     webhookID,
+		EventType:     event,
+		Status:        model.WebhookDeliveryPending,
+		CreatedAt:     time.Now().UTC(),
+		NextAttemptAt: dueAt,
+	}
+	if key != "" {
+		d.EventKey = string(event) + ":" + key
+	}
+	payload, err := json.Marshal(model.WebhookPayload{
+		ID:        d.ID,
+		Event:     event,
+		CreatedAt: d.CreatedAt,
+		Data:      data,
+	})
+	if err != nil {
+		return model.WebhookDelivery{}, false, fmt.Errorf("encode payload: %w", err)
+	}
+	d.Payload = payload
+
+	inserted, err := s.webhookRepo.InsertDelivery(ctx, &d)
+	if err != nil {
+		return model.WebhookDelivery{}, false, err
+	}
+	return d, inserted, nil
+}
+
+// RunDeliveryWorker sends queued deliveries until ctx is cancelled. It wakes whenever an
+// event is queued, and every webhookPollInterval for retries that have become due.
+func (s *WebhookService) RunDeliveryWorker(ctx context.Context) {
+	webhookLog.Info("webhooks: delivery worker started")
+	ticker := time.NewTicker(webhookPollInterval)
+	defer ticker.Stop()
+
+	var lastPurge time.Time
+	for {
+		s.drainDeliveries(ctx)
+		if time.Since(lastPurge) >= webhookPurgeInterval {
+			if _, err := s.webhookRepo.DeleteFinishedDeliveries(ctx, time.Now().Add(-webhookDeliveryRetention)); err != nil {
+				webhookLog.Warn("webhooks: failed to purge delivery log", "error", err)
+			}
+			lastPurge = time.Now()
+		}
+		select {
+		case <-ctx.Done():
+			webhookLog.Info("webhooks: delivery worker stopped")
+			return
+		case <-s.wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+// drainDeliveries sends due deliveries one at a time until none are due.
+func (s *WebhookService) drainDeliveries(ctx context.Context) {
+	for ctx.Err() == nil {
+		d, ok, err := s.webhookRepo.GetDueDelivery(time.Now().UTC())
+		if err != nil {
+			webhookLog.Warn("webhooks: failed to read delivery queue", "error", err)
+			return
+		}
+		if !ok {
+			return
+		}
+		w, err := s.webhookRepo.GetWebhook(d.WebhookID)
+		if err != nil {
+			webhookLog.Warn("webhooks: failed to load webhook", "webhook_id", d.WebhookID, "error", err)
+			return
+		}
+		if !s.deliver(ctx, w, d) {
+			return
+		}
+	}
+}
+
+// deliver POSTs a delivery to its webhook once and records the outcome: succeeded on a 2xx
+// response, failed once the attempts run out, otherwise pending with the next retry time.
+// Returns false if the outcome could not be recorded.
+func (s *WebhookService) deliver(ctx context.Context, w model.Webhook, d model.WebhookDelivery) bool {
+	ctx, span := tracing.Start(ctx, "WebhookService.deliver",
+		attribute.String("webhook.id", w.ID),
+		attribute.String("webhook.event", string(d.EventType)),
+	)
+	status, err := s.post(ctx, w, d)
+	tracing.End(span, err)
+
+	now := time.Now().UTC()
+	attempts := d.Attempts + 1
+	var recordErr error
+	switch {
+	case err == nil:
+		webhookLog.Debug("webhooks: delivered", "webhook_id", w.ID, "event", d.EventType, "delivery_id", d.ID)
+		recordErr = s.webhookRepo.RecordDeliveryAttempt(ctx, d.ID, model.WebhookDeliverySucceeded, status, "", nil, &now)
+	case attempts >= s.cfg.MaxAttempts:
+		webhookLog.Warn("webhooks: delivery failed", "webhook_id", w.ID, "event", d.EventType, "delivery_id", d.ID, "attempts", attempts, "error", err)
+		recordErr = s.webhookRepo.RecordDeliveryAttempt(ctx, d.ID, model.WebhookDeliveryFailed, status, err.Error(), nil, nil)
+	default:
+		backoff := min(time.Duration(s.cfg.InitialBackoff)<<min(attempts-1, 16), time.Duration(s.cfg.MaxBackoff))
+		next := now.Add(backoff)
+		webhookLog.Info("webhooks: delivery attempt failed", "webhook_id", w.ID, "event", d.EventType, "delivery_id", d.ID, "retryIn", backoff, "error", err)
+		recordErr = s.webhookRepo.RecordDeliveryAttempt(ctx, d.ID, model.WebhookDeliveryPending, status, err.Error(), &next, nil)
+	}
+	if recordErr != nil {
+		webhookLog.Warn("webhooks: failed to record delivery attempt", "delivery_id", d.ID, "error", recordErr)
+		return false
+	}
+	return true
+}
+
+// post sends the signed payload and returns the response status (0 when there was no response).
+func (s *WebhookService) post(ctx context.Context, w model.Webhook, d model.WebhookDelivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(d.Payload))
+	if err != nil {
+		return 0, fmt.Errorf("build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-IPM-Event", string(d.EventType))
+	req.Header.Set("X-IPM-Delivery", d.ID)
+	req.Header.Set(webhookSignatureHeader, SignWebhookPayload(w.Secret, d.Payload))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10)) //nolint:errcheck // Drained only so the connection can be reused.
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("unexpected response status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// SignWebhookPayload returns the X-IPM-Signature header value for body: "sha256=" followed
+// by the hex-encoded HMAC-SHA256 of body keyed with secret.
+func SignWebhookPayload(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func toWebhookEvents(events []string) []model.WebhookEvent {
+	out := make([]model.WebhookEvent, len(events))
+	for i, e := range events {
+		out[i] = model.WebhookEvent(e)
+	}
+	return out
+}
+
+// withoutSecret returns w with its secret removed, for audit snapshots.
+func withoutSecret(w model.Webhook) model.Webhook {
+	w.Secret = ""
+	return w
+}
@@ -0,0 +1,417 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/api/request"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/apperrors"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/config"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/model"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/service"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/testutil"
+)
+
+// recordingPublisher is an EventPublisher that records the published events.
+type recordingPublisher struct {
+	mu     sync.Mutex
+	events []publishedEvent
+}
+
+type publishedEvent struct {
+	Event model.WebhookEvent
+	Key   string
+	Data  any
+}
+
+func (p *recordingPublisher) Publish(_ context.Context, event model.WebhookEvent, key string, data any) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.events = append(p.events, publishedEvent{Event: event, Key: key, Data: data})
+}
+
+func (p *recordingPublisher) find(event model.WebhookEvent) (publishedEvent, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, e := range p.events {
+		if e.Event == event {
+			return e, true
+		}
+	}
+	return publishedEvent{}, false
+}
+
+// webhookReceiver is an HTTP endpoint that answers with the queued statuses, then 200,
+// and records the requests it receives.
+type webhookReceiver struct {
+	*httptest.Server
+	mu       sync.Mutex
+	statuses []int
+	requests []receivedWebhook
+}
+
+type receivedWebhook struct {
+	Header http.Header
+	Body   []byte
+}
+
+func newWebhookReceiver(t *testing.T, statuses ...int) *webhookReceiver {
+	t.Helper()
+	rcv := &webhookReceiver{statuses: statuses}
+	rcv.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body) //nolint:errcheck // Test receiver; an unreadable body fails the assertions.
+		rcv.mu.Lock()
+		defer rcv.mu.Unlock()
+		rcv.requests = append(rcv.requests, receivedWebhook{Header: r.Header.Clone(), Body: body})
+		status := http.StatusOK
+		if len(rcv.statuses) > 0 {
+			status, rcv.statuses = rcv.statuses[0], rcv.statuses[1:]
+		}
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(rcv.Close)
+	return rcv
+}
+
+func (rcv *webhookReceiver) received() []receivedWebhook {
+	rcv.mu.Lock()
+	defer rcv.mu.Unlock()
+	return append([]receivedWebhook(nil), rcv.requests...)
+}
+
+func testWebhooksConfig() config.WebhooksConfig {
+	cfg := config.Defaults().Webhooks
+	cfg.MaxAttempts = 3
+	cfg.InitialBackoff = config.Duration(time.Millisecond)
+	cfg.MaxBackoff = config.Duration(time.Millisecond)
+	return cfg
+}
+
+func createTestWebhook(t *testing.T, svc *service.WebhookService, url string, events ...model.WebhookEvent) model.Webhook {
+	t.Helper()
+	eventTypes := make([]string, len(events))
+	for i, e := range events {
+		eventTypes[i] = string(e)
+	}
+	w, err := svc.CreateWebhook(context.Background(), request.CreateWebhookRequest{URL: url, EventTypes: eventTypes})
+	if err != nil {
+		t.Fatalf("CreateWebhook: %v", err)
+	}
+	return w
+}
+
+//nolint:gocyclo // Test function with multiple subtests and assertions.
+func TestWebhookService_CRUD(t *testing.T) {
+	t.Run("secret is generated and only returned when set", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		svc := testutil.NewTestWebhookService(t, db, testWebhooksConfig())
+		ctx := context.Background()
+
+		w := createTestWebhook(t, svc, "https://example.com/hook", model.WebhookEventIbkrInboxItems)
+		if w.Secret == "" || !w.Enabled {
+			t.Fatalf("expected an enabled webhook with a generated secret, got %+v", w)
+		}
+
+		got, err := svc.GetWebhook(w.ID)
+		if err != nil {
+			t.Fatalf("GetWebhook: %v", err)
+		}
+		if got.Secret != "" {
+			t.Error("expected GetWebhook to omit the secret")
+		}
+
+		disabled := false
+		updated, err := svc.UpdateWebhook(ctx, w.ID, request.UpdateWebhookRequest{Enabled: &disabled})
+		if err != nil {
+			t.Fatalf("UpdateWebhook: %v", err)
+		}
+		if updated.Enabled || updated.Secret != "" || updated.URL != w.URL {
+			t.Errorf("expected a disabled webhook without secret, got %+v", updated)
+		}
+
+		empty := ""
+		updated, err = svc.UpdateWebhook(ctx, w.ID, request.UpdateWebhookRequest{Secret: &empty})
+		if err != nil {
+			t.Fatalf("UpdateWebhook: %v", err)
+		}
+		if updated.Secret == "" || updated.Secret == w.Secret {
+			t.Errorf("expected a newly generated secret, got %q", updated.Secret)
+		}
+
+		if err := svc.DeleteWebhook(ctx, w.ID); err != nil {
+			t.Fatalf("DeleteWebhook: %v", err)
+		}
+		if _, err := svc.GetWebhook(w.ID); !errors.Is(err, apperrors.ErrWebhookNotFound) {
+			t.Errorf("expected ErrWebhookNotFound, got %v", err)
+		}
+
+		if n := countRows(t, db, "audit_event", "entity_type = ? AND entity_id = ?", model.AuditEntityWebhook, w.ID); n != 4 {
+			t.Errorf("expected 4 audit entries, got %d", n)
+		}
+		for _, secret := range []string{w.Secret, updated.Secret} {
+			if n := countRows(t, db, "audit_event", "before_state LIKE ? OR after_state LIKE ?", "%"+secret+"%", "%"+secret+"%"); n != 0 {
+				t.Errorf("expected no secret in the audit log, found it in %d entries", n)
+			}
+		}
+	})
+
+	t.Run("unknown webhook", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		svc := testutil.NewTestWebhookService(t, db, testWebhooksConfig())
+		id := testutil.MakeID()
+
+		if _, err := svc.UpdateWebhook(context.Background(), id, request.UpdateWebhookRequest{}); !errors.Is(err, apperrors.ErrWebhookNotFound) {
+			t.Errorf("expected ErrWebhookNotFound from UpdateWebhook, got %v", err)
+		}
+		if err := svc.DeleteWebhook(context.Background(), id); !errors.Is(err, apperrors.ErrWebhookNotFound) {
+			t.Errorf("expected ErrWebhookNotFound from DeleteWebhook, got %v", err)
+		}
+		if _, err := svc.GetDeliveries(id, 50); !errors.Is(err, apperrors.ErrWebhookNotFound) {
+			t.Errorf("expected ErrWebhookNotFound from GetDeliveries, got %v", err)
+		}
+	})
+}
+
+//nolint:gocyclo // Test function with multiple subtests and assertions.
+func TestWebhookService_Publish(t *testing.T) {
+	t.Run("delivers signed events to enabled subscribers", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		svc := testutil.NewTestWebhookService(t, db, testWebhooksConfig())
+		rcv := newWebhookReceiver(t)
+		ctx := context.Background()
+
+		w := createTestWebhook(t, svc, rcv.URL, model.WebhookEventIbkrInboxItems)
+		createTestWebhook(t, svc, rcv.URL, model.WebhookEventPriceUpdateFailed)
+		off := createTestWebhook(t, svc, rcv.URL, model.WebhookEventIbkrInboxItems)
+		disabled := false
+		if _, err := svc.UpdateWebhook(ctx, off.ID, request.UpdateWebhookRequest{Enabled: &disabled}); err != nil {
+			t.Fatalf("UpdateWebhook: %v", err)
+		}
+
+		svc.Publish(ctx, model.WebhookEventIbkrInboxItems, "", map[string]int{"count": 3})
+		svc.ExportDrainDeliveries(ctx)
+
+		received := rcv.received()
+		if len(received) != 1 {
+			t.Fatalf("expected 1 request, got %d", len(received))
+		}
+		req := received[0]
+		if got, want := req.Header.Get("X-IPM-Signature"), service.SignWebhookPayload(w.Secret, req.Body); got != want {
+			t.Errorf("expected signature %s, got %s", want, got)
+		}
+		if req.Header.Get("X-IPM-Event") != string(model.WebhookEventIbkrInboxItems) || req.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("unexpected headers: %v", req.Header)
+		}
+
+		var payload struct {
+			ID    string             `json:"id"`
+			Event model.WebhookEvent `json:"event"`
+			Data  map[string]int     `json:"data"`
+		}
+		if err := json.Unmarshal(req.Body, &payload); err != nil {
+			t.Fatalf("decode payload: %v", err)
+		}
+		if payload.Event != model.WebhookEventIbkrInboxItems || payload.Data["count"] != 3 || payload.ID != req.Header.Get("X-IPM-Delivery") {
+			t.Errorf("unexpected payload %s", req.Body)
+		}
+
+		deliveries, err := svc.GetDeliveries(w.ID, 50)
+		if err != nil {
+			t.Fatalf("GetDeliveries: %v", err)
+		}
+		if len(deliveries) != 1 || deliveries[0].Status != model.WebhookDeliverySucceeded || deliveries[0].Attempts != 1 || deliveries[0].DeliveredAt == nil {
+			t.Errorf("expected one succeeded delivery, got %+v", deliveries)
+		}
+	})
+
+	t.Run("event key is delivered once", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		svc := testutil.NewTestWebhookService(t, db, testWebhooksConfig())
+		rcv := newWebhookReceiver(t)
+		ctx := context.Background()
+		createTestWebhook(t, svc, rcv.URL, model.WebhookEventIbkrTokenExpiring)
+
+		for range 2 {
+			svc.Publish(ctx, model.WebhookEventIbkrTokenExpiring, "2026-10-18", map[string]string{"warning": "Token expires in 5 days"})
+		}
+		svc.ExportDrainDeliveries(ctx)
+
+		if n := len(rcv.received()); n != 1 {
+			t.Errorf("expected 1 request, got %d", n)
+		}
+	})
+
+	t.Run("retries until the endpoint succeeds", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		svc := testutil.NewTestWebhookService(t, db, testWebhooksConfig())
+		rcv := newWebhookReceiver(t, http.StatusServiceUnavailable)
+		ctx := context.Background()
+		w := createTestWebhook(t, svc, rcv.URL, model.WebhookEventPriceUpdateFailed)
+
+		svc.Publish(ctx, model.WebhookEventPriceUpdateFailed, "", nil)
+		svc.ExportDrainDeliveries(ctx)
+
+		received := rcv.received()
+		if len(received) != 2 || received[0].Header.Get("X-IPM-Delivery") != received[1].Header.Get("X-IPM-Delivery") {
+			t.Fatalf("expected 2 attempts of the same delivery, got %d", len(received))
+		}
+		deliveries, _ := svc.GetDeliveries(w.ID, 50) //nolint:errcheck // Checked through the assertion below.
+		if len(deliveries) != 1 || deliveries[0].Status != model.WebhookDeliverySucceeded || deliveries[0].Attempts != 2 {
+			t.Errorf("expected a delivery that succeeded on the second attempt, got %+v", deliveries)
+		}
+	})
+
+	t.Run("fails after the last attempt", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		svc := testutil.NewTestWebhookService(t, db, testWebhooksConfig())
+		rcv := newWebhookReceiver(t, http.StatusInternalServerError, http.StatusInternalServerError, http.StatusInternalServerError, http.StatusInternalServerError)
+		ctx := context.Background()
+		w := createTestWebhook(t, svc, rcv.URL, model.WebhookEventPriceUpdateFailed)
+
+		svc.Publish(ctx, model.WebhookEventPriceUpdateFailed, "", nil)
+		svc.ExportDrainDeliveries(ctx)
+
+		if n := len(rcv.received()); n != 3 {
+			t.Errorf("expected 3 attempts, got %d", n)
+		}
+		deliveries, _ := svc.GetDeliveries(w.ID, 50) //nolint:errcheck // Checked through the assertion below.
+		if len(deliveries) != 1 {
+			t.Fatalf("expected 1 delivery, got %d", len(deliveries))
+		}
+		d := deliveries[0]
+		if d.Status != model.WebhookDeliveryFailed || d.Attempts != 3 || d.ResponseStatus == nil || *d.ResponseStatus != http.StatusInternalServerError ||
+			d.LastError == "" || d.NextAttemptAt != nil {
+			t.Errorf("expected a failed delivery after 3 attempts, got %+v", d)
+		}
+	})
+}
+
+func TestWebhookService_TestWebhook(t *testing.T) {
+	t.Run("sends a test event to a disabled webhook", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		svc := testutil.NewTestWebhookService(t, db, testWebhooksConfig())
+		rcv := newWebhookReceiver(t)
+		w := createTestWebhook(t, svc, rcv.URL, model.WebhookEventIbkrInboxItems)
+		disabled := false
+		if _, err := svc.UpdateWebhook(context.Background(), w.ID, request.UpdateWebhookRequest{Enabled: &disabled}); err != nil {
+			t.Fatalf("UpdateWebhook: %v", err)
+		}
+
+		d, err := svc.TestWebhook(context.Background(), w.ID)
+		if err != nil {
+			t.Fatalf("TestWebhook: %v", err)
+		}
+		if d.EventType != model.WebhookEventTest || d.Status != model.WebhookDeliverySucceeded || d.Attempts != 1 {
+			t.Errorf("expected a succeeded test delivery, got %+v", d)
+		}
+		if n := len(rcv.received()); n != 1 {
+			t.Errorf("expected 1 request, got %d", n)
+		}
+	})
+
+	t.Run("failed test is retried by the worker", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		svc := testutil.NewTestWebhookService(t, db, testWebhooksConfig())
+		rcv := newWebhookReceiver(t, http.StatusBadGateway)
+		w := createTestWebhook(t, svc, rcv.URL, model.WebhookEventIbkrInboxItems)
+
+		d, err := svc.TestWebhook(context.Background(), w.ID)
+		if err != nil {
+			t.Fatalf("TestWebhook: %v", err)
+		}
+		if d.Status != model.WebhookDeliveryPending || d.ResponseStatus == nil || *d.ResponseStatus != http.StatusBadGateway || d.NextAttemptAt == nil {
+			t.Fatalf("expected a pending delivery awaiting retry, got %+v", d)
+		}
+
+		time.Sleep(5 * time.Millisecond)
+		svc.ExportDrainDeliveries(context.Background())
+		if n := len(rcv.received()); n != 2 {
+			t.Errorf("expected the retry to be sent, got %d requests", n)
+		}
+	})
+
+	t.Run("unknown webhook", func(t *testing.T) {
+		db := testutil.SetupTestDB(t)
+		svc := testutil.NewTestWebhookService(t, db, testWebhooksConfig())
+
+		if _, err := svc.TestWebhook(context.Background(), testutil.MakeID()); !errors.Is(err, apperrors.ErrWebhookNotFound) {
+			t.Errorf("expected ErrWebhookNotFound, got %v", err)
+		}
+	})
+}
+
+func TestMaterializedService_CheckValueDrop(t *testing.T) {
+	setup := func(t *testing.T, prevGain, lastGain float64) *recordingPublisher {
+		t.Helper()
+		db := testutil.SetupTestDB(t)
+		svc := testutil.NewTestMaterializedService(t, db)
+		publisher := &recordingPublisher{}
+		svc.SetEventPublisher(publisher, 5)
+
+		portfolio := testutil.NewPortfolio().Build(t, db)
+		today := time.Now().UTC().Truncate(24 * time.Hour)
+		for _, snap := range []struct {
+			date time.Time
+			gain float64
+		}{{today.AddDate(0, 0, -1), prevGain}, {today, lastGain}} {
+			if _, err := db.Exec(`
+				INSERT INTO portfolio_history_materialized
+				(portfolio_id, date, value, cost, realized_gain, unrealized_gain, total_gain_loss, total_dividends, total_sale_proceeds, total_original_cost, calculated_at)
+				VALUES (?, ?, ?, 1000, 0, ?, ?, 0, 0, 0, ?)
+			`, portfolio.ID, snap.date.Format("2006-01-02"), 1000+snap.gain, snap.gain, snap.gain, time.Now().UTC().Format("2006-01-02 15:04:05")); err != nil {
+				t.Fatalf("insert snapshot: %v", err)
+			}
+		}
+
+		svc.ExportCheckValueDrop(context.Background(), portfolio.ID)
+		return publisher
+	}
+
+	t.Run("publishes a drop beyond the threshold", func(t *testing.T) {
+		// 1100 -> 1000 is a 9.1% loss.
+		publisher := setup(t, 100, 0)
+		e, ok := publisher.find(model.WebhookEventPortfolioValueDrop)
+		if !ok {
+			t.Fatal("expected a portfolio.value_drop event")
+		}
+		if data, _ := e.Data.(map[string]any); data["change"] != -100.0 || e.Key == "" {
+			t.Errorf("unexpected event %+v", e)
+		}
+	})
+
+	t.Run("ignores a drop below the threshold", func(t *testing.T) {
+		// 1100 -> 1060 is a 3.6% loss.
+		publisher := setup(t, 100, 60)
+		if _, ok := publisher.find(model.WebhookEventPortfolioValueDrop); ok {
+			t.Error("expected no event")
+		}
+	})
+}
+
+func TestFundService_UpdateAllFundHistory_PublishesFailure(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	svc := testutil.NewTestFundServiceWithMockYahoo(t, db, testutil.NewMockYahooClient())
+	publisher := &recordingPublisher{}
+	svc.SetEventPublisher(publisher)
+
+	// Fund with no portfolio funds means UpdateHistoricalFundPrice errors
+	testutil.NewFund().WithSymbol("TEST1").Build(t, db)
+
+	if _, err := svc.UpdateAllFundHistory(context.Background()); err == nil {
+		t.Fatal("expected error when all funds fail")
+	}
+	e, ok := publisher.find(model.WebhookEventPriceUpdateFailed)
+	if !ok {
+		t.Fatal("expected a price_update.failed event")
+	}
+	if data, _ := e.Data.(map[string]any); data["totalErrors"] != 1 {
+		t.Errorf("unexpected event data %+v", e.Data)
+	}
+}
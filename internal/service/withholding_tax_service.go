@@ -0,0 +1,210 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/api/request"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/apperrors"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/auth"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/model"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/repository"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/tracing"
+)
+
+// WithholdingTaxService manages the default withholding tax rates per fund domicile and
+// reports the tax withheld on dividends.
+type WithholdingTaxService struct {
+	db              *sql.DB
+	withholdingRepo *repository.WithholdingTaxRepository
+	auditRepo       *repository.AuditRepository
+}
+
+// NewWithholdingTaxService creates a new WithholdingTaxService with the provided dependencies.
+func NewWithholdingTaxService(
+	db *sql.DB,
+	withholdingRepo *repository.WithholdingTaxRepository,
+	auditRepo *repository.AuditRepository,
+) *WithholdingTaxService {
+	return &WithholdingTaxService{
+		db:              db,
+		withholdingRepo: withholdingRepo,
+		auditRepo:       auditRepo,
+	}
+}
+
+// GetWithholdingTaxRates returns every default withholding tax rate, ordered by country.
+func (s *WithholdingTaxService) GetWithholdingTaxRates() ([]model.WithholdingTaxRate, error) {
+	return s.withholdingRepo.GetWithholdingTaxRates()
+}
+
+// SetWithholdingTaxRate creates or replaces the default withholding tax rate of a country.
+// The rate applies to dividends created afterwards; existing dividends are not changed.
+func (s *WithholdingTaxService) SetWithholdingTaxRate(ctx context.Context, country string, req request.SetWithholdingTaxRateRequest) (model.WithholdingTaxRate, error) {
+	ctx, span := tracing.Start(ctx, "WithholdingTaxService.SetWithholdingTaxRate")
+	defer span.End()
+
+	before, err := s.withholdingTaxRate(country)
+	if err != nil {
+		return model.WithholdingTaxRate{}, err
+	}
+	rate := model.WithholdingTaxRate{Country: country, RatePercent: req.RatePercent, UpdatedAt: time.Now().UTC()}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return model.WithholdingTaxRate{}, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }() //nolint:errcheck // Rollback is a no-op after Commit; error is intentionally ignored.
+
+	if err := s.withholdingRepo.WithTx(tx).UpsertWithholdingTaxRate(ctx, rate); err != nil {
+		return model.WithholdingTaxRate{}, err
+	}
+	if err := recordAuditUpsert(ctx, s.auditRepo.WithTx(tx), model.AuditEntityWithholdingTax, country, before, rate); err != nil {
+		return model.WithholdingTaxRate{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return model.WithholdingTaxRate{}, fmt.Errorf("commit transaction: %w", err)
+	}
+
+	divLog.InfoContext(ctx, "withholding tax rate set", "country", country, "ratePercent", rate.RatePercent)
+	return rate, nil
+}
+
+// DeleteWithholdingTaxRate removes the default withholding tax rate of a country, so new
+// dividends of funds domiciled there have no tax withheld by default.
+// Returns ErrWithholdingTaxRateNotFound if the country has no rate.
+func (s *WithholdingTaxService) DeleteWithholdingTaxRate(ctx context.Context, country string) error {
+	ctx, span := tracing.Start(ctx, "WithholdingTaxService.DeleteWithholdingTaxRate")
+	defer span.End()
+
+	before, err := s.withholdingTaxRate(country)
+	if err != nil {
+		return err
+	}
+	if before == nil {
+		return apperrors.ErrWithholdingTaxRateNotFound
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }() //nolint:errcheck // Rollback is a no-op after Commit; error is intentionally ignored.
+
+	if err := s.withholdingRepo.WithTx(tx).DeleteWithholdingTaxRate(ctx, country); err != nil {
+		return err
+	}
+	if err := recordAudit(ctx, s.auditRepo.WithTx(tx), model.AuditEntityWithholdingTax, country, model.AuditActionDelete, before, nil); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+
+	divLog.InfoContext(ctx, "withholding tax rate deleted", "country", country)
+	return nil
+}
+
+// withholdingTaxRate returns the default withholding tax rate of a country, or nil if it has none.
+func (s *WithholdingTaxService) withholdingTaxRate(country string) (*model.WithholdingTaxRate, error) {
+	rates, err := s.withholdingRepo.GetWithholdingTaxRates()
+	if err != nil {
+		return nil, err
+	}
+	if i := slices.IndexFunc(rates, func(r model.WithholdingTaxRate) bool { return r.Country == country }); i >= 0 {
+		return &rates[i], nil
+	}
+	return nil, nil
+}
+
+// GetWithholdingTaxReport reports the tax withheld on the dividends with an ex-dividend date
+// in year, grouped by fund domicile (the country code of the fund's ISIN, or empty when the
+// fund has none) and currency. Only the portfolios the caller in ctx can read are included;
+// a non-empty portfolioID limits the report to that portfolio.
+//
+// Reclaimable is, per dividend, the tax withheld above the domicile's current default rate.
+func (s *WithholdingTaxService) GetWithholdingTaxReport(ctx context.Context, year int, portfolioID string) (report model.WithholdingTaxReport, err error) {
+	ctx, span := tracing.Start(ctx, "WithholdingTaxService.GetWithholdingTaxReport")
+	defer func() { tracing.End(span, err) }()
+
+	dividends, err := s.withholdingRepo.GetWithholdingTaxDividends(year, auth.Scope(ctx))
+	if err != nil {
+		return model.WithholdingTaxReport{}, err
+	}
+	if portfolioID != "" {
+		dividends = slices.DeleteFunc(dividends, func(d model.WithholdingTaxDividend) bool { return d.PortfolioID != portfolioID })
+	}
+
+	rates, err := s.withholdingRepo.GetWithholdingTaxRates()
+	if err != nil {
+		return model.WithholdingTaxReport{}, err
+	}
+	defaultRates := make(map[string]float64, len(rates))
+	for _, r := range rates {
+		defaultRates[r.Country] = r.RatePercent
+	}
+
+	countries := make(map[string]*model.WithholdingTaxCountry)
+	funds := make(map[string]*model.WithholdingTaxFund)
+	for _, d := range dividends {
+		country := isinCountry(d.ISIN)
+		key := country + "|" + d.Currency
+		c, ok := countries[key]
+		if !ok {
+			c = &model.WithholdingTaxCountry{Country: country, Currency: d.Currency, DefaultRate: defaultRates[country]}
+			countries[key] = c
+		}
+		c.GrossAmount += d.GrossAmount
+		c.WithholdingTax += d.WithholdingTax
+		c.Reclaimable += max(0, d.WithholdingTax-d.GrossAmount*c.DefaultRate/100)
+
+		f, ok := funds[key+"|"+d.FundID]
+		if !ok {
+			f = &model.WithholdingTaxFund{FundID: d.FundID, FundName: d.FundName, ISIN: d.ISIN}
+			funds[key+"|"+d.FundID] = f
+		}
+		f.Dividends++
+		f.GrossAmount += d.GrossAmount
+		f.WithholdingTax += d.WithholdingTax
+	}
+
+	for key, f := range funds {
+		f.GrossAmount = round(f.GrossAmount)
+		f.WithholdingTax = round(f.WithholdingTax)
+		f.NetAmount = round(f.GrossAmount - f.WithholdingTax)
+		c := countries[key[:strings.LastIndex(key, "|")]]
+		c.Funds = append(c.Funds, *f)
+	}
+
+	report = model.WithholdingTaxReport{Year: year, Countries: make([]model.WithholdingTaxCountry, 0, len(countries))}
+	for _, c := range countries {
+		c.GrossAmount = round(c.GrossAmount)
+		c.WithholdingTax = round(c.WithholdingTax)
+		c.NetAmount = round(c.GrossAmount - c.WithholdingTax)
+		c.Reclaimable = round(c.Reclaimable)
+		if c.GrossAmount > 0 {
+			c.EffectiveRate = round(c.WithholdingTax / c.GrossAmount * 100)
+		}
+		slices.SortFunc(c.Funds, func(a, b model.WithholdingTaxFund) int { return strings.Compare(a.FundName, b.FundName) })
+		report.Countries = append(report.Countries, *c)
+	}
+	slices.SortFunc(report.Countries, func(a, b model.WithholdingTaxCountry) int {
+		if n := strings.Compare(a.Country, b.Country); n != 0 {
+			return n
+		}
+		return strings.Compare(a.Currency, b.Currency)
+	})
+	return report, nil
+}
+
+// isinCountry returns the country code of an ISIN, or an empty string if it has none.
+func isinCountry(isin string) string {
+	if len(isin) < 2 {
+		return ""
+	}
+	return strings.ToUpper(isin[:2])
+}
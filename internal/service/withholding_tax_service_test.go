@@ -0,0 +1,119 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/api/request"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/apperrors"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/auth"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/model"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/testutil"
+)
+
+func TestWithholdingTaxService_Rates(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	svc := testutil.NewTestWithholdingTaxService(t, db)
+	ctx := context.Background()
+
+	if _, err := svc.SetWithholdingTaxRate(ctx, "US", request.SetWithholdingTaxRateRequest{RatePercent: 30}); err != nil {
+		t.Fatalf("SetWithholdingTaxRate() error: %v", err)
+	}
+	rate, err := svc.SetWithholdingTaxRate(ctx, "US", request.SetWithholdingTaxRateRequest{RatePercent: 15})
+	if err != nil {
+		t.Fatalf("SetWithholdingTaxRate() error: %v", err)
+	}
+	if rate.Country != "US" || rate.RatePercent != 15 {
+		t.Errorf("unexpected rate %+v", rate)
+	}
+
+	rates, err := svc.GetWithholdingTaxRates()
+	if err != nil {
+		t.Fatalf("GetWithholdingTaxRates() error: %v", err)
+	}
+	if len(rates) != 1 || rates[0].RatePercent != 15 {
+		t.Errorf("expected one US rate of 15, got %+v", rates)
+	}
+	if n := countRows(t, db, "audit_event", "entity_type = ? AND entity_id = ?", string(model.AuditEntityWithholdingTax), "US"); n != 2 {
+		t.Errorf("expected 2 audit events, got %d", n)
+	}
+
+	if err := svc.DeleteWithholdingTaxRate(ctx, "US"); err != nil {
+		t.Fatalf("DeleteWithholdingTaxRate() error: %v", err)
+	}
+	if err := svc.DeleteWithholdingTaxRate(ctx, "US"); !errors.Is(err, apperrors.ErrWithholdingTaxRateNotFound) {
+		t.Errorf("expected ErrWithholdingTaxRateNotFound, got %v", err)
+	}
+}
+
+func TestWithholdingTaxService_GetWithholdingTaxReport(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	svc := testutil.NewTestWithholdingTaxService(t, db)
+	ctx := context.Background()
+
+	if _, err := svc.SetWithholdingTaxRate(ctx, "US", request.SetWithholdingTaxRateRequest{RatePercent: 15}); err != nil {
+		t.Fatalf("SetWithholdingTaxRate() error: %v", err)
+	}
+
+	portfolio := testutil.NewPortfolio().Build(t, db)
+	other := testutil.NewPortfolio().Build(t, db)
+	usFund := testutil.NewFund().WithName("US Equity").WithISIN("US0378331005").Build(t, db)
+	ieFund := testutil.NewFund().WithName("World ETF").WithISIN("IE00B4L5Y983").WithCurrency("EUR").Build(t, db)
+	usPF := testutil.NewPortfolioFund(portfolio.ID, usFund.ID).Build(t, db)
+	iePF := testutil.NewPortfolioFund(portfolio.ID, ieFund.ID).Build(t, db)
+	otherPF := testutil.NewPortfolioFund(other.ID, usFund.ID).Build(t, db)
+
+	date := func(m time.Month) time.Time { return time.Date(2025, m, 10, 0, 0, 0, 0, time.UTC) }
+	// 50 gross each: one at the 15% treaty rate, one at the 30% statutory rate.
+	testutil.NewDividend(usFund.ID, usPF.ID).WithExDividendDate(date(3)).WithWithholdingTax(7.5, model.WithholdingSourceIBKR).Build(t, db)
+	testutil.NewDividend(usFund.ID, usPF.ID).WithExDividendDate(date(6)).WithWithholdingTax(15, model.WithholdingSourceIBKR).Build(t, db)
+	testutil.NewDividend(ieFund.ID, iePF.ID).WithExDividendDate(date(9)).Build(t, db)
+	testutil.NewDividend(usFund.ID, otherPF.ID).WithExDividendDate(date(9)).WithWithholdingTax(7.5, model.WithholdingSourceDefault).Build(t, db)
+	testutil.NewDividend(usFund.ID, usPF.ID).WithExDividendDate(time.Date(2024, 12, 10, 0, 0, 0, 0, time.UTC)).WithWithholdingTax(7.5, model.WithholdingSourceDefault).Build(t, db)
+
+	t.Run("groups one portfolio by domicile and currency", func(t *testing.T) {
+		report, err := svc.GetWithholdingTaxReport(ctx, 2025, portfolio.ID)
+		if err != nil {
+			t.Fatalf("GetWithholdingTaxReport() error: %v", err)
+		}
+		if report.Year != 2025 || len(report.Countries) != 2 {
+			t.Fatalf("expected 2 countries for 2025, got %+v", report)
+		}
+
+		ie, us := report.Countries[0], report.Countries[1]
+		if ie.Country != "IE" || ie.Currency != "EUR" || ie.GrossAmount != 50 || ie.WithholdingTax != 0 {
+			t.Errorf("unexpected IE totals %+v", ie)
+		}
+		if us.Country != "US" || us.GrossAmount != 100 || us.WithholdingTax != 22.5 || us.NetAmount != 77.5 {
+			t.Errorf("unexpected US totals %+v", us)
+		}
+		if us.DefaultRate != 15 || us.EffectiveRate != 22.5 || us.Reclaimable != 7.5 {
+			t.Errorf("expected default 15%%, effective 22.5%% and 7.5 reclaimable, got %+v", us)
+		}
+		if len(us.Funds) != 1 || us.Funds[0].FundName != "US Equity" || us.Funds[0].Dividends != 2 {
+			t.Errorf("unexpected US funds %+v", us.Funds)
+		}
+	})
+
+	t.Run("includes every readable portfolio", func(t *testing.T) {
+		report, err := svc.GetWithholdingTaxReport(ctx, 2025, "")
+		if err != nil {
+			t.Fatalf("GetWithholdingTaxReport() error: %v", err)
+		}
+		if us := report.Countries[1]; us.GrossAmount != 150 || us.WithholdingTax != 30 {
+			t.Errorf("expected both portfolios in the US totals, got %+v", us)
+		}
+
+		user := model.User{ID: testutil.MakeID()}
+		restricted := auth.WithPrincipal(ctx, auth.NewPrincipal(user, map[string]model.PortfolioAccess{other.ID: model.PortfolioAccessRead}, nil))
+		report, err = svc.GetWithholdingTaxReport(restricted, 2025, "")
+		if err != nil {
+			t.Fatalf("GetWithholdingTaxReport() error: %v", err)
+		}
+		if len(report.Countries) != 1 || report.Countries[0].GrossAmount != 50 {
+			t.Errorf("expected only the shared portfolio, got %+v", report.Countries)
+		}
+	})
+}
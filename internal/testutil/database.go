@@ -88,20 +88,30 @@ func CleanDatabase(t *testing.T, db *sql.DB) {
 
 	// Order matters: delete children before parents due to foreign keys
 	tables := []string{
+		"alert",
+		"alert_rule",
+		"allocation_target",
+		"webhook_delivery",
+		"webhook",
+		"portfolio_history_rollup",
+		"portfolio_history_materialized",
 		"fund_history_materialized",
 		"ibkr_transaction_allocation",
 		"ibkr_transaction",
+		"ibkr_withholding_tax",
 		"ibkr_import_cache",
 		"ibkr_config",
 		"realized_gain_loss",
 		"dividend",
 		"transaction",
+		"investment_plan",
 		"fund_price",
 		"portfolio_fund",
 		"fund",
 		"portfolio",
 		"exchange_rate",
 		"system_setting",
+		"withholding_tax_rate",
 		"symbol_info",
 		"log",
 	}
@@ -448,6 +448,8 @@ type DividendBuilder struct {
 	SharesOwned               float64
 	DividendPerShare          float64
 	TotalAmount               float64
+	WithholdingTax            float64
+	WithholdingSource         model.WithholdingSource
 	ReinvestmentStatus        string
 	BuyOrderDate              *time.Time
 	ReinvestmentTransactionID string
@@ -464,6 +466,7 @@ func NewDividend(fundID, portfolioFundID string) *DividendBuilder {
 		SharesOwned:        100.0,
 		DividendPerShare:   0.50,
 		TotalAmount:        50.0,
+		WithholdingSource:  model.WithholdingSourceManual,
 		ReinvestmentStatus: "pending",
 	}
 }
@@ -500,15 +503,22 @@ func (b *DividendBuilder) WithDividendPerShare(amount float64) *DividendBuilder
 	return b
 }
 
+// WithWithholdingTax sets the withholding tax and where it came from.
+func (b *DividendBuilder) WithWithholdingTax(amount float64, source model.WithholdingSource) *DividendBuilder {
+	b.WithholdingTax = amount
+	b.WithholdingSource = source
+	return b
+}
+
 // Build creates the dividend in the database
 func (b *DividendBuilder) Build(t *testing.T, db *sql.DB) model.Dividend {
 	t.Helper()
 
 	query := `
 		INSERT INTO dividend (id, fund_id, portfolio_fund_id, record_date, ex_dividend_date,
-		                     shares_owned, dividend_per_share, total_amount, reinvestment_status,
-		                     buy_order_date, reinvestment_transaction_id)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		                     shares_owned, dividend_per_share, total_amount, withholding_tax, withholding_source,
+		                     reinvestment_status, buy_order_date, reinvestment_transaction_id)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
 	var buyOrderDate any
@@ -525,7 +535,7 @@ func (b *DividendBuilder) Build(t *testing.T, db *sql.DB) model.Dividend {
 		b.ID, b.FundID, b.PortfolioFundID,
 		b.RecordDate.Format("2006-01-02"),
 		b.ExDividendDate.Format("2006-01-02"),
-		b.SharesOwned, b.DividendPerShare, b.TotalAmount,
+		b.SharesOwned, b.DividendPerShare, b.TotalAmount, b.WithholdingTax, b.WithholdingSource,
 		b.ReinvestmentStatus, buyOrderDate, reinvTxID)
 
 	if err != nil {
@@ -541,6 +551,8 @@ func (b *DividendBuilder) Build(t *testing.T, db *sql.DB) model.Dividend {
 		SharesOwned:               b.SharesOwned,
 		DividendPerShare:          b.DividendPerShare,
 		TotalAmount:               b.TotalAmount,
+		WithholdingTax:            b.WithholdingTax,
+		WithholdingSource:         b.WithholdingSource,
 		ReinvestmentStatus:        b.ReinvestmentStatus,
 		ReinvestmentTransactionID: b.ReinvestmentTransactionID,
 		CreatedAt:                 time.Now().UTC(),
@@ -807,6 +819,7 @@ func NewLoggingLevel(level string) *SystemSettingBuilder {
 // IBKRTransactionBuilder provides a fluent interface for creating IBKR transactions.
 type IBKRTransactionBuilder struct {
 	ID                string
+	Source            string
 	IBKRTransactionID string
 	TransactionDate   time.Time
 	Symbol            string
@@ -827,6 +840,7 @@ type IBKRTransactionBuilder struct {
 func NewIBKRTransaction() *IBKRTransactionBuilder {
 	return &IBKRTransactionBuilder{
 		ID:                MakeID(),
+		Source:            "ibkr",
 		IBKRTransactionID: "IBKR_" + randomAlphanumeric(10),
 		TransactionDate:   time.Now().UTC(),
 		Symbol:            "AAPL",
@@ -844,6 +858,12 @@ func NewIBKRTransaction() *IBKRTransactionBuilder {
 	}
 }
 
+// WithSource sets the importer that staged the transaction.
+func (b *IBKRTransactionBuilder) WithSource(source string) *IBKRTransactionBuilder {
+	b.Source = source
+	return b
+}
+
 // WithStatus sets the transaction status.
 func (b *IBKRTransactionBuilder) WithStatus(status string) *IBKRTransactionBuilder {
 	b.Status = status
@@ -904,14 +924,14 @@ func (b *IBKRTransactionBuilder) Build(t *testing.T, db *sql.DB) model.IBKRTrans
 
 	query := `
 		INSERT INTO ibkr_transaction (
-			id, ibkr_transaction_id, transaction_date, symbol, isin, description,
+			id, source, ibkr_transaction_id, transaction_date, symbol, isin, description,
 			transaction_type, quantity, price, total_amount, currency, fees,
 			status, imported_at, report_date, notes
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, datetime('now'), ?, ?)
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, datetime('now'), ?, ?)
 	`
 
 	_, err := db.Exec(query,
-		b.ID, b.IBKRTransactionID, b.TransactionDate.Format("2006-01-02"),
+		b.ID, b.Source, b.IBKRTransactionID, b.TransactionDate.Format("2006-01-02"),
 		b.Symbol, b.ISIN, b.Description, b.TransactionType,
 		b.Quantity, b.Price, b.TotalAmount, b.Currency, b.Fees,
 		b.Status, b.ReportDate.Format("2006-01-02"), b.Notes,
@@ -922,6 +942,7 @@ func (b *IBKRTransactionBuilder) Build(t *testing.T, db *sql.DB) model.IBKRTrans
 
 	return model.IBKRTransaction{
 		ID:                b.ID,
+		Source:            b.Source,
 		IBKRTransactionID: b.IBKRTransactionID,
 		TransactionDate:   b.TransactionDate,
 		Symbol:            b.Symbol,
@@ -1,11 +1,14 @@
 package testutil
 
 import (
+	"context"
 	"database/sql"
 	"math/rand"
 	"testing"
 
 	"github.com/google/uuid"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/config"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/email"
 	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/ibkr"
 	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/repository"
 	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/service"
@@ -169,6 +172,23 @@ func NewTestMaterializedService(t *testing.T, db *sql.DB) *service.MaterializedS
 	)
 }
 
+// StartRegenWorker runs svc's materialized regeneration worker for the duration of the test.
+// Without it, queued regenerations stay in the queue.
+func StartRegenWorker(t *testing.T, svc *service.MaterializedService) {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		svc.RunRegenWorker(ctx)
+		close(done)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+}
+
 // NewTestIbkrService creates an IbkrService wired to the provided test database.
 func NewTestIbkrService(t *testing.T, db *sql.DB, opts ...service.IbkrServiceOption) *service.IbkrService {
 	t.Helper()
@@ -187,7 +207,7 @@ func NewTestIbkrServiceWithMockIBKR(t *testing.T, db *sql.DB, mockIBKR ibkr.Clie
 	transactionRepo := repository.NewTransactionRepository(db)
 	dividendRepo := repository.NewDividendRepository(db)
 
-	base := make([]service.IbkrServiceOption, 0, 7+len(opts))
+	base := make([]service.IbkrServiceOption, 0, 8+len(opts))
 	base = append(base,
 		service.IbkrWithIbkrRepo(ibkrRepo),
 		service.IbkrWithPortfolioRepo(repository.NewPortfolioRepository(db)),
@@ -195,12 +215,183 @@ func NewTestIbkrServiceWithMockIBKR(t *testing.T, db *sql.DB, mockIBKR ibkr.Clie
 		service.IbkrWithPortfolioFundRepo(pfRepo),
 		service.IbkrWithTransactionRepo(transactionRepo),
 		service.IbkrWithDividendRepo(dividendRepo),
+		service.IbkrWithWithholdingTaxRepo(repository.NewWithholdingTaxRepository(db)),
 		service.IbkrWithClient(mockIBKR),
 	)
 
 	return service.NewIbkrService(db, append(base, opts...)...)
 }
 
+// NewTestInboxService creates an InboxService wired to the provided test database.
+func NewTestInboxService(t *testing.T, db *sql.DB) *service.InboxService {
+	t.Helper()
+
+	return service.NewInboxService(db, repository.NewIbkrRepository(db))
+}
+
+// NewTestTrashService creates a TrashService wired to the provided test database,
+// using the default retention period.
+func NewTestTrashService(t *testing.T, db *sql.DB) *service.TrashService {
+	t.Helper()
+
+	return service.NewTrashService(
+		db,
+		repository.NewTrashRepository(db),
+		repository.NewIbkrRepository(db),
+		service.DefaultTrashRetention,
+	)
+}
+
+// NewTestAuditService creates an AuditService wired to the provided test database.
+func NewTestAuditService(t *testing.T, db *sql.DB) *service.AuditService {
+	t.Helper()
+
+	return service.NewAuditService(repository.NewAuditRepository(db))
+}
+
+// NewTestAuthService creates an AuthService wired to the provided test database,
+// using the default session TTL.
+func NewTestAuthService(t *testing.T, db *sql.DB) *service.AuthService {
+	t.Helper()
+
+	return service.NewAuthService(
+		db,
+		repository.NewUserRepository(db),
+		repository.NewAPITokenRepository(db),
+		repository.NewAuditRepository(db),
+		service.DefaultSessionTTL,
+	)
+}
+
+// NewTestJobService creates a JobService wired to the provided test database.
+// No job types are registered; tests register the handlers they need.
+func NewTestJobService(t *testing.T, db *sql.DB) *service.JobService {
+	t.Helper()
+
+	return service.NewJobService(repository.NewJobRepository(db))
+}
+
+// NewTestSchedulerService creates a SchedulerService wired to the provided test database,
+// running its tasks through jobs with the default schedules.
+func NewTestSchedulerService(t *testing.T, db *sql.DB, jobs *service.JobService) *service.SchedulerService {
+	t.Helper()
+
+	return service.NewSchedulerService(
+		db,
+		repository.NewDeveloperRepository(db),
+		repository.NewJobRepository(db),
+		repository.NewAuditRepository(db),
+		jobs,
+		config.Defaults().Scheduler,
+	)
+}
+
+// NewTestWebhookService creates a WebhookService wired to the provided test database.
+func NewTestWebhookService(t *testing.T, db *sql.DB, cfg config.WebhooksConfig) *service.WebhookService {
+	t.Helper()
+
+	return service.NewWebhookService(
+		db,
+		repository.NewWebhookRepository(db),
+		repository.NewAuditRepository(db),
+		cfg,
+	)
+}
+
+// NewTestDigestService creates a DigestService wired to the provided test database that
+// sends through sender. A nil sender behaves like a server without SMTP configuration.
+func NewTestDigestService(t *testing.T, db *sql.DB, sender email.Sender) *service.DigestService {
+	t.Helper()
+
+	return service.NewDigestService(
+		db,
+		repository.NewDeveloperRepository(db),
+		repository.NewAuditRepository(db),
+		NewTestMaterializedService(t, db),
+		NewTestIbkrService(t, db),
+		sender,
+	)
+}
+
+// NewTestAlertService creates an AlertService wired to the provided test database.
+// No event publisher or notifier is set.
+func NewTestAlertService(t *testing.T, db *sql.DB) *service.AlertService {
+	t.Helper()
+
+	return service.NewAlertService(
+		db,
+		repository.NewAlertRepository(db),
+		repository.NewAuditRepository(db),
+		repository.NewPortfolioRepository(db),
+		repository.NewFundRepository(db),
+		repository.NewPortfolioFundRepository(db),
+		repository.NewMaterializedRepository(db),
+	)
+}
+
+// NewTestAllocationService creates an AllocationService wired to the provided test database.
+func NewTestAllocationService(t *testing.T, db *sql.DB) *service.AllocationService {
+	t.Helper()
+
+	return service.NewAllocationService(
+		db,
+		repository.NewAllocationRepository(db),
+		repository.NewAuditRepository(db),
+		repository.NewPortfolioRepository(db),
+		repository.NewPortfolioFundRepository(db),
+		NewTestFundService(t, db),
+	)
+}
+
+// NewTestInvestmentPlanService creates an InvestmentPlanService wired to the provided test database.
+func NewTestInvestmentPlanService(t *testing.T, db *sql.DB) *service.InvestmentPlanService {
+	t.Helper()
+
+	return service.NewInvestmentPlanService(
+		db,
+		repository.NewInvestmentPlanRepository(db),
+		repository.NewAuditRepository(db),
+		repository.NewPortfolioFundRepository(db),
+		repository.NewFundRepository(db),
+		repository.NewTransactionRepository(db),
+	)
+}
+
+// NewTestDividendIncomeService creates a DividendIncomeService wired to the provided test database.
+func NewTestDividendIncomeService(t *testing.T, db *sql.DB) *service.DividendIncomeService {
+	t.Helper()
+
+	return service.NewDividendIncomeService(
+		repository.NewDividendRepository(db),
+		repository.NewTransactionRepository(db),
+		NewTestFundService(t, db),
+	)
+}
+
+// NewTestWithholdingTaxService creates a WithholdingTaxService wired to the provided test database.
+func NewTestWithholdingTaxService(t *testing.T, db *sql.DB) *service.WithholdingTaxService {
+	t.Helper()
+
+	return service.NewWithholdingTaxService(
+		db,
+		repository.NewWithholdingTaxRepository(db),
+		repository.NewAuditRepository(db),
+	)
+}
+
+// NewTestTaxReportService creates a TaxReportService wired to the provided test database.
+func NewTestTaxReportService(t *testing.T, db *sql.DB) *service.TaxReportService {
+	t.Helper()
+
+	return service.NewTaxReportService(
+		repository.NewTaxReportRepository(db),
+		repository.NewPortfolioRepository(db),
+		repository.NewTransactionRepository(db),
+		repository.NewWithholdingTaxRepository(db),
+		repository.NewDeveloperRepository(db),
+	)
+}
+
 // NewTestSystemService creates a SystemService wired to the provided test database.
 func NewTestSystemService(t *testing.T, db *sql.DB) *service.SystemService {
 	t.Helper()
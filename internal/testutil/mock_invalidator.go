@@ -48,6 +48,12 @@ func (m *MockMaterializedInvalidator) RegenerateMaterializedTable(_ context.Cont
 	return nil
 }
 
+// ScheduleRegeneration implements service.MaterializedInvalidator by recording the call
+// from a goroutine, like the real background regeneration.
+func (m *MockMaterializedInvalidator) ScheduleRegeneration(ctx context.Context, startDate time.Time, portfolioIDs []string, fundID, portfolioFundID string) {
+	go m.RegenerateMaterializedTable(ctx, startDate, portfolioIDs, fundID, portfolioFundID) //nolint:errcheck // The mock never fails.
+}
+
 // WaitForCall blocks until one call is recorded or the timeout expires.
 // Returns true if a call was received, false on timeout.
 func (m *MockMaterializedInvalidator) WaitForCall(timeout time.Duration) bool {
@@ -0,0 +1,80 @@
+package tracing_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/config"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/testutil"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/tracing"
+)
+
+func TestInit(t *testing.T) {
+	t.Run("disabled", func(t *testing.T) {
+		shutdown, err := tracing.Init(context.Background(), config.TracingConfig{Exporter: tracing.ExporterNone})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := shutdown(context.Background()); err != nil {
+			t.Errorf("unexpected shutdown error: %v", err)
+		}
+	})
+
+	t.Run("unknown exporter", func(t *testing.T) {
+		if _, err := tracing.Init(context.Background(), config.TracingConfig{Exporter: "zipkin"}); err == nil {
+			t.Error("Expected error for unknown exporter")
+		}
+	})
+}
+
+func TestEnd(t *testing.T) {
+	recorder := testutil.RecordSpans(t)
+
+	_, ok := tracing.Start(context.Background(), "ok")
+	tracing.End(ok, nil)
+	_, failed := tracing.Start(context.Background(), "failed")
+	tracing.End(failed, errors.New("boom"))
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("Expected 2 spans, got %d", len(spans))
+	}
+	if spans[0].Status().Code != codes.Unset {
+		t.Errorf("Expected unset status, got %v", spans[0].Status().Code)
+	}
+	if spans[1].Status().Code != codes.Error || len(spans[1].Events()) != 1 {
+		t.Errorf("Expected error status with a recorded error event, got %v and %d events",
+			spans[1].Status().Code, len(spans[1].Events()))
+	}
+}
+
+func TestDetach(t *testing.T) {
+	recorder := testutil.RecordSpans(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ctx, parent := tracing.Start(ctx, "request")
+	if !tracing.Active(ctx) {
+		t.Error("Expected request context to be active")
+	}
+	detached := tracing.Detach(ctx)
+	cancel()
+	parent.End()
+
+	if detached.Err() != nil {
+		t.Error("Expected detached context to outlive the request")
+	}
+	_, child := tracing.Start(detached, "background")
+	child.End()
+
+	spans := recorder.Ended()
+	if spans[1].Parent().SpanID() != trace.SpanContextFromContext(ctx).SpanID() {
+		t.Error("Expected background span to be a child of the request span")
+	}
+	if tracing.Active(ctx) {
+		t.Error("Expected ended span to be inactive")
+	}
+}
@@ -0,0 +1,75 @@
+package validation
+
+import (
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/api/request"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/model"
+)
+
+// maxAlertRuleNameLength bounds alert rule names, matching the alert_rule.name column.
+const maxAlertRuleNameLength = 100
+
+// ValidateCreateAlertRule validates a CreateAlertRuleRequest.
+// Returns a validation Error if the name is missing or too long, the type is unknown, a
+// field the type needs is missing or invalid, or the threshold is not positive. Position
+// loss thresholds are percentages and cannot exceed 100.
+func ValidateCreateAlertRule(req request.CreateAlertRuleRequest) error {
+	errors := make(map[string]string)
+
+	if req.Name == "" {
+		errors["name"] = "name is required"
+	} else if len(req.Name) > maxAlertRuleNameLength {
+		errors["name"] = "name must be at most 100 characters"
+	}
+
+	ruleType := model.AlertRuleType(req.Type)
+	if !model.ValidAlertRuleTypes[ruleType] {
+		errors["type"] = "type must be fund_price, fund_move, portfolio_value or position_loss"
+	}
+
+	needsPortfolio := ruleType == model.AlertRulePortfolioValue || ruleType == model.AlertRulePositionLoss
+	needsFund := ruleType.IsFundRule() || ruleType == model.AlertRulePositionLoss
+	if needsPortfolio {
+		if req.PortfolioID == "" {
+			errors["portfolioId"] = "portfolioId is required for " + req.Type + " rules"
+		} else if ValidateUUID(req.PortfolioID) != nil {
+			errors["portfolioId"] = "portfolioId must be a valid UUID"
+		}
+	}
+	if needsFund {
+		if req.FundID == "" {
+			errors["fundId"] = "fundId is required for " + req.Type + " rules"
+		} else if ValidateUUID(req.FundID) != nil {
+			errors["fundId"] = "fundId must be a valid UUID"
+		}
+	}
+	if ruleType == model.AlertRuleFundPrice {
+		if direction := model.AlertDirection(req.Direction); direction != model.AlertDirectionAbove && direction != model.AlertDirectionBelow {
+			errors["direction"] = "direction must be above or below"
+		}
+	}
+	if ruleType == model.AlertRuleFundMove {
+		if period := model.AlertPeriod(req.Period); period != model.AlertPeriodDay && period != model.AlertPeriodWeek {
+			errors["period"] = "period must be day or week"
+		}
+	}
+
+	switch {
+	case req.Threshold == nil:
+		errors["threshold"] = "threshold is required"
+	case *req.Threshold <= 0:
+		errors["threshold"] = "threshold must be greater than 0"
+	case ruleType == model.AlertRulePositionLoss && *req.Threshold > 100:
+		errors["threshold"] = "threshold must be at most 100 percent"
+	}
+
+	if len(errors) > 0 {
+		return &Error{Fields: errors}
+	}
+	return nil
+}
+
+// ValidateUpdateAlertRule validates an UpdateAlertRuleRequest with the same rules as
+// ValidateCreateAlertRule.
+func ValidateUpdateAlertRule(req request.UpdateAlertRuleRequest) error {
+	return ValidateCreateAlertRule(request.CreateAlertRuleRequest(req))
+}
@@ -0,0 +1,53 @@
+package validation
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/api/request"
+)
+
+func TestValidateCreateAlertRule(t *testing.T) {
+	num := func(f float64) *float64 { return &f }
+	const portfolioID = "3f7c2a1e-9b4d-4e8a-8c6f-1d2e3f4a5b6c"
+	const fundID = "8a9b0c1d-2e3f-4a5b-9c6d-7e8f9a0b1c2d"
+
+	tests := []struct {
+		name       string
+		req        request.CreateAlertRuleRequest
+		wantErr    bool
+		fieldCheck string
+	}{
+		{"valid fund price", request.CreateAlertRuleRequest{Name: "ETF above 100", Type: "fund_price", FundID: fundID, Direction: "above", Threshold: num(100)}, false, ""},
+		{"valid fund move", request.CreateAlertRuleRequest{Name: "ETF weekly move", Type: "fund_move", FundID: fundID, Period: "week", Threshold: num(5)}, false, ""},
+		{"valid portfolio value", request.CreateAlertRuleRequest{Name: "Floor", Type: "portfolio_value", PortfolioID: portfolioID, Threshold: num(10000)}, false, ""},
+		{"valid position loss", request.CreateAlertRuleRequest{Name: "ETF loss", Type: "position_loss", PortfolioID: portfolioID, FundID: fundID, Threshold: num(10)}, false, ""},
+		{"missing name", request.CreateAlertRuleRequest{Type: "fund_price", FundID: fundID, Direction: "above", Threshold: num(100)}, true, "name"},
+		{"unknown type", request.CreateAlertRuleRequest{Name: "x", Type: "fund_volume", Threshold: num(1)}, true, "type"},
+		{"fund price without fund", request.CreateAlertRuleRequest{Name: "x", Type: "fund_price", Direction: "below", Threshold: num(1)}, true, "fundId"},
+		{"fund price without direction", request.CreateAlertRuleRequest{Name: "x", Type: "fund_price", FundID: fundID, Threshold: num(1)}, true, "direction"},
+		{"fund move with month period", request.CreateAlertRuleRequest{Name: "x", Type: "fund_move", FundID: fundID, Period: "month", Threshold: num(1)}, true, "period"},
+		{"portfolio value with invalid id", request.CreateAlertRuleRequest{Name: "x", Type: "portfolio_value", PortfolioID: "abc", Threshold: num(1)}, true, "portfolioId"},
+		{"position loss without fund", request.CreateAlertRuleRequest{Name: "x", Type: "position_loss", PortfolioID: portfolioID, Threshold: num(10)}, true, "fundId"},
+		{"position loss over 100 percent", request.CreateAlertRuleRequest{Name: "x", Type: "position_loss", PortfolioID: portfolioID, FundID: fundID, Threshold: num(150)}, true, "threshold"},
+		{"missing threshold", request.CreateAlertRuleRequest{Name: "x", Type: "portfolio_value", PortfolioID: portfolioID}, true, "threshold"},
+		{"zero threshold", request.CreateAlertRuleRequest{Name: "x", Type: "portfolio_value", PortfolioID: portfolioID, Threshold: num(0)}, true, "threshold"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateCreateAlertRule(tt.req)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateCreateAlertRule() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && tt.fieldCheck != "" {
+				var valErr *Error
+				if errors.As(err, &valErr) {
+					if _, ok := valErr.Fields[tt.fieldCheck]; !ok {
+						t.Errorf("expected error on field %q, got fields: %v", tt.fieldCheck, valErr.Fields)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package validation
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/api/request"
+)
+
+// allocationSumTolerance is how far the sum of the target percentages may be from 100
+// to allow for rounding in the client.
+const allocationSumTolerance = 0.01
+
+// ValidateSetAllocationTargets validates a SetAllocationTargetsRequest.
+// Returns a validation Error if a target sets both or neither of fundId and assetClass,
+// mixes fund and asset class targets, has an invalid fund ID or unknown asset class,
+// repeats a fund or asset class, or has a percentage outside 0-100, or if the targets
+// do not add up to 100 percent. An empty list is valid.
+func ValidateSetAllocationTargets(req request.SetAllocationTargetsRequest) error {
+	errors := make(map[string]string)
+
+	seen := make(map[string]bool)
+	var fundTargets, classTargets int
+	var sum float64
+	for i, t := range req.Targets {
+		field := fmt.Sprintf("targets[%d]", i)
+		switch {
+		case t.FundID != "" && t.AssetClass != "":
+			errors[field] = "set either fundId or assetClass, not both"
+		case t.FundID != "":
+			fundTargets++
+			if ValidateUUID(t.FundID) != nil {
+				errors[field+".fundId"] = "fundId must be a valid UUID"
+			} else if seen[t.FundID] {
+				errors[field+".fundId"] = "fund has more than one target"
+			}
+			seen[t.FundID] = true
+		case t.AssetClass != "":
+			classTargets++
+			if !ValidInvestmentType[t.AssetClass] {
+				errors[field+".assetClass"] = "assetClass must be FUND or STOCK"
+			} else if seen[t.AssetClass] {
+				errors[field+".assetClass"] = "asset class has more than one target"
+			}
+			seen[t.AssetClass] = true
+		default:
+			errors[field] = "fundId or assetClass is required"
+		}
+
+		if t.TargetPercent < 0 || t.TargetPercent > 100 {
+			errors[field+".targetPercent"] = "targetPercent must be between 0 and 100"
+		}
+		if t.TolerancePercent < 0 || t.TolerancePercent > 100 {
+			errors[field+".tolerancePercent"] = "tolerancePercent must be between 0 and 100"
+		}
+		sum += t.TargetPercent
+	}
+
+	if fundTargets > 0 && classTargets > 0 {
+		errors["targets"] = "targets must all be set per fund or all per asset class"
+	} else if len(req.Targets) > 0 && math.Abs(sum-100) > allocationSumTolerance {
+		errors["targets"] = fmt.Sprintf("target percentages must add up to 100, got %.2f", sum)
+	}
+
+	if len(errors) > 0 {
+		return &Error{Fields: errors}
+	}
+	return nil
+}
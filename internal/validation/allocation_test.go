@@ -0,0 +1,84 @@
+package validation
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/api/request"
+)
+
+func TestValidateSetAllocationTargets(t *testing.T) {
+	const fundA = "3f7c2a1e-9b4d-4e8a-8c6f-1d2e3f4a5b6c"
+	const fundB = "8a9b0c1d-2e3f-4a5b-9c6d-7e8f9a0b1c2d"
+	targets := func(ts ...request.AllocationTargetRequest) request.SetAllocationTargetsRequest {
+		return request.SetAllocationTargetsRequest{Targets: ts}
+	}
+
+	tests := []struct {
+		name       string
+		req        request.SetAllocationTargetsRequest
+		wantErr    bool
+		fieldCheck string
+	}{
+		{"empty list clears targets", targets(), false, ""},
+		{"valid fund targets", targets(
+			request.AllocationTargetRequest{FundID: fundA, TargetPercent: 60, TolerancePercent: 5},
+			request.AllocationTargetRequest{FundID: fundB, TargetPercent: 40, TolerancePercent: 5},
+		), false, ""},
+		{"valid asset class targets", targets(
+			request.AllocationTargetRequest{AssetClass: "FUND", TargetPercent: 80},
+			request.AllocationTargetRequest{AssetClass: "STOCK", TargetPercent: 20},
+		), false, ""},
+		{"sum within rounding", targets(
+			request.AllocationTargetRequest{FundID: fundA, TargetPercent: 66.67},
+			request.AllocationTargetRequest{FundID: fundB, TargetPercent: 33.33},
+		), false, ""},
+		{"sum below 100", targets(
+			request.AllocationTargetRequest{FundID: fundA, TargetPercent: 60},
+			request.AllocationTargetRequest{FundID: fundB, TargetPercent: 30},
+		), true, "targets"},
+		{"mixed fund and asset class", targets(
+			request.AllocationTargetRequest{FundID: fundA, TargetPercent: 50},
+			request.AllocationTargetRequest{AssetClass: "STOCK", TargetPercent: 50},
+		), true, "targets"},
+		{"both fund and asset class", targets(
+			request.AllocationTargetRequest{FundID: fundA, AssetClass: "FUND", TargetPercent: 100},
+		), true, "targets[0]"},
+		{"neither fund nor asset class", targets(
+			request.AllocationTargetRequest{TargetPercent: 100},
+		), true, "targets[0]"},
+		{"invalid fund id", targets(
+			request.AllocationTargetRequest{FundID: "abc", TargetPercent: 100},
+		), true, "targets[0].fundId"},
+		{"unknown asset class", targets(
+			request.AllocationTargetRequest{AssetClass: "BOND", TargetPercent: 100},
+		), true, "targets[0].assetClass"},
+		{"duplicate fund", targets(
+			request.AllocationTargetRequest{FundID: fundA, TargetPercent: 50},
+			request.AllocationTargetRequest{FundID: fundA, TargetPercent: 50},
+		), true, "targets[1].fundId"},
+		{"negative tolerance", targets(
+			request.AllocationTargetRequest{FundID: fundA, TargetPercent: 100, TolerancePercent: -1},
+		), true, "targets[0].tolerancePercent"},
+		{"target over 100", targets(
+			request.AllocationTargetRequest{FundID: fundA, TargetPercent: 120},
+		), true, "targets[0].targetPercent"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateSetAllocationTargets(tt.req)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateSetAllocationTargets() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && tt.fieldCheck != "" {
+				var valErr *Error
+				if errors.As(err, &valErr) {
+					if _, ok := valErr.Fields[tt.fieldCheck]; !ok {
+						t.Errorf("expected error on field %q, got fields: %v", tt.fieldCheck, valErr.Fields)
+					}
+				}
+			}
+		})
+	}
+}
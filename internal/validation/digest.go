@@ -0,0 +1,49 @@
+package validation
+
+import (
+	"fmt"
+	"net/mail"
+
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/api/request"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/model"
+)
+
+// maxDigestRecipients bounds the recipient list; the digest is meant for a household.
+const maxDigestRecipients = 20
+
+// ValidateUpdateDigestSettings validates an UpdateDigestSettingsRequest.
+// Returns a validation Error if the period is not week or month, or the recipient list is
+// too long or contains an invalid or duplicate address.
+func ValidateUpdateDigestSettings(req request.UpdateDigestSettingsRequest) error {
+	errors := make(map[string]string)
+
+	if req.Period != nil && !model.ValidDigestPeriods[model.DigestPeriod(*req.Period)] {
+		errors["period"] = "period must be week or month"
+	}
+	if msg := digestRecipientsError(req.Recipients); msg != "" {
+		errors["recipients"] = msg
+	}
+
+	if len(errors) > 0 {
+		return &Error{Fields: errors}
+	}
+	return nil
+}
+
+func digestRecipientsError(recipients []string) string {
+	if len(recipients) > maxDigestRecipients {
+		return fmt.Sprintf("at most %d recipients are allowed", maxDigestRecipients)
+	}
+	seen := make(map[string]bool, len(recipients))
+	for _, recipient := range recipients {
+		addr, err := mail.ParseAddress(recipient)
+		if err != nil {
+			return fmt.Sprintf("invalid email address %q", recipient)
+		}
+		if seen[addr.Address] {
+			return fmt.Sprintf("duplicate email address %q", addr.Address)
+		}
+		seen[addr.Address] = true
+	}
+	return ""
+}
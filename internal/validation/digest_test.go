@@ -0,0 +1,43 @@
+package validation
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/api/request"
+)
+
+func TestValidateUpdateDigestSettings(t *testing.T) {
+	str := func(s string) *string { return &s }
+
+	tests := []struct {
+		name       string
+		req        request.UpdateDigestSettingsRequest
+		wantErr    bool
+		fieldCheck string
+	}{
+		{"empty request", request.UpdateDigestSettingsRequest{}, false, ""},
+		{"valid", request.UpdateDigestSettingsRequest{Period: str("month"), Recipients: []string{"alice@example.com", "Bob <bob@example.com>"}}, false, ""},
+		{"no recipients", request.UpdateDigestSettingsRequest{Recipients: []string{}}, false, ""},
+		{"unknown period", request.UpdateDigestSettingsRequest{Period: str("day")}, true, "period"},
+		{"invalid address", request.UpdateDigestSettingsRequest{Recipients: []string{"not an address"}}, true, "recipients"},
+		{"duplicate address", request.UpdateDigestSettingsRequest{Recipients: []string{"alice@example.com", "Alice <alice@example.com>"}}, true, "recipients"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateUpdateDigestSettings(tt.req)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateUpdateDigestSettings() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && tt.fieldCheck != "" {
+				var valErr *Error
+				if errors.As(err, &valErr) {
+					if _, ok := valErr.Fields[tt.fieldCheck]; !ok {
+						t.Errorf("expected error on field %q, got fields: %v", tt.fieldCheck, valErr.Fields)
+					}
+				}
+			}
+		})
+	}
+}
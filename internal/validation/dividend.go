@@ -20,6 +20,7 @@ import (
 //   - buyOrderDate: Must be in YYYY-MM-DD format
 //   - reinvestmentShares: Must be positive
 //   - reinvestmentPrice: Must be positive
+//   - withholdingTax: Must not be negative
 //
 // Returns a validation Error with field-specific error messages if validation fails.
 func ValidateCreateDividend(req request.CreateDividendRequest) error {
@@ -69,6 +70,10 @@ func ValidateCreateDividend(req request.CreateDividendRequest) error {
 		errors["reinvestmentPrice"] = "reinvestmentPrice must be positive"
 	}
 
+	if req.WithholdingTax != nil && *req.WithholdingTax < 0.0 {
+		errors["withholdingTax"] = "withholdingTax must not be negative"
+	}
+
 	if len(errors) > 0 {
 		return &Error{Fields: errors}
 	}
@@ -87,6 +92,7 @@ func ValidateCreateDividend(req request.CreateDividendRequest) error {
 //   - buyOrderDate: Must be in YYYY-MM-DD format if provided
 //   - reinvestmentShares: Must be positive if provided
 //   - reinvestmentPrice: Must be positive if provided
+//   - withholdingTax: Must not be negative if provided
 //
 // Returns a validation Error with field-specific error messages if validation fails.
 //
@@ -148,6 +154,10 @@ func ValidateUpdateDividend(req request.UpdateDividendRequest) error {
 		}
 	}
 
+	if req.WithholdingTax != nil && *req.WithholdingTax < 0.0 {
+		errors["withholdingTax"] = "withholdingTax must not be negative"
+	}
+
 	if len(errors) > 0 {
 		return &Error{Fields: errors}
 	}
@@ -44,6 +44,8 @@ func TestValidateCreateDividend(t *testing.T) {
 		{"zero reinvestment shares ok", func(r *request.CreateDividendRequest) { r.ReinvestmentShares = 0.0 }, false, "", false},
 		{"negative reinvestment price", func(r *request.CreateDividendRequest) { r.ReinvestmentPrice = -1.0 }, true, "reinvestmentPrice", false},
 		{"zero reinvestment price ok", func(r *request.CreateDividendRequest) { r.ReinvestmentPrice = 0.0 }, false, "", false},
+		{"withholding tax", func(r *request.CreateDividendRequest) { v := 1.5; r.WithholdingTax = &v }, false, "", false},
+		{"negative withholding tax", func(r *request.CreateDividendRequest) { v := -1.0; r.WithholdingTax = &v }, true, "withholdingTax", false},
 	}
 
 	for _, tt := range tests {
@@ -104,6 +106,8 @@ func TestValidateUpdateDividend(t *testing.T) {
 		{"negative reinvestment shares", request.UpdateDividendRequest{ReinvestmentShares: floatPtr(-1.0)}, true, "reinvestmentShares", false},
 		{"positive reinvestment price", request.UpdateDividendRequest{ReinvestmentPrice: floatPtr(10.0)}, false, "", false},
 		{"zero reinvestment price", request.UpdateDividendRequest{ReinvestmentPrice: floatPtr(0.0)}, true, "reinvestmentPrice", false},
+		{"zero withholding tax", request.UpdateDividendRequest{WithholdingTax: floatPtr(0.0)}, false, "", false},
+		{"negative withholding tax", request.UpdateDividendRequest{WithholdingTax: floatPtr(-0.1)}, true, "withholdingTax", false},
 		{"negative reinvestment price", request.UpdateDividendRequest{ReinvestmentPrice: floatPtr(-1.0)}, true, "reinvestmentPrice", false},
 	}
 
@@ -0,0 +1,85 @@
+package validation
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/api/request"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/model"
+)
+
+// maxInboxBatchSize caps the number of transactions accepted in a single staging request.
+const maxInboxBatchSize = 1000
+
+var inboxSourceRegex = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,19}$`)
+
+const inboxSourceMessage = "source must be a lowercase slug (a-z, 0-9, '-' or '_') of at most 20 characters"
+
+// ValidateInboxSource checks that source is a lowercase slug of at most 20 characters.
+func ValidateInboxSource(source string) error {
+	if !inboxSourceRegex.MatchString(source) {
+		return &Error{Fields: map[string]string{"source": inboxSourceMessage}}
+	}
+	return nil
+}
+
+// ValidateStageInboxTransactions validates a request to stage transactions in the inbox.
+//
+// Rules:
+//   - source: lowercase slug; "ibkr" is reserved for the Flex report importer
+//   - transactions: between 1 and 1000 entries
+//   - each entry needs a YYYY-MM-DD date, a valid transaction type, an ISIN or symbol,
+//     a 3-letter currency, and non-negative quantity, price, totalAmount and fees
+//
+//nolint:gocyclo // Per-entry field validation; splitting would scatter the rules.
+func ValidateStageInboxTransactions(req request.StageInboxTransactionsRequest) error {
+	errors := make(map[string]string)
+
+	if !inboxSourceRegex.MatchString(req.Source) {
+		errors["source"] = inboxSourceMessage
+	} else if req.Source == string(model.InboxSourceIBKR) {
+		errors["source"] = "source 'ibkr' is reserved for the IBKR Flex importer"
+	}
+
+	if len(req.Transactions) == 0 {
+		errors["transactions"] = "at least one transaction is required"
+	} else if len(req.Transactions) > maxInboxBatchSize {
+		errors["transactions"] = fmt.Sprintf("at most %d transactions can be staged at once", maxInboxBatchSize)
+	}
+
+	for i, e := range req.Transactions {
+		field := func(name string) string { return fmt.Sprintf("transactions[%d].%s", i, name) }
+
+		if _, err := time.Parse("2006-01-02", e.Date); err != nil {
+			errors[field("date")] = "date must be in YYYY-MM-DD format"
+		}
+		if !ValidTransactionType[model.TransactionType(e.Type)] {
+			errors[field("type")] = fmt.Sprintf("invalid type: %s", e.Type)
+		}
+		if strings.TrimSpace(e.ISIN) == "" && strings.TrimSpace(e.Symbol) == "" {
+			errors[field("isin")] = "isin or symbol is required"
+		}
+		if len(e.Currency) != 3 {
+			errors[field("currency")] = "currency must be a 3-letter code"
+		}
+		if e.Quantity < 0 {
+			errors[field("quantity")] = "quantity cannot be negative"
+		}
+		if e.Price < 0 {
+			errors[field("price")] = "price cannot be negative"
+		}
+		if e.TotalAmount < 0 {
+			errors[field("totalAmount")] = "totalAmount cannot be negative"
+		}
+		if e.Fees < 0 {
+			errors[field("fees")] = "fees cannot be negative"
+		}
+	}
+
+	if len(errors) > 0 {
+		return &Error{Fields: errors}
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package validation
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/api/request"
+)
+
+func TestValidateInboxSource(t *testing.T) {
+	tests := []struct {
+		name    string
+		source  string
+		wantErr bool
+	}{
+		{"simple slug", "csv", false},
+		{"with dash and underscore", "degiro-nl_2", false},
+		{"empty", "", true},
+		{"uppercase", "CSV", true},
+		{"leading dash", "-csv", true},
+		{"too long", "abcdefghijklmnopqrstu", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateInboxSource(tt.source)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateInboxSource(%q) error = %v, wantErr %v", tt.source, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateStageInboxTransactions(t *testing.T) {
+	valid := request.InboxTransactionEntry{
+		Date:        "2025-03-14",
+		ISIN:        "IE00B3RBWM25",
+		Type:        "buy",
+		Quantity:    10,
+		Price:       100,
+		TotalAmount: 1000,
+		Currency:    "EUR",
+	}
+	with := func(fn func(e *request.InboxTransactionEntry)) []request.InboxTransactionEntry {
+		e := valid
+		fn(&e)
+		return []request.InboxTransactionEntry{e}
+	}
+
+	tests := []struct {
+		name       string
+		req        request.StageInboxTransactionsRequest
+		wantErr    bool
+		fieldCheck string
+	}{
+		{
+			"valid",
+			request.StageInboxTransactionsRequest{Source: "csv", Transactions: []request.InboxTransactionEntry{valid}},
+			false, "",
+		},
+		{
+			"reserved ibkr source",
+			request.StageInboxTransactionsRequest{Source: "ibkr", Transactions: []request.InboxTransactionEntry{valid}},
+			true, "source",
+		},
+		{
+			"invalid source",
+			request.StageInboxTransactionsRequest{Source: "My Broker", Transactions: []request.InboxTransactionEntry{valid}},
+			true, "source",
+		},
+		{
+			"no transactions",
+			request.StageInboxTransactionsRequest{Source: "csv"},
+			true, "transactions",
+		},
+		{
+			"too many transactions",
+			request.StageInboxTransactionsRequest{Source: "csv", Transactions: make([]request.InboxTransactionEntry, maxInboxBatchSize+1)},
+			true, "transactions",
+		},
+		{
+			"invalid date",
+			request.StageInboxTransactionsRequest{Source: "csv", Transactions: with(func(e *request.InboxTransactionEntry) { e.Date = "14-03-2025" })},
+			true, "transactions[0].date",
+		},
+		{
+			"invalid type",
+			request.StageInboxTransactionsRequest{Source: "csv", Transactions: with(func(e *request.InboxTransactionEntry) { e.Type = "swap" })},
+			true, "transactions[0].type",
+		},
+		{
+			"missing isin and symbol",
+			request.StageInboxTransactionsRequest{Source: "csv", Transactions: with(func(e *request.InboxTransactionEntry) { e.ISIN = "" })},
+			true, "transactions[0].isin",
+		},
+		{
+			"symbol without isin",
+			request.StageInboxTransactionsRequest{Source: "csv", Transactions: with(func(e *request.InboxTransactionEntry) { e.ISIN = ""; e.Symbol = "VWRL" })},
+			false, "",
+		},
+		{
+			"invalid currency",
+			request.StageInboxTransactionsRequest{Source: "csv", Transactions: with(func(e *request.InboxTransactionEntry) { e.Currency = "EURO" })},
+			true, "transactions[0].currency",
+		},
+		{
+			"negative quantity",
+			request.StageInboxTransactionsRequest{Source: "csv", Transactions: with(func(e *request.InboxTransactionEntry) { e.Quantity = -1 })},
+			true, "transactions[0].quantity",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateStageInboxTransactions(tt.req)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateStageInboxTransactions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.fieldCheck != "" {
+				var valErr *Error
+				if !errors.As(err, &valErr) {
+					t.Fatalf("expected *Error, got %T", err)
+				}
+				if _, ok := valErr.Fields[tt.fieldCheck]; !ok {
+					t.Errorf("expected error for field %q, got %v", tt.fieldCheck, valErr.Fields)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package validation
+
+import (
+	"time"
+
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/api/request"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/model"
+)
+
+// ValidateCreateInvestmentPlan validates a CreateInvestmentPlanRequest.
+// Returns a validation Error if the portfolio fund ID is not a UUID or the schedule is invalid.
+func ValidateCreateInvestmentPlan(req request.CreateInvestmentPlanRequest) error {
+	errors := make(map[string]string)
+
+	if req.PortfolioFundID == "" {
+		errors["portfolioFundId"] = "portfolioFundId is required"
+	} else if ValidateUUID(req.PortfolioFundID) != nil {
+		errors["portfolioFundId"] = "portfolioFundId must be a valid UUID"
+	}
+	validateInvestmentPlanSchedule(errors, req.Amount, req.Frequency, req.DayOfMonth, req.StartDate, req.EndDate)
+
+	if len(errors) > 0 {
+		return &Error{Fields: errors}
+	}
+	return nil
+}
+
+// ValidateUpdateInvestmentPlan validates an UpdateInvestmentPlanRequest with the same
+// schedule rules as ValidateCreateInvestmentPlan.
+func ValidateUpdateInvestmentPlan(req request.UpdateInvestmentPlanRequest) error {
+	errors := make(map[string]string)
+
+	validateInvestmentPlanSchedule(errors, req.Amount, req.Frequency, req.DayOfMonth, req.StartDate, req.EndDate)
+
+	if len(errors) > 0 {
+		return &Error{Fields: errors}
+	}
+	return nil
+}
+
+// validateInvestmentPlanSchedule checks the amount and schedule of an investment plan:
+// a positive amount, a known frequency, a day of month from 1 to 31, a start date and an
+// optional end date that is not before it.
+func validateInvestmentPlanSchedule(errors map[string]string, amount float64, frequency string, dayOfMonth int, startDate, endDate string) {
+	if amount <= 0 {
+		errors["amount"] = "amount must be greater than 0"
+	}
+	if !model.ValidInvestmentPlanFrequencies[model.InvestmentPlanFrequency(frequency)] {
+		errors["frequency"] = "frequency must be monthly, quarterly or yearly"
+	}
+	if dayOfMonth < 1 || dayOfMonth > 31 {
+		errors["dayOfMonth"] = "dayOfMonth must be between 1 and 31"
+	}
+
+	var start time.Time
+	if startDate == "" {
+		errors["startDate"] = "startDate is required"
+	} else {
+		var err error
+		if start, err = time.Parse("2006-01-02", startDate); err != nil {
+			errors["startDate"] = "startDate must be in YYYY-MM-DD format"
+		}
+	}
+	if endDate != "" {
+		end, err := time.Parse("2006-01-02", endDate)
+		switch {
+		case err != nil:
+			errors["endDate"] = "endDate must be in YYYY-MM-DD format"
+		case !start.IsZero() && end.Before(start):
+			errors["endDate"] = "endDate must not be before startDate"
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package validation
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/api/request"
+)
+
+func TestValidateCreateInvestmentPlan(t *testing.T) {
+	valid := func() request.CreateInvestmentPlanRequest {
+		return request.CreateInvestmentPlanRequest{
+			PortfolioFundID: "3f7c2a1e-9b4d-4e8a-8c6f-1d2e3f4a5b6c",
+			Amount:          250,
+			Frequency:       "monthly",
+			DayOfMonth:      15,
+			StartDate:       "2024-01-01",
+		}
+	}
+
+	tests := []struct {
+		name       string
+		modify     func(*request.CreateInvestmentPlanRequest)
+		wantErr    bool
+		fieldCheck string
+	}{
+		{"valid without end date", func(*request.CreateInvestmentPlanRequest) {}, false, ""},
+		{"valid with end date", func(r *request.CreateInvestmentPlanRequest) { r.EndDate = "2025-12-31" }, false, ""},
+		{"end date equals start date", func(r *request.CreateInvestmentPlanRequest) { r.EndDate = r.StartDate }, false, ""},
+		{"day 31", func(r *request.CreateInvestmentPlanRequest) { r.DayOfMonth = 31 }, false, ""},
+		{"missing portfolio fund", func(r *request.CreateInvestmentPlanRequest) { r.PortfolioFundID = "" }, true, "portfolioFundId"},
+		{"invalid portfolio fund", func(r *request.CreateInvestmentPlanRequest) { r.PortfolioFundID = "abc" }, true, "portfolioFundId"},
+		{"zero amount", func(r *request.CreateInvestmentPlanRequest) { r.Amount = 0 }, true, "amount"},
+		{"unknown frequency", func(r *request.CreateInvestmentPlanRequest) { r.Frequency = "weekly" }, true, "frequency"},
+		{"day 0", func(r *request.CreateInvestmentPlanRequest) { r.DayOfMonth = 0 }, true, "dayOfMonth"},
+		{"day 32", func(r *request.CreateInvestmentPlanRequest) { r.DayOfMonth = 32 }, true, "dayOfMonth"},
+		{"missing start date", func(r *request.CreateInvestmentPlanRequest) { r.StartDate = "" }, true, "startDate"},
+		{"invalid start date", func(r *request.CreateInvestmentPlanRequest) { r.StartDate = "01-01-2024" }, true, "startDate"},
+		{"invalid end date", func(r *request.CreateInvestmentPlanRequest) { r.EndDate = "2025-13-01" }, true, "endDate"},
+		{"end before start", func(r *request.CreateInvestmentPlanRequest) { r.EndDate = "2023-12-31" }, true, "endDate"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := valid()
+			tt.modify(&req)
+			err := ValidateCreateInvestmentPlan(req)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateCreateInvestmentPlan() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && tt.fieldCheck != "" {
+				var valErr *Error
+				if errors.As(err, &valErr) {
+					if _, ok := valErr.Fields[tt.fieldCheck]; !ok {
+						t.Errorf("expected error on field %q, got fields: %v", tt.fieldCheck, valErr.Fields)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestValidateUpdateInvestmentPlan(t *testing.T) {
+	req := request.UpdateInvestmentPlanRequest{Amount: 100, Frequency: "quarterly", DayOfMonth: 1, StartDate: "2024-01-01"}
+	if err := ValidateUpdateInvestmentPlan(req); err != nil {
+		t.Errorf("ValidateUpdateInvestmentPlan() unexpected error: %v", err)
+	}
+
+	req.Frequency = ""
+	var valErr *Error
+	if err := ValidateUpdateInvestmentPlan(req); !errors.As(err, &valErr) || valErr.Fields["frequency"] == "" {
+		t.Errorf("ValidateUpdateInvestmentPlan() error = %v, want frequency field error", err)
+	}
+}
@@ -0,0 +1,31 @@
+package validation
+
+import (
+	"time"
+
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/api/request"
+)
+
+// ValidateRebuildMaterialized validates a RebuildMaterializedRequest.
+// Both fields are optional; returns a validation Error if portfolioId is not a UUID or
+// startDate is not a YYYY-MM-DD date.
+func ValidateRebuildMaterialized(req request.RebuildMaterializedRequest) error {
+	errors := make(map[string]string)
+
+	if req.PortfolioID != "" {
+		if err := ValidateUUID(req.PortfolioID); err != nil {
+			errors["portfolioId"] = err.Error()
+		}
+	}
+
+	if req.StartDate != "" {
+		if _, err := time.Parse("2006-01-02", req.StartDate); err != nil {
+			errors["startDate"] = "startDate must be in YYYY-MM-DD format"
+		}
+	}
+
+	if len(errors) > 0 {
+		return &Error{Fields: errors}
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package validation
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/api/request"
+)
+
+func TestValidateRebuildMaterialized(t *testing.T) {
+	tests := []struct {
+		name       string
+		req        request.RebuildMaterializedRequest
+		wantErr    bool
+		fieldCheck string
+	}{
+		{"empty rebuilds everything", request.RebuildMaterializedRequest{}, false, ""},
+		{"portfolio and start date", request.RebuildMaterializedRequest{PortfolioID: "7d4f3a1e-2b9c-4c8d-9e6f-1a2b3c4d5e6f", StartDate: "2025-01-01"}, false, ""},
+		{"invalid portfolio id", request.RebuildMaterializedRequest{PortfolioID: "not-a-uuid"}, true, "portfolioId"},
+		{"invalid start date", request.RebuildMaterializedRequest{StartDate: "01-01-2025"}, true, "startDate"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateRebuildMaterialized(tt.req)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateRebuildMaterialized() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && tt.fieldCheck != "" {
+				var valErr *Error
+				if errors.As(err, &valErr) {
+					if _, ok := valErr.Fields[tt.fieldCheck]; !ok {
+						t.Errorf("expected error on field %q, got fields: %v", tt.fieldCheck, valErr.Fields)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package validation
+
+import (
+	"strings"
+	"time"
+
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/api/request"
+	"github.com/robfig/cron/v3"
+)
+
+// ValidateUpdateScheduledTask validates an UpdateScheduledTaskRequest.
+// All fields are optional; returns a validation Error if the schedule is not a standard
+// cron expression or the timezone is not a known IANA time zone.
+func ValidateUpdateScheduledTask(req request.UpdateScheduledTaskRequest) error {
+	errors := make(map[string]string)
+
+	if req.Schedule != nil {
+		schedule := strings.TrimSpace(*req.Schedule)
+		switch {
+		case schedule == "":
+			errors["schedule"] = "schedule cannot be empty"
+		case strings.HasPrefix(schedule, "TZ=") || strings.HasPrefix(schedule, "CRON_TZ="):
+			errors["schedule"] = "use the timezone field instead of a TZ prefix"
+		default:
+			if _, err := cron.ParseStandard(schedule); err != nil {
+				errors["schedule"] = "invalid cron expression: " + err.Error()
+			}
+		}
+	}
+
+	if req.Timezone != nil {
+		if strings.TrimSpace(*req.Timezone) == "" {
+			errors["timezone"] = "timezone cannot be empty"
+		} else if _, err := time.LoadLocation(*req.Timezone); err != nil {
+			errors["timezone"] = "unknown timezone: " + *req.Timezone
+		}
+	}
+
+	if len(errors) > 0 {
+		return &Error{Fields: errors}
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package validation
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/api/request"
+)
+
+func TestValidateUpdateScheduledTask(t *testing.T) {
+	str := func(s string) *string { return &s }
+	enabled := false
+
+	tests := []struct {
+		name       string
+		req        request.UpdateScheduledTaskRequest
+		wantErr    bool
+		fieldCheck string
+	}{
+		{"all fields", request.UpdateScheduledTaskRequest{Schedule: str("55 0 * * 1-5"), Timezone: str("Europe/Amsterdam"), Enabled: &enabled}, false, ""},
+		{"enabled only", request.UpdateScheduledTaskRequest{Enabled: &enabled}, false, ""},
+		{"descriptor schedule", request.UpdateScheduledTaskRequest{Schedule: str("@daily")}, false, ""},
+		{"empty request", request.UpdateScheduledTaskRequest{}, false, ""},
+		{"empty schedule", request.UpdateScheduledTaskRequest{Schedule: str(" ")}, true, "schedule"},
+		{"invalid schedule", request.UpdateScheduledTaskRequest{Schedule: str("61 * * * *")}, true, "schedule"},
+		{"seconds field", request.UpdateScheduledTaskRequest{Schedule: str("0 55 0 * * 1-5")}, true, "schedule"},
+		{"timezone prefix", request.UpdateScheduledTaskRequest{Schedule: str("CRON_TZ=UTC 0 1 * * *")}, true, "schedule"},
+		{"empty timezone", request.UpdateScheduledTaskRequest{Timezone: str("")}, true, "timezone"},
+		{"unknown timezone", request.UpdateScheduledTaskRequest{Timezone: str("Mars/Olympus_Mons")}, true, "timezone"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateUpdateScheduledTask(tt.req)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateUpdateScheduledTask() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && tt.fieldCheck != "" {
+				var valErr *Error
+				if errors.As(err, &valErr) {
+					if _, ok := valErr.Fields[tt.fieldCheck]; !ok {
+						t.Errorf("expected error on field %q, got fields: %v", tt.fieldCheck, valErr.Fields)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,11 @@
+package validation
+
+import "github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/model"
+
+// ValidateTrashEntityType checks that entityType is one of the entity types kept in the trash.
+func ValidateTrashEntityType(entityType string) error {
+	if !model.ValidTrashEntityTypes[model.TrashEntityType(entityType)] {
+		return &Error{Fields: map[string]string{"entityType": "entityType must be one of portfolio, fund, transaction, dividend"}}
+	}
+	return nil
+}
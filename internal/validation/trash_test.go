@@ -0,0 +1,28 @@
+package validation
+
+import "testing"
+
+func TestValidateTrashEntityType(t *testing.T) {
+	tests := []struct {
+		name       string
+		entityType string
+		wantErr    bool
+	}{
+		{"portfolio", "portfolio", false},
+		{"fund", "fund", false},
+		{"transaction", "transaction", false},
+		{"dividend", "dividend", false},
+		{"empty", "", true},
+		{"not trashable", "ibkr_transaction", true},
+		{"uppercase", "Portfolio", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateTrashEntityType(tt.entityType)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateTrashEntityType(%q) error = %v, wantErr %v", tt.entityType, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package validation
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/api/request"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/model"
+)
+
+const (
+	minPasswordLength = 8
+	// bcrypt ignores everything after the 72nd byte.
+	maxPasswordLength = 72
+
+	maxTokenNameLength   = 100
+	maxTokenLifetimeDays = 3650
+)
+
+var usernamePattern = regexp.MustCompile(`^[a-z0-9._-]{3,100}$`)
+
+// ValidateSetup validates a SetupRequest.
+func ValidateSetup(req request.SetupRequest) error {
+	return validateCredentials(req.Username, req.Password, "password")
+}
+
+// ValidateCreateUser validates a CreateUserRequest.
+func ValidateCreateUser(req request.CreateUserRequest) error {
+	return validateCredentials(req.Username, req.Password, "password")
+}
+
+// ValidateLogin validates a LoginRequest. Only presence is checked so that login
+// failures never reveal which account names exist.
+func ValidateLogin(req request.LoginRequest) error {
+	errors := make(map[string]string)
+
+	if strings.TrimSpace(req.Username) == "" {
+		errors["username"] = "username is required"
+	}
+	if req.Password == "" {
+		errors["password"] = "password is required"
+	}
+
+	if len(errors) > 0 {
+		return &Error{Fields: errors}
+	}
+	return nil
+}
+
+// ValidateChangePassword validates a ChangePasswordRequest.
+func ValidateChangePassword(req request.ChangePasswordRequest) error {
+	errors := make(map[string]string)
+
+	if req.CurrentPassword == "" {
+		errors["currentPassword"] = "currentPassword is required"
+	}
+	if msg := passwordError(req.NewPassword, "newPassword"); msg != "" {
+		errors["newPassword"] = msg
+	}
+
+	if len(errors) > 0 {
+		return &Error{Fields: errors}
+	}
+	return nil
+}
+
+// ValidateSharePortfolio validates a SharePortfolioRequest.
+func ValidateSharePortfolio(req request.SharePortfolioRequest) error {
+	errors := make(map[string]string)
+
+	if err := ValidateUUID(req.UserID); err != nil {
+		errors["userId"] = "userId must be a valid UUID"
+	}
+	if !model.ValidShareAccess[model.PortfolioAccess(req.Access)] {
+		errors["access"] = "access must be one of read, write"
+	}
+
+	if len(errors) > 0 {
+		return &Error{Fields: errors}
+	}
+	return nil
+}
+
+// ValidateCreateAPIToken validates a CreateAPITokenRequest. Whether the caller may
+// hold the requested scopes is checked by the service.
+func ValidateCreateAPIToken(req request.CreateAPITokenRequest) error {
+	errors := make(map[string]string)
+
+	if name := strings.TrimSpace(req.Name); name == "" || len(name) > maxTokenNameLength {
+		errors["name"] = fmt.Sprintf("name is required and must be %d characters or less", maxTokenNameLength)
+	}
+	if len(req.Scopes) == 0 {
+		errors["scopes"] = "at least one scope is required"
+	}
+	for _, scope := range req.Scopes {
+		if _, ok := model.ValidAPIScopes[model.APIScope(scope)]; !ok {
+			errors["scopes"] = fmt.Sprintf("unknown scope %q", scope)
+			break
+		}
+	}
+	if req.ExpiresInDays != nil && (*req.ExpiresInDays < 1 || *req.ExpiresInDays > maxTokenLifetimeDays) {
+		errors["expiresInDays"] = fmt.Sprintf("expiresInDays must be between 1 and %d", maxTokenLifetimeDays)
+	}
+
+	if len(errors) > 0 {
+		return &Error{Fields: errors}
+	}
+	return nil
+}
+
+func validateCredentials(username, password, passwordField string) error {
+	errors := make(map[string]string)
+
+	// Usernames are stored trimmed and lower-cased; validate that form.
+	if !usernamePattern.MatchString(strings.ToLower(strings.TrimSpace(username))) {
+		errors["username"] = "username must be 3-100 characters of a-z, 0-9, '.', '_' or '-'"
+	}
+	if msg := passwordError(password, passwordField); msg != "" {
+		errors[passwordField] = msg
+	}
+
+	if len(errors) > 0 {
+		return &Error{Fields: errors}
+	}
+	return nil
+}
+
+func passwordError(password, field string) string {
+	switch {
+	case len(password) < minPasswordLength:
+		return fmt.Sprintf("%s must be at least %d characters", field, minPasswordLength)
+	case len(password) > maxPasswordLength:
+		return fmt.Sprintf("%s must be %d bytes or less", field, maxPasswordLength)
+	}
+	return ""
+}
@@ -0,0 +1,118 @@
+package validation
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/api/request"
+)
+
+func TestValidateCreateUser(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     request.CreateUserRequest
+		wantErr string
+	}{
+		{"valid", request.CreateUserRequest{Username: "alice", Password: "correct horse"}, ""},
+		{"username normalized", request.CreateUserRequest{Username: "  Alice.B ", Password: "correct horse"}, ""},
+		{"username too short", request.CreateUserRequest{Username: "al", Password: "correct horse"}, "username"},
+		{"username with space", request.CreateUserRequest{Username: "alice b", Password: "correct horse"}, "username"},
+		{"password too short", request.CreateUserRequest{Username: "alice", Password: "short"}, "password"},
+		{"password too long", request.CreateUserRequest{Username: "alice", Password: strings.Repeat("x", 73)}, "password"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateCreateUser(tt.req)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				return
+			}
+			vErr, ok := err.(*Error)
+			if !ok {
+				t.Fatalf("expected *Error, got %v", err)
+			}
+			if _, ok := vErr.Fields[tt.wantErr]; !ok {
+				t.Errorf("expected error on field %q, got %v", tt.wantErr, vErr.Fields)
+			}
+		})
+	}
+}
+
+func TestValidateLogin(t *testing.T) {
+	if err := ValidateLogin(request.LoginRequest{Username: "x", Password: "y"}); err != nil {
+		t.Errorf("expected presence-only check to pass, got %v", err)
+	}
+	if err := ValidateLogin(request.LoginRequest{}); err == nil {
+		t.Error("expected error for empty credentials")
+	}
+}
+
+func TestValidateChangePassword(t *testing.T) {
+	if err := ValidateChangePassword(request.ChangePasswordRequest{CurrentPassword: "old", NewPassword: "new password"}); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if err := ValidateChangePassword(request.ChangePasswordRequest{NewPassword: "short"}); err == nil {
+		t.Error("expected error for missing current and short new password")
+	}
+}
+
+func TestValidateSharePortfolio(t *testing.T) {
+	const userID = "123e4567-e89b-12d3-a456-426614174000"
+	tests := []struct {
+		name    string
+		req     request.SharePortfolioRequest
+		wantErr bool
+	}{
+		{"read", request.SharePortfolioRequest{UserID: userID, Access: "read"}, false},
+		{"write", request.SharePortfolioRequest{UserID: userID, Access: "write"}, false},
+		{"owner cannot be granted", request.SharePortfolioRequest{UserID: userID, Access: "owner"}, true},
+		{"invalid user ID", request.SharePortfolioRequest{UserID: "nope", Access: "read"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateSharePortfolio(tt.req); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateSharePortfolio() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateCreateAPIToken(t *testing.T) {
+	days := func(n int) *int { return &n }
+
+	tests := []struct {
+		name    string
+		req     request.CreateAPITokenRequest
+		wantErr string
+	}{
+		{"valid", request.CreateAPITokenRequest{Name: "dashboard", Scopes: []string{"read:portfolio"}}, ""},
+		{"valid with expiry", request.CreateAPITokenRequest{Name: "script", Scopes: []string{"write:transaction", "admin:ibkr"}, ExpiresInDays: days(30)}, ""},
+		{"missing name", request.CreateAPITokenRequest{Name: " ", Scopes: []string{"read:fund"}}, "name"},
+		{"no scopes", request.CreateAPITokenRequest{Name: "dashboard"}, "scopes"},
+		{"unknown scope", request.CreateAPITokenRequest{Name: "dashboard", Scopes: []string{"read:everything"}}, "scopes"},
+		{"zero expiry", request.CreateAPITokenRequest{Name: "dashboard", Scopes: []string{"read:fund"}, ExpiresInDays: days(0)}, "expiresInDays"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateCreateAPIToken(tt.req)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				return
+			}
+			vErr, ok := err.(*Error)
+			if !ok {
+				t.Fatalf("expected *Error, got %v", err)
+			}
+			if _, ok := vErr.Fields[tt.wantErr]; !ok {
+				t.Errorf("expected error on field %q, got %v", tt.wantErr, vErr.Fields)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package validation
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/api/request"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/model"
+)
+
+// Webhook secret length bounds. Shorter secrets make the signature easy to forge.
+const (
+	minWebhookSecretLength = 16
+	maxWebhookSecretLength = 128
+)
+
+// ValidateCreateWebhook validates a CreateWebhookRequest.
+// Returns a validation Error if the URL is not an absolute http(s) URL, no or unknown
+// event types are given, or the secret is too short or too long.
+func ValidateCreateWebhook(req request.CreateWebhookRequest) error {
+	errors := make(map[string]string)
+
+	if msg := webhookURLError(req.URL); msg != "" {
+		errors["url"] = msg
+	}
+	if msg := webhookEventsError(req.EventTypes); msg != "" {
+		errors["eventTypes"] = msg
+	}
+	if req.Secret != "" {
+		if msg := webhookSecretError(req.Secret); msg != "" {
+			errors["secret"] = msg
+		}
+	}
+
+	if len(errors) > 0 {
+		return &Error{Fields: errors}
+	}
+	return nil
+}
+
+// ValidateUpdateWebhook validates an UpdateWebhookRequest. All fields are optional; the
+// ones present are checked like in ValidateCreateWebhook.
+func ValidateUpdateWebhook(req request.UpdateWebhookRequest) error {
+	errors := make(map[string]string)
+
+	if req.URL != nil {
+		if msg := webhookURLError(*req.URL); msg != "" {
+			errors["url"] = msg
+		}
+	}
+	if req.EventTypes != nil {
+		if msg := webhookEventsError(req.EventTypes); msg != "" {
+			errors["eventTypes"] = msg
+		}
+	}
+	if req.Secret != nil && *req.Secret != "" {
+		if msg := webhookSecretError(*req.Secret); msg != "" {
+			errors["secret"] = msg
+		}
+	}
+
+	if len(errors) > 0 {
+		return &Error{Fields: errors}
+	}
+	return nil
+}
+
+func webhookURLError(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "url must be an absolute http or https URL"
+	}
+	return ""
+}
+
+func webhookEventsError(events []string) string {
+	if len(events) == 0 {
+		return "at least one event type is required"
+	}
+	for _, event := range events {
+		if !model.ValidWebhookEvents[model.WebhookEvent(event)] {
+			return fmt.Sprintf("unknown event type %q", event)
+		}
+	}
+	return ""
+}
+
+func webhookSecretError(secret string) string {
+	if len(secret) < minWebhookSecretLength || len(secret) > maxWebhookSecretLength {
+		return fmt.Sprintf("secret must be between %d and %d characters", minWebhookSecretLength, maxWebhookSecretLength)
+	}
+	return ""
+}
@@ -0,0 +1,77 @@
+package validation
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/api/request"
+)
+
+func TestValidateCreateWebhook(t *testing.T) {
+	tests := []struct {
+		name       string
+		req        request.CreateWebhookRequest
+		wantErr    bool
+		fieldCheck string
+	}{
+		{"valid", request.CreateWebhookRequest{URL: "https://hooks.example.com/ipm", EventTypes: []string{"ibkr.inbox_items"}}, false, ""},
+		{"valid with secret", request.CreateWebhookRequest{URL: "http://homeassistant.local:8123/api/webhook/x", EventTypes: []string{"portfolio.value_drop"}, Secret: "0123456789abcdef"}, false, ""},
+		{"relative url", request.CreateWebhookRequest{URL: "/hook", EventTypes: []string{"ibkr.inbox_items"}}, true, "url"},
+		{"unsupported scheme", request.CreateWebhookRequest{URL: "ftp://example.com", EventTypes: []string{"ibkr.inbox_items"}}, true, "url"},
+		{"no events", request.CreateWebhookRequest{URL: "https://example.com"}, true, "eventTypes"},
+		{"unknown event", request.CreateWebhookRequest{URL: "https://example.com", EventTypes: []string{"portfolio.created"}}, true, "eventTypes"},
+		{"test event is not subscribable", request.CreateWebhookRequest{URL: "https://example.com", EventTypes: []string{"webhook.test"}}, true, "eventTypes"},
+		{"short secret", request.CreateWebhookRequest{URL: "https://example.com", EventTypes: []string{"ibkr.inbox_items"}, Secret: "short"}, true, "secret"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateCreateWebhook(tt.req)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateCreateWebhook() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && tt.fieldCheck != "" {
+				var valErr *Error
+				if errors.As(err, &valErr) {
+					if _, ok := valErr.Fields[tt.fieldCheck]; !ok {
+						t.Errorf("expected error on field %q, got fields: %v", tt.fieldCheck, valErr.Fields)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestValidateUpdateWebhook(t *testing.T) {
+	str := func(s string) *string { return &s }
+
+	tests := []struct {
+		name       string
+		req        request.UpdateWebhookRequest
+		wantErr    bool
+		fieldCheck string
+	}{
+		{"empty request", request.UpdateWebhookRequest{}, false, ""},
+		{"regenerate secret", request.UpdateWebhookRequest{Secret: str("")}, false, ""},
+		{"invalid url", request.UpdateWebhookRequest{URL: str("example.com")}, true, "url"},
+		{"empty event list", request.UpdateWebhookRequest{EventTypes: []string{}}, true, "eventTypes"},
+		{"short secret", request.UpdateWebhookRequest{Secret: str("abc")}, true, "secret"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateUpdateWebhook(tt.req)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateUpdateWebhook() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && tt.fieldCheck != "" {
+				var valErr *Error
+				if errors.As(err, &valErr) {
+					if _, ok := valErr.Fields[tt.fieldCheck]; !ok {
+						t.Errorf("expected error on field %q, got fields: %v", tt.fieldCheck, valErr.Fields)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,28 @@
+package validation
+
+import (
+	"regexp"
+
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/api/request"
+)
+
+// countryCodePattern matches an ISIN country code: two uppercase letters.
+var countryCodePattern = regexp.MustCompile(`^[A-Z]{2}$`)
+
+// ValidateSetWithholdingTaxRate validates a default withholding tax rate for country.
+// Returns a validation Error if country is not a two-letter code or the rate is not 0–100.
+func ValidateSetWithholdingTaxRate(country string, req request.SetWithholdingTaxRateRequest) error {
+	errors := make(map[string]string)
+
+	if !countryCodePattern.MatchString(country) {
+		errors["country"] = "country must be a two-letter country code"
+	}
+	if req.RatePercent < 0 || req.RatePercent > 100 {
+		errors["ratePercent"] = "ratePercent must be between 0 and 100"
+	}
+
+	if len(errors) > 0 {
+		return &Error{Fields: errors}
+	}
+	return nil
+}
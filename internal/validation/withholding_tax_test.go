@@ -0,0 +1,44 @@
+package validation
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/api/request"
+)
+
+func TestValidateSetWithholdingTaxRate(t *testing.T) {
+	tests := []struct {
+		name       string
+		country    string
+		rate       float64
+		wantErr    bool
+		fieldCheck string
+	}{
+		{"valid", "US", 15, false, ""},
+		{"zero rate", "IE", 0, false, ""},
+		{"full rate", "CH", 100, false, ""},
+		{"lowercase country", "us", 15, true, "country"},
+		{"three letter country", "USA", 15, true, "country"},
+		{"empty country", "", 15, true, "country"},
+		{"negative rate", "US", -1, true, "ratePercent"},
+		{"rate above 100", "US", 100.5, true, "ratePercent"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateSetWithholdingTaxRate(tt.country, request.SetWithholdingTaxRateRequest{RatePercent: tt.rate})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateSetWithholdingTaxRate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && tt.fieldCheck != "" {
+				var valErr *Error
+				if errors.As(err, &valErr) {
+					if _, ok := valErr.Fields[tt.fieldCheck]; !ok {
+						t.Errorf("expected error on field %q, got fields: %v", tt.fieldCheck, valErr.Fields)
+					}
+				}
+			}
+		})
+	}
+}
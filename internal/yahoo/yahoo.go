@@ -10,17 +10,19 @@ import (
 	"net/url"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/config"
 	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/logging"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/metrics"
+	"github.com/ndewijer/Investment-Portfolio-Manager-Backend/internal/tracing"
 )
 
 var log = logging.NewLogger("fund")
 
-// Retry constants.
-const (
-	maxAttempts    = 3               // Total attempts per request.
-	initialBackoff = 1 * time.Second // First retry wait.
-	maxBackoff     = 4 * time.Second // Backoff cap.
-)
+// metricsOperation labels Yahoo chart queries in the external call metrics.
+const metricsOperation = "chart"
 
 // Client defines the interface for fetching financial data from Yahoo Finance API.
 // This interface enables dependency injection and testing with mock implementations.
@@ -40,16 +42,24 @@ type Client interface {
 // and related financial data.
 type FinanceClient struct {
 	httpClient *http.Client
+	cfg        config.YahooConfig // retry attempts and backoff
 }
 
-// NewFinanceClient creates a new Yahoo Finance client with default HTTP settings.
-// The client uses a standard http.Client for making requests to Yahoo Finance endpoints.
+// NewFinanceClient creates a new Yahoo Finance client with the default timeout and retry
+// settings.
 //
 // Returns:
 //   - *FinanceClient: A new client instance ready for use
 func NewFinanceClient() *FinanceClient {
+	return NewFinanceClientWithConfig(config.Defaults().Providers.Yahoo)
+}
+
+// NewFinanceClientWithConfig creates a new Yahoo Finance client with the request timeout
+// and retry settings in cfg.
+func NewFinanceClientWithConfig(cfg config.YahooConfig) *FinanceClient {
 	return &FinanceClient{
-		httpClient: &http.Client{},
+		httpClient: &http.Client{Timeout: time.Duration(cfg.Timeout)},
+		cfg:        cfg,
 	}
 }
 
@@ -259,9 +269,22 @@ func isRetryableStatus(code int) bool {
 // failures: HTTP 5xx, 429 (rate limit), and network/timeout errors.
 // Permanent failures (4xx except 429, JSON parse errors, Yahoo API errors) fail immediately.
 //
-//nolint:gocyclo // Retry loop with error classification needs the branches.
+// Each call is counted once in the external call metrics, whatever the number of attempts.
 func (c *FinanceClient) queryYahoo(ctx context.Context, queryURL string) (Response, error) {
-	backoff := initialBackoff
+	ctx, span := tracing.StartKind(ctx, "yahoo.query", trace.SpanKindClient, attribute.String("url.full", queryURL))
+	response, err := c.queryYahooWithRetry(ctx, queryURL)
+	metrics.ObserveExternalCall(metrics.ClientYahoo, metricsOperation, err)
+	tracing.End(span, err)
+	return response, err
+}
+
+// queryYahooWithRetry runs the attempts for queryYahoo.
+//
+//nolint:gocyclo // Retry loop with error classification needs the branches.
+func (c *FinanceClient) queryYahooWithRetry(ctx context.Context, queryURL string) (Response, error) {
+	maxAttempts := c.cfg.MaxAttempts
+	maxBackoff := time.Duration(c.cfg.MaxBackoff)
+	backoff := time.Duration(c.cfg.InitialBackoff)
 	var lastErr error
 
 	for attempt := range maxAttempts {
@@ -273,6 +296,11 @@ func (c *FinanceClient) queryYahoo(ctx context.Context, queryURL string) (Respon
 				"error", lastErr.Error(),
 				"url", queryURL,
 			)
+			metrics.ExternalRetries.WithLabelValues(metrics.ClientYahoo, metricsOperation).Inc()
+			trace.SpanFromContext(ctx).AddEvent("retry", trace.WithAttributes(
+				attribute.Int("attempt", attempt+1),
+				attribute.String("error", lastErr.Error()),
+			))
 			select {
 			case <-ctx.Done():
 				return Response{}, fmt.Errorf("context cancelled waiting for retry: %w", ctx.Err())